@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/archive"
+)
+
+// archiveMonthLayout is the format of the --from and --to months.
+const archiveMonthLayout = "2006-01"
+
+// runArchiveTracker copies tracked donations to S3, one object per month, so they outlive the table's retention.
+func runArchiveTracker(args []string) error {
+	fs := flag.NewFlagSet("archive-tracker", flag.ContinueOnError)
+	bucket := fs.String("bucket", "", "S3 bucket to archive tracked donations to")
+	dryRun := fs.Bool("dry-run", false, "show the months that would be archived without writing to S3")
+	from := fs.String("from", "", "first month to archive, e.g. 2024-01")
+	prefix := fs.String("prefix", "", "object key prefix (default: <table>/)")
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	table := fs.String("table", "", "donation tracker table name (default: TRACKER_TABLE_NAME, or <stack-name>-donations)")
+	to := fs.String("to", "", "month to stop before, e.g. 2025-01 (default: the current month)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *bucket == "" {
+		return errors.New("--bucket is required")
+	}
+	if *from == "" {
+		return errors.New("--from is required")
+	}
+
+	fromMonth, err := time.Parse(archiveMonthLayout, *from)
+	if err != nil {
+		return fmt.Errorf("invalid --from month %q, expected YYYY-MM: %w", *from, err)
+	}
+
+	// Default to every month that has ended, as the current month may still gain donations.
+	now := time.Now().UTC()
+	toMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if *to != "" {
+		toMonth, err = time.Parse(archiveMonthLayout, *to)
+		if err != nil {
+			return fmt.Errorf("invalid --to month %q, expected YYYY-MM: %w", *to, err)
+		}
+	}
+
+	tableName := trackerTableName(*stackName, *table)
+	keyPrefix := *prefix
+	if keyPrefix == "" {
+		keyPrefix = tableName + "/"
+	}
+
+	ctx := context.Background()
+
+	awsClients, err := newLocalAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	tracker, err := newDonationTracker(awsClients, tableName)
+	if err != nil {
+		return err
+	}
+
+	archiver, err := archive.NewArchiver(awsClients.S3, *bucket, tracker, archive.WithPrefix(keyPrefix))
+	if err != nil {
+		return fmt.Errorf("creating archiver: %w", err)
+	}
+
+	report, err := archiver.Archive(ctx, fromMonth, toMonth, *dryRun)
+	// Show the months archived before a failure, so a rerun's skips are not a surprise.
+	if report != nil {
+		if writeErr := report.Write(os.Stdout, *dryRun); writeErr != nil {
+			return errors.Join(err, writeErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("archiving tracked donations: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/arrears"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/version"
+)
+
+// defaultArrearsLookback is how far back installments are read by default, long enough to include the last
+// installment of an annual plan.
+const defaultArrearsLookback = 13 * 30 * 24 * time.Hour
+
+// runArrearsReport lists active recurring plans whose expected installment has not arrived within the grace period.
+func runArrearsReport(args []string) error {
+	fs := flag.NewFlagSet("arrears-report", flag.ContinueOnError)
+	grace := fs.Duration("grace", arrears.DefaultGrace, "how long past its due date an installment may arrive")
+	since := fs.String(
+		"since",
+		"",
+		"only consider installments made after this time, in RFC3339 format (default: 13 months ago)",
+	)
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	table := fs.String("table", "", "donation tracker table name (default: TRACKER_TABLE_NAME, or <stack-name>-donations)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sinceTime := now.Add(-defaultArrearsLookback)
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("parsing since time: %w", err)
+		}
+		sinceTime = t
+	}
+	if !sinceTime.Before(now) {
+		return errors.New("--since must be in the past")
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	transport, err := newLocalTransport(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Every installment counts towards a plan being paid up, so the campaign and status filters used for syncing
+	// are not applied.
+	fundraiseupClient, err := fundraiseup.NewClient(cfg.FundraiseUp.APIKey,
+		fundraiseup.WithPageSize(cfg.FundraiseUp.PageSize),
+		fundraiseup.WithTransport(transport),
+		fundraiseup.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)))
+	if err != nil {
+		return fmt.Errorf("creating FundraiseUp client: %w", err)
+	}
+
+	tracker, err := newLocalDonationTracker(ctx, trackerTableName(*stackName, *table))
+	if err != nil {
+		return err
+	}
+
+	reporter, err := arrears.NewReporter(fundraiseupClient, tracker, *grace)
+	if err != nil {
+		return fmt.Errorf("creating arrears reporter: %w", err)
+	}
+
+	report, err := reporter.Generate(ctx, sinceTime, now)
+	if err != nil {
+		return fmt.Errorf("generating arrears report: %w", err)
+	}
+
+	return report.Write(os.Stdout, now)
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/peteski22/giftbridge/internal/config"
 	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 const (
@@ -45,6 +46,8 @@ type tokenExchangeRequest struct {
 	Code         string
 	RedirectURI  string
 	TokenURL     string
+	Transport    http.RoundTripper
+	UserAgent    string
 }
 
 // tokenResponse represents the OAuth token response.
@@ -96,6 +99,9 @@ func buildBlackbaudTokenRequest(req tokenExchangeRequest) (*http.Request, error)
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if req.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", req.UserAgent)
+	}
 
 	return httpReq, nil
 }
@@ -107,7 +113,7 @@ func exchangeBlackbaudCode(req tokenExchangeRequest) (*tokenResponse, error) {
 		return nil, err
 	}
 
-	client := &http.Client{Timeout: httpTimeout}
+	client := &http.Client{Timeout: httpTimeout, Transport: req.Transport}
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("executing request: %w", err)
@@ -216,12 +222,19 @@ func runBlackbaudAuth() error {
 	fmt.Println()
 	fmt.Println("Authorization received, exchanging for tokens...")
 
+	transport, err := newLocalTransport(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+
 	tokens, err := exchangeBlackbaudCode(tokenExchangeRequest{
 		ClientID:     cfg.Blackbaud.ClientID,
 		ClientSecret: cfg.Blackbaud.ClientSecret,
 		Code:         code,
 		RedirectURI:  redirectURI,
 		TokenURL:     tokenURL,
+		Transport:    transport,
+		UserAgent:    version.UserAgent(cfg.UserAgent.Organization),
 	})
 	if err != nil {
 		return fmt.Errorf("exchanging code for tokens: %w", err)
@@ -117,6 +117,7 @@ func TestBuildBlackbaudTokenRequest(t *testing.T) {
 		Code:         "auth-code-123",
 		RedirectURI:  "http://localhost:8080/callback",
 		TokenURL:     "https://oauth2.sky.blackbaud.com/token",
+		UserAgent:    "giftbridge/v1.4.0 (st-marys-hospice)",
 	}
 
 	httpReq, err := buildBlackbaudTokenRequest(req)
@@ -125,6 +126,7 @@ func TestBuildBlackbaudTokenRequest(t *testing.T) {
 	require.Equal(t, http.MethodPost, httpReq.Method)
 	require.Equal(t, "https://oauth2.sky.blackbaud.com/token", httpReq.URL.String())
 	require.Equal(t, "application/x-www-form-urlencoded", httpReq.Header.Get("Content-Type"))
+	require.Equal(t, "giftbridge/v1.4.0 (st-marys-hospice)", httpReq.Header.Get("User-Agent"))
 
 	// Parse the body to verify form values.
 	require.NoError(t, httpReq.ParseForm())
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/awsclient"
+	"github.com/peteski22/giftbridge/internal/backfill"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/sync"
+	"github.com/peteski22/giftbridge/internal/version"
+)
+
+// backfillIDLayout formats the default ID of a backfill from the time it is dispatched.
+const backfillIDLayout = "20060102T150405Z"
+
+// runBackfill splits historical donations into batches in S3 for the deployed sync Lambda to process in parallel
+// through the backfill state machine, dispatches a backfill's unfinished batches again, or reports its progress.
+func runBackfill(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	batchSize := fs.Int("batch-size", backfill.DefaultBatchSize, "donations per Lambda invocation")
+	bucket := fs.String("bucket", "", "S3 bucket for batches and results (the stack's BackfillBucketName output)")
+	dispatch := fs.Bool("dispatch", false, "split the donations from --since to --until into batches")
+	id := fs.String("id", "", "ID of the new backfill (default: the current time)")
+	prefix := fs.String("prefix", "", "object key prefix")
+	report := fs.String("report", "", "show the progress of the backfill with this ID")
+	resume := fs.String("resume", "", "dispatch the unfinished batches of the backfill with this ID again")
+	since := fs.String("since", "", "start of the donations to backfill (RFC3339 format)")
+	stateMachine := fs.String("state-machine", "", "backfill state machine ARN, shown in the command to start it")
+	until := fs.String("until", "", "end of the donations to backfill (RFC3339 format, default: now)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	modes := 0
+	for _, set := range []bool{*dispatch, *resume != "", *report != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return errors.New("exactly one of --dispatch, --resume or --report is required")
+	}
+	if *bucket == "" {
+		return errors.New("--bucket is required")
+	}
+
+	ctx := context.Background()
+
+	awsClients, err := newLocalAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	dispatcher, err := backfill.NewDispatcher(awsClients.S3, *bucket,
+		backfill.WithBatchSize(*batchSize), backfill.WithPrefix(*prefix))
+	if err != nil {
+		return fmt.Errorf("creating backfill dispatcher: %w", err)
+	}
+
+	switch {
+	case *report != "":
+		progress, err := dispatcher.Report(ctx, *report)
+		if err != nil {
+			return fmt.Errorf("reporting on backfill: %w", err)
+		}
+		return progress.Write(os.Stdout)
+	case *resume != "":
+		plan, err := dispatcher.Resume(ctx, *resume)
+		if err != nil {
+			return fmt.Errorf("resuming backfill: %w", err)
+		}
+		if plan == nil {
+			fmt.Printf("Every batch of backfill %s has finished, nothing to resume.\n", *resume)
+			return nil
+		}
+		return writeBackfillPlan(os.Stdout, plan, *prefix, *stateMachine)
+	}
+
+	if *since == "" {
+		return errors.New("--dispatch requires --since")
+	}
+	sinceTime, err := time.Parse(time.RFC3339, *since)
+	if err != nil {
+		return fmt.Errorf("--since must be an RFC3339 time, such as 2024-01-01T00:00:00Z: %w", err)
+	}
+	untilTime := time.Now().UTC()
+	if *until != "" {
+		untilTime, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return fmt.Errorf("--until must be an RFC3339 time, such as 2025-01-01T00:00:00Z: %w", err)
+		}
+	}
+	backfillID := *id
+	if backfillID == "" {
+		backfillID = time.Now().UTC().Format(backfillIDLayout)
+	}
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	transport, err := newLocalTransport(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Batches hold the donations the scheduled sync would fetch, so the same filters apply.
+	fundraiseupOpts := append(
+		donationFetchOptions(cfg.FundraiseUp.PageSize, cfg.FundraiseUp.Status, cfg.FundraiseUp.CampaignID),
+		fundraiseup.WithTransport(transport),
+		fundraiseup.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)),
+	)
+	fundraiseupClient, err := donationSource(cfg.FundraiseUp.APIKey, cfg.FundraiseUp.Accounts, fundraiseupOpts)
+	if err != nil {
+		return err
+	}
+
+	plan, err := dispatcher.Dispatch(ctx, backfillID, fundraiseupClient, sinceTime, untilTime)
+	if err != nil {
+		return fmt.Errorf("dispatching backfill: %w", err)
+	}
+
+	return writeBackfillPlan(os.Stdout, plan, *prefix, *stateMachine)
+}
+
+// writeBackfillPlan describes a dispatched manifest, with the commands to start processing it and to follow progress.
+func writeBackfillPlan(w io.Writer, plan *backfill.Plan, prefix string, stateMachine string) error {
+	input, err := json.Marshal(map[string]string{"bucket": plan.Bucket, "key": plan.Key})
+	if err != nil {
+		return fmt.Errorf("encoding execution input: %w", err)
+	}
+	if stateMachine == "" {
+		stateMachine = "<BackfillStateMachineArn>"
+	}
+	prefixFlag := ""
+	if prefix != "" {
+		prefixFlag = " --prefix=" + prefix
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Backfill %s: dispatched %d donations in %d batches to s3://%s/%s\n",
+		plan.ID, plan.Donations, plan.Batches, plan.Bucket, plan.Key)
+	b.WriteString("\nStart processing the batches with:\n")
+	fmt.Fprintf(&b, "  aws stepfunctions start-execution --state-machine-arn %s --name %s-%d --input '%s'\n",
+		stateMachine, plan.ID, plan.Attempt, input)
+	b.WriteString("\nFollow progress, and resume unfinished batches once the execution has stopped, with:\n")
+	fmt.Fprintf(&b, "  giftbridge backfill --report=%s --bucket=%s%s\n", plan.ID, plan.Bucket, prefixFlag)
+	fmt.Fprintf(&b, "  giftbridge backfill --resume=%s --bucket=%s%s\n", plan.ID, plan.Bucket, prefixFlag)
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// backfillBatch returns the backfill batch an invocation event carries, or nil for any other event,
+// such as the schedule's, which runs a sync.
+func backfillBatch(event json.RawMessage) *backfill.Batch {
+	var batch backfill.Batch
+	if err := json.Unmarshal(event, &batch); err != nil || batch.Backfill == "" {
+		return nil
+	}
+	return &batch
+}
+
+// handleBackfillBatch processes one batch of a backfill, as an item of the backfill state machine, and writes its
+// result to the backfill's bucket. The sync time, pending donations and health snapshot are left alone, since the
+// scheduled sync keeps them and batches run in parallel. Donations that fail are reported in the result rather than
+// failing the invocation, so only a batch that could not be processed at all is retried.
+func handleBackfillBatch(
+	ctx context.Context,
+	transport http.RoundTripper,
+	batch backfill.Batch,
+) (*backfill.BatchResult, error) {
+	slog.InfoContext(ctx, "starting backfill batch",
+		"backfill", batch.Backfill,
+		"batch", batch.Number,
+		"attempt", batch.Attempt,
+		"donations", len(batch.DonationIDs))
+
+	if len(batch.DonationIDs) == 0 {
+		return nil, fmt.Errorf("backfill batch %d has no donations", batch.Number)
+	}
+
+	// Stop taking new donations before the Lambda deadline, leaving time to write the result.
+	runCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithDeadline(ctx, deadline.Add(-shutdownGracePeriod))
+		defer cancel()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	// The batch lists its donations, so the window a reconcile-only deployment looks back over does not apply.
+	cfg.Tracker.ReconcileOnly = false
+
+	awsClients, err := awsclient.New(ctx, cfg.AWS)
+	if err != nil {
+		return nil, fmt.Errorf("creating AWS clients: %w", err)
+	}
+
+	tokenStore, err := newLambdaTokenStore(cfg, awsClients, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	stateStore := storage.NewNoopStateStore(time.Time{})
+	syncService, blackbaudClient, err := newLambdaSyncService(
+		cfg, awsClients, transport, stateStore, tokenStore, batch.DonationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := syncService.Run(runCtx)
+	warnSecondaryKey(ctx, blackbaudClient)
+	// An interrupted batch reports the donations it did not reach, which resuming the backfill dispatches again.
+	if err != nil && (result == nil || !result.Interrupted) {
+		return nil, fmt.Errorf("running backfill batch %d: %w", batch.Number, err)
+	}
+
+	batchResult := newBatchResult(result)
+	if err := backfill.WriteResult(ctx, awsClients.S3, batch, batchResult); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "backfill batch complete",
+		append([]any{"backfill", batch.Backfill, "batch", batch.Number}, summaryAttrs(result)...)...)
+
+	return &batchResult, nil
+}
+
+// newBatchResult returns the result of a backfill batch from the result of the sync that processed it.
+func newBatchResult(result *sync.Result) backfill.BatchResult {
+	batchResult := backfill.BatchResult{
+		ConstituentsCreated:  result.ConstituentsCreated,
+		DonationsProcessed:   result.DonationsProcessed,
+		FinishedAt:           time.Now().UTC(),
+		GiftsCreated:         result.GiftsCreated,
+		GiftsSkippedExisting: result.GiftsSkippedExisting,
+		GiftsUpdated:         result.GiftsUpdated,
+		RemainingDonationIDs: result.RemainingDonationIDs,
+	}
+	for _, err := range result.Errors {
+		batchResult.Errors = append(batchResult.Errors, err.Error())
+	}
+	return batchResult
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/backfill"
+)
+
+func TestBackfillBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		event json.RawMessage
+		want  *backfill.Batch
+	}{
+		"backfill batch": {
+			event: json.RawMessage(`{"backfill": "bf-1", "batch": 2, "attempt": 1, "bucket": "my-backfills",
+				"donationIds": ["don_1", "don_2"], "resultKey": "bf-1/results/00002-1.json"}`),
+			want: &backfill.Batch{
+				Attempt:     1,
+				Backfill:    "bf-1",
+				Bucket:      "my-backfills",
+				DonationIDs: []string{"don_1", "don_2"},
+				Number:      2,
+				ResultKey:   "bf-1/results/00002-1.json",
+			},
+		},
+		"scheduled event": {
+			event: json.RawMessage(`{"source": "aws.events", "detail-type": "Scheduled Event", "detail": {}}`),
+		},
+		"no payload": {
+			event: json.RawMessage(``),
+		},
+		"not an object": {
+			event: json.RawMessage(`"sync"`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, backfillBatch(tc.event))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/peteski22/giftbridge/internal/bench"
+)
+
+// runBench syncs generated donations into in-memory fakes of FundraiseUp and Blackbaud for each combination of
+// concurrency and gift cache size, and prints the throughput of each.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	concurrency := fs.String("concurrency", "1,4,16", "comma-separated numbers of batches to sync at once")
+	donations := fs.Int("donations", 1000, "number of donations to sync in each run")
+	giftCacheSizes := fs.String("gift-cache-size", "1,1000",
+		"comma-separated gift cache sizes to compare, where 0 is the sync's default")
+	latency := fs.Duration("latency", 0, "how long each fake API call takes, e.g. 100ms (default: no delay)")
+	supporters := fs.Int("supporters", 0, "number of distinct supporters making the donations (default: donations/4)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	concurrencies, err := parseIntList(*concurrency)
+	if err != nil {
+		return fmt.Errorf("parsing --concurrency: %w", err)
+	}
+	cacheSizes, err := parseIntList(*giftCacheSizes)
+	if err != nil {
+		return fmt.Errorf("parsing --gift-cache-size: %w", err)
+	}
+
+	ctx := context.Background()
+
+	var reports []bench.Report
+	for _, c := range concurrencies {
+		for _, size := range cacheSizes {
+			report, err := bench.Run(ctx, bench.Config{
+				Concurrency:   c,
+				Donations:     *donations,
+				GiftCacheSize: size,
+				Latency:       *latency,
+				Supporters:    *supporters,
+			})
+			if err != nil {
+				return fmt.Errorf("benchmarking concurrency %d with gift cache size %d: %w", c, size, err)
+			}
+			reports = append(reports, *report)
+		}
+	}
+
+	return bench.WriteTable(os.Stdout, reports)
+}
+
+// parseIntList parses a comma-separated list of integers.
+func parseIntList(list string) ([]int, error) {
+	var values []int
+	for field := range strings.SplitSeq(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, errors.New("at least one value is required")
+	}
+
+	return values, nil
+}
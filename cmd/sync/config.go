@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+// runConfig runs a config subcommand.
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.New("config requires a command: show or validate")
+	}
+
+	switch args[0] {
+	case "show":
+		return runConfigShow(args[1:])
+	case "validate":
+		return runConfigValidate(args[1:])
+	default:
+		return fmt.Errorf("unknown config command: %s", args[0])
+	}
+}
+
+// runConfigShow prints the configuration the local sync would use, or with --env the configuration the Lambda
+// would use, including defaults, with secrets redacted.
+func runConfigShow(args []string) error {
+	fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+	env := fs.Bool("env", false, "show the configuration the Lambda reads from environment variables")
+	file := fs.String("file", "", "config file to show (default: config.yaml in the config directory)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfigSource(*env, *file)
+	if err != nil {
+		return err
+	}
+	return writeSettings(os.Stdout, config.Describe(cfg))
+}
+
+// runConfigValidate checks the local config file, or with --env the environment variables the Lambda reads,
+// and lists every problem found without running a sync.
+func runConfigValidate(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	env := fs.Bool("env", false, "check the environment variables the Lambda reads instead of the config file")
+	file := fs.String("file", "", "config file to check (default: config.yaml in the config directory)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	source, err := configSource(*env, *file)
+	if err != nil {
+		return err
+	}
+	_, err = loadConfigSource(*env, *file)
+	return writeValidation(os.Stdout, source, err)
+}
+
+// configSource describes where configuration is read from: the environment with env set,
+// otherwise the config file at path or the default config file.
+func configSource(env bool, path string) (string, error) {
+	switch {
+	case env && path != "":
+		return "", errors.New("--env and --file cannot be used together")
+	case env:
+		return "Environment variables", nil
+	case path != "":
+		return path, nil
+	default:
+		return config.ConfigFilePath()
+	}
+}
+
+// loadConfigSource loads the configuration described by configSource.
+func loadConfigSource(env bool, path string) (any, error) {
+	source, err := configSource(env, path)
+	if err != nil {
+		return nil, err
+	}
+	if env {
+		return config.Load()
+	}
+	return config.LoadLocalFile(source)
+}
+
+// configProblems splits an error loading configuration into its problems, one per line.
+func configProblems(err error) []string {
+	var problems []string
+	for _, line := range strings.Split(strings.TrimPrefix(err.Error(), "invalid config: "), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			problems = append(problems, line)
+		}
+	}
+	return problems
+}
+
+// writeSettings prints settings as a table of names and values.
+func writeSettings(w io.Writer, settings []config.Setting) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE")
+	for _, setting := range settings {
+		fmt.Fprintf(tw, "%s\t%s\n", setting.Name, setting.Value)
+	}
+	return tw.Flush()
+}
+
+// writeValidation prints whether the configuration from source is valid, listing its problems if not.
+// It returns an error when there are problems, so the command exits with a failure status.
+func writeValidation(w io.Writer, source string, err error) error {
+	if err == nil {
+		fmt.Fprintf(w, "%s: valid\n", source)
+		return nil
+	}
+
+	problems := configProblems(err)
+	fmt.Fprintf(w, "%s: %d problem(s)\n", source, len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  - %s\n", problem)
+	}
+	return fmt.Errorf("%s is not valid", source)
+}
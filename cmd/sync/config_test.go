@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+func TestWriteSettings(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	err := writeSettings(&out, []config.Setting{
+		{Name: "Blackbaud.ClientSecret", Value: "****1234"},
+		{Name: "GiftDefaults.FundID", Value: "fund-123"},
+		{Name: "GiftDefaults.CampaignID"},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "SETTING                  VALUE\n"+
+		"Blackbaud.ClientSecret   ****1234\n"+
+		"GiftDefaults.FundID      fund-123\n"+
+		"GiftDefaults.CampaignID  \n", out.String())
+}
+
+func TestWriteValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err     error
+		wantErr bool
+		wantOut string
+	}{
+		"valid": {
+			wantOut: "config.yaml: valid\n",
+		},
+		"lists each problem": {
+			err: errors.New("invalid config: gift.fund_id is required\n" +
+				`gift.type must be spelled "Donation", not "donation"`),
+			wantErr: true,
+			wantOut: "config.yaml: 2 problem(s)\n" +
+				"  - gift.fund_id is required\n" +
+				`  - gift.type must be spelled "Donation", not "donation"` + "\n",
+		},
+		"file that cannot be read": {
+			err:     errors.New("config file not found: config.yaml (run 'giftbridge init' to create)"),
+			wantErr: true,
+			wantOut: "config.yaml: 1 problem(s)\n" +
+				"  - config file not found: config.yaml (run 'giftbridge init' to create)\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			err := writeValidation(&out, "config.yaml", tc.err)
+
+			if tc.wantErr {
+				require.EqualError(t, err, "config.yaml is not valid")
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.wantOut, out.String())
+		})
+	}
+}
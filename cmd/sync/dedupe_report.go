@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/dedupe"
+)
+
+// runDedupeReport lists supporters and constituents that look duplicated across FundraiseUp and Blackbaud.
+func runDedupeReport(args []string) error {
+	fs := flag.NewFlagSet("dedupe-report", flag.ContinueOnError)
+	since := fs.String("since", "", "only consider donations made after this time, in RFC3339 format (default: all)")
+	skipSearch := fs.Bool("skip-search", false, "use tracker data only, without searching Blackbaud by email")
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	table := fs.String("table", "", "donation tracker table name (default: TRACKER_TABLE_NAME, or <stack-name>-donations)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var sinceTime time.Time
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("parsing since time: %w", err)
+		}
+		sinceTime = t
+	}
+
+	ctx := context.Background()
+
+	tracker, err := newLocalDonationTracker(ctx, trackerTableName(*stackName, *table))
+	if err != nil {
+		return err
+	}
+
+	var bb dedupe.Blackbaud
+	if !*skipSearch {
+		cfg, err := config.LoadLocal()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		client, err := newLocalBlackbaudClient(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		bb = client
+	}
+
+	reporter, err := dedupe.NewReporter(tracker, bb)
+	if err != nil {
+		return fmt.Errorf("creating dedupe reporter: %w", err)
+	}
+
+	report, err := reporter.Generate(ctx, sinceTime)
+	if err != nil {
+		return fmt.Errorf("generating dedupe report: %w", err)
+	}
+
+	return report.Write(os.Stdout)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/peteski22/giftbridge/internal/erasure"
+)
+
+// runForget removes a FundraiseUp supporter's personal data from the donation tracker,
+// and lists the Raiser's Edge NXT records to erase by hand.
+func runForget(args []string) error {
+	fs := flag.NewFlagSet("forget", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show the records that would be erased without changing them")
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	supporter := fs.String("supporter", "", "FundraiseUp supporter ID, e.g. sup_XXXXXXXX")
+	table := fs.String("table", "", "donation tracker table name (default: TRACKER_TABLE_NAME, or <stack-name>-donations)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *supporter == "" {
+		return errors.New("--supporter is required")
+	}
+
+	ctx := context.Background()
+
+	tracker, err := newLocalDonationTracker(ctx, trackerTableName(*stackName, *table))
+	if err != nil {
+		return err
+	}
+
+	eraser, err := erasure.NewEraser(tracker)
+	if err != nil {
+		return fmt.Errorf("creating supporter eraser: %w", err)
+	}
+
+	report, err := eraser.Erase(ctx, *supporter, *dryRun)
+	// Show what was erased before a failure, and the records still to erase by hand.
+	if report != nil {
+		if writeErr := report.Write(os.Stdout, *dryRun); writeErr != nil {
+			return errors.Join(err, writeErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("erasing supporter: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+// functionReadHeaderTimeout bounds how long a Cloud Functions or Azure Functions request may take to send its
+// headers.
+const functionReadHeaderTimeout = 10 * time.Second
+
+// syncResponse is the JSON body of a response to an HTTP-triggered sync.
+type syncResponse struct {
+	// Error describes why the sync failed, or is empty when it succeeded.
+	Error string `json:"error,omitempty"`
+
+	// Result is the outcome of the sync, including one that failed after processing donations, or nil when the
+	// sync did not run.
+	Result *sync.Result `json:"result,omitempty"`
+
+	// Status is "ok" when the sync succeeded, otherwise "error".
+	Status string `json:"status"`
+}
+
+// syncHTTPHandler runs a sync cycle for each POST request, for the Cloud Functions and Azure Functions entry
+// points. A request arriving while a sync is still running is refused with 409 Conflict rather than running a
+// second sync alongside it, which could create the same gifts twice. The response carries the sync's result.
+func syncHTTPHandler(run func(ctx context.Context) (*sync.Result, error)) http.Handler {
+	var running atomic.Bool
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeSyncResponse(w, http.StatusMethodNotAllowed, nil, errors.New("method not allowed"))
+			return
+		}
+		if !running.CompareAndSwap(false, true) {
+			writeSyncResponse(w, http.StatusConflict, nil, errors.New("a sync is already running"))
+			return
+		}
+		defer running.Store(false)
+
+		result, err := run(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "sync failed", "error", err)
+			writeSyncResponse(w, http.StatusInternalServerError, result, err)
+			return
+		}
+		writeSyncResponse(w, http.StatusOK, result, nil)
+	})
+}
+
+// writeSyncResponse writes the outcome of an HTTP-triggered sync as JSON.
+func writeSyncResponse(w http.ResponseWriter, status int, result *sync.Result, err error) {
+	resp := syncResponse{Result: result, Status: "ok"}
+	if err != nil {
+		resp = syncResponse{Error: err.Error(), Result: result, Status: "error"}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// serveFunction serves handler on addr until the platform sends SIGTERM or SIGINT, then waits for a running sync
+// to finish its in-flight donation before returning.
+func serveFunction(addr string, handler http.Handler) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr: addr,
+		// A running sync stops taking new donations once the platform stops the instance.
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		Handler:           handler,
+		ReadHeaderTimeout: functionReadHeaderTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		slog.Info("listening for sync requests", "addr", addr)
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, waiting for the running sync to finish")
+	return server.Shutdown(context.Background())
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+func TestSyncHTTPHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method     string
+		result     *sync.Result
+		runErr     error
+		wantBody   string
+		wantRuns   int
+		wantStatus int
+	}{
+		"successful sync": {
+			method: http.MethodPost,
+			result: &sync.Result{
+				DonationsProcessed:   3,
+				GiftsCreated:         2,
+				GiftsSkippedExisting: 1,
+				RunID:                "2025-03-01T12:00:00Z",
+			},
+			wantBody: `{"result":{"donationsProcessed":3,"giftsCreated":2,"giftsSkippedExisting":1,` +
+				`"runId":"2025-03-01T12:00:00Z"},"status":"ok"}`,
+			wantRuns:   1,
+			wantStatus: http.StatusOK,
+		},
+		"failed sync": {
+			method:     http.MethodPost,
+			runErr:     errors.New("running sync: fetching donations: unexpected status 503"),
+			wantBody:   `{"error":"running sync: fetching donations: unexpected status 503","status":"error"}`,
+			wantRuns:   1,
+			wantStatus: http.StatusInternalServerError,
+		},
+		"sync with failed donations": {
+			method: http.MethodPost,
+			result: &sync.Result{
+				DonationsProcessed: 1,
+				Errors:             []error{errors.New("don_1: creating gift: unexpected status 400")},
+				RunID:              "2025-03-01T12:00:00Z",
+			},
+			runErr: errors.New("sync completed with 1 errors"),
+			wantBody: `{"error":"sync completed with 1 errors","result":{"donationsProcessed":1,` +
+				`"errors":["don_1: creating gift: unexpected status 400"],"runId":"2025-03-01T12:00:00Z"},` +
+				`"status":"error"}`,
+			wantRuns:   1,
+			wantStatus: http.StatusInternalServerError,
+		},
+		"GET does not sync": {
+			method:     http.MethodGet,
+			wantBody:   `{"error":"method not allowed","status":"error"}`,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runs := 0
+			handler := syncHTTPHandler(func(context.Context) (*sync.Result, error) {
+				runs++
+				return tc.result, tc.runErr
+			})
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tc.method, "/api/sync", nil))
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			require.JSONEq(t, tc.wantBody, rec.Body.String())
+			require.Equal(t, tc.wantRuns, runs)
+		})
+	}
+}
+
+func TestSyncHTTPHandler_RefusesOverlappingSync(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := syncHTTPHandler(func(context.Context) (*sync.Result, error) {
+		close(started)
+		<-release
+		return &sync.Result{}, nil
+	})
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/", nil))
+	}()
+	<-started
+
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusConflict, second.Code)
+
+	close(release)
+	<-done
+	require.Equal(t, http.StatusOK, first.Code)
+}
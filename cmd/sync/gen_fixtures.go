@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fixtures"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/version"
+)
+
+// runGenFixtures writes FundraiseUp donations to a test fixture, with donors' personal data replaced by fake values.
+func runGenFixtures(args []string) error {
+	fs := flag.NewFlagSet("gen-fixtures", flag.ContinueOnError)
+	key := fs.String("key", "", "secret choosing the fake values, to generate the same fixture again (default: random)")
+	limit := fs.Int("limit", 0, "maximum number of donations to write (default: all)")
+	output := fs.String("output", filepath.Join("testdata", "donations.json"), "output file path")
+	since := fs.String("since", "", "write donations made after this time, in RFC3339 format")
+	until := fs.String("until", "", "write donations made before this time, in RFC3339 format (default: now)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *since == "" {
+		return errors.New("--since is required")
+	}
+	sinceTime, err := time.Parse(time.RFC3339, *since)
+	if err != nil {
+		return fmt.Errorf("parsing --since: %w", err)
+	}
+	var untilTime time.Time
+	if *until != "" {
+		untilTime, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return fmt.Errorf("parsing --until: %w", err)
+		}
+		if !untilTime.After(sinceTime) {
+			return errors.New("--until must be after --since")
+		}
+	}
+	if *limit < 0 {
+		return errors.New("--limit cannot be negative")
+	}
+
+	// A random key means the fake values cannot be traced back to donors, even by whoever generated them.
+	secret := []byte(*key)
+	if len(secret) == 0 {
+		secret = []byte(rand.Text())
+	}
+	pseudonymizer, err := fixtures.NewPseudonymizer(secret)
+	if err != nil {
+		return fmt.Errorf("creating pseudonymizer: %w", err)
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	transport, err := newLocalTransport(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Fixtures should cover every kind of donation, so the campaign and status filters used for syncing are not
+	// applied.
+	fundraiseupClient, err := fundraiseup.NewClient(cfg.FundraiseUp.APIKey,
+		fundraiseup.WithPageSize(cfg.FundraiseUp.PageSize),
+		fundraiseup.WithTransport(transport),
+		fundraiseup.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)))
+	if err != nil {
+		return fmt.Errorf("creating FundraiseUp client: %w", err)
+	}
+
+	donations, err := fixtures.Collect(ctx, fundraiseupClient, pseudonymizer, sinceTime, untilTime, *limit)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	file, err := os.OpenFile(*output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := fixtures.Write(file, donations); err != nil {
+		return fmt.Errorf("writing fixture: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d pseudonymized donations to %s\n", len(donations), *output)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	gosync "sync"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+// rotationRecordingTokenStore wraps a token store and records when the refresh token was last replaced.
+type rotationRecordingTokenStore struct {
+	blackbaud.TokenStore
+
+	mu      gosync.Mutex
+	savedAt time.Time
+}
+
+// SaveRefreshToken saves the refresh token and records when it was replaced.
+func (r *rotationRecordingTokenStore) SaveRefreshToken(ctx context.Context, token string) error {
+	if err := r.TokenStore.SaveRefreshToken(ctx, token); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.savedAt = time.Now()
+	return nil
+}
+
+// SavedAt returns when the refresh token was last replaced through the store, or zero if it has not been.
+func (r *rotationRecordingTokenStore) SavedAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.savedAt
+}
+
+// nextHealthSnapshot returns the health snapshot to publish after a run, carrying forward the last success
+// and token refresh times from the previous snapshot when this run did not update them.
+// A run succeeds when it finishes without a failure or any donation errors.
+func nextHealthSnapshot(
+	previous *storage.HealthSnapshot,
+	now time.Time,
+	result *sync.Result,
+	runErr error,
+	backlog int,
+	fetchInProgress bool,
+	tokenSavedAt time.Time,
+) storage.HealthSnapshot {
+	snapshot := storage.HealthSnapshot{
+		BacklogSize:      backlog,
+		FetchInProgress:  fetchInProgress,
+		LastRunSucceeded: runErr == nil && result != nil && len(result.Errors) == 0,
+		TokenRefreshedAt: tokenSavedAt.UTC(),
+		UpdatedAt:        now.UTC(),
+	}
+	if previous != nil {
+		snapshot.LastSuccessAt = previous.LastSuccessAt
+		if tokenSavedAt.IsZero() {
+			snapshot.TokenRefreshedAt = previous.TokenRefreshedAt
+		}
+	}
+	if snapshot.LastRunSucceeded {
+		snapshot.LastSuccessAt = now.UTC()
+	}
+	return snapshot
+}
+
+// publishHealth publishes a health snapshot for external monitors after a run.
+// Failures are logged rather than failing the run, whose own outcome is what matters.
+func publishHealth(
+	ctx context.Context,
+	store *storage.StateStore,
+	tokenStore *rotationRecordingTokenStore,
+	result *sync.Result,
+	runErr error,
+) {
+	// Publish even when the run was cancelled, since a stalled sync is what monitors look for.
+	ctx = context.WithoutCancel(ctx)
+
+	previous, err := store.HealthSnapshot(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read health snapshot", "error", err)
+		return
+	}
+	pendingIDs, err := store.PendingDonationIDs(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read pending donations for health snapshot", "error", err)
+		return
+	}
+	fetchState, err := store.FetchState(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read fetch state for health snapshot", "error", err)
+		return
+	}
+
+	snapshot := nextHealthSnapshot(
+		previous,
+		time.Now(),
+		result,
+		runErr,
+		len(pendingIDs),
+		fetchState != nil,
+		tokenStore.SavedAt(),
+	)
+	if err := store.SetHealthSnapshot(ctx, snapshot); err != nil {
+		slog.WarnContext(ctx, "failed to publish health snapshot", "error", err)
+	}
+}
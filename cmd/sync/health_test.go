@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+func TestNextHealthSnapshot(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-24 * time.Hour)
+	previous := &storage.HealthSnapshot{
+		BacklogSize:      5,
+		LastSuccessAt:    earlier,
+		TokenRefreshedAt: earlier,
+		UpdatedAt:        earlier,
+	}
+
+	tests := map[string]struct {
+		previous     *storage.HealthSnapshot
+		result       *sync.Result
+		runErr       error
+		tokenSavedAt time.Time
+		want         storage.HealthSnapshot
+	}{
+		"first successful run": {
+			result:       &sync.Result{},
+			tokenSavedAt: now,
+			want: storage.HealthSnapshot{
+				LastRunSucceeded: true,
+				LastSuccessAt:    now,
+				TokenRefreshedAt: now,
+				UpdatedAt:        now,
+			},
+		},
+		"successful run without token rotation keeps previous token time": {
+			previous: previous,
+			result:   &sync.Result{},
+			want: storage.HealthSnapshot{
+				LastRunSucceeded: true,
+				LastSuccessAt:    now,
+				TokenRefreshedAt: earlier,
+				UpdatedAt:        now,
+			},
+		},
+		"run with donation errors keeps previous success time": {
+			previous:     previous,
+			result:       &sync.Result{Errors: []error{errors.New("creating gift")}},
+			tokenSavedAt: now,
+			want: storage.HealthSnapshot{
+				LastSuccessAt:    earlier,
+				TokenRefreshedAt: now,
+				UpdatedAt:        now,
+			},
+		},
+		"failed run keeps previous success time": {
+			previous: previous,
+			runErr:   errors.New("getting last sync time"),
+			want: storage.HealthSnapshot{
+				LastSuccessAt:    earlier,
+				TokenRefreshedAt: earlier,
+				UpdatedAt:        now,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := nextHealthSnapshot(tc.previous, now, tc.result, tc.runErr, 0, false, tc.tokenSavedAt)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -17,10 +17,79 @@ blackbaud:
   client_secret: ""
   # From Blackbaud Developer Portal -> My Subscriptions.
   subscription_key: ""
+  # Optional: The subscription's other key, used when Blackbaud rejects the one above.
+  secondary_subscription_key: ""
+  # Pause when fewer than this many API calls are left in the quota (0 never pauses).
+  quota_reserve: 0
+  # Optional: Repeat a slow API read after this long, e.g. "2s", using whichever answers first (default: off).
+  hedge_delay: 0s
+  # Constituents whose gifts are kept in memory during a run (0 uses the default, 1000).
+  gift_cache_size: 0
+
+comments:
+  # Remove payment card numbers from donor comments before they become gift references.
+  scrub_card_numbers: false
+  # Optional: Regular expressions removed from donor comments, e.g. ['\d+ \w+ (Road|Street)'].
+  scrub_patterns: []
+  # Optional: Words removed from donor comments, e.g. a profanity list.
+  scrub_words: []
+
+constituent:
+  # Optional: How new constituents are addressed in mail, from {{.FirstName}} and {{.LastName}}.
+  # Leave empty to use your organisation's default format.
+  addressee_format: ""
+  # Optional: Constituent codes added to new constituents, e.g. ["Online Donor"].
+  codes: []
+  # Optional: Add a note of this Raiser's Edge note type describing each online donation to its constituent.
+  donation_note_type: ""
+  # Optional: The note's text, from fields such as {{.Campaign}}, {{.UTMSource}} and {{.Comment}}.
+  # Leave empty for a summary of the donation.
+  donation_note_format: ""
+  # Optional: Raiser's Edge events that donors buying a ticket for a FundraiseUp event are added to.
+  # events:
+  #   - fundraiseup_event_id: EVTGALA24
+  #     event_id: GALA-2024
+  events: []
+  # Optional: How new constituents are greeted in mail, e.g. "Dear {{.FirstName}}".
+  salutation_format: ""
+
+email:
+  # Match "John.Doe+fr@gmail.com" to an existing "johndoe@gmail.com" constituent.
+  fold_gmail: false
+  # Ignore "+tag" in addresses on any domain when matching constituents.
+  strip_plus_tags: false
+  # Only match the exact address in a constituent's email field, not names or partial text.
+  strict_search: false
+  # Also match inactive constituents.
+  include_inactive: false
+  # Add the donor's email to a matched constituent with no email on file.
+  add_missing: false
 
 fundraiseup:
   # From FundraiseUp Dashboard -> Settings -> API keys.
   api_key: ""
+  # Optional: Further accounts, such as other entities of your charity, synced in the same run.
+  # Their gifts record the account name in the origin.
+  # accounts:
+  #   - name: trading
+  #     api_key: ""
+  accounts: []
+  # Optional: Only sync donations to this campaign, or with this status (e.g. "succeeded").
+  campaign_id: ""
+  status: ""
+  # Optional: Sync a campaign only from or until a date (until is exclusive), or exclude it altogether.
+  # campaign_windows:
+  #   - campaign_id: FUNSPRING
+  #     from: 2025-03-01T00:00:00Z
+  #   - campaign_id: FUNLEGACY
+  #     exclude: true
+  campaign_windows: []
+  # Fetch donations from this long before the last sync, e.g. "15m", so donations listed late are not missed.
+  fetch_overlap: 0s
+  # Donations fetched per API request (1 to 100).
+  page_size: 100
+  # Log fields FundraiseUp sends that GiftBridge does not map, once per run.
+  strict_decode: false
 
 gift:
   # Required: Raiser's Edge Fund ID.
@@ -28,8 +97,78 @@ gift:
   # Optional: Campaign and Appeal IDs.
   campaign_id: ""
   appeal_id: ""
+  # Optional: Record each donor's response to the appeal of their gift, so appeal reports include online gifts.
+  appeal_responses: false
   # Gift type (default: Donation).
   type: "Donation"
+  # Optional: Posting status of new gifts, "NotPosted" or "DoNotPost" (default: Raiser's Edge default).
+  post_status: ""
+  # Optional: Post date of NotPosted gifts, "donation" (default) or "sync".
+  post_date: ""
+  # Optional: Gift field storing the FundraiseUp donation ID, "lookup_id" (default) or "origin".
+  reference_field: ""
+  # Optional: Gift note type of the note holding a donor comment too long for the gift's reference (255 characters).
+  reference_note_type: ""
+  # Optional: How recurring donations are recorded, "linked" (default) or "flat" as plain gifts of the gift type.
+  recurring_mode: ""
+  # Optional: Rules computing gift fields from each donation (see docs/field-mapping.md).
+  # rules:
+  #   - field: fund_id
+  #     when: "donation.amount >= 1000"
+  #     value: "'MAJOR'"
+  rules: []
+  # Optional: Checks each gift must pass before it is created; gifts failing one are reported as errors instead.
+  # checks:
+  #   - assert: "donation.amount < 10000"
+  #     message: "gift over 10,000 needs review"
+  checks: []
+  # Optional: What to do with gifts dated in the future or older than max_age_days, "flag" or "refuse".
+  date_policy: ""
+  # Optional: How many days old a gift's date may be before date_policy applies to it (0 checks only future dates).
+  max_age_days: 0
+  # Optional: Splits sending an amount or percentage of each gift to other funds, the remainder going to fund_id.
+  # splits:
+  #   - fund_id: BUILDING
+  #     amount: 50
+  #   - fund_id: ADMIN
+  #     percent: 10
+  splits: []
+  # Optional: What to do with donations made in FundraiseUp's test mode, "skip" (default) or "sync".
+  test_donations: ""
+  # Optional: Fund ID synced test-mode donations are recorded in, in place of every other fund.
+  test_fund_id: ""
+  # Optional: Routes sending gifts from supporters in given countries to their own fund, campaign or appeal.
+  # country_routes:
+  #   - countries: ["GB"]
+  #     fund_id: GIFTAID
+  #   - countries: ["US"]
+  #     fund_id: US501C3
+  country_routes: []
+  # Optional: Gift custom field category (Text) recording that GiftBridge created each gift, and in which run.
+  creator_custom_field: ""
+
+names:
+  # Capitalise names of new constituents supplied all lowercase or all uppercase.
+  title_case: false
+  # Replace accented letters with ASCII in names of new constituents.
+  transliterate: false
+
+proxy:
+  # Optional: HTTP proxy for API requests (default: the HTTPS_PROXY environment variable).
+  url: ""
+  # Optional: Hosts, and their subdomains, whose requests skip the proxy.
+  bypass: []
+
+tls:
+  # Optional: PEM file, or Secrets Manager secret ARN, of extra CAs trusted for API servers.
+  ca_bundle: ""
+  # Optional: PEM client certificate and private key for APIs that require mutual TLS.
+  client_cert: ""
+  client_key: ""
+
+user_agent:
+  # Optional: Identifies your organization to FundraiseUp and Blackbaud in the User-Agent header.
+  organization: ""
 `
 
 // runInit creates a sample configuration file.
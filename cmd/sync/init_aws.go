@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/awsclient"
+	"github.com/peteski22/giftbridge/internal/bootstrap"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// runInitAWS creates the SSM parameters and Secrets Manager secret required by the Lambda.
+func runInitAWS(args []string) error {
+	fs := flag.NewFlagSet("init-aws", flag.ContinueOnError)
+	externalID := fs.String("external-id", "", "external ID required to assume --role-arn")
+	region := fs.String("region", "", "AWS region (default: from AWS CLI config)")
+	roleARN := fs.String("role-arn", "", "IAM role to assume, for resources in another account")
+	seedToken := fs.Bool("seed-token", false, "store the local refresh token (from 'giftbridge auth') in the secret")
+	since := fs.String("since", "", "initial last sync time in RFC3339 format (default: 30 days ago)")
+	skipTracker := fs.Bool("skip-tracker", false, "do not create the DynamoDB donation tracker table")
+	stackName := fs.String("stack-name", "giftbridge", "prefix for resource names not set in the environment")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	initialSync := time.Now().AddDate(0, 0, -30)
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("parsing since time: %w", err)
+		}
+		initialSync = t
+	}
+
+	var refreshToken string
+	if *seedToken {
+		token, err := localRefreshToken(ctx)
+		if err != nil {
+			return fmt.Errorf("reading local refresh token: %w", err)
+		}
+		refreshToken = token
+	}
+
+	awsCfg, err := config.LoadAWS()
+	if err != nil {
+		return fmt.Errorf("loading AWS config: %w", err)
+	}
+	if *region != "" {
+		awsCfg.Region = *region
+	}
+	if *roleARN != "" {
+		awsCfg.RoleARN = *roleARN
+		awsCfg.RoleExternalID = *externalID
+	}
+
+	awsClients, err := awsclient.New(ctx, awsCfg)
+	if err != nil {
+		return fmt.Errorf("creating AWS clients: %w", err)
+	}
+
+	provisioner, err := bootstrap.NewProvisioner(awsClients.SSM, awsClients.SecretsManager, awsClients.STS)
+	if err != nil {
+		return fmt.Errorf("creating provisioner: %w", err)
+	}
+
+	fmt.Println("=== AWS Setup ===")
+	fmt.Println()
+
+	resources, err := bootstrap.ResolveResources(*stackName, config.LoadResourceNames())
+	if err != nil {
+		return fmt.Errorf("resolving resource names: %w", err)
+	}
+
+	result, err := provisioner.Provision(ctx, bootstrap.ProvisionRequest{
+		InitialSyncTime: initialSync,
+		RefreshToken:    refreshToken,
+		Resources:       resources,
+	})
+	if err != nil {
+		return fmt.Errorf("provisioning AWS resources: %w", err)
+	}
+
+	printProvisionResult(result, resources)
+
+	if !*skipTracker {
+		if err := ensureTrackerTable(ctx, awsClients.DynamoDB, resources.DonationTableName); err != nil {
+			return err
+		}
+	}
+
+	if result.Failed() {
+		return errors.New("one or more permission checks failed")
+	}
+
+	return nil
+}
+
+// ensureTrackerTable creates or migrates the donation tracker table and prints the outcome.
+func ensureTrackerTable(ctx context.Context, client storage.DynamoDBAPI, tableName string) error {
+	tracker, err := storage.NewDonationTracker(client, tableName)
+	if err != nil {
+		return fmt.Errorf("creating donation tracker: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Waiting for DynamoDB table %s...\n", tableName)
+
+	status, err := tracker.EnsureDonationTable(ctx)
+	if err != nil {
+		return fmt.Errorf("ensuring donation table: %w", err)
+	}
+
+	switch {
+	case status.Created:
+		fmt.Printf("DynamoDB table %s: %s (schema version %d)\n", tableName, bootstrap.StatusCreated, status.Version)
+	case status.Migrated():
+		fmt.Printf("DynamoDB table %s: migrated from schema version %d to %d\n",
+			tableName, status.PreviousVersion, status.Version)
+	default:
+		fmt.Printf("DynamoDB table %s: %s (schema version %d)\n", tableName, bootstrap.StatusExists, status.Version)
+	}
+	fmt.Printf("  %s=%s\n", config.EnvTrackerTableName, tableName)
+
+	return nil
+}
+
+// localRefreshToken reads the refresh token saved by 'giftbridge auth'.
+func localRefreshToken(ctx context.Context) (string, error) {
+	tokenPath, err := config.TokenFilePath()
+	if err != nil {
+		return "", fmt.Errorf("getting token path: %w", err)
+	}
+
+	tokenStore, err := storage.NewFileTokenStore(tokenPath)
+	if err != nil {
+		return "", fmt.Errorf("creating token store: %w", err)
+	}
+
+	return tokenStore.RefreshToken(ctx)
+}
+
+// printProvisionResult outputs a human-readable summary of the provisioned resources to stdout.
+func printProvisionResult(result *bootstrap.ProvisionResult, resources bootstrap.Resources) {
+	fmt.Printf("Using credentials: %s\n", result.CallerARN)
+	fmt.Println()
+
+	for _, r := range result.Resources {
+		fmt.Printf("%s %s: %s\n", r.Kind, r.Name, r.Status)
+	}
+
+	fmt.Println()
+	fmt.Println("Permission checks:")
+	for _, c := range result.Checks {
+		if c.Err != nil {
+			fmt.Printf("  FAIL %s on %s: %s\n", c.Action, c.Resource, c.Err)
+			continue
+		}
+		fmt.Printf("  OK   %s on %s\n", c.Action, c.Resource)
+	}
+
+	fmt.Println()
+	fmt.Println("Lambda environment:")
+	fmt.Printf("  %s=%s\n", config.EnvSSMParameterName, resources.LastSyncParameterName)
+	fmt.Printf("  %s=%s\n", config.EnvBlackbaudRefreshTokenSecretARN, result.SecretARN)
+}
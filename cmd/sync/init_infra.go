@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/peteski22/giftbridge/internal/bootstrap"
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+// runInitInfra generates Terraform or CDK infrastructure definitions for a giftbridge deployment.
+func runInitInfra(args []string) error {
+	fs := flag.NewFlagSet("init-infra", flag.ContinueOnError)
+	format := fs.String("format", "", "output format: terraform or cdk (required)")
+	output := fs.String("output", "", "write to this file instead of stdout")
+	schedule := fs.String("schedule", "rate(1 hour)", "EventBridge schedule expression")
+	stackName := fs.String("stack-name", "giftbridge", "prefix for resource names not set in the environment")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		return errors.New("--format is required (terraform or cdk)")
+	}
+
+	f, err := bootstrap.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.OpenFile(*output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		w = file
+	}
+
+	if err := bootstrap.Render(w, f, bootstrap.Options{
+		Names:              config.LoadResourceNames(),
+		ScheduleExpression: *schedule,
+		StackName:          *stackName,
+	}); err != nil {
+		return fmt.Errorf("generating infrastructure: %w", err)
+	}
+
+	if *output != "" {
+		fmt.Println("Created", f, "definition:", *output)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+func TestRunInitInfraWritesOutputFile(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "main.tf")
+
+	err := runInitInfra([]string{"--format=terraform", "--stack-name=charity", "--output=" + outputPath})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"charity-donations"`)
+}
+
+func TestRunInitInfraUsesConfiguredNames(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv().
+	t.Setenv(config.EnvSSMParameterName, "/charity/sync/last-sync-time")
+	t.Setenv(config.EnvBlackbaudRefreshTokenSecretARN,
+		"arn:aws:secretsmanager:eu-west-2:123456789012:secret:charity/bb-token-AbCdEf")
+	t.Setenv(config.EnvTrackerTableName, "charity-gifts")
+
+	outputPath := filepath.Join(t.TempDir(), "main.tf")
+
+	err := runInitInfra([]string{"--format=terraform", "--stack-name=charity", "--output=" + outputPath})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"charity-gifts"`)
+	require.Contains(t, string(data), `"/charity/sync/last-sync-time"`)
+	require.Contains(t, string(data), `parameter/charity/sync/pending-donations`)
+	require.Contains(t, string(data), `"charity/bb-token"`)
+	require.Contains(t, string(data), `"charity-sync"`)
+}
+
+func TestRunInitInfraRefusesToOverwrite(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "main.tf")
+	require.NoError(t, os.WriteFile(outputPath, []byte("existing"), 0o600))
+
+	err := runInitInfra([]string{"--format=terraform", "--output=" + outputPath})
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "creating output file")
+}
+
+func TestRunInitInfraErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args   []string
+		errMsg string
+	}{
+		"missing format": {
+			args:   nil,
+			errMsg: "--format is required",
+		},
+		"unsupported format": {
+			args:   []string{"--format=pulumi"},
+			errMsg: "unsupported format",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := runInitInfra(tc.args)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
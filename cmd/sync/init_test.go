@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
 )
 
 func TestConfigTemplate(t *testing.T) {
@@ -28,15 +30,15 @@ func TestConfigTemplate(t *testing.T) {
 func TestRunInitCreatesConfig(t *testing.T) {
 	// Cannot use t.Parallel() with t.Setenv().
 
-	// Create a temp directory to act as home.
+	// Create a temp directory to act as the config directory's parent.
 	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
+	t.Setenv(config.EnvConfigDir, filepath.Join(tmpHome, "giftbridge"))
 
 	err := runInit()
 	require.NoError(t, err)
 
 	// Check config file was created.
-	configPath := filepath.Join(tmpHome, ".giftbridge", "config.yaml")
+	configPath := filepath.Join(tmpHome, "giftbridge", "config.yaml")
 	data, err := os.ReadFile(configPath)
 	require.NoError(t, err)
 	require.Equal(t, configTemplate, string(data))
@@ -47,7 +49,7 @@ func TestRunInitCreatesConfig(t *testing.T) {
 	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
 
 	// Check directory permissions (0700).
-	dirInfo, err := os.Stat(filepath.Join(tmpHome, ".giftbridge"))
+	dirInfo, err := os.Stat(filepath.Join(tmpHome, "giftbridge"))
 	require.NoError(t, err)
 	require.Equal(t, os.FileMode(0o700), dirInfo.Mode().Perm())
 }
@@ -57,12 +59,12 @@ func TestRunInitFailsIfConfigExists(t *testing.T) {
 
 	// Create a temp directory with existing config.
 	tmpHome := t.TempDir()
-	configDir := filepath.Join(tmpHome, ".giftbridge")
+	configDir := filepath.Join(tmpHome, "giftbridge")
 	require.NoError(t, os.MkdirAll(configDir, 0o700))
 	configPath := filepath.Join(configDir, "config.yaml")
 	require.NoError(t, os.WriteFile(configPath, []byte("existing config"), 0o600))
 
-	t.Setenv("HOME", tmpHome)
+	t.Setenv(config.EnvConfigDir, filepath.Join(tmpHome, "giftbridge"))
 
 	err := runInit()
 
@@ -73,12 +75,12 @@ func TestRunInitFailsIfConfigExists(t *testing.T) {
 func TestRunInitCreatesDirectory(t *testing.T) {
 	// Cannot use t.Parallel() with t.Setenv().
 
-	// Create a temp home without .giftbridge directory.
+	// Create a temp directory without a giftbridge directory.
 	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
+	t.Setenv(config.EnvConfigDir, filepath.Join(tmpHome, "giftbridge"))
 
 	// Verify directory doesn't exist yet.
-	configDir := filepath.Join(tmpHome, ".giftbridge")
+	configDir := filepath.Join(tmpHome, "giftbridge")
 	_, err := os.Stat(configDir)
 	require.True(t, os.IsNotExist(err))
 
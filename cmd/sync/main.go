@@ -3,41 +3,157 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
-
+	"github.com/peteski22/giftbridge/internal/awsclient"
 	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/bootstrap"
 	"github.com/peteski22/giftbridge/internal/config"
 	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/httpclient"
 	"github.com/peteski22/giftbridge/internal/storage"
 	"github.com/peteski22/giftbridge/internal/sync"
+	"github.com/peteski22/giftbridge/internal/version"
+)
+
+// shutdownGracePeriod is how long before the Lambda deadline the sync stops taking new donations.
+// It leaves time for the in-flight donation to finish and the summary to be logged.
+const shutdownGracePeriod = 30 * time.Second
+
+const (
+	// envAzureIdentityEndpoint is set by Azure Functions to the URL of the app's managed identity token endpoint.
+	envAzureIdentityEndpoint = "IDENTITY_ENDPOINT"
+
+	// envAzureIdentityHeader is set by Azure Functions to the secret the managed identity token endpoint expects.
+	envAzureIdentityHeader = "IDENTITY_HEADER"
+
+	// envGoogleMetadataHost overrides the Google Cloud metadata server host, as for the Google Cloud client libraries.
+	envGoogleMetadataHost = "GCE_METADATA_HOST"
 )
 
 func main() {
 	// Check for subcommands first.
 	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
 		switch os.Args[1] {
+		case "arrears-report":
+			if err := runArrearsReport(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "archive-tracker":
+			if err := runArchiveTracker(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
 		case "auth":
 			if err := runBlackbaudAuth(); err != nil {
 				fmt.Fprintln(os.Stderr, formatError(err))
 				os.Exit(1)
 			}
 			return
+		case "backfill":
+			if err := runBackfill(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "bench":
+			if err := runBench(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "config":
+			if err := runConfig(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "forget":
+			if err := runForget(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "gen-fixtures":
+			if err := runGenFixtures(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
 		case "init":
 			if err := runInit(); err != nil {
 				fmt.Fprintln(os.Stderr, formatError(err))
 				os.Exit(1)
 			}
 			return
+		case "init-aws":
+			if err := runInitAWS(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "init-infra":
+			if err := runInitInfra(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "dedupe-report":
+			if err := runDedupeReport(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "reconcile":
+			if err := runReconcile(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "repair-recurring":
+			if err := runRepairRecurring(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "review":
+			if err := runReview(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "status":
+			if err := runStatus(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "statements":
+			if err := runStatements(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
+		case "update":
+			if err := runUpdate(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, formatError(err))
+				os.Exit(1)
+			}
+			return
 		default:
 			fmt.Fprintln(os.Stderr, formatError(fmt.Errorf("unknown subcommand: %s", os.Args[1])))
 			os.Exit(1)
@@ -52,99 +168,358 @@ Usage:
   giftbridge [flags]
 
 Commands:
-  init        Create a local configuration file
-  auth        Authorize with Blackbaud (OAuth flow)
+  init              Create a local configuration file
+  init-aws          Create the SSM parameters and secret in your AWS account
+  init-infra        Generate Terraform or CDK infrastructure definitions
+  auth              Authorize with Blackbaud (OAuth flow)
+  config show       Show the configuration in use, with secrets hidden
+  config validate   Check the local config file, or the Lambda's environment variables with --env
+  dedupe-report     List donors that look duplicated between FundraiseUp and Raiser's Edge NXT
+  arrears-report    List recurring plans whose expected installment has not arrived
+  reconcile         Export donation totals per payment processor payout as CSV
+  repair-recurring  Link the payments of a recurring plan to its recurring gift
+  forget            Erase a FundraiseUp supporter from the donation tracker
+  archive-tracker   Copy tracked donations to S3, one JSON Lines file per month
+  backfill          Process historical donations in parallel on the deployed Lambda, in batches from S3
+  statements        Export year-end gift totals per constituent as CSV
+  status            Show the last sync, pending backlog and recent runs of the deployed sync
+  review            List donations held back by the gift checks, and release them to be tried again
+  update            Replace this binary with the latest release
+  bench             Measure sync throughput against in-memory fakes of FundraiseUp and Blackbaud
+  gen-fixtures      Write FundraiseUp donations to a test fixture, with donors' personal data faked
 
 Flags:
 `)
 		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, `
+		fmt.Fprint(os.Stderr, `
 When run without flags or commands, starts as an AWS Lambda handler.
 
 Examples:
-  # Set up local configuration (~/.giftbridge/config.yaml)
+  # Set up local configuration (config.yaml in ~/.config/giftbridge, %APPDATA%\giftbridge
+  # or GIFTBRIDGE_CONFIG_DIR)
   giftbridge init
 
-  # Authorize with Blackbaud (saves token to ~/.giftbridge/token)
+  # Authorize with Blackbaud (saves the token alongside config.yaml)
   giftbridge auth
 
+  # List every problem in the local config file before running a sync
+  giftbridge config validate
+
   # Preview what would be synced locally (uses file-based config and token)
   giftbridge --dry-run --since=2024-01-01T00:00:00Z
 
+  # Preview 50 random donations from a large window, repeatably
+  giftbridge --dry-run --since=2020-01-01T00:00:00Z --sample=50 --seed=7
+
   # Run a real sync locally (uses file-based config and token)
   giftbridge --since=2024-01-01T00:00:00Z
 
+  # Run a real sync locally, then check each new gift was stored as sent
+  giftbridge --since=2024-01-01T00:00:00Z --verify
+
+  # Try out a new configuration, stopping at the first donation that fails
+  giftbridge --since=2024-01-01T00:00:00Z --fail-fast
+
+  # Generate Terraform for the AWS infrastructure
+  giftbridge init-infra --format=terraform --output=main.tf
+
+  # Export March 2024 payout totals to match against bank deposits
+  giftbridge reconcile --from=2024-03-01 --to=2024-04-01 --output=payouts-2024-03.csv
+
+  # List recurring plans more than a week behind on their installments
+  giftbridge arrears-report --grace=168h
+
+  # Preview, then fix, the links between the gifts of a recurring plan
+  giftbridge repair-recurring --plan=rec_XXXXXXXX --dry-run
+  giftbridge repair-recurring --plan=rec_XXXXXXXX
+
+  # Erase a donor who asked to be forgotten, then erase the listed records in Raiser's Edge NXT
+  giftbridge forget --supporter=sup_XXXXXXXX --dry-run
+  giftbridge forget --supporter=sup_XXXXXXXX
+
+  # Export 2024 gift totals per constituent for year-end statements
+  giftbridge statements --year=2024 --output=statements-2024.csv
+
+  # Archive 2023 tracker records to S3 before they expire from the table
+  giftbridge archive-tracker --bucket=my-giftbridge-archive --from=2023-01 --to=2024-01
+
+  # Migrate every donation of 2020 to 2024 in parallel, then check progress and resume unfinished batches
+  giftbridge backfill --dispatch --bucket=my-giftbridge-backfills --since=2020-01-01T00:00:00Z \
+    --until=2025-01-01T00:00:00Z
+  giftbridge backfill --report=20250101T090000Z --bucket=my-giftbridge-backfills
+  giftbridge backfill --resume=20250101T090000Z --bucket=my-giftbridge-backfills
+
+  # Check recent runs of the deployed sync without CloudWatch access
+  giftbridge status --stack-name=giftbridge
+
+  # List donations held back by the gift checks, then have the next run try two of them again
+  giftbridge review
+  giftbridge review --release=don_XXXXXXXX,don_YYYYYYYY
+
+  # Write March 2024 donations to a test fixture, with donors' names, emails and addresses faked
+  giftbridge gen-fixtures --since=2024-03-01T00:00:00Z --until=2024-04-01T00:00:00Z
+
+  # Compare sync throughput with 1 and 8 batches at once, with API calls taking 100ms
+  giftbridge bench --donations=5000 --concurrency=1,8 --latency=100ms
+
   # Run as Lambda handler (requires AWS infrastructure)
   giftbridge
 `)
 	}
 
 	dryRun := flag.Bool("dry-run", false, "preview what would happen without making changes")
+	failFast := flag.Bool("fail-fast", false, "stop at the first donation that fails, rather than carrying on")
 	since := flag.String("since", "", "override last sync time (RFC3339 format)")
+	sample := flag.Int("sample", 0, "with --dry-run, process only this many randomly chosen donations")
+	seed := flag.Int64("seed", 0, "seed choosing the --sample donations (default: random)")
+	verify := flag.Bool("verify", false, "after a real run, re-read each created gift and report differences")
+	showVersion := flag.Bool("version", false, "print the version and exit")
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println("giftbridge", version.String())
+		return
+	}
+
 	// If running locally (flags provided), run directly with human-readable logs.
-	// Otherwise, start Lambda handler with JSON logs.
-	if *dryRun || *since != "" {
-		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-			Level: slog.LevelInfo,
-		}))
-		slog.SetDefault(logger)
-
-		if err := runLocal(*dryRun, *since); err != nil {
+	// Otherwise, start the function handler with JSON logs.
+	if *dryRun || *failFast || *since != "" || *sample != 0 || *verify {
+		opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+		if *dryRun {
+			// Leave out timestamps, so the logs of two dry runs over the same donations can be compared with diff.
+			opts.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+				if attr.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return attr
+			}
+		}
+		logger := slog.New(slog.NewTextHandler(os.Stderr, opts))
+		slog.SetDefault(logger.With("version", version.Version))
+
+		if err := runLocal(*dryRun, *failFast, *since, *sample, *seed, *verify); err != nil {
 			fmt.Fprintln(os.Stderr, formatError(err))
 			os.Exit(1)
 		}
 		return
 	}
 
-	// Lambda mode: use JSON logs for CloudWatch.
+	// Function mode: use JSON logs for CloudWatch, Cloud Logging or Azure Monitor.
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
-	slog.SetDefault(logger)
+	slog.SetDefault(logger.With("version", version.Version))
 
-	lambda.Start(handler)
+	proxy, err := config.LoadProxy()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, formatError(err))
+		os.Exit(1)
+	}
+
+	tlsCfg, err := config.LoadTLS()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, formatError(err))
+		os.Exit(1)
+	}
+
+	tlsOpts, err := tlsOptions(context.Background(), tlsCfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, formatError(err))
+		os.Exit(1)
+	}
+
+	// The transport outlives each invocation so warm starts reuse open API connections.
+	transport, err := httpclient.NewTransport(append(proxyOptions(proxy), tlsOpts...)...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, formatError(err))
+		os.Exit(1)
+	}
+
+	if err := serve(transport); err != nil {
+		fmt.Fprintln(os.Stderr, formatError(err))
+		os.Exit(1)
+	}
 }
 
-// handler is the AWS Lambda entry point that runs a sync cycle.
-func handler(ctx context.Context) error {
+// handler runs a sync cycle for each AWS Lambda, Cloud Functions or Azure Functions invocation, returning its result
+// for the invocation's response. The result is returned with the error when a sync that ran reported errors.
+// Both API clients send requests through transport, sharing its connections.
+func handler(ctx context.Context, transport http.RoundTripper) (*sync.Result, error) {
 	slog.InfoContext(ctx, "starting sync")
 
+	// Stop taking new donations before the Lambda deadline, so the in-flight one finishes.
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-shutdownGracePeriod))
+		defer cancel()
+	}
+
 	// Load configuration from environment variables.
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return nil, fmt.Errorf("loading config: %w", err)
 	}
 
-	// Initialize AWS SDK.
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	// Create AWS service clients, applying any region and endpoint overrides.
+	awsClients, err := awsclient.New(ctx, cfg.AWS)
 	if err != nil {
-		return fmt.Errorf("loading AWS config: %w", err)
+		return nil, fmt.Errorf("creating AWS clients: %w", err)
 	}
 
-	// Create AWS service clients.
-	secretsClient := secretsmanager.NewFromConfig(awsCfg)
-	ssmClient := ssm.NewFromConfig(awsCfg)
-
 	// Create storage implementations.
-	stateStore, err := storage.NewStateStore(ssmClient, cfg.SSM.ParameterName)
+	stateStore, err := newLambdaStateStore(cfg, awsClients)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenStore, err := newLambdaTokenStore(cfg, awsClients, transport)
+	if err != nil {
+		return nil, err
+	}
+	// Record refresh token rotations for the health snapshot's token age.
+	tokenStore := &rotationRecordingTokenStore{TokenStore: refreshTokenStore}
+
+	syncService, blackbaudClient, err := newLambdaSyncService(cfg, awsClients, transport, stateStore, tokenStore, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := syncService.Run(ctx)
+	warnSecondaryKey(ctx, blackbaudClient)
+	// Only the SSM state store keeps a health snapshot.
+	if ssmStateStore, ok := stateStore.(*storage.StateStore); ok {
+		publishHealth(ctx, ssmStateStore, tokenStore, result, err)
+	}
+	if err != nil {
+		if result != nil && result.Interrupted {
+			slog.WarnContext(ctx, "sync interrupted", summaryAttrs(result)...)
+		}
+		return result, fmt.Errorf("running sync: %w", err)
+	}
+
+	slog.InfoContext(ctx, "sync complete", summaryAttrs(result)...)
+
+	// Return error if any donations failed.
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("sync completed with %d errors", len(result.Errors))
+	}
+
+	return result, nil
+}
+
+// newLambdaStateStore creates the store of the sync state in the configured storage provider.
+func newLambdaStateStore(cfg *config.Settings, awsClients *awsclient.Clients) (sync.StateStore, error) {
+	var (
+		stateStore sync.StateStore
+		err        error
+	)
+	switch cfg.Storage.Provider {
+	case config.StorageProviderAzure:
+		identity := azureManagedIdentity(cfg.Storage.Azure)
+		tokens := storage.NewAzureTokenSource(nil, identity, storage.AzureStorageResource)
+		stateStore, err = storage.NewAzureTableStateStore(tokens, cfg.Storage.Azure.TableEndpoint,
+			cfg.Storage.Azure.TableName)
+	case config.StorageProviderGCP:
+		tokens := storage.NewGoogleTokenSource(nil, os.Getenv(envGoogleMetadataHost))
+		stateStore, err = storage.NewFirestoreStateStore(tokens, cfg.Storage.GCP.Project,
+			cfg.Storage.GCP.FirestoreDocument)
+	default:
+		stateStore, err = storage.NewStateStore(awsClients.SSM, cfg.SSM.ParameterName)
+	}
 	if err != nil {
-		return fmt.Errorf("creating state store: %w", err)
+		return nil, fmt.Errorf("creating state store: %w", err)
 	}
+	return stateStore, nil
+}
 
-	tokenStore, err := storage.NewTokenStore(secretsClient, cfg.Blackbaud.RefreshTokenSecretARN)
+// newLambdaTokenStore creates the store of the Blackbaud refresh token: a Vault secret when a Vault address is
+// configured, otherwise the secret in the configured storage provider. Requests to Vault go through transport,
+// like the API clients'.
+func newLambdaTokenStore(
+	cfg *config.Settings,
+	awsClients *awsclient.Clients,
+	transport http.RoundTripper,
+) (blackbaud.TokenStore, error) {
+	if cfg.Vault.Address != "" {
+		tokenStore, err := storage.NewVaultTokenStore(cfg.Vault.Address, cfg.Vault.Token, cfg.Vault.SecretPath,
+			storage.WithVaultHTTPClient(&http.Client{Timeout: httpTimeout, Transport: transport}),
+			storage.WithVaultKVMount(cfg.Vault.KVMount),
+			storage.WithVaultNamespace(cfg.Vault.Namespace))
+		if err != nil {
+			return nil, fmt.Errorf("creating Vault token store: %w", err)
+		}
+		return tokenStore, nil
+	}
+
+	var (
+		tokenStore blackbaud.TokenStore
+		err        error
+	)
+	switch cfg.Storage.Provider {
+	case config.StorageProviderAzure:
+		identity := azureManagedIdentity(cfg.Storage.Azure)
+		tokens := storage.NewAzureTokenSource(nil, identity, storage.AzureKeyVaultResource)
+		tokenStore, err = storage.NewKeyVaultTokenStore(tokens, cfg.Storage.Azure.KeyVaultURL,
+			cfg.Storage.Azure.RefreshTokenSecret)
+	case config.StorageProviderGCP:
+		tokens := storage.NewGoogleTokenSource(nil, os.Getenv(envGoogleMetadataHost))
+		tokenStore, err = storage.NewGoogleSecretTokenStore(tokens, cfg.Storage.GCP.Project,
+			cfg.Storage.GCP.RefreshTokenSecret)
+	default:
+		tokenStore, err = storage.NewTokenStore(awsClients.SecretsManager, cfg.Blackbaud.RefreshTokenSecretARN)
+	}
 	if err != nil {
-		return fmt.Errorf("creating token store: %w", err)
+		return nil, fmt.Errorf("creating token store: %w", err)
+	}
+	return tokenStore, nil
+}
+
+// azureManagedIdentity returns the app's managed identity, from the variables Azure Functions sets.
+func azureManagedIdentity(cfg config.AzureStorage) storage.AzureManagedIdentity {
+	return storage.AzureManagedIdentity{
+		ClientID: cfg.ClientID,
+		Endpoint: os.Getenv(envAzureIdentityEndpoint),
+		Header:   os.Getenv(envAzureIdentityHeader),
+	}
+}
+
+// newLambdaSyncService creates the Lambda's sync service from its environment configuration, keeping state in
+// stateStore, with the Blackbaud client it syncs to. When donationIDs is set, the service processes only those
+// donations, as for a backfill batch.
+func newLambdaSyncService(
+	cfg *config.Settings,
+	awsClients *awsclient.Clients,
+	transport http.RoundTripper,
+	stateStore sync.StateStore,
+	tokenStore blackbaud.TokenStore,
+	donationIDs []string,
+) (*sync.Service, *blackbaud.Client, error) {
+	// Donation tracking is optional; Blackbaud remains the source of truth without it.
+	var tracker sync.DonationTracker
+	if cfg.Tracker.TableName != "" {
+		retention := time.Duration(cfg.Tracker.RetentionDays) * 24 * time.Hour
+		var err error
+		tracker, err = storage.NewDonationTracker(awsClients.DynamoDB, cfg.Tracker.TableName,
+			storage.WithRetention(retention))
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating donation tracker: %w", err)
+		}
 	}
 
 	// Create API clients.
-	fundraiseupClient, err := fundraiseup.NewClient(
-		cfg.FundraiseUp.APIKey,
+	fundraiseupOpts := append(
+		donationFetchOptions(cfg.FundraiseUp.PageSize, cfg.FundraiseUp.Status, cfg.FundraiseUp.CampaignID),
 		fundraiseup.WithBaseURL(cfg.FundraiseUp.BaseURL),
+		fundraiseup.WithMaxResponseSize(cfg.Sync.MaxResponseBytes),
+		fundraiseup.WithTransport(transport),
+		fundraiseup.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)),
 	)
+	if cfg.FundraiseUp.StrictDecode {
+		fundraiseupOpts = append(fundraiseupOpts, fundraiseup.WithStrictDecoding())
+	}
+	fundraiseupClient, err := donationSource(cfg.FundraiseUp.APIKey, cfg.FundraiseUp.Accounts, fundraiseupOpts)
 	if err != nil {
-		return fmt.Errorf("creating FundraiseUp client: %w", err)
+		return nil, nil, err
 	}
 
 	blackbaudClient, err := blackbaud.NewClient(
@@ -154,62 +529,111 @@ func handler(ctx context.Context) error {
 			SubscriptionKey: cfg.Blackbaud.SubscriptionKey,
 			TokenStore:      tokenStore,
 		},
-		blackbaud.WithBaseURL(cfg.Blackbaud.APIBaseURL),
+		append(
+			blackbaudOptions(cfg.Blackbaud.HedgeDelay, cfg.Blackbaud.SecondarySubscriptionKey),
+			blackbaud.WithBaseURL(cfg.Blackbaud.APIBaseURL),
+			blackbaud.WithMaxResponseSize(cfg.Sync.MaxResponseBytes),
+			blackbaud.WithTransport(transport),
+			blackbaud.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)),
+		)...,
 	)
 	if err != nil {
-		return fmt.Errorf("creating Blackbaud client: %w", err)
+		return nil, nil, fmt.Errorf("creating Blackbaud client: %w", err)
 	}
 
-	// Create and run sync service.
+	// Create the sync service.
 	syncService, err := sync.New(sync.Config{
-		Blackbaud:    blackbaudClient,
-		FundraiseUp:  fundraiseupClient,
-		GiftDefaults: cfg.GiftDefaults,
-		Logger:       slog.Default(),
-		StateStore:   stateStore,
+		Blackbaud:           blackbaudClient,
+		CampaignWindows:     cfg.FundraiseUp.CampaignWindows,
+		ChargebackNoteType:  cfg.Tracker.ChargebackNoteType,
+		ChargebackStatus:    cfg.Tracker.ChargebackStatus,
+		CommentScrubbing:    cfg.CommentScrubbing,
+		ConstituentDefaults: cfg.ConstituentDefaults,
+		DeletedGiftCheckAge: time.Duration(cfg.Tracker.DeletedGiftCheckDays) * 24 * time.Hour,
+		DeletedGiftPolicy:   cfg.Tracker.DeletedGiftPolicy,
+		DonationIDs:         donationIDs,
+		EmailNormalization:  cfg.EmailNormalization,
+		FailFast:            cfg.Sync.FailFast,
+		FetchOverlap:        cfg.FundraiseUp.FetchOverlap,
+		FundraiseUp:         fundraiseupClient,
+		GiftDefaults:        cfg.GiftDefaults,
+		Logger:              slog.Default(),
+		MaxDonationSize:     cfg.Sync.MaxDonationBytes,
+		NameNormalization:   cfg.NameNormalization,
+		PlanChangeNoteType:  cfg.Tracker.PlanChangeNoteType,
+		QuotaReserve:        cfg.Blackbaud.QuotaReserve,
+		ReconcileOnly:       cfg.Tracker.ReconcileOnly,
+		ReconcileWindow:     time.Duration(cfg.Tracker.ReconcileDays) * 24 * time.Hour,
+		StateStore:          stateStore,
+		SupporterCacheTTL:   time.Duration(cfg.Tracker.SupporterCacheDays) * 24 * time.Hour,
+		Tracker:             tracker,
+		UpdateComments:      cfg.Tracker.UpdateComments,
 	})
 	if err != nil {
-		return fmt.Errorf("creating sync service: %w", err)
+		return nil, nil, fmt.Errorf("creating sync service: %w", err)
 	}
 
-	result, err := syncService.Run(ctx)
-	if err != nil {
-		return fmt.Errorf("running sync: %w", err)
-	}
+	return syncService, blackbaudClient, nil
+}
 
-	slog.InfoContext(ctx, "sync complete",
+// summaryAttrs returns the structured log attributes summarising a sync result.
+func summaryAttrs(result *sync.Result) []any {
+	attrs := []any{
 		"donations_processed", result.DonationsProcessed,
 		"constituents_created", result.ConstituentsCreated,
 		"gifts_created", result.GiftsCreated,
 		"gifts_updated", result.GiftsUpdated,
+		"gifts_charged_back", result.GiftsChargedBack,
+		"donations_skipped_campaign", result.DonationsSkippedCampaign,
 		"errors", len(result.Errors),
-	)
-
-	// Return error if any donations failed.
-	if len(result.Errors) > 0 {
-		return fmt.Errorf("sync completed with %d errors", len(result.Errors))
+		"paused_for_quota", result.PausedForQuota,
+		"stopped_on_error", result.StoppedOnError,
 	}
-
-	return nil
+	if result.BlackbaudQuota != nil {
+		attrs = append(attrs,
+			"blackbaud_quota_remaining", result.BlackbaudQuota.Remaining,
+			"blackbaud_quota_limit", result.BlackbaudQuota.Limit)
+	}
+	return attrs
 }
 
 // runLocal executes a sync using local configuration and file-based token storage.
 // This mode is used for dry-run testing without AWS infrastructure.
-func runLocal(dryRun bool, sinceStr string) error {
-	ctx := context.Background()
+// A positive sample processes that many randomly chosen donations, chosen by seed, or a random seed when zero.
+// With verify, each gift created is read back afterwards and compared with what was sent.
+// With failFast, the sync stops at the first donation that fails.
+func runLocal(dryRun, failFast bool, sinceStr string, sample int, seed int64, verify bool) error {
+	if sample < 0 {
+		return errors.New("--sample must not be negative")
+	}
+	if sample > 0 && !dryRun {
+		return errors.New("--sample requires --dry-run")
+	}
+	if verify && dryRun {
+		return errors.New("--verify cannot be used with --dry-run, which creates no gifts")
+	}
+
+	ctx, stop := shutdownContext(context.Background())
+	defer stop()
 
 	if dryRun {
 		fmt.Println("=== DRY-RUN MODE ===")
 		fmt.Println("No changes will be made to Blackbaud Raiser's Edge NXT")
 		fmt.Println()
 	}
+	if sample > 0 {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		fmt.Printf("Sampling %d donations (repeat with --seed=%d)\n\n", sample, seed)
+	}
 
 	// Parse since time (required for dry-run without AWS).
 	var sinceTime time.Time
 	if sinceStr != "" {
 		t, err := time.Parse(time.RFC3339, sinceStr)
 		if err != nil {
-			return fmt.Errorf("parsing since time: %w", err)
+			return fmt.Errorf("--since must be an RFC3339 time, such as 2024-01-01T00:00:00Z: %w", err)
 		}
 		sinceTime = t
 		fmt.Printf("Using since: %s\n\n", t.Format(time.RFC3339))
@@ -225,52 +649,65 @@ func runLocal(dryRun bool, sinceStr string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	// Get token path.
-	tokenPath, err := config.TokenFilePath()
-	if err != nil {
-		return fmt.Errorf("getting token path: %w", err)
-	}
+	// Use noop state store for local runs.
+	stateStore := storage.NewNoopStateStore(sinceTime)
 
-	// Create local storage implementations (no AWS needed for dry-run).
-	tokenStore, err := storage.NewFileTokenStore(tokenPath)
+	// Create API clients sharing one transport, so connections are reused between them.
+	transport, err := newLocalTransport(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("creating token store: %w", err)
+		return err
 	}
 
-	// Use noop state store for local runs.
-	stateStore := storage.NewNoopStateStore(sinceTime)
-
-	// Create API clients.
-	fundraiseupClient, err := fundraiseup.NewClient(cfg.FundraiseUp.APIKey)
+	fundraiseupOpts := append(
+		donationFetchOptions(cfg.FundraiseUp.PageSize, cfg.FundraiseUp.Status, cfg.FundraiseUp.CampaignID),
+		fundraiseup.WithTransport(transport),
+		fundraiseup.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)),
+	)
+	if cfg.FundraiseUp.StrictDecode {
+		fundraiseupOpts = append(fundraiseupOpts, fundraiseup.WithStrictDecoding())
+	}
+	fundraiseupClient, err := donationSource(cfg.FundraiseUp.APIKey, cfg.FundraiseUp.Accounts, fundraiseupOpts)
 	if err != nil {
-		return fmt.Errorf("creating FundraiseUp client: %w", err)
+		return err
 	}
 
-	blackbaudClient, err := blackbaud.NewClient(blackbaud.Config{
-		ClientID:        cfg.Blackbaud.ClientID,
-		ClientSecret:    cfg.Blackbaud.ClientSecret,
-		SubscriptionKey: cfg.Blackbaud.SubscriptionKey,
-		TokenStore:      tokenStore,
-	})
+	blackbaudClient, err := newLocalBlackbaudClient(ctx, cfg, blackbaud.WithTransport(transport))
 	if err != nil {
-		return fmt.Errorf("creating Blackbaud client: %w", err)
+		return err
 	}
 
 	// Create and run sync service.
 	syncService, err := sync.New(sync.Config{
-		Blackbaud:    blackbaudClient,
-		DryRun:       dryRun,
-		FundraiseUp:  fundraiseupClient,
-		GiftDefaults: cfg.GiftDefaults,
-		Logger:       slog.Default(),
-		StateStore:   stateStore,
+		Blackbaud:           blackbaudClient,
+		CampaignWindows:     cfg.FundraiseUp.CampaignWindows,
+		CommentScrubbing:    cfg.CommentScrubbing,
+		ConstituentDefaults: cfg.ConstituentDefaults,
+		DryRun:              dryRun,
+		EmailNormalization:  cfg.EmailNormalization,
+		FailFast:            failFast,
+		FetchOverlap:        cfg.FundraiseUp.FetchOverlap,
+		FundraiseUp:         fundraiseupClient,
+		GiftCacheSize:       cfg.Blackbaud.GiftCacheSize,
+		GiftDefaults:        cfg.GiftDefaults,
+		Logger:              slog.Default(),
+		NameNormalization:   cfg.NameNormalization,
+		QuotaReserve:        cfg.Blackbaud.QuotaReserve,
+		Sample:              sample,
+		SampleSeed:          seed,
+		StateStore:          stateStore,
+		Verify:              verify,
 	})
 	if err != nil {
 		return fmt.Errorf("creating sync service: %w", err)
 	}
 
 	result, err := syncService.Run(ctx)
+	warnSecondaryKey(ctx, blackbaudClient)
 	if err != nil {
+		// Show what was synced before the interruption.
+		if result != nil && result.Interrupted {
+			printSummary(result, sinceTime)
+		}
 		return fmt.Errorf("running sync: %w", err)
 	}
 
@@ -285,6 +722,262 @@ func runLocal(dryRun bool, sinceStr string) error {
 	return nil
 }
 
+// shutdownContext returns a context that is cancelled on the first SIGINT or SIGTERM.
+// The sync then finishes the in-flight donation and stops; a second signal terminates immediately.
+func shutdownContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			// Restore default handling so a second signal kills the process.
+			signal.Stop(signals)
+			fmt.Fprintf(os.Stderr,
+				"\nReceived %s, stopping after the current donation (repeat to quit immediately)...\n", sig)
+			cancel()
+		case <-ctx.Done():
+			signal.Stop(signals)
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// donationSource returns the FundraiseUp client for apiKey, merged with a client for each further account
+// when any are configured.
+func donationSource(
+	apiKey string,
+	accounts []config.FundraiseUpAccount,
+	opts []fundraiseup.Option,
+) (sync.DonationSource, error) {
+	client, err := fundraiseup.NewClient(apiKey, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating FundraiseUp client: %w", err)
+	}
+	if len(accounts) == 0 {
+		return client, nil
+	}
+
+	merged := []fundraiseup.Account{{Client: client}}
+	for _, account := range accounts {
+		accountClient, err := fundraiseup.NewClient(account.APIKey, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("creating FundraiseUp client for account %q: %w", account.Name, err)
+		}
+		merged = append(merged, fundraiseup.Account{Client: accountClient, Name: account.Name})
+	}
+	source, err := fundraiseup.NewAccounts(merged...)
+	if err != nil {
+		return nil, fmt.Errorf("merging FundraiseUp accounts: %w", err)
+	}
+	return source, nil
+}
+
+// donationFetchOptions returns the FundraiseUp client options for the configured page size and donation filters.
+func donationFetchOptions(pageSize int, status string, campaignID string) []fundraiseup.Option {
+	opts := []fundraiseup.Option{fundraiseup.WithPageSize(pageSize)}
+	if status != "" {
+		opts = append(opts, fundraiseup.WithStatus(status))
+	}
+	if campaignID != "" {
+		opts = append(opts, fundraiseup.WithCampaign(campaignID))
+	}
+	return opts
+}
+
+// proxyOptions returns the transport options for the configured proxy, if any.
+// Without one, the transport uses the HTTPS_PROXY and NO_PROXY environment variables.
+func proxyOptions(proxy config.Proxy) []httpclient.Option {
+	if proxy.URL == "" {
+		return nil
+	}
+	return []httpclient.Option{httpclient.WithProxy(proxy.URL, proxy.Bypass)}
+}
+
+// tlsOptions returns the transport options for the configured CA bundle and client certificate, if any.
+// Certificates stored in Secrets Manager are read using the default AWS credentials.
+func tlsOptions(ctx context.Context, cfg config.TLS) ([]httpclient.Option, error) {
+	if cfg == (config.TLS{}) {
+		return nil, nil
+	}
+
+	var secrets storage.SecretsManagerAPI
+	read := func(source string) ([]byte, error) {
+		if !config.IsSecretARN(source) {
+			data, err := os.ReadFile(source)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", source, err)
+			}
+			return data, nil
+		}
+
+		if secrets == nil {
+			awsClients, err := newLocalAWSClients(ctx)
+			if err != nil {
+				return nil, err
+			}
+			secrets = awsClients.SecretsManager
+		}
+		value, err := storage.SecretString(ctx, secrets, source)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", source, err)
+		}
+		return []byte(value), nil
+	}
+
+	var opts []httpclient.Option
+	if cfg.CABundle != "" {
+		bundle, err := read(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("loading CA bundle: %w", err)
+		}
+		opts = append(opts, httpclient.WithCABundle(bundle))
+	}
+	if cfg.ClientCert != "" {
+		cert, err := read(cfg.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		key, err := read(cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client key: %w", err)
+		}
+		opts = append(opts, httpclient.WithClientCertificate(cert, key))
+	}
+
+	return opts, nil
+}
+
+// blackbaudOptions returns the Blackbaud client options for the configured hedge delay and secondary
+// subscription key, if any.
+func blackbaudOptions(hedgeDelay time.Duration, secondaryKey string) []blackbaud.Option {
+	var opts []blackbaud.Option
+	if hedgeDelay > 0 {
+		opts = append(opts, blackbaud.WithHedgeDelay(hedgeDelay))
+	}
+	if secondaryKey != "" {
+		opts = append(opts, blackbaud.WithSecondarySubscriptionKey(secondaryKey))
+	}
+	return opts
+}
+
+// warnSecondaryKey logs when Blackbaud rejected the primary subscription key during a run and the secondary key
+// was used instead, so the primary key is replaced before the secondary one needs regenerating too.
+func warnSecondaryKey(ctx context.Context, client *blackbaud.Client) {
+	if client.UsingSecondarySubscriptionKey() {
+		slog.WarnContext(ctx, "Blackbaud rejected the primary subscription key, so the secondary key was used",
+			"setting", config.EnvBlackbaudSubscriptionKey)
+	}
+}
+
+// newLocalBlackbaudClient creates a Blackbaud client using the local config and the token saved by 'giftbridge auth'.
+func newLocalBlackbaudClient(
+	ctx context.Context,
+	cfg *config.LocalConfig,
+	opts ...blackbaud.Option,
+) (*blackbaud.Client, error) {
+	// Get token path.
+	tokenPath, err := config.TokenFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("getting token path: %w", err)
+	}
+
+	// Create local storage implementations (no AWS needed).
+	tokenStore, err := storage.NewFileTokenStore(tokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("creating token store: %w", err)
+	}
+
+	transport, err := newLocalTransport(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Options passed by the caller come last, so they can replace the transport.
+	clientOpts := append(
+		blackbaudOptions(cfg.Blackbaud.HedgeDelay, cfg.Blackbaud.SecondarySubscriptionKey),
+		blackbaud.WithTransport(transport),
+		blackbaud.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)),
+	)
+	client, err := blackbaud.NewClient(blackbaud.Config{
+		ClientID:        cfg.Blackbaud.ClientID,
+		ClientSecret:    cfg.Blackbaud.ClientSecret,
+		SubscriptionKey: cfg.Blackbaud.SubscriptionKey,
+		TokenStore:      tokenStore,
+	}, append(clientOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("creating Blackbaud client: %w", err)
+	}
+
+	return client, nil
+}
+
+// newLocalTransport creates an HTTP transport for API requests, using the proxy and certificates
+// in the local config, if any.
+func newLocalTransport(ctx context.Context, cfg *config.LocalConfig) (*http.Transport, error) {
+	tlsOpts, err := tlsOptions(ctx, cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := httpclient.NewTransport(append(proxyOptions(cfg.Proxy), tlsOpts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP transport: %w", err)
+	}
+
+	return transport, nil
+}
+
+// newLocalDonationTracker creates a donation tracker for the named table using the default AWS credentials.
+func newLocalDonationTracker(ctx context.Context, tableName string) (*storage.DonationTracker, error) {
+	awsClients, err := newLocalAWSClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDonationTracker(awsClients, tableName)
+}
+
+// newLocalAWSClients creates AWS clients from the local environment's AWS configuration.
+func newLocalAWSClients(ctx context.Context) (*awsclient.Clients, error) {
+	awsCfg, err := config.LoadAWS()
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+
+	awsClients, err := awsclient.New(ctx, awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating AWS clients: %w", err)
+	}
+
+	return awsClients, nil
+}
+
+// newDonationTracker creates a donation tracker for tableName using awsClients.
+func newDonationTracker(awsClients *awsclient.Clients, tableName string) (*storage.DonationTracker, error) {
+	tracker, err := storage.NewDonationTracker(awsClients.DynamoDB, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("creating donation tracker: %w", err)
+	}
+
+	return tracker, nil
+}
+
+// trackerTableName returns table if set, then the configured tracker table, otherwise the table init-aws
+// creates for stackName.
+func trackerTableName(stackName string, table string) string {
+	if table != "" {
+		return table
+	}
+	if configured := config.LoadResourceNames().TrackerTableName; configured != "" {
+		return configured
+	}
+	return bootstrap.NewResources(stackName).DonationTableName
+}
+
 // printSummary outputs a human-readable summary of the sync results to stdout.
 func printSummary(result *sync.Result, since time.Time) {
 	fmt.Println()
@@ -294,7 +987,12 @@ func printSummary(result *sync.Result, since time.Time) {
 		fmt.Println("=== Sync Summary ===")
 	}
 
-	fmt.Printf("Donations processed: %d\n", result.DonationsProcessed)
+	if result.DonationsSampledFrom > 0 {
+		fmt.Printf("Donations processed: %d (sampled from %d)\n",
+			result.DonationsProcessed, result.DonationsSampledFrom)
+	} else {
+		fmt.Printf("Donations processed: %d\n", result.DonationsProcessed)
+	}
 	fmt.Printf("Constituents: %d would be created, %d exist\n",
 		result.ConstituentsCreated, result.ConstituentsExisting)
 
@@ -306,11 +1004,55 @@ func printSummary(result *sync.Result, since time.Time) {
 		giftsSummary += fmt.Sprintf(", %d skipped (exists)", result.GiftsSkippedExisting)
 	}
 	fmt.Println(giftsSummary)
+	if result.GiftsSkippedExisting > 0 {
+		fmt.Printf("Existing gifts found by: %d lookup ID, %d origin, %d tracker\n",
+			result.GiftsSkippedExistingBy[sync.DuplicateLookupID],
+			result.GiftsSkippedExistingBy[sync.DuplicateOrigin],
+			result.GiftsSkippedExistingBy[sync.DuplicateTracker])
+	}
+	if result.DonationsSkippedTest > 0 {
+		fmt.Printf("Test-mode donations skipped: %d\n", result.DonationsSkippedTest)
+	}
+	printCampaigns(result)
 
 	if len(result.Errors) > 0 {
 		fmt.Printf("Errors: %d\n", len(result.Errors))
 	}
 
+	if quota := result.BlackbaudQuota; quota != nil {
+		quotaSummary := fmt.Sprintf("Blackbaud quota: %d calls remaining", quota.Remaining)
+		if quota.Limit > 0 {
+			quotaSummary = fmt.Sprintf("Blackbaud quota: %d of %d calls remaining", quota.Remaining, quota.Limit)
+		}
+		if !quota.ResetAt.IsZero() {
+			quotaSummary += fmt.Sprintf(" (resets %s)", quota.ResetAt.Local().Format(time.RFC3339))
+		}
+		fmt.Println(quotaSummary)
+	}
+	if result.PausedForQuota {
+		fmt.Println("Paused: Blackbaud quota fell below the reserve. Remaining donations will be processed next run.")
+	}
+	if result.StoppedOnError {
+		fmt.Println("Stopped: --fail-fast stopped the sync at the first donation that failed.")
+	}
+	if result.Interrupted {
+		fmt.Println("Interrupted: the sync was stopped before all donations were processed.")
+		if !since.IsZero() {
+			fmt.Printf("Run again with --since=%s to finish; gifts already synced are skipped.\n",
+				since.Format(time.RFC3339))
+		}
+	}
+
+	printTiming(result)
+	printVerification(result)
+
+	if len(result.Warnings) > 0 {
+		fmt.Printf("Warnings: %d\n", len(result.Warnings))
+		for _, warning := range result.Warnings {
+			fmt.Printf("  - %s\n", warning)
+		}
+	}
+
 	if result.DryRun {
 		fmt.Println()
 		fmt.Println("To run for real, deploy to AWS and run without --dry-run flag.")
@@ -320,6 +1062,52 @@ func printSummary(result *sync.Result, since time.Time) {
 	}
 }
 
+// printCampaigns outputs the donations of each FundraiseUp campaign, when campaign windows are configured.
+func printCampaigns(result *sync.Result) {
+	if len(result.Campaigns) == 0 {
+		return
+	}
+
+	fmt.Println("By campaign:")
+	for _, campaignID := range slices.Sorted(maps.Keys(result.Campaigns)) {
+		counts := result.Campaigns[campaignID]
+		if campaignID == "" {
+			campaignID = "(no campaign)"
+		}
+		fmt.Printf("  - %s: %d donations, %d gifts created, %d outside window\n",
+			campaignID, counts.Donations, counts.GiftsCreated, counts.SkippedOutsideWindow)
+	}
+}
+
+// printVerification outputs the gifts read back after the run and any fields stored differently from what was sent.
+func printVerification(result *sync.Result) {
+	if result.GiftsVerified == 0 && len(result.Discrepancies) == 0 {
+		return
+	}
+
+	fmt.Printf("Verified: %d gifts, %d differences\n", result.GiftsVerified, len(result.Discrepancies))
+	for _, d := range result.Discrepancies {
+		fmt.Printf("  - donation %s, gift %s: %s sent %q, stored %q\n", d.DonationID, d.GiftID, d.Field, d.Want, d.Got)
+	}
+}
+
+// printTiming outputs where the sync spent its time, to show whether FundraiseUp, Blackbaud or the state store
+// was the bottleneck.
+func printTiming(result *sync.Result) {
+	m := result.Metrics
+	round := func(d time.Duration) time.Duration { return d.Round(time.Millisecond) }
+
+	fmt.Printf("Time: %s total, %s fetching, %s processing (%s per donation)\n",
+		round(m.TotalDuration), round(m.FetchDuration), round(m.ProcessDuration),
+		round(result.AverageDonationDuration()))
+	fmt.Printf("API calls: FundraiseUp %d (%s), Blackbaud %d (%s), state store %d (%s)\n",
+		m.FundraiseUp.Calls, round(m.FundraiseUp.Duration),
+		m.Blackbaud.Calls, round(m.Blackbaud.Duration),
+		m.StateStore.Calls, round(m.StateStore.Duration))
+	fmt.Printf("Gift cache: %d hits, %d misses, %d evictions\n",
+		m.GiftCache.Hits, m.GiftCache.Misses, m.GiftCache.Evictions)
+}
+
 // formatError formats an error for terminal display, indenting multi-line errors.
 func formatError(err error) string {
 	msg := err.Error()
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/reconcile"
+	"github.com/peteski22/giftbridge/internal/version"
+)
+
+// runReconcile exports donation totals per payment processor payout as CSV.
+func runReconcile(args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	from := fs.String("from", "", "first payout arrival date to include, in YYYY-MM-DD format")
+	output := fs.String("output", "", "output file path (default: stdout)")
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	table := fs.String("table", "", "donation tracker table name (default: TRACKER_TABLE_NAME, or <stack-name>-donations)")
+	to := fs.String("to", "", "payout arrival date to stop before, in YYYY-MM-DD format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return errors.New("--from and --to are required")
+	}
+	fromDate, err := time.Parse(time.DateOnly, *from)
+	if err != nil {
+		return fmt.Errorf("parsing --from: %w", err)
+	}
+	toDate, err := time.Parse(time.DateOnly, *to)
+	if err != nil {
+		return fmt.Errorf("parsing --to: %w", err)
+	}
+	if !toDate.After(fromDate) {
+		return errors.New("--to must be after --from")
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	transport, err := newLocalTransport(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Payout totals must include every donation in the payout to match the deposit,
+	// so the campaign and status filters used for syncing are not applied.
+	fundraiseupClient, err := fundraiseup.NewClient(cfg.FundraiseUp.APIKey,
+		fundraiseup.WithPageSize(cfg.FundraiseUp.PageSize),
+		fundraiseup.WithTransport(transport),
+		fundraiseup.WithUserAgent(version.UserAgent(cfg.UserAgent.Organization)))
+	if err != nil {
+		return fmt.Errorf("creating FundraiseUp client: %w", err)
+	}
+
+	tracker, err := newLocalDonationTracker(ctx, trackerTableName(*stackName, *table))
+	if err != nil {
+		return err
+	}
+
+	reconciler, err := reconcile.NewReconciler(fundraiseupClient, tracker)
+	if err != nil {
+		return fmt.Errorf("creating payout reconciler: %w", err)
+	}
+
+	report, err := reconciler.Reconcile(ctx, fromDate, toDate)
+	if err != nil {
+		return fmt.Errorf("reconciling payouts: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.OpenFile(*output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		w = file
+	}
+
+	if err := reconcile.WriteCSV(w, report.Payouts); err != nil {
+		return fmt.Errorf("writing payouts: %w", err)
+	}
+
+	// Progress goes to stderr so stdout can be redirected straight to a CSV file.
+	unsynced := 0
+	for _, payout := range report.Payouts {
+		unsynced += len(payout.UnsyncedDonationIDs)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d payouts arriving from %s to before %s\n", len(report.Payouts), *from, *to)
+	if unsynced > 0 {
+		fmt.Fprintf(os.Stderr, "%d donations in these payouts have not been synced to Blackbaud\n", unsynced)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/repair"
+)
+
+// runRepairRecurring links the payments of a recurring series to its recurring gift in Blackbaud.
+func runRepairRecurring(args []string) error {
+	fs := flag.NewFlagSet("repair-recurring", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show the links that would be fixed without updating gifts")
+	plan := fs.String("plan", "", "FundraiseUp recurring plan ID, e.g. rec_XXXXXXXX")
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	table := fs.String("table", "", "donation tracker table name (default: TRACKER_TABLE_NAME, or <stack-name>-donations)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *plan == "" {
+		return errors.New("--plan is required")
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	bb, err := newLocalBlackbaudClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	tracker, err := newLocalDonationTracker(ctx, trackerTableName(*stackName, *table))
+	if err != nil {
+		return err
+	}
+
+	repairer, err := repair.NewRepairer(bb, tracker)
+	if err != nil {
+		return fmt.Errorf("creating recurring series repairer: %w", err)
+	}
+
+	report, err := repairer.Repair(ctx, *plan, *dryRun)
+	// Show the links fixed before a failure, so a rerun's output is not a surprise.
+	if report != nil {
+		if writeErr := report.Write(os.Stdout, *dryRun); writeErr != nil {
+			return errors.Join(err, writeErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("repairing recurring plan: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// runReview lists the donations the deployed sync held for review because their gifts failed the gift checks,
+// or releases some of them to be tried again by the next run, once the checks or the donations are fixed.
+func runReview(args []string) error {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	parameter := fs.String(
+		"parameter",
+		"",
+		"last sync SSM parameter name (default: SSM_PARAMETER_NAME, or /<stack-name>/last-sync-time)",
+	)
+	release := fs.String("release", "", "comma-separated IDs of held donations for the next run to try again")
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	awsClients, err := newLocalAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewStateStore(awsClients.SSM, lastSyncParameterName(*stackName, *parameter))
+	if err != nil {
+		return fmt.Errorf("creating state store: %w", err)
+	}
+
+	if *release != "" {
+		var donationIDs []string
+		for id := range strings.SplitSeq(*release, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				donationIDs = append(donationIDs, id)
+			}
+		}
+		if err := store.ReleaseGiftReviews(ctx, donationIDs, time.Now()); err != nil {
+			return fmt.Errorf("releasing held donations: %w", err)
+		}
+		fmt.Printf("Released %d donations. The next run tries them again.\n", len(donationIDs))
+		return nil
+	}
+
+	reviews, err := store.GiftReviews(ctx)
+	if err != nil {
+		return fmt.Errorf("getting held donations: %w", err)
+	}
+
+	return writeGiftReviews(os.Stdout, reviews)
+}
+
+// writeGiftReviews prints the donations held for review, with the checks each failed.
+func writeGiftReviews(w io.Writer, reviews []storage.GiftReview) error {
+	if len(reviews) == 0 {
+		fmt.Fprintln(w, "No donations held for review.")
+		return nil
+	}
+
+	fmt.Fprintln(w, "Donations held for review after failing the gift checks:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HELD\tDONATION\tFAILED CHECKS")
+	for _, review := range reviews {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n",
+			review.HeldAt.UTC().Format(time.RFC3339), review.DonationID, strings.Join(review.Violations, "; "))
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("writing held donations: %w", err)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Fix the gift checks or the donations, then release them with --release=<donation IDs>.")
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build azure
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+// envAzureCustomHandlerPort is set by Azure Functions to the port a custom handler must listen on.
+const envAzureCustomHandlerPort = "FUNCTIONS_CUSTOMHANDLER_PORT"
+
+// serve runs an Azure Functions custom handler for an HTTP-triggered function, which syncs on each POST request.
+// The function app's host.json must enable enableForwardingHttpRequest, so requests arrive unchanged.
+func serve(transport http.RoundTripper) error {
+	port := os.Getenv(envAzureCustomHandlerPort)
+	if port == "" {
+		return errors.New(envAzureCustomHandlerPort + " is not set; run the handler from the Azure Functions host")
+	}
+
+	return serveFunction(":"+port, syncHTTPHandler(func(ctx context.Context) (*sync.Result, error) {
+		return handler(ctx, transport)
+	}))
+}
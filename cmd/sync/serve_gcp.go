@@ -0,0 +1,32 @@
+//go:build gcp
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+const (
+	// defaultGCPPort is the port served when Cloud Functions does not set PORT.
+	defaultGCPPort = "8080"
+
+	// envGCPPort is set by Cloud Functions to the port the function must listen on.
+	envGCPPort = "PORT"
+)
+
+// serve runs an HTTP-triggered Cloud Functions (2nd gen) handler, which syncs on each POST request, such as one
+// sent by Cloud Scheduler.
+func serve(transport http.RoundTripper) error {
+	port := os.Getenv(envGCPPort)
+	if port == "" {
+		port = defaultGCPPort
+	}
+
+	return serveFunction(":"+port, syncHTTPHandler(func(ctx context.Context) (*sync.Result, error) {
+		return handler(ctx, transport)
+	}))
+}
@@ -0,0 +1,25 @@
+//go:build !gcp && !azure
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// serve runs the AWS Lambda handler, which syncs on each scheduled invocation and processes the backfill batches
+// dispatched to it. A sync responds with its result, and a batch with the batch's result. A failed invocation
+// responds with only its error, as Lambda reports it. Build with the gcp or azure tag for Cloud Functions or Azure
+// Functions instead.
+func serve(transport http.RoundTripper) error {
+	lambda.Start(func(ctx context.Context, event json.RawMessage) (any, error) {
+		if batch := backfillBatch(event); batch != nil {
+			return handleBackfillBatch(ctx, transport, *batch)
+		}
+		return handler(ctx, transport)
+	})
+	return nil
+}
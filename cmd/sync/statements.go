@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/statements"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// runStatements exports per-constituent gift totals for a year as CSV.
+func runStatements(args []string) error {
+	fs := flag.NewFlagSet("statements", flag.ContinueOnError)
+	output := fs.String("output", "", "output file path (default: stdout)")
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	table := fs.String("table", "", "donation tracker table name (default: TRACKER_TABLE_NAME, or <stack-name>-donations)")
+	year := fs.Int("year", 0, "calendar year to report on (e.g. 2024)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *year <= 0 {
+		return errors.New("--year is required")
+	}
+
+	tableName := trackerTableName(*stackName, *table)
+
+	ctx := context.Background()
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	blackbaudClient, err := newLocalBlackbaudClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	tracker, err := newLocalDonationTracker(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	generator, err := statements.NewGenerator(tracker, blackbaudClient)
+	if err != nil {
+		return fmt.Errorf("creating statement generator: %w", err)
+	}
+
+	report, err := generator.Generate(ctx, *year)
+	if err != nil {
+		return fmt.Errorf("generating statements: %w", err)
+	}
+
+	from := time.Date(*year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	counts, err := tracker.DonationCounts(ctx, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return fmt.Errorf("counting tracked donations: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		// Statements contain donor names and addresses, so keep the file private.
+		file, err := os.OpenFile(*output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		w = file
+	}
+
+	if err := statements.WriteCSV(w, report.Statements); err != nil {
+		return fmt.Errorf("writing statements: %w", err)
+	}
+
+	// Progress goes to stderr so stdout can be redirected straight to a CSV file.
+	fmt.Fprintf(os.Stderr, "Wrote %d statements for %d\n", len(report.Statements), *year)
+	fmt.Fprintf(os.Stderr, "Tracked %d donations in %d%s\n", counts.Total, *year, describeDonationCounts(counts))
+	if len(report.MissingGiftIDs) > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d tracked gifts no longer in Blackbaud:\n", len(report.MissingGiftIDs))
+		for _, id := range report.MissingGiftIDs {
+			fmt.Fprintf(os.Stderr, "  %s\n", id)
+		}
+	}
+
+	return nil
+}
+
+// describeDonationCounts summarises tracked donations by gift type and recurring plan, e.g.
+// ": 80 Donation, 30 RecurringGiftPayment from 12 recurring plans". It is empty when nothing was tracked.
+func describeDonationCounts(counts *storage.DonationCounts) string {
+	if counts.Total == 0 {
+		return ""
+	}
+
+	giftTypes := make([]string, 0, len(counts.ByGiftType))
+	for giftType := range counts.ByGiftType {
+		giftTypes = append(giftTypes, giftType)
+	}
+	sort.Strings(giftTypes)
+
+	parts := make([]string, 0, len(giftTypes))
+	for _, giftType := range giftTypes {
+		name := giftType
+		if name == "" {
+			// Donations tracked before gift types were recorded.
+			name = "of unrecorded type"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", counts.ByGiftType[giftType], name))
+	}
+
+	summary := ": " + strings.Join(parts, ", ")
+	if len(counts.ByRecurringID) > 0 {
+		summary += fmt.Sprintf(" from %d recurring plans", len(counts.ByRecurringID))
+	}
+	return summary
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/bootstrap"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// runStatus prints the last sync time, the pending backlog, the donations held for review, recent runs and poison
+// pills recorded by the deployed sync.
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	parameter := fs.String(
+		"parameter",
+		"",
+		"last sync SSM parameter name (default: SSM_PARAMETER_NAME, or /<stack-name>/last-sync-time)",
+	)
+	stackName := fs.String("stack-name", "giftbridge", "prefix used for resource names by init-aws")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	awsClients, err := newLocalAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewStateStore(awsClients.SSM, lastSyncParameterName(*stackName, *parameter))
+	if err != nil {
+		return fmt.Errorf("creating state store: %w", err)
+	}
+
+	lastSync, err := store.LastSyncTime(ctx)
+	if err != nil {
+		return fmt.Errorf("getting last sync time: %w", err)
+	}
+	pendingIDs, err := store.PendingDonationIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("getting pending donations: %w", err)
+	}
+	fetchState, err := store.FetchState(ctx)
+	if err != nil {
+		return fmt.Errorf("getting fetch state: %w", err)
+	}
+	reviews, err := store.GiftReviews(ctx)
+	if err != nil {
+		return fmt.Errorf("getting held donations: %w", err)
+	}
+	runs, err := store.RunHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("getting run history: %w", err)
+	}
+	pills, err := store.PoisonPills(ctx)
+	if err != nil {
+		return fmt.Errorf("getting poison pills: %w", err)
+	}
+
+	return writeStatus(os.Stdout, time.Now(), lastSync, len(pendingIDs), fetchState, len(reviews), runs, pills)
+}
+
+// describeRunOutcome summarises how a run ended, e.g. "completed, 2 errors (blackbaud_400: 1, network: 1)".
+func describeRunOutcome(run storage.RunSummary) string {
+	var outcome string
+	switch {
+	case run.Failure != "":
+		outcome = "failed: " + run.Failure
+	case run.Interrupted:
+		outcome = "interrupted"
+	case run.PausedForQuota:
+		outcome = "paused for quota"
+	case run.StoppedOnError:
+		outcome = "stopped on error"
+	default:
+		outcome = "completed"
+	}
+
+	if run.Errors == 0 {
+		return outcome
+	}
+
+	categories := make([]string, 0, len(run.ErrorCategories))
+	for category := range run.ErrorCategories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	parts := make([]string, 0, len(categories))
+	for _, category := range categories {
+		parts = append(parts, fmt.Sprintf("%s: %d", category, run.ErrorCategories[category]))
+	}
+
+	outcome += fmt.Sprintf(", %d errors", run.Errors)
+	if len(parts) > 0 {
+		outcome += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return outcome
+}
+
+// lastSyncParameterName returns parameter if set, then the configured last sync parameter, otherwise the parameter
+// init-aws creates for stackName.
+func lastSyncParameterName(stackName string, parameter string) string {
+	if parameter != "" {
+		return parameter
+	}
+	if configured := config.LoadResourceNames().LastSyncParameterName; configured != "" {
+		return configured
+	}
+	return bootstrap.NewResources(stackName).LastSyncParameterName
+}
+
+// writeStatus prints the sync state, run history and poison pills as of now.
+func writeStatus(
+	w io.Writer,
+	now time.Time,
+	lastSync time.Time,
+	pending int,
+	fetchState *storage.FetchState,
+	held int,
+	runs []storage.RunSummary,
+	pills []storage.PoisonPill,
+) error {
+	if lastSync.IsZero() {
+		fmt.Fprintln(w, "Last sync:         never")
+	} else {
+		fmt.Fprintf(w, "Last sync:         %s (%s ago)\n",
+			lastSync.UTC().Format(time.RFC3339), now.Sub(lastSync).Round(time.Minute))
+	}
+	fmt.Fprintf(w, "Pending donations: %d\n", pending)
+	if held > 0 {
+		fmt.Fprintf(w, "Held for review:   %d (see giftbridge review)\n", held)
+	}
+	if fetchState != nil {
+		fmt.Fprintf(w, "Unfinished fetch:  donations since %s, resuming after %s\n",
+			fetchState.Since.UTC().Format(time.RFC3339), fetchState.Cursor)
+	}
+
+	fmt.Fprintln(w)
+	if len(runs) == 0 {
+		fmt.Fprintln(w, "No runs recorded yet.")
+	} else {
+		fmt.Fprintln(w, "Recent runs:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "STARTED\tDURATION\tDONATIONS\tCONSTITUENTS\tGIFTS\tUPDATED\tCHARGED BACK\tSKIPPED\tOUTCOME")
+		for _, run := range runs {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n",
+				run.StartedAt.UTC().Format(time.RFC3339),
+				run.Duration.Round(time.Second),
+				run.DonationsProcessed,
+				run.ConstituentsCreated,
+				run.GiftsCreated,
+				run.GiftsUpdated,
+				run.GiftsChargedBack,
+				run.GiftsSkippedExisting,
+				describeRunOutcome(run))
+		}
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("writing run history: %w", err)
+		}
+	}
+
+	if len(pills) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Donations that crashed processing (see the stack in the poison-pills parameter):")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OCCURRED\tDONATION\tPANIC")
+	for _, pill := range pills {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", pill.OccurredAt.UTC().Format(time.RFC3339), pill.DonationID, pill.Panic)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("writing poison pills: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/peteski22/giftbridge/internal/selfupdate"
+	"github.com/peteski22/giftbridge/internal/version"
+)
+
+// runUpdate replaces the running binary with the latest release, once the download matches the release's
+// checksum. A newer build than the latest release is only replaced with --force.
+func runUpdate(args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only report whether a newer release is available")
+	force := fs.Bool("force", false,
+		"replace a development build or one newer than the latest release, or reinstall the current release")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	updater, err := selfupdate.NewUpdater()
+	if err != nil {
+		return fmt.Errorf("creating updater: %w", err)
+	}
+
+	release, err := updater.Latest(ctx)
+	if err != nil {
+		return fmt.Errorf("checking for updates: %w", err)
+	}
+
+	// A development build has no version to compare, so the release is treated as newer.
+	order := 1
+	if version.Version != version.Dev {
+		if order, err = version.Compare(release.Tag, version.Version); err != nil && !*force {
+			return fmt.Errorf("comparing with %s: %w; run 'giftbridge update --force' to replace it anyway",
+				release.Tag, err)
+		}
+	}
+
+	switch {
+	case order == 0 && !*force:
+		fmt.Printf("giftbridge %s is up to date.\n", version.Version)
+		return nil
+	case order < 0 && *check:
+		fmt.Printf("giftbridge %s is newer than the latest release, %s.\n", version.Version, release.Tag)
+		return nil
+	case *check:
+		fmt.Printf("giftbridge %s is available (you have %s). Run 'giftbridge update' to install it.\n",
+			release.Tag, version.Version)
+		return nil
+	case order < 0 && !*force:
+		return fmt.Errorf("giftbridge %s is newer than the latest release; run 'giftbridge update --force' to "+
+			"downgrade to %s", version.Version, release.Tag)
+	case version.Version == version.Dev && !*force:
+		return fmt.Errorf("this is a development build; run 'giftbridge update --force' to replace it with %s",
+			release.Tag)
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding the running binary: %w", err)
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return fmt.Errorf("finding the running binary: %w", err)
+	}
+
+	if err := updater.Apply(ctx, release, path); err != nil {
+		return fmt.Errorf("updating to %s: %w", release.Tag, err)
+	}
+
+	fmt.Printf("Updated giftbridge from %s to %s.\n", version.Version, release.Tag)
+	return nil
+}
@@ -246,17 +246,65 @@ deploy_stack() {
         --capabilities CAPABILITY_IAM \
         ${region_arg} \
         --parameter-overrides \
+            "BackfillConcurrency=${BACKFILL_CONCURRENCY:-4}" \
             "BlackbaudClientId=${BLACKBAUD_CLIENT_ID}" \
             "BlackbaudClientSecret=${BLACKBAUD_CLIENT_SECRET}" \
             "BlackbaudEnvironmentId=${BLACKBAUD_ENVIRONMENT_ID}" \
+            "BlackbaudHedgeDelay=${BLACKBAUD_HEDGE_DELAY:-}" \
+            "BlackbaudQuotaReserve=${BLACKBAUD_QUOTA_RESERVE:-0}" \
             "BlackbaudRefreshToken=${BLACKBAUD_REFRESH_TOKEN}" \
+            "BlackbaudSecondarySubscriptionKey=${BLACKBAUD_SECONDARY_SUBSCRIPTION_KEY:-}" \
             "BlackbaudSubscriptionKey=${BLACKBAUD_SUBSCRIPTION_KEY}" \
+            "CommentScrubCardNumbers=${COMMENT_SCRUB_CARD_NUMBERS:-false}" \
+            "CommentScrubPatterns=${COMMENT_SCRUB_PATTERNS:-}" \
+            "CommentScrubWords=${COMMENT_SCRUB_WORDS:-}" \
+            "ConstituentAddresseeFormat=${CONSTITUENT_ADDRESSEE_FORMAT:-}" \
+            "ConstituentCodes=${CONSTITUENT_CODES:-}" \
+            "ConstituentDonationNoteFormat=${CONSTITUENT_DONATION_NOTE_FORMAT:-}" \
+            "ConstituentDonationNoteType=${CONSTITUENT_DONATION_NOTE_TYPE:-}" \
+            "ConstituentEvents=${CONSTITUENT_EVENTS:-}" \
+            "ConstituentSalutationFormat=${CONSTITUENT_SALUTATION_FORMAT:-}" \
+            "EmailAddMissing=${EMAIL_ADD_MISSING:-false}" \
+            "EmailFoldGmail=${EMAIL_FOLD_GMAIL:-false}" \
+            "EmailStripPlusTags=${EMAIL_STRIP_PLUS_TAGS:-false}" \
+            "FundraiseUpAccounts=${FUNDRAISEUP_ACCOUNTS:-}" \
             "FundraiseUpApiKey=${FUNDRAISEUP_API_KEY}" \
+            "FundraiseUpCampaignId=${FUNDRAISEUP_CAMPAIGN_ID:-}" \
+            "FundraiseUpCampaignWindows=${FUNDRAISEUP_CAMPAIGN_WINDOWS:-}" \
+            "FundraiseUpFetchOverlap=${FUNDRAISEUP_FETCH_OVERLAP:-}" \
+            "FundraiseUpPageSize=${FUNDRAISEUP_PAGE_SIZE:-100}" \
+            "FundraiseUpStatus=${FUNDRAISEUP_STATUS:-}" \
+            "FundraiseUpStrictDecode=${FUNDRAISEUP_STRICT_DECODE:-false}" \
             "GiftFundId=${GIFT_FUND_ID}" \
             "GiftCampaignId=${GIFT_CAMPAIGN_ID:-}" \
             "GiftAppealId=${GIFT_APPEAL_ID:-}" \
+            "GiftAppealResponses=${GIFT_APPEAL_RESPONSES:-false}" \
+            "GiftChecks=${GIFT_CHECKS:-}" \
+            "GiftCountryRoutes=${GIFT_COUNTRY_ROUTES:-}" \
+            "GiftCreatorCustomField=${GIFT_CREATOR_CUSTOM_FIELD:-}" \
+            "GiftDatePolicy=${GIFT_DATE_POLICY:-}" \
+            "GiftMaxAgeDays=${GIFT_MAX_AGE_DAYS:-0}" \
+            "GiftPostDate=${GIFT_POST_DATE:-}" \
+            "GiftPostStatus=${GIFT_POST_STATUS:-}" \
+            "GiftRecurringMode=${GIFT_RECURRING_MODE:-linked}" \
+            "GiftReferenceField=${GIFT_REFERENCE_FIELD:-lookup_id}" \
+            "GiftReferenceNoteType=${GIFT_REFERENCE_NOTE_TYPE:-}" \
+            "GiftRules=${GIFT_RULES:-}" \
+            "GiftSplits=${GIFT_SPLITS:-}" \
+            "GiftTestDonations=${GIFT_TEST_DONATIONS:-skip}" \
+            "GiftTestFundId=${GIFT_TEST_FUND_ID:-}" \
             "GiftType=${GIFT_TYPE:-Donation}" \
-            "ScheduleExpression=${SCHEDULE_EXPRESSION:-rate(1 hour)}"
+            "NameTitleCase=${NAME_TITLE_CASE:-false}" \
+            "NameTransliterate=${NAME_TRANSLITERATE:-false}" \
+            "ProxyBypass=${PROXY_BYPASS:-}" \
+            "ProxyUrl=${PROXY_URL:-}" \
+            "ScheduleExpression=${SCHEDULE_EXPRESSION:-rate(1 hour)}" \
+            "UserAgentOrganization=${USER_AGENT_ORGANIZATION:-}" \
+            "VaultAddr=${VAULT_ADDR:-}" \
+            "VaultKvMount=${VAULT_KV_MOUNT:-}" \
+            "VaultNamespace=${VAULT_NAMESPACE:-}" \
+            "VaultSecretPath=${VAULT_SECRET_PATH:-}" \
+            "VaultToken=${VAULT_TOKEN:-}"
 
     rm -f "${packaged_template}"
     success "Deployment complete!"
@@ -0,0 +1,293 @@
+// Package archive copies donation tracker records to S3 one month at a time,
+// so they outlive the tracker table's retention for long-term auditing.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+const (
+	// contentType is the media type of archive objects, which hold one JSON record per line.
+	contentType = "application/x-ndjson"
+
+	// monthLayout formats the month of an archive object.
+	monthLayout = "2006-01"
+)
+
+// S3API defines the S3 operations needed to archive tracker records.
+type S3API interface {
+	// HeadObject retrieves an object's metadata.
+	HeadObject(
+		ctx context.Context,
+		params *s3.HeadObjectInput,
+		optFns ...func(*s3.Options),
+	) (*s3.HeadObjectOutput, error)
+
+	// PutObject stores an object.
+	PutObject(
+		ctx context.Context,
+		params *s3.PutObjectInput,
+		optFns ...func(*s3.Options),
+	) (*s3.PutObjectOutput, error)
+}
+
+// Tracker defines the donation tracker operations needed to archive records.
+type Tracker interface {
+	// DonationsBetween returns all tracked donations made in [from, to).
+	DonationsBetween(ctx context.Context, from time.Time, to time.Time) ([]storage.DonationRecord, error)
+}
+
+// Archiver copies tracker records to S3.
+type Archiver struct {
+	bucket  string
+	prefix  string
+	s3      S3API
+	tracker Tracker
+}
+
+// Month describes the archive object for one month of donations.
+type Month struct {
+	// Count is the number of donations archived, or in a dry run that would be.
+	Count int
+
+	// Exists indicates the object was already in the bucket, so it was left unchanged.
+	Exists bool
+
+	// Key is the object key.
+	Key string
+
+	// Month is the month the donations were made, in YYYY-MM format.
+	Month string
+}
+
+// Option configures an Archiver.
+type Option func(*Archiver)
+
+// Report describes the months that were, or in a dry run would be, archived.
+type Report struct {
+	// Bucket is the S3 bucket archived to.
+	Bucket string
+
+	// Months contains one entry per month archived, in order.
+	Months []Month
+}
+
+// record is the archived form of a tracker record, one JSON object per line.
+type record struct {
+	Amount        string `json:"amount,omitempty"`
+	ConstituentID string `json:"constituentId,omitempty"`
+	CreatedAt     string `json:"createdAt"`
+	Currency      string `json:"currency,omitempty"`
+	DonationID    string `json:"donationId"`
+	ExcludedAt    string `json:"excludedAt,omitempty"`
+	GiftID        string `json:"giftId"`
+	GiftType      string `json:"giftType,omitempty"`
+	RecurringID   string `json:"recurringId,omitempty"`
+	SupporterID   string `json:"supporterId,omitempty"`
+	TrackedAt     string `json:"trackedAt,omitempty"`
+}
+
+// WithPrefix sets the prefix of object keys, e.g. "giftbridge-donations/".
+func WithPrefix(prefix string) Option {
+	return func(a *Archiver) {
+		a.prefix = prefix
+	}
+}
+
+// NewArchiver creates a new archiver writing to bucket.
+func NewArchiver(client S3API, bucket string, tracker Tracker, opts ...Option) (*Archiver, error) {
+	if client == nil {
+		return nil, errors.New("s3 client is required")
+	}
+	if bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+	if tracker == nil {
+		return nil, errors.New("tracker is required")
+	}
+
+	archiver := &Archiver{
+		bucket:  bucket,
+		s3:      client,
+		tracker: tracker,
+	}
+
+	for _, opt := range opts {
+		opt(archiver)
+	}
+
+	return archiver, nil
+}
+
+// Archive writes one object per month for the donations made in the months from the month of from up to,
+// but not including, the month of to. Objects already in the bucket are never overwritten,
+// since records in them may have since expired from the table. In a dry run nothing is written.
+// Months that have not yet ended cannot be archived, as later donations would be left out.
+func (a *Archiver) Archive(ctx context.Context, from time.Time, to time.Time, dryRun bool) (*Report, error) {
+	first := monthStart(from)
+	end := monthStart(to)
+	if current := monthStart(time.Now()); end.After(current) {
+		return nil, fmt.Errorf("cannot archive %s or later, as the month has not ended", current.Format(monthLayout))
+	}
+
+	report := &Report{Bucket: a.bucket}
+	for month := first; month.Before(end); month = month.AddDate(0, 1, 0) {
+		archived, err := a.archiveMonth(ctx, month, dryRun)
+		if err != nil {
+			return report, err
+		}
+		report.Months = append(report.Months, *archived)
+	}
+
+	return report, nil
+}
+
+// archiveMonth writes the object for the donations made in the month starting at month.
+func (a *Archiver) archiveMonth(ctx context.Context, month time.Time, dryRun bool) (*Month, error) {
+	archived := &Month{
+		Key:   a.prefix + month.Format(monthLayout) + ".jsonl",
+		Month: month.Format(monthLayout),
+	}
+
+	exists, err := a.exists(ctx, archived.Key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		archived.Exists = true
+		return archived, nil
+	}
+
+	records, err := a.tracker.DonationsBetween(ctx, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("listing tracked donations for %s: %w", archived.Month, err)
+	}
+	archived.Count = len(records)
+	if dryRun || len(records) == 0 {
+		return archived, nil
+	}
+
+	body, err := encode(records)
+	if err != nil {
+		return nil, fmt.Errorf("encoding donations for %s: %w", archived.Month, err)
+	}
+
+	_, err = a.s3.PutObject(ctx, &s3.PutObjectInput{
+		Body:        bytes.NewReader(body),
+		Bucket:      aws.String(a.bucket),
+		ContentType: aws.String(contentType),
+		Key:         aws.String(archived.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("writing %s to bucket %s: %w", archived.Key, a.bucket, err)
+	}
+
+	return archived, nil
+}
+
+// exists returns true if the bucket already holds an object with the given key.
+func (a *Archiver) exists(ctx context.Context, key string) (bool, error) {
+	_, err := a.s3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		return true, nil
+	}
+
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	return false, fmt.Errorf("checking for %s in bucket %s: %w", key, a.bucket, err)
+}
+
+// Write writes the report as text, describing the months as archived or, in a dry run, as planned.
+func (r *Report) Write(w io.Writer, dryRun bool) error {
+	var b strings.Builder
+
+	verb := "Archived"
+	if dryRun {
+		verb = "Would archive"
+	}
+
+	if len(r.Months) == 0 {
+		b.WriteString("No months to archive.\n")
+	} else {
+		fmt.Fprintf(&b, "%s to s3://%s:\n", verb, r.Bucket)
+	}
+	for _, month := range r.Months {
+		switch {
+		case month.Exists:
+			fmt.Fprintf(&b, "  %s: already archived as %s, skipped\n", month.Month, month.Key)
+		case month.Count == 0:
+			fmt.Fprintf(&b, "  %s: no donations\n", month.Month)
+		default:
+			fmt.Fprintf(&b, "  %s: %d donations -> %s\n", month.Month, month.Count, month.Key)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// encode writes records as JSON lines, ordered by donation time and then ID so reruns produce the same object.
+func encode(records []storage.DonationRecord) ([]byte, error) {
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].CreatedAt.Before(records[j].CreatedAt)
+		}
+		return records[i].DonationID < records[j].DonationID
+	})
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		err := enc.Encode(record{
+			Amount:        r.Amount,
+			ConstituentID: r.ConstituentID,
+			CreatedAt:     formatTime(r.CreatedAt),
+			Currency:      r.Currency,
+			DonationID:    r.DonationID,
+			ExcludedAt:    formatTime(r.ExcludedAt),
+			GiftID:        r.GiftID,
+			GiftType:      r.GiftType,
+			RecurringID:   r.RecurringID,
+			SupporterID:   r.SupporterID,
+			TrackedAt:     formatTime(r.TrackedAt),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatTime formats t as RFC3339 in UTC, or returns an empty string for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// monthStart returns the start of t's month in UTC.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
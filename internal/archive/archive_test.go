@@ -0,0 +1,310 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+type mockS3Client struct {
+	headErr error
+	objects map[string]string
+	putErr  error
+}
+
+func (m *mockS3Client) HeadObject(
+	_ context.Context,
+	params *s3.HeadObjectInput,
+	_ ...func(*s3.Options),
+) (*s3.HeadObjectOutput, error) {
+	if m.headErr != nil {
+		return nil, m.headErr
+	}
+	if _, ok := m.objects[aws.ToString(params.Key)]; !ok {
+		return nil, &types.NotFound{}
+	}
+	return &s3.HeadObjectOutput{}, nil
+}
+
+func (m *mockS3Client) PutObject(
+	_ context.Context,
+	params *s3.PutObjectInput,
+	_ ...func(*s3.Options),
+) (*s3.PutObjectOutput, error) {
+	if m.putErr != nil {
+		return nil, m.putErr
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	if m.objects == nil {
+		m.objects = make(map[string]string)
+	}
+	m.objects[aws.ToString(params.Key)] = string(body)
+	return &s3.PutObjectOutput{}, nil
+}
+
+type mockTracker struct {
+	err     error
+	records []storage.DonationRecord
+}
+
+func (m *mockTracker) DonationsBetween(
+	_ context.Context,
+	from time.Time,
+	to time.Time,
+) ([]storage.DonationRecord, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var records []storage.DonationRecord
+	for _, r := range m.records {
+		if !r.CreatedAt.Before(from) && r.CreatedAt.Before(to) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func TestNewArchiver(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		bucket  string
+		client  S3API
+		tracker Tracker
+		wantErr string
+	}{
+		"valid": {
+			bucket:  "archive",
+			client:  &mockS3Client{},
+			tracker: &mockTracker{},
+		},
+		"missing client": {
+			bucket:  "archive",
+			tracker: &mockTracker{},
+			wantErr: "s3 client is required",
+		},
+		"missing bucket": {
+			client:  &mockS3Client{},
+			tracker: &mockTracker{},
+			wantErr: "bucket is required",
+		},
+		"missing tracker": {
+			bucket:  "archive",
+			client:  &mockS3Client{},
+			wantErr: "tracker is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			archiver, err := NewArchiver(tc.client, tc.bucket, tc.tracker)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				require.Nil(t, archiver)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, archiver)
+		})
+	}
+}
+
+func TestArchiver_Archive(t *testing.T) {
+	t.Parallel()
+
+	jan := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	records := []storage.DonationRecord{
+		{
+			ConstituentID: "const-1",
+			CreatedAt:     jan.Add(48 * time.Hour),
+			DonationID:    "don-2",
+			GiftID:        "gift-2",
+			GiftType:      "Donation",
+			TrackedAt:     jan.Add(49 * time.Hour),
+		},
+		{
+			Amount:     "25.00",
+			CreatedAt:  jan.Add(24 * time.Hour),
+			Currency:   "GBP",
+			DonationID: "don-1",
+			ExcludedAt: jan.AddDate(0, 2, 0),
+			GiftID:     "gift-1",
+		},
+		{
+			CreatedAt:   jan.AddDate(0, 2, 3),
+			DonationID:  "don-3",
+			GiftID:      "gift-3",
+			RecurringID: "rec-1",
+		},
+	}
+	janObject := `{"amount":"25.00","createdAt":"2024-01-02T00:00:00Z","currency":"GBP","donationId":"don-1",` +
+		`"excludedAt":"2024-03-01T00:00:00Z","giftId":"gift-1"}` + "\n" +
+		`{"constituentId":"const-1","createdAt":"2024-01-03T00:00:00Z","donationId":"don-2",` +
+		`"giftId":"gift-2","giftType":"Donation","trackedAt":"2024-01-03T01:00:00Z"}` + "\n"
+
+	tests := map[string]struct {
+		dryRun      bool
+		existing    map[string]string
+		from        time.Time
+		headErr     error
+		putErr      error
+		to          time.Time
+		trackerErr  error
+		wantErr     string
+		wantMonths  []Month
+		wantObjects map[string]string
+	}{
+		"archives each month": {
+			from: jan,
+			to:   jan.AddDate(0, 3, 0),
+			wantMonths: []Month{
+				{Count: 2, Key: "donations/2024-01.jsonl", Month: "2024-01"},
+				{Key: "donations/2024-02.jsonl", Month: "2024-02"},
+				{Count: 1, Key: "donations/2024-03.jsonl", Month: "2024-03"},
+			},
+			wantObjects: map[string]string{
+				"donations/2024-01.jsonl": janObject,
+				"donations/2024-03.jsonl": `{"createdAt":"2024-03-04T00:00:00Z","donationId":"don-3",` +
+					`"giftId":"gift-3","recurringId":"rec-1"}` + "\n",
+			},
+		},
+		"rounds window to whole months": {
+			from: jan.Add(36 * time.Hour),
+			to:   jan.AddDate(0, 1, 5),
+			wantMonths: []Month{
+				{Count: 2, Key: "donations/2024-01.jsonl", Month: "2024-01"},
+			},
+			wantObjects: map[string]string{"donations/2024-01.jsonl": janObject},
+		},
+		"skips existing objects": {
+			existing: map[string]string{"donations/2024-01.jsonl": "kept"},
+			from:     jan,
+			to:       jan.AddDate(0, 1, 0),
+			wantMonths: []Month{
+				{Exists: true, Key: "donations/2024-01.jsonl", Month: "2024-01"},
+			},
+			wantObjects: map[string]string{"donations/2024-01.jsonl": "kept"},
+		},
+		"dry run writes nothing": {
+			dryRun: true,
+			from:   jan,
+			to:     jan.AddDate(0, 1, 0),
+			wantMonths: []Month{
+				{Count: 2, Key: "donations/2024-01.jsonl", Month: "2024-01"},
+			},
+			wantObjects: map[string]string{},
+		},
+		"empty window": {
+			from:        jan,
+			to:          jan,
+			wantObjects: map[string]string{},
+		},
+		"month not ended": {
+			from:    jan,
+			to:      time.Now().AddDate(0, 2, 0),
+			wantErr: "as the month has not ended",
+		},
+		"head error": {
+			from:    jan,
+			headErr: errors.New("access denied"),
+			to:      jan.AddDate(0, 1, 0),
+			wantErr: "checking for donations/2024-01.jsonl in bucket archive: access denied",
+		},
+		"tracker error": {
+			from:       jan,
+			to:         jan.AddDate(0, 1, 0),
+			trackerErr: errors.New("throttled"),
+			wantErr:    "listing tracked donations for 2024-01: throttled",
+		},
+		"put error": {
+			from:    jan,
+			putErr:  errors.New("access denied"),
+			to:      jan.AddDate(0, 1, 0),
+			wantErr: "writing donations/2024-01.jsonl to bucket archive: access denied",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			objects := make(map[string]string)
+			for k, v := range tc.existing {
+				objects[k] = v
+			}
+			client := &mockS3Client{headErr: tc.headErr, objects: objects, putErr: tc.putErr}
+			tracker := &mockTracker{err: tc.trackerErr, records: append([]storage.DonationRecord(nil), records...)}
+
+			archiver, err := NewArchiver(client, "archive", tracker, WithPrefix("donations/"))
+			require.NoError(t, err)
+
+			report, err := archiver.Archive(context.Background(), tc.from, tc.to, tc.dryRun)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "archive", report.Bucket)
+			require.Equal(t, tc.wantMonths, report.Months)
+			require.Equal(t, tc.wantObjects, client.objects)
+		})
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	t.Parallel()
+
+	months := []Month{
+		{Count: 2, Key: "donations/2024-01.jsonl", Month: "2024-01"},
+		{Key: "donations/2024-02.jsonl", Month: "2024-02"},
+		{Exists: true, Key: "donations/2024-03.jsonl", Month: "2024-03"},
+	}
+
+	tests := map[string]struct {
+		dryRun bool
+		report Report
+		want   string
+	}{
+		"archived": {
+			report: Report{Bucket: "archive", Months: months},
+			want: "Archived to s3://archive:\n" +
+				"  2024-01: 2 donations -> donations/2024-01.jsonl\n" +
+				"  2024-02: no donations\n" +
+				"  2024-03: already archived as donations/2024-03.jsonl, skipped\n",
+		},
+		"dry run": {
+			dryRun: true,
+			report: Report{Bucket: "archive", Months: months[:1]},
+			want:   "Would archive to s3://archive:\n  2024-01: 2 donations -> donations/2024-01.jsonl\n",
+		},
+		"no months": {
+			report: Report{Bucket: "archive"},
+			want:   "No months to archive.\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, tc.report.Write(&buf, tc.dryRun))
+			require.Equal(t, tc.want, buf.String())
+		})
+	}
+}
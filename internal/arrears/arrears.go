@@ -0,0 +1,204 @@
+// Package arrears finds recurring plans whose expected installment has not arrived,
+// so fundraising teams can chase failed card payments before the donor lapses.
+package arrears
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// DefaultGrace is how long after its due date an installment may arrive before its plan is reported by default,
+// allowing for card retries by the payment processor.
+const DefaultGrace = 3 * 24 * time.Hour
+
+// planStatusActive is the status of a recurring plan that is still taking installments.
+const planStatusActive = "active"
+
+// Donations defines the FundraiseUp operations needed to find plans in arrears.
+type Donations interface {
+	// DonationsEach calls fn for each donation created after the given time.
+	DonationsEach(ctx context.Context, since time.Time, fn func(fundraiseup.Donation) error) error
+}
+
+// Tracker defines the donation tracker operations needed to find plans in arrears.
+type Tracker interface {
+	// DonationsBetween returns all tracked donations made in [from, to).
+	DonationsBetween(ctx context.Context, from time.Time, to time.Time) ([]storage.DonationRecord, error)
+}
+
+// Plan is an active recurring plan whose expected installment did not arrive within the grace period.
+type Plan struct {
+	// Amount is the amount of the plan's latest installment, as a decimal string.
+	Amount string
+
+	// ConstituentID is the Blackbaud constituent the plan's gifts belong to, empty if none were tracked.
+	ConstituentID string
+
+	// Currency is the three-letter currency code of the plan's latest installment.
+	Currency string
+
+	// DueAt is when the missing installment was expected.
+	DueAt time.Time
+
+	// Frequency is how often the plan takes an installment, such as "monthly".
+	Frequency string
+
+	// LastInstallmentAt is when the plan's latest installment was made.
+	LastInstallmentAt time.Time
+
+	// RecurringID is the FundraiseUp recurring plan identifier.
+	RecurringID string
+
+	// SupporterEmail is the email address of the plan's supporter.
+	SupporterEmail string
+
+	// SupporterID is the FundraiseUp supporter identifier.
+	SupporterID string
+}
+
+// Report lists recurring plans in arrears.
+type Report struct {
+	// Plans are the plans in arrears, longest overdue first.
+	Plans []Plan
+}
+
+// Reporter builds arrears reports from FundraiseUp recurring plans and tracked donations.
+type Reporter struct {
+	donations Donations
+	grace     time.Duration
+	tracker   Tracker
+}
+
+// NewReporter creates a new arrears reporter that reports installments more than grace past their due date.
+func NewReporter(donations Donations, tracker Tracker, grace time.Duration) (*Reporter, error) {
+	if donations == nil {
+		return nil, errors.New("donations client is required")
+	}
+	if tracker == nil {
+		return nil, errors.New("tracker is required")
+	}
+	if grace < 0 {
+		return nil, errors.New("grace must not be negative")
+	}
+
+	return &Reporter{
+		donations: donations,
+		grace:     grace,
+		tracker:   tracker,
+	}, nil
+}
+
+// Generate reports the active plans with an installment made since the given time whose next installment was
+// due more than the grace period before now and has not arrived. An installment has arrived when FundraiseUp or
+// the tracker holds one for the plan made on or after its due date, so a late sync does not report a paid plan.
+func (r *Reporter) Generate(ctx context.Context, since time.Time, now time.Time) (*Report, error) {
+	// Include donations created up to now; the upper bound is exclusive.
+	records, err := r.tracker.DonationsBetween(ctx, since, now.Add(time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("listing tracked donations: %w", err)
+	}
+	tracked := make(map[string]storage.DonationRecord)
+	for _, record := range records {
+		if record.RecurringID == "" {
+			continue
+		}
+		if latest, ok := tracked[record.RecurringID]; !ok || record.CreatedAt.After(latest.CreatedAt) {
+			tracked[record.RecurringID] = record
+		}
+	}
+
+	latest := make(map[string]fundraiseup.Donation)
+	err = r.donations.DonationsEach(ctx, since, func(donation fundraiseup.Donation) error {
+		id := donation.RecurringID()
+		if id == "" {
+			return nil
+		}
+		if previous, ok := latest[id]; !ok || donation.CreatedAt.After(previous.CreatedAt) {
+			latest[id] = donation
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing donations: %w", err)
+	}
+
+	report := &Report{}
+	for id, donation := range latest {
+		plan := donation.RecurringPlan
+		if !strings.EqualFold(plan.Status, planStatusActive) || plan.EndedAt != nil || plan.NextInstallmentAt == nil {
+			continue
+		}
+		due := *plan.NextInstallmentAt
+		if now.Before(due.Add(r.grace)) {
+			continue
+		}
+
+		record := tracked[id]
+		last := donation.CreatedAt
+		if record.CreatedAt.After(last) {
+			last = record.CreatedAt
+		}
+		if !last.Before(due) {
+			continue
+		}
+
+		overdue := Plan{
+			Amount:            donation.Amount,
+			ConstituentID:     record.ConstituentID,
+			Currency:          donation.Currency,
+			DueAt:             due,
+			Frequency:         plan.Frequency,
+			LastInstallmentAt: last,
+			RecurringID:       id,
+		}
+		if donation.Supporter != nil {
+			overdue.SupporterEmail = donation.Supporter.Email
+			overdue.SupporterID = donation.Supporter.ID
+		}
+		report.Plans = append(report.Plans, overdue)
+	}
+	sort.Slice(report.Plans, func(i, j int) bool {
+		a, b := report.Plans[i], report.Plans[j]
+		if !a.DueAt.Equal(b.DueAt) {
+			return a.DueAt.Before(b.DueAt)
+		}
+		return a.RecurringID < b.RecurringID
+	})
+
+	return report, nil
+}
+
+// Write prints the report as a table, with how long each plan has been overdue as of now.
+func (r *Report) Write(w io.Writer, now time.Time) error {
+	if len(r.Plans) == 0 {
+		_, err := io.WriteString(w, "No recurring plans in arrears.\n")
+		return err
+	}
+
+	fmt.Fprintf(w, "Recurring plans whose expected installment has not arrived (%d):\n", len(r.Plans))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PLAN\tSUPPORTER\tEMAIL\tCONSTITUENT\tAMOUNT\tFREQUENCY\tLAST PAID\tDUE\tDAYS OVERDUE")
+	for _, p := range r.Plans {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s %s\t%s\t%s\t%s\t%d\n",
+			p.RecurringID,
+			p.SupporterID,
+			p.SupporterEmail,
+			p.ConstituentID,
+			p.Amount,
+			p.Currency,
+			p.Frequency,
+			p.LastInstallmentAt.UTC().Format(time.DateOnly),
+			p.DueAt.UTC().Format(time.DateOnly),
+			int(now.Sub(p.DueAt).Hours()/24))
+	}
+	return tw.Flush()
+}
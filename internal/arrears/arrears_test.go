@@ -0,0 +1,237 @@
+package arrears
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+type mockDonations struct {
+	donations []fundraiseup.Donation
+	since     time.Time
+}
+
+func (m *mockDonations) DonationsEach(
+	_ context.Context,
+	since time.Time,
+	fn func(fundraiseup.Donation) error,
+) error {
+	m.since = since
+	for _, donation := range m.donations {
+		if err := fn(donation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mockTracker struct {
+	from    time.Time
+	records []storage.DonationRecord
+	to      time.Time
+}
+
+func (m *mockTracker) DonationsBetween(
+	_ context.Context,
+	from time.Time,
+	to time.Time,
+) ([]storage.DonationRecord, error) {
+	m.from = from
+	m.to = to
+	return m.records, nil
+}
+
+func TestNewReporter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		donations Donations
+		grace     time.Duration
+		tracker   Tracker
+		wantErr   string
+	}{
+		"valid": {
+			donations: &mockDonations{},
+			grace:     DefaultGrace,
+			tracker:   &mockTracker{},
+		},
+		"missing donations": {
+			tracker: &mockTracker{},
+			wantErr: "donations client is required",
+		},
+		"missing tracker": {
+			donations: &mockDonations{},
+			wantErr:   "tracker is required",
+		},
+		"negative grace": {
+			donations: &mockDonations{},
+			grace:     -time.Hour,
+			tracker:   &mockTracker{},
+			wantErr:   "grace must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reporter, err := NewReporter(tc.donations, tc.tracker, tc.grace)
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, reporter)
+		})
+	}
+}
+
+func TestReporter_Generate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 12, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, -13, 0)
+	at := func(month time.Month, day int) *time.Time {
+		date := time.Date(2024, month, day, 9, 0, 0, 0, time.UTC)
+		return &date
+	}
+	lastYear := at(time.May, 1).AddDate(-1, 0, 0)
+	installment := func(id string, planID string, made time.Time, plan fundraiseup.RecurringPlan) fundraiseup.Donation {
+		plan.ID = planID
+		return fundraiseup.Donation{
+			Amount:        "10.00",
+			CreatedAt:     made,
+			Currency:      "GBP",
+			ID:            id,
+			RecurringPlan: &plan,
+			Supporter:     &fundraiseup.Supporter{Email: planID + "@example.com", ID: "sup_" + planID},
+		}
+	}
+	active := func(next *time.Time) fundraiseup.RecurringPlan {
+		return fundraiseup.RecurringPlan{Frequency: "monthly", NextInstallmentAt: next, Status: "active"}
+	}
+
+	donations := &mockDonations{
+		donations: []fundraiseup.Donation{
+			// Missed June, due a fortnight ago.
+			installment("don_1", "rec_missed", *at(time.April, 5), active(at(time.May, 5))),
+			installment("don_2", "rec_missed", *at(time.May, 5), active(at(time.June, 5))),
+			// Due yesterday, still within the grace period.
+			installment("don_3", "rec_grace", *at(time.May, 19), active(at(time.June, 19))),
+			// Paid on time.
+			installment("don_4", "rec_paid", *at(time.June, 1), active(at(time.July, 1))),
+			// Missed, but a later installment was tracked before FundraiseUp listed it.
+			installment("don_5", "rec_synced", *at(time.May, 2), active(at(time.June, 2))),
+			// Cancelled plans are not chased.
+			installment("don_6", "rec_cancelled", *at(time.March, 1), fundraiseup.RecurringPlan{
+				EndedAt:           at(time.March, 15),
+				Frequency:         "monthly",
+				NextInstallmentAt: at(time.April, 1),
+				Status:            "canceled",
+			}),
+			// Missed an annual installment last month.
+			installment("don_7", "rec_annual", lastYear, fundraiseup.RecurringPlan{
+				Frequency:         "annual",
+				NextInstallmentAt: at(time.May, 1),
+				Status:            "active",
+			}),
+			// One-off donations are ignored.
+			{Amount: "50.00", CreatedAt: *at(time.January, 1), ID: "don_8"},
+		},
+	}
+	tracker := &mockTracker{
+		records: []storage.DonationRecord{
+			{ConstituentID: "const-1", CreatedAt: *at(time.May, 5), DonationID: "don_2", RecurringID: "rec_missed"},
+			{ConstituentID: "const-1", CreatedAt: *at(time.April, 5), DonationID: "don_1", RecurringID: "rec_missed"},
+			{ConstituentID: "const-5", CreatedAt: *at(time.June, 2), DonationID: "don_9", RecurringID: "rec_synced"},
+			{ConstituentID: "const-8", CreatedAt: *at(time.January, 1), DonationID: "don_8"},
+		},
+	}
+
+	reporter, err := NewReporter(donations, tracker, DefaultGrace)
+	require.NoError(t, err)
+
+	report, err := reporter.Generate(context.Background(), since, now)
+
+	require.NoError(t, err)
+	require.Equal(t, since, donations.since)
+	require.Equal(t, since, tracker.from)
+	require.True(t, tracker.to.After(now))
+	require.Equal(t, []Plan{
+		{
+			Amount:            "10.00",
+			Currency:          "GBP",
+			DueAt:             *at(time.May, 1),
+			Frequency:         "annual",
+			LastInstallmentAt: lastYear,
+			RecurringID:       "rec_annual",
+			SupporterEmail:    "rec_annual@example.com",
+			SupporterID:       "sup_rec_annual",
+		},
+		{
+			Amount:            "10.00",
+			ConstituentID:     "const-1",
+			Currency:          "GBP",
+			DueAt:             *at(time.June, 5),
+			Frequency:         "monthly",
+			LastInstallmentAt: *at(time.May, 5),
+			RecurringID:       "rec_missed",
+			SupporterEmail:    "rec_missed@example.com",
+			SupporterID:       "sup_rec_missed",
+		},
+	}, report.Plans)
+}
+
+func TestReport_Write(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		report       *Report
+		wantContains []string
+	}{
+		"no plans": {
+			report:       &Report{},
+			wantContains: []string{"No recurring plans in arrears."},
+		},
+		"plans": {
+			report: &Report{Plans: []Plan{{
+				Amount:            "10.00",
+				ConstituentID:     "const-1",
+				Currency:          "GBP",
+				DueAt:             time.Date(2024, time.June, 5, 9, 0, 0, 0, time.UTC),
+				Frequency:         "monthly",
+				LastInstallmentAt: time.Date(2024, time.May, 5, 9, 0, 0, 0, time.UTC),
+				RecurringID:       "rec_missed",
+				SupporterEmail:    "jane@example.com",
+				SupporterID:       "sup_1",
+			}}},
+			wantContains: []string{
+				"Recurring plans whose expected installment has not arrived (1):",
+				"DAYS OVERDUE",
+				"rec_missed  sup_1      jane@example.com  const-1      10.00 GBP  monthly    " +
+					"2024-05-05  2024-06-05  15",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, tc.report.Write(&buf, now))
+			for _, want := range tc.wantContains {
+				require.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package awsclient creates the AWS service clients used by giftbridge.
+package awsclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+const (
+	// roleSessionName identifies giftbridge sessions in CloudTrail when assuming a resource role.
+	roleSessionName = "giftbridge"
+)
+
+// Clients holds the AWS service clients used by giftbridge.
+type Clients struct {
+	// DynamoDB is the DynamoDB client used by the donation tracker.
+	DynamoDB *dynamodb.Client
+
+	// S3 is the S3 client used to archive tracker records.
+	S3 *s3.Client
+
+	// SecretsManager is the Secrets Manager client used by the token store.
+	SecretsManager *secretsmanager.Client
+
+	// SSM is the SSM client used by the state store.
+	SSM *ssm.Client
+
+	// STS is the STS client.
+	STS *sts.Client
+}
+
+// New loads the default AWS configuration, applies any overrides in cfg, and creates the service clients.
+// Credentials are resolved through the default chain (environment, shared config, or the Lambda role).
+// When cfg.RoleARN is set, those credentials are used to assume the role, and every client uses the
+// assumed role's credentials instead.
+func New(ctx context.Context, cfg config.AWS) (*Clients, error) {
+	var loadOpts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(cfg.Region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+
+	if cfg.RoleARN != "" {
+		// The base credentials call STS directly, honouring any STS endpoint override.
+		baseSTS := sts.NewFromConfig(awsCfg, func(o *sts.Options) {
+			o.BaseEndpoint = endpoint(cfg.STSEndpoint, cfg.Endpoint, o.BaseEndpoint)
+		})
+		provider := stscreds.NewAssumeRoleProvider(baseSTS, cfg.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = roleSessionName
+			if cfg.RoleExternalID != "" {
+				o.ExternalID = aws.String(cfg.RoleExternalID)
+			}
+		})
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return &Clients{
+		DynamoDB: dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+			o.BaseEndpoint = endpoint(cfg.DynamoDBEndpoint, cfg.Endpoint, o.BaseEndpoint)
+		}),
+		S3: s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+			o.BaseEndpoint = endpoint(cfg.S3Endpoint, cfg.Endpoint, o.BaseEndpoint)
+			// Endpoints such as LocalStack do not serve bucket subdomains.
+			o.UsePathStyle = o.BaseEndpoint != nil
+		}),
+		SecretsManager: secretsmanager.NewFromConfig(awsCfg, func(o *secretsmanager.Options) {
+			o.BaseEndpoint = endpoint(cfg.SecretsManagerEndpoint, cfg.Endpoint, o.BaseEndpoint)
+		}),
+		SSM: ssm.NewFromConfig(awsCfg, func(o *ssm.Options) {
+			o.BaseEndpoint = endpoint(cfg.SSMEndpoint, cfg.Endpoint, o.BaseEndpoint)
+		}),
+		STS: sts.NewFromConfig(awsCfg, func(o *sts.Options) {
+			o.BaseEndpoint = endpoint(cfg.STSEndpoint, cfg.Endpoint, o.BaseEndpoint)
+		}),
+	}, nil
+}
+
+// endpoint returns the service-specific override, then the global override, then the SDK's own value.
+func endpoint(service string, global string, current *string) *string {
+	switch {
+	case service != "":
+		return aws.String(service)
+	case global != "":
+		return aws.String(global)
+	default:
+		return current
+	}
+}
@@ -0,0 +1,94 @@
+package awsclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv().
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	tests := map[string]struct {
+		cfg                config.AWS
+		wantAssumeRole     bool
+		wantDynamoDB       *string
+		wantRegion         string
+		wantS3             *string
+		wantSecretsManager *string
+		wantSSM            *string
+		wantSTS            *string
+	}{
+		"no overrides": {
+			cfg:        config.AWS{},
+			wantRegion: "us-east-1",
+		},
+		"region override": {
+			cfg:        config.AWS{Region: "eu-west-2"},
+			wantRegion: "eu-west-2",
+		},
+		"global endpoint": {
+			cfg:                config.AWS{Endpoint: "http://localhost:4566"},
+			wantDynamoDB:       aws.String("http://localhost:4566"),
+			wantRegion:         "us-east-1",
+			wantS3:             aws.String("http://localhost:4566"),
+			wantSecretsManager: aws.String("http://localhost:4566"),
+			wantSSM:            aws.String("http://localhost:4566"),
+			wantSTS:            aws.String("http://localhost:4566"),
+		},
+		"service endpoint takes precedence over global": {
+			cfg: config.AWS{
+				DynamoDBEndpoint: "http://localhost:8000",
+				Endpoint:         "http://localhost:4566",
+				SSMEndpoint:      "https://vpce-123.ssm.us-east-1.vpce.amazonaws.com",
+			},
+			wantDynamoDB:       aws.String("http://localhost:8000"),
+			wantRegion:         "us-east-1",
+			wantS3:             aws.String("http://localhost:4566"),
+			wantSecretsManager: aws.String("http://localhost:4566"),
+			wantSSM:            aws.String("https://vpce-123.ssm.us-east-1.vpce.amazonaws.com"),
+			wantSTS:            aws.String("http://localhost:4566"),
+		},
+		"assume role": {
+			cfg: config.AWS{
+				RoleARN:        "arn:aws:iam::123456789012:role/giftbridge-resources",
+				RoleExternalID: "charity-123",
+			},
+			wantAssumeRole: true,
+			wantRegion:     "us-east-1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			clients, err := New(context.Background(), tc.cfg)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantDynamoDB, clients.DynamoDB.Options().BaseEndpoint)
+			require.Equal(t, tc.wantS3, clients.S3.Options().BaseEndpoint)
+			require.Equal(t, tc.wantS3 != nil, clients.S3.Options().UsePathStyle)
+			require.Equal(t, tc.wantSecretsManager, clients.SecretsManager.Options().BaseEndpoint)
+			require.Equal(t, tc.wantSSM, clients.SSM.Options().BaseEndpoint)
+			require.Equal(t, tc.wantSTS, clients.STS.Options().BaseEndpoint)
+			require.Equal(t, tc.wantRegion, clients.SSM.Options().Region)
+
+			for _, creds := range []aws.CredentialsProvider{
+				clients.DynamoDB.Options().Credentials,
+				clients.S3.Options().Credentials,
+				clients.SecretsManager.Options().Credentials,
+				clients.SSM.Options().Credentials,
+				clients.STS.Options().Credentials,
+			} {
+				require.Equal(t, tc.wantAssumeRole, aws.IsCredentialsProvider(creds, (*stscreds.AssumeRoleProvider)(nil)))
+			}
+		})
+	}
+}
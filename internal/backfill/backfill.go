@@ -0,0 +1,586 @@
+// Package backfill splits a window of historical donations into batches that a Step Functions Distributed Map
+// processes in parallel on the sync Lambda, and aggregates the batches' results into a report,
+// for initial migrations of many thousands of donations.
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+const (
+	// DefaultBatchSize is how many donations each batch holds by default, few enough for one Lambda invocation
+	// to process well within its 15 minute limit.
+	DefaultBatchSize = 100
+
+	// contentType is the media type of manifest and result objects.
+	contentType = "application/json"
+)
+
+// S3API defines the S3 operations needed to dispatch batches and collect their results.
+type S3API interface {
+	// GetObject retrieves an object.
+	GetObject(
+		ctx context.Context,
+		params *s3.GetObjectInput,
+		optFns ...func(*s3.Options),
+	) (*s3.GetObjectOutput, error)
+
+	// PutObject stores an object.
+	PutObject(
+		ctx context.Context,
+		params *s3.PutObjectInput,
+		optFns ...func(*s3.Options),
+	) (*s3.PutObjectOutput, error)
+}
+
+// DonationLister lists donations in pages, oldest first, as the FundraiseUp client does.
+type DonationLister interface {
+	// DonationPages calls fn with each page of donations created after since, starting after the donation
+	// with ID startingAfter. Returning fundraiseup.ErrStop from fn stops the iteration without an error.
+	DonationPages(
+		ctx context.Context,
+		since time.Time,
+		startingAfter string,
+		fn func([]fundraiseup.Donation) error,
+	) error
+}
+
+// Batch is one batch of a backfill, and the input of the sync Lambda invocation that processes it.
+// Each manifest is a JSON array of batches, read by the Distributed Map as its items.
+type Batch struct {
+	// Attempt is the dispatch the batch belongs to: 0 for the first, and 1 onwards for each resume.
+	Attempt int `json:"attempt"`
+
+	// Backfill is the ID of the backfill. The Lambda treats an event with it set as a batch to process.
+	Backfill string `json:"backfill"`
+
+	// Bucket is the S3 bucket the result is written to.
+	Bucket string `json:"bucket"`
+
+	// DonationIDs are the FundraiseUp donations to process, in the order they were made.
+	DonationIDs []string `json:"donationIds"`
+
+	// Number identifies the batch within the backfill, from 1.
+	Number int `json:"batch"`
+
+	// ResultKey is the object key the result is written to.
+	ResultKey string `json:"resultKey"`
+}
+
+// BatchResult is the outcome of processing one batch, written by the Lambda to the batch's ResultKey.
+type BatchResult struct {
+	// ConstituentsCreated is the number of new constituents created.
+	ConstituentsCreated int `json:"constituentsCreated"`
+
+	// DonationsProcessed is the number of donations processed.
+	DonationsProcessed int `json:"donationsProcessed"`
+
+	// Errors describes the donations that failed.
+	Errors []string `json:"errors,omitempty"`
+
+	// FinishedAt is when the batch finished processing.
+	FinishedAt time.Time `json:"finishedAt"`
+
+	// GiftsCreated is the number of new gifts created.
+	GiftsCreated int `json:"giftsCreated"`
+
+	// GiftsSkippedExisting is the number of gifts skipped because they already existed.
+	GiftsSkippedExisting int `json:"giftsSkippedExisting"`
+
+	// GiftsUpdated is the number of existing gifts updated.
+	GiftsUpdated int `json:"giftsUpdated"`
+
+	// RemainingDonationIDs lists the donations not processed because the invocation ran out of time
+	// or paused for quota. Resuming the backfill dispatches them again.
+	RemainingDonationIDs []string `json:"remainingDonationIds,omitempty"`
+}
+
+// Dispatcher writes the manifests of backfills to S3 and reads back their results.
+type Dispatcher struct {
+	batchSize int
+	bucket    string
+	prefix    string
+	s3        S3API
+}
+
+// Option configures a Dispatcher.
+type Option func(*Dispatcher)
+
+// Plan describes a dispatched manifest, to be started as a Step Functions execution.
+type Plan struct {
+	// Attempt is the dispatch the manifest belongs to: 0 for the first, and 1 onwards for each resume.
+	Attempt int
+
+	// Batches is the number of batches in the manifest.
+	Batches int
+
+	// Bucket is the S3 bucket holding the manifest.
+	Bucket string
+
+	// Donations is the number of donations in the manifest's batches.
+	Donations int
+
+	// ID is the ID of the backfill.
+	ID string
+
+	// Key is the object key of the manifest.
+	Key string
+}
+
+// Report totals the results of a backfill's batches.
+type Report struct {
+	// Attempts is the number of times the backfill has been dispatched: 1, plus one for each resume.
+	Attempts int
+
+	// Batches is the number of batches the backfill was split into.
+	Batches int
+
+	// ConstituentsCreated is the number of new constituents created.
+	ConstituentsCreated int
+
+	// Donations is the number of donations the backfill was dispatched with.
+	Donations int
+
+	// DonationsProcessed is the number of donations processed, including those that failed.
+	DonationsProcessed int
+
+	// DonationsRemaining is the number of donations in unfinished batches not yet processed.
+	DonationsRemaining int
+
+	// Errors describes the donations that failed, prefixed with their batch number.
+	Errors []string
+
+	// GiftsCreated is the number of new gifts created.
+	GiftsCreated int
+
+	// GiftsSkippedExisting is the number of gifts skipped because they already existed.
+	GiftsSkippedExisting int
+
+	// GiftsUpdated is the number of existing gifts updated.
+	GiftsUpdated int
+
+	// ID is the ID of the backfill.
+	ID string
+
+	// Unfinished lists the numbers of the batches with no result yet or with donations left unprocessed.
+	Unfinished []int
+}
+
+// attempt is one dispatch of a batch, with its result once written.
+type attempt struct {
+	batch  Batch
+	result *BatchResult
+}
+
+// state is what has been dispatched for a backfill and what its batches have reported.
+type state struct {
+	// attempts is the number of manifests written, and so the attempt number of the next.
+	attempts int
+
+	// donations is the number of donations dispatched by the first manifest.
+	donations int
+
+	// latest is the most recent dispatch of each batch, by batch number.
+	latest map[int]attempt
+
+	// results are the dispatches that have written a result, in the order they were dispatched.
+	results []attempt
+}
+
+// WithBatchSize sets how many donations each batch holds. Default is DefaultBatchSize.
+// A batch can hold more, as every donation from one supporter goes in the same batch.
+func WithBatchSize(size int) Option {
+	return func(d *Dispatcher) {
+		d.batchSize = size
+	}
+}
+
+// WithPrefix sets the prefix of object keys, e.g. "backfills/".
+func WithPrefix(prefix string) Option {
+	return func(d *Dispatcher) {
+		d.prefix = prefix
+	}
+}
+
+// NewDispatcher creates a new dispatcher writing to bucket.
+func NewDispatcher(client S3API, bucket string, opts ...Option) (*Dispatcher, error) {
+	if client == nil {
+		return nil, errors.New("s3 client is required")
+	}
+	if bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	dispatcher := &Dispatcher{
+		batchSize: DefaultBatchSize,
+		bucket:    bucket,
+		s3:        client,
+	}
+
+	for _, opt := range opts {
+		opt(dispatcher)
+	}
+
+	if dispatcher.batchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	return dispatcher, nil
+}
+
+// Dispatch lists the donations made in [since, until) and writes them to S3 in batches, as the first manifest of
+// the backfill with the given ID. Every donation from one supporter goes in the same batch, in the order they were
+// made, so batches processed in parallel never create the same constituent twice or link a recurring payment
+// before the first payment of its plan.
+func (d *Dispatcher) Dispatch(
+	ctx context.Context,
+	id string,
+	lister DonationLister,
+	since time.Time,
+	until time.Time,
+) (*Plan, error) {
+	if id == "" {
+		return nil, errors.New("backfill ID is required")
+	}
+	if !until.After(since) {
+		return nil, errors.New("until must be after since")
+	}
+
+	exists, err := d.exists(ctx, d.manifestKey(id, 0))
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("backfill %s has already been dispatched", id)
+	}
+
+	var donations []fundraiseup.Donation
+	err = lister.DonationPages(ctx, since, "", func(page []fundraiseup.Donation) error {
+		for _, donation := range page {
+			if !donation.CreatedAt.Before(until) {
+				return fundraiseup.ErrStop
+			}
+			donations = append(donations, donation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing donations: %w", err)
+	}
+
+	var batches []Batch
+	for i, ids := range group(donations, d.batchSize) {
+		batches = append(batches, d.batch(id, i+1, 0, ids))
+	}
+
+	return d.writeManifest(ctx, id, 0, batches)
+}
+
+// Resume writes a new manifest for the batches of a backfill that are unfinished: those with no result, because
+// their invocation failed, and those that left donations unprocessed. It returns nil when every batch has finished.
+// Only resume once the backfill's executions have stopped, or donations still being processed are dispatched again.
+func (d *Dispatcher) Resume(ctx context.Context, id string) (*Plan, error) {
+	state, err := d.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []Batch
+	for _, number := range state.outstanding() {
+		latest := state.latest[number]
+		ids := latest.batch.DonationIDs
+		if latest.result != nil {
+			ids = latest.result.RemainingDonationIDs
+		}
+		batches = append(batches, d.batch(id, number, state.attempts, ids))
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	return d.writeManifest(ctx, id, state.attempts, batches)
+}
+
+// Report reads the results written so far by the batches of a backfill and totals them.
+func (d *Dispatcher) Report(ctx context.Context, id string) (*Report, error) {
+	state, err := d.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Attempts: state.attempts,
+		Batches:  len(state.latest),
+		ID:       id,
+	}
+	for _, attempt := range state.results {
+		report.add(attempt.batch.Number, *attempt.result)
+	}
+	for _, number := range state.outstanding() {
+		latest := state.latest[number]
+		report.Unfinished = append(report.Unfinished, number)
+		if latest.result == nil {
+			report.DonationsRemaining += len(latest.batch.DonationIDs)
+		} else {
+			report.DonationsRemaining += len(latest.result.RemainingDonationIDs)
+		}
+	}
+	report.Donations = state.donations
+
+	return report, nil
+}
+
+// WriteResult writes the result of processing a batch to its ResultKey.
+func WriteResult(ctx context.Context, client S3API, batch Batch, result BatchResult) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("encoding result of batch %d: %w", batch.Number, err)
+	}
+
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Body:        bytes.NewReader(body),
+		Bucket:      aws.String(batch.Bucket),
+		ContentType: aws.String(contentType),
+		Key:         aws.String(batch.ResultKey),
+	})
+	if err != nil {
+		return fmt.Errorf("writing %s to bucket %s: %w", batch.ResultKey, batch.Bucket, err)
+	}
+
+	return nil
+}
+
+// outstanding returns the numbers of the batches whose latest dispatch has not written a result
+// or left donations unprocessed, in order.
+func (s *state) outstanding() []int {
+	var numbers []int
+	for number := 1; number <= len(s.latest); number++ {
+		latest, ok := s.latest[number]
+		if !ok {
+			continue
+		}
+		if latest.result == nil || len(latest.result.RemainingDonationIDs) > 0 {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// load reads every manifest of a backfill and the results of their batches.
+func (d *Dispatcher) load(ctx context.Context, id string) (*state, error) {
+	s := &state{latest: make(map[int]attempt)}
+	for {
+		var batches []Batch
+		found, err := d.read(ctx, d.manifestKey(id, s.attempts), &batches)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			break
+		}
+
+		for _, batch := range batches {
+			current := attempt{batch: batch}
+			var result BatchResult
+			found, err := d.read(ctx, batch.ResultKey, &result)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				current.result = &result
+				s.results = append(s.results, current)
+			}
+			s.latest[batch.Number] = current
+			if s.attempts == 0 {
+				s.donations += len(batch.DonationIDs)
+			}
+		}
+		s.attempts++
+	}
+
+	if s.attempts == 0 {
+		return nil, fmt.Errorf("backfill %s not found in bucket %s", id, d.bucket)
+	}
+	return s, nil
+}
+
+// batch returns the batch with the given number for a dispatch of the backfill.
+func (d *Dispatcher) batch(id string, number int, attempt int, donationIDs []string) Batch {
+	return Batch{
+		Attempt:     attempt,
+		Backfill:    id,
+		Bucket:      d.bucket,
+		DonationIDs: donationIDs,
+		Number:      number,
+		ResultKey:   fmt.Sprintf("%s%s/results/%05d-%d.json", d.prefix, id, number, attempt),
+	}
+}
+
+// manifestKey returns the object key of a backfill's manifest for the given dispatch.
+func (d *Dispatcher) manifestKey(id string, attempt int) string {
+	return fmt.Sprintf("%s%s/manifests/%d.json", d.prefix, id, attempt)
+}
+
+// writeManifest writes the batches of one dispatch of a backfill.
+func (d *Dispatcher) writeManifest(ctx context.Context, id string, attempt int, batches []Batch) (*Plan, error) {
+	plan := &Plan{
+		Attempt: attempt,
+		Batches: len(batches),
+		Bucket:  d.bucket,
+		ID:      id,
+		Key:     d.manifestKey(id, attempt),
+	}
+	for _, batch := range batches {
+		plan.Donations += len(batch.DonationIDs)
+	}
+
+	// An empty manifest is still written, so reports show the backfill had nothing to do.
+	if batches == nil {
+		batches = []Batch{}
+	}
+	body, err := json.Marshal(batches)
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	_, err = d.s3.PutObject(ctx, &s3.PutObjectInput{
+		Body:        bytes.NewReader(body),
+		Bucket:      aws.String(d.bucket),
+		ContentType: aws.String(contentType),
+		Key:         aws.String(plan.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("writing %s to bucket %s: %w", plan.Key, d.bucket, err)
+	}
+
+	return plan, nil
+}
+
+// exists returns true if the bucket already holds an object with the given key.
+func (d *Dispatcher) exists(ctx context.Context, key string) (bool, error) {
+	var manifest []Batch
+	return d.read(ctx, key, &manifest)
+}
+
+// read decodes the JSON object with the given key into v, returning false if there is no such object.
+func (d *Dispatcher) read(ctx context.Context, key string, v any) (bool, error) {
+	out, err := d.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading %s from bucket %s: %w", key, d.bucket, err)
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	if err := json.NewDecoder(out.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("decoding %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// group splits donations into batches of donation IDs of about size each, keeping every donation from one
+// supporter in the first batch they appear in. Donations stay in the order given.
+func group(donations []fundraiseup.Donation, size int) [][]string {
+	var batches [][]string
+	bySupporter := make(map[string]int)
+	current := -1
+	for _, donation := range donations {
+		key := supporterKey(donation)
+		if i, ok := bySupporter[key]; ok {
+			batches[i] = append(batches[i], donation.ID)
+			continue
+		}
+
+		if current < 0 || len(batches[current]) >= size {
+			batches = append(batches, nil)
+			current = len(batches) - 1
+		}
+		batches[current] = append(batches[current], donation.ID)
+		if key != "" {
+			bySupporter[key] = current
+		}
+	}
+	return batches
+}
+
+// supporterKey identifies the supporter who made a donation by their email, which constituents are matched by,
+// or their FundraiseUp ID when they have none. Returns an empty key for a donation with no supporter.
+func supporterKey(donation fundraiseup.Donation) string {
+	if donation.Supporter == nil {
+		return ""
+	}
+	if email := strings.ToLower(strings.TrimSpace(donation.Supporter.Email)); email != "" {
+		return "email:" + email
+	}
+	if donation.Supporter.ID != "" {
+		return "id:" + donation.Supporter.ID
+	}
+	return ""
+}
+
+// add totals the result of a batch into the report.
+func (r *Report) add(number int, result BatchResult) {
+	r.ConstituentsCreated += result.ConstituentsCreated
+	r.DonationsProcessed += result.DonationsProcessed
+	r.GiftsCreated += result.GiftsCreated
+	r.GiftsSkippedExisting += result.GiftsSkippedExisting
+	r.GiftsUpdated += result.GiftsUpdated
+	for _, msg := range result.Errors {
+		r.Errors = append(r.Errors, fmt.Sprintf("batch %d: %s", number, msg))
+	}
+}
+
+// Write writes the report as text.
+func (r *Report) Write(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Backfill %s: %d donations in %d batches, dispatched %d times\n",
+		r.ID, r.Donations, r.Batches, r.Attempts)
+	fmt.Fprintf(&b, "  Donations processed:    %d\n", r.DonationsProcessed)
+	fmt.Fprintf(&b, "  Constituents created:   %d\n", r.ConstituentsCreated)
+	fmt.Fprintf(&b, "  Gifts created:          %d\n", r.GiftsCreated)
+	fmt.Fprintf(&b, "  Gifts updated:          %d\n", r.GiftsUpdated)
+	fmt.Fprintf(&b, "  Gifts already existing: %d\n", r.GiftsSkippedExisting)
+	fmt.Fprintf(&b, "  Errors:                 %d\n", len(r.Errors))
+
+	if len(r.Unfinished) == 0 {
+		b.WriteString("Every batch has finished.\n")
+	} else {
+		fmt.Fprintf(&b, "%d batches unfinished, with %d donations still to process: %s\n",
+			len(r.Unfinished), r.DonationsRemaining, joinNumbers(r.Unfinished))
+	}
+
+	for _, msg := range r.Errors {
+		fmt.Fprintf(&b, "  %s\n", msg)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// joinNumbers formats batch numbers as a comma-separated list.
+func joinNumbers(numbers []int) string {
+	parts := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		parts = append(parts, fmt.Sprint(number))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,318 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+type mockS3Client struct {
+	objects map[string]string
+}
+
+func (m *mockS3Client) GetObject(
+	_ context.Context,
+	params *s3.GetObjectInput,
+	_ ...func(*s3.Options),
+) (*s3.GetObjectOutput, error) {
+	body, ok := m.objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func (m *mockS3Client) PutObject(
+	_ context.Context,
+	params *s3.PutObjectInput,
+	_ ...func(*s3.Options),
+) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	if m.objects == nil {
+		m.objects = make(map[string]string)
+	}
+	m.objects[aws.ToString(params.Key)] = string(body)
+	return &s3.PutObjectOutput{}, nil
+}
+
+// manifest decodes the manifest stored under key.
+func (m *mockS3Client) manifest(t *testing.T, key string) []Batch {
+	t.Helper()
+
+	var batches []Batch
+	require.Contains(t, m.objects, key)
+	require.NoError(t, json.Unmarshal([]byte(m.objects[key]), &batches))
+	return batches
+}
+
+type mockLister struct {
+	donations []fundraiseup.Donation
+	err       error
+}
+
+func (m *mockLister) DonationPages(
+	_ context.Context,
+	since time.Time,
+	_ string,
+	fn func([]fundraiseup.Donation) error,
+) error {
+	if m.err != nil {
+		return m.err
+	}
+	var page []fundraiseup.Donation
+	for _, donation := range m.donations {
+		if !donation.CreatedAt.Before(since) {
+			page = append(page, donation)
+		}
+	}
+	if err := fn(page); err != nil && !errors.Is(err, fundraiseup.ErrStop) {
+		return err
+	}
+	return nil
+}
+
+// donation returns a donation made on the given day of March 2025 by the supporter with the given email.
+func donation(id string, day int, email string) fundraiseup.Donation {
+	return fundraiseup.Donation{
+		CreatedAt: time.Date(2025, time.March, day, 0, 0, 0, 0, time.UTC),
+		ID:        id,
+		Supporter: &fundraiseup.Supporter{Email: email},
+	}
+}
+
+func TestNewDispatcher(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		bucket string
+		client S3API
+		errMsg string
+		opts   []Option
+	}{
+		"valid": {
+			bucket: "my-backfills",
+			client: &mockS3Client{},
+			opts:   []Option{WithBatchSize(50), WithPrefix("giftbridge/")},
+		},
+		"missing client": {
+			bucket: "my-backfills",
+			errMsg: "s3 client is required",
+		},
+		"missing bucket": {
+			client: &mockS3Client{},
+			errMsg: "bucket is required",
+		},
+		"zero batch size": {
+			bucket: "my-backfills",
+			client: &mockS3Client{},
+			errMsg: "batch size must be positive",
+			opts:   []Option{WithBatchSize(0)},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dispatcher, err := NewDispatcher(tc.client, tc.bucket, tc.opts...)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, dispatcher)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, dispatcher)
+		})
+	}
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	donations := []fundraiseup.Donation{
+		donation("don_1", 1, "ada@example.com"),
+		donation("don_2", 2, "bob@example.com"),
+		donation("don_3", 3, "cy@example.com"),
+		donation("don_4", 4, "ADA@example.com"),
+		donation("don_5", 5, "dee@example.com"),
+		donation("don_6", 12, "eve@example.com"),
+	}
+
+	tests := map[string]struct {
+		batchSize   int
+		existing    map[string]string
+		errMsg      string
+		listErr     error
+		wantBatches [][]string
+	}{
+		"groups each supporter's donations in one batch": {
+			batchSize:   2,
+			wantBatches: [][]string{{"don_1", "don_2", "don_4"}, {"don_3", "don_5"}},
+		},
+		"one batch for the window": {
+			batchSize:   100,
+			wantBatches: [][]string{{"don_1", "don_2", "don_3", "don_4", "don_5"}},
+		},
+		"already dispatched": {
+			batchSize: 100,
+			existing:  map[string]string{"backfills/bf-1/manifests/0.json": "[]"},
+			errMsg:    "backfill bf-1 has already been dispatched",
+		},
+		"listing fails": {
+			batchSize: 100,
+			listErr:   errors.New("connection refused"),
+			errMsg:    "listing donations: connection refused",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockS3Client{objects: tc.existing}
+			dispatcher, err := NewDispatcher(client, "my-backfills",
+				WithBatchSize(tc.batchSize), WithPrefix("backfills/"))
+			require.NoError(t, err)
+
+			lister := &mockLister{donations: donations, err: tc.listErr}
+			plan, err := dispatcher.Dispatch(context.Background(), "bf-1", lister, since, until)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "backfills/bf-1/manifests/0.json", plan.Key)
+			require.Equal(t, len(tc.wantBatches), plan.Batches)
+			require.Equal(t, 5, plan.Donations)
+
+			batches := client.manifest(t, plan.Key)
+			require.Len(t, batches, len(tc.wantBatches))
+			for i, batch := range batches {
+				require.Equal(t, tc.wantBatches[i], batch.DonationIDs)
+				require.Equal(t, i+1, batch.Number)
+				require.Equal(t, "bf-1", batch.Backfill)
+				require.Equal(t, "my-backfills", batch.Bucket)
+			}
+			require.Equal(t, "backfills/bf-1/results/00001-0.json", batches[0].ResultKey)
+		})
+	}
+}
+
+func TestDispatcher_ResumeAndReport(t *testing.T) {
+	t.Parallel()
+
+	client := &mockS3Client{}
+	dispatcher, err := NewDispatcher(client, "my-backfills", WithBatchSize(2))
+	require.NoError(t, err)
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	lister := &mockLister{donations: []fundraiseup.Donation{
+		donation("don_1", 1, "ada@example.com"),
+		donation("don_2", 2, "bob@example.com"),
+		donation("don_3", 3, "cy@example.com"),
+		donation("don_4", 4, "dee@example.com"),
+		donation("don_5", 5, "eve@example.com"),
+		donation("don_6", 6, "fay@example.com"),
+	}}
+	plan, err := dispatcher.Dispatch(context.Background(), "bf-1", lister, since, until)
+	require.NoError(t, err)
+	require.Equal(t, 3, plan.Batches)
+
+	// Batch 1 finishes, batch 2 runs out of time after one donation, and batch 3 fails without a result.
+	batches := client.manifest(t, plan.Key)
+	require.NoError(t, WriteResult(context.Background(), client, batches[0], BatchResult{
+		ConstituentsCreated: 2,
+		DonationsProcessed:  2,
+		Errors:              []string{"donation don_2: fund not found"},
+		GiftsCreated:        1,
+	}))
+	require.NoError(t, WriteResult(context.Background(), client, batches[1], BatchResult{
+		DonationsProcessed:   1,
+		GiftsCreated:         1,
+		RemainingDonationIDs: []string{"don_4"},
+	}))
+
+	report, err := dispatcher.Report(context.Background(), "bf-1")
+	require.NoError(t, err)
+	require.Equal(t, &Report{
+		Attempts:            1,
+		Batches:             3,
+		ConstituentsCreated: 2,
+		Donations:           6,
+		DonationsProcessed:  3,
+		DonationsRemaining:  3,
+		Errors:              []string{"batch 1: donation don_2: fund not found"},
+		GiftsCreated:        2,
+		ID:                  "bf-1",
+		Unfinished:          []int{2, 3},
+	}, report)
+
+	resumed, err := dispatcher.Resume(context.Background(), "bf-1")
+	require.NoError(t, err)
+	require.Equal(t, "bf-1/manifests/1.json", resumed.Key)
+	require.Equal(t, 2, resumed.Batches)
+	require.Equal(t, 3, resumed.Donations)
+
+	retried := client.manifest(t, resumed.Key)
+	require.Len(t, retried, 2)
+	require.Equal(t, []string{"don_4"}, retried[0].DonationIDs)
+	require.Equal(t, "bf-1/results/00002-1.json", retried[0].ResultKey)
+	require.Equal(t, []string{"don_5", "don_6"}, retried[1].DonationIDs)
+	for _, batch := range retried {
+		require.NoError(t, WriteResult(context.Background(), client, batch, BatchResult{
+			DonationsProcessed: len(batch.DonationIDs),
+			GiftsCreated:       len(batch.DonationIDs),
+		}))
+	}
+
+	report, err = dispatcher.Report(context.Background(), "bf-1")
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Attempts)
+	require.Equal(t, 6, report.DonationsProcessed)
+	require.Equal(t, 5, report.GiftsCreated)
+	require.Zero(t, report.DonationsRemaining)
+	require.Empty(t, report.Unfinished)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+	require.Contains(t, out.String(), "Backfill bf-1: 6 donations in 3 batches, dispatched 2 times")
+	require.Contains(t, out.String(), "Every batch has finished.")
+
+	resumed, err = dispatcher.Resume(context.Background(), "bf-1")
+	require.NoError(t, err)
+	require.Nil(t, resumed)
+}
+
+func TestDispatcher_ReportNotFound(t *testing.T) {
+	t.Parallel()
+
+	dispatcher, err := NewDispatcher(&mockS3Client{}, "my-backfills")
+	require.NoError(t, err)
+
+	_, err = dispatcher.Report(context.Background(), "bf-missing")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "backfill bf-missing not found in bucket my-backfills")
+}
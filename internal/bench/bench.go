@@ -0,0 +1,199 @@
+// Package bench measures sync throughput against in-memory fakes of FundraiseUp and Blackbaud, so concurrency and
+// cache settings can be compared without calling either API or using quota.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	gosync "sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/lru"
+	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+// defaultDonationsPerSupporter is how many donations each supporter makes on average by default, so repeat donors
+// exercise the constituent and gift caches.
+const defaultDonationsPerSupporter = 4
+
+// Config configures a benchmark run.
+type Config struct {
+	// Concurrency is how many batches of donations are synced at once, each by its own sync service as the
+	// batches of a parallel backfill are. Defaults to 1.
+	Concurrency int
+
+	// Donations is the number of donations to sync.
+	Donations int
+
+	// GiftCacheSize is how many constituents' gifts each sync caches. Zero uses the sync's default.
+	GiftCacheSize int
+
+	// Latency is how long each fake API call takes, to approximate the network. Zero measures GiftBridge alone.
+	Latency time.Duration
+
+	// Supporters is the number of distinct supporters making the donations in turn. Defaults to a quarter of
+	// Donations.
+	Supporters int
+}
+
+// Report is the outcome of a benchmark run.
+type Report struct {
+	// BlackbaudCalls is the number of calls made to the fake Blackbaud API.
+	BlackbaudCalls int
+
+	// Concurrency is how many batches were synced at once.
+	Concurrency int
+
+	// Donations is the number of donations processed.
+	Donations int
+
+	// Duration is the wall-clock time taken to sync every batch.
+	Duration time.Duration
+
+	// Errors is the number of donations that failed.
+	Errors int
+
+	// GiftCache counts the gift cache lookups and evictions across every batch.
+	GiftCache lru.Stats
+
+	// GiftCacheSize is the gift cache size configured, or zero for the sync's default.
+	GiftCacheSize int
+
+	// Latency is how long each fake API call took.
+	Latency time.Duration
+}
+
+// validate reports every problem with the configuration.
+func (c Config) validate() error {
+	var errs []error
+	if c.Concurrency < 0 {
+		errs = append(errs, errors.New("concurrency must not be negative"))
+	}
+	if c.Donations <= 0 {
+		errs = append(errs, errors.New("donations must be positive"))
+	}
+	if c.GiftCacheSize < 0 {
+		errs = append(errs, errors.New("gift cache size must not be negative"))
+	}
+	if c.Latency < 0 {
+		errs = append(errs, errors.New("latency must not be negative"))
+	}
+	if c.Supporters < 0 {
+		errs = append(errs, errors.New("supporters must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Run generates cfg.Donations one-off donations and syncs them into an empty fake Blackbaud, split into
+// cfg.Concurrency batches synced at once.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	concurrency := max(cfg.Concurrency, 1)
+	supporters := cfg.Supporters
+	if supporters == 0 {
+		supporters = max(cfg.Donations/defaultDonationsPerSupporter, 1)
+	}
+
+	since := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	source := newDonationSource(cfg.Donations, supporters, since, cfg.Latency)
+	client := newBlackbaudClient(cfg.Latency)
+
+	// Each batch is a contiguous run of donations, as backfills split them, so a repeat donor's donations can be
+	// in several batches.
+	batchSize := (cfg.Donations + concurrency - 1) / concurrency
+	var services []*sync.Service
+	for start := 0; start < cfg.Donations; start += batchSize {
+		ids := make([]string, 0, batchSize)
+		for _, donation := range source.donations[start:min(start+batchSize, cfg.Donations)] {
+			ids = append(ids, donation.ID)
+		}
+		svc, err := sync.New(sync.Config{
+			Blackbaud:     client,
+			DonationIDs:   ids,
+			FundraiseUp:   source,
+			GiftCacheSize: cfg.GiftCacheSize,
+			GiftDefaults:  config.GiftDefaults{FundID: "fund-bench", Type: "Donation"},
+			Logger:        slog.New(slog.DiscardHandler),
+			StateStore:    storage.NewNoopStateStore(since),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating sync service: %w", err)
+		}
+		services = append(services, svc)
+	}
+
+	results := make([]*sync.Result, len(services))
+	errs := make([]error, len(services))
+	var wg gosync.WaitGroup
+	start := time.Now()
+	for i, svc := range services {
+		wg.Go(func() {
+			results[i], errs[i] = svc.Run(ctx)
+		})
+	}
+	wg.Wait()
+	duration := time.Since(start)
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("running sync: %w", err)
+	}
+
+	report := &Report{
+		Concurrency:   concurrency,
+		Duration:      duration,
+		GiftCacheSize: cfg.GiftCacheSize,
+		Latency:       cfg.Latency,
+	}
+	for _, result := range results {
+		report.BlackbaudCalls += result.Metrics.Blackbaud.Calls
+		report.Donations += result.DonationsProcessed
+		report.Errors += len(result.Errors)
+		report.GiftCache.Evictions += result.Metrics.GiftCache.Evictions
+		report.GiftCache.Hits += result.Metrics.GiftCache.Hits
+		report.GiftCache.Misses += result.Metrics.GiftCache.Misses
+	}
+
+	return report, nil
+}
+
+// DonationsPerSecond returns the number of donations processed per second of the run.
+func (r *Report) DonationsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Donations) / r.Duration.Seconds()
+}
+
+// WriteTable writes reports as an aligned table, one row per run.
+func WriteTable(w io.Writer, reports []Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONCURRENCY\tGIFT CACHE\tLATENCY\tDONATIONS\tERRORS\tDURATION\tDONATIONS/S\tBLACKBAUD CALLS\t"+
+		"CACHE HITS\tCACHE MISSES")
+	for _, r := range reports {
+		giftCache := "default"
+		if r.GiftCacheSize > 0 {
+			giftCache = fmt.Sprint(r.GiftCacheSize)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%s\t%.0f\t%d\t%d\t%d\n",
+			r.Concurrency,
+			giftCache,
+			r.Latency,
+			r.Donations,
+			r.Errors,
+			r.Duration.Round(time.Millisecond),
+			r.DonationsPerSecond(),
+			r.BlackbaudCalls,
+			r.GiftCache.Hits,
+			r.GiftCache.Misses)
+	}
+	return tw.Flush()
+}
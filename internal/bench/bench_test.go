@@ -0,0 +1,118 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg                Config
+		errMsg             string
+		wantBlackbaudCalls int
+		wantCacheHits      int
+	}{
+		"one batch": {
+			// A search and create for each of the 5 supporters, then a gift list for each and a gift per donation.
+			cfg:                Config{Donations: 20, Supporters: 5},
+			wantBlackbaudCalls: 5*2 + 5 + 20,
+			wantCacheHits:      15,
+		},
+		"gift cache of one": {
+			// Supporters donate in turn, so each gift list is evicted before the supporter donates again.
+			cfg:                Config{Donations: 20, GiftCacheSize: 1, Supporters: 5},
+			wantBlackbaudCalls: 5*2 + 20 + 20,
+		},
+		"concurrent batches": {
+			// Each batch has its own supporters, so no constituent is searched for by both at once.
+			cfg:                Config{Concurrency: 2, Donations: 40, Supporters: 40},
+			wantBlackbaudCalls: 40*2 + 40 + 40,
+		},
+		"default supporters": {
+			cfg:                Config{Donations: 8},
+			wantBlackbaudCalls: 2*2 + 2 + 8,
+			wantCacheHits:      6,
+		},
+		"no donations": {
+			errMsg: "invalid config: donations must be positive",
+		},
+		"negative settings": {
+			cfg: Config{Concurrency: -1, Donations: 10, GiftCacheSize: -1, Latency: -time.Second, Supporters: -1},
+			errMsg: "concurrency must not be negative\ngift cache size must not be negative\n" +
+				"latency must not be negative\nsupporters must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := Run(context.Background(), tc.cfg)
+
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				require.Nil(t, report)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.cfg.Donations, report.Donations)
+			require.Zero(t, report.Errors)
+			require.Equal(t, max(tc.cfg.Concurrency, 1), report.Concurrency)
+			require.Equal(t, tc.wantBlackbaudCalls, report.BlackbaudCalls)
+			require.Equal(t, tc.wantCacheHits, report.GiftCache.Hits)
+			require.Positive(t, report.DonationsPerSecond())
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := WriteTable(&buf, []Report{
+		{BlackbaudCalls: 35, Concurrency: 1, Donations: 20, Duration: 2 * time.Second},
+		{BlackbaudCalls: 50, Concurrency: 4, Donations: 20, Duration: time.Second, GiftCacheSize: 1,
+			Latency: 50 * time.Millisecond},
+	})
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"1", "default", "0s", "20", "0", "2s", "10", "35", "0", "0"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"4", "1", "50ms", "20", "0", "1s", "20", "50", "0", "0"}, strings.Fields(lines[2]))
+}
+
+// BenchmarkSync reports the donations synced per second against the in-memory fakes, for each concurrency and gift
+// cache size. The fakes answer instantly, so this measures GiftBridge's own overhead; set a Latency to see how the
+// settings hide API round trips.
+func BenchmarkSync(b *testing.B) {
+	const donations = 1000
+
+	for _, concurrency := range []int{1, 4, 16} {
+		for _, giftCacheSize := range []int{1, 1000} {
+			b.Run(fmt.Sprintf("concurrency=%d/gift_cache=%d", concurrency, giftCacheSize), func(b *testing.B) {
+				var processed int
+				var elapsed time.Duration
+				for b.Loop() {
+					report, err := Run(context.Background(), Config{
+						Concurrency:   concurrency,
+						Donations:     donations,
+						GiftCacheSize: giftCacheSize,
+					})
+					require.NoError(b, err)
+					processed += report.Donations
+					elapsed += report.Duration
+				}
+				b.ReportMetric(float64(processed)/elapsed.Seconds(), "donations/s")
+			})
+		}
+	}
+}
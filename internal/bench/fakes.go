@@ -0,0 +1,262 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// donationsPageSize is how many donations the fake FundraiseUp API returns per page, as the real API does by default.
+const donationsPageSize = 100
+
+// donationSource serves generated donations from memory, as the FundraiseUp API would.
+type donationSource struct {
+	// byID holds each donation, by ID.
+	byID map[string]fundraiseup.Donation
+
+	// donations holds the donations, oldest first.
+	donations []fundraiseup.Donation
+
+	// latency is how long each call takes.
+	latency time.Duration
+}
+
+// newDonationSource generates count one-off donations made by supporters distinct supporters in turn, one minute
+// apart from since.
+func newDonationSource(count int, supporters int, since time.Time, latency time.Duration) *donationSource {
+	source := &donationSource{
+		byID:      make(map[string]fundraiseup.Donation, count),
+		donations: make([]fundraiseup.Donation, 0, count),
+		latency:   latency,
+	}
+	for i := range count {
+		supporter := i % supporters
+		donation := fundraiseup.Donation{
+			Amount:    strconv.Itoa(5+i%50) + ".00",
+			CreatedAt: since.Add(time.Duration(i+1) * time.Minute),
+			Currency:  "USD",
+			ID:        fmt.Sprintf("DBENCH%07d", i),
+			Status:    "succeeded",
+			Supporter: &fundraiseup.Supporter{
+				Email:     fmt.Sprintf("supporter%d@example.org", supporter),
+				FirstName: "Supporter",
+				ID:        fmt.Sprintf("sup_bench%d", supporter),
+				LastName:  strconv.Itoa(supporter),
+			},
+		}
+		source.byID[donation.ID] = donation
+		source.donations = append(source.donations, donation)
+	}
+	return source
+}
+
+// Donation returns the donation with the given ID.
+func (s *donationSource) Donation(ctx context.Context, id string) (*fundraiseup.Donation, error) {
+	if err := wait(ctx, s.latency); err != nil {
+		return nil, err
+	}
+	donation, ok := s.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("donation %s not found", id)
+	}
+	return &donation, nil
+}
+
+// DonationPages calls fn with each page of donations created after since, starting after startingAfter.
+func (s *donationSource) DonationPages(
+	ctx context.Context,
+	since time.Time,
+	startingAfter string,
+	fn func([]fundraiseup.Donation) error,
+) error {
+	start := 0
+	for start < len(s.donations) && !s.donations[start].CreatedAt.After(since) {
+		start++
+	}
+	if startingAfter != "" {
+		for i, donation := range s.donations {
+			if donation.ID == startingAfter {
+				start = i + 1
+			}
+		}
+	}
+
+	for ; start < len(s.donations); start += donationsPageSize {
+		if err := wait(ctx, s.latency); err != nil {
+			return err
+		}
+		if err := fn(s.donations[start:min(start+donationsPageSize, len(s.donations))]); err != nil {
+			if errors.Is(err, fundraiseup.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// EventPages calls fn with nothing, as the generated donations have no events.
+func (s *donationSource) EventPages(
+	_ context.Context,
+	_ time.Time,
+	_ string,
+	_ func([]fundraiseup.Event) error,
+) error {
+	return nil
+}
+
+// UnknownFields returns nothing, as the generated donations are not decoded from payloads.
+func (s *donationSource) UnknownFields() []string {
+	return nil
+}
+
+// blackbaudClient keeps constituents and gifts in memory, as the Blackbaud SKY API would. It is safe for
+// concurrent use, so concurrent syncs can share it.
+type blackbaudClient struct {
+	mu sync.Mutex
+
+	// constituents holds the constituent IDs, by lowercase email.
+	constituents map[string]string
+
+	// gifts holds each constituent's gifts, by constituent ID.
+	gifts map[string][]blackbaud.Gift
+
+	// latency is how long each call takes.
+	latency time.Duration
+
+	// nextID numbers the records created.
+	nextID int
+}
+
+// newBlackbaudClient creates an empty blackbaudClient whose calls each take latency.
+func newBlackbaudClient(latency time.Duration) *blackbaudClient {
+	return &blackbaudClient{
+		constituents: make(map[string]string),
+		gifts:        make(map[string][]blackbaud.Gift),
+		latency:      latency,
+	}
+}
+
+// CreateConstituent stores the constituent and returns its new ID.
+func (c *blackbaudClient) CreateConstituent(ctx context.Context, constituent *blackbaud.Constituent) (string, error) {
+	if err := wait(ctx, c.latency); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.newID("constituent")
+	if constituent.Email != nil {
+		c.constituents[strings.ToLower(constituent.Email.Address)] = id
+	}
+	return id, nil
+}
+
+// CreateConstituentCode returns a new constituent code ID.
+func (c *blackbaudClient) CreateConstituentCode(ctx context.Context, _ *blackbaud.ConstituentCode) (string, error) {
+	if err := wait(ctx, c.latency); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.newID("code"), nil
+}
+
+// CreateGift stores the gift under its constituent and returns its new ID.
+func (c *blackbaudClient) CreateGift(ctx context.Context, gift *blackbaud.Gift) (string, error) {
+	if err := wait(ctx, c.latency); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *gift
+	stored.ID = c.newID("gift")
+	c.gifts[gift.ConstituentID] = append(c.gifts[gift.ConstituentID], stored)
+	return stored.ID, nil
+}
+
+// ListGiftsByConstituent returns the constituent's gifts.
+func (c *blackbaudClient) ListGiftsByConstituent(
+	ctx context.Context,
+	constituentID string,
+	_ []blackbaud.GiftType,
+) ([]blackbaud.Gift, error) {
+	if err := wait(ctx, c.latency); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]blackbaud.Gift(nil), c.gifts[constituentID]...), nil
+}
+
+// SearchConstituents returns the constituent with the given email, if one was created.
+func (c *blackbaudClient) SearchConstituents(
+	ctx context.Context,
+	email string,
+	_ blackbaud.SearchOptions,
+) ([]blackbaud.Constituent, error) {
+	if err := wait(ctx, c.latency); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, ok := c.constituents[strings.ToLower(email)]
+	if !ok {
+		return nil, nil
+	}
+	return []blackbaud.Constituent{{Email: &blackbaud.Email{Address: email, Primary: true}, ID: id}}, nil
+}
+
+// UpdateGift replaces the stored gift with the given ID.
+func (c *blackbaudClient) UpdateGift(ctx context.Context, giftID string, gift *blackbaud.Gift) error {
+	if err := wait(ctx, c.latency); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for constituentID, gifts := range c.gifts {
+		for i := range gifts {
+			if gifts[i].ID == giftID {
+				updated := *gift
+				updated.ID = giftID
+				c.gifts[constituentID][i] = updated
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("gift %s not found", giftID)
+}
+
+// newID returns a new record ID of the given kind. The caller must hold c.mu.
+func (c *blackbaudClient) newID(kind string) string {
+	c.nextID++
+	return fmt.Sprintf("%s-%d", kind, c.nextID)
+}
+
+// wait returns after latency, or when ctx is done.
+func wait(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -9,8 +9,16 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
 )
 
+// constituentSearchPageSize is the number of constituents requested per search page.
+const constituentSearchPageSize = 500
+
 // Client is a Blackbaud SKY API client.
 type Client struct {
 	// baseURL is the base URL for API requests.
@@ -19,11 +27,36 @@ type Client struct {
 	// config holds the client configuration.
 	config Config
 
+	// hedgeDelay is how long a read waits for a response before an identical request is sent (zero disables).
+	// Each hedged copy is an extra API call counted against the quota.
+	hedgeDelay time.Duration
+
 	// httpClient is the HTTP client for making requests.
 	httpClient *http.Client
 
+	// keyMu guards the subscription keys in config and secondaryKey, and usingSecondaryKey.
+	keyMu sync.Mutex
+
+	// maxResponseSize is the largest response body read, in bytes.
+	maxResponseSize int
+
+	// quota is the call quota reported by the most recent response.
+	quota Quota
+
+	// quotaMu guards quota.
+	quotaMu sync.Mutex
+
+	// secondaryKey is the subscription key requests fail over to when the SKY API rejects the primary one (optional).
+	secondaryKey string
+
 	// tokenManager handles OAuth token refresh.
 	tokenManager *tokenManager
+
+	// userAgent is the User-Agent header sent with every request, including token refreshes.
+	userAgent string
+
+	// usingSecondaryKey is whether requests are sent with secondaryKey, after the SKY API rejected the primary key.
+	usingSecondaryKey bool
 }
 
 // Config holds the required configuration for creating a Client.
@@ -41,6 +74,20 @@ type Config struct {
 	TokenStore TokenStore
 }
 
+// StatusError is returned when the SKY API responds with a status other than 2xx.
+type StatusError struct {
+	// Body is the response body, which usually describes the problem.
+	Body string
+
+	// StatusCode is the HTTP status code.
+	StatusCode int
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
 // NewClient creates a new Blackbaud SKY API client.
 func NewClient(cfg Config, opts ...Option) (*Client, error) {
 	if err := cfg.validate(); err != nil {
@@ -56,19 +103,58 @@ func NewClient(cfg Config, opts ...Option) (*Client, error) {
 
 	httpClient := o.httpClient
 	if httpClient == nil {
-		httpClient = &http.Client{Timeout: o.timeout}
+		httpClient = &http.Client{Timeout: o.timeout, Transport: o.transport}
 	}
 
-	tm := newTokenManager(cfg.ClientID, cfg.ClientSecret, cfg.TokenStore, httpClient)
+	tm := newTokenManager(cfg.ClientID, cfg.ClientSecret, cfg.TokenStore, httpClient, o.userAgent)
 
 	return &Client{
-		baseURL:      o.baseURL,
-		config:       cfg,
-		httpClient:   httpClient,
-		tokenManager: tm,
+		baseURL:         o.baseURL,
+		config:          cfg,
+		hedgeDelay:      o.hedgeDelay,
+		httpClient:      httpClient,
+		maxResponseSize: o.maxResponseSize,
+		secondaryKey:    o.secondaryKey,
+		tokenManager:    tm,
+		userAgent:       o.userAgent,
 	}, nil
 }
 
+// Constituent returns the constituent with the given ID.
+func (c *Client) Constituent(ctx context.Context, constituentID string) (*Constituent, error) {
+	reqURL := fmt.Sprintf("%s/constituent/v1/constituents/%s", c.baseURL, url.PathEscape(constituentID))
+
+	var result Constituent
+	if err := c.doRequest(ctx, http.MethodGet, reqURL, nil, &result); err != nil {
+		return nil, fmt.Errorf("getting constituent: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ConstituentAppeals returns the appeals recorded for a constituent.
+// Handles pagination automatically to return all of them.
+func (c *Client) ConstituentAppeals(ctx context.Context, constituentID string) ([]ConstituentAppeal, error) {
+	var appeals []ConstituentAppeal
+	reqURL := fmt.Sprintf("%s/constituent/v1/constituents/%s/appeals", c.baseURL, url.PathEscape(constituentID))
+
+	for reqURL != "" {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("listing constituent appeals: %w", err)
+		}
+
+		var result constituentAppealListResponse
+		if err := c.doRequest(ctx, http.MethodGet, reqURL, nil, &result); err != nil {
+			return nil, fmt.Errorf("listing constituent appeals: %w", err)
+		}
+
+		appeals = append(appeals, result.Value...)
+		reqURL = result.NextLink
+	}
+
+	return appeals, nil
+}
+
 // CreateConstituent creates a new constituent and returns the new constituent ID.
 func (c *Client) CreateConstituent(ctx context.Context, constituent *Constituent) (string, error) {
 	reqURL := fmt.Sprintf("%s/constituent/v1/constituents", c.baseURL)
@@ -81,6 +167,54 @@ func (c *Client) CreateConstituent(ctx context.Context, constituent *Constituent
 	return result.ID, nil
 }
 
+// CreateConstituentAppeal records a constituent's response to an appeal and returns the new constituent appeal ID.
+func (c *Client) CreateConstituentAppeal(ctx context.Context, appeal *ConstituentAppeal) (string, error) {
+	reqURL := fmt.Sprintf("%s/constituent/v1/constituentappeals", c.baseURL)
+
+	var result createResponse
+	if err := c.doRequest(ctx, http.MethodPost, reqURL, appeal, &result); err != nil {
+		return "", fmt.Errorf("creating constituent appeal: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// CreateConstituentCode adds a constituent code to a constituent and returns the new constituent code ID.
+func (c *Client) CreateConstituentCode(ctx context.Context, code *ConstituentCode) (string, error) {
+	reqURL := fmt.Sprintf("%s/constituent/v1/constituentcodes", c.baseURL)
+
+	var result createResponse
+	if err := c.doRequest(ctx, http.MethodPost, reqURL, code, &result); err != nil {
+		return "", fmt.Errorf("creating constituent code: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// CreateConstituentNote adds a note to a constituent and returns the new note ID.
+func (c *Client) CreateConstituentNote(ctx context.Context, note *ConstituentNote) (string, error) {
+	reqURL := fmt.Sprintf("%s/constituent/v1/notes", c.baseURL)
+
+	var result createResponse
+	if err := c.doRequest(ctx, http.MethodPost, reqURL, note, &result); err != nil {
+		return "", fmt.Errorf("creating constituent note: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// CreateEmailAddress adds an email address to an existing constituent and returns the new email address ID.
+func (c *Client) CreateEmailAddress(ctx context.Context, email *EmailAddress) (string, error) {
+	reqURL := fmt.Sprintf("%s/constituent/v1/emailaddresses", c.baseURL)
+
+	var result createResponse
+	if err := c.doRequest(ctx, http.MethodPost, reqURL, email, &result); err != nil {
+		return "", fmt.Errorf("creating email address: %w", err)
+	}
+
+	return result.ID, nil
+}
+
 // CreateGift creates a new gift and returns the new gift ID.
 func (c *Client) CreateGift(ctx context.Context, gift *Gift) (string, error) {
 	reqURL := fmt.Sprintf("%s/gift/v1/gifts", c.baseURL)
@@ -93,6 +227,77 @@ func (c *Client) CreateGift(ctx context.Context, gift *Gift) (string, error) {
 	return result.ID, nil
 }
 
+// CreateGiftCustomField adds a custom field to a gift and returns the new custom field ID.
+func (c *Client) CreateGiftCustomField(ctx context.Context, field *GiftCustomField) (string, error) {
+	reqURL := fmt.Sprintf("%s/gift/v1/gifts/customfields", c.baseURL)
+
+	var result createResponse
+	if err := c.doRequest(ctx, http.MethodPost, reqURL, field, &result); err != nil {
+		return "", fmt.Errorf("creating gift custom field: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// CreateGiftNote adds a note to a gift and returns the new note ID.
+func (c *Client) CreateGiftNote(ctx context.Context, note *GiftNote) (string, error) {
+	reqURL := fmt.Sprintf("%s/gift/v1/gifts/notes", c.baseURL)
+
+	var result createResponse
+	if err := c.doRequest(ctx, http.MethodPost, reqURL, note, &result); err != nil {
+		return "", fmt.Errorf("creating gift note: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// CreateEventParticipant adds a constituent to an event as a participant and returns the new participant ID.
+func (c *Client) CreateEventParticipant(ctx context.Context, eventID string, participant *Participant) (string, error) {
+	reqURL := fmt.Sprintf("%s/event/v1/events/%s/participants", c.baseURL, url.PathEscape(eventID))
+
+	var result createResponse
+	if err := c.doRequest(ctx, http.MethodPost, reqURL, participant, &result); err != nil {
+		return "", fmt.Errorf("creating event participant: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// EventParticipants returns the participants of an event.
+// Handles pagination automatically to return all of them.
+func (c *Client) EventParticipants(ctx context.Context, eventID string) ([]Participant, error) {
+	var participants []Participant
+	reqURL := fmt.Sprintf("%s/event/v1/events/%s/participants", c.baseURL, url.PathEscape(eventID))
+
+	for reqURL != "" {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("listing event participants: %w", err)
+		}
+
+		var result participantListResponse
+		if err := c.doRequest(ctx, http.MethodGet, reqURL, nil, &result); err != nil {
+			return nil, fmt.Errorf("listing event participants: %w", err)
+		}
+
+		participants = append(participants, result.Value...)
+		reqURL = result.NextLink
+	}
+
+	return participants, nil
+}
+
+// Gift returns the gift with the given ID.
+func (c *Client) Gift(ctx context.Context, giftID string) (*Gift, error) {
+	reqURL := fmt.Sprintf("%s/gift/v1/gifts/%s", c.baseURL, url.PathEscape(giftID))
+
+	var result Gift
+	if err := c.doRequest(ctx, http.MethodGet, reqURL, nil, &result); err != nil {
+		return nil, fmt.Errorf("getting gift: %w", err)
+	}
+
+	return &result, nil
+}
+
 // ListGiftsByConstituent returns all gifts for a constituent, optionally filtered by gift type.
 // Handles pagination automatically to return all matching gifts.
 func (c *Client) ListGiftsByConstituent(
@@ -110,6 +315,11 @@ func (c *Client) ListGiftsByConstituent(
 	reqURL := fmt.Sprintf("%s/gift/v1/gifts?%s", c.baseURL, params.Encode())
 
 	for reqURL != "" {
+		// Stop between pages once cancelled, rather than waiting for the next request to fail.
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("listing gifts: %w", err)
+		}
+
 		var result giftListResponse
 		if err := c.doRequest(ctx, http.MethodGet, reqURL, nil, &result); err != nil {
 			return nil, fmt.Errorf("listing gifts: %w", err)
@@ -123,18 +333,39 @@ func (c *Client) ListGiftsByConstituent(
 }
 
 // SearchConstituents searches for constituents matching the given email address.
-func (c *Client) SearchConstituents(ctx context.Context, email string) ([]Constituent, error) {
+// Handles pagination automatically to return all matching constituents.
+func (c *Client) SearchConstituents(ctx context.Context, email string, opts SearchOptions) ([]Constituent, error) {
 	params := url.Values{}
 	params.Set("search_text", email)
+	params.Set("limit", strconv.Itoa(constituentSearchPageSize))
+	if opts.IncludeInactive {
+		params.Set("include_inactive", "true")
+	}
+	if opts.StrictEmail {
+		params.Set("search_field", "email_address")
+		params.Set("strict_search", "true")
+	}
 
-	reqURL := fmt.Sprintf("%s/constituent/v1/constituents/search?%s", c.baseURL, params.Encode())
+	var allConstituents []Constituent
+	for {
+		// Stop between pages once cancelled, rather than waiting for the next request to fail.
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("searching constituents: %w", err)
+		}
 
-	var result constituentSearchResponse
-	if err := c.doRequest(ctx, http.MethodGet, reqURL, nil, &result); err != nil {
-		return nil, fmt.Errorf("searching constituents: %w", err)
-	}
+		params.Set("offset", strconv.Itoa(len(allConstituents)))
+		reqURL := fmt.Sprintf("%s/constituent/v1/constituents/search?%s", c.baseURL, params.Encode())
 
-	return result.Value, nil
+		var result constituentSearchResponse
+		if err := c.doRequest(ctx, http.MethodGet, reqURL, nil, &result); err != nil {
+			return nil, fmt.Errorf("searching constituents: %w", err)
+		}
+
+		allConstituents = append(allConstituents, result.Value...)
+		if len(result.Value) == 0 || len(allConstituents) >= result.Count {
+			return allConstituents, nil
+		}
+	}
 }
 
 // UpdateGift updates an existing gift by ID.
@@ -155,37 +386,72 @@ func (c *Client) doRequest(ctx context.Context, method string, reqURL string, bo
 		return fmt.Errorf("getting access token: %w", err)
 	}
 
-	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("marshaling request body: %w", err)
 		}
-		reqBody = bytes.NewReader(jsonBody)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
+	// The request is sent again with the secondary subscription key when the SKY API rejects the primary one.
+	sendWithKey := func(subscriptionKey string) (*http.Response, error) {
+		var reqBody io.Reader
+		if jsonBody != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Bb-Api-Subscription-Key", subscriptionKey)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		httpclient.AcceptGzip(req)
 
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("Bb-Api-Subscription-Key", c.config.SubscriptionKey)
-	req.Header.Set("Content-Type", "application/json")
+		resp, err := c.send(req)
+		if err != nil {
+			return nil, fmt.Errorf("executing request: %w", err)
+		}
+		return resp, nil
+	}
 
-	resp, err := c.httpClient.Do(req)
+	key, fallbackKey := c.subscriptionKeys()
+	resp, err := sendWithKey(key)
 	if err != nil {
-		return fmt.Errorf("executing request: %w", err)
+		return err
+	}
+	if fallbackKey != "" && keyRejected(resp.StatusCode) {
+		_ = resp.Body.Close()
+		resp, err = sendWithKey(fallbackKey)
+		if err != nil {
+			return err
+		}
+		if !keyRejected(resp.StatusCode) {
+			c.failOver(key)
+		}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	c.recordQuota(resp.Header, time.Now())
+
+	if err := httpclient.Decompress(resp); err != nil {
+		return fmt.Errorf("decompressing response: %w", err)
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+		respBody := httpclient.ReadErrorBody(resp.Body, c.maxResponseSize)
+		return &StatusError{Body: respBody, StatusCode: resp.StatusCode}
 	}
 
 	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		respBody, err := httpclient.ReadBody(resp.Body, c.maxResponseSize)
+		if err != nil {
+			return fmt.Errorf("reading response: %w", err)
+		}
+		if err := json.Unmarshal(respBody, result); err != nil {
 			return fmt.Errorf("decoding response: %w", err)
 		}
 	}
@@ -193,6 +459,12 @@ func (c *Client) doRequest(ctx context.Context, method string, reqURL string, bo
 	return nil
 }
 
+// IsNotFound reports whether err is the SKY API saying the requested record does not exist.
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
 // validate checks that all required Config fields are set.
 func (c *Config) validate() error {
 	var errs []error
@@ -224,7 +496,8 @@ func (o GiftOrigin) String() string {
 // ParseGiftOrigin deserializes a gift's origin field back into a GiftOrigin struct.
 // This is used to extract the original FundraiseUp donation ID from recurring gifts,
 // enabling deduplication by matching against the donation being processed.
-// Returns an empty GiftOrigin if the input is empty.
+// Returns an empty GiftOrigin if the input is empty. Fields missing from origins written by earlier versions are
+// left empty, and fields added by later versions are ignored, so any version's origins can be read.
 func ParseGiftOrigin(origin string) (GiftOrigin, error) {
 	if origin == "" {
 		return GiftOrigin{}, nil
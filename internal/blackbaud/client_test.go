@@ -2,9 +2,20 @@ package blackbaud
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 // mockTokenStore implements TokenStore for testing.
@@ -173,6 +184,620 @@ func TestNewClientWithOptions(t *testing.T) {
 	}
 }
 
+func TestNewClientWithTransport(t *testing.T) {
+	t.Parallel()
+
+	transport := &http.Transport{}
+	client, err := NewClient(Config{
+		ClientID:        "client-id",
+		ClientSecret:    "client-secret",
+		SubscriptionKey: "sub-key",
+		TokenStore:      &mockTokenStore{refreshToken: "test-token"},
+	}, WithTransport(transport))
+
+	require.NoError(t, err)
+	require.Same(t, transport, client.httpClient.Transport)
+	require.Same(t, client.httpClient, client.tokenManager.httpClient)
+}
+
+func TestListGiftsByConstituentCancelled(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	client, err := NewClient(Config{
+		ClientID:        "client-id",
+		ClientSecret:    "client-secret",
+		SubscriptionKey: "sub-key",
+		TokenStore:      &mockTokenStore{refreshToken: "test-token"},
+	}, WithHTTPClient(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		requests++
+		return nil, errors.New("unexpected request")
+	})}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	gifts, err := client.ListGiftsByConstituent(ctx, "const-123", nil)
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Nil(t, gifts)
+	require.Zero(t, requests)
+}
+
+func TestConstituentAppeals(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.EscapedPath()+"?"+req.URL.RawQuery)
+		nextLink := "https://api.sky.blackbaud.com/constituent/v1/constituents/const%2F1/appeals?offset=1"
+		body := `{"count":2,"next_link":"` + nextLink + `",` +
+			`"value":[{"id":"ca-1","appeal_id":"APPEAL-1","constituent_id":"const/1"}]}`
+		if req.URL.Query().Get("offset") == "1" {
+			body = `{"count":2,"value":[{"id":"ca-2","appeal_id":"APPEAL-2","constituent_id":"const/1"}]}`
+		}
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	appeals, err := client.ConstituentAppeals(context.Background(), "const/1")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/constituent/v1/constituents/const%2F1/appeals?",
+		"/constituent/v1/constituents/const%2F1/appeals?offset=1",
+	}, paths)
+	require.Equal(t, []ConstituentAppeal{
+		{AppealID: "APPEAL-1", ConstituentID: "const/1", ID: "ca-1"},
+		{AppealID: "APPEAL-2", ConstituentID: "const/1", ID: "ca-2"},
+	}, appeals)
+}
+
+func TestCreateConstituentAppeal(t *testing.T) {
+	t.Parallel()
+
+	var (
+		body   map[string]any
+		method string
+		path   string
+	)
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		path = req.URL.Path
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"id":"ca-1"}`)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	id, err := client.CreateConstituentAppeal(context.Background(), &ConstituentAppeal{
+		AppealID:      "APPEAL-1",
+		ConstituentID: "const-1",
+		Date:          "2024-01-15",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "ca-1", id)
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/constituent/v1/constituentappeals", path)
+	require.Equal(t, map[string]any{"appeal_id": "APPEAL-1", "constituent_id": "const-1", "date": "2024-01-15"}, body)
+}
+
+func TestCreateConstituentNote(t *testing.T) {
+	t.Parallel()
+
+	var (
+		body   map[string]any
+		method string
+		path   string
+	)
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		path = req.URL.Path
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"id":"note-1"}`)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	id, err := client.CreateConstituentNote(context.Background(), &ConstituentNote{
+		ConstituentID: "const-1",
+		Date:          &FuzzyDate{Day: 15, Month: 1, Year: 2024},
+		Summary:       "Recurring gift increased",
+		Text:          "Plan rec_1 changed",
+		Type:          "Stewardship",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "note-1", id)
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/constituent/v1/notes", path)
+	require.Equal(t, map[string]any{
+		"constituent_id": "const-1",
+		"date":           map[string]any{"d": float64(15), "m": float64(1), "y": float64(2024)},
+		"summary":        "Recurring gift increased",
+		"text":           "Plan rec_1 changed",
+		"type":           "Stewardship",
+	}, body)
+}
+
+func TestCreateEmailAddress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		body   map[string]any
+		method string
+		path   string
+	)
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		path = req.URL.Path
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"id":"email-1"}`)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	id, err := client.CreateEmailAddress(context.Background(), &EmailAddress{
+		Address:       "jane@example.com",
+		ConstituentID: "const-1",
+		Primary:       true,
+		Type:          "Email",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "email-1", id)
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/constituent/v1/emailaddresses", path)
+	require.Equal(t, map[string]any{
+		"address":        "jane@example.com",
+		"constituent_id": "const-1",
+		"primary":        true,
+		"type":           "Email",
+	}, body)
+}
+
+func TestCreateGiftCustomField(t *testing.T) {
+	t.Parallel()
+
+	var (
+		body   map[string]any
+		method string
+		path   string
+	)
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		path = req.URL.Path
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"id":"gift-custom-field-1"}`)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	id, err := client.CreateGiftCustomField(context.Background(), &GiftCustomField{
+		Category: "Created by",
+		ParentID: "gift-1",
+		Value:    "GiftBridge run 2025-03-01T12:00:00Z",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "gift-custom-field-1", id)
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/gift/v1/gifts/customfields", path)
+	require.Equal(t, map[string]any{
+		"category":  "Created by",
+		"parent_id": "gift-1",
+		"value":     "GiftBridge run 2025-03-01T12:00:00Z",
+	}, body)
+}
+
+func TestCreateGiftNote(t *testing.T) {
+	t.Parallel()
+
+	var (
+		body   map[string]any
+		method string
+		path   string
+	)
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		path = req.URL.Path
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"id":"gift-note-1"}`)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	id, err := client.CreateGiftNote(context.Background(), &GiftNote{
+		Date:    &FuzzyDate{Day: 15, Month: 1, Year: 2024},
+		GiftID:  "gift-1",
+		Summary: "Donor comment",
+		Text:    "In memory of my grandmother",
+		Type:    "Comment",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "gift-note-1", id)
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/gift/v1/gifts/notes", path)
+	require.Equal(t, map[string]any{
+		"date":    map[string]any{"d": float64(15), "m": float64(1), "y": float64(2024)},
+		"gift_id": "gift-1",
+		"summary": "Donor comment",
+		"text":    "In memory of my grandmother",
+		"type":    "Comment",
+	}, body)
+}
+
+func TestEventParticipants(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.EscapedPath()+"?"+req.URL.RawQuery)
+		nextLink := "https://api.sky.blackbaud.com/event/v1/events/42/participants?offset=1"
+		body := `{"count":2,"next_link":"` + nextLink + `","value":[{"id":"p-1","constituent_id":"const-1"}]}`
+		if req.URL.Query().Get("offset") == "1" {
+			body = `{"count":2,"value":[{"id":"p-2","constituent_id":"const-2","rsvp_status":"Attending"}]}`
+		}
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	participants, err := client.EventParticipants(context.Background(), "42")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/event/v1/events/42/participants?",
+		"/event/v1/events/42/participants?offset=1",
+	}, paths)
+	require.Equal(t, []Participant{
+		{ConstituentID: "const-1", ID: "p-1"},
+		{ConstituentID: "const-2", ID: "p-2", RSVPStatus: RSVPStatusAttending},
+	}, participants)
+}
+
+func TestCreateEventParticipant(t *testing.T) {
+	t.Parallel()
+
+	var (
+		body   map[string]any
+		method string
+		path   string
+	)
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		path = req.URL.Path
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"id":"p-1"}`)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	id, err := client.CreateEventParticipant(context.Background(), "42", &Participant{
+		ConstituentID: "const-1",
+		RSVPStatus:    RSVPStatusAttending,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "p-1", id)
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/event/v1/events/42/participants", path)
+	require.Equal(t, map[string]any{"constituent_id": "const-1", "rsvp_status": "Attending"}, body)
+}
+
+func TestGift(t *testing.T) {
+	t.Parallel()
+
+	var path, userAgent string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		path = req.URL.EscapedPath()
+		userAgent = req.Header.Get("User-Agent")
+		body := `{"id":"gift/1","amount":{"value":25.5},"date":"2024-01-15T00:00:00","type":"Donation"}`
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	gift, err := client.Gift(context.Background(), "gift/1")
+
+	require.NoError(t, err)
+	require.Equal(t, "/gift/v1/gifts/gift%2F1", path)
+	require.Equal(t, version.UserAgent(""), userAgent)
+	require.Equal(t, "gift/1", gift.ID)
+	require.Equal(t, 25.5, gift.Amount.Value)
+	require.Equal(t, "2024-01-15T00:00:00", gift.Date)
+}
+
+func TestGiftNotFound(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		status       int
+		wantNotFound bool
+	}{
+		"deleted gift":  {status: http.StatusNotFound, wantNotFound: true},
+		"server error":  {status: http.StatusInternalServerError},
+		"access denied": {status: http.StatusForbidden},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestClient(t, func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Body:       io.NopCloser(strings.NewReader("gone")),
+					Header:     http.Header{},
+					StatusCode: tc.status,
+				}, nil
+			})
+
+			_, err := client.Gift(context.Background(), "gift-1")
+
+			require.ErrorContains(t, err, fmt.Sprintf("unexpected status %d: gone", tc.status))
+			require.Equal(t, tc.wantNotFound, IsNotFound(err))
+		})
+	}
+}
+
+func TestGiftResponseTooLarge(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, func(_ *http.Request) (*http.Response, error) {
+		body := `{"id":"gift-1","reference":"` + strings.Repeat("x", 2048) + `"}`
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+		}, nil
+	}, WithMaxResponseSize(1024))
+
+	_, err := client.Gift(context.Background(), "gift-1")
+
+	require.ErrorIs(t, err, httpclient.ErrBodyTooLarge)
+	require.ErrorContains(t, err, "reading response: response body too large: more than 1024 bytes")
+}
+
+func TestSubscriptionKeyFailover(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg        string
+		opts          []Option
+		rejected      map[string]int
+		wantKeys      []string
+		wantSecondary bool
+	}{
+		"primary accepted": {
+			opts:     []Option{WithSecondarySubscriptionKey("second-key")},
+			wantKeys: []string{"sub-key", "sub-key"},
+		},
+		"revoked primary fails over": {
+			opts:          []Option{WithSecondarySubscriptionKey("second-key")},
+			rejected:      map[string]int{"sub-key": http.StatusUnauthorized},
+			wantKeys:      []string{"sub-key", "second-key", "second-key"},
+			wantSecondary: true,
+		},
+		"primary out of quota fails over": {
+			opts:          []Option{WithSecondarySubscriptionKey("second-key")},
+			rejected:      map[string]int{"sub-key": http.StatusForbidden},
+			wantKeys:      []string{"sub-key", "second-key", "second-key"},
+			wantSecondary: true,
+		},
+		"no secondary key": {
+			rejected: map[string]int{"sub-key": http.StatusUnauthorized},
+			errMsg:   "unexpected status 401",
+			wantKeys: []string{"sub-key", "sub-key"},
+		},
+		"both keys rejected": {
+			opts:     []Option{WithSecondarySubscriptionKey("second-key")},
+			rejected: map[string]int{"sub-key": http.StatusUnauthorized, "second-key": http.StatusForbidden},
+			errMsg:   "unexpected status 403",
+			wantKeys: []string{"sub-key", "second-key", "sub-key", "second-key"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var keys []string
+			client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+				key := req.Header.Get("Bb-Api-Subscription-Key")
+				keys = append(keys, key)
+				status, body := http.StatusOK, `{"id":"gift-1"}`
+				if rejected, ok := tc.rejected[key]; ok {
+					status, body = rejected, "access denied"
+				}
+				return &http.Response{
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Header:     http.Header{},
+					StatusCode: status,
+				}, nil
+			}, tc.opts...)
+
+			// Later requests go straight to the key that worked.
+			for range 2 {
+				_, err := client.Gift(context.Background(), "gift-1")
+				if tc.errMsg != "" {
+					require.ErrorContains(t, err, tc.errMsg)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+
+			require.Equal(t, tc.wantKeys, keys)
+			require.Equal(t, tc.wantSecondary, client.UsingSecondarySubscriptionKey())
+		})
+	}
+}
+
+func TestSetSubscriptionKeys(t *testing.T) {
+	t.Parallel()
+
+	var keys []string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		key := req.Header.Get("Bb-Api-Subscription-Key")
+		keys = append(keys, key)
+		status := http.StatusOK
+		if key == "sub-key" {
+			status = http.StatusUnauthorized
+		}
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(`{"id":"gift-1"}`)),
+			Header:     http.Header{},
+			StatusCode: status,
+		}, nil
+	}, WithSecondarySubscriptionKey("second-key"))
+
+	_, err := client.Gift(context.Background(), "gift-1")
+	require.NoError(t, err)
+	require.True(t, client.UsingSecondarySubscriptionKey())
+
+	require.ErrorContains(t, client.SetSubscriptionKeys(" ", "second-key"), "subscription key is required")
+	require.NoError(t, client.SetSubscriptionKeys("new-key", "second-key"))
+	require.False(t, client.UsingSecondarySubscriptionKey())
+
+	_, err = client.Gift(context.Background(), "gift-1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"sub-key", "second-key", "new-key"}, keys)
+}
+
+func TestSearchConstituents(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts       SearchOptions
+		total      int
+		wantCount  int
+		wantPages  int
+		wantParams map[string]string
+	}{
+		"single page with default filters": {
+			total:     2,
+			wantCount: 2,
+			wantPages: 1,
+			wantParams: map[string]string{
+				"include_inactive": "",
+				"limit":            "500",
+				"search_field":     "",
+				"search_text":      "ada@example.com",
+				"strict_search":    "",
+			},
+		},
+		"strict email search including inactive": {
+			opts:      SearchOptions{IncludeInactive: true, StrictEmail: true},
+			total:     1,
+			wantCount: 1,
+			wantPages: 1,
+			wantParams: map[string]string{
+				"include_inactive": "true",
+				"search_field":     "email_address",
+				"strict_search":    "true",
+			},
+		},
+		"results over one page": {
+			total:     constituentSearchPageSize + 3,
+			wantCount: constituentSearchPageSize + 3,
+			wantPages: 2,
+		},
+		"no results": {
+			wantPages: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests []*http.Request
+			client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+				requests = append(requests, req)
+
+				offset, err := strconv.Atoi(req.URL.Query().Get("offset"))
+				if err != nil {
+					return nil, err
+				}
+				n := min(constituentSearchPageSize, tc.total-offset)
+				values := make([]string, 0, n)
+				for i := range n {
+					values = append(values, fmt.Sprintf(`{"id":"const-%d"}`, offset+i))
+				}
+				body := fmt.Sprintf(`{"count":%d,"value":[%s]}`, tc.total, strings.Join(values, ","))
+
+				return &http.Response{
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Header:     http.Header{},
+					StatusCode: http.StatusOK,
+				}, nil
+			})
+
+			constituents, err := client.SearchConstituents(context.Background(), "ada@example.com", tc.opts)
+
+			require.NoError(t, err)
+			require.Len(t, constituents, tc.wantCount)
+			require.Len(t, requests, tc.wantPages)
+			for i, req := range requests {
+				require.Equal(t, strconv.Itoa(i*constituentSearchPageSize), req.URL.Query().Get("offset"))
+			}
+			for param, want := range tc.wantParams {
+				require.Equal(t, want, requests[0].URL.Query().Get(param), param)
+			}
+			if tc.wantCount > 0 {
+				require.Equal(t, fmt.Sprintf("const-%d", tc.wantCount-1), constituents[tc.wantCount-1].ID)
+			}
+		})
+	}
+}
+
+// newTestClient creates a client with a valid access token that sends requests to fn.
+func newTestClient(t *testing.T, fn roundTripFunc, opts ...Option) *Client {
+	t.Helper()
+
+	client, err := NewClient(Config{
+		ClientID:        "client-id",
+		ClientSecret:    "client-secret",
+		SubscriptionKey: "sub-key",
+		TokenStore:      &mockTokenStore{refreshToken: "test-token"},
+	}, append(opts, WithHTTPClient(&http.Client{Transport: fn}))...)
+	require.NoError(t, err)
+
+	client.tokenManager.accessToken = "access-token"
+	client.tokenManager.expiresAt = time.Now().Add(time.Hour)
+
+	return client
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f.
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
 func TestConfigValidate(t *testing.T) {
 	t.Parallel()
 
@@ -248,6 +873,19 @@ func TestGiftOriginString(t *testing.T) {
 			},
 			want: `{"donation_id":"don_123","name":"FundraiseUp"}`,
 		},
+		"origin with provenance": {
+			origin: GiftOrigin{
+				CampaignID:  "FUNSPRING",
+				DonationID:  "don_124",
+				Installment: 2,
+				Name:        "FundraiseUp",
+				RecurringID: "rec_456",
+				Source:      "giftbridge",
+				Version:     "v1.2.3",
+			},
+			want: `{"campaign_id":"FUNSPRING","donation_id":"don_124","installment":2,"name":"FundraiseUp",` +
+				`"recurring_id":"rec_456","source":"giftbridge","version":"v1.2.3"}`,
+		},
 		"empty origin": {
 			origin: GiftOrigin{},
 			want:   `{"donation_id":"","name":""}`,
@@ -287,6 +925,27 @@ func TestParseGiftOrigin(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"origin with provenance": {
+			input: `{"donation_id":"don_124","installment":2,"name":"FundraiseUp","recurring_id":"rec_456",` +
+				`"source":"giftbridge","version":"v1.2.3"}`,
+			want: GiftOrigin{
+				DonationID:  "don_124",
+				Installment: 2,
+				Name:        "FundraiseUp",
+				RecurringID: "rec_456",
+				Source:      "giftbridge",
+				Version:     "v1.2.3",
+			},
+			wantErr: false,
+		},
+		"fields from later versions are ignored": {
+			input: `{"donation_id":"don_123","name":"FundraiseUp","payout_id":"po_1"}`,
+			want: GiftOrigin{
+				DonationID: "don_123",
+				Name:       "FundraiseUp",
+			},
+			wantErr: false,
+		},
 		"empty string returns empty origin": {
 			input:   "",
 			want:    GiftOrigin{},
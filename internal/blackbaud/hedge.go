@@ -0,0 +1,141 @@
+package blackbaud
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// hedgeAttempt is the outcome of one copy of a hedged request.
+type hedgeAttempt struct {
+	// index identifies the copy that produced the outcome.
+	index int
+
+	// resp is the response, if the request succeeded.
+	resp *http.Response
+
+	// err is the error, if the request failed.
+	err error
+}
+
+// cancelOnClose cancels a request's context once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+
+	// cancel releases the request's context.
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request's context.
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// send executes an HTTP request, hedging read-only requests when a hedge delay is configured.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.hedgeDelay <= 0 || req.Method != http.MethodGet {
+		return c.httpClient.Do(req)
+	}
+	return c.hedge(req, c.hedgeDelay)
+}
+
+// hedge sends req and, if no settled response has arrived after delay, an identical copy of it.
+// The first settled response, a 2xx or a client error that will not succeed on retry, is returned and
+// the other copy is cancelled. A failure, or a response that may succeed on retry (408, 429 or 5xx), only
+// ends the race once every copy sent has finished: the first such response is then returned, or the first
+// error if no copy got a response. A copy is never sent after the first one finishes without settling.
+// Each copy is a separate API call, so hedging spends extra quota; hedged copies are counted in Quota.
+func (c *Client) hedge(req *http.Request, delay time.Duration) (*http.Response, error) {
+	results := make(chan hedgeAttempt, 2)
+	var cancels []context.CancelFunc
+
+	sendCopy := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := c.httpClient.Do(req.Clone(ctx))
+			results <- hedgeAttempt{err: err, index: index, resp: resp}
+		}()
+	}
+
+	sendCopy()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	hedgeC := timer.C
+
+	pending := 1
+	var firstErr error
+	var fallback *hedgeAttempt
+	for {
+		select {
+		case <-hedgeC:
+			hedgeC = nil
+			c.recordHedge()
+			sendCopy()
+			pending++
+		case attempt := <-results:
+			pending--
+			if attempt.err == nil && settled(attempt.resp.StatusCode) {
+				for i, cancel := range cancels {
+					if i != attempt.index {
+						cancel()
+					}
+				}
+				if fallback != nil {
+					_ = fallback.resp.Body.Close()
+					cancels[fallback.index]()
+				}
+				go discardAttempts(results, pending)
+				return withCancel(attempt, cancels[attempt.index]), nil
+			}
+
+			switch {
+			case attempt.err != nil:
+				cancels[attempt.index]()
+				if firstErr == nil {
+					firstErr = attempt.err
+				}
+			case fallback == nil:
+				fallback = &attempt
+			default:
+				_ = attempt.resp.Body.Close()
+				cancels[attempt.index]()
+			}
+			if pending == 0 {
+				if fallback != nil {
+					return withCancel(*fallback, cancels[fallback.index]), nil
+				}
+				return nil, firstErr
+			}
+		}
+	}
+}
+
+// settled reports whether a response status ends a hedged race: a 2xx, or a client error that would
+// fail the same way on every copy. Timeouts, rate limiting and server errors may succeed on another copy.
+func settled(statusCode int) bool {
+	switch {
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return false
+	default:
+		return statusCode < http.StatusInternalServerError
+	}
+}
+
+// withCancel returns the attempt's response, cancelling its request's context once the body is closed.
+func withCancel(attempt hedgeAttempt, cancel context.CancelFunc) *http.Response {
+	attempt.resp.Body = &cancelOnClose{ReadCloser: attempt.resp.Body, cancel: cancel}
+	return attempt.resp
+}
+
+// discardAttempts waits for the remaining copies of a hedged request and closes their responses.
+func discardAttempts(results <-chan hedgeAttempt, pending int) {
+	for range pending {
+		if attempt := <-results; attempt.resp != nil {
+			_ = attempt.resp.Body.Close()
+		}
+	}
+}
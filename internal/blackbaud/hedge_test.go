@@ -0,0 +1,226 @@
+package blackbaud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSend(t *testing.T) {
+	t.Parallel()
+
+	const delay = 20 * time.Millisecond
+
+	// Each respond handles the nth request (from 0). An empty body blocks until the request is cancelled.
+	// Responses are 200 OK unless status gives another code for the nth request.
+	tests := map[string]struct {
+		hedgeDelay time.Duration
+		method     string
+		respond    func(n int32) (string, error)
+		status     func(n int32) int
+		wantBody   string
+		wantCalls  int32
+		wantErr    string
+		wantHedged int
+		wantStatus int
+	}{
+		"hedging disabled": {
+			method:    http.MethodGet,
+			respond:   func(int32) (string, error) { return "first", nil },
+			wantBody:  "first",
+			wantCalls: 1,
+		},
+		"fast response is not hedged": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond:    func(int32) (string, error) { return "first", nil },
+			wantBody:   "first",
+			wantCalls:  1,
+		},
+		"slow response is hedged": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond: func(n int32) (string, error) {
+				if n == 0 {
+					return "", nil
+				}
+				return "second", nil
+			},
+			wantBody:   "second",
+			wantCalls:  2,
+			wantHedged: 1,
+		},
+		"retryable status does not win": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond: func(n int32) (string, error) {
+				if n == 0 {
+					time.Sleep(2 * delay)
+					return "busy", nil
+				}
+				time.Sleep(3 * delay)
+				return "second", nil
+			},
+			status: func(n int32) int {
+				if n == 0 {
+					return http.StatusServiceUnavailable
+				}
+				return http.StatusOK
+			},
+			wantBody:   "second",
+			wantCalls:  2,
+			wantHedged: 1,
+		},
+		"retryable status is returned when every copy fails": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond: func(n int32) (string, error) {
+				time.Sleep(time.Duration(2+n) * delay)
+				return fmt.Sprintf("limited %d", n), nil
+			},
+			status:     func(int32) int { return http.StatusTooManyRequests },
+			wantBody:   "limited 0",
+			wantCalls:  2,
+			wantHedged: 1,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		"retryable status before the delay is not hedged": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond:    func(int32) (string, error) { return "busy", nil },
+			status:     func(int32) int { return http.StatusBadGateway },
+			wantBody:   "busy",
+			wantCalls:  1,
+			wantStatus: http.StatusBadGateway,
+		},
+		"client error wins": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond: func(n int32) (string, error) {
+				if n == 0 {
+					return "", nil
+				}
+				return "missing", nil
+			},
+			status:     func(int32) int { return http.StatusNotFound },
+			wantBody:   "missing",
+			wantCalls:  2,
+			wantHedged: 1,
+			wantStatus: http.StatusNotFound,
+		},
+		"writes are not hedged": {
+			hedgeDelay: delay,
+			method:     http.MethodPost,
+			respond: func(int32) (string, error) {
+				time.Sleep(3 * delay)
+				return "first", nil
+			},
+			wantBody:  "first",
+			wantCalls: 1,
+		},
+		"failure before the delay is not hedged": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond:    func(int32) (string, error) { return "", errors.New("connection refused") },
+			wantCalls:  1,
+			wantErr:    "connection refused",
+		},
+		"hedge succeeds after the first copy fails": {
+			hedgeDelay: delay,
+			method:     http.MethodGet,
+			respond: func(n int32) (string, error) {
+				if n == 0 {
+					time.Sleep(3 * delay)
+					return "", errors.New("connection reset")
+				}
+				time.Sleep(5 * delay)
+				return "second", nil
+			},
+			wantBody:   "second",
+			wantCalls:  2,
+			wantHedged: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			client := &Client{
+				hedgeDelay: tc.hedgeDelay,
+				httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					n := calls.Add(1) - 1
+					body, err := tc.respond(n)
+					if err != nil {
+						return nil, err
+					}
+					if body == "" {
+						<-req.Context().Done()
+						return nil, req.Context().Err()
+					}
+					status := http.StatusOK
+					if tc.status != nil {
+						status = tc.status(n)
+					}
+					return &http.Response{
+						Body:       io.NopCloser(strings.NewReader(body)),
+						Header:     http.Header{},
+						StatusCode: status,
+					}, nil
+				})},
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), tc.method, "https://api.example.com", nil)
+			require.NoError(t, err)
+
+			resp, err := client.send(req)
+
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				require.Equal(t, tc.wantCalls, calls.Load())
+				return
+			}
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tc.wantBody, string(body))
+			require.Equal(t, tc.wantCalls, calls.Load())
+			if tc.wantStatus != 0 {
+				require.Equal(t, tc.wantStatus, resp.StatusCode)
+			}
+			require.Equal(t, tc.wantHedged, client.quota.HedgedCalls)
+		})
+	}
+}
+
+func TestClientSendCancelled(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{
+		hedgeDelay: time.Millisecond,
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com", nil)
+	require.NoError(t, err)
+
+	resp, err := client.send(req)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Nil(t, resp)
+}
@@ -0,0 +1,59 @@
+package blackbaud
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// SetSubscriptionKeys replaces the subscription keys sent with later requests, so a long-running client picks up
+// rotated keys without being recreated. The client goes back to using primary, failing over to secondary when it
+// is set and the SKY API rejects primary.
+func (c *Client) SetSubscriptionKeys(primary string, secondary string) error {
+	primary = strings.TrimSpace(primary)
+	if primary == "" {
+		return errors.New("subscription key is required")
+	}
+
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	c.config.SubscriptionKey = primary
+	c.secondaryKey = strings.TrimSpace(secondary)
+	c.usingSecondaryKey = false
+	return nil
+}
+
+// UsingSecondarySubscriptionKey reports whether the client has failed over to the secondary subscription key,
+// because the SKY API rejected the primary one. The primary key needs replacing once this is true.
+func (c *Client) UsingSecondarySubscriptionKey() bool {
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	return c.usingSecondaryKey
+}
+
+// subscriptionKeys returns the subscription key to send with a request, and the key to try instead if the SKY API
+// rejects it. The second key is empty when no secondary key is set or the client has already failed over.
+func (c *Client) subscriptionKeys() (string, string) {
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	if c.usingSecondaryKey {
+		return c.secondaryKey, ""
+	}
+	return c.config.SubscriptionKey, c.secondaryKey
+}
+
+// failOver switches later requests to the secondary subscription key after the SKY API rejected primary and
+// accepted the secondary key. Nothing changes if the keys were replaced in the meantime.
+func (c *Client) failOver(primary string) {
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	if c.config.SubscriptionKey == primary && c.secondaryKey != "" {
+		c.usingSecondaryKey = true
+	}
+}
+
+// keyRejected reports whether a response status means the subscription key was refused: 401 for a key that is
+// invalid or revoked, and 403 for a key whose call quota is used up.
+func keyRejected(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
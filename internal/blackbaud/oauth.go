@@ -4,18 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
 )
 
 const (
 	// defaultTokenDuration is used when the API doesn't return an expiry time.
 	defaultTokenDuration = 60 * time.Minute
 
+	// maxTokenResponseSize is the largest token response read, far above the few hundred bytes the endpoint sends.
+	maxTokenResponseSize = 64 << 10
+
 	// tokenExpiryBuffer is the time before expiry to trigger a refresh.
 	tokenExpiryBuffer = 5 * time.Minute
 
@@ -54,6 +58,9 @@ type tokenManager struct {
 
 	// tokenStore provides access to refresh tokens.
 	tokenStore TokenStore
+
+	// userAgent is the User-Agent header sent with token requests.
+	userAgent string
 }
 
 // AccessToken returns a valid access token, refreshing if necessary.
@@ -107,6 +114,9 @@ func (tm *tokenManager) refreshAccessToken(ctx context.Context) (string, error)
 		return "", fmt.Errorf("creating token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if tm.userAgent != "" {
+		req.Header.Set("User-Agent", tm.userAgent)
+	}
 
 	resp, err := tm.httpClient.Do(req)
 	if err != nil {
@@ -115,12 +125,17 @@ func (tm *tokenManager) refreshAccessToken(ctx context.Context) (string, error)
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("token refresh failed with status %d: %s", resp.StatusCode, string(body))
+		body := httpclient.ReadErrorBody(resp.Body, maxTokenResponseSize)
+		return "", fmt.Errorf("token refresh failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	body, err := httpclient.ReadBody(resp.Body, maxTokenResponseSize)
+	if err != nil {
+		return "", fmt.Errorf("reading token response: %w", err)
 	}
 
 	var tokenResp tokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
 		return "", fmt.Errorf("decoding token response: %w", err)
 	}
 
@@ -147,11 +162,13 @@ func newTokenManager(
 	clientSecret string,
 	tokenStore TokenStore,
 	httpClient *http.Client,
+	userAgent string,
 ) *tokenManager {
 	return &tokenManager{
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		httpClient:   httpClient,
 		tokenStore:   tokenStore,
+		userAgent:    userAgent,
 	}
 }
@@ -3,13 +3,17 @@ package blackbaud
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
 )
 
 // errMockGetRefreshToken is a sentinel error for testing.
@@ -49,13 +53,14 @@ func TestNewTokenManager(t *testing.T) {
 	store := &mockTokenStore{refreshToken: "refresh-token"}
 	httpClient := &http.Client{Timeout: 10 * time.Second}
 
-	tm := newTokenManager("client-id", "client-secret", store, httpClient)
+	tm := newTokenManager("client-id", "client-secret", store, httpClient, "giftbridge/v1.4.0")
 
 	require.NotNil(t, tm)
 	require.Equal(t, "client-id", tm.clientID)
 	require.Equal(t, "client-secret", tm.clientSecret)
 	require.Equal(t, store, tm.tokenStore)
 	require.Equal(t, httpClient, tm.httpClient)
+	require.Equal(t, "giftbridge/v1.4.0", tm.userAgent)
 	require.Empty(t, tm.accessToken)
 	require.True(t, tm.expiresAt.IsZero())
 }
@@ -113,6 +118,54 @@ func TestTokenManager_AccessToken(t *testing.T) {
 		require.Equal(t, "new-access-token", token)
 		require.Equal(t, "new-refresh-token", store.refreshToken)
 	})
+
+	t.Run("sends user agent when refreshing", func(t *testing.T) {
+		t.Parallel()
+
+		var userAgent string
+		tm := newTokenManager(
+			"client-id",
+			"client-secret",
+			&mockTokenStore{refreshToken: "refresh-token"},
+			&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				userAgent = req.Header.Get("User-Agent")
+				return &http.Response{
+					Body:       io.NopCloser(strings.NewReader(`{"access_token":"new-access-token"}`)),
+					Header:     http.Header{},
+					StatusCode: http.StatusOK,
+				}, nil
+			})},
+			"giftbridge/v1.4.0 (st-marys-hospice)",
+		)
+
+		_, err := tm.AccessToken(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, "giftbridge/v1.4.0 (st-marys-hospice)", userAgent)
+	})
+
+	t.Run("refuses an oversized token response", func(t *testing.T) {
+		t.Parallel()
+
+		body := `{"access_token":"` + strings.Repeat("a", maxTokenResponseSize) + `"}`
+		tm := newTokenManager(
+			"client-id",
+			"client-secret",
+			&mockTokenStore{refreshToken: "refresh-token"},
+			&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Header:     http.Header{},
+					StatusCode: http.StatusOK,
+				}, nil
+			})},
+			"",
+		)
+
+		_, err := tm.AccessToken(context.Background())
+
+		require.ErrorIs(t, err, httpclient.ErrBodyTooLarge)
+	})
 }
 
 func TestTokenManager_CachedToken(t *testing.T) {
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 // Option configures optional Client settings.
@@ -15,11 +18,26 @@ type options struct {
 	// baseURL is the base URL for API requests.
 	baseURL string
 
+	// hedgeDelay is how long a read waits for a response before an identical request is sent.
+	hedgeDelay time.Duration
+
 	// httpClient is a custom HTTP client.
 	httpClient *http.Client
 
+	// maxResponseSize is the largest response body read, in bytes.
+	maxResponseSize int
+
+	// secondaryKey is the subscription key requests fail over to when the SKY API rejects the primary one.
+	secondaryKey string
+
 	// timeout is the HTTP client timeout.
 	timeout time.Duration
+
+	// transport is the HTTP transport used when no custom HTTP client is set.
+	transport http.RoundTripper
+
+	// userAgent is the User-Agent header sent with every request.
+	userAgent string
 }
 
 // WithBaseURL sets a custom base URL for the API.
@@ -34,6 +52,19 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
+// WithHedgeDelay hedges read-only requests: when a GET has no response after delay, an identical request
+// is sent and whichever settles first is used. This trims occasional slow responses at the cost of extra calls:
+// each hedged copy uses one call from the subscription quota, and Quota.HedgedCalls counts them.
+func WithHedgeDelay(delay time.Duration) Option {
+	return func(o *options) error {
+		if delay <= 0 {
+			return fmt.Errorf("hedge delay must be positive, got %v", delay)
+		}
+		o.hedgeDelay = delay
+		return nil
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client. Overrides WithTimeout.
 func WithHTTPClient(httpClient *http.Client) Option {
 	return func(o *options) error {
@@ -45,6 +76,33 @@ func WithHTTPClient(httpClient *http.Client) Option {
 	}
 }
 
+// WithMaxResponseSize sets the largest response body read, in bytes, after decompression. Longer responses fail
+// rather than being held in memory. Defaults to httpclient.DefaultMaxBodySize.
+func WithMaxResponseSize(size int) Option {
+	return func(o *options) error {
+		if size <= 0 {
+			return fmt.Errorf("max response size must be positive, got %d", size)
+		}
+		o.maxResponseSize = size
+		return nil
+	}
+}
+
+// WithSecondarySubscriptionKey sets a second SKY API subscription key. When the SKY API rejects the primary key
+// with a 401 or 403, the request is sent again with the secondary key, and later requests use it too if it is
+// accepted. Setting the subscription's secondary key lets the primary be regenerated, or run out of quota, without
+// failing requests.
+func WithSecondarySubscriptionKey(key string) Option {
+	return func(o *options) error {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return fmt.Errorf("secondary subscription key cannot be empty")
+		}
+		o.secondaryKey = key
+		return nil
+	}
+}
+
 // WithTimeout sets the HTTP client timeout.
 func WithTimeout(timeout time.Duration) Option {
 	return func(o *options) error {
@@ -56,10 +114,36 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithTransport sets the HTTP transport, so connections can be shared with other clients.
+// Ignored when WithHTTPClient is used.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) error {
+		if transport == nil {
+			return fmt.Errorf("transport cannot be nil")
+		}
+		o.transport = transport
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request, replacing the default giftbridge version.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) error {
+		userAgent = strings.TrimSpace(userAgent)
+		if userAgent == "" {
+			return fmt.Errorf("user agent cannot be empty")
+		}
+		o.userAgent = userAgent
+		return nil
+	}
+}
+
 // defaultOptions returns options with sensible defaults.
 func defaultOptions() *options {
 	return &options{
-		baseURL: "https://api.sky.blackbaud.com",
-		timeout: 30 * time.Second,
+		baseURL:         "https://api.sky.blackbaud.com",
+		maxResponseSize: httpclient.DefaultMaxBodySize,
+		timeout:         30 * time.Second,
+		userAgent:       version.UserAgent(""),
 	}
 }
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 func TestDefaultOptions(t *testing.T) {
@@ -15,6 +18,8 @@ func TestDefaultOptions(t *testing.T) {
 
 	require.Equal(t, "https://api.sky.blackbaud.com", opts.baseURL)
 	require.Equal(t, 30*time.Second, opts.timeout)
+	require.Equal(t, version.UserAgent(""), opts.userAgent)
+	require.Equal(t, httpclient.DefaultMaxBodySize, opts.maxResponseSize)
 	require.Nil(t, opts.httpClient)
 }
 
@@ -99,6 +104,119 @@ func TestWithHTTPClient(t *testing.T) {
 	}
 }
 
+func TestWithHedgeDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		delay   time.Duration
+		wantErr bool
+	}{
+		"valid delay": {
+			delay:   2 * time.Second,
+			wantErr: false,
+		},
+		"zero delay": {
+			delay:   0,
+			wantErr: true,
+		},
+		"negative delay": {
+			delay:   -1 * time.Second,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithHedgeDelay(tc.delay)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "hedge delay must be positive")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.delay, opts.hedgeDelay)
+			}
+		})
+	}
+}
+
+func TestWithMaxResponseSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		size    int
+		wantErr bool
+	}{
+		"positive": {
+			size: 1 << 20,
+		},
+		"zero": {
+			size:    0,
+			wantErr: true,
+		},
+		"negative": {
+			size:    -1,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithMaxResponseSize(tc.size)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "max response size must be positive")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.size, opts.maxResponseSize)
+			}
+		})
+	}
+}
+
+func TestWithSecondarySubscriptionKey(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		key      string
+		expected string
+		wantErr  bool
+	}{
+		"secondary key": {
+			key:      " second-key ",
+			expected: "second-key",
+		},
+		"empty key": {
+			key:     " ",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithSecondarySubscriptionKey(tc.key)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "secondary subscription key cannot be empty")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, opts.secondaryKey)
+			}
+		})
+	}
+}
+
 func TestWithTimeout(t *testing.T) {
 	t.Parallel()
 
@@ -139,3 +257,74 @@ func TestWithTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestWithTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		transport http.RoundTripper
+		wantErr   bool
+	}{
+		"valid transport": {
+			transport: &http.Transport{},
+			wantErr:   false,
+		},
+		"nil transport": {
+			transport: nil,
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithTransport(tc.transport)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "transport cannot be nil")
+			} else {
+				require.NoError(t, err)
+				require.Same(t, tc.transport, opts.transport)
+			}
+		})
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		userAgent string
+		expected  string
+		wantErr   bool
+	}{
+		"custom user agent": {
+			userAgent: " giftbridge/v1.4.0 (st-marys-hospice) ",
+			expected:  "giftbridge/v1.4.0 (st-marys-hospice)",
+		},
+		"empty user agent": {
+			userAgent: " ",
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithUserAgent(tc.userAgent)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "user agent cannot be empty")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, opts.userAgent)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package blackbaud
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// headerQuotaLimit is the response header carrying the number of calls allowed in the current quota period.
+	headerQuotaLimit = "X-RateLimit-Limit"
+
+	// headerQuotaRemaining is the response header carrying the number of calls left in the current quota period.
+	headerQuotaRemaining = "X-RateLimit-Remaining"
+
+	// headerQuotaReset is the response header carrying the number of seconds until the quota is replenished.
+	headerQuotaReset = "X-RateLimit-Reset"
+)
+
+// Quota is the SKY API call quota for the subscription, as reported by the most recent response.
+// The quota is shared by every application using the same subscription key.
+type Quota struct {
+	// HedgedCalls is the number of extra calls hedged reads have sent since the quota was reported.
+	// Remaining does not include them yet.
+	HedgedCalls int `json:"hedgedCalls"`
+
+	// Limit is the number of calls allowed in the current quota period, or zero if not reported.
+	Limit int `json:"limit"`
+
+	// Remaining is the number of calls left in the current quota period.
+	Remaining int `json:"remaining"`
+
+	// ResetAt is when the quota is replenished, or the zero time if not reported.
+	ResetAt time.Time `json:"resetAt,omitzero"`
+
+	// UpdatedAt is when the quota was reported.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Available returns the calls left once the hedged calls sent since the quota was reported are taken off.
+func (q Quota) Available() int {
+	return q.Remaining - q.HedgedCalls
+}
+
+// Quota returns the call quota reported by the most recent API response.
+// Returns false if no response has reported a quota yet.
+func (c *Client) Quota() (Quota, bool) {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	return c.quota, !c.quota.UpdatedAt.IsZero()
+}
+
+// recordHedge counts an extra call sent by a hedged read against the quota until the next response reports it.
+func (c *Client) recordHedge() {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	c.quota.HedgedCalls++
+}
+
+// recordQuota stores the call quota reported by a response, if any.
+func (c *Client) recordQuota(header http.Header, now time.Time) {
+	quota, ok := parseQuota(header, now)
+	if !ok {
+		return
+	}
+
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	// The reported quota includes the hedged calls counted so far.
+	c.quota = quota
+}
+
+// parseQuota reads the call quota from response headers.
+// Returns false if the remaining quota is missing or malformed.
+func parseQuota(header http.Header, now time.Time) (Quota, bool) {
+	remaining, err := strconv.Atoi(strings.TrimSpace(header.Get(headerQuotaRemaining)))
+	if err != nil || remaining < 0 {
+		return Quota{}, false
+	}
+
+	quota := Quota{
+		Remaining: remaining,
+		UpdatedAt: now,
+	}
+
+	if limit, err := strconv.Atoi(strings.TrimSpace(header.Get(headerQuotaLimit))); err == nil && limit > 0 {
+		quota.Limit = limit
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header.Get(headerQuotaReset))); err == nil && seconds >= 0 {
+		quota.ResetAt = now.Add(time.Duration(seconds) * time.Second)
+	}
+
+	return quota, true
+}
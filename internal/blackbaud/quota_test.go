@@ -0,0 +1,107 @@
+package blackbaud
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuota(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.March, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		headers map[string]string
+		want    Quota
+		wantOK  bool
+	}{
+		"all headers": {
+			headers: map[string]string{
+				headerQuotaLimit:     "25000",
+				headerQuotaRemaining: "1200",
+				headerQuotaReset:     "3600",
+			},
+			want: Quota{
+				Limit:     25000,
+				Remaining: 1200,
+				ResetAt:   now.Add(time.Hour),
+				UpdatedAt: now,
+			},
+			wantOK: true,
+		},
+		"remaining only": {
+			headers: map[string]string{headerQuotaRemaining: "0"},
+			want:    Quota{Remaining: 0, UpdatedAt: now},
+			wantOK:  true,
+		},
+		"malformed limit and reset are ignored": {
+			headers: map[string]string{
+				headerQuotaLimit:     "lots",
+				headerQuotaRemaining: "50",
+				headerQuotaReset:     "-1",
+			},
+			want:   Quota{Remaining: 50, UpdatedAt: now},
+			wantOK: true,
+		},
+		"no headers": {
+			headers: map[string]string{},
+			wantOK:  false,
+		},
+		"malformed remaining": {
+			headers: map[string]string{headerQuotaRemaining: "many"},
+			wantOK:  false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			header := http.Header{}
+			for k, v := range tc.headers {
+				header.Set(k, v)
+			}
+
+			got, ok := parseQuota(header, now)
+
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClientQuota(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{}
+	now := time.Date(2025, time.March, 7, 10, 0, 0, 0, time.UTC)
+
+	_, ok := client.Quota()
+	require.False(t, ok)
+
+	header := http.Header{}
+	header.Set(headerQuotaRemaining, "10")
+	client.recordQuota(header, now)
+
+	// A response without quota headers keeps the last reported quota.
+	client.recordQuota(http.Header{}, now.Add(time.Minute))
+
+	quota, ok := client.Quota()
+	require.True(t, ok)
+	require.Equal(t, Quota{Remaining: 10, UpdatedAt: now}, quota)
+
+	// Hedged calls count against the quota until a response reports it again.
+	client.recordHedge()
+	client.recordHedge()
+	quota, _ = client.Quota()
+	require.Equal(t, 2, quota.HedgedCalls)
+	require.Equal(t, 8, quota.Available())
+
+	header.Set(headerQuotaRemaining, "7")
+	client.recordQuota(header, now.Add(2*time.Minute))
+	quota, _ = client.Quota()
+	require.Equal(t, Quota{Remaining: 7, UpdatedAt: now.Add(2 * time.Minute)}, quota)
+}
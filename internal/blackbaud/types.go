@@ -1,6 +1,18 @@
 // Package blackbaud provides a client for the Blackbaud SKY API.
 package blackbaud
 
+// MaxGiftReferenceLength is the most characters Raiser's Edge NXT accepts in a gift's reference.
+// Longer references are rejected with a 400 error.
+const MaxGiftReferenceLength = 255
+
+const (
+	// GiftPostStatusDoNotPost marks a gift that is never posted to the general ledger.
+	GiftPostStatusDoNotPost GiftPostStatus = "DoNotPost"
+
+	// GiftPostStatusNotPosted marks a gift waiting to be posted to the general ledger.
+	GiftPostStatusNotPosted GiftPostStatus = "NotPosted"
+)
+
 const (
 	// GiftSubtypeRecurring indicates a recurring gift.
 	GiftSubtypeRecurring GiftSubtype = "Recurring"
@@ -17,12 +29,23 @@ const (
 	GiftTypeRecurringGiftPayment GiftType = "RecurringGiftPayment"
 )
 
+const (
+	// RSVPStatusAttending marks an event participant who is attending.
+	RSVPStatusAttending RSVPStatus = "Attending"
+)
+
+// GiftPostStatus represents the general ledger posting status of a gift in Raiser's Edge NXT.
+type GiftPostStatus string
+
 // GiftSubtype represents the subtype of gift in Raiser's Edge NXT.
 type GiftSubtype string
 
 // GiftType represents the type of gift in Raiser's Edge NXT.
 type GiftType string
 
+// RSVPStatus represents whether an event participant is attending, in Raiser's Edge NXT.
+type RSVPStatus string
+
 // Address represents a constituent's address.
 type Address struct {
 	// AddressLines contains the street address.
@@ -31,9 +54,12 @@ type Address struct {
 	// City is the city name.
 	City string `json:"city"`
 
-	// Country is the country name or code.
+	// Country is the country name as Raiser's Edge NXT expects it (e.g., "United Kingdom").
 	Country string `json:"country"`
 
+	// County is the county, used instead of State for countries such as the United Kingdom.
+	County string `json:"county,omitempty"`
+
 	// PostCode is the postal or ZIP code.
 	PostCode string `json:"post_code"`
 
@@ -67,10 +93,67 @@ type Constituent struct {
 	// Phone is the constituent's phone number.
 	Phone *Phone `json:"phone,omitempty"`
 
+	// PrimaryAddressee is how the constituent is addressed in mail (e.g., "Mr. John Smith"),
+	// left to the organisation's default format when nil.
+	PrimaryAddressee *FormattedName `json:"primary_addressee,omitempty"`
+
+	// PrimarySalutation is how the constituent is greeted in mail (e.g., "Dear John"),
+	// left to the organisation's default format when nil.
+	PrimarySalutation *FormattedName `json:"primary_salutation,omitempty"`
+
 	// Type is the constituent type (e.g., Individual, Organization).
 	Type string `json:"type"`
 }
 
+// ConstituentAppeal records that a constituent was solicited by, or responded to, an appeal,
+// which Raiser's Edge NXT counts in the appeal's performance.
+type ConstituentAppeal struct {
+	// AppealID is the appeal the constituent responded to.
+	AppealID string `json:"appeal_id"`
+
+	// ConstituentID links the appeal to a constituent.
+	ConstituentID string `json:"constituent_id"`
+
+	// Date is when the constituent responded to the appeal, in YYYY-MM-DD format.
+	Date string `json:"date,omitempty"`
+
+	// ID is the unique constituent appeal identifier.
+	ID string `json:"id,omitempty"`
+}
+
+// ConstituentCode represents a constituent code, which categorises a constituent's relationship with the organisation.
+type ConstituentCode struct {
+	// ConstituentID links the code to a constituent.
+	ConstituentID string `json:"constituent_id"`
+
+	// Description is the constituent code from the organisation's code table (e.g., "Online Donor").
+	Description string `json:"description"`
+
+	// Start is when the code starts to apply (optional).
+	Start *FuzzyDate `json:"start,omitempty"`
+}
+
+// ConstituentNote represents a note on a constituent's record, such as a stewardship note.
+type ConstituentNote struct {
+	// ConstituentID links the note to a constituent.
+	ConstituentID string `json:"constituent_id"`
+
+	// Date is the date the note is about.
+	Date *FuzzyDate `json:"date,omitempty"`
+
+	// ID is the unique note identifier.
+	ID string `json:"id,omitempty"`
+
+	// Summary is the note's title, up to 50 characters.
+	Summary string `json:"summary,omitempty"`
+
+	// Text is the body of the note.
+	Text string `json:"text,omitempty"`
+
+	// Type is the note type from the organisation's note type table (e.g., "Stewardship").
+	Type string `json:"type"`
+}
+
 // Email represents a constituent's email.
 type Email struct {
 	// Address is the email address.
@@ -83,6 +166,42 @@ type Email struct {
 	Type string `json:"type"`
 }
 
+// EmailAddress is an email address record added to an existing constituent.
+type EmailAddress struct {
+	// Address is the email address.
+	Address string `json:"address"`
+
+	// ConstituentID links the email address to a constituent.
+	ConstituentID string `json:"constituent_id"`
+
+	// Primary indicates if this is the constituent's primary email address.
+	Primary bool `json:"primary"`
+
+	// Type is the email type from the organisation's email types table (e.g., Email, Work).
+	Type string `json:"type"`
+}
+
+// FormattedName is a constituent's name as it appears in mail, such as an addressee or salutation.
+type FormattedName struct {
+	// CustomFormat indicates the name is given in FormattedName rather than built from a name format.
+	CustomFormat bool `json:"custom_format"`
+
+	// FormattedName is the name as it appears in mail.
+	FormattedName string `json:"formatted_name"`
+}
+
+// FuzzyDate represents a date in Raiser's Edge NXT where the day or month may be unknown (zero).
+type FuzzyDate struct {
+	// Day is the day of the month.
+	Day int `json:"d,omitempty"`
+
+	// Month is the month of the year.
+	Month int `json:"m,omitempty"`
+
+	// Year is the year.
+	Year int `json:"y"`
+}
+
 // Gift represents a gift in Raiser's Edge NXT.
 type Gift struct {
 	// Amount is the gift amount.
@@ -127,22 +246,26 @@ type Gift struct {
 	// LookupID is the user-defined lookup identifier.
 	LookupID string `json:"lookup_id,omitempty"`
 
-	// Origin contains source system information as JSON with name and donation_id fields.
+	// Origin contains source system information as JSON with name and donation_id fields, and optionally the
+	// other fields of GiftOrigin.
 	Origin string `json:"origin,omitempty"`
 
 	// PaymentMethod is the payment method used.
 	PaymentMethod string `json:"payment_method,omitempty"`
 
+	// Payments contains the details of each payment made toward the gift.
+	Payments []GiftPayment `json:"payments,omitempty"`
+
 	// PostDate is the date the gift was posted.
 	PostDate string `json:"post_date,omitempty"`
 
 	// PostStatus is the posting status.
-	PostStatus string `json:"post_status,omitempty"`
+	PostStatus GiftPostStatus `json:"post_status,omitempty"`
 
 	// Receipts contains receipt information.
 	Receipts []Receipt `json:"receipts,omitempty"`
 
-	// Reference is a reference note or comment.
+	// Reference is a reference note or comment, up to MaxGiftReferenceLength characters.
 	Reference string `json:"reference,omitempty"`
 
 	// SoftCredits contains soft credit attributions.
@@ -164,13 +287,82 @@ type GiftAmount struct {
 	Value float64 `json:"value"`
 }
 
+// GiftCustomField represents a custom field on a gift's record, such as one recording the system that created it.
+type GiftCustomField struct {
+	// Category is the custom field category, as configured in the organisation's gift custom fields.
+	Category string `json:"category"`
+
+	// Comment is a comment on the value (optional).
+	Comment string `json:"comment,omitempty"`
+
+	// ID is the unique custom field identifier.
+	ID string `json:"id,omitempty"`
+
+	// ParentID links the custom field to a gift.
+	ParentID string `json:"parent_id"`
+
+	// Value is the value of the custom field, which must suit the category's data type.
+	Value string `json:"value"`
+}
+
+// GiftNote represents a note on a gift's record, such as a donor comment too long for the gift's reference.
+type GiftNote struct {
+	// Date is the date the note is about.
+	Date *FuzzyDate `json:"date,omitempty"`
+
+	// GiftID links the note to a gift.
+	GiftID string `json:"gift_id"`
+
+	// ID is the unique note identifier.
+	ID string `json:"id,omitempty"`
+
+	// Summary is the note's title, up to 50 characters.
+	Summary string `json:"summary,omitempty"`
+
+	// Text is the body of the note.
+	Text string `json:"text,omitempty"`
+
+	// Type is the note type from the organisation's gift note type table (e.g., "Comment").
+	Type string `json:"type"`
+}
+
 // GiftOrigin contains source system information for a gift.
 type GiftOrigin struct {
+	// Account is the name of the source system account the donation came from, when several are synced.
+	Account string `json:"account,omitempty"`
+
+	// CampaignID is the source system's identifier for the campaign the donation was made to.
+	CampaignID string `json:"campaign_id,omitempty"`
+
 	// DonationID is the original donation identifier from the source system.
 	DonationID string `json:"donation_id"`
 
+	// Installment is the donation's position in its recurring series, starting at 1.
+	Installment int `json:"installment,omitempty"`
+
 	// Name is the source system name.
 	Name string `json:"name"`
+
+	// RecurringID is the source system's recurring plan identifier, for gifts in a recurring series.
+	RecurringID string `json:"recurring_id,omitempty"`
+
+	// Source is the integration that created the gift.
+	Source string `json:"source,omitempty"`
+
+	// Version is the version of the integration that created the gift.
+	Version string `json:"version,omitempty"`
+}
+
+// GiftPayment represents a payment made toward a gift.
+type GiftPayment struct {
+	// CheckNumber is the check number, for payments by check.
+	CheckNumber string `json:"check_number,omitempty"`
+
+	// PaymentMethod is the payment method used.
+	PaymentMethod string `json:"payment_method"`
+
+	// Reference identifies the payment for reconciliation, such as the card brand and last four digits.
+	Reference string `json:"reference,omitempty"`
 }
 
 // GiftSplit represents how a gift is split across funds.
@@ -188,6 +380,18 @@ type GiftSplit struct {
 	FundID string `json:"fund_id"`
 }
 
+// Participant represents a constituent taking part in a Raiser's Edge NXT event.
+type Participant struct {
+	// ConstituentID links the participant to a constituent.
+	ConstituentID string `json:"constituent_id"`
+
+	// ID is the unique participant identifier.
+	ID string `json:"id,omitempty"`
+
+	// RSVPStatus is whether the participant is attending.
+	RSVPStatus RSVPStatus `json:"rsvp_status,omitempty"`
+}
+
 // Phone represents a constituent's phone number.
 type Phone struct {
 	// Number is the phone number.
@@ -212,6 +416,15 @@ type Receipt struct {
 	Status string `json:"status"`
 }
 
+// SearchOptions narrows a constituent search.
+type SearchOptions struct {
+	// IncludeInactive includes inactive constituents in the results.
+	IncludeInactive bool
+
+	// StrictEmail matches the search text against email addresses only, without fuzzy matching.
+	StrictEmail bool
+}
+
 // SoftCredit represents a soft credit on a gift.
 type SoftCredit struct {
 	// Amount is the soft credit amount.
@@ -227,6 +440,18 @@ type Tribute struct {
 	TributeID string `json:"tribute_id"`
 }
 
+// constituentAppealListResponse represents the constituent appeal list API response.
+type constituentAppealListResponse struct {
+	// Count is the total number of results.
+	Count int `json:"count"`
+
+	// NextLink is the URL for the next page of results.
+	NextLink string `json:"next_link"`
+
+	// Value contains the constituent appeals.
+	Value []ConstituentAppeal `json:"value"`
+}
+
 // constituentSearchResponse represents the constituent search API response.
 type constituentSearchResponse struct {
 	// Count is the total number of results.
@@ -254,6 +479,18 @@ type giftListResponse struct {
 	Value []Gift `json:"value"`
 }
 
+// participantListResponse represents the event participant list API response.
+type participantListResponse struct {
+	// Count is the total number of results.
+	Count int `json:"count"`
+
+	// NextLink is the URL for the next page of results.
+	NextLink string `json:"next_link"`
+
+	// Value contains the participants.
+	Value []Participant `json:"value"`
+}
+
 // tokenResponse represents the OAuth token response from Blackbaud.
 type tokenResponse struct {
 	// AccessToken is the OAuth access token.
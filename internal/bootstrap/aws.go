@@ -0,0 +1,444 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+const (
+	// StatusCreated indicates the resource was created.
+	StatusCreated ResourceStatus = "created"
+
+	// StatusExists indicates the resource already existed and was left unchanged.
+	StatusExists ResourceStatus = "exists"
+
+	// StatusSeeded indicates an existing secret without a value was seeded with a refresh token.
+	StatusSeeded ResourceStatus = "seeded"
+)
+
+// ResourceStatus describes what Provision did with a resource.
+type ResourceStatus string
+
+// SecretsManagerAPI defines the Secrets Manager operations used by the provisioner.
+type SecretsManagerAPI interface {
+	// CreateSecret creates a new secret.
+	CreateSecret(
+		ctx context.Context,
+		params *secretsmanager.CreateSecretInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.CreateSecretOutput, error)
+
+	// DescribeSecret retrieves secret metadata.
+	DescribeSecret(
+		ctx context.Context,
+		params *secretsmanager.DescribeSecretInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.DescribeSecretOutput, error)
+
+	// GetSecretValue retrieves a secret value.
+	GetSecretValue(
+		ctx context.Context,
+		params *secretsmanager.GetSecretValueInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.GetSecretValueOutput, error)
+
+	// PutSecretValue stores a secret value.
+	PutSecretValue(
+		ctx context.Context,
+		params *secretsmanager.PutSecretValueInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.PutSecretValueOutput, error)
+}
+
+// STSAPI defines the STS operations used by the provisioner.
+type STSAPI interface {
+	// GetCallerIdentity returns details about the calling credentials.
+	GetCallerIdentity(
+		ctx context.Context,
+		params *sts.GetCallerIdentityInput,
+		optFns ...func(*sts.Options),
+	) (*sts.GetCallerIdentityOutput, error)
+}
+
+// PermissionCheck records the outcome of exercising one IAM permission.
+type PermissionCheck struct {
+	// Action is the IAM action that was exercised (e.g., ssm:GetParameter).
+	Action string
+
+	// Err is the error returned by AWS, or nil if the call succeeded.
+	Err error
+
+	// Resource is the name of the resource the action was exercised against.
+	Resource string
+}
+
+// ProvisionRequest describes the resources Provision should create.
+type ProvisionRequest struct {
+	// InitialSyncTime is written to the last sync parameter when it is created.
+	InitialSyncTime time.Time
+
+	// RefreshToken optionally seeds the refresh token secret.
+	RefreshToken string
+
+	// Resources holds the names of the resources to create.
+	Resources Resources
+}
+
+// ProvisionResult contains the outcome of Provision.
+type ProvisionResult struct {
+	// CallerARN is the ARN of the credentials used to provision.
+	CallerARN string
+
+	// Checks contains the outcome of each permission check.
+	Checks []PermissionCheck
+
+	// Resources contains the status of each resource.
+	Resources []ProvisionedResource
+
+	// SecretARN is the ARN of the refresh token secret.
+	SecretARN string
+}
+
+// ProvisionedResource describes a single resource handled by Provision.
+type ProvisionedResource struct {
+	// Kind is a human-readable resource type (e.g., SSM parameter).
+	Kind string
+
+	// Name is the resource name.
+	Name string
+
+	// Status describes what was done with the resource.
+	Status ResourceStatus
+}
+
+// Provisioner creates the AWS resources required by giftbridge when they do not already exist.
+type Provisioner struct {
+	// secretsManager is the Secrets Manager API client.
+	secretsManager SecretsManagerAPI
+
+	// ssm is the SSM API client.
+	ssm storage.SSMAPI
+
+	// sts is the STS API client.
+	sts STSAPI
+}
+
+// NewProvisioner creates a new Provisioner.
+func NewProvisioner(ssmClient storage.SSMAPI, secretsClient SecretsManagerAPI, stsClient STSAPI) (*Provisioner, error) {
+	if ssmClient == nil {
+		return nil, errors.New("ssm client is required")
+	}
+	if secretsClient == nil {
+		return nil, errors.New("secrets manager client is required")
+	}
+	if stsClient == nil {
+		return nil, errors.New("sts client is required")
+	}
+
+	return &Provisioner{
+		secretsManager: secretsClient,
+		ssm:            ssmClient,
+		sts:            stsClient,
+	}, nil
+}
+
+// Failed returns true if any permission check failed.
+func (r *ProvisionResult) Failed() bool {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Provision creates any missing resources and then verifies they can be read and written.
+// Existing resources are left unchanged, so it is safe to run repeatedly.
+func (p *Provisioner) Provision(ctx context.Context, req ProvisionRequest) (*ProvisionResult, error) {
+	identity, err := p.sts.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("checking AWS credentials: %w", err)
+	}
+
+	result := &ProvisionResult{CallerARN: aws.ToString(identity.Arn)}
+
+	lastSyncStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.LastSyncParameterName,
+		req.InitialSyncTime.UTC().Format(time.RFC3339),
+		"Timestamp of the last successful sync.",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.LastSyncParameterName,
+		Status: lastSyncStatus,
+	})
+
+	pendingStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.PendingParameterName,
+		"",
+		"Comma-separated list of donation IDs pending processing (for resume after timeout).",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.PendingParameterName,
+		Status: pendingStatus,
+	})
+
+	fetchStateStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.FetchStateParameterName,
+		"",
+		"Checkpoint of an unfinished donations fetch (for resume after timeout).",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.FetchStateParameterName,
+		Status: fetchStateStatus,
+	})
+
+	runHistoryStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.RunHistoryParameterName,
+		"",
+		"Summaries of recent sync runs, newest first (read by the status command).",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.RunHistoryParameterName,
+		Status: runHistoryStatus,
+	})
+
+	retryStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.RetryParameterName,
+		"",
+		"Donations to retry after transient failures, with their attempt counts and next attempt times.",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.RetryParameterName,
+		Status: retryStatus,
+	})
+
+	poisonPillStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.PoisonPillParameterName,
+		"",
+		"Donations whose processing panicked, newest first, with their stack traces.",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.PoisonPillParameterName,
+		Status: poisonPillStatus,
+	})
+
+	giftReviewStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.GiftReviewParameterName,
+		"",
+		"Donations held for review after failing the gift checks, newest first, with the checks they failed.",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.GiftReviewParameterName,
+		Status: giftReviewStatus,
+	})
+
+	healthStatus, err := p.ensureParameter(
+		ctx,
+		req.Resources.HealthParameterName,
+		"",
+		"Health snapshot published after each sync run (read by external monitors).",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "SSM parameter",
+		Name:   req.Resources.HealthParameterName,
+		Status: healthStatus,
+	})
+
+	secretARN, secretStatus, err := p.ensureSecret(ctx, req.Resources.RefreshTokenSecretName, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	result.SecretARN = secretARN
+	result.Resources = append(result.Resources, ProvisionedResource{
+		Kind:   "Secret",
+		Name:   req.Resources.RefreshTokenSecretName,
+		Status: secretStatus,
+	})
+
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.LastSyncParameterName)...)
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.PendingParameterName)...)
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.FetchStateParameterName)...)
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.RunHistoryParameterName)...)
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.HealthParameterName)...)
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.RetryParameterName)...)
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.PoisonPillParameterName)...)
+	result.Checks = append(result.Checks, p.verifyParameter(ctx, req.Resources.GiftReviewParameterName)...)
+	result.Checks = append(result.Checks, p.verifySecret(ctx, secretARN, req.Resources.RefreshTokenSecretName))
+
+	return result, nil
+}
+
+// ensureParameter creates an SSM parameter with the given value if it does not exist.
+func (p *Provisioner) ensureParameter(
+	ctx context.Context,
+	name string,
+	value string,
+	description string,
+) (ResourceStatus, error) {
+	_, err := p.ssm.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(name)})
+	if err == nil {
+		return StatusExists, nil
+	}
+
+	var notFoundErr *ssmtypes.ParameterNotFound
+	if !errors.As(err, &notFoundErr) {
+		return "", fmt.Errorf("checking parameter %s: %w", name, err)
+	}
+
+	_, err = p.ssm.PutParameter(ctx, &ssm.PutParameterInput{
+		Description: aws.String(description),
+		Name:        aws.String(name),
+		Overwrite:   aws.Bool(false),
+		Type:        ssmtypes.ParameterTypeString,
+		Value:       aws.String(value),
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating parameter %s: %w", name, err)
+	}
+
+	return StatusCreated, nil
+}
+
+// ensureSecret creates the refresh token secret if it does not exist, seeding it with token when provided.
+// An existing secret is only seeded if it has no value yet.
+func (p *Provisioner) ensureSecret(ctx context.Context, name string, token string) (string, ResourceStatus, error) {
+	described, err := p.secretsManager.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(name),
+	})
+	if err == nil {
+		arn := aws.ToString(described.ARN)
+		if token == "" {
+			return arn, StatusExists, nil
+		}
+		return p.seedEmptySecret(ctx, arn, token)
+	}
+
+	var notFoundErr *smtypes.ResourceNotFoundException
+	if !errors.As(err, &notFoundErr) {
+		return "", "", fmt.Errorf("checking secret %s: %w", name, err)
+	}
+
+	input := &secretsmanager.CreateSecretInput{
+		Description: aws.String("Blackbaud OAuth refresh token for GiftBridge."),
+		Name:        aws.String(name),
+	}
+	if token != "" {
+		input.SecretString = aws.String(token)
+	}
+
+	created, err := p.secretsManager.CreateSecret(ctx, input)
+	if err != nil {
+		return "", "", fmt.Errorf("creating secret %s: %w", name, err)
+	}
+
+	return aws.ToString(created.ARN), StatusCreated, nil
+}
+
+// seedEmptySecret stores token in an existing secret only if it has no current value.
+func (p *Provisioner) seedEmptySecret(ctx context.Context, arn string, token string) (string, ResourceStatus, error) {
+	_, err := p.secretsManager.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(arn)})
+	if err == nil {
+		// Never overwrite a live refresh token; the Lambda rotates it on every refresh.
+		return arn, StatusExists, nil
+	}
+
+	var notFoundErr *smtypes.ResourceNotFoundException
+	if !errors.As(err, &notFoundErr) {
+		return "", "", fmt.Errorf("reading secret %s: %w", arn, err)
+	}
+
+	_, err = p.secretsManager.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(arn),
+		SecretString: aws.String(token),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("seeding secret %s: %w", arn, err)
+	}
+
+	return arn, StatusSeeded, nil
+}
+
+// verifyParameter checks the parameter can be read and written by writing back its current value.
+func (p *Provisioner) verifyParameter(ctx context.Context, name string) []PermissionCheck {
+	output, err := p.ssm.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(name)})
+	checks := []PermissionCheck{{Action: "ssm:GetParameter", Resource: name, Err: err}}
+	if err != nil {
+		return checks
+	}
+
+	value := ""
+	if output.Parameter != nil {
+		value = aws.ToString(output.Parameter.Value)
+	}
+
+	_, err = p.ssm.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(name),
+		Overwrite: aws.Bool(true),
+		Type:      ssmtypes.ParameterTypeString,
+		Value:     aws.String(value),
+	})
+
+	return append(checks, PermissionCheck{Action: "ssm:PutParameter", Resource: name, Err: err})
+}
+
+// verifySecret checks the secret can be read. A secret without a value yet is not treated as a failure.
+// Write access is not exercised because each write creates a new secret version.
+func (p *Provisioner) verifySecret(ctx context.Context, arn string, name string) PermissionCheck {
+	_, err := p.secretsManager.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(arn)})
+
+	var notFoundErr *smtypes.ResourceNotFoundException
+	if errors.As(err, &notFoundErr) {
+		err = nil
+	}
+
+	return PermissionCheck{Action: "secretsmanager:GetSecretValue", Resource: name, Err: err}
+}
@@ -0,0 +1,382 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// mockSSMClient is an in-memory SSM parameter store.
+type mockSSMClient struct {
+	params map[string]string
+	putErr error
+}
+
+func (m *mockSSMClient) GetParameter(
+	_ context.Context,
+	params *ssm.GetParameterInput,
+	_ ...func(*ssm.Options),
+) (*ssm.GetParameterOutput, error) {
+	value, ok := m.params[aws.ToString(params.Name)]
+	if !ok {
+		return nil, &ssmtypes.ParameterNotFound{}
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssmtypes.Parameter{Value: aws.String(value)}}, nil
+}
+
+func (m *mockSSMClient) PutParameter(
+	_ context.Context,
+	params *ssm.PutParameterInput,
+	_ ...func(*ssm.Options),
+) (*ssm.PutParameterOutput, error) {
+	if m.putErr != nil {
+		return nil, m.putErr
+	}
+	m.params[aws.ToString(params.Name)] = aws.ToString(params.Value)
+	return &ssm.PutParameterOutput{}, nil
+}
+
+// mockSecretsManagerClient is an in-memory secret store keyed by name, using "arn:" + name as the ARN.
+type mockSecretsManagerClient struct {
+	secrets map[string]*string
+}
+
+func (m *mockSecretsManagerClient) CreateSecret(
+	_ context.Context,
+	params *secretsmanager.CreateSecretInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.CreateSecretOutput, error) {
+	name := aws.ToString(params.Name)
+	m.secrets[name] = params.SecretString
+	return &secretsmanager.CreateSecretOutput{ARN: aws.String("arn:" + name)}, nil
+}
+
+func (m *mockSecretsManagerClient) DescribeSecret(
+	_ context.Context,
+	params *secretsmanager.DescribeSecretInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.DescribeSecretOutput, error) {
+	name := aws.ToString(params.SecretId)
+	if _, ok := m.secrets[name]; !ok {
+		return nil, &smtypes.ResourceNotFoundException{}
+	}
+	return &secretsmanager.DescribeSecretOutput{ARN: aws.String("arn:" + name)}, nil
+}
+
+func (m *mockSecretsManagerClient) GetSecretValue(
+	_ context.Context,
+	params *secretsmanager.GetSecretValueInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.GetSecretValueOutput, error) {
+	value := m.secrets[aws.ToString(params.SecretId)[len("arn:"):]]
+	if value == nil {
+		return nil, &smtypes.ResourceNotFoundException{}
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: value}, nil
+}
+
+func (m *mockSecretsManagerClient) PutSecretValue(
+	_ context.Context,
+	params *secretsmanager.PutSecretValueInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.PutSecretValueOutput, error) {
+	m.secrets[aws.ToString(params.SecretId)[len("arn:"):]] = params.SecretString
+	return &secretsmanager.PutSecretValueOutput{}, nil
+}
+
+// mockSTSClient returns a fixed caller identity.
+type mockSTSClient struct {
+	err error
+}
+
+func (m *mockSTSClient) GetCallerIdentity(
+	_ context.Context,
+	_ *sts.GetCallerIdentityInput,
+	_ ...func(*sts.Options),
+) (*sts.GetCallerIdentityOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &sts.GetCallerIdentityOutput{Arn: aws.String("arn:aws:iam::123456789012:user/admin")}, nil
+}
+
+func TestNewProvisioner(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg  string
+		secrets SecretsManagerAPI
+		ssm     storage.SSMAPI
+		sts     STSAPI
+		wantErr bool
+	}{
+		"valid inputs": {
+			secrets: &mockSecretsManagerClient{},
+			ssm:     &mockSSMClient{},
+			sts:     &mockSTSClient{},
+		},
+		"nil ssm client": {
+			secrets: &mockSecretsManagerClient{},
+			sts:     &mockSTSClient{},
+			wantErr: true,
+			errMsg:  "ssm client is required",
+		},
+		"nil secrets manager client": {
+			ssm:     &mockSSMClient{},
+			sts:     &mockSTSClient{},
+			wantErr: true,
+			errMsg:  "secrets manager client is required",
+		},
+		"nil sts client": {
+			secrets: &mockSecretsManagerClient{},
+			ssm:     &mockSSMClient{},
+			wantErr: true,
+			errMsg:  "sts client is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewProvisioner(tc.ssm, tc.secrets, tc.sts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, p)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestProvisioner_Provision(t *testing.T) {
+	t.Parallel()
+
+	resources := NewResources("giftbridge")
+	initialSync := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		existingParams  map[string]string
+		existingSecrets map[string]*string
+		refreshToken    string
+		wantParams      map[string]string
+		wantSecret      *string
+		wantStatuses    []ResourceStatus
+	}{
+		"creates everything in empty account": {
+			existingParams:  map[string]string{},
+			existingSecrets: map[string]*string{},
+			wantParams: map[string]string{
+				resources.LastSyncParameterName:   "2024-01-15T10:30:00Z",
+				resources.PendingParameterName:    "",
+				resources.FetchStateParameterName: "",
+				resources.RunHistoryParameterName: "",
+				resources.HealthParameterName:     "",
+				resources.RetryParameterName:      "",
+				resources.PoisonPillParameterName: "",
+				resources.GiftReviewParameterName: "",
+			},
+			wantSecret: nil,
+			wantStatuses: []ResourceStatus{
+				StatusCreated, StatusCreated, StatusCreated, StatusCreated,
+				StatusCreated, StatusCreated, StatusCreated, StatusCreated, StatusCreated,
+			},
+		},
+		"seeds refresh token on creation": {
+			existingParams:  map[string]string{},
+			existingSecrets: map[string]*string{},
+			refreshToken:    "local-token",
+			wantParams: map[string]string{
+				resources.LastSyncParameterName:   "2024-01-15T10:30:00Z",
+				resources.PendingParameterName:    "",
+				resources.FetchStateParameterName: "",
+				resources.RunHistoryParameterName: "",
+				resources.HealthParameterName:     "",
+				resources.RetryParameterName:      "",
+				resources.PoisonPillParameterName: "",
+				resources.GiftReviewParameterName: "",
+			},
+			wantSecret: aws.String("local-token"),
+			wantStatuses: []ResourceStatus{
+				StatusCreated, StatusCreated, StatusCreated, StatusCreated,
+				StatusCreated, StatusCreated, StatusCreated, StatusCreated, StatusCreated,
+			},
+		},
+		"leaves existing resources unchanged": {
+			existingParams: map[string]string{
+				resources.LastSyncParameterName:   "2023-06-01T00:00:00Z",
+				resources.PendingParameterName:    "don_1,don_2",
+				resources.FetchStateParameterName: "",
+				resources.RunHistoryParameterName: "",
+				resources.HealthParameterName:     "",
+				resources.RetryParameterName:      "",
+				resources.PoisonPillParameterName: "",
+				resources.GiftReviewParameterName: "",
+			},
+			existingSecrets: map[string]*string{
+				resources.RefreshTokenSecretName: aws.String("live-token"),
+			},
+			refreshToken: "local-token",
+			wantParams: map[string]string{
+				resources.LastSyncParameterName:   "2023-06-01T00:00:00Z",
+				resources.PendingParameterName:    "don_1,don_2",
+				resources.FetchStateParameterName: "",
+				resources.RunHistoryParameterName: "",
+				resources.HealthParameterName:     "",
+				resources.RetryParameterName:      "",
+				resources.PoisonPillParameterName: "",
+				resources.GiftReviewParameterName: "",
+			},
+			wantSecret: aws.String("live-token"),
+			wantStatuses: []ResourceStatus{
+				StatusExists, StatusExists, StatusExists, StatusExists,
+				StatusExists, StatusExists, StatusExists, StatusExists, StatusExists,
+			},
+		},
+		"seeds existing secret without a value": {
+			existingParams: map[string]string{
+				resources.LastSyncParameterName:   "2023-06-01T00:00:00Z",
+				resources.PendingParameterName:    "",
+				resources.FetchStateParameterName: "",
+				resources.RunHistoryParameterName: "",
+				resources.HealthParameterName:     "",
+				resources.RetryParameterName:      "",
+				resources.PoisonPillParameterName: "",
+				resources.GiftReviewParameterName: "",
+			},
+			existingSecrets: map[string]*string{
+				resources.RefreshTokenSecretName: nil,
+			},
+			refreshToken: "local-token",
+			wantParams: map[string]string{
+				resources.LastSyncParameterName:   "2023-06-01T00:00:00Z",
+				resources.PendingParameterName:    "",
+				resources.FetchStateParameterName: "",
+				resources.RunHistoryParameterName: "",
+				resources.HealthParameterName:     "",
+				resources.RetryParameterName:      "",
+				resources.PoisonPillParameterName: "",
+				resources.GiftReviewParameterName: "",
+			},
+			wantSecret: aws.String("local-token"),
+			wantStatuses: []ResourceStatus{
+				StatusExists, StatusExists, StatusExists, StatusExists,
+				StatusExists, StatusExists, StatusExists, StatusExists, StatusSeeded,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ssmClient := &mockSSMClient{params: tc.existingParams}
+			secretsClient := &mockSecretsManagerClient{secrets: tc.existingSecrets}
+			p, err := NewProvisioner(ssmClient, secretsClient, &mockSTSClient{})
+			require.NoError(t, err)
+
+			result, err := p.Provision(context.Background(), ProvisionRequest{
+				InitialSyncTime: initialSync,
+				RefreshToken:    tc.refreshToken,
+				Resources:       resources,
+			})
+
+			require.NoError(t, err)
+			require.False(t, result.Failed())
+			require.Equal(t, "arn:aws:iam::123456789012:user/admin", result.CallerARN)
+			require.Equal(t, "arn:"+resources.RefreshTokenSecretName, result.SecretARN)
+			require.Equal(t, tc.wantParams, ssmClient.params)
+			require.Equal(t, tc.wantSecret, secretsClient.secrets[resources.RefreshTokenSecretName])
+
+			statuses := make([]ResourceStatus, len(result.Resources))
+			for i, r := range result.Resources {
+				statuses[i] = r.Status
+			}
+			require.Equal(t, tc.wantStatuses, statuses)
+			require.Len(t, result.Checks, 17)
+		})
+	}
+}
+
+func TestProvisioner_ProvisionErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg string
+		ssm    *mockSSMClient
+		sts    *mockSTSClient
+	}{
+		"invalid credentials": {
+			ssm:    &mockSSMClient{params: map[string]string{}},
+			sts:    &mockSTSClient{err: errors.New("expired token")},
+			errMsg: "checking AWS credentials",
+		},
+		"parameter creation denied": {
+			ssm:    &mockSSMClient{params: map[string]string{}, putErr: errors.New("access denied")},
+			sts:    &mockSTSClient{},
+			errMsg: "creating parameter /giftbridge/last-sync-time",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			secretsClient := &mockSecretsManagerClient{secrets: map[string]*string{}}
+			p, err := NewProvisioner(tc.ssm, secretsClient, tc.sts)
+			require.NoError(t, err)
+
+			result, err := p.Provision(context.Background(), ProvisionRequest{Resources: NewResources("giftbridge")})
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+			require.Nil(t, result)
+		})
+	}
+}
+
+func TestProvisioner_ProvisionReportsFailedChecks(t *testing.T) {
+	t.Parallel()
+
+	resources := NewResources("giftbridge")
+	ssmClient := &mockSSMClient{
+		params: map[string]string{
+			resources.LastSyncParameterName:   "2023-06-01T00:00:00Z",
+			resources.PendingParameterName:    "",
+			resources.FetchStateParameterName: "",
+			resources.RunHistoryParameterName: "",
+			resources.HealthParameterName:     "",
+			resources.RetryParameterName:      "",
+			resources.PoisonPillParameterName: "",
+			resources.GiftReviewParameterName: "",
+		},
+		putErr: errors.New("access denied"),
+	}
+	secretsClient := &mockSecretsManagerClient{
+		secrets: map[string]*string{resources.RefreshTokenSecretName: aws.String("token")},
+	}
+	p, err := NewProvisioner(ssmClient, secretsClient, &mockSTSClient{})
+	require.NoError(t, err)
+
+	result, err := p.Provision(context.Background(), ProvisionRequest{Resources: resources})
+
+	require.NoError(t, err)
+	require.True(t, result.Failed())
+	require.Equal(t, "ssm:PutParameter", result.Checks[1].Action)
+	require.Error(t, result.Checks[1].Err)
+}
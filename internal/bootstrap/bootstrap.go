@@ -0,0 +1,656 @@
+// Package bootstrap generates infrastructure definitions for deploying giftbridge.
+package bootstrap
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+const (
+	// FormatCDK renders an AWS CDK (TypeScript) stack.
+	FormatCDK Format = "cdk"
+
+	// FormatTerraform renders a Terraform configuration.
+	FormatTerraform Format = "terraform"
+)
+
+const (
+	defaultScheduleExpression = "rate(1 hour)"
+	defaultStackName          = "giftbridge"
+)
+
+const (
+	fetchStateSuffix = "fetch-state"
+	giftReviewSuffix = "gift-reviews"
+	healthSuffix     = "health"
+	lastSyncSuffix   = "last-sync-time"
+	pendingSuffix    = "pending-donations"
+	poisonPillSuffix = "poison-pills"
+	retrySuffix      = "retry-schedule"
+	runHistorySuffix = "run-history"
+
+	// secretARNSuffixLength is the length of the random suffix Secrets Manager appends to secret names in ARNs,
+	// such as -AbCdEf.
+	secretARNSuffixLength = 7
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Format identifies an infrastructure-as-code output format.
+type Format string
+
+// Options configures the generated infrastructure.
+type Options struct {
+	// Names holds resource names read from config. Unset names are derived from StackName.
+	Names config.ResourceNames
+
+	// ScheduleExpression controls how often the sync runs (default: rate(1 hour)).
+	ScheduleExpression string
+
+	// StackName prefixes all resource names (default: giftbridge).
+	StackName string
+}
+
+// Resources holds the names of the AWS resources used by a giftbridge deployment.
+type Resources struct {
+	// DonationTableName is the DynamoDB table tracking synced donations.
+	DonationTableName string
+
+	// FetchStateParameterName is the SSM parameter storing the checkpoint of an unfinished donations fetch.
+	FetchStateParameterName string
+
+	// FunctionName is the Lambda function name.
+	FunctionName string
+
+	// GiftReviewParameterName is the SSM parameter storing the donations held for review after failing the gift checks.
+	GiftReviewParameterName string
+
+	// HealthParameterName is the SSM parameter storing the health snapshot for external monitors.
+	HealthParameterName string
+
+	// LastSyncParameterName is the SSM parameter storing the last sync timestamp.
+	LastSyncParameterName string
+
+	// LogGroupName is the CloudWatch log group for the Lambda function.
+	LogGroupName string
+
+	// PendingParameterName is the SSM parameter storing pending donation IDs.
+	PendingParameterName string
+
+	// PoisonPillParameterName is the SSM parameter storing the donations whose processing panicked.
+	PoisonPillParameterName string
+
+	// RefreshTokenSecretName is the Secrets Manager secret storing the Blackbaud refresh token.
+	RefreshTokenSecretName string
+
+	// RetryParameterName is the SSM parameter storing the donations to retry after transient failures.
+	RetryParameterName string
+
+	// RunHistoryParameterName is the SSM parameter storing summaries of recent runs.
+	RunHistoryParameterName string
+}
+
+// input describes a deployment input passed through to the Lambda as an environment variable.
+type input struct {
+	// Default is the default value, used when HasDefault is true.
+	Default string
+
+	// Description explains the input to whoever applies the infrastructure.
+	Description string
+
+	// EnvVar is the environment variable read by config.Load.
+	EnvVar string
+
+	// HasDefault indicates the input is optional.
+	HasDefault bool
+
+	// Sensitive indicates the value must not be echoed or logged.
+	Sensitive bool
+}
+
+// templateData is the data passed to the infrastructure templates.
+type templateData struct {
+	// EnvLastSyncParameter is the environment variable for the last sync parameter name.
+	EnvLastSyncParameter string
+
+	// EnvRefreshTokenSecretARN is the environment variable for the refresh token secret ARN.
+	EnvRefreshTokenSecretARN string
+
+	// EnvTrackerTableName is the environment variable for the donation tracker table name.
+	EnvTrackerTableName string
+
+	// Inputs are the values supplied when the infrastructure is applied.
+	Inputs []input
+
+	// Resources holds the derived resource names.
+	Resources Resources
+
+	// ScheduleExpression controls how often the sync runs.
+	ScheduleExpression string
+}
+
+// Formats returns the supported output formats.
+func Formats() []Format {
+	return []Format{FormatCDK, FormatTerraform}
+}
+
+// NewResources derives resource names from a stack name, matching the SAM template conventions.
+func NewResources(stackName string) Resources {
+	return Resources{
+		DonationTableName:       stackName + "-donations",
+		FetchStateParameterName: "/" + stackName + "/" + fetchStateSuffix,
+		FunctionName:            stackName + "-sync",
+		GiftReviewParameterName: "/" + stackName + "/" + giftReviewSuffix,
+		HealthParameterName:     "/" + stackName + "/" + healthSuffix,
+		LastSyncParameterName:   "/" + stackName + "/" + lastSyncSuffix,
+		LogGroupName:            "/aws/lambda/" + stackName + "-sync",
+		PendingParameterName:    "/" + stackName + "/" + pendingSuffix,
+		PoisonPillParameterName: "/" + stackName + "/" + poisonPillSuffix,
+		RefreshTokenSecretName:  stackName + "/blackbaud-refresh-token",
+		RetryParameterName:      "/" + stackName + "/" + retrySuffix,
+		RunHistoryParameterName: "/" + stackName + "/" + runHistorySuffix,
+	}
+}
+
+// ResolveResources returns the resource names set in names, deriving any that are unset from stackName.
+// The pending donations, fetch state, retry, poison pill, gift review, run history and health parameters sit beside
+// the last sync parameter, where the sync expects them.
+func ResolveResources(stackName string, names config.ResourceNames) (Resources, error) {
+	resources := NewResources(stackName)
+
+	if names.LastSyncParameterName != "" {
+		prefix, ok := strings.CutSuffix(names.LastSyncParameterName, lastSyncSuffix)
+		if !ok {
+			return Resources{}, fmt.Errorf("%s must end with %q", config.EnvSSMParameterName, lastSyncSuffix)
+		}
+		if !validName(names.LastSyncParameterName, "-_./") {
+			return Resources{}, fmt.Errorf("%s may only contain letters, numbers, and -_./", config.EnvSSMParameterName)
+		}
+		resources.FetchStateParameterName = prefix + fetchStateSuffix
+		resources.GiftReviewParameterName = prefix + giftReviewSuffix
+		resources.HealthParameterName = prefix + healthSuffix
+		resources.LastSyncParameterName = names.LastSyncParameterName
+		resources.PendingParameterName = prefix + pendingSuffix
+		resources.PoisonPillParameterName = prefix + poisonPillSuffix
+		resources.RetryParameterName = prefix + retrySuffix
+		resources.RunHistoryParameterName = prefix + runHistorySuffix
+	}
+
+	if names.RefreshTokenSecretARN != "" {
+		name, err := secretName(names.RefreshTokenSecretARN)
+		if err != nil {
+			return Resources{}, fmt.Errorf("%s: %w", config.EnvBlackbaudRefreshTokenSecretARN, err)
+		}
+		resources.RefreshTokenSecretName = name
+	}
+
+	if names.TrackerTableName != "" {
+		if !validName(names.TrackerTableName, "-_.") {
+			return Resources{}, fmt.Errorf("%s may only contain letters, numbers, and -_.", config.EnvTrackerTableName)
+		}
+		resources.DonationTableName = names.TrackerTableName
+	}
+
+	return resources, nil
+}
+
+// ParseFormat converts a string into a supported Format.
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, supported := range Formats() {
+		if f == supported {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported format %q (expected one of: cdk, terraform)", s)
+}
+
+// Render writes the infrastructure definition for the given format to w.
+func Render(w io.Writer, format Format, opts Options) error {
+	if err := opts.validate(); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	name, err := templateName(format)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"camel":  camelCase,
+		"pascal": pascalCase,
+		"snake":  strings.ToLower,
+	}).ParseFS(templateFS, "templates/"+name)
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+
+	resources, err := ResolveResources(opts.stackName(), opts.Names)
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	data := templateData{
+		EnvLastSyncParameter:     config.EnvSSMParameterName,
+		EnvRefreshTokenSecretARN: config.EnvBlackbaudRefreshTokenSecretARN,
+		EnvTrackerTableName:      config.EnvTrackerTableName,
+		Inputs:                   inputs(),
+		Resources:                resources,
+		ScheduleExpression:       opts.scheduleExpression(),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("rendering %s template: %w", format, err)
+	}
+
+	return nil
+}
+
+// inputs returns the deployment inputs in the order they are declared in the generated output.
+func inputs() []input {
+	return []input{
+		{
+			EnvVar:      config.EnvBlackbaudClientID,
+			Description: "Blackbaud SKY API OAuth client ID.",
+			Sensitive:   true,
+		},
+		{
+			EnvVar:      config.EnvBlackbaudClientSecret,
+			Description: "Blackbaud SKY API OAuth client secret.",
+			Sensitive:   true,
+		},
+		{
+			EnvVar:      config.EnvBlackbaudEnvironmentID,
+			Description: "Blackbaud environment identifier.",
+		},
+		{
+			EnvVar:      config.EnvBlackbaudHedgeDelay,
+			Description: "Repeat a slow Blackbaud API read after this long, e.g. 2s (optional, empty disables).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvBlackbaudQuotaReserve,
+			Description: "Pause syncing until the next run when fewer Blackbaud API calls are left (0 disables).",
+			Default:     "0",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvBlackbaudSecondarySubscriptionKey,
+			Description: "Second Blackbaud SKY API subscription key, used when the first is rejected (optional).",
+			HasDefault:  true,
+			Sensitive:   true,
+		},
+		{
+			EnvVar:      config.EnvBlackbaudSubscriptionKey,
+			Description: "Blackbaud SKY API subscription key.",
+			Sensitive:   true,
+		},
+		{
+			EnvVar:      config.EnvCommentScrubCardNumbers,
+			Description: "Remove payment card numbers from donor comments (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvCommentScrubPatterns,
+			Description: "JSON list of regular expressions removed from donor comments (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvCommentScrubWords,
+			Description: "Comma-separated words removed from donor comments (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvConstituentAddresseeFormat,
+			Description: "Addressee template for new constituents, e.g. {{.FirstName}} {{.LastName}} (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvConstituentCodes,
+			Description: "Comma-separated constituent codes added to new constituents, e.g. Online Donor (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvConstituentDonationNoteFormat,
+			Description: "Template for the note describing each donation, e.g. Donated to {{.Campaign}} (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvConstituentDonationNoteType,
+			Description: "Note type of the note describing each donation, added to its constituent (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvConstituentEvents,
+			Description: "JSON list linking FundraiseUp events to Raiser's Edge events for ticket buyers (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvConstituentSalutationFormat,
+			Description: "Salutation template for new constituents, e.g. Dear {{.FirstName}} (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvEmailAddMissing,
+			Description: "Add the donor's email to matched constituents with no email on file (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvEmailFoldGmail,
+			Description: "Ignore dots and plus tags in Gmail addresses when matching constituents (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvEmailIncludeInactive,
+			Description: "Also match inactive constituents by email (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvEmailStrictSearch,
+			Description: "Only match the exact address in constituents' email fields (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvEmailStripPlusTags,
+			Description: "Ignore plus tags in all email addresses when matching constituents (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpAccounts,
+			Description: "JSON list of further FundraiseUp accounts to sync, each with a name and apiKey (optional).",
+			HasDefault:  true,
+			Sensitive:   true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpAPIKey,
+			Description: "FundraiseUp API key.",
+			Sensitive:   true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpCampaignID,
+			Description: "Only sync donations to this FundraiseUp campaign (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpCampaignWindows,
+			Description: "JSON list of FundraiseUp campaigns synced only within a date window, or excluded (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpFetchOverlap,
+			Description: "Fetch donations from this long before the last sync, e.g. 15m (optional, empty disables).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpPageSize,
+			Description: "Number of donations fetched per FundraiseUp API request (1 to 100).",
+			Default:     "100",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpStatus,
+			Description: "Only sync donations with this FundraiseUp status, e.g. succeeded (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvFundraiseUpStrictDecode,
+			Description: "Log FundraiseUp donation fields that GiftBridge does not map (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftAppealID,
+			Description: "Raiser's Edge Appeal ID to attribute gifts to (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftAppealResponses,
+			Description: "Record each donor's response to the appeal of their gift (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftCampaignID,
+			Description: "Raiser's Edge Campaign ID to attribute gifts to (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftChecks,
+			Description: "JSON list of checks each gift must pass before it is created (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftCountryRoutes,
+			Description: "JSON list of routes setting the fund, campaign or appeal by supporter country (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftCreatorCustomField,
+			Description: "Gift custom field category recording that GiftBridge created each gift (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftDatePolicy,
+			Description: "What to do with gifts dated in the future or too long ago: flag or refuse (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftFundID,
+			Description: "Raiser's Edge Fund ID where gifts are recorded (required).",
+		},
+		{
+			EnvVar:      config.EnvGiftMaxAgeDays,
+			Description: "Days old a gift's date may be before the date policy applies (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftPostDate,
+			Description: "Post date of NotPosted gifts: donation (default) or sync (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftPostStatus,
+			Description: "Posting status of new gifts: NotPosted or DoNotPost (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftRecurringMode,
+			Description: "How recurring donations are recorded: linked (default) or flat, as plain gifts.",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftReferenceField,
+			Description: "Gift field storing the FundraiseUp donation ID: lookup_id (default) or origin.",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftReferenceNoteType,
+			Description: "Gift note type of the note holding a donor comment too long for the reference (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftRules,
+			Description: "JSON list of rules computing gift fields from each donation (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftSplits,
+			Description: "JSON list of splits sending an amount or percentage of each gift to other funds (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftTestDonations,
+			Description: "What to do with donations made in FundraiseUp's test mode: skip (default) or sync.",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftTestFundID,
+			Description: "Fund ID synced test-mode donations are recorded in (optional, requires sync).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvGiftType,
+			Description: "Gift type in Raiser's Edge (e.g., Donation, Grant).",
+			Default:     "Donation",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvNameTitleCase,
+			Description: "Title-case all-lowercase or all-uppercase names of new constituents (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvNameTransliterate,
+			Description: "Replace accented letters with ASCII in names of new constituents (true or false).",
+			Default:     "false",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvProxyBypass,
+			Description: "Comma-separated hosts whose API requests skip the proxy (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvProxyURL,
+			Description: "HTTP proxy for API requests, e.g. http://proxy.internal:3128 (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvUserAgentOrganization,
+			Description: "Organization identifier sent to the APIs in the User-Agent header (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvVaultAddr,
+			Description: "Vault server URL, to keep the refresh token in Vault instead of Secrets Manager (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvVaultKVMount,
+			Description: "Path the Vault KV version 2 secrets engine is mounted at (optional, default: secret).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvVaultNamespace,
+			Description: "Vault Enterprise namespace of the refresh token secret (optional).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvVaultSecretPath,
+			Description: "Path of the Vault secret holding the refresh token (required with VAULT_ADDR).",
+			HasDefault:  true,
+		},
+		{
+			EnvVar:      config.EnvVaultToken,
+			Description: "Vault token used to read and rotate the refresh token (required with VAULT_ADDR).",
+			HasDefault:  true,
+			Sensitive:   true,
+		},
+	}
+}
+
+// camelCase converts an environment variable name such as GIFT_FUND_ID to giftFundId.
+func camelCase(s string) string {
+	p := pascalCase(s)
+	if p == "" {
+		return p
+	}
+	return strings.ToLower(p[:1]) + p[1:]
+}
+
+// pascalCase converts an environment variable name such as GIFT_FUND_ID to GiftFundId.
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(strings.ToLower(s), "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// secretName returns the name of the secret identified by arn. Values that are not ARNs are taken to be names.
+func secretName(arn string) (string, error) {
+	if !strings.HasPrefix(arn, "arn:") {
+		if !validName(arn, "-_/+=.@") {
+			return "", errors.New("secret name may only contain letters, numbers, and -_/+=.@")
+		}
+		return arn, nil
+	}
+
+	_, name, ok := strings.Cut(arn, ":secret:")
+	if !ok || len(name) <= secretARNSuffixLength || name[len(name)-secretARNSuffixLength] != '-' {
+		return "", fmt.Errorf("%q is not a Secrets Manager secret ARN", arn)
+	}
+	name = name[:len(name)-secretARNSuffixLength]
+	if !validName(name, "-_/+=.@") {
+		return "", errors.New("secret name may only contain letters, numbers, and -_/+=.@")
+	}
+
+	return name, nil
+}
+
+// templateName returns the embedded template file name for a format.
+func templateName(format Format) (string, error) {
+	switch format {
+	case FormatCDK:
+		return "cdk.ts.tmpl", nil
+	case FormatTerraform:
+		return "terraform.tf.tmpl", nil
+	default:
+		return "", fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// validName reports whether s contains only letters, numbers, and the characters in extra.
+func validName(s string, extra string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && !strings.ContainsRune(extra, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// scheduleExpression returns the configured schedule or the default.
+func (o Options) scheduleExpression() string {
+	if s := strings.TrimSpace(o.ScheduleExpression); s != "" {
+		return s
+	}
+	return defaultScheduleExpression
+}
+
+// stackName returns the configured stack name or the default.
+func (o Options) stackName() string {
+	if s := strings.TrimSpace(o.StackName); s != "" {
+		return s
+	}
+	return defaultStackName
+}
+
+// validate checks that the options can be safely rendered into resource names.
+func (o Options) validate() error {
+	if !validName(o.stackName(), "-") {
+		return errors.New("stack name may only contain letters, numbers, and hyphens")
+	}
+	schedule := o.scheduleExpression()
+	if !strings.HasPrefix(schedule, "rate(") && !strings.HasPrefix(schedule, "cron(") {
+		return fmt.Errorf("schedule expression must be rate(...) or cron(...), got %q", schedule)
+	}
+	return nil
+}
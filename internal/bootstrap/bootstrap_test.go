@@ -0,0 +1,286 @@
+package bootstrap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		"terraform": {
+			input: "terraform",
+			want:  FormatTerraform,
+		},
+		"cdk": {
+			input: "cdk",
+			want:  FormatCDK,
+		},
+		"mixed case with whitespace": {
+			input: " Terraform ",
+			want:  FormatTerraform,
+		},
+		"unsupported format": {
+			input:   "pulumi",
+			wantErr: true,
+		},
+		"empty": {
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseFormat(tc.input)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "unsupported format")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewResources(t *testing.T) {
+	t.Parallel()
+
+	got := NewResources("charity")
+
+	require.Equal(t, Resources{
+		DonationTableName:       "charity-donations",
+		FetchStateParameterName: "/charity/fetch-state",
+		FunctionName:            "charity-sync",
+		GiftReviewParameterName: "/charity/gift-reviews",
+		HealthParameterName:     "/charity/health",
+		LastSyncParameterName:   "/charity/last-sync-time",
+		LogGroupName:            "/aws/lambda/charity-sync",
+		PendingParameterName:    "/charity/pending-donations",
+		PoisonPillParameterName: "/charity/poison-pills",
+		RefreshTokenSecretName:  "charity/blackbaud-refresh-token",
+		RetryParameterName:      "/charity/retry-schedule",
+		RunHistoryParameterName: "/charity/run-history",
+	}, got)
+}
+
+func TestResolveResources(t *testing.T) {
+	t.Parallel()
+
+	defaults := NewResources("charity")
+
+	tests := map[string]struct {
+		names   config.ResourceNames
+		want    Resources
+		wantErr string
+	}{
+		"nothing configured": {
+			want: defaults,
+		},
+		"all configured": {
+			names: config.ResourceNames{
+				LastSyncParameterName: "/prod/giftbridge/last-sync-time",
+				RefreshTokenSecretARN: "arn:aws:secretsmanager:eu-west-2:123456789012:secret:prod/bb-token-AbCdEf",
+				TrackerTableName:      "prod-gifts",
+			},
+			want: Resources{
+				DonationTableName:       "prod-gifts",
+				FetchStateParameterName: "/prod/giftbridge/fetch-state",
+				FunctionName:            "charity-sync",
+				GiftReviewParameterName: "/prod/giftbridge/gift-reviews",
+				HealthParameterName:     "/prod/giftbridge/health",
+				LastSyncParameterName:   "/prod/giftbridge/last-sync-time",
+				LogGroupName:            "/aws/lambda/charity-sync",
+				PendingParameterName:    "/prod/giftbridge/pending-donations",
+				PoisonPillParameterName: "/prod/giftbridge/poison-pills",
+				RefreshTokenSecretName:  "prod/bb-token",
+				RetryParameterName:      "/prod/giftbridge/retry-schedule",
+				RunHistoryParameterName: "/prod/giftbridge/run-history",
+			},
+		},
+		"secret name instead of ARN": {
+			names: config.ResourceNames{RefreshTokenSecretARN: "prod/bb-token"},
+			want: func() Resources {
+				r := defaults
+				r.RefreshTokenSecretName = "prod/bb-token"
+				return r
+			}(),
+		},
+		"parameter without last sync suffix": {
+			names:   config.ResourceNames{LastSyncParameterName: "/prod/giftbridge/state"},
+			wantErr: config.EnvSSMParameterName + ` must end with "last-sync-time"`,
+		},
+		"parameter with invalid characters": {
+			names:   config.ResourceNames{LastSyncParameterName: `/prod"/last-sync-time`},
+			wantErr: config.EnvSSMParameterName + " may only contain letters, numbers, and -_./",
+		},
+		"ARN of another service": {
+			names: config.ResourceNames{RefreshTokenSecretARN: "arn:aws:ssm:eu-west-2:123456789012:parameter/token"},
+			wantErr: config.EnvBlackbaudRefreshTokenSecretARN +
+				`: "arn:aws:ssm:eu-west-2:123456789012:parameter/token" is not a Secrets Manager secret ARN`,
+		},
+		"ARN without random suffix": {
+			names: config.ResourceNames{RefreshTokenSecretARN: "arn:aws:secretsmanager:eu-west-2:123456789012:secret:token"},
+			wantErr: config.EnvBlackbaudRefreshTokenSecretARN +
+				`: "arn:aws:secretsmanager:eu-west-2:123456789012:secret:token" is not a Secrets Manager secret ARN`,
+		},
+		"table with invalid characters": {
+			names:   config.ResourceNames{TrackerTableName: "prod/gifts"},
+			wantErr: config.EnvTrackerTableName + " may only contain letters, numbers, and -_.",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ResolveResources("charity", tc.names)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		format       Format
+		opts         Options
+		wantContains []string
+	}{
+		"terraform with defaults": {
+			format: FormatTerraform,
+			opts:   Options{},
+			wantContains: []string{
+				`name         = "giftbridge-donations"`,
+				`name            = "RecurringIdIndex"`,
+				`name            = "CreatedMonthIndex"`,
+				`attribute_name = "expires_at"`,
+				`"dynamodb:BatchGetItem",`,
+				`"dynamodb:BatchWriteItem",`,
+				`billing_mode = "PAY_PER_REQUEST"`,
+				`name        = "/giftbridge/last-sync-time"`,
+				`parameter/giftbridge/pending-donations`,
+				`parameter/giftbridge/fetch-state`,
+				`parameter/giftbridge/run-history`,
+				`parameter/giftbridge/health`,
+				`parameter/giftbridge/retry-schedule`,
+				`parameter/giftbridge/poison-pills`,
+				`parameter/giftbridge/gift-reviews`,
+				`name        = "giftbridge/blackbaud-refresh-token"`,
+				`function_name    = "giftbridge-sync"`,
+				`schedule_expression = "rate(1 hour)"`,
+				config.EnvSSMParameterName + ` = aws_ssm_parameter.last_sync_time.name`,
+				config.EnvTrackerTableName + ` = aws_dynamodb_table.donations.name`,
+				config.EnvGiftFundID + ` = var.gift_fund_id`,
+				`variable "blackbaud_client_secret"`,
+			},
+		},
+		"cdk with custom stack and schedule": {
+			format: FormatCDK,
+			opts: Options{
+				ScheduleExpression: "rate(15 minutes)",
+				StackName:          "charity",
+			},
+			wantContains: []string{
+				`tableName: 'charity-donations'`,
+				`indexName: 'RecurringIdIndex'`,
+				`indexName: 'CreatedMonthIndex'`,
+				`timeToLiveAttribute: 'expires_at'`,
+				`billingMode: dynamodb.BillingMode.PAY_PER_REQUEST`,
+				`parameterName: '/charity/last-sync-time'`,
+				`parameter/charity/pending-donations`,
+				`parameter/charity/fetch-state`,
+				`parameter/charity/run-history`,
+				`parameter/charity/health`,
+				`parameter/charity/retry-schedule`,
+				`parameter/charity/poison-pills`,
+				`parameter/charity/gift-reviews`,
+				`secretName: 'charity/blackbaud-refresh-token'`,
+				`functionName: 'charity-sync'`,
+				`events.Schedule.expression('rate(15 minutes)')`,
+				config.EnvBlackbaudRefreshTokenSecretARN + `: refreshTokenSecret.secretArn`,
+				config.EnvTrackerTableName + `: donationTable.tableName`,
+				config.EnvGiftFundID + `: giftFundId.valueAsString`,
+				`new cdk.CfnParameter(this, 'BlackbaudClientSecret'`,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := Render(&buf, tc.format, tc.opts)
+
+			require.NoError(t, err)
+			for _, want := range tc.wantContains {
+				require.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg string
+		format Format
+		opts   Options
+	}{
+		"unsupported format": {
+			format: "pulumi",
+			errMsg: "unsupported format",
+		},
+		"invalid stack name": {
+			format: FormatTerraform,
+			opts:   Options{StackName: "my stack"},
+			errMsg: "stack name may only contain",
+		},
+		"invalid schedule expression": {
+			format: FormatCDK,
+			opts:   Options{ScheduleExpression: "hourly"},
+			errMsg: "schedule expression must be",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := Render(&buf, tc.format, tc.opts)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestPascalCase(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "GiftFundId", pascalCase("GIFT_FUND_ID"))
+	require.Equal(t, "giftFundId", camelCase("GIFT_FUND_ID"))
+	require.Empty(t, camelCase(""))
+}
@@ -2,13 +2,69 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/transform"
 )
 
 const (
+	// EnvAWSEndpointURL overrides the endpoint for all AWS services.
+	EnvAWSEndpointURL = "AWS_ENDPOINT_URL"
+
+	// EnvAWSEndpointURLDynamoDB overrides the DynamoDB endpoint.
+	EnvAWSEndpointURLDynamoDB = "AWS_ENDPOINT_URL_DYNAMODB"
+
+	// EnvAWSEndpointURLS3 overrides the S3 endpoint.
+	EnvAWSEndpointURLS3 = "AWS_ENDPOINT_URL_S3"
+
+	// EnvAWSEndpointURLSecretsManager overrides the Secrets Manager endpoint.
+	EnvAWSEndpointURLSecretsManager = "AWS_ENDPOINT_URL_SECRETS_MANAGER"
+
+	// EnvAWSEndpointURLSSM overrides the SSM endpoint.
+	EnvAWSEndpointURLSSM = "AWS_ENDPOINT_URL_SSM"
+
+	// EnvAWSEndpointURLSTS overrides the STS endpoint.
+	EnvAWSEndpointURLSTS = "AWS_ENDPOINT_URL_STS"
+
+	// EnvAWSResourceRoleARN is an IAM role to assume when accessing the state, secret, and tracker resources,
+	// typically in another account.
+	EnvAWSResourceRoleARN = "AWS_RESOURCE_ROLE_ARN"
+
+	// EnvAWSResourceRoleExternalID is the external ID required by the trust policy of the resource role.
+	EnvAWSResourceRoleExternalID = "AWS_RESOURCE_ROLE_EXTERNAL_ID"
+
+	// EnvAWSResourceRegion is the region of the state, secret, and tracker resources,
+	// when different from the region the Lambda runs in.
+	EnvAWSResourceRegion = "AWS_RESOURCE_REGION"
+
+	// EnvAzureClientID is the client ID of the user-assigned managed identity used to reach Azure storage
+	// (optional, default: the app's system-assigned identity).
+	EnvAzureClientID = "AZURE_CLIENT_ID"
+
+	// EnvAzureKeyVaultURL is the URL of the Azure Key Vault keeping the refresh token, such as
+	// https://giftbridge.vault.azure.net (required with STORAGE_PROVIDER=azure).
+	EnvAzureKeyVaultURL = "AZURE_KEY_VAULT_URL"
+
+	// EnvAzureRefreshTokenSecret is the name of the Key Vault secret storing the refresh token
+	// (required with STORAGE_PROVIDER=azure).
+	EnvAzureRefreshTokenSecret = "AZURE_REFRESH_TOKEN_SECRET"
+
+	// EnvAzureTableEndpoint is the Table Storage endpoint of the storage account keeping the sync state, such as
+	// https://giftbridge.table.core.windows.net (required with STORAGE_PROVIDER=azure).
+	EnvAzureTableEndpoint = "AZURE_TABLE_ENDPOINT"
+
+	// EnvAzureTableName is the table keeping the sync state (optional, default: giftbridge).
+	EnvAzureTableName = "AZURE_TABLE_NAME"
+
 	// EnvBlackbaudAPIBaseURL is the base URL for the Blackbaud SKY API.
 	EnvBlackbaudAPIBaseURL = "BLACKBAUD_API_BASE_URL"
 
@@ -21,37 +77,459 @@ const (
 	// EnvBlackbaudEnvironmentID is the Blackbaud environment identifier.
 	EnvBlackbaudEnvironmentID = "BLACKBAUD_ENVIRONMENT_ID"
 
+	// EnvBlackbaudHedgeDelay is how long a SKY API read waits for a response before an identical request is sent,
+	// as a duration such as 2s (optional, unset disables).
+	EnvBlackbaudHedgeDelay = "BLACKBAUD_HEDGE_DELAY"
+
+	// EnvBlackbaudQuotaReserve is the remaining SKY API call quota below which processing pauses until the next run,
+	// leaving calls for other integrations on the same subscription (optional, 0 disables).
+	EnvBlackbaudQuotaReserve = "BLACKBAUD_QUOTA_RESERVE"
+
 	// EnvBlackbaudRefreshTokenSecretARN is the Secrets Manager ARN for the refresh token.
 	EnvBlackbaudRefreshTokenSecretARN = "BLACKBAUD_REFRESH_TOKEN_SECRET_ARN"
 
+	// EnvBlackbaudSecondarySubscriptionKey is a second SKY API subscription key, used when the SKY API rejects
+	// the primary one, so the primary can be regenerated without failing runs (optional).
+	EnvBlackbaudSecondarySubscriptionKey = "BLACKBAUD_SECONDARY_SUBSCRIPTION_KEY"
+
 	// EnvBlackbaudSubscriptionKey is the SKY API subscription key.
 	EnvBlackbaudSubscriptionKey = "BLACKBAUD_SUBSCRIPTION_KEY"
 
 	// EnvBlackbaudTokenURL is the OAuth token endpoint URL.
 	EnvBlackbaudTokenURL = "BLACKBAUD_TOKEN_URL"
 
+	// EnvCommentScrubCardNumbers enables removing card numbers from donor comments.
+	EnvCommentScrubCardNumbers = "COMMENT_SCRUB_CARD_NUMBERS"
+
+	// EnvCommentScrubPatterns is a JSON list of regular expressions removed from donor comments (optional).
+	EnvCommentScrubPatterns = "COMMENT_SCRUB_PATTERNS"
+
+	// EnvCommentScrubWords is a comma-separated list of words removed from donor comments (optional).
+	EnvCommentScrubWords = "COMMENT_SCRUB_WORDS"
+
+	// EnvConfigDir is the directory holding the local config file and refresh token, overriding the
+	// platform's user config directory (optional, local runs only).
+	EnvConfigDir = "GIFTBRIDGE_CONFIG_DIR"
+
+	// EnvConstituentAddresseeFormat is a text/template format for the primary addressee of new constituents,
+	// such as "{{.FirstName}} {{.LastName}}" (optional).
+	EnvConstituentAddresseeFormat = "CONSTITUENT_ADDRESSEE_FORMAT"
+
+	// EnvConstituentCodes is a comma-separated list of constituent codes applied to new constituents (optional).
+	EnvConstituentCodes = "CONSTITUENT_CODES"
+
+	// EnvConstituentDonationNoteFormat is a text/template format for the note added to the constituent of each new
+	// gift, such as "Online donation to {{.Campaign}}" (optional, requires CONSTITUENT_DONATION_NOTE_TYPE).
+	EnvConstituentDonationNoteFormat = "CONSTITUENT_DONATION_NOTE_FORMAT"
+
+	// EnvConstituentDonationNoteType is the note type of the note added to the constituent of each new gift
+	// (optional, unset adds no notes).
+	EnvConstituentDonationNoteType = "CONSTITUENT_DONATION_NOTE_TYPE"
+
+	// EnvConstituentEvents is a JSON list of links from FundraiseUp events to Raiser's Edge NXT events, whose
+	// ticket buyers are added to the event as participants (optional).
+	EnvConstituentEvents = "CONSTITUENT_EVENTS"
+
+	// EnvConstituentSalutationFormat is a text/template format for the primary salutation of new constituents,
+	// such as "Dear {{.FirstName}}" (optional).
+	EnvConstituentSalutationFormat = "CONSTITUENT_SALUTATION_FORMAT"
+
+	// EnvEmailAddMissing enables adding the supporter's email to matched constituents with no email on file.
+	EnvEmailAddMissing = "EMAIL_ADD_MISSING"
+
+	// EnvEmailFoldGmail enables folding Gmail addresses (dots and plus tags) when matching constituents.
+	EnvEmailFoldGmail = "EMAIL_FOLD_GMAIL"
+
+	// EnvEmailIncludeInactive includes inactive constituents when matching constituents by email.
+	EnvEmailIncludeInactive = "EMAIL_INCLUDE_INACTIVE"
+
+	// EnvEmailStrictSearch restricts constituent searches to exact matches on email addresses.
+	EnvEmailStrictSearch = "EMAIL_STRICT_SEARCH"
+
+	// EnvEmailStripPlusTags enables ignoring plus tags (name+tag@example.com) when matching constituents.
+	EnvEmailStripPlusTags = "EMAIL_STRIP_PLUS_TAGS"
+
+	// EnvFundraiseUpAccounts is a JSON list of further FundraiseUp accounts synced with the API key's, each with a
+	// name and API key, such as [{"name":"trading","apiKey":"..."}] (optional).
+	EnvFundraiseUpAccounts = "FUNDRAISEUP_ACCOUNTS"
+
 	// EnvFundraiseUpAPIKey is the API key for FundraiseUp.
 	EnvFundraiseUpAPIKey = "FUNDRAISEUP_API_KEY"
 
 	// EnvFundraiseUpBaseURL is the base URL for the FundraiseUp API.
 	EnvFundraiseUpBaseURL = "FUNDRAISEUP_BASE_URL"
 
+	// EnvFundraiseUpCampaignID restricts synced donations to a single FundraiseUp campaign (optional).
+	EnvFundraiseUpCampaignID = "FUNDRAISEUP_CAMPAIGN_ID"
+
+	// EnvFundraiseUpCampaignWindows is a JSON list of FundraiseUp campaigns whose donations are only synced when made
+	// within a window, or are not synced at all (optional).
+	EnvFundraiseUpCampaignWindows = "FUNDRAISEUP_CAMPAIGN_WINDOWS"
+
+	// EnvFundraiseUpFetchOverlap is how far before the last sync time each run starts fetching donations,
+	// such as 15m, so donations FundraiseUp lists late are not missed (optional).
+	EnvFundraiseUpFetchOverlap = "FUNDRAISEUP_FETCH_OVERLAP"
+
+	// EnvFundraiseUpPageSize is the number of donations fetched per FundraiseUp API request (default: 100).
+	EnvFundraiseUpPageSize = "FUNDRAISEUP_PAGE_SIZE"
+
+	// EnvFundraiseUpStrictDecode enables logging FundraiseUp payload fields the mapper does not decode (optional).
+	EnvFundraiseUpStrictDecode = "FUNDRAISEUP_STRICT_DECODE"
+
+	// EnvFundraiseUpStatus restricts synced donations to those with the given FundraiseUp status (optional).
+	EnvFundraiseUpStatus = "FUNDRAISEUP_STATUS"
+
+	// EnvGCPFirestoreDocument is the path of the Firestore document keeping the sync state
+	// (optional, default: giftbridge/sync-state).
+	EnvGCPFirestoreDocument = "GCP_FIRESTORE_DOCUMENT"
+
+	// EnvGCPProject is the Google Cloud project of the Secret Manager secret and Firestore database
+	// (required with STORAGE_PROVIDER=gcp).
+	EnvGCPProject = "GCP_PROJECT"
+
+	// EnvGCPRefreshTokenSecret is the ID of the Secret Manager secret storing the refresh token
+	// (required with STORAGE_PROVIDER=gcp).
+	EnvGCPRefreshTokenSecret = "GCP_REFRESH_TOKEN_SECRET"
+
 	// EnvGiftAppealID is the Raiser's Edge Appeal ID for gifts.
 	EnvGiftAppealID = "GIFT_APPEAL_ID"
 
+	// EnvGiftAppealResponses records each donor's response to the appeal of their gift in Raiser's Edge NXT.
+	EnvGiftAppealResponses = "GIFT_APPEAL_RESPONSES"
+
 	// EnvGiftCampaignID is the Raiser's Edge Campaign ID for gifts.
 	EnvGiftCampaignID = "GIFT_CAMPAIGN_ID"
 
+	// EnvGiftChecks is a JSON list of checks each gift must pass before it is created, such as a maximum amount
+	// (optional).
+	EnvGiftChecks = "GIFT_CHECKS"
+
+	// EnvGiftCountryRoutes is a JSON list of routes sending gifts from supporters in given countries to their own
+	// fund, campaign or appeal (optional).
+	EnvGiftCountryRoutes = "GIFT_COUNTRY_ROUTES"
+
+	// EnvGiftCreatorCustomField is the gift custom field category recording that GiftBridge created each gift, and in
+	// which run (optional).
+	EnvGiftCreatorCustomField = "GIFT_CREATOR_CUSTOM_FIELD"
+
+	// EnvGiftDatePolicy is what to do with gifts dated in the future or older than GIFT_MAX_AGE_DAYS:
+	// "flag" or "refuse" (optional, unchecked if unset).
+	EnvGiftDatePolicy = "GIFT_DATE_POLICY"
+
 	// EnvGiftFundID is the Raiser's Edge Fund ID for gifts.
 	EnvGiftFundID = "GIFT_FUND_ID"
 
+	// EnvGiftMaxAgeDays is how many days old a gift's date may be before GIFT_DATE_POLICY applies to it
+	// (optional, requires GIFT_DATE_POLICY, no limit if unset or 0).
+	EnvGiftMaxAgeDays = "GIFT_MAX_AGE_DAYS"
+
+	// EnvGiftPostDate chooses the post date of NotPosted gifts: "donation" (default) or "sync" (optional).
+	EnvGiftPostDate = "GIFT_POST_DATE"
+
+	// EnvGiftPostStatus is the posting status of new gifts: NotPosted or DoNotPost (optional, API default if unset).
+	EnvGiftPostStatus = "GIFT_POST_STATUS"
+
+	// EnvGiftRecurringMode is how recurring donations are recorded: "linked" (default), as a RecurringGift and its
+	// linked payments, or "flat", as plain gifts of GIFT_TYPE.
+	EnvGiftRecurringMode = "GIFT_RECURRING_MODE"
+
+	// EnvGiftReferenceField is the gift field storing the FundraiseUp donation ID: lookup_id (default) or origin.
+	EnvGiftReferenceField = "GIFT_REFERENCE_FIELD"
+
+	// EnvGiftReferenceNoteType is the gift note type of the note holding a donor comment too long for the gift's
+	// reference (optional, the comment is only truncated if unset).
+	EnvGiftReferenceNoteType = "GIFT_REFERENCE_NOTE_TYPE"
+
+	// EnvGiftRules is a JSON list of rules computing gift fields from each donation (optional).
+	EnvGiftRules = "GIFT_RULES"
+
+	// EnvGiftSplits is a JSON list of splits sending an amount or percentage of each gift to other funds (optional).
+	EnvGiftSplits = "GIFT_SPLITS"
+
+	// EnvGiftTestDonations is what to do with donations made in FundraiseUp's test mode: "skip" (default) or
+	// "sync", for integration testing against a sandbox environment (optional).
+	EnvGiftTestDonations = "GIFT_TEST_DONATIONS"
+
+	// EnvGiftTestFundID is the Raiser's Edge Fund ID synced test-mode donations are recorded in,
+	// in place of every other fund (optional, requires GIFT_TEST_DONATIONS=sync).
+	EnvGiftTestFundID = "GIFT_TEST_FUND_ID"
+
 	// EnvGiftType is the gift type in Raiser's Edge (default: Donation).
 	EnvGiftType = "GIFT_TYPE"
 
+	// EnvNameTitleCase enables title-casing all-lowercase or all-uppercase names of new constituents.
+	EnvNameTitleCase = "NAME_TITLE_CASE"
+
+	// EnvNameTransliterate enables replacing accented letters with ASCII in names of new constituents.
+	EnvNameTransliterate = "NAME_TRANSLITERATE"
+
+	// EnvProxyBypass is a comma-separated list of hosts whose API requests skip PROXY_URL (optional).
+	// Subdomains of each host and loopback addresses also skip the proxy, and "*" disables it.
+	EnvProxyBypass = "PROXY_BYPASS"
+
+	// EnvProxyURL is the HTTP proxy API requests are sent through (optional, default: HTTPS_PROXY).
+	EnvProxyURL = "PROXY_URL"
+
 	// EnvSSMParameterName is the SSM parameter storing the last sync timestamp.
 	EnvSSMParameterName = "SSM_PARAMETER_NAME"
+
+	// EnvStorageProvider is the cloud keeping the sync state and refresh token: aws (default), gcp or azure.
+	EnvStorageProvider = "STORAGE_PROVIDER"
+
+	// EnvSyncFailFast stops each run at the first donation that fails, leaving the rest pending for the next run
+	// (optional).
+	EnvSyncFailFast = "SYNC_FAIL_FAST"
+
+	// EnvSyncMaxDonationBytes is the largest a donation may be, in bytes of its JSON encoding (default: 65536).
+	// A larger donation has its comment shortened to fit, or fails if it is too large even without one.
+	EnvSyncMaxDonationBytes = "SYNC_MAX_DONATION_BYTES"
+
+	// EnvSyncMaxResponseBytes is the largest FundraiseUp or Blackbaud API response read, in bytes
+	// (default: 16777216).
+	EnvSyncMaxResponseBytes = "SYNC_MAX_RESPONSE_BYTES"
+
+	// EnvTLSCABundle is a PEM file path or Secrets Manager secret ARN of extra certificate authorities
+	// trusted for API servers (optional).
+	EnvTLSCABundle = "TLS_CA_BUNDLE"
+
+	// EnvTLSClientCert is a PEM file path or Secrets Manager secret ARN of the client certificate
+	// presented to API servers (optional, requires TLS_CLIENT_KEY).
+	EnvTLSClientCert = "TLS_CLIENT_CERT"
+
+	// EnvTLSClientKey is a PEM file path or Secrets Manager secret ARN of the client certificate's
+	// private key (optional, requires TLS_CLIENT_CERT).
+	EnvTLSClientKey = "TLS_CLIENT_KEY"
+
+	// EnvTrackerChargebackNoteType is the note type of the constituent note added when a tracked gift's donation is
+	// charged back (optional, unset adds no notes, requires TRACKER_CHARGEBACK_STATUS).
+	EnvTrackerChargebackNoteType = "TRACKER_CHARGEBACK_NOTE_TYPE"
+
+	// EnvTrackerChargebackStatus is the gift status set on tracked gifts whose donation is disputed or charged back
+	// (optional, unset leaves chargebacks alone).
+	EnvTrackerChargebackStatus = "TRACKER_CHARGEBACK_STATUS"
+
+	// EnvTrackerDeletedGiftCheckDays is how many days after a gift was tracked it is still checked for deletion
+	// (optional, default 30, 0 checks every tracked gift).
+	EnvTrackerDeletedGiftCheckDays = "TRACKER_DELETED_GIFT_CHECK_DAYS"
+
+	// EnvTrackerDeletedGiftPolicy is what to do when a tracked gift has been deleted in Raiser's Edge NXT:
+	// report, recreate or exclude (optional, unset trusts the tracker without checking).
+	EnvTrackerDeletedGiftPolicy = "TRACKER_DELETED_GIFT_POLICY"
+
+	// EnvTrackerPlanChangeNoteType is the note type of the constituent note added when a recurring plan's amount or
+	// frequency changes (optional, unset adds no notes).
+	EnvTrackerPlanChangeNoteType = "TRACKER_PLAN_CHANGE_NOTE_TYPE"
+
+	// EnvTrackerReconcileDays is how many days back a reconcile-only run looks for untracked donations
+	// (optional, default 7).
+	EnvTrackerReconcileDays = "TRACKER_RECONCILE_DAYS"
+
+	// EnvTrackerReconcileOnly limits scheduled runs to creating gifts for donations the webhook missed (optional).
+	EnvTrackerReconcileOnly = "TRACKER_RECONCILE_ONLY"
+
+	// EnvTrackerRetentionDays is how many days after a donation its tracker record expires (optional).
+	EnvTrackerRetentionDays = "TRACKER_RETENTION_DAYS"
+
+	// EnvTrackerSupporterCacheDays is how many days the constituent a supporter's email matched is remembered
+	// in the tracker table, so repeat donors are not searched for again (optional, 0 disables).
+	EnvTrackerSupporterCacheDays = "TRACKER_SUPPORTER_CACHE_DAYS"
+
+	// EnvTrackerTableName is the DynamoDB table recording synced donations (optional).
+	EnvTrackerTableName = "TRACKER_TABLE_NAME"
+
+	// EnvTrackerUpdateComments applies comments donors edit in FundraiseUp to their tracked gifts (optional).
+	EnvTrackerUpdateComments = "TRACKER_UPDATE_COMMENTS"
+
+	// EnvUserAgentOrganization identifies the organization running giftbridge in the User-Agent header sent to
+	// FundraiseUp and Blackbaud, such as st-marys-hospice (optional).
+	EnvUserAgentOrganization = "USER_AGENT_ORGANIZATION"
+
+	// EnvVaultAddr is the address of the HashiCorp Vault server keeping the Blackbaud refresh token, in place of
+	// BLACKBAUD_REFRESH_TOKEN_SECRET_ARN (optional).
+	EnvVaultAddr = "VAULT_ADDR"
+
+	// EnvVaultKVMount is the path Vault's KV version 2 secrets engine is mounted at (optional, default: secret).
+	EnvVaultKVMount = "VAULT_KV_MOUNT"
+
+	// EnvVaultNamespace is the Vault Enterprise namespace of the secret (optional).
+	EnvVaultNamespace = "VAULT_NAMESPACE"
+
+	// EnvVaultSecretPath is the path of the Vault secret holding the refresh token, within the KV mount
+	// (required with VAULT_ADDR).
+	EnvVaultSecretPath = "VAULT_SECRET_PATH"
+
+	// EnvVaultToken is the Vault token used to read and write the refresh token (required with VAULT_ADDR).
+	EnvVaultToken = "VAULT_TOKEN"
+)
+
+const (
+	// DeletedGiftPolicyExclude stops syncing donations whose gift was deleted, honouring the deletion.
+	DeletedGiftPolicyExclude = "exclude"
+
+	// DeletedGiftPolicyRecreate creates the gift again.
+	DeletedGiftPolicyRecreate = "recreate"
+
+	// DeletedGiftPolicyReport skips the donation and reports the deleted gift as a warning on every run.
+	DeletedGiftPolicyReport = "report"
+)
+
+const (
+	// GiftDatePolicyFlag creates gifts with an implausible date, adding a warning to the run.
+	GiftDatePolicyFlag = "flag"
+
+	// GiftDatePolicyRefuse fails donations whose gift would have an implausible date, without creating the gift.
+	GiftDatePolicyRefuse = "refuse"
+
+	// GiftPostDateDonation posts gifts on the date the donation was made.
+	GiftPostDateDonation = "donation"
+
+	// GiftPostDateSync posts gifts on the date they are synced.
+	GiftPostDateSync = "sync"
+
+	// GiftPostStatusDoNotPost keeps gifts out of the general ledger.
+	GiftPostStatusDoNotPost = "DoNotPost"
+
+	// GiftPostStatusNotPosted queues gifts to be posted to the general ledger.
+	GiftPostStatusNotPosted = "NotPosted"
+
+	// GiftRecurringModeFlat records every recurring payment as a plain gift of the default type, skipping the
+	// lookup of, and link to, the first gift in its series.
+	GiftRecurringModeFlat = "flat"
+
+	// GiftRecurringModeLinked records the first payment of a recurring donation as a RecurringGift, and each later
+	// payment as a RecurringGiftPayment linked to it.
+	GiftRecurringModeLinked = "linked"
+
+	// GiftReferenceFieldLookupID stores the FundraiseUp donation ID in the gift's lookup ID.
+	GiftReferenceFieldLookupID = "lookup_id"
+
+	// GiftReferenceFieldOrigin stores the FundraiseUp donation ID in the gift's origin, leaving the lookup ID
+	// free for Raiser's Edge NXT to assign.
+	GiftReferenceFieldOrigin = "origin"
+)
+
+const (
+	// StorageProviderAWS keeps the sync state in SSM Parameter Store and the refresh token in Secrets Manager.
+	StorageProviderAWS = "aws"
+
+	// StorageProviderAzure keeps the sync state in Azure Table Storage and the refresh token in Azure Key Vault.
+	StorageProviderAzure = "azure"
+
+	// StorageProviderGCP keeps the sync state in Firestore and the refresh token in Google Secret Manager.
+	StorageProviderGCP = "gcp"
+)
+
+const (
+	// TestDonationsSkip leaves donations made in FundraiseUp's test mode out of Raiser's Edge NXT.
+	TestDonationsSkip = "skip"
+
+	// TestDonationsSync creates gifts for test-mode donations like any other, or in the test fund when one is set.
+	TestDonationsSync = "sync"
 )
 
+const (
+	// DefaultDeletedGiftCheckDays is how many days after a gift was tracked it is checked for deletion by default.
+	DefaultDeletedGiftCheckDays = 30
+
+	// DefaultAzureTableName is the table keeping the sync state with STORAGE_PROVIDER=azure by default.
+	DefaultAzureTableName = "giftbridge"
+
+	// DefaultFirestoreDocument is the Firestore document keeping the sync state with STORAGE_PROVIDER=gcp by default.
+	DefaultFirestoreDocument = "giftbridge/sync-state"
+
+	// DefaultFundraiseUpPageSize is the number of donations fetched per FundraiseUp API request by default.
+	DefaultFundraiseUpPageSize = 100
+
+	// DefaultMaxDonationBytes is the largest a donation's JSON encoding may be by default, far above any donation
+	// made through a FundraiseUp form.
+	DefaultMaxDonationBytes = 64 << 10
+
+	// DefaultMaxResponseBytes is the largest API response read by default, many times a full page of donations yet
+	// small enough for the smallest Lambda memory size.
+	DefaultMaxResponseBytes = 16 << 20
+
+	// DefaultReconcileDays is how many days back a reconcile-only run looks for untracked donations by default.
+	DefaultReconcileDays = 7
+
+	// MaxFundraiseUpPageSize is the largest page size the FundraiseUp API accepts.
+	MaxFundraiseUpPageSize = 100
+)
+
+const (
+	// exampleRoleARN shows the expected form of an IAM role ARN in validation errors.
+	exampleRoleARN = "arn:aws:iam::123456789012:role/giftbridge-resources"
+
+	// exampleSecretARN shows the expected form of a Secrets Manager secret ARN in validation errors.
+	exampleSecretARN = "arn:aws:secretsmanager:eu-west-2:123456789012:secret:giftbridge/blackbaud-token"
+
+	// maxOrganizationLength caps the organization identifier sent in the User-Agent header.
+	maxOrganizationLength = 64
+)
+
+// azureTableNamePattern matches the names Azure Table Storage allows for tables.
+var azureTableNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{2,62}$`)
+
+// knownGiftTypes are Raiser's Edge NXT gift types, spelled as the SKY API expects them.
+// Other gift types are allowed, as organisations may use types not listed here.
+var knownGiftTypes = []string{
+	"Donation",
+	"GiftInKind",
+	"Other",
+	"Pledge",
+	"RecurringGift",
+	"RecurringGiftPayment",
+	"Stock",
+}
+
+// AWS holds AWS client configuration. All fields are optional and default to the AWS SDK behaviour.
+type AWS struct {
+	// DynamoDBEndpoint overrides the DynamoDB endpoint.
+	DynamoDBEndpoint string
+
+	// Endpoint overrides the endpoint for all AWS services without a service-specific override.
+	Endpoint string
+
+	// Region overrides the region of the state, secret, and tracker resources.
+	Region string
+
+	// RoleARN is an IAM role to assume for all AWS clients, so resources can live in another account.
+	RoleARN string
+
+	// RoleExternalID is the external ID passed when assuming RoleARN.
+	RoleExternalID string
+
+	// S3Endpoint overrides the S3 endpoint.
+	S3Endpoint string
+
+	// SecretsManagerEndpoint overrides the Secrets Manager endpoint.
+	SecretsManagerEndpoint string
+
+	// SSMEndpoint overrides the SSM endpoint.
+	SSMEndpoint string
+
+	// STSEndpoint overrides the STS endpoint.
+	STSEndpoint string
+}
+
+// AzureStorage holds the Azure resources keeping the sync state and refresh token with STORAGE_PROVIDER=azure.
+// Requests are authorized with the managed identity of the Azure Functions app.
+type AzureStorage struct {
+	// ClientID is the client ID of a user-assigned managed identity, or empty for the system-assigned one.
+	ClientID string
+
+	// KeyVaultURL is the URL of the Key Vault keeping the refresh token.
+	KeyVaultURL string
+
+	// RefreshTokenSecret is the name of the Key Vault secret storing the refresh token.
+	RefreshTokenSecret string
+
+	// TableEndpoint is the Table Storage endpoint of the storage account keeping the sync state.
+	TableEndpoint string
+
+	// TableName is the table keeping the sync state.
+	TableName string
+}
+
 // Blackbaud holds Blackbaud SKY API configuration.
 type Blackbaud struct {
 	// APIBaseURL is the base URL for API requests.
@@ -66,9 +544,21 @@ type Blackbaud struct {
 	// EnvironmentID is the Blackbaud environment identifier.
 	EnvironmentID string
 
+	// HedgeDelay is how long a read waits for a response before an identical request is sent.
+	// Each identical request uses an extra call from the quota. Zero disables hedging.
+	HedgeDelay time.Duration
+
+	// QuotaReserve is the remaining call quota below which processing pauses until the next run.
+	// Zero disables the limit.
+	QuotaReserve int
+
 	// RefreshTokenSecretARN is the Secrets Manager ARN storing the OAuth refresh token.
 	RefreshTokenSecretARN string
 
+	// SecondarySubscriptionKey is the SKY API subscription key used once the SKY API rejects SubscriptionKey
+	// (optional).
+	SecondarySubscriptionKey string
+
 	// SubscriptionKey is the SKY API subscription key.
 	SubscriptionKey string
 
@@ -76,13 +566,96 @@ type Blackbaud struct {
 	TokenURL string
 }
 
+// CommentScrubbing controls what is removed from donor comments before they are stored on gifts,
+// for organisations whose data-minimization policies keep personal data out of gift references.
+type CommentScrubbing struct {
+	// CardNumbers removes payment card numbers, recognised by their length and check digit.
+	CardNumbers bool
+
+	// Patterns are regular expressions whose matches are removed, such as postal addresses.
+	Patterns []string
+
+	// Words are removed wherever they appear as whole words, ignoring case, such as a profanity list.
+	Words []string
+}
+
+// ConstituentDefaults holds default values applied to constituents created in Raiser's Edge.
+type ConstituentDefaults struct {
+	// AddresseeFormat is a text/template format for each new constituent's primary addressee, over the fields
+	// FirstName and LastName (optional). Raiser's Edge NXT's default format is used when empty.
+	AddresseeFormat string
+
+	// Codes are the constituent codes (e.g., "Online Donor") added to each new constituent (optional).
+	Codes []string
+
+	// DonationNoteFormat is a text/template format for the note added to the constituent of each new gift,
+	// over the fields of giftbridge.DonationNoteFields (optional). A summary of the donation is used when empty.
+	DonationNoteFormat string
+
+	// DonationNoteType is the Raiser's Edge NXT note type of the note added to the constituent of each new gift,
+	// describing the online donation in ways the gift cannot hold (optional). No notes are added when empty.
+	DonationNoteType string
+
+	// Events link FundraiseUp events to Raiser's Edge NXT events, so constituents whose donation bought a ticket
+	// are added to the event as participants (optional).
+	Events []EventLink
+
+	// SalutationFormat is a text/template format for each new constituent's primary salutation, over the fields
+	// FirstName and LastName (optional). Raiser's Edge NXT's default format is used when empty.
+	SalutationFormat string
+}
+
+// EmailNormalization controls how email addresses are compared and searched when matching constituents.
+// Addresses are always trimmed and lowercased.
+type EmailNormalization struct {
+	// AddMissing adds the supporter's email to a matched constituent with no email on file,
+	// so later donations match the constituent by email.
+	AddMissing bool
+
+	// FoldGmail removes dots and plus tags from Gmail addresses and treats googlemail.com as gmail.com.
+	FoldGmail bool
+
+	// IncludeInactive also matches inactive constituents.
+	IncludeInactive bool
+
+	// StrictSearch only matches the exact address in a constituent's email field,
+	// instead of the Blackbaud search that also matches names and partial text.
+	StrictSearch bool
+
+	// StripPlusTags removes plus tags (name+tag@example.com) from addresses on any domain.
+	StripPlusTags bool
+}
+
 // FundraiseUp holds FundraiseUp API configuration.
 type FundraiseUp struct {
+	// Accounts are further FundraiseUp accounts, such as other entities of the same charity, whose donations are
+	// synced in the same run and tagged with the account name in the gift origin (optional).
+	Accounts []FundraiseUpAccount
+
 	// APIKey is the API key for authentication.
 	APIKey string
 
 	// BaseURL is the base URL for API requests.
 	BaseURL string
+
+	// CampaignID restricts fetched donations to a single FundraiseUp campaign (optional).
+	CampaignID string
+
+	// CampaignWindows limit the donations of the listed FundraiseUp campaigns synced to those made within a window,
+	// or exclude a campaign's donations altogether (optional).
+	CampaignWindows []CampaignWindow
+
+	// FetchOverlap is how far before the last sync time each run starts fetching donations (zero disables).
+	FetchOverlap time.Duration
+
+	// PageSize is the number of donations fetched per request.
+	PageSize int
+
+	// Status restricts fetched donations to those with the given status (optional).
+	Status string
+
+	// StrictDecode logs payload fields the mapper does not decode, once per run.
+	StrictDecode bool
 }
 
 // GiftDefaults holds default values applied to all gifts in Raiser's Edge.
@@ -90,109 +663,1368 @@ type GiftDefaults struct {
 	// AppealID is the Raiser's Edge Appeal to attribute gifts to (optional).
 	AppealID string
 
+	// AppealResponses records each constituent's response to the appeals of their gifts, however the appeal was
+	// chosen, so appeal performance in Raiser's Edge NXT includes online gifts.
+	AppealResponses bool
+
 	// CampaignID is the Raiser's Edge Campaign to attribute gifts to (optional).
 	CampaignID string
 
+	// Checks are assertions each gift must pass once mapped, or its donation fails without a gift being created
+	// (optional).
+	Checks []GiftCheck
+
+	// CountryRoutes send gifts from supporters in the listed countries to their own fund, campaign or appeal,
+	// in place of the defaults and before the rules (optional).
+	CountryRoutes []CountryRoute
+
+	// CreatorCustomField is the Raiser's Edge NXT gift custom field category, of the Text data type, added to each new
+	// gift to record that GiftBridge created it and the run it was created in (optional). Raiser's Edge NXT shows the
+	// user who authorised GiftBridge as the gift's "Added by", so this tells integration gifts from manual entry.
+	CreatorCustomField string
+
+	// DatePolicy is what to do with a gift dated in the future or more than MaxAgeDays ago:
+	// GiftDatePolicyFlag or GiftDatePolicyRefuse. When empty, gift dates are not checked.
+	DatePolicy string
+
 	// FundID is the Raiser's Edge Fund where gifts are recorded (required).
 	FundID string
 
+	// MaxAgeDays is how many days old a gift's date may be before DatePolicy applies to it (optional, requires
+	// DatePolicy). When zero, only future dates are checked.
+	MaxAgeDays int
+
+	// PostDate chooses the post date of NotPosted gifts: GiftPostDateDonation (default) or GiftPostDateSync.
+	PostDate string
+
+	// PostStatus is the posting status of new gifts: GiftPostStatusNotPosted or GiftPostStatusDoNotPost.
+	// When empty, the status is left to the Raiser's Edge NXT default.
+	PostStatus string
+
+	// RecurringMode is how recurring donations are recorded: GiftRecurringModeLinked (default) or
+	// GiftRecurringModeFlat.
+	RecurringMode string
+
+	// ReferenceField is the gift field storing the FundraiseUp donation ID:
+	// GiftReferenceFieldLookupID (default) or GiftReferenceFieldOrigin.
+	ReferenceField string
+
+	// ReferenceNoteType is the Raiser's Edge NXT gift note type of the note added to a gift whose reference had to
+	// be truncated, holding the donor's full comment (optional). When empty, the rest of the comment is dropped.
+	ReferenceNoteType string
+
+	// Rules compute gift fields from each donation, and are applied in order after the defaults (optional).
+	Rules []GiftRule
+
+	// Splits send an amount or percentage of each gift to other funds, in order, with the remainder going to FundID
+	// (optional).
+	Splits []GiftSplit
+
+	// TestDonations is what to do with donations made in FundraiseUp's test mode:
+	// TestDonationsSkip (default) or TestDonationsSync.
+	TestDonations string
+
+	// TestFundID is the Raiser's Edge Fund synced test-mode donations are recorded in, in place of the fund chosen by
+	// the defaults, routes, rules and splits, so test gifts are kept apart (optional).
+	TestFundID string
+
 	// Type is the type of gift in Raiser's Edge (default: Donation).
 	Type string
 }
 
-// SSM holds AWS Systems Manager Parameter Store configuration.
-type SSM struct {
-	// ParameterName is the SSM parameter storing the last sync timestamp.
-	ParameterName string
+// CampaignWindow limits the donations of one FundraiseUp campaign synced to those made within a window, such as
+// after the campaign's launch, or excludes the campaign altogether, such as a legacy campaign, as described in
+// docs/field-mapping.md. Donations of campaigns without a window are all synced.
+type CampaignWindow struct {
+	// CampaignID is the FundraiseUp campaign, such as "FUNXXXXXXXX".
+	CampaignID string `json:"campaignId"`
+
+	// Exclude leaves every donation of the campaign unsynced. Cannot be combined with From or Until.
+	Exclude bool `json:"exclude,omitempty"`
+
+	// From is when the campaign's donations start being synced, by when they were made (optional).
+	From time.Time `json:"from,omitzero"`
+
+	// Until is when the campaign's donations stop being synced, by when they were made (optional, exclusive).
+	Until time.Time `json:"until,omitzero"`
 }
 
-// Settings holds all configuration for the application.
-type Settings struct {
-	// Blackbaud contains Blackbaud SKY API settings.
-	Blackbaud Blackbaud
+// CountryRoute sends gifts from supporters in the listed countries to their own fund, campaign or appeal,
+// such as a Gift Aid fund for UK supporters, as described in docs/field-mapping.md.
+// Fields left empty keep the gift defaults.
+type CountryRoute struct {
+	// AppealID is the Raiser's Edge Appeal to attribute the gifts to (optional).
+	AppealID string `json:"appealId,omitempty"`
 
-	// FundraiseUp contains FundraiseUp API settings.
-	FundraiseUp FundraiseUp
+	// CampaignID is the Raiser's Edge Campaign to attribute the gifts to (optional).
+	CampaignID string `json:"campaignId,omitempty"`
 
-	// GiftDefaults contains default values for gifts in Raiser's Edge.
-	GiftDefaults GiftDefaults
+	// Countries lists the supporter countries routed, as ISO 3166-1 codes or names, such as "GB" or "United Kingdom".
+	Countries []string `json:"countries"`
 
-	// SSM contains AWS Systems Manager Parameter Store settings.
-	SSM SSM
+	// FundID is the Raiser's Edge Fund where the gifts are recorded (optional).
+	FundID string `json:"fundId,omitempty"`
 }
 
-func (s *Settings) validate() error {
-	var errs []error
+// EventLink links a FundraiseUp event to the Raiser's Edge NXT event its ticket buyers are registered for.
+type EventLink struct {
+	// EventID is the Raiser's Edge NXT event the ticket buyers are added to as participants.
+	EventID string `json:"eventId"`
 
-	if s.Blackbaud.ClientID == "" {
-		errs = append(errs, requiredError(EnvBlackbaudClientID))
-	}
-	if s.Blackbaud.ClientSecret == "" {
-		errs = append(errs, requiredError(EnvBlackbaudClientSecret))
-	}
-	if s.Blackbaud.EnvironmentID == "" {
-		errs = append(errs, requiredError(EnvBlackbaudEnvironmentID))
-	}
-	if s.Blackbaud.RefreshTokenSecretARN == "" {
-		errs = append(errs, requiredError(EnvBlackbaudRefreshTokenSecretARN))
-	}
-	if s.Blackbaud.SubscriptionKey == "" {
-		errs = append(errs, requiredError(EnvBlackbaudSubscriptionKey))
-	}
-	if s.FundraiseUp.APIKey == "" {
-		errs = append(errs, requiredError(EnvFundraiseUpAPIKey))
-	}
-	if s.GiftDefaults.FundID == "" {
-		errs = append(errs, requiredError(EnvGiftFundID))
-	}
-	if s.SSM.ParameterName == "" {
-		errs = append(errs, requiredError(EnvSSMParameterName))
-	}
+	// FundraiseUpEventID is the FundraiseUp event whose ticket purchases are linked.
+	FundraiseUpEventID string `json:"fundraiseUpEventId"`
+}
 
-	return errors.Join(errs...)
+// FundraiseUpAccount is a further FundraiseUp account synced alongside the main one.
+type FundraiseUpAccount struct {
+	// APIKey is the account's API key.
+	APIKey string `json:"apiKey"`
+
+	// Name identifies the account in gift origins, such as "trading".
+	Name string `json:"name"`
 }
 
-// Load reads configuration from environment variables.
-func Load() (*Settings, error) {
-	cfg := &Settings{
-		Blackbaud: Blackbaud{
-			APIBaseURL:            envOrDefault(EnvBlackbaudAPIBaseURL, "https://api.sky.blackbaud.com"),
-			ClientID:              strings.TrimSpace(os.Getenv(EnvBlackbaudClientID)),
-			ClientSecret:          strings.TrimSpace(os.Getenv(EnvBlackbaudClientSecret)),
-			EnvironmentID:         strings.TrimSpace(os.Getenv(EnvBlackbaudEnvironmentID)),
-			RefreshTokenSecretARN: strings.TrimSpace(os.Getenv(EnvBlackbaudRefreshTokenSecretARN)),
-			SubscriptionKey:       strings.TrimSpace(os.Getenv(EnvBlackbaudSubscriptionKey)),
-			TokenURL:              envOrDefault(EnvBlackbaudTokenURL, "https://oauth2.sky.blackbaud.com/token"),
-		},
-		FundraiseUp: FundraiseUp{
-			APIKey:  strings.TrimSpace(os.Getenv(EnvFundraiseUpAPIKey)),
-			BaseURL: envOrDefault(EnvFundraiseUpBaseURL, "https://api.fundraiseup.com/v1"),
-		},
-		GiftDefaults: GiftDefaults{
-			AppealID:   strings.TrimSpace(os.Getenv(EnvGiftAppealID)),
-			CampaignID: strings.TrimSpace(os.Getenv(EnvGiftCampaignID)),
-			FundID:     strings.TrimSpace(os.Getenv(EnvGiftFundID)),
-			Type:       envOrDefault(EnvGiftType, "Donation"),
-		},
-		SSM: SSM{
-			ParameterName: strings.TrimSpace(os.Getenv(EnvSSMParameterName)),
-		},
-	}
+// GiftCheck is an assertion a gift must pass before it is created, as described in docs/field-mapping.md.
+type GiftCheck struct {
+	// Assert is the expression that must be true for the gift to be created.
+	Assert string `json:"assert"`
 
-	if err := cfg.validate(); err != nil {
-		return nil, err
-	}
+	// Message describes a gift that fails the check (optional, defaults to the expression).
+	Message string `json:"message,omitempty"`
+}
 
-	return cfg, nil
+// GiftRule sets a gift field from an expression, as described in docs/field-mapping.md.
+type GiftRule struct {
+	// Field is the gift field to set, such as "fund_id" or "reference".
+	Field string `json:"field"`
+
+	// Value is the expression computing the field's value.
+	Value string `json:"value"`
+
+	// When is an expression deciding whether the rule applies (optional).
+	When string `json:"when,omitempty"`
 }
 
-func envOrDefault(key string, defaultValue string) string {
-	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+// GiftSplit sends an amount or percentage of each gift to a fund, as described in docs/field-mapping.md.
+// Exactly one of Amount and Percent is set.
+type GiftSplit struct {
+	// Amount is a fixed amount of the gift, in the gift's currency, the fund receives from what earlier splits leave.
+	Amount float64 `json:"amount,omitempty"`
+
+	// FundID is the Raiser's Edge Fund receiving the split.
+	FundID string `json:"fundId"`
+
+	// Percent is the percentage of the gift amount the fund receives, rounded down to the currency's minor unit,
+	// up to what earlier splits leave.
+	Percent float64 `json:"percent,omitempty"`
+}
+
+// NameNormalization controls how supporter names are cleaned up when creating constituents.
+// Whitespace is always trimmed.
+type NameNormalization struct {
+	// TitleCase capitalizes names supplied entirely in lower or upper case, keeping particles such as "van" lowercase.
+	TitleCase bool
+
+	// Transliterate replaces accented letters with their closest ASCII equivalents.
+	Transliterate bool
+}
+
+// Proxy holds the HTTP proxy for API requests. When URL is empty, the HTTPS_PROXY and NO_PROXY
+// environment variables apply.
+type Proxy struct {
+	// Bypass lists the hosts whose requests go direct, including their subdomains. "*" bypasses the proxy.
+	Bypass []string
+
+	// URL is the proxy's http or https URL.
+	URL string
+}
+
+// ResourceNames holds the configured names of the AWS resources a deployment uses. Unset names are empty.
+type ResourceNames struct {
+	// LastSyncParameterName is the SSM parameter storing the last sync timestamp.
+	LastSyncParameterName string
+
+	// RefreshTokenSecretARN is the Secrets Manager ARN storing the OAuth refresh token.
+	RefreshTokenSecretARN string
+
+	// TrackerTableName is the DynamoDB table recording synced donations.
+	TrackerTableName string
+}
+
+// SSM holds AWS Systems Manager Parameter Store configuration.
+type SSM struct {
+	// ParameterName is the SSM parameter storing the last sync timestamp.
+	ParameterName string
+}
+
+// TLS holds the certificates used for API connections. Each value is a PEM file path or,
+// when it starts with "arn:", a Secrets Manager secret ARN.
+type TLS struct {
+	// CABundle holds certificate authorities trusted in addition to the system roots.
+	CABundle string
+
+	// ClientCert is the client certificate chain presented to servers that require mutual TLS.
+	ClientCert string
+
+	// ClientKey is the client certificate's private key.
+	ClientKey string
+}
+
+// Tracker holds DynamoDB donation tracker configuration.
+type Tracker struct {
+	// ChargebackNoteType is the Raiser's Edge NXT note type of the note added to a constituent when the donation of
+	// one of their tracked gifts is charged back. No notes are added when empty.
+	ChargebackNoteType string
+
+	// ChargebackStatus is the gift status set on tracked gifts whose donation is disputed or charged back,
+	// read from the FundraiseUp events since the previous sync. Chargebacks are not applied when empty.
+	ChargebackStatus string
+
+	// DeletedGiftCheckDays is how many days after a gift was tracked it is still checked for deletion,
+	// so long-tracked donations seen again do not each cost a Raiser's Edge NXT call. Zero checks every gift.
+	DeletedGiftCheckDays int
+
+	// DeletedGiftPolicy is what to do when a tracked gift has been deleted in Raiser's Edge NXT:
+	// DeletedGiftPolicyReport, DeletedGiftPolicyRecreate or DeletedGiftPolicyExclude.
+	// When empty, tracked donations are skipped without checking their gift still exists.
+	DeletedGiftPolicy string
+
+	// PlanChangeNoteType is the Raiser's Edge NXT note type of the note added to a constituent when their recurring
+	// plan's amount or frequency changes, compared with the plan's previous tracked installment.
+	// No notes are added when empty.
+	PlanChangeNoteType string
+
+	// ReconcileDays is how many days back a reconcile-only run looks for donations with no tracker record.
+	ReconcileDays int
+
+	// ReconcileOnly limits scheduled runs to donations the webhook has not tracked, for deployments where the
+	// webhook creates gifts as donations arrive. Reconcile-only runs keep no pending donations or last sync time.
+	ReconcileOnly bool
+
+	// RetentionDays is how many days after a donation was made its record expires from the table.
+	// Records are kept forever when zero.
+	RetentionDays int
+
+	// SupporterCacheDays is how many days the constituent a supporter's email matched is remembered in the table,
+	// so repeat donors are not searched for in Raiser's Edge NXT again. The cache is disabled when zero.
+	SupporterCacheDays int
+
+	// TableName is the DynamoDB table recording synced donations.
+	// Donation tracking is disabled when empty.
+	TableName string
+
+	// UpdateComments applies comments donors edit in FundraiseUp after their gift was created to the
+	// gift's reference, read from the FundraiseUp events since the previous sync.
+	UpdateComments bool
+}
+
+// UserAgent holds how giftbridge identifies itself to the FundraiseUp and Blackbaud APIs.
+type UserAgent struct {
+	// Organization identifies the organization running giftbridge, sent after the giftbridge version in the
+	// User-Agent header, such as "giftbridge/v1.4.0 (st-marys-hospice)". Only the version is sent when empty.
+	Organization string
+}
+
+// GCPStorage holds the Google Cloud resources keeping the sync state and refresh token with STORAGE_PROVIDER=gcp.
+// Requests are authorized as the service account the Cloud Function runs as.
+type GCPStorage struct {
+	// FirestoreDocument is the path of the Firestore document keeping the sync state, as collection/document.
+	FirestoreDocument string
+
+	// Project is the Google Cloud project of the secret and Firestore database.
+	Project string
+
+	// RefreshTokenSecret is the ID of the Secret Manager secret storing the refresh token.
+	RefreshTokenSecret string
+}
+
+// Storage selects the cloud keeping the sync state and the Blackbaud refresh token, so giftbridge can run on
+// Google Cloud Functions or Azure Functions as well as AWS Lambda.
+type Storage struct {
+	// Azure holds the Azure resources used when Provider is azure.
+	Azure AzureStorage
+
+	// GCP holds the Google Cloud resources used when Provider is gcp.
+	GCP GCPStorage
+
+	// Provider is the cloud keeping the state and token: aws, gcp or azure. Empty means aws.
+	Provider string
+}
+
+// UsesAWS reports whether the sync state and refresh token are kept in AWS.
+func (s Storage) UsesAWS() bool {
+	return s.Provider == "" || s.Provider == StorageProviderAWS
+}
+
+// Sync holds how a sync run proceeds.
+type Sync struct {
+	// FailFast stops a run at the first donation that fails rather than carrying on with the rest, for testing a
+	// new configuration. The failed donation and those after it are left for the next run.
+	FailFast bool
+
+	// MaxDonationBytes is the largest a donation may be, in bytes of its JSON encoding, so an unexpectedly huge
+	// donation cannot exhaust the Lambda's memory.
+	MaxDonationBytes int
+
+	// MaxResponseBytes is the largest FundraiseUp or Blackbaud API response read, in bytes.
+	MaxResponseBytes int
+}
+
+// Vault holds the HashiCorp Vault secret keeping the Blackbaud refresh token, for organisations that keep their
+// secrets in Vault rather than AWS Secrets Manager. The token is kept in Secrets Manager when Address is empty.
+type Vault struct {
+	// Address is the Vault server's URL, such as https://vault.example.org:8200.
+	Address string
+
+	// KVMount is the path the KV version 2 secrets engine holding the secret is mounted at (default: secret).
+	KVMount string
+
+	// Namespace is the Vault Enterprise namespace of the secret (optional).
+	Namespace string
+
+	// SecretPath is the path of the secret holding the refresh token, within KVMount.
+	SecretPath string
+
+	// Token is the Vault token used to read and write the secret.
+	Token string
+}
+
+// Settings holds all configuration for the application.
+type Settings struct {
+	// AWS contains AWS client settings.
+	AWS AWS
+
+	// Blackbaud contains Blackbaud SKY API settings.
+	Blackbaud Blackbaud
+
+	// CommentScrubbing contains settings for removing personal data from donor comments.
+	CommentScrubbing CommentScrubbing
+
+	// ConstituentDefaults contains default values for new constituents in Raiser's Edge.
+	ConstituentDefaults ConstituentDefaults
+
+	// EmailNormalization contains settings for matching constituents by email.
+	EmailNormalization EmailNormalization
+
+	// FundraiseUp contains FundraiseUp API settings.
+	FundraiseUp FundraiseUp
+
+	// GiftDefaults contains default values for gifts in Raiser's Edge.
+	GiftDefaults GiftDefaults
+
+	// NameNormalization contains settings for names of new constituents.
+	NameNormalization NameNormalization
+
+	// Proxy contains the HTTP proxy for API requests.
+	Proxy Proxy
+
+	// SSM contains AWS Systems Manager Parameter Store settings.
+	SSM SSM
+
+	// Storage contains the cloud keeping the sync state and refresh token.
+	Storage Storage
+
+	// Sync contains settings for how a sync run proceeds.
+	Sync Sync
+
+	// TLS contains the certificates used for API connections.
+	TLS TLS
+
+	// Tracker contains DynamoDB donation tracker settings.
+	Tracker Tracker
+
+	// UserAgent contains how giftbridge identifies itself to the APIs.
+	UserAgent UserAgent
+
+	// Vault contains the HashiCorp Vault secret keeping the refresh token, when it is not in Secrets Manager.
+	Vault Vault
+}
+
+func (a *AWS) validate() error {
+	var errs []error
+
+	endpoints := []struct {
+		envVar string
+		value  string
+	}{
+		{EnvAWSEndpointURL, a.Endpoint},
+		{EnvAWSEndpointURLDynamoDB, a.DynamoDBEndpoint},
+		{EnvAWSEndpointURLS3, a.S3Endpoint},
+		{EnvAWSEndpointURLSecretsManager, a.SecretsManagerEndpoint},
+		{EnvAWSEndpointURLSSM, a.SSMEndpoint},
+		{EnvAWSEndpointURLSTS, a.STSEndpoint},
+	}
+	for _, e := range endpoints {
+		if e.value == "" {
+			continue
+		}
+		u, err := url.Parse(e.value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s must be an absolute http or https URL", e.envVar))
+		}
+	}
+
+	if a.RoleARN != "" && (!strings.HasPrefix(a.RoleARN, "arn:") || !strings.Contains(a.RoleARN, ":role/")) {
+		errs = append(errs, fmt.Errorf("%s must be an IAM role ARN, such as %s", EnvAWSResourceRoleARN, exampleRoleARN))
+	}
+	if a.RoleExternalID != "" && a.RoleARN == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvAWSResourceRoleExternalID, EnvAWSResourceRoleARN))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *FundraiseUp) validate() error {
+	var errs []error
+	if f.PageSize < 1 || f.PageSize > MaxFundraiseUpPageSize {
+		errs = append(errs, fmt.Errorf("%s must be between 1 and %d", EnvFundraiseUpPageSize, MaxFundraiseUpPageSize))
+	}
+	if err := validateFundraiseUpAccounts(f.Accounts, EnvFundraiseUpAccounts); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateCampaignWindows(f.CampaignWindows, EnvFundraiseUpCampaignWindows); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (g *GiftDefaults) validate() error {
+	return errors.Join(
+		validatePosting(g.PostStatus, g.PostDate, EnvGiftPostStatus, EnvGiftPostDate),
+		validateRecurringMode(g.RecurringMode, EnvGiftRecurringMode),
+		validateReferenceField(g.ReferenceField, EnvGiftReferenceField),
+		validateGiftType(g.Type, EnvGiftType),
+		validateGiftRules(g.Rules, EnvGiftRules),
+		validateGiftChecks(g.Checks, EnvGiftChecks),
+		validateGiftSplits(g.Splits, EnvGiftSplits),
+		validateCountryRoutes(g.CountryRoutes, EnvGiftCountryRoutes),
+		validateGiftDatePolicy(g.DatePolicy, g.MaxAgeDays, EnvGiftDatePolicy, EnvGiftMaxAgeDays),
+		validateTestDonations(g.TestDonations, g.TestFundID, EnvGiftTestDonations, EnvGiftTestFundID),
+	)
+}
+
+func (t *Tracker) validate() error {
+	var errs []error
+
+	if t.ChargebackNoteType != "" && t.ChargebackStatus == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerChargebackNoteType, EnvTrackerChargebackStatus))
+	}
+	if t.ChargebackStatus != "" && t.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerChargebackStatus, EnvTrackerTableName))
+	}
+	switch t.DeletedGiftPolicy {
+	case "", DeletedGiftPolicyExclude, DeletedGiftPolicyRecreate, DeletedGiftPolicyReport:
+	default:
+		errs = append(errs, fmt.Errorf("%s must be %s, %s or %s", EnvTrackerDeletedGiftPolicy,
+			DeletedGiftPolicyReport, DeletedGiftPolicyRecreate, DeletedGiftPolicyExclude))
+	}
+	if t.DeletedGiftCheckDays < 0 {
+		errs = append(errs, fmt.Errorf("%s must be a non-negative integer", EnvTrackerDeletedGiftCheckDays))
+	}
+	if t.DeletedGiftPolicy != "" && t.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerDeletedGiftPolicy, EnvTrackerTableName))
+	}
+	if t.PlanChangeNoteType != "" && t.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerPlanChangeNoteType, EnvTrackerTableName))
+	}
+	if t.ReconcileDays <= 0 {
+		errs = append(errs, fmt.Errorf("%s must be a positive integer", EnvTrackerReconcileDays))
+	}
+	if t.ReconcileOnly && t.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerReconcileOnly, EnvTrackerTableName))
+	}
+	if t.RetentionDays > 0 && t.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerRetentionDays, EnvTrackerTableName))
+	}
+	if t.SupporterCacheDays > 0 && t.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerSupporterCacheDays, EnvTrackerTableName))
+	}
+	if t.UpdateComments && t.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvTrackerUpdateComments, EnvTrackerTableName))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (v *Vault) validate() error {
+	var errs []error
+
+	u, err := url.Parse(v.Address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%s must be an absolute http or https URL", EnvVaultAddr))
+	}
+	if v.SecretPath == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvVaultAddr, EnvVaultSecretPath))
+	}
+	if v.Token == "" {
+		errs = append(errs, fmt.Errorf("%s requires %s", EnvVaultAddr, EnvVaultToken))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the selected provider's resources are set. The refresh token secret is not required when
+// tokenInVault is set, as the token is kept in Vault instead.
+func (s *Storage) validate(tokenInVault bool) error {
+	required := func(envVar string) error {
+		return fmt.Errorf("%s=%s requires %s", EnvStorageProvider, s.Provider, envVar)
+	}
+
+	var errs []error
+	switch s.Provider {
+	case "", StorageProviderAWS:
+	case StorageProviderAzure:
+		if s.Azure.KeyVaultURL == "" && !tokenInVault {
+			errs = append(errs, required(EnvAzureKeyVaultURL))
+		} else if s.Azure.KeyVaultURL != "" && !isHTTPSURL(s.Azure.KeyVaultURL) {
+			errs = append(errs, fmt.Errorf("%s must be an absolute https URL", EnvAzureKeyVaultURL))
+		}
+		if s.Azure.RefreshTokenSecret == "" && !tokenInVault {
+			errs = append(errs, required(EnvAzureRefreshTokenSecret))
+		}
+		if s.Azure.TableEndpoint == "" {
+			errs = append(errs, required(EnvAzureTableEndpoint))
+		} else if !isHTTPSURL(s.Azure.TableEndpoint) {
+			errs = append(errs, fmt.Errorf("%s must be an absolute https URL", EnvAzureTableEndpoint))
+		}
+		if !azureTableNamePattern.MatchString(s.Azure.TableName) {
+			errs = append(errs, fmt.Errorf("%s must be 3 to 63 letters and digits, starting with a letter",
+				EnvAzureTableName))
+		}
+	case StorageProviderGCP:
+		if s.GCP.Project == "" {
+			errs = append(errs, required(EnvGCPProject))
+		}
+		if s.GCP.RefreshTokenSecret == "" && !tokenInVault {
+			errs = append(errs, required(EnvGCPRefreshTokenSecret))
+		}
+		if collection, document, ok := strings.Cut(s.GCP.FirestoreDocument, "/"); !ok || collection == "" ||
+			document == "" || strings.Contains(document, "/") {
+			errs = append(errs, fmt.Errorf("%s must be a collection and document ID, such as %s",
+				EnvGCPFirestoreDocument, DefaultFirestoreDocument))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s must be %s, %s or %s",
+			EnvStorageProvider, StorageProviderAWS, StorageProviderGCP, StorageProviderAzure))
+	}
+
+	return errors.Join(errs...)
+}
+
+// isHTTPSURL reports whether value is an absolute https URL.
+func isHTTPSURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+func (s *Sync) validate() error {
+	var errs []error
+	if s.MaxDonationBytes <= 0 {
+		errs = append(errs, fmt.Errorf("%s must be a positive integer", EnvSyncMaxDonationBytes))
+	}
+	if s.MaxResponseBytes <= 0 {
+		errs = append(errs, fmt.Errorf("%s must be a positive integer", EnvSyncMaxResponseBytes))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Settings) validate() error {
+	var errs []error
+
+	if err := s.AWS.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if s.Blackbaud.ClientID == "" {
+		errs = append(errs, requiredError(EnvBlackbaudClientID))
+	}
+	if s.Blackbaud.ClientSecret == "" {
+		errs = append(errs, requiredError(EnvBlackbaudClientSecret))
+	}
+	if s.Blackbaud.EnvironmentID == "" {
+		errs = append(errs, requiredError(EnvBlackbaudEnvironmentID))
+	}
+	// Outside AWS, the refresh token secret is checked with the storage provider's other resources.
+	switch secret := s.Blackbaud.RefreshTokenSecretARN; {
+	case s.Vault.Address != "":
+		if err := s.Vault.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case !s.Storage.UsesAWS():
+	case secret == "":
+		errs = append(errs, requiredError(EnvBlackbaudRefreshTokenSecretARN))
+	case IsSecretARN(secret) && !isSecretsManagerARN(secret):
+		errs = append(errs, fmt.Errorf("%s must be a Secrets Manager secret name or ARN, such as %s",
+			EnvBlackbaudRefreshTokenSecretARN, exampleSecretARN))
+	}
+	if s.Blackbaud.SubscriptionKey == "" {
+		errs = append(errs, requiredError(EnvBlackbaudSubscriptionKey))
+	}
+	if err := validatePatterns(s.CommentScrubbing.Patterns, EnvCommentScrubPatterns); err != nil {
+		errs = append(errs, err)
+	}
+	if s.FundraiseUp.APIKey == "" {
+		errs = append(errs, requiredError(EnvFundraiseUpAPIKey))
+	}
+	if err := s.FundraiseUp.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateEventLinks(s.ConstituentDefaults.Events, EnvConstituentEvents); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateNameFormat(s.ConstituentDefaults.AddresseeFormat, EnvConstituentAddresseeFormat); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateNameFormat(s.ConstituentDefaults.SalutationFormat, EnvConstituentSalutationFormat); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateDonationNote(
+		s.ConstituentDefaults,
+		EnvConstituentDonationNoteFormat,
+		EnvConstituentDonationNoteType,
+	); err != nil {
+		errs = append(errs, err)
+	}
+	if s.GiftDefaults.FundID == "" {
+		errs = append(errs, requiredError(EnvGiftFundID))
+	}
+	if err := s.GiftDefaults.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateProxy(s.Proxy, EnvProxyURL, EnvProxyBypass); err != nil {
+		errs = append(errs, err)
+	}
+	if s.SSM.ParameterName == "" && s.Storage.UsesAWS() {
+		errs = append(errs, requiredError(EnvSSMParameterName))
+	}
+	if err := s.Storage.validate(s.Vault.Address != ""); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.Sync.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateTLS(s.TLS, EnvTLSCABundle, EnvTLSClientCert, EnvTLSClientKey); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.Tracker.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateOrganization(s.UserAgent.Organization, EnvUserAgentOrganization); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsSecretARN reports whether a certificate source names a secret rather than a file.
+func IsSecretARN(source string) bool {
+	return strings.HasPrefix(source, "arn:")
+}
+
+// isSecretsManagerARN reports whether arn is the ARN of a Secrets Manager secret.
+func isSecretsManagerARN(arn string) bool {
+	parts := strings.SplitN(arn, ":", 7)
+	return len(parts) == 7 && parts[2] == "secretsmanager" && parts[5] == "secret" && parts[6] != ""
+}
+
+// LoadAWS reads AWS client configuration from environment variables.
+func LoadAWS() (AWS, error) {
+	cfg := loadAWS()
+	if err := cfg.validate(); err != nil {
+		return AWS{}, err
+	}
+	return cfg, nil
+}
+
+// LoadProxy reads the HTTP proxy for API requests from environment variables.
+func LoadProxy() (Proxy, error) {
+	cfg := loadProxy()
+	if err := validateProxy(cfg, EnvProxyURL, EnvProxyBypass); err != nil {
+		return Proxy{}, err
+	}
+	return cfg, nil
+}
+
+// LoadTLS reads the certificates used for API connections from environment variables.
+func LoadTLS() (TLS, error) {
+	cfg := loadTLS()
+	if err := validateTLS(cfg, EnvTLSCABundle, EnvTLSClientCert, EnvTLSClientKey); err != nil {
+		return TLS{}, err
+	}
+	return cfg, nil
+}
+
+// LoadResourceNames reads the AWS resource names from environment variables, without requiring any of them.
+func LoadResourceNames() ResourceNames {
+	return ResourceNames{
+		LastSyncParameterName: strings.TrimSpace(os.Getenv(EnvSSMParameterName)),
+		RefreshTokenSecretARN: strings.TrimSpace(os.Getenv(EnvBlackbaudRefreshTokenSecretARN)),
+		TrackerTableName:      strings.TrimSpace(os.Getenv(EnvTrackerTableName)),
+	}
+}
+
+// Load reads configuration from environment variables.
+func Load() (*Settings, error) {
+	strictDecode, strictDecodeErr := envBool(EnvFundraiseUpStrictDecode)
+	hedgeDelay, hedgeDelayErr := envNonNegativeDuration(EnvBlackbaudHedgeDelay)
+	fetchOverlap, fetchOverlapErr := envNonNegativeDuration(EnvFundraiseUpFetchOverlap)
+	accounts, accountsErr := envFundraiseUpAccounts(EnvFundraiseUpAccounts)
+	campaignWindows, campaignWindowsErr := envCampaignWindows(EnvFundraiseUpCampaignWindows)
+	quotaReserve, quotaReserveErr := envNonNegativeInt(EnvBlackbaudQuotaReserve)
+	reconcileOnly, reconcileOnlyErr := envBool(EnvTrackerReconcileOnly)
+	pageSize, pageSizeErr := envIntOrDefault(EnvFundraiseUpPageSize, DefaultFundraiseUpPageSize)
+	retentionDays, retentionDaysErr := envNonNegativeInt(EnvTrackerRetentionDays)
+	supporterCacheDays, supporterCacheDaysErr := envNonNegativeInt(EnvTrackerSupporterCacheDays)
+	checkDays, checkDaysErr := envIntOrDefault(EnvTrackerDeletedGiftCheckDays, DefaultDeletedGiftCheckDays)
+	reconcileDays, reconcileDaysErr := envIntOrDefault(EnvTrackerReconcileDays, DefaultReconcileDays)
+	updateComments, updateCommentsErr := envBool(EnvTrackerUpdateComments)
+	failFast, failFastErr := envBool(EnvSyncFailFast)
+	maxDonationBytes, maxDonationBytesErr := envIntOrDefault(EnvSyncMaxDonationBytes, DefaultMaxDonationBytes)
+	maxResponseBytes, maxResponseBytesErr := envIntOrDefault(EnvSyncMaxResponseBytes, DefaultMaxResponseBytes)
+
+	cfg := &Settings{
+		AWS: loadAWS(),
+		Blackbaud: Blackbaud{
+			APIBaseURL:               envOrDefault(EnvBlackbaudAPIBaseURL, "https://api.sky.blackbaud.com"),
+			ClientID:                 strings.TrimSpace(os.Getenv(EnvBlackbaudClientID)),
+			ClientSecret:             strings.TrimSpace(os.Getenv(EnvBlackbaudClientSecret)),
+			EnvironmentID:            strings.TrimSpace(os.Getenv(EnvBlackbaudEnvironmentID)),
+			HedgeDelay:               hedgeDelay,
+			QuotaReserve:             quotaReserve,
+			RefreshTokenSecretARN:    strings.TrimSpace(os.Getenv(EnvBlackbaudRefreshTokenSecretARN)),
+			SecondarySubscriptionKey: strings.TrimSpace(os.Getenv(EnvBlackbaudSecondarySubscriptionKey)),
+			SubscriptionKey:          strings.TrimSpace(os.Getenv(EnvBlackbaudSubscriptionKey)),
+			TokenURL:                 envOrDefault(EnvBlackbaudTokenURL, "https://oauth2.sky.blackbaud.com/token"),
+		},
+		FundraiseUp: FundraiseUp{
+			Accounts:        accounts,
+			APIKey:          strings.TrimSpace(os.Getenv(EnvFundraiseUpAPIKey)),
+			BaseURL:         envOrDefault(EnvFundraiseUpBaseURL, "https://api.fundraiseup.com/v1"),
+			CampaignID:      strings.TrimSpace(os.Getenv(EnvFundraiseUpCampaignID)),
+			CampaignWindows: campaignWindows,
+			FetchOverlap:    fetchOverlap,
+			PageSize:        pageSize,
+			Status:          strings.TrimSpace(os.Getenv(EnvFundraiseUpStatus)),
+			StrictDecode:    strictDecode,
+		},
+		GiftDefaults: GiftDefaults{
+			ReferenceField: GiftReferenceFieldLookupID,
+			TestDonations:  TestDonationsSkip,
+			Type:           defaultType,
+		},
+		Proxy: loadProxy(),
+		SSM: SSM{
+			ParameterName: strings.TrimSpace(os.Getenv(EnvSSMParameterName)),
+		},
+		Storage: loadStorage(),
+		Sync: Sync{
+			FailFast:         failFast,
+			MaxDonationBytes: maxDonationBytes,
+			MaxResponseBytes: maxResponseBytes,
+		},
+		TLS: loadTLS(),
+		Tracker: Tracker{
+			ChargebackNoteType:   strings.TrimSpace(os.Getenv(EnvTrackerChargebackNoteType)),
+			ChargebackStatus:     strings.TrimSpace(os.Getenv(EnvTrackerChargebackStatus)),
+			DeletedGiftCheckDays: checkDays,
+			DeletedGiftPolicy:    strings.TrimSpace(os.Getenv(EnvTrackerDeletedGiftPolicy)),
+			PlanChangeNoteType:   strings.TrimSpace(os.Getenv(EnvTrackerPlanChangeNoteType)),
+			ReconcileDays:        reconcileDays,
+			ReconcileOnly:        reconcileOnly,
+			RetentionDays:        retentionDays,
+			SupporterCacheDays:   supporterCacheDays,
+			TableName:            strings.TrimSpace(os.Getenv(EnvTrackerTableName)),
+			UpdateComments:       updateComments,
+		},
+		UserAgent: UserAgent{
+			Organization: strings.TrimSpace(os.Getenv(EnvUserAgentOrganization)),
+		},
+		Vault: loadVault(),
+	}
+
+	// Settings shared with the local config file are read the same way, over their defaults.
+	if err := errors.Join(
+		strictDecodeErr,
+		hedgeDelayErr,
+		fetchOverlapErr,
+		accountsErr,
+		campaignWindowsErr,
+		quotaReserveErr,
+		reconcileOnlyErr,
+		pageSizeErr,
+		retentionDaysErr,
+		supporterCacheDaysErr,
+		checkDaysErr,
+		reconcileDaysErr,
+		updateCommentsErr,
+		failFastErr,
+		maxDonationBytesErr,
+		maxResponseBytesErr,
+		cfg.CommentScrubbing.overrideFromEnv(),
+		cfg.ConstituentDefaults.overrideFromEnv(),
+		cfg.EmailNormalization.overrideFromEnv(),
+		cfg.GiftDefaults.overrideFromEnv(),
+		cfg.NameNormalization.overrideFromEnv(),
+	); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func loadAWS() AWS {
+	return AWS{
+		DynamoDBEndpoint:       strings.TrimSpace(os.Getenv(EnvAWSEndpointURLDynamoDB)),
+		Endpoint:               strings.TrimSpace(os.Getenv(EnvAWSEndpointURL)),
+		Region:                 strings.TrimSpace(os.Getenv(EnvAWSResourceRegion)),
+		RoleARN:                strings.TrimSpace(os.Getenv(EnvAWSResourceRoleARN)),
+		RoleExternalID:         strings.TrimSpace(os.Getenv(EnvAWSResourceRoleExternalID)),
+		S3Endpoint:             strings.TrimSpace(os.Getenv(EnvAWSEndpointURLS3)),
+		SecretsManagerEndpoint: strings.TrimSpace(os.Getenv(EnvAWSEndpointURLSecretsManager)),
+		SSMEndpoint:            strings.TrimSpace(os.Getenv(EnvAWSEndpointURLSSM)),
+		STSEndpoint:            strings.TrimSpace(os.Getenv(EnvAWSEndpointURLSTS)),
+	}
+}
+
+func loadProxy() Proxy {
+	var p Proxy
+	p.overrideFromEnv()
+	return p
+}
+
+// loadStorage reads the storage provider and its resources, defaulting the state's location for the selected provider.
+func loadStorage() Storage {
+	s := Storage{
+		Azure: AzureStorage{
+			ClientID:           strings.TrimSpace(os.Getenv(EnvAzureClientID)),
+			KeyVaultURL:        strings.TrimRight(strings.TrimSpace(os.Getenv(EnvAzureKeyVaultURL)), "/"),
+			RefreshTokenSecret: strings.TrimSpace(os.Getenv(EnvAzureRefreshTokenSecret)),
+			TableEndpoint:      strings.TrimRight(strings.TrimSpace(os.Getenv(EnvAzureTableEndpoint)), "/"),
+			TableName:          strings.TrimSpace(os.Getenv(EnvAzureTableName)),
+		},
+		GCP: GCPStorage{
+			FirestoreDocument:  strings.Trim(strings.TrimSpace(os.Getenv(EnvGCPFirestoreDocument)), "/"),
+			Project:            strings.TrimSpace(os.Getenv(EnvGCPProject)),
+			RefreshTokenSecret: strings.TrimSpace(os.Getenv(EnvGCPRefreshTokenSecret)),
+		},
+		Provider: strings.ToLower(strings.TrimSpace(os.Getenv(EnvStorageProvider))),
+	}
+
+	switch s.Provider {
+	case StorageProviderAzure:
+		if s.Azure.TableName == "" {
+			s.Azure.TableName = DefaultAzureTableName
+		}
+	case StorageProviderGCP:
+		if s.GCP.FirestoreDocument == "" {
+			s.GCP.FirestoreDocument = DefaultFirestoreDocument
+		}
+	}
+
+	return s
+}
+
+func loadVault() Vault {
+	return Vault{
+		Address:    strings.TrimSpace(os.Getenv(EnvVaultAddr)),
+		KVMount:    strings.Trim(strings.TrimSpace(os.Getenv(EnvVaultKVMount)), "/"),
+		Namespace:  strings.TrimSpace(os.Getenv(EnvVaultNamespace)),
+		SecretPath: strings.Trim(strings.TrimSpace(os.Getenv(EnvVaultSecretPath)), "/"),
+		Token:      strings.TrimSpace(os.Getenv(EnvVaultToken)),
+	}
+}
+
+func loadTLS() TLS {
+	var t TLS
+	t.overrideFromEnv()
+	return t
+}
+
+func envBool(key string) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+	return b, nil
+}
+
+func envCampaignWindows(key string) ([]CampaignWindow, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var windows []CampaignWindow
+	if err := json.Unmarshal([]byte(value), &windows); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of campaign windows: %w", key, err)
+	}
+	for i := range windows {
+		windows[i].CampaignID = strings.TrimSpace(windows[i].CampaignID)
+	}
+	return windows, nil
+}
+
+func envCountryRoutes(key string) ([]CountryRoute, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var routes []CountryRoute
+	if err := json.Unmarshal([]byte(value), &routes); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of routes: %w", key, err)
+	}
+	return routes, nil
+}
+
+func envEventLinks(key string) ([]EventLink, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var links []EventLink
+	if err := json.Unmarshal([]byte(value), &links); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of event links: %w", key, err)
+	}
+	return links, nil
+}
+
+func envFundraiseUpAccounts(key string) ([]FundraiseUpAccount, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var accounts []FundraiseUpAccount
+	if err := json.Unmarshal([]byte(value), &accounts); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of accounts: %w", key, err)
+	}
+	return accounts, nil
+}
+
+func envGiftChecks(key string) ([]GiftCheck, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var checks []GiftCheck
+	if err := json.Unmarshal([]byte(value), &checks); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of checks: %w", key, err)
+	}
+	return checks, nil
+}
+
+func envGiftRules(key string) ([]GiftRule, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var rules []GiftRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of rules: %w", key, err)
+	}
+	return rules, nil
+}
+
+func envGiftSplits(key string) ([]GiftSplit, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var splits []GiftSplit
+	if err := json.Unmarshal([]byte(value), &splits); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of splits: %w", key, err)
+	}
+	return splits, nil
+}
+
+func envIntOrDefault(key string, defaultValue int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+	return n, nil
+}
+
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func envNonNegativeDuration(key string) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration such as 2s", key)
+	}
+	return d, nil
+}
+
+func envNonNegativeInt(key string) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return n, nil
+}
+
+func envOrDefault(key string, defaultValue string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
+func envStrings(key string) ([]string, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of strings: %w", key, err)
+	}
+	return values, nil
+}
+
 func requiredError(envVar string) error {
 	return fmt.Errorf("%s is required", envVar)
 }
+
+// validateCampaignWindows checks that each campaign window names a campaign not already given a window, and either
+// excludes it or sets a window that ends after it starts, naming the windows key in errors.
+func validateCampaignWindows(windows []CampaignWindow, key string) error {
+	var errs []error
+	windowed := make(map[string]bool, len(windows))
+	for i, window := range windows {
+		switch {
+		case window.CampaignID == "":
+			errs = append(errs, fmt.Errorf("%s window %d: campaign_id is required", key, i+1))
+		case windowed[window.CampaignID]:
+			errs = append(errs, fmt.Errorf("%s window %d: campaign_id %q already has a window",
+				key, i+1, window.CampaignID))
+		}
+		windowed[window.CampaignID] = true
+
+		switch {
+		case window.Exclude && (!window.From.IsZero() || !window.Until.IsZero()):
+			errs = append(errs, fmt.Errorf("%s window %d: exclude cannot be combined with from or until", key, i+1))
+		case !window.Exclude && window.From.IsZero() && window.Until.IsZero():
+			errs = append(errs, fmt.Errorf("%s window %d: from, until or exclude is required", key, i+1))
+		case !window.From.IsZero() && !window.Until.IsZero() && !window.Until.After(window.From):
+			errs = append(errs, fmt.Errorf("%s window %d: until must be after from", key, i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateCountryRoutes checks that each country route lists countries and sets a fund, campaign or appeal,
+// naming the routes key in errors. Whether the countries are recognised is checked when the sync starts.
+func validateCountryRoutes(routes []CountryRoute, key string) error {
+	var errs []error
+	for i, route := range routes {
+		if len(route.Countries) == 0 {
+			errs = append(errs, fmt.Errorf("%s route %d: countries is required", key, i+1))
+		}
+		for _, country := range route.Countries {
+			if strings.TrimSpace(country) == "" {
+				errs = append(errs, fmt.Errorf("%s route %d: countries must not be empty", key, i+1))
+				break
+			}
+		}
+		if route.AppealID == "" && route.CampaignID == "" && route.FundID == "" {
+			errs = append(errs, fmt.Errorf("%s route %d: fund_id, campaign_id or appeal_id is required", key, i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateEventLinks checks that each event link names both events and that no FundraiseUp event is linked twice,
+// naming the links key in errors.
+func validateEventLinks(links []EventLink, key string) error {
+	var errs []error
+	linked := make(map[string]bool, len(links))
+	for i, link := range links {
+		if strings.TrimSpace(link.FundraiseUpEventID) == "" {
+			errs = append(errs, fmt.Errorf("%s event %d: fundraiseup_event_id is required", key, i+1))
+		} else if linked[link.FundraiseUpEventID] {
+			errs = append(errs, fmt.Errorf("%s event %d: fundraiseup_event_id %q is already linked",
+				key, i+1, link.FundraiseUpEventID))
+		}
+		linked[link.FundraiseUpEventID] = true
+		if strings.TrimSpace(link.EventID) == "" {
+			errs = append(errs, fmt.Errorf("%s event %d: event_id is required", key, i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateFundraiseUpAccounts checks that each further FundraiseUp account has a name and API key, and that no name
+// is used twice, naming the accounts key in errors.
+func validateFundraiseUpAccounts(accounts []FundraiseUpAccount, key string) error {
+	var errs []error
+	named := make(map[string]bool, len(accounts))
+	for i, account := range accounts {
+		if strings.TrimSpace(account.Name) == "" {
+			errs = append(errs, fmt.Errorf("%s account %d: name is required", key, i+1))
+		} else if named[account.Name] {
+			errs = append(errs, fmt.Errorf("%s account %d: name %q is already used", key, i+1, account.Name))
+		}
+		named[account.Name] = true
+		if strings.TrimSpace(account.APIKey) == "" {
+			errs = append(errs, fmt.Errorf("%s account %d: api_key is required", key, i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateGiftChecks checks that each gift check has a valid true or false expression, naming the checks key in errors.
+func validateGiftChecks(checks []GiftCheck, key string) error {
+	var errs []error
+	for i, check := range checks {
+		if _, err := transform.NewCheck(check.Assert, check.Message); err != nil {
+			errs = append(errs, fmt.Errorf("%s check %d: %w", key, i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateGiftRules checks that each gift rule names a known field and has valid expressions,
+// naming the rules key in errors.
+func validateGiftRules(rules []GiftRule, key string) error {
+	var errs []error
+	for i, rule := range rules {
+		if _, err := transform.NewRule(rule.Field, rule.When, rule.Value); err != nil {
+			errs = append(errs, fmt.Errorf("%s rule %d: %w", key, i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateGiftSplits checks that each gift split names a fund and exactly one of an amount or a percentage,
+// and that the percentages leave some of the gift for the remainder fund, naming the splits key in errors.
+func validateGiftSplits(splits []GiftSplit, key string) error {
+	var errs []error
+	total := 0.0
+	for i, split := range splits {
+		if strings.TrimSpace(split.FundID) == "" {
+			errs = append(errs, fmt.Errorf("%s split %d: fund_id is required", key, i+1))
+		}
+		switch {
+		case split.Amount == 0 && split.Percent == 0:
+			errs = append(errs, fmt.Errorf("%s split %d: amount or percent is required", key, i+1))
+		case split.Amount != 0 && split.Percent != 0:
+			errs = append(errs, fmt.Errorf("%s split %d: amount and percent cannot both be set", key, i+1))
+		case split.Amount < 0:
+			errs = append(errs, fmt.Errorf("%s split %d: amount must be positive", key, i+1))
+		case split.Percent < 0 || split.Percent >= 100:
+			errs = append(errs, fmt.Errorf("%s split %d: percent must be between 0 and 100", key, i+1))
+		}
+		total += split.Percent
+	}
+	if total >= 100 {
+		errs = append(errs, fmt.Errorf("%s percentages must total less than 100", key))
+	}
+	return errors.Join(errs...)
+}
+
+// validateGiftType checks that a gift type matching a known type apart from case or spacing is spelled as the
+// SKY API expects, naming it key in errors. Unknown gift types are allowed.
+func validateGiftType(giftType string, key string) error {
+	squashed := strings.ToLower(strings.Join(strings.Fields(giftType), ""))
+	for _, known := range knownGiftTypes {
+		if giftType != known && squashed == strings.ToLower(known) {
+			return fmt.Errorf("%s must be spelled %q, not %q", key, known, giftType)
+		}
+	}
+	return nil
+}
+
+// validateNameFormat checks that format is a valid text/template, naming it key in errors.
+// Fields the format refers to are checked when the sync service is created.
+func validateNameFormat(format string, key string) error {
+	if _, err := template.New(key).Parse(format); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// validateDonationNote checks the donation note format parses and is only set with a note type,
+// naming them formatKey and typeKey in errors.
+func validateDonationNote(defaults ConstituentDefaults, formatKey string, typeKey string) error {
+	if err := validateNameFormat(defaults.DonationNoteFormat, formatKey); err != nil {
+		return err
+	}
+	if strings.TrimSpace(defaults.DonationNoteFormat) != "" && defaults.DonationNoteType == "" {
+		return fmt.Errorf("%s requires %s", formatKey, typeKey)
+	}
+	return nil
+}
+
+// validatePatterns checks that each pattern is a valid regular expression, naming them key in errors.
+func validatePatterns(patterns []string, key string) error {
+	var errs []error
+	for i, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("%s pattern %d: %w", key, i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validatePosting checks a gift post status and post date, naming them statusKey and dateKey in errors.
+// A post date is only meaningful for gifts that will be posted.
+func validatePosting(status string, date string, statusKey string, dateKey string) error {
+	var errs []error
+
+	switch status {
+	case "", GiftPostStatusDoNotPost, GiftPostStatusNotPosted:
+	default:
+		errs = append(errs,
+			fmt.Errorf("%s must be %s or %s", statusKey, GiftPostStatusNotPosted, GiftPostStatusDoNotPost))
+	}
+
+	switch date {
+	case "":
+	case GiftPostDateDonation, GiftPostDateSync:
+		if status != GiftPostStatusNotPosted {
+			errs = append(errs, fmt.Errorf("%s requires %s to be %s", dateKey, statusKey, GiftPostStatusNotPosted))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s must be %s or %s", dateKey, GiftPostDateDonation, GiftPostDateSync))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateOrganization checks that an organization identifier named key can be sent in a User-Agent header.
+func validateOrganization(organization string, key string) error {
+	if len(organization) > maxOrganizationLength {
+		return fmt.Errorf("%s must be at most %d characters", key, maxOrganizationLength)
+	}
+	for _, r := range organization {
+		if r < ' ' || r > '~' || r == '(' || r == ')' {
+			return fmt.Errorf("%s must contain only printable ASCII characters other than parentheses, "+
+				"such as st-marys-hospice", key)
+		}
+	}
+	return nil
+}
+
+// validateProxy checks a proxy's URL and bypass list, naming them urlKey and bypassKey in errors.
+func validateProxy(p Proxy, urlKey string, bypassKey string) error {
+	if p.URL == "" {
+		if len(p.Bypass) > 0 {
+			return fmt.Errorf("%s requires %s", bypassKey, urlKey)
+		}
+		return nil
+	}
+
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute http or https URL", urlKey)
+	}
+	return nil
+}
+
+// validateTLS checks that the client certificate and key are set together and that each secret ARN
+// names a Secrets Manager secret, naming the values caKey, certKey and keyKey in errors.
+func validateTLS(t TLS, caKey string, certKey string, keyKey string) error {
+	var errs []error
+
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		errs = append(errs, fmt.Errorf("%s and %s must be set together", certKey, keyKey))
+	}
+
+	sources := []struct {
+		key   string
+		value string
+	}{
+		{caKey, t.CABundle},
+		{certKey, t.ClientCert},
+		{keyKey, t.ClientKey},
+	}
+	for _, source := range sources {
+		if !IsSecretARN(source.value) {
+			continue
+		}
+		if !isSecretsManagerARN(source.value) {
+			errs = append(errs, fmt.Errorf("%s must be a file path or Secrets Manager secret ARN, such as %s",
+				source.key, exampleSecretARN))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateGiftDatePolicy checks a gift date policy and the maximum age it applies to, naming them by their keys in
+// errors.
+func validateGiftDatePolicy(policy string, maxAgeDays int, policyKey string, maxAgeKey string) error {
+	switch policy {
+	case "":
+		if maxAgeDays != 0 {
+			return fmt.Errorf("%s requires %s", maxAgeKey, policyKey)
+		}
+	case GiftDatePolicyFlag, GiftDatePolicyRefuse:
+	default:
+		return fmt.Errorf("%s must be %s or %s", policyKey, GiftDatePolicyFlag, GiftDatePolicyRefuse)
+	}
+	if maxAgeDays < 0 {
+		return fmt.Errorf("%s must not be negative", maxAgeKey)
+	}
+	return nil
+}
+
+// validateRecurringMode checks a gift recurring mode, naming it key in errors.
+func validateRecurringMode(mode string, key string) error {
+	switch mode {
+	case "", GiftRecurringModeFlat, GiftRecurringModeLinked:
+		return nil
+	default:
+		return fmt.Errorf("%s must be %s or %s", key, GiftRecurringModeLinked, GiftRecurringModeFlat)
+	}
+}
+
+// validateReferenceField checks a gift reference field, naming it key in errors.
+func validateReferenceField(field string, key string) error {
+	switch field {
+	case "", GiftReferenceFieldLookupID, GiftReferenceFieldOrigin:
+		return nil
+	default:
+		return fmt.Errorf("%s must be %s or %s", key, GiftReferenceFieldLookupID, GiftReferenceFieldOrigin)
+	}
+}
+
+func validateTestDonations(policy string, fundID string, policyKey string, fundKey string) error {
+	switch policy {
+	case "", TestDonationsSkip:
+		if fundID != "" {
+			return fmt.Errorf("%s requires %s to be %s", fundKey, policyKey, TestDonationsSync)
+		}
+	case TestDonationsSync:
+	default:
+		return fmt.Errorf("%s must be %s or %s", policyKey, TestDonationsSkip, TestDonationsSync)
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -37,59 +39,425 @@ func TestLoad(t *testing.T) {
 					TokenURL:              "https://oauth2.sky.blackbaud.com/token",
 				},
 				FundraiseUp: FundraiseUp{
-					APIKey:  "fru-key",
-					BaseURL: "https://api.fundraiseup.com/v1",
+					APIKey:   "fru-key",
+					BaseURL:  "https://api.fundraiseup.com/v1",
+					PageSize: DefaultFundraiseUpPageSize,
 				},
 				GiftDefaults: GiftDefaults{
-					FundID: "fund-123",
-					Type:   "Donation",
+					FundID:         "fund-123",
+					ReferenceField: GiftReferenceFieldLookupID,
+					TestDonations:  TestDonationsSkip,
+					Type:           "Donation",
 				},
 				SSM: SSM{
 					ParameterName: "/app/last-sync",
 				},
+				Sync: Sync{
+					MaxDonationBytes: DefaultMaxDonationBytes,
+					MaxResponseBytes: DefaultMaxResponseBytes,
+				},
+				Tracker: Tracker{
+					DeletedGiftCheckDays: DefaultDeletedGiftCheckDays,
+					ReconcileDays:        DefaultReconcileDays,
+				},
+			},
+		},
+		"refresh token in vault": {
+			envVars: map[string]string{
+				EnvBlackbaudClientID:        "client-id",
+				EnvBlackbaudClientSecret:    "client-secret",
+				EnvBlackbaudEnvironmentID:   "env-id",
+				EnvBlackbaudSubscriptionKey: "sub-key",
+				EnvFundraiseUpAPIKey:        "fru-key",
+				EnvGiftFundID:               "fund-123",
+				EnvSSMParameterName:         "/app/last-sync",
+				EnvVaultAddr:                "https://vault.example.org:8200",
+				EnvVaultKVMount:             "/kv/",
+				EnvVaultNamespace:           " fundraising ",
+				EnvVaultSecretPath:          "/giftbridge/blackbaud",
+				EnvVaultToken:               "hvs.token",
+			},
+			wantErr: false,
+			wantSettings: &Settings{
+				Blackbaud: Blackbaud{
+					APIBaseURL:      "https://api.sky.blackbaud.com",
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					EnvironmentID:   "env-id",
+					SubscriptionKey: "sub-key",
+					TokenURL:        "https://oauth2.sky.blackbaud.com/token",
+				},
+				FundraiseUp: FundraiseUp{
+					APIKey:   "fru-key",
+					BaseURL:  "https://api.fundraiseup.com/v1",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID:         "fund-123",
+					ReferenceField: GiftReferenceFieldLookupID,
+					TestDonations:  TestDonationsSkip,
+					Type:           "Donation",
+				},
+				SSM: SSM{
+					ParameterName: "/app/last-sync",
+				},
+				Sync: Sync{
+					MaxDonationBytes: DefaultMaxDonationBytes,
+					MaxResponseBytes: DefaultMaxResponseBytes,
+				},
+				Tracker: Tracker{
+					DeletedGiftCheckDays: DefaultDeletedGiftCheckDays,
+					ReconcileDays:        DefaultReconcileDays,
+				},
+				Vault: Vault{
+					Address:    "https://vault.example.org:8200",
+					KVMount:    "kv",
+					Namespace:  "fundraising",
+					SecretPath: "giftbridge/blackbaud",
+					Token:      "hvs.token",
+				},
+			},
+		},
+		"state and refresh token in google cloud": {
+			envVars: map[string]string{
+				EnvBlackbaudClientID:        "client-id",
+				EnvBlackbaudClientSecret:    "client-secret",
+				EnvBlackbaudEnvironmentID:   "env-id",
+				EnvBlackbaudSubscriptionKey: "sub-key",
+				EnvFundraiseUpAPIKey:        "fru-key",
+				EnvGCPProject:               "charity-fundraising",
+				EnvGCPRefreshTokenSecret:    "blackbaud-refresh-token",
+				EnvGiftFundID:               "fund-123",
+				EnvStorageProvider:          " GCP ",
+			},
+			wantErr: false,
+			wantSettings: &Settings{
+				Blackbaud: Blackbaud{
+					APIBaseURL:      "https://api.sky.blackbaud.com",
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					EnvironmentID:   "env-id",
+					SubscriptionKey: "sub-key",
+					TokenURL:        "https://oauth2.sky.blackbaud.com/token",
+				},
+				FundraiseUp: FundraiseUp{
+					APIKey:   "fru-key",
+					BaseURL:  "https://api.fundraiseup.com/v1",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID:         "fund-123",
+					ReferenceField: GiftReferenceFieldLookupID,
+					TestDonations:  TestDonationsSkip,
+					Type:           "Donation",
+				},
+				Storage: Storage{
+					GCP: GCPStorage{
+						FirestoreDocument:  DefaultFirestoreDocument,
+						Project:            "charity-fundraising",
+						RefreshTokenSecret: "blackbaud-refresh-token",
+					},
+					Provider: StorageProviderGCP,
+				},
+				Sync: Sync{
+					MaxDonationBytes: DefaultMaxDonationBytes,
+					MaxResponseBytes: DefaultMaxResponseBytes,
+				},
+				Tracker: Tracker{
+					DeletedGiftCheckDays: DefaultDeletedGiftCheckDays,
+					ReconcileDays:        DefaultReconcileDays,
+				},
+			},
+		},
+		"state in azure with refresh token in vault": {
+			envVars: map[string]string{
+				EnvAzureTableEndpoint:       "https://charity.table.core.windows.net/",
+				EnvBlackbaudClientID:        "client-id",
+				EnvBlackbaudClientSecret:    "client-secret",
+				EnvBlackbaudEnvironmentID:   "env-id",
+				EnvBlackbaudSubscriptionKey: "sub-key",
+				EnvFundraiseUpAPIKey:        "fru-key",
+				EnvGiftFundID:               "fund-123",
+				EnvStorageProvider:          "azure",
+				EnvVaultAddr:                "https://vault.example.org:8200",
+				EnvVaultSecretPath:          "giftbridge/blackbaud",
+				EnvVaultToken:               "hvs.token",
+			},
+			wantErr: false,
+			wantSettings: &Settings{
+				Blackbaud: Blackbaud{
+					APIBaseURL:      "https://api.sky.blackbaud.com",
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					EnvironmentID:   "env-id",
+					SubscriptionKey: "sub-key",
+					TokenURL:        "https://oauth2.sky.blackbaud.com/token",
+				},
+				FundraiseUp: FundraiseUp{
+					APIKey:   "fru-key",
+					BaseURL:  "https://api.fundraiseup.com/v1",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID:         "fund-123",
+					ReferenceField: GiftReferenceFieldLookupID,
+					TestDonations:  TestDonationsSkip,
+					Type:           "Donation",
+				},
+				Storage: Storage{
+					Azure: AzureStorage{
+						TableEndpoint: "https://charity.table.core.windows.net",
+						TableName:     DefaultAzureTableName,
+					},
+					Provider: StorageProviderAzure,
+				},
+				Sync: Sync{
+					MaxDonationBytes: DefaultMaxDonationBytes,
+					MaxResponseBytes: DefaultMaxResponseBytes,
+				},
+				Tracker: Tracker{
+					DeletedGiftCheckDays: DefaultDeletedGiftCheckDays,
+					ReconcileDays:        DefaultReconcileDays,
+				},
+				Vault: Vault{
+					Address:    "https://vault.example.org:8200",
+					SecretPath: "giftbridge/blackbaud",
+					Token:      "hvs.token",
+				},
+			},
+		},
+		"incomplete azure storage settings": {
+			envVars: map[string]string{
+				EnvAzureKeyVaultURL: "http://charity.vault.azure.net",
+				EnvAzureTableName:   "sync-state",
+				EnvStorageProvider:  StorageProviderAzure,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvAzureKeyVaultURL + " must be an absolute https URL",
+				EnvStorageProvider + "=azure requires " + EnvAzureRefreshTokenSecret,
+				EnvStorageProvider + "=azure requires " + EnvAzureTableEndpoint,
+				EnvAzureTableName + " must be 3 to 63 letters and digits, starting with a letter",
+			},
+		},
+		"incomplete google cloud storage settings": {
+			envVars: map[string]string{
+				EnvGCPFirestoreDocument: "giftbridge",
+				EnvStorageProvider:      StorageProviderGCP,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvStorageProvider + "=gcp requires " + EnvGCPProject,
+				EnvStorageProvider + "=gcp requires " + EnvGCPRefreshTokenSecret,
+				EnvGCPFirestoreDocument + " must be a collection and document ID",
+			},
+		},
+		"unknown storage provider": {
+			envVars: map[string]string{
+				EnvStorageProvider: "oracle",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvStorageProvider + " must be aws, gcp or azure"},
+		},
+		"incomplete vault settings": {
+			envVars: map[string]string{
+				EnvVaultAddr: "vault.example.org:8200",
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvVaultAddr + " must be an absolute http or https URL",
+				EnvVaultAddr + " requires " + EnvVaultSecretPath,
+				EnvVaultAddr + " requires " + EnvVaultToken,
 			},
 		},
 		"custom URLs and gift defaults": {
 			envVars: map[string]string{
-				EnvBlackbaudAPIBaseURL:            "https://custom.api.com",
-				EnvBlackbaudClientID:              "client-id",
-				EnvBlackbaudClientSecret:          "client-secret",
-				EnvBlackbaudEnvironmentID:         "env-id",
-				EnvBlackbaudRefreshTokenSecretARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
-				EnvBlackbaudSubscriptionKey:       "sub-key",
-				EnvBlackbaudTokenURL:              "https://custom.token.com",
-				EnvFundraiseUpAPIKey:              "fru-key",
-				EnvFundraiseUpBaseURL:             "https://custom.fru.com",
-				EnvGiftAppealID:                   "appeal-456",
-				EnvGiftCampaignID:                 "campaign-789",
-				EnvGiftFundID:                     "fund-123",
-				EnvGiftType:                       "Grant",
-				EnvSSMParameterName:               "/app/last-sync",
+				EnvBlackbaudAPIBaseURL:               "https://custom.api.com",
+				EnvBlackbaudClientID:                 "client-id",
+				EnvBlackbaudClientSecret:             "client-secret",
+				EnvBlackbaudEnvironmentID:            "env-id",
+				EnvBlackbaudHedgeDelay:               "1500ms",
+				EnvBlackbaudQuotaReserve:             "500",
+				EnvBlackbaudRefreshTokenSecretARN:    "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
+				EnvBlackbaudSecondarySubscriptionKey: " second-key ",
+				EnvBlackbaudSubscriptionKey:          "sub-key",
+				EnvBlackbaudTokenURL:                 "https://custom.token.com",
+				EnvFundraiseUpAccounts:               `[{"name":"trading","apiKey":"fru-trading-key"}]`,
+				EnvFundraiseUpAPIKey:                 "fru-key",
+				EnvFundraiseUpBaseURL:                "https://custom.fru.com",
+				EnvFundraiseUpCampaignID:             "FUNCAMP1",
+				EnvFundraiseUpCampaignWindows:        `[{"campaignId":" FUNLEGACY ","exclude":true}]`,
+				EnvFundraiseUpFetchOverlap:           "15m",
+				EnvFundraiseUpPageSize:               "50",
+				EnvFundraiseUpStatus:                 " succeeded ",
+				EnvFundraiseUpStrictDecode:           "true",
+				EnvGiftAppealID:                      "appeal-456",
+				EnvGiftAppealResponses:               "true",
+				EnvGiftCampaignID:                    "campaign-789",
+				EnvGiftChecks:                        `[{"assert":"donation.amount < 1e5","message":"too large"}]`,
+				EnvGiftCountryRoutes:                 `[{"countries":["GB"],"fundId":"gift-aid"}]`,
+				EnvGiftCreatorCustomField:            "Created by",
+				EnvGiftDatePolicy:                    "refuse",
+				EnvGiftFundID:                        "fund-123",
+				EnvGiftMaxAgeDays:                    "365",
+				EnvGiftPostDate:                      "sync",
+				EnvGiftPostStatus:                    "NotPosted",
+				EnvGiftRecurringMode:                 "flat",
+				EnvGiftReferenceField:                "origin",
+				EnvGiftReferenceNoteType:             "Comment",
+				EnvGiftRules:                         `[{"field":"fund_id","when":"true","value":"'major'"}]`,
+				EnvGiftSplits:                        `[{"fundId":"gala","amount":50},{"fundId":"admin","percent":10}]`,
+				EnvGiftTestDonations:                 "sync",
+				EnvGiftTestFundID:                    "sandbox",
+				EnvGiftType:                          "Grant",
+				EnvSSMParameterName:                  "/app/last-sync",
+				EnvSyncFailFast:                      "true",
+				EnvSyncMaxDonationBytes:              "131072",
+				EnvSyncMaxResponseBytes:              "8388608",
+				EnvTrackerChargebackNoteType:         " Finance ",
+				EnvTrackerChargebackStatus:           " Held ",
+				EnvTrackerDeletedGiftCheckDays:       "0",
+				EnvTrackerDeletedGiftPolicy:          "exclude",
+				EnvTrackerPlanChangeNoteType:         " Stewardship ",
+				EnvTrackerReconcileDays:              "3",
+				EnvTrackerReconcileOnly:              "true",
+				EnvTrackerRetentionDays:              "730",
+				EnvTrackerSupporterCacheDays:         "90",
+				EnvTrackerTableName:                  "giftbridge-donations",
+				EnvTrackerUpdateComments:             "true",
+				EnvUserAgentOrganization:             " st-marys-hospice ",
+				EnvAWSEndpointURLDynamoDB:            "http://localhost:8000",
+				EnvAWSResourceRegion:                 "eu-west-2",
+				EnvAWSResourceRoleARN:                "arn:aws:iam::123456789012:role/giftbridge-resources",
+				EnvAWSResourceRoleExternalID:         "charity-123",
+				EnvCommentScrubCardNumbers:           "true",
+				EnvCommentScrubPatterns:              `["\\bflat \\d+\\b"]`,
+				EnvCommentScrubWords:                 "darn, heck",
+				EnvConstituentAddresseeFormat:        "{{.FirstName}} {{.LastName}}",
+				EnvConstituentCodes:                  " Online Donor, ,Newsletter ",
+				EnvConstituentDonationNoteFormat:     "Donated to {{.Campaign}}",
+				EnvConstituentDonationNoteType:       " Online giving ",
+				EnvConstituentEvents:                 `[{"fundraiseUpEventId":"evt_gala","eventId":"42"}]`,
+				EnvConstituentSalutationFormat:       "Dear {{.FirstName}}",
+				EnvEmailAddMissing:                   "true",
+				EnvEmailFoldGmail:                    "true",
+				EnvEmailIncludeInactive:              "true",
+				EnvEmailStrictSearch:                 "true",
+				EnvEmailStripPlusTags:                "1",
+				EnvNameTitleCase:                     "true",
+				EnvProxyBypass:                       "localstack, .internal",
+				EnvProxyURL:                          "http://proxy.internal:3128",
+				EnvTLSCABundle:                       "/etc/ssl/giftbridge-ca.pem",
+				EnvTLSClientCert:                     "arn:aws:secretsmanager:eu-west-2:123456789012:secret:client-cert",
+				EnvTLSClientKey:                      "arn:aws:secretsmanager:eu-west-2:123456789012:secret:client-key",
 			},
 			wantErr: false,
 			wantSettings: &Settings{
+				AWS: AWS{
+					DynamoDBEndpoint: "http://localhost:8000",
+					Region:           "eu-west-2",
+					RoleARN:          "arn:aws:iam::123456789012:role/giftbridge-resources",
+					RoleExternalID:   "charity-123",
+				},
 				Blackbaud: Blackbaud{
-					APIBaseURL:            "https://custom.api.com",
-					ClientID:              "client-id",
-					ClientSecret:          "client-secret",
-					EnvironmentID:         "env-id",
-					RefreshTokenSecretARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
-					SubscriptionKey:       "sub-key",
-					TokenURL:              "https://custom.token.com",
+					APIBaseURL:               "https://custom.api.com",
+					ClientID:                 "client-id",
+					ClientSecret:             "client-secret",
+					EnvironmentID:            "env-id",
+					HedgeDelay:               1500 * time.Millisecond,
+					QuotaReserve:             500,
+					RefreshTokenSecretARN:    "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
+					SecondarySubscriptionKey: "second-key",
+					SubscriptionKey:          "sub-key",
+					TokenURL:                 "https://custom.token.com",
+				},
+				CommentScrubbing: CommentScrubbing{
+					CardNumbers: true,
+					Patterns:    []string{`\bflat \d+\b`},
+					Words:       []string{"darn", "heck"},
+				},
+				ConstituentDefaults: ConstituentDefaults{
+					AddresseeFormat:    "{{.FirstName}} {{.LastName}}",
+					Codes:              []string{"Online Donor", "Newsletter"},
+					DonationNoteFormat: "Donated to {{.Campaign}}",
+					DonationNoteType:   "Online giving",
+					Events:             []EventLink{{EventID: "42", FundraiseUpEventID: "evt_gala"}},
+					SalutationFormat:   "Dear {{.FirstName}}",
+				},
+				EmailNormalization: EmailNormalization{
+					AddMissing:      true,
+					FoldGmail:       true,
+					IncludeInactive: true,
+					StrictSearch:    true,
+					StripPlusTags:   true,
 				},
 				FundraiseUp: FundraiseUp{
-					APIKey:  "fru-key",
-					BaseURL: "https://custom.fru.com",
+					Accounts:        []FundraiseUpAccount{{APIKey: "fru-trading-key", Name: "trading"}},
+					APIKey:          "fru-key",
+					BaseURL:         "https://custom.fru.com",
+					CampaignID:      "FUNCAMP1",
+					CampaignWindows: []CampaignWindow{{CampaignID: "FUNLEGACY", Exclude: true}},
+					FetchOverlap:    15 * time.Minute,
+					PageSize:        50,
+					Status:          "succeeded",
+					StrictDecode:    true,
 				},
 				GiftDefaults: GiftDefaults{
-					AppealID:   "appeal-456",
-					CampaignID: "campaign-789",
-					FundID:     "fund-123",
-					Type:       "Grant",
+					AppealID:           "appeal-456",
+					AppealResponses:    true,
+					CampaignID:         "campaign-789",
+					Checks:             []GiftCheck{{Assert: "donation.amount < 1e5", Message: "too large"}},
+					CountryRoutes:      []CountryRoute{{Countries: []string{"GB"}, FundID: "gift-aid"}},
+					CreatorCustomField: "Created by",
+					DatePolicy:         GiftDatePolicyRefuse,
+					FundID:             "fund-123",
+					MaxAgeDays:         365,
+					PostDate:           GiftPostDateSync,
+					PostStatus:         GiftPostStatusNotPosted,
+					RecurringMode:      GiftRecurringModeFlat,
+					ReferenceField:     GiftReferenceFieldOrigin,
+					ReferenceNoteType:  "Comment",
+					Rules:              []GiftRule{{Field: "fund_id", Value: "'major'", When: "true"}},
+					Splits:             []GiftSplit{{Amount: 50, FundID: "gala"}, {FundID: "admin", Percent: 10}},
+					TestDonations:      TestDonationsSync,
+					TestFundID:         "sandbox",
+					Type:               "Grant",
+				},
+				NameNormalization: NameNormalization{
+					TitleCase: true,
+				},
+				Proxy: Proxy{
+					Bypass: []string{"localstack", ".internal"},
+					URL:    "http://proxy.internal:3128",
 				},
 				SSM: SSM{
 					ParameterName: "/app/last-sync",
 				},
+				Sync: Sync{
+					FailFast:         true,
+					MaxDonationBytes: 128 << 10,
+					MaxResponseBytes: 8 << 20,
+				},
+				TLS: TLS{
+					CABundle:   "/etc/ssl/giftbridge-ca.pem",
+					ClientCert: "arn:aws:secretsmanager:eu-west-2:123456789012:secret:client-cert",
+					ClientKey:  "arn:aws:secretsmanager:eu-west-2:123456789012:secret:client-key",
+				},
+				Tracker: Tracker{
+					ChargebackNoteType: "Finance",
+					ChargebackStatus:   "Held",
+					DeletedGiftPolicy:  DeletedGiftPolicyExclude,
+					PlanChangeNoteType: "Stewardship",
+					ReconcileDays:      3,
+					ReconcileOnly:      true,
+					RetentionDays:      730,
+					SupporterCacheDays: 90,
+					TableName:          "giftbridge-donations",
+					UpdateComments:     true,
+				},
+				UserAgent: UserAgent{
+					Organization: "st-marys-hospice",
+				},
 			},
 		},
 		"whitespace only values treated as empty": {
@@ -133,6 +501,467 @@ func TestLoad(t *testing.T) {
 			wantErr:      true,
 			errFragments: []string{EnvBlackbaudClientID + " is required"},
 		},
+		"invalid AWS role": {
+			envVars: map[string]string{
+				EnvAWSResourceRoleARN:             "giftbridge-resources",
+				EnvBlackbaudClientID:              "client-id",
+				EnvBlackbaudClientSecret:          "client-secret",
+				EnvBlackbaudEnvironmentID:         "env-id",
+				EnvBlackbaudRefreshTokenSecretARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
+				EnvBlackbaudSubscriptionKey:       "sub-key",
+				EnvFundraiseUpAPIKey:              "fru-key",
+				EnvGiftFundID:                     "fund-123",
+				EnvSSMParameterName:               "/app/last-sync",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvAWSResourceRoleARN + " must be an IAM role ARN, such as arn:aws:iam::"},
+		},
+		"refresh token secret ARN for another service": {
+			envVars: map[string]string{
+				EnvBlackbaudClientID:              "client-id",
+				EnvBlackbaudClientSecret:          "client-secret",
+				EnvBlackbaudEnvironmentID:         "env-id",
+				EnvBlackbaudRefreshTokenSecretARN: "arn:aws:ssm:us-east-1:123456789012:parameter/token",
+				EnvBlackbaudSubscriptionKey:       "sub-key",
+				EnvFundraiseUpAPIKey:              "fru-key",
+				EnvGiftFundID:                     "fund-123",
+				EnvSSMParameterName:               "/app/last-sync",
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvBlackbaudRefreshTokenSecretARN + " must be a Secrets Manager secret name or ARN, such as arn:aws:",
+			},
+		},
+		"misspelled gift type": {
+			envVars: map[string]string{
+				EnvBlackbaudClientID:              "client-id",
+				EnvBlackbaudClientSecret:          "client-secret",
+				EnvBlackbaudEnvironmentID:         "env-id",
+				EnvBlackbaudRefreshTokenSecretARN: "giftbridge/blackbaud-token",
+				EnvBlackbaudSubscriptionKey:       "sub-key",
+				EnvFundraiseUpAPIKey:              "fru-key",
+				EnvGiftFundID:                     "fund-123",
+				EnvGiftType:                       "gift in kind",
+				EnvSSMParameterName:               "/app/last-sync",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftType + ` must be spelled "GiftInKind", not "gift in kind"`},
+		},
+		"external ID without role": {
+			envVars: map[string]string{
+				EnvAWSResourceRoleExternalID:      "charity-123",
+				EnvBlackbaudClientID:              "client-id",
+				EnvBlackbaudClientSecret:          "client-secret",
+				EnvBlackbaudEnvironmentID:         "env-id",
+				EnvBlackbaudRefreshTokenSecretARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
+				EnvBlackbaudSubscriptionKey:       "sub-key",
+				EnvFundraiseUpAPIKey:              "fru-key",
+				EnvGiftFundID:                     "fund-123",
+				EnvSSMParameterName:               "/app/last-sync",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvAWSResourceRoleExternalID + " requires " + EnvAWSResourceRoleARN},
+		},
+		"invalid strict decode flag": {
+			envVars: map[string]string{
+				EnvFundraiseUpStrictDecode: "yes please",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvFundraiseUpStrictDecode + " must be true or false"},
+		},
+		"invalid campaign windows": {
+			envVars: map[string]string{
+				EnvFundraiseUpCampaignWindows: `[{"from":"2025-03-01T00:00:00Z"},` +
+					`{"campaignId":"FUNLEGACY","exclude":true,"until":"2025-01-01T00:00:00Z"},` +
+					`{"campaignId":"FUNLEGACY"}]`,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvFundraiseUpCampaignWindows + " window 1: campaign_id is required",
+				EnvFundraiseUpCampaignWindows + " window 2: exclude cannot be combined with from or until",
+				EnvFundraiseUpCampaignWindows + ` window 3: campaign_id "FUNLEGACY" already has a window`,
+				EnvFundraiseUpCampaignWindows + " window 3: from, until or exclude is required",
+			},
+		},
+		"invalid fail fast flag": {
+			envVars: map[string]string{
+				EnvSyncFailFast: "on the first error",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvSyncFailFast + " must be true or false"},
+		},
+		"invalid size limits": {
+			envVars: map[string]string{
+				EnvSyncMaxDonationBytes: "0",
+				EnvSyncMaxResponseBytes: "-1",
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvSyncMaxDonationBytes + " must be a positive integer",
+				EnvSyncMaxResponseBytes + " must be a positive integer",
+			},
+		},
+		"invalid email normalization flag": {
+			envVars: map[string]string{
+				EnvEmailFoldGmail: "sometimes",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvEmailFoldGmail + " must be true or false"},
+		},
+		"invalid hedge delay": {
+			envVars: map[string]string{
+				EnvBlackbaudHedgeDelay: "2",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvBlackbaudHedgeDelay + " must be a non-negative duration such as 2s"},
+		},
+		"invalid fetch overlap": {
+			envVars: map[string]string{
+				EnvFundraiseUpFetchOverlap: "-15m",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvFundraiseUpFetchOverlap + " must be a non-negative duration such as 2s"},
+		},
+		"invalid fundraiseup accounts": {
+			envVars: map[string]string{
+				EnvFundraiseUpAccounts: `[{"name":"trading","apiKey":"a"},{"name":"trading"}]`,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvFundraiseUpAccounts + ` account 2: name "trading" is already used`,
+				EnvFundraiseUpAccounts + " account 2: api_key is required",
+			},
+		},
+		"invalid quota reserve": {
+			envVars: map[string]string{
+				EnvBlackbaudQuotaReserve: "-5",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvBlackbaudQuotaReserve + " must be a non-negative integer"},
+		},
+		"non-numeric page size": {
+			envVars: map[string]string{
+				EnvFundraiseUpPageSize: "lots",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvFundraiseUpPageSize + " must be an integer"},
+		},
+		"page size out of range": {
+			envVars: map[string]string{
+				EnvBlackbaudClientID:              "client-id",
+				EnvBlackbaudClientSecret:          "client-secret",
+				EnvBlackbaudEnvironmentID:         "env-id",
+				EnvBlackbaudRefreshTokenSecretARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
+				EnvBlackbaudSubscriptionKey:       "sub-key",
+				EnvFundraiseUpAPIKey:              "fru-key",
+				EnvFundraiseUpPageSize:            "500",
+				EnvGiftFundID:                     "fund-123",
+				EnvSSMParameterName:               "/app/last-sync",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvFundraiseUpPageSize + " must be between 1 and 100"},
+		},
+		"invalid gift posting": {
+			envVars: map[string]string{
+				EnvGiftPostDate:   "tomorrow",
+				EnvGiftPostStatus: "Posted",
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvGiftPostStatus + " must be NotPosted or DoNotPost",
+				EnvGiftPostDate + " must be donation or sync",
+			},
+		},
+		"post date without NotPosted status": {
+			envVars: map[string]string{
+				EnvGiftPostDate:   "donation",
+				EnvGiftPostStatus: "DoNotPost",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftPostDate + " requires " + EnvGiftPostStatus + " to be NotPosted"},
+		},
+		"invalid gift reference field": {
+			envVars: map[string]string{
+				EnvGiftReferenceField: "custom_field",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftReferenceField + " must be lookup_id or origin"},
+		},
+		"invalid gift recurring mode": {
+			envVars: map[string]string{
+				EnvGiftRecurringMode: "series",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftRecurringMode + " must be linked or flat"},
+		},
+		"invalid gift date policy": {
+			envVars: map[string]string{
+				EnvGiftDatePolicy: "warn",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftDatePolicy + " must be flag or refuse"},
+		},
+		"gift max age without date policy": {
+			envVars: map[string]string{
+				EnvGiftMaxAgeDays: "365",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftMaxAgeDays + " requires " + EnvGiftDatePolicy},
+		},
+		"invalid test donations": {
+			envVars: map[string]string{
+				EnvGiftTestDonations: "route",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftTestDonations + " must be skip or sync"},
+		},
+		"test fund without syncing test donations": {
+			envVars: map[string]string{
+				EnvGiftTestFundID: "sandbox",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftTestFundID + " requires " + EnvGiftTestDonations + " to be sync"},
+		},
+		"invalid proxy URL": {
+			envVars: map[string]string{
+				EnvProxyURL: "proxy.internal:3128",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvProxyURL + " must be an absolute http or https URL"},
+		},
+		"user agent organization with control characters": {
+			envVars: map[string]string{
+				EnvUserAgentOrganization: "st-marys\r\nX-Injected: 1",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvUserAgentOrganization + " must contain only printable ASCII characters"},
+		},
+		"user agent organization too long": {
+			envVars: map[string]string{
+				EnvUserAgentOrganization: strings.Repeat("a", 65),
+			},
+			wantErr:      true,
+			errFragments: []string{EnvUserAgentOrganization + " must be at most 64 characters"},
+		},
+		"proxy bypass without proxy URL": {
+			envVars: map[string]string{
+				EnvProxyBypass: "localstack",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvProxyBypass + " requires " + EnvProxyURL},
+		},
+		"client certificate without key": {
+			envVars: map[string]string{
+				EnvTLSClientCert: "/etc/ssl/client.pem",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvTLSClientCert + " and " + EnvTLSClientKey + " must be set together"},
+		},
+		"CA bundle ARN not naming a secret": {
+			envVars: map[string]string{
+				EnvTLSCABundle: "arn:aws:s3:::giftbridge/ca.pem",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvTLSCABundle + " must be a file path or Secrets Manager secret ARN"},
+		},
+		"malformed gift rules": {
+			envVars: map[string]string{
+				EnvGiftRules: `{"field":"fund_id"}`,
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftRules + " must be a JSON list of rules"},
+		},
+		"invalid gift rules": {
+			envVars: map[string]string{
+				EnvGiftRules: `[{"field":"type","value":"'x'"},{"field":"reference","value":"donation.fee"}]`,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvGiftRules + ` rule 1: field "type" must be appeal_id, campaign_id, fund_id or reference`,
+				EnvGiftRules + " rule 2: value: undeclared reference to 'donation' (in container '') at position 1",
+			},
+		},
+		"invalid gift checks": {
+			envVars: map[string]string{
+				EnvGiftChecks: `[{"assert":"gift.fund_id"}]`,
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftChecks + " check 1: assert must be true or false, got text"},
+		},
+		"malformed gift splits": {
+			envVars: map[string]string{
+				EnvGiftSplits: `{"fundId":"admin"}`,
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftSplits + " must be a JSON list of splits"},
+		},
+		"invalid gift splits": {
+			envVars: map[string]string{
+				EnvGiftSplits: `[{"fundId":"admin"},{"fundId":"","percent":100},{"fundId":"a","amount":-5},` +
+					`{"fundId":"b","amount":5,"percent":5}]`,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvGiftSplits + " split 1: amount or percent is required",
+				EnvGiftSplits + " split 3: amount must be positive",
+				EnvGiftSplits + " split 4: amount and percent cannot both be set",
+				EnvGiftSplits + " split 2: fund_id is required",
+				EnvGiftSplits + " split 2: percent must be between 0 and 100",
+				EnvGiftSplits + " percentages must total less than 100",
+			},
+		},
+		"malformed country routes": {
+			envVars: map[string]string{
+				EnvGiftCountryRoutes: `{"countries":["GB"]}`,
+			},
+			wantErr:      true,
+			errFragments: []string{EnvGiftCountryRoutes + " must be a JSON list of routes"},
+		},
+		"invalid country routes": {
+			envVars: map[string]string{
+				EnvGiftCountryRoutes: `[{"fundId":"gift-aid"},{"countries":["US",""]}]`,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvGiftCountryRoutes + " route 1: countries is required",
+				EnvGiftCountryRoutes + " route 2: countries must not be empty",
+				EnvGiftCountryRoutes + " route 2: fund_id, campaign_id or appeal_id is required",
+			},
+		},
+		"event links not a list": {
+			envVars: map[string]string{
+				EnvConstituentEvents: `{"fundraiseUpEventId":"evt_gala"}`,
+			},
+			wantErr:      true,
+			errFragments: []string{EnvConstituentEvents + " must be a JSON list of event links"},
+		},
+		"invalid event links": {
+			envVars: map[string]string{
+				EnvConstituentEvents: `[{"eventId":"42"},{"fundraiseUpEventId":"evt_gala"},` +
+					`{"fundraiseUpEventId":"evt_gala","eventId":"43"}]`,
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvConstituentEvents + " event 1: fundraiseup_event_id is required",
+				EnvConstituentEvents + " event 2: event_id is required",
+				EnvConstituentEvents + ` event 3: fundraiseup_event_id "evt_gala" is already linked`,
+			},
+		},
+		"invalid addressee format": {
+			envVars: map[string]string{
+				EnvConstituentAddresseeFormat: "{{.FirstName",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvConstituentAddresseeFormat + ": template:", "unclosed action"},
+		},
+		"donation note format without note type": {
+			envVars: map[string]string{
+				EnvConstituentDonationNoteFormat: "Donated to {{.Campaign}}",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvConstituentDonationNoteFormat + " requires " + EnvConstituentDonationNoteType},
+		},
+		"invalid donation note format": {
+			envVars: map[string]string{
+				EnvConstituentDonationNoteFormat: "Donated to {{.Campaign",
+				EnvConstituentDonationNoteType:   "Online giving",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvConstituentDonationNoteFormat + ": template:", "unclosed action"},
+		},
+		"invalid deleted gift policy": {
+			envVars: map[string]string{
+				EnvTrackerDeletedGiftCheckDays: "-1",
+				EnvTrackerDeletedGiftPolicy:    "ignore",
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvTrackerDeletedGiftCheckDays + " must be a non-negative integer",
+				EnvTrackerDeletedGiftPolicy + " must be report, recreate or exclude",
+				EnvTrackerDeletedGiftPolicy + " requires " + EnvTrackerTableName,
+			},
+		},
+		"chargeback note type without status": {
+			envVars: map[string]string{
+				EnvTrackerChargebackNoteType: "Finance",
+				EnvTrackerTableName:          "giftbridge-donations",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvTrackerChargebackNoteType + " requires " + EnvTrackerChargebackStatus},
+		},
+		"reconcile only without tracker table": {
+			envVars: map[string]string{
+				EnvTrackerChargebackStatus:   "Held",
+				EnvTrackerPlanChangeNoteType: "Stewardship",
+				EnvTrackerReconcileDays:      "0",
+				EnvTrackerReconcileOnly:      "true",
+				EnvTrackerUpdateComments:     "true",
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvTrackerChargebackStatus + " requires " + EnvTrackerTableName,
+				EnvTrackerPlanChangeNoteType + " requires " + EnvTrackerTableName,
+				EnvTrackerReconcileDays + " must be a positive integer",
+				EnvTrackerReconcileOnly + " requires " + EnvTrackerTableName,
+				EnvTrackerUpdateComments + " requires " + EnvTrackerTableName,
+			},
+		},
+		"invalid comment scrub patterns": {
+			envVars: map[string]string{
+				EnvCommentScrubPatterns: `["ok", "(unclosed"]`,
+			},
+			wantErr:      true,
+			errFragments: []string{EnvCommentScrubPatterns + " pattern 2: error parsing regexp"},
+		},
+		"comment scrub patterns not a list": {
+			envVars: map[string]string{
+				EnvCommentScrubPatterns: `"\\d+"`,
+			},
+			wantErr:      true,
+			errFragments: []string{EnvCommentScrubPatterns + " must be a JSON list of strings"},
+		},
+		"retention without tracker table": {
+			envVars: map[string]string{
+				EnvTrackerRetentionDays: "365",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvTrackerRetentionDays + " requires " + EnvTrackerTableName},
+		},
+		"negative retention": {
+			envVars: map[string]string{
+				EnvTrackerRetentionDays: "-1",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvTrackerRetentionDays + " must be a non-negative integer"},
+		},
+		"supporter cache without tracker table": {
+			envVars: map[string]string{
+				EnvTrackerSupporterCacheDays: "90",
+			},
+			wantErr:      true,
+			errFragments: []string{EnvTrackerSupporterCacheDays + " requires " + EnvTrackerTableName},
+		},
+		"invalid AWS endpoints": {
+			envVars: map[string]string{
+				EnvAWSEndpointURL:                 "localhost:4566",
+				EnvAWSEndpointURLS3:               "s3.localhost",
+				EnvAWSEndpointURLSSM:              "ftp://ssm.example.com",
+				EnvBlackbaudClientID:              "client-id",
+				EnvBlackbaudClientSecret:          "client-secret",
+				EnvBlackbaudEnvironmentID:         "env-id",
+				EnvBlackbaudRefreshTokenSecretARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token",
+				EnvBlackbaudSubscriptionKey:       "sub-key",
+				EnvFundraiseUpAPIKey:              "fru-key",
+				EnvGiftFundID:                     "fund-123",
+				EnvSSMParameterName:               "/app/last-sync",
+			},
+			wantErr: true,
+			errFragments: []string{
+				EnvAWSEndpointURL + " must be an absolute http or https URL",
+				EnvAWSEndpointURLS3 + " must be an absolute http or https URL",
+				EnvAWSEndpointURLSSM + " must be an absolute http or https URL",
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -158,6 +987,45 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoadResourceNames(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv().
+	tests := map[string]struct {
+		env  map[string]string
+		want ResourceNames
+	}{
+		"unset": {
+			env: map[string]string{
+				EnvSSMParameterName:               "",
+				EnvBlackbaudRefreshTokenSecretARN: "",
+				EnvTrackerTableName:               "",
+			},
+			want: ResourceNames{},
+		},
+		"set": {
+			env: map[string]string{
+				EnvSSMParameterName:               " /charity/last-sync-time ",
+				EnvBlackbaudRefreshTokenSecretARN: "arn:aws:secretsmanager:eu-west-2:123456789012:secret:charity/token-AbCdEf",
+				EnvTrackerTableName:               "charity-gifts",
+			},
+			want: ResourceNames{
+				LastSyncParameterName: "/charity/last-sync-time",
+				RefreshTokenSecretARN: "arn:aws:secretsmanager:eu-west-2:123456789012:secret:charity/token-AbCdEf",
+				TrackerTableName:      "charity-gifts",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			require.Equal(t, tc.want, LoadResourceNames())
+		})
+	}
+}
+
 func TestEnvOrDefault(t *testing.T) {
 	// Cannot use t.Parallel() with t.Setenv().
 	tests := map[string]struct {
@@ -3,72 +3,238 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	configDirName  = ".giftbridge"
-	configFileName = "config.yaml"
-	defaultType    = "Donation"
-	tokenFileName  = "token"
+	configDirName       = "giftbridge"
+	configFileName      = "config.yaml"
+	defaultType         = "Donation"
+	legacyConfigDirName = ".giftbridge"
+	tokenFileName       = "token"
 )
 
 // LocalConfig holds configuration loaded from a local file.
 type LocalConfig struct {
-	Blackbaud    localBlackbaudConfig
-	FundraiseUp  localFundraiseUpConfig
-	GiftDefaults GiftDefaults
+	Blackbaud           localBlackbaudConfig
+	CommentScrubbing    CommentScrubbing
+	ConstituentDefaults ConstituentDefaults
+	EmailNormalization  EmailNormalization
+	FundraiseUp         localFundraiseUpConfig
+	GiftDefaults        GiftDefaults
+	NameNormalization   NameNormalization
+	Proxy               Proxy
+	TLS                 TLS
+	UserAgent           UserAgent
 }
 
 // localBlackbaud represents the blackbaud section of the config file.
 type localBlackbaud struct {
-	ClientID        string `yaml:"client_id"`
-	ClientSecret    string `yaml:"client_secret"`
-	SubscriptionKey string `yaml:"subscription_key"`
+	ClientID                 string        `yaml:"client_id"`
+	ClientSecret             string        `yaml:"client_secret"`
+	GiftCacheSize            int           `yaml:"gift_cache_size"`
+	HedgeDelay               time.Duration `yaml:"hedge_delay"`
+	QuotaReserve             int           `yaml:"quota_reserve"`
+	SecondarySubscriptionKey string        `yaml:"secondary_subscription_key"`
+	SubscriptionKey          string        `yaml:"subscription_key"`
 }
 
 // localBlackbaudConfig holds Blackbaud credentials from the config file.
 type localBlackbaudConfig struct {
-	ClientID        string
-	ClientSecret    string
-	SubscriptionKey string
+	ClientID                 string
+	ClientSecret             string
+	GiftCacheSize            int
+	HedgeDelay               time.Duration
+	QuotaReserve             int
+	SecondarySubscriptionKey string
+	SubscriptionKey          string
 }
 
 // localConfig represents the local configuration file structure.
 type localConfig struct {
 	Blackbaud   localBlackbaud   `yaml:"blackbaud"`
+	Comments    localComments    `yaml:"comments"`
+	Constituent localConstituent `yaml:"constituent"`
+	Email       localEmail       `yaml:"email"`
 	FundraiseUp localFundraiseUp `yaml:"fundraiseup"`
 	Gift        localGift        `yaml:"gift"`
+	Names       localNames       `yaml:"names"`
+	Proxy       localProxy       `yaml:"proxy"`
+	TLS         localTLS         `yaml:"tls"`
+	UserAgent   localUserAgent   `yaml:"user_agent"`
+}
+
+// localComments represents the comments section of the config file.
+type localComments struct {
+	ScrubCardNumbers bool     `yaml:"scrub_card_numbers"`
+	ScrubPatterns    []string `yaml:"scrub_patterns"`
+	ScrubWords       []string `yaml:"scrub_words"`
+}
+
+// localConstituent represents the constituent section of the config file.
+type localConstituent struct {
+	AddresseeFormat    string           `yaml:"addressee_format"`
+	Codes              []string         `yaml:"codes"`
+	DonationNoteFormat string           `yaml:"donation_note_format"`
+	DonationNoteType   string           `yaml:"donation_note_type"`
+	Events             []localEventLink `yaml:"events"`
+	SalutationFormat   string           `yaml:"salutation_format"`
+}
+
+// localEventLink represents an event link in the constituent section of the config file.
+type localEventLink struct {
+	EventID            string `yaml:"event_id"`
+	FundraiseUpEventID string `yaml:"fundraiseup_event_id"`
+}
+
+// localEmail represents the email section of the config file.
+type localEmail struct {
+	AddMissing      bool `yaml:"add_missing"`
+	FoldGmail       bool `yaml:"fold_gmail"`
+	IncludeInactive bool `yaml:"include_inactive"`
+	StrictSearch    bool `yaml:"strict_search"`
+	StripPlusTags   bool `yaml:"strip_plus_tags"`
 }
 
 // localFundraiseUp represents the fundraiseup section of the config file.
 type localFundraiseUp struct {
+	Accounts        []localFundraiseUpAccount `yaml:"accounts"`
+	APIKey          string                    `yaml:"api_key"`
+	CampaignID      string                    `yaml:"campaign_id"`
+	CampaignWindows []localCampaignWindow     `yaml:"campaign_windows"`
+	FetchOverlap    time.Duration             `yaml:"fetch_overlap"`
+	PageSize        int                       `yaml:"page_size"`
+	Status          string                    `yaml:"status"`
+	StrictDecode    bool                      `yaml:"strict_decode"`
+}
+
+// localFundraiseUpAccount represents a further account in the fundraiseup section of the config file.
+type localFundraiseUpAccount struct {
 	APIKey string `yaml:"api_key"`
+	Name   string `yaml:"name"`
 }
 
-// localFundraiseUpConfig holds FundraiseUp credentials from the config file.
+// localCampaignWindow represents a campaign window in the fundraiseup section of the config file.
+type localCampaignWindow struct {
+	CampaignID string    `yaml:"campaign_id"`
+	Exclude    bool      `yaml:"exclude"`
+	From       time.Time `yaml:"from"`
+	Until      time.Time `yaml:"until"`
+}
+
+// localFundraiseUpConfig holds FundraiseUp credentials and donation filters from the config file.
 type localFundraiseUpConfig struct {
-	APIKey string
+	Accounts        []FundraiseUpAccount
+	APIKey          string
+	CampaignID      string
+	CampaignWindows []CampaignWindow
+	FetchOverlap    time.Duration
+	PageSize        int
+	Status          string
+	StrictDecode    bool
 }
 
 // localGift represents the gift section of the config file.
 type localGift struct {
-	AppealID   string `yaml:"appeal_id"`
-	CampaignID string `yaml:"campaign_id"`
-	FundID     string `yaml:"fund_id"`
-	Type       string `yaml:"type"`
+	AppealID           string              `yaml:"appeal_id"`
+	AppealResponses    bool                `yaml:"appeal_responses"`
+	CampaignID         string              `yaml:"campaign_id"`
+	Checks             []localGiftCheck    `yaml:"checks"`
+	CountryRoutes      []localCountryRoute `yaml:"country_routes"`
+	CreatorCustomField string              `yaml:"creator_custom_field"`
+	DatePolicy         string              `yaml:"date_policy"`
+	FundID             string              `yaml:"fund_id"`
+	MaxAgeDays         int                 `yaml:"max_age_days"`
+	PostDate           string              `yaml:"post_date"`
+	PostStatus         string              `yaml:"post_status"`
+	RecurringMode      string              `yaml:"recurring_mode"`
+	ReferenceField     string              `yaml:"reference_field"`
+	ReferenceNoteType  string              `yaml:"reference_note_type"`
+	Rules              []localGiftRule     `yaml:"rules"`
+	Splits             []localGiftSplit    `yaml:"splits"`
+	TestDonations      string              `yaml:"test_donations"`
+	TestFundID         string              `yaml:"test_fund_id"`
+	Type               string              `yaml:"type"`
+}
+
+// localGiftCheck represents a check in the gift section of the config file.
+type localGiftCheck struct {
+	Assert  string `yaml:"assert"`
+	Message string `yaml:"message"`
+}
+
+// localCountryRoute represents a country route in the gift section of the config file.
+type localCountryRoute struct {
+	AppealID   string   `yaml:"appeal_id"`
+	CampaignID string   `yaml:"campaign_id"`
+	Countries  []string `yaml:"countries"`
+	FundID     string   `yaml:"fund_id"`
+}
+
+// localGiftRule represents a rule in the gift section of the config file.
+type localGiftRule struct {
+	Field string `yaml:"field"`
+	Value string `yaml:"value"`
+	When  string `yaml:"when"`
+}
+
+// localGiftSplit represents a split in the gift section of the config file.
+type localGiftSplit struct {
+	Amount  float64 `yaml:"amount"`
+	FundID  string  `yaml:"fund_id"`
+	Percent float64 `yaml:"percent"`
 }
 
-// ConfigDir returns the giftbridge configuration directory path.
+// localNames represents the names section of the config file.
+type localNames struct {
+	TitleCase     bool `yaml:"title_case"`
+	Transliterate bool `yaml:"transliterate"`
+}
+
+// localProxy represents the proxy section of the config file.
+type localProxy struct {
+	Bypass []string `yaml:"bypass"`
+	URL    string   `yaml:"url"`
+}
+
+// localTLS represents the tls section of the config file.
+type localTLS struct {
+	CABundle   string `yaml:"ca_bundle"`
+	ClientCert string `yaml:"client_cert"`
+	ClientKey  string `yaml:"client_key"`
+}
+
+// localUserAgent represents the user_agent section of the config file.
+type localUserAgent struct {
+	Organization string `yaml:"organization"`
+}
+
+// ConfigDir returns the giftbridge configuration directory path: GIFTBRIDGE_CONFIG_DIR when set, otherwise a
+// giftbridge directory in the user config directory, such as $XDG_CONFIG_HOME (default ~/.config) on Linux or
+// %APPDATA% on Windows. A ~/.giftbridge directory left by earlier versions is moved there on first use.
 func ConfigDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv(EnvConfigDir)); dir != "" {
+		return dir, nil
+	}
+
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("getting user config directory (set %s to choose one): %w", EnvConfigDir, err)
+	}
+	dir := filepath.Join(base, configDirName)
+
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("getting home directory: %w", err)
+		// Without a home directory there is no legacy directory to move.
+		return dir, nil
 	}
-	return filepath.Join(home, configDirName), nil
+	return migrateConfigDir(filepath.Join(home, legacyConfigDirName), dir), nil
 }
 
 // ConfigFilePath returns the path to the local config file.
@@ -87,6 +253,12 @@ func LoadLocal() (*LocalConfig, error) {
 		return nil, err
 	}
 
+	return LoadLocalFile(configPath)
+}
+
+// LoadLocalFile loads configuration from the config file at configPath.
+// Environment variables that are set override the values in the file.
+func LoadLocalFile(configPath string) (*LocalConfig, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -103,16 +275,119 @@ func LoadLocal() (*LocalConfig, error) {
 	cfg := &LocalConfig{}
 	cfg.Blackbaud.ClientID = local.Blackbaud.ClientID
 	cfg.Blackbaud.ClientSecret = local.Blackbaud.ClientSecret
+	cfg.Blackbaud.GiftCacheSize = local.Blackbaud.GiftCacheSize
+	cfg.Blackbaud.HedgeDelay = local.Blackbaud.HedgeDelay
+	cfg.Blackbaud.QuotaReserve = local.Blackbaud.QuotaReserve
+	cfg.Blackbaud.SecondarySubscriptionKey = strings.TrimSpace(local.Blackbaud.SecondarySubscriptionKey)
 	cfg.Blackbaud.SubscriptionKey = local.Blackbaud.SubscriptionKey
+	cfg.CommentScrubbing.CardNumbers = local.Comments.ScrubCardNumbers
+	cfg.CommentScrubbing.Patterns = local.Comments.ScrubPatterns
+	cfg.CommentScrubbing.Words = local.Comments.ScrubWords
+	cfg.ConstituentDefaults.AddresseeFormat = local.Constituent.AddresseeFormat
+	cfg.ConstituentDefaults.Codes = local.Constituent.Codes
+	cfg.ConstituentDefaults.DonationNoteFormat = local.Constituent.DonationNoteFormat
+	cfg.ConstituentDefaults.DonationNoteType = strings.TrimSpace(local.Constituent.DonationNoteType)
+	for _, link := range local.Constituent.Events {
+		cfg.ConstituentDefaults.Events = append(cfg.ConstituentDefaults.Events, EventLink{
+			EventID:            strings.TrimSpace(link.EventID),
+			FundraiseUpEventID: strings.TrimSpace(link.FundraiseUpEventID),
+		})
+	}
+	cfg.ConstituentDefaults.SalutationFormat = local.Constituent.SalutationFormat
+	cfg.EmailNormalization.AddMissing = local.Email.AddMissing
+	cfg.EmailNormalization.FoldGmail = local.Email.FoldGmail
+	cfg.EmailNormalization.IncludeInactive = local.Email.IncludeInactive
+	cfg.EmailNormalization.StrictSearch = local.Email.StrictSearch
+	cfg.EmailNormalization.StripPlusTags = local.Email.StripPlusTags
+	for _, account := range local.FundraiseUp.Accounts {
+		cfg.FundraiseUp.Accounts = append(cfg.FundraiseUp.Accounts, FundraiseUpAccount{
+			APIKey: strings.TrimSpace(account.APIKey),
+			Name:   strings.TrimSpace(account.Name),
+		})
+	}
 	cfg.FundraiseUp.APIKey = local.FundraiseUp.APIKey
+	cfg.FundraiseUp.CampaignID = strings.TrimSpace(local.FundraiseUp.CampaignID)
+	for _, window := range local.FundraiseUp.CampaignWindows {
+		cfg.FundraiseUp.CampaignWindows = append(cfg.FundraiseUp.CampaignWindows, CampaignWindow{
+			CampaignID: strings.TrimSpace(window.CampaignID),
+			Exclude:    window.Exclude,
+			From:       window.From,
+			Until:      window.Until,
+		})
+	}
+	cfg.FundraiseUp.FetchOverlap = local.FundraiseUp.FetchOverlap
+	cfg.FundraiseUp.PageSize = local.FundraiseUp.PageSize
+	cfg.FundraiseUp.Status = strings.TrimSpace(local.FundraiseUp.Status)
+	cfg.FundraiseUp.StrictDecode = local.FundraiseUp.StrictDecode
 	cfg.GiftDefaults.AppealID = local.Gift.AppealID
+	cfg.GiftDefaults.AppealResponses = local.Gift.AppealResponses
 	cfg.GiftDefaults.CampaignID = local.Gift.CampaignID
+	cfg.GiftDefaults.CreatorCustomField = strings.TrimSpace(local.Gift.CreatorCustomField)
+	cfg.GiftDefaults.DatePolicy = strings.TrimSpace(local.Gift.DatePolicy)
 	cfg.GiftDefaults.FundID = local.Gift.FundID
+	cfg.GiftDefaults.MaxAgeDays = local.Gift.MaxAgeDays
+	cfg.GiftDefaults.PostDate = strings.TrimSpace(local.Gift.PostDate)
+	cfg.GiftDefaults.PostStatus = strings.TrimSpace(local.Gift.PostStatus)
+	cfg.GiftDefaults.RecurringMode = strings.TrimSpace(local.Gift.RecurringMode)
+	cfg.GiftDefaults.ReferenceField = strings.TrimSpace(local.Gift.ReferenceField)
+	cfg.GiftDefaults.ReferenceNoteType = strings.TrimSpace(local.Gift.ReferenceNoteType)
+	cfg.GiftDefaults.TestDonations = strings.TrimSpace(local.Gift.TestDonations)
+	cfg.GiftDefaults.TestFundID = strings.TrimSpace(local.Gift.TestFundID)
 	cfg.GiftDefaults.Type = local.Gift.Type
+	for _, rule := range local.Gift.Rules {
+		cfg.GiftDefaults.Rules = append(cfg.GiftDefaults.Rules, GiftRule{
+			Field: strings.TrimSpace(rule.Field),
+			Value: rule.Value,
+			When:  rule.When,
+		})
+	}
+	for _, check := range local.Gift.Checks {
+		cfg.GiftDefaults.Checks = append(cfg.GiftDefaults.Checks, GiftCheck{
+			Assert:  check.Assert,
+			Message: strings.TrimSpace(check.Message),
+		})
+	}
+	for _, route := range local.Gift.CountryRoutes {
+		cfg.GiftDefaults.CountryRoutes = append(cfg.GiftDefaults.CountryRoutes, CountryRoute{
+			AppealID:   strings.TrimSpace(route.AppealID),
+			CampaignID: strings.TrimSpace(route.CampaignID),
+			Countries:  route.Countries,
+			FundID:     strings.TrimSpace(route.FundID),
+		})
+	}
+	for _, split := range local.Gift.Splits {
+		cfg.GiftDefaults.Splits = append(cfg.GiftDefaults.Splits, GiftSplit{
+			Amount:  split.Amount,
+			FundID:  strings.TrimSpace(split.FundID),
+			Percent: split.Percent,
+		})
+	}
+	cfg.NameNormalization.TitleCase = local.Names.TitleCase
+	cfg.NameNormalization.Transliterate = local.Names.Transliterate
+	cfg.Proxy.Bypass = local.Proxy.Bypass
+	cfg.Proxy.URL = strings.TrimSpace(local.Proxy.URL)
+	cfg.TLS.CABundle = strings.TrimSpace(local.TLS.CABundle)
+	cfg.TLS.ClientCert = strings.TrimSpace(local.TLS.ClientCert)
+	cfg.TLS.ClientKey = strings.TrimSpace(local.TLS.ClientKey)
+	cfg.UserAgent.Organization = strings.TrimSpace(local.UserAgent.Organization)
+
+	// Environment variables take precedence over the file, and both over the defaults below.
+	if err := cfg.overrideFromEnv(); err != nil {
+		return nil, fmt.Errorf("reading environment overrides: %w", err)
+	}
 
 	if cfg.GiftDefaults.Type == "" {
 		cfg.GiftDefaults.Type = defaultType
 	}
+	if cfg.GiftDefaults.ReferenceField == "" {
+		cfg.GiftDefaults.ReferenceField = GiftReferenceFieldLookupID
+	}
+	if cfg.GiftDefaults.TestDonations == "" {
+		cfg.GiftDefaults.TestDonations = TestDonationsSkip
+	}
+	if cfg.FundraiseUp.PageSize == 0 {
+		cfg.FundraiseUp.PageSize = DefaultFundraiseUpPageSize
+	}
 
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
@@ -121,6 +396,26 @@ func LoadLocal() (*LocalConfig, error) {
 	return cfg, nil
 }
 
+// migrateConfigDir moves the legacy config directory to dir when only the legacy one exists, so the config file
+// and refresh token saved by earlier versions are kept. It returns the directory to use, which is the legacy one
+// if it could not be moved.
+func migrateConfigDir(legacy string, dir string) string {
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		return dir
+	}
+	if info, err := os.Stat(legacy); err != nil || !info.IsDir() {
+		return dir
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o700); err != nil {
+		return legacy
+	}
+	if err := os.Rename(legacy, dir); err != nil {
+		return legacy
+	}
+	return dir
+}
+
 // LocalConfigExists checks if a local config file exists.
 func LocalConfigExists() bool {
 	configPath, err := ConfigFilePath()
@@ -150,15 +445,109 @@ func (c *LocalConfig) validate() error {
 	if c.Blackbaud.ClientSecret == "" {
 		errs = append(errs, errors.New("blackbaud.client_secret is required"))
 	}
+	if c.Blackbaud.GiftCacheSize < 0 {
+		errs = append(errs, errors.New("blackbaud.gift_cache_size must not be negative"))
+	}
+	if c.Blackbaud.HedgeDelay < 0 {
+		errs = append(errs, errors.New("blackbaud.hedge_delay must not be negative"))
+	}
+	if c.Blackbaud.QuotaReserve < 0 {
+		errs = append(errs, errors.New("blackbaud.quota_reserve must not be negative"))
+	}
 	if c.Blackbaud.SubscriptionKey == "" {
 		errs = append(errs, errors.New("blackbaud.subscription_key is required"))
 	}
+	if err := validatePatterns(c.CommentScrubbing.Patterns, "comments.scrub_patterns"); err != nil {
+		errs = append(errs, err)
+	}
 	if c.FundraiseUp.APIKey == "" {
 		errs = append(errs, errors.New("fundraiseup.api_key is required"))
 	}
+	if err := validateFundraiseUpAccounts(c.FundraiseUp.Accounts, "fundraiseup.accounts"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateCampaignWindows(c.FundraiseUp.CampaignWindows, "fundraiseup.campaign_windows"); err != nil {
+		errs = append(errs, err)
+	}
+	if c.FundraiseUp.FetchOverlap < 0 {
+		errs = append(errs, errors.New("fundraiseup.fetch_overlap must not be negative"))
+	}
+	if c.FundraiseUp.PageSize < 1 || c.FundraiseUp.PageSize > MaxFundraiseUpPageSize {
+		errs = append(errs, fmt.Errorf("fundraiseup.page_size must be between 1 and %d", MaxFundraiseUpPageSize))
+	}
 	if c.GiftDefaults.FundID == "" {
 		errs = append(errs, errors.New("gift.fund_id is required"))
 	}
+	if err := validatePosting(
+		c.GiftDefaults.PostStatus,
+		c.GiftDefaults.PostDate,
+		"gift.post_status",
+		"gift.post_date",
+	); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateRecurringMode(c.GiftDefaults.RecurringMode, "gift.recurring_mode"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateReferenceField(c.GiftDefaults.ReferenceField, "gift.reference_field"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateGiftType(c.GiftDefaults.Type, "gift.type"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateGiftRules(c.GiftDefaults.Rules, "gift.rules"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateGiftChecks(c.GiftDefaults.Checks, "gift.checks"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateGiftSplits(c.GiftDefaults.Splits, "gift.splits"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateCountryRoutes(c.GiftDefaults.CountryRoutes, "gift.country_routes"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateGiftDatePolicy(
+		c.GiftDefaults.DatePolicy,
+		c.GiftDefaults.MaxAgeDays,
+		"gift.date_policy",
+		"gift.max_age_days",
+	); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateTestDonations(
+		c.GiftDefaults.TestDonations,
+		c.GiftDefaults.TestFundID,
+		"gift.test_donations",
+		"gift.test_fund_id",
+	); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateEventLinks(c.ConstituentDefaults.Events, "constituent.events"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateNameFormat(c.ConstituentDefaults.AddresseeFormat, "constituent.addressee_format"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateNameFormat(c.ConstituentDefaults.SalutationFormat, "constituent.salutation_format"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateDonationNote(
+		c.ConstituentDefaults,
+		"constituent.donation_note_format",
+		"constituent.donation_note_type",
+	); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateProxy(c.Proxy, "proxy.url", "proxy.bypass"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateTLS(c.TLS, "tls.ca_bundle", "tls.client_cert", "tls.client_key"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateOrganization(c.UserAgent.Organization, "user_agent.organization"); err != nil {
+		errs = append(errs, err)
+	}
 
 	return errors.Join(errs...)
 }
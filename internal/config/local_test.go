@@ -1,42 +1,131 @@
 package config
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
-	"gopkg.in/yaml.v3"
 )
 
 func TestConfigDir(t *testing.T) {
-	t.Parallel()
+	// Cannot use t.Parallel() with t.Setenv().
+
+	dir := t.TempDir()
+	t.Setenv(EnvConfigDir, dir)
 
-	dir, err := ConfigDir()
+	got, err := ConfigDir()
 
 	require.NoError(t, err)
-	require.Contains(t, dir, ".giftbridge")
+	require.Equal(t, dir, got)
 }
 
-func TestConfigFilePath(t *testing.T) {
+func TestConfigDirDefault(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv().
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	t.Setenv(EnvConfigDir, "")
+	base, err := os.UserConfigDir()
+	require.NoError(t, err)
+
+	got, err := ConfigDir()
+
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(base, "giftbridge"), got)
+}
+
+func TestConfigDirMigratesLegacy(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv().
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	t.Setenv(EnvConfigDir, "")
+	legacy := filepath.Join(home, ".giftbridge")
+	require.NoError(t, os.MkdirAll(legacy, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "token"), []byte("refresh-token"), 0o600))
+
+	got, err := ConfigDir()
+
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(got, "token"))
+	require.NoError(t, err)
+	require.Equal(t, "refresh-token", string(data))
+	_, err = os.Stat(legacy)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestMigrateConfigDir(t *testing.T) {
 	t.Parallel()
 
+	tests := map[string]struct {
+		dirExists    bool
+		legacyExists bool
+	}{
+		"no directories": {},
+		"legacy only is moved": {
+			legacyExists: true,
+		},
+		"new directory is kept": {
+			legacyExists: true,
+			dirExists:    true,
+		},
+		"new directory only": {
+			dirExists: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			legacy := filepath.Join(root, ".giftbridge")
+			dir := filepath.Join(root, "config", "giftbridge")
+			if tc.legacyExists {
+				require.NoError(t, os.MkdirAll(legacy, 0o700))
+				require.NoError(t, os.WriteFile(filepath.Join(legacy, "config.yaml"), []byte("legacy"), 0o600))
+			}
+			if tc.dirExists {
+				require.NoError(t, os.MkdirAll(dir, 0o700))
+			}
+
+			got := migrateConfigDir(legacy, dir)
+
+			require.Equal(t, dir, got)
+			_, err := os.Stat(filepath.Join(dir, "config.yaml"))
+			require.Equal(t, tc.legacyExists && !tc.dirExists, err == nil)
+			_, err = os.Stat(legacy)
+			require.Equal(t, tc.legacyExists && tc.dirExists, err == nil)
+		})
+	}
+}
+
+func TestConfigFilePath(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv().
+
+	dir := t.TempDir()
+	t.Setenv(EnvConfigDir, dir)
+
 	path, err := ConfigFilePath()
 
 	require.NoError(t, err)
-	require.Contains(t, path, ".giftbridge")
-	require.Contains(t, path, "config.yaml")
+	require.Equal(t, filepath.Join(dir, "config.yaml"), path)
 }
 
 func TestTokenFilePath(t *testing.T) {
-	t.Parallel()
+	// Cannot use t.Parallel() with t.Setenv().
+
+	dir := t.TempDir()
+	t.Setenv(EnvConfigDir, dir)
 
 	path, err := TokenFilePath()
 
 	require.NoError(t, err)
-	require.Contains(t, path, ".giftbridge")
-	require.Contains(t, path, "token")
+	require.Equal(t, filepath.Join(dir, "token"), path)
 }
 
 func TestLocalConfigValidate(t *testing.T) {
@@ -55,7 +144,8 @@ func TestLocalConfigValidate(t *testing.T) {
 					SubscriptionKey: "sub-key",
 				},
 				FundraiseUp: localFundraiseUpConfig{
-					APIKey: "api-key",
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
 				},
 				GiftDefaults: GiftDefaults{
 					FundID: "fund-123",
@@ -64,6 +154,162 @@ func TestLocalConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"page size out of range": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: 101,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID: "fund-123",
+				},
+			},
+			wantErr:      true,
+			errFragments: []string{"fundraiseup.page_size must be between 1 and 100"},
+		},
+		"invalid gift posting": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID:     "fund-123",
+					PostDate:   GiftPostDateSync,
+					PostStatus: "Posted",
+				},
+			},
+			wantErr: true,
+			errFragments: []string{
+				"gift.post_status must be NotPosted or DoNotPost",
+				"gift.post_date requires gift.post_status to be NotPosted",
+			},
+		},
+		"invalid gift reference field": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID:         "fund-123",
+					ReferenceField: "custom_field",
+				},
+			},
+			wantErr:      true,
+			errFragments: []string{"gift.reference_field must be lookup_id or origin"},
+		},
+		"invalid gift rule": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID: "fund-123",
+					Rules:  []GiftRule{{Field: "fund_id", Value: "'a' +"}},
+				},
+			},
+			wantErr:      true,
+			errFragments: []string{"gift.rules rule 1: value: Syntax error: mismatched input '<EOF>'"},
+		},
+		"invalid gift checks": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					Checks: []GiftCheck{{Assert: "donation.amount"}},
+					FundID: "fund-123",
+				},
+			},
+			wantErr:      true,
+			errFragments: []string{"gift.checks check 1: assert must be true or false, got a number"},
+		},
+		"invalid gift splits": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					FundID: "fund-123",
+					Splits: []GiftSplit{{FundID: "admin", Percent: 60}, {Percent: 40}},
+				},
+			},
+			wantErr: true,
+			errFragments: []string{
+				"gift.splits split 2: fund_id is required",
+				"gift.splits percentages must total less than 100",
+			},
+		},
+		"invalid country routes": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{
+					CountryRoutes: []CountryRoute{{Countries: []string{"GB"}}},
+					FundID:        "fund-123",
+				},
+			},
+			wantErr:      true,
+			errFragments: []string{"gift.country_routes route 1: fund_id, campaign_id or appeal_id is required"},
+		},
+		"event link without event": {
+			config: LocalConfig{
+				Blackbaud: localBlackbaudConfig{
+					ClientID:        "client-id",
+					ClientSecret:    "client-secret",
+					SubscriptionKey: "sub-key",
+				},
+				ConstituentDefaults: ConstituentDefaults{Events: []EventLink{{FundraiseUpEventID: "evt_gala"}}},
+				FundraiseUp: localFundraiseUpConfig{
+					APIKey:   "api-key",
+					PageSize: DefaultFundraiseUpPageSize,
+				},
+				GiftDefaults: GiftDefaults{FundID: "fund-123"},
+			},
+			wantErr:      true,
+			errFragments: []string{"constituent.events event 1: event_id is required"},
+		},
 		"missing all required fields": {
 			config:  LocalConfig{},
 			wantErr: true,
@@ -130,6 +376,7 @@ gift:
   fund_id: "fund-123"
   campaign_id: "campaign-456"
   appeal_id: "appeal-789"
+  appeal_responses: true
   type: "Donation"
 `,
 			wantErr: false,
@@ -142,58 +389,770 @@ gift:
 				require.Equal(t, "fund-123", cfg.GiftDefaults.FundID)
 				require.Equal(t, "campaign-456", cfg.GiftDefaults.CampaignID)
 				require.Equal(t, "appeal-789", cfg.GiftDefaults.AppealID)
+				require.True(t, cfg.GiftDefaults.AppealResponses)
+				require.Equal(t, GiftReferenceFieldLookupID, cfg.GiftDefaults.ReferenceField)
+				require.Equal(t, TestDonationsSkip, cfg.GiftDefaults.TestDonations)
 				require.Equal(t, "Donation", cfg.GiftDefaults.Type)
 			},
 		},
-		"defaults type to Donation when empty": {
+		"email and name normalization": {
 			content: `
 blackbaud:
   client_id: "test-client-id"
   client_secret: "test-client-secret"
   subscription_key: "test-sub-key"
+email:
+  add_missing: true
+  fold_gmail: true
+  include_inactive: true
+  strict_search: true
+  strip_plus_tags: true
 fundraiseup:
   api_key: "test-api-key"
 gift:
   fund_id: "fund-123"
+names:
+  title_case: true
+  transliterate: true
 `,
 			wantErr: false,
 			validateCfg: func(t *testing.T, cfg *LocalConfig) {
 				t.Helper()
-				require.Equal(t, "Donation", cfg.GiftDefaults.Type)
+				require.Equal(t, EmailNormalization{
+					AddMissing:      true,
+					FoldGmail:       true,
+					IncludeInactive: true,
+					StrictSearch:    true,
+					StripPlusTags:   true,
+				}, cfg.EmailNormalization)
+				require.Equal(t, NameNormalization{TitleCase: true, Transliterate: true}, cfg.NameNormalization)
 			},
 		},
-		"invalid yaml": {
-			content:     `invalid: yaml: content: [}`,
+		"comment scrubbing": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+comments:
+  scrub_card_numbers: true
+  scrub_patterns:
+    - '\d+ [A-Z][a-z]+ (Road|Street)'
+  scrub_words: ["darn"]
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, CommentScrubbing{
+					CardNumbers: true,
+					Patterns:    []string{`\d+ [A-Z][a-z]+ (Road|Street)`},
+					Words:       []string{"darn"},
+				}, cfg.CommentScrubbing)
+			},
+		},
+		"proxy": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+proxy:
+  url: " http://proxy.internal:3128 "
+  bypass: ["localstack"]
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, Proxy{Bypass: []string{"localstack"}, URL: "http://proxy.internal:3128"}, cfg.Proxy)
+			},
+		},
+		"tls": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+tls:
+  ca_bundle: "/etc/ssl/giftbridge-ca.pem"
+  client_cert: "/etc/ssl/client.pem"
+  client_key: "/etc/ssl/client-key.pem"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, TLS{
+					CABundle:   "/etc/ssl/giftbridge-ca.pem",
+					ClientCert: "/etc/ssl/client.pem",
+					ClientKey:  "/etc/ssl/client-key.pem",
+				}, cfg.TLS)
+			},
+		},
+		"tls client key without certificate": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+tls:
+  client_key: "/etc/ssl/client-key.pem"
+`,
 			wantErr:     true,
-			errContains: "parsing config",
+			errContains: "tls.client_cert and tls.client_key must be set together",
 		},
-		"missing required fields": {
+		"user agent organization": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+user_agent:
+  organization: " st-marys-hospice "
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, "st-marys-hospice", cfg.UserAgent.Organization)
+			},
+		},
+		"user agent organization with parentheses": {
 			content: `
 blackbaud:
   client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+user_agent:
+  organization: "St Mary's (Hospice)"
 `,
 			wantErr:     true,
-			errContains: "invalid config",
+			errContains: "user_agent.organization must contain only printable ASCII characters other than parentheses",
 		},
-	}
-
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-
-			dir := t.TempDir()
-			configPath := filepath.Join(dir, "config.yaml")
-			require.NoError(t, os.WriteFile(configPath, []byte(tc.content), 0o600))
-
-			cfg, err := loadLocalFromPath(configPath)
-
-			if tc.wantErr {
-				require.Error(t, err)
-				if tc.errContains != "" {
-					require.Contains(t, err.Error(), tc.errContains)
-				}
-			} else {
-				require.NoError(t, err)
+		"proxy bypass without proxy URL": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+proxy:
+  bypass: ["localstack"]
+`,
+			wantErr:     true,
+			errContains: "proxy.bypass requires proxy.url",
+		},
+		"invalid comment pattern": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+comments:
+  scrub_patterns: ["(unclosed"]
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr:     true,
+			errContains: "comments.scrub_patterns pattern 1: error parsing regexp",
+		},
+		"misspelled gift type": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  type: "donation"
+`,
+			wantErr:     true,
+			errContains: `gift.type must be spelled "Donation", not "donation"`,
+		},
+		"invalid salutation format": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+constituent:
+  salutation_format: "Dear {{.FirstName"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr:     true,
+			errContains: "constituent.salutation_format: template: constituent.salutation_format:1: unclosed action",
+		},
+		"constituent codes": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+constituent:
+  addressee_format: "{{.FirstName}} {{.LastName}}"
+  codes:
+    - "Online Donor"
+    - "Newsletter"
+  donation_note_format: "Donated to {{.Campaign}}"
+  donation_note_type: " Online giving "
+  events:
+    - fundraiseup_event_id: " evt_gala "
+      event_id: "42"
+  salutation_format: "Dear {{.FirstName}}"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, []string{"Online Donor", "Newsletter"}, cfg.ConstituentDefaults.Codes)
+				require.Equal(
+					t,
+					[]EventLink{{EventID: "42", FundraiseUpEventID: "evt_gala"}},
+					cfg.ConstituentDefaults.Events,
+				)
+				require.Equal(t, "{{.FirstName}} {{.LastName}}", cfg.ConstituentDefaults.AddresseeFormat)
+				require.Equal(t, "Dear {{.FirstName}}", cfg.ConstituentDefaults.SalutationFormat)
+				require.Equal(t, "Donated to {{.Campaign}}", cfg.ConstituentDefaults.DonationNoteFormat)
+				require.Equal(t, "Online giving", cfg.ConstituentDefaults.DonationNoteType)
+			},
+		},
+		"donation note format without note type": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+constituent:
+  donation_note_format: "Donated to {{.Campaign}}"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr:     true,
+			errContains: "constituent.donation_note_format requires constituent.donation_note_type",
+		},
+		"defaults type to Donation when empty": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, "Donation", cfg.GiftDefaults.Type)
+				require.Equal(t, DefaultFundraiseUpPageSize, cfg.FundraiseUp.PageSize)
+			},
+		},
+		"fundraiseup page size and filters": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+  campaign_id: "FUNCAMP1"
+  fetch_overlap: "15m"
+  page_size: 25
+  status: "succeeded"
+  strict_decode: true
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, "FUNCAMP1", cfg.FundraiseUp.CampaignID)
+				require.Equal(t, 15*time.Minute, cfg.FundraiseUp.FetchOverlap)
+				require.Equal(t, 25, cfg.FundraiseUp.PageSize)
+				require.Equal(t, "succeeded", cfg.FundraiseUp.Status)
+				require.True(t, cfg.FundraiseUp.StrictDecode)
+			},
+		},
+		"fundraiseup accounts": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+  accounts:
+    - name: " trading "
+      api_key: "trading-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, []FundraiseUpAccount{{APIKey: "trading-api-key", Name: "trading"}},
+					cfg.FundraiseUp.Accounts)
+			},
+		},
+		"fundraiseup account without a name": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+  accounts:
+    - api_key: "trading-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr:     true,
+			errContains: "fundraiseup.accounts account 1: name is required",
+		},
+		"fundraiseup campaign windows": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+  campaign_windows:
+    - campaign_id: " FUNSPRING "
+      from: 2025-03-01T00:00:00Z
+    - campaign_id: "FUNLEGACY"
+      exclude: true
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, []CampaignWindow{
+					{CampaignID: "FUNSPRING", From: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)},
+					{CampaignID: "FUNLEGACY", Exclude: true},
+				}, cfg.FundraiseUp.CampaignWindows)
+			},
+		},
+		"fundraiseup campaign window ending before it starts": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+  campaign_windows:
+    - campaign_id: "FUNSPRING"
+      from: 2025-03-01T00:00:00Z
+      until: 2025-02-01T00:00:00Z
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr:     true,
+			errContains: "fundraiseup.campaign_windows window 1: until must be after from",
+		},
+		"hedge delay": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  hedge_delay: "2s"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, 2*time.Second, cfg.Blackbaud.HedgeDelay)
+			},
+		},
+		"gift cache size": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  gift_cache_size: 250
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, 250, cfg.Blackbaud.GiftCacheSize)
+			},
+		},
+		"negative gift cache size": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  gift_cache_size: -1
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr: true,
+		},
+		"gift posting": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  post_date: "sync"
+  post_status: "NotPosted"
+  reference_field: "origin"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, GiftPostDateSync, cfg.GiftDefaults.PostDate)
+				require.Equal(t, GiftPostStatusNotPosted, cfg.GiftDefaults.PostStatus)
+				require.Equal(t, GiftReferenceFieldOrigin, cfg.GiftDefaults.ReferenceField)
+			},
+		},
+		"flat recurring mode": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  recurring_mode: " flat "
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, GiftRecurringModeFlat, cfg.GiftDefaults.RecurringMode)
+			},
+		},
+		"invalid recurring mode": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  recurring_mode: "series"
+`,
+			wantErr:     true,
+			errContains: "gift.recurring_mode must be linked or flat",
+		},
+		"test donations": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  test_donations: "sync"
+  test_fund_id: " sandbox "
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, TestDonationsSync, cfg.GiftDefaults.TestDonations)
+				require.Equal(t, "sandbox", cfg.GiftDefaults.TestFundID)
+			},
+		},
+		"gift date policy": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  date_policy: " flag "
+  max_age_days: 730
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, GiftDatePolicyFlag, cfg.GiftDefaults.DatePolicy)
+				require.Equal(t, 730, cfg.GiftDefaults.MaxAgeDays)
+			},
+		},
+		"gift creator custom field": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  creator_custom_field: " Created by "
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, "Created by", cfg.GiftDefaults.CreatorCustomField)
+			},
+		},
+		"gift reference note type": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  reference_note_type: " Comment "
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, "Comment", cfg.GiftDefaults.ReferenceNoteType)
+			},
+		},
+		"negative gift max age": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  date_policy: "refuse"
+  max_age_days: -1
+`,
+			wantErr:     true,
+			errContains: "gift.max_age_days must not be negative",
+		},
+		"test fund without syncing test donations": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  test_fund_id: "sandbox"
+`,
+			wantErr:     true,
+			errContains: "gift.test_fund_id requires gift.test_donations to be sync",
+		},
+		"gift rules": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  rules:
+    - field: reference
+      value: "'FRU ' + donation.id"
+    - field: " fund_id "
+      when: "donation.amount >= 1000"
+      value: "'major'"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, []GiftRule{
+					{Field: "reference", Value: "'FRU ' + donation.id"},
+					{Field: "fund_id", Value: "'major'", When: "donation.amount >= 1000"},
+				}, cfg.GiftDefaults.Rules)
+			},
+		},
+		"gift checks": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  checks:
+    - assert: "donation.amount <= 100000.0"
+      message: " Amount over 100,000 "
+    - assert: "gift.fund_id != 'BUILD' || gift.campaign_id == 'CAPITAL'"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, []GiftCheck{
+					{Assert: "donation.amount <= 100000.0", Message: "Amount over 100,000"},
+					{Assert: "gift.fund_id != 'BUILD' || gift.campaign_id == 'CAPITAL'"},
+				}, cfg.GiftDefaults.Checks)
+			},
+		},
+		"gift splits": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  splits:
+    - fund_id: "appeal"
+      amount: 50
+    - fund_id: " admin "
+      percent: 10
+    - fund_id: "reserves"
+      percent: 2.5
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, []GiftSplit{
+					{Amount: 50, FundID: "appeal"},
+					{FundID: "admin", Percent: 10},
+					{FundID: "reserves", Percent: 2.5},
+				}, cfg.GiftDefaults.Splits)
+			},
+		},
+		"country routes": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+gift:
+  fund_id: "fund-123"
+  country_routes:
+    - countries: ["GB", "Isle of Man"]
+      fund_id: " gift-aid "
+    - countries: ["US"]
+      fund_id: "us-501c3"
+      appeal_id: "us-appeal"
+`,
+			wantErr: false,
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, []CountryRoute{
+					{Countries: []string{"GB", "Isle of Man"}, FundID: "gift-aid"},
+					{AppealID: "us-appeal", Countries: []string{"US"}, FundID: "us-501c3"},
+				}, cfg.GiftDefaults.CountryRoutes)
+			},
+		},
+		"invalid page size": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+  client_secret: "test-client-secret"
+  subscription_key: "test-sub-key"
+fundraiseup:
+  api_key: "test-api-key"
+  page_size: -1
+gift:
+  fund_id: "fund-123"
+`,
+			wantErr:     true,
+			errContains: "fundraiseup.page_size must be between 1 and 100",
+		},
+		"invalid yaml": {
+			content:     `invalid: yaml: content: [}`,
+			wantErr:     true,
+			errContains: "parsing config",
+		},
+		"missing required fields": {
+			content: `
+blackbaud:
+  client_id: "test-client-id"
+`,
+			wantErr:     true,
+			errContains: "invalid config",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			configPath := filepath.Join(dir, "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tc.content), 0o600))
+
+			cfg, err := LoadLocalFile(configPath)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.errContains != "" {
+					require.Contains(t, err.Error(), tc.errContains)
+				}
+			} else {
+				require.NoError(t, err)
 				require.NotNil(t, cfg)
 				if tc.validateCfg != nil {
 					tc.validateCfg(t, cfg)
@@ -203,58 +1162,100 @@ blackbaud:
 	}
 }
 
+func TestLoadLocalFileEnvOverrides(t *testing.T) {
+	tests := map[string]struct {
+		env         map[string]string
+		errContains string
+		validateCfg func(t *testing.T, cfg *LocalConfig)
+	}{
+		"env overrides file values": {
+			env: map[string]string{
+				EnvEmailFoldGmail:      "true",
+				EnvFundraiseUpAPIKey:   " env-api-key ",
+				EnvGiftFundID:          "env-fund",
+				EnvProxyURL:            "http://proxy.internal:3128",
+				EnvFundraiseUpPageSize: "25",
+			},
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, "env-api-key", cfg.FundraiseUp.APIKey)
+				require.Equal(t, "env-fund", cfg.GiftDefaults.FundID)
+				require.Equal(t, "http://proxy.internal:3128", cfg.Proxy.URL)
+				require.Equal(t, 25, cfg.FundraiseUp.PageSize)
+				require.True(t, cfg.EmailNormalization.FoldGmail)
+				require.Equal(t, "file-client-id", cfg.Blackbaud.ClientID)
+				require.Equal(t, "Donation", cfg.GiftDefaults.Type)
+			},
+		},
+		"blank env leaves file values": {
+			env: map[string]string{
+				EnvFundraiseUpAPIKey: "  ",
+				EnvGiftFundID:        "",
+			},
+			validateCfg: func(t *testing.T, cfg *LocalConfig) {
+				t.Helper()
+				require.Equal(t, "file-api-key", cfg.FundraiseUp.APIKey)
+				require.Equal(t, "file-fund", cfg.GiftDefaults.FundID)
+			},
+		},
+		"invalid env value": {
+			env: map[string]string{
+				EnvEmailFoldGmail: "sometimes",
+			},
+			errContains: "reading environment overrides",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := `
+blackbaud:
+  client_id: "file-client-id"
+  client_secret: "file-client-secret"
+  subscription_key: "file-sub-key"
+fundraiseup:
+  api_key: "file-api-key"
+gift:
+  fund_id: "file-fund"
+`
+			require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+
+			cfg, err := LoadLocalFile(configPath)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			tc.validateCfg(t, cfg)
+		})
+	}
+}
+
 func TestLoadLocalFileNotFound(t *testing.T) {
 	t.Parallel()
 
 	dir := t.TempDir()
 	configPath := filepath.Join(dir, "nonexistent.yaml")
 
-	_, err := loadLocalFromPath(configPath)
+	_, err := LoadLocalFile(configPath)
 
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "config file not found")
 }
 
 func TestLocalConfigExists(t *testing.T) {
-	t.Parallel()
-
-	// This test verifies the function doesn't panic.
-	// Actual result depends on whether ~/.giftbridge/config.yaml exists.
-	_ = LocalConfigExists()
-}
-
-// loadLocalFromPath loads config from a specific path for testing.
-func loadLocalFromPath(configPath string) (*LocalConfig, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("config file not found: %s (run 'giftbridge init' to create)", configPath)
-		}
-		return nil, fmt.Errorf("reading config file: %w", err)
-	}
-
-	var local localConfig
-	if err := yaml.Unmarshal(data, &local); err != nil {
-		return nil, fmt.Errorf("parsing config file: %w", err)
-	}
+	// Cannot use t.Parallel() with t.Setenv().
 
-	cfg := &LocalConfig{}
-	cfg.Blackbaud.ClientID = local.Blackbaud.ClientID
-	cfg.Blackbaud.ClientSecret = local.Blackbaud.ClientSecret
-	cfg.Blackbaud.SubscriptionKey = local.Blackbaud.SubscriptionKey
-	cfg.FundraiseUp.APIKey = local.FundraiseUp.APIKey
-	cfg.GiftDefaults.AppealID = local.Gift.AppealID
-	cfg.GiftDefaults.CampaignID = local.Gift.CampaignID
-	cfg.GiftDefaults.FundID = local.Gift.FundID
-	cfg.GiftDefaults.Type = local.Gift.Type
-
-	if cfg.GiftDefaults.Type == "" {
-		cfg.GiftDefaults.Type = "Donation"
-	}
-
-	if err := cfg.validate(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
-	}
+	dir := t.TempDir()
+	t.Setenv(EnvConfigDir, dir)
+	require.False(t, LocalConfigExists())
 
-	return cfg, nil
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(""), 0o600))
+	require.True(t, LocalConfigExists())
 }
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// overrideFromEnv replaces comment scrubbing settings with the environment variables that are set.
+func (c *CommentScrubbing) overrideFromEnv() error {
+	overrideList(&c.Words, EnvCommentScrubWords)
+	return errors.Join(
+		overrideWith(&c.CardNumbers, EnvCommentScrubCardNumbers, envBool),
+		overrideWith(&c.Patterns, EnvCommentScrubPatterns, envStrings),
+	)
+}
+
+// overrideFromEnv replaces constituent defaults with the environment variables that are set.
+func (c *ConstituentDefaults) overrideFromEnv() error {
+	overrideString(&c.AddresseeFormat, EnvConstituentAddresseeFormat)
+	overrideList(&c.Codes, EnvConstituentCodes)
+	overrideString(&c.DonationNoteFormat, EnvConstituentDonationNoteFormat)
+	overrideString(&c.DonationNoteType, EnvConstituentDonationNoteType)
+	overrideString(&c.SalutationFormat, EnvConstituentSalutationFormat)
+	return overrideWith(&c.Events, EnvConstituentEvents, envEventLinks)
+}
+
+// overrideFromEnv replaces email matching settings with the environment variables that are set.
+func (e *EmailNormalization) overrideFromEnv() error {
+	return errors.Join(
+		overrideWith(&e.AddMissing, EnvEmailAddMissing, envBool),
+		overrideWith(&e.FoldGmail, EnvEmailFoldGmail, envBool),
+		overrideWith(&e.IncludeInactive, EnvEmailIncludeInactive, envBool),
+		overrideWith(&e.StrictSearch, EnvEmailStrictSearch, envBool),
+		overrideWith(&e.StripPlusTags, EnvEmailStripPlusTags, envBool),
+	)
+}
+
+// overrideFromEnv replaces gift defaults with the environment variables that are set.
+func (g *GiftDefaults) overrideFromEnv() error {
+	overrideString(&g.AppealID, EnvGiftAppealID)
+	overrideString(&g.CampaignID, EnvGiftCampaignID)
+	overrideString(&g.CreatorCustomField, EnvGiftCreatorCustomField)
+	overrideString(&g.DatePolicy, EnvGiftDatePolicy)
+	overrideString(&g.FundID, EnvGiftFundID)
+	overrideString(&g.PostDate, EnvGiftPostDate)
+	overrideString(&g.PostStatus, EnvGiftPostStatus)
+	overrideString(&g.RecurringMode, EnvGiftRecurringMode)
+	overrideString(&g.ReferenceField, EnvGiftReferenceField)
+	overrideString(&g.ReferenceNoteType, EnvGiftReferenceNoteType)
+	overrideString(&g.TestDonations, EnvGiftTestDonations)
+	overrideString(&g.TestFundID, EnvGiftTestFundID)
+	overrideString(&g.Type, EnvGiftType)
+	return errors.Join(
+		overrideWith(&g.AppealResponses, EnvGiftAppealResponses, envBool),
+		overrideWith(&g.Checks, EnvGiftChecks, envGiftChecks),
+		overrideWith(&g.CountryRoutes, EnvGiftCountryRoutes, envCountryRoutes),
+		overrideWith(&g.MaxAgeDays, EnvGiftMaxAgeDays, envNonNegativeInt),
+		overrideWith(&g.Rules, EnvGiftRules, envGiftRules),
+		overrideWith(&g.Splits, EnvGiftSplits, envGiftSplits),
+	)
+}
+
+// overrideFromEnv replaces name tidying settings with the environment variables that are set.
+func (n *NameNormalization) overrideFromEnv() error {
+	return errors.Join(
+		overrideWith(&n.TitleCase, EnvNameTitleCase, envBool),
+		overrideWith(&n.Transliterate, EnvNameTransliterate, envBool),
+	)
+}
+
+// overrideFromEnv replaces the proxy settings with the environment variables that are set.
+func (p *Proxy) overrideFromEnv() {
+	overrideList(&p.Bypass, EnvProxyBypass)
+	overrideString(&p.URL, EnvProxyURL)
+}
+
+// overrideFromEnv replaces the certificate settings with the environment variables that are set.
+func (t *TLS) overrideFromEnv() {
+	overrideString(&t.CABundle, EnvTLSCABundle)
+	overrideString(&t.ClientCert, EnvTLSClientCert)
+	overrideString(&t.ClientKey, EnvTLSClientKey)
+}
+
+// overrideFromEnv replaces values read from the config file with the environment variables that are set,
+// so a local run can change a setting without editing the file.
+func (c *LocalConfig) overrideFromEnv() error {
+	overrideString(&c.Blackbaud.ClientID, EnvBlackbaudClientID)
+	overrideString(&c.Blackbaud.ClientSecret, EnvBlackbaudClientSecret)
+	overrideString(&c.Blackbaud.SecondarySubscriptionKey, EnvBlackbaudSecondarySubscriptionKey)
+	overrideString(&c.Blackbaud.SubscriptionKey, EnvBlackbaudSubscriptionKey)
+	overrideString(&c.FundraiseUp.APIKey, EnvFundraiseUpAPIKey)
+	overrideString(&c.FundraiseUp.CampaignID, EnvFundraiseUpCampaignID)
+	overrideString(&c.FundraiseUp.Status, EnvFundraiseUpStatus)
+	c.Proxy.overrideFromEnv()
+	c.TLS.overrideFromEnv()
+	overrideString(&c.UserAgent.Organization, EnvUserAgentOrganization)
+
+	return errors.Join(
+		overrideWith(&c.Blackbaud.HedgeDelay, EnvBlackbaudHedgeDelay, envNonNegativeDuration),
+		overrideWith(&c.Blackbaud.QuotaReserve, EnvBlackbaudQuotaReserve, envNonNegativeInt),
+		overrideWith(&c.FundraiseUp.Accounts, EnvFundraiseUpAccounts, envFundraiseUpAccounts),
+		overrideWith(&c.FundraiseUp.CampaignWindows, EnvFundraiseUpCampaignWindows, envCampaignWindows),
+		overrideWith(&c.FundraiseUp.FetchOverlap, EnvFundraiseUpFetchOverlap, envNonNegativeDuration),
+		overrideWith(&c.FundraiseUp.PageSize, EnvFundraiseUpPageSize, func(key string) (int, error) {
+			return envIntOrDefault(key, 0)
+		}),
+		overrideWith(&c.FundraiseUp.StrictDecode, EnvFundraiseUpStrictDecode, envBool),
+		c.CommentScrubbing.overrideFromEnv(),
+		c.ConstituentDefaults.overrideFromEnv(),
+		c.EmailNormalization.overrideFromEnv(),
+		c.GiftDefaults.overrideFromEnv(),
+		c.NameNormalization.overrideFromEnv(),
+	)
+}
+
+// envSet reports whether the environment variable key is set to something other than whitespace.
+// Blank variables are treated as unset, as deployment files list every variable whether used or not.
+func envSet(key string) bool {
+	return strings.TrimSpace(os.Getenv(key)) != ""
+}
+
+// overrideList replaces *dst with the comma-separated values of the environment variable key, if it is set.
+func overrideList(dst *[]string, key string) {
+	if envSet(key) {
+		*dst = envList(key)
+	}
+}
+
+// overrideString replaces *dst with the trimmed value of the environment variable key, if it is set.
+func overrideString(dst *string, key string) {
+	if envSet(key) {
+		*dst = strings.TrimSpace(os.Getenv(key))
+	}
+}
+
+// overrideWith replaces *dst with the environment variable key as read by parse, if it is set.
+func overrideWith[T any](dst *T, key string, parse func(key string) (T, error)) error {
+	if !envSet(key) {
+		return nil
+	}
+	value, err := parse(key)
+	if err != nil {
+		return err
+	}
+	*dst = value
+	return nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redactedText replaces a secret, or all but the last few characters of a long one, when settings are shown.
+const redactedText = "****"
+
+// settingIndex matches the index of a slice element in a setting name, such as the "[0]" of "Accounts[0].Name".
+var settingIndex = regexp.MustCompile(`\[\d+\]`)
+
+// secretSettings names the settings, as listed by Describe with slice indexes written "[]", whose values are secrets.
+var secretSettings = map[string]bool{
+	"Blackbaud.ClientSecret":             true,
+	"Blackbaud.SecondarySubscriptionKey": true,
+	"Blackbaud.SubscriptionKey":          true,
+	"FundraiseUp.APIKey":                 true,
+	"FundraiseUp.Accounts[].APIKey":      true,
+}
+
+// Setting is a single configuration value.
+type Setting struct {
+	// Name is the path to the value through the configuration, such as "GiftDefaults.FundID".
+	Name string
+
+	// Value is the value as text, with secrets redacted.
+	Value string
+}
+
+// Describe lists every value in cfg, a Settings or LocalConfig, in the order its fields are declared,
+// with secrets redacted so the list can be shared when asking for help.
+// Slices are listed one element per setting, such as "GiftDefaults.Rules[0].Field".
+func Describe(cfg any) []Setting {
+	var settings []Setting
+	describe(reflect.Indirect(reflect.ValueOf(cfg)), "", &settings)
+	return settings
+}
+
+// describe appends the settings in v, naming them below prefix.
+func describe(v reflect.Value, prefix string, settings *[]Setting) {
+	switch {
+	case v.Kind() == reflect.Struct && v.Type() != reflect.TypeFor[time.Time]():
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			describe(v.Field(i), joinSettingName(prefix, field.Name), settings)
+		}
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		for i := range v.Len() {
+			describe(v.Index(i), fmt.Sprintf("%s[%d]", prefix, i), settings)
+		}
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			*settings = append(*settings, Setting{Name: prefix})
+			return
+		}
+		describe(v.Elem(), prefix, settings)
+	default:
+		*settings = append(*settings, Setting{Name: prefix, Value: settingValue(prefix, v)})
+	}
+}
+
+// joinSettingName returns the name of field below prefix.
+func joinSettingName(prefix string, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
+
+// redact returns a secret with all but its last four characters hidden, or hidden entirely when it is too short
+// for the rest to stay secret.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) < 12 {
+		return redactedText
+	}
+	return redactedText + secret[len(secret)-4:]
+}
+
+// redactURL returns rawURL with any password hidden.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// settingValue returns the value of the setting named name as text, with secrets and URL passwords redacted.
+func settingValue(name string, v reflect.Value) string {
+	var value string
+	if v.Kind() == reflect.Slice {
+		parts := make([]string, v.Len())
+		for i := range v.Len() {
+			parts[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		value = strings.Join(parts, ", ")
+	} else {
+		value = fmt.Sprint(v.Interface())
+	}
+
+	switch {
+	case secretSettings[settingIndex.ReplaceAllString(name, "[]")]:
+		return redact(value)
+	case strings.Contains(value, "://"):
+		return redactURL(value)
+	default:
+		return value
+	}
+}
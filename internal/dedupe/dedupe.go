@@ -0,0 +1,239 @@
+// Package dedupe finds likely duplicate donors across FundraiseUp and Blackbaud.
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// Blackbaud defines the Blackbaud operations needed to look for duplicate constituents.
+type Blackbaud interface {
+	// Constituent returns the constituent with the given ID.
+	Constituent(ctx context.Context, constituentID string) (*blackbaud.Constituent, error)
+
+	// SearchConstituents searches for constituents matching the given email address.
+	SearchConstituents(
+		ctx context.Context,
+		email string,
+		opts blackbaud.SearchOptions,
+	) ([]blackbaud.Constituent, error)
+}
+
+// Tracker defines the donation tracker operations needed to build the report.
+type Tracker interface {
+	// DonationsBetween returns all tracked donations made in [from, to).
+	DonationsBetween(ctx context.Context, from time.Time, to time.Time) ([]storage.DonationRecord, error)
+}
+
+// ConstituentMatch is a constituent that shares its email address with other constituents.
+type ConstituentMatch struct {
+	// ConstituentID is the tracked Blackbaud constituent.
+	ConstituentID string
+
+	// Email is the constituent's email address.
+	Email string
+
+	// MatchingConstituentIDs are the other constituents with the same email address.
+	MatchingConstituentIDs []string
+}
+
+// Mapping links one identifier to several identifiers on the other side of the sync.
+type Mapping struct {
+	// ID is the identifier that maps to more than one counterpart.
+	ID string
+
+	// Related are the counterpart identifiers, sorted.
+	Related []string
+}
+
+// Report lists likely duplicates for manual review.
+type Report struct {
+	// ConstituentsWithMultipleSupporters are Blackbaud constituents that gifts from several
+	// FundraiseUp supporters were attached to, suggesting duplicate supporters in FundraiseUp.
+	ConstituentsWithMultipleSupporters []Mapping
+
+	// EmailMatches are tracked constituents whose email address is shared with other
+	// constituents in Blackbaud, suggesting duplicates to merge in Raiser's Edge NXT.
+	EmailMatches []ConstituentMatch
+
+	// SupportersWithMultipleConstituents are FundraiseUp supporters whose gifts were attached
+	// to several Blackbaud constituents, suggesting duplicates to merge in Raiser's Edge NXT.
+	SupportersWithMultipleConstituents []Mapping
+}
+
+// Reporter builds dedupe reports from tracked donations and Blackbaud searches.
+type Reporter struct {
+	blackbaud Blackbaud
+	tracker   Tracker
+}
+
+// NewReporter creates a new dedupe reporter.
+// When bb is nil, the report is built from tracker data only and EmailMatches is empty.
+func NewReporter(tracker Tracker, bb Blackbaud) (*Reporter, error) {
+	if tracker == nil {
+		return nil, errors.New("tracker is required")
+	}
+
+	return &Reporter{
+		blackbaud: bb,
+		tracker:   tracker,
+	}, nil
+}
+
+// Empty reports whether no likely duplicates were found.
+func (r *Report) Empty() bool {
+	return len(r.ConstituentsWithMultipleSupporters) == 0 &&
+		len(r.EmailMatches) == 0 &&
+		len(r.SupportersWithMultipleConstituents) == 0
+}
+
+// Write prints the report in a human-readable form.
+func (r *Report) Write(w io.Writer) error {
+	if r.Empty() {
+		_, err := io.WriteString(w, "No likely duplicates found.\n")
+		return err
+	}
+
+	var b strings.Builder
+
+	if len(r.SupportersWithMultipleConstituents) > 0 {
+		b.WriteString("FundraiseUp supporters linked to more than one constituent (merge in Raiser's Edge NXT):\n")
+		for _, m := range r.SupportersWithMultipleConstituents {
+			fmt.Fprintf(&b, "  %s -> %s\n", m.ID, strings.Join(m.Related, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.EmailMatches) > 0 {
+		b.WriteString("Constituents sharing an email address (merge in Raiser's Edge NXT):\n")
+		for _, m := range r.EmailMatches {
+			matching := strings.Join(m.MatchingConstituentIDs, ", ")
+			fmt.Fprintf(&b, "  %s (%s) also matches %s\n", m.ConstituentID, m.Email, matching)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.ConstituentsWithMultipleSupporters) > 0 {
+		b.WriteString("Constituents receiving gifts from more than one FundraiseUp supporter:\n")
+		for _, m := range r.ConstituentsWithMultipleSupporters {
+			fmt.Fprintf(&b, "  %s <- %s\n", m.ID, strings.Join(m.Related, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Generate builds a report from donations tracked since the given time.
+func (r *Reporter) Generate(ctx context.Context, since time.Time) (*Report, error) {
+	// Include donations created up to now; the upper bound is exclusive.
+	records, err := r.tracker.DonationsBetween(ctx, since, time.Now().Add(time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("listing tracked donations: %w", err)
+	}
+
+	constituentsBySupporter := make(map[string]map[string]bool)
+	supportersByConstituent := make(map[string]map[string]bool)
+	for _, record := range records {
+		if record.SupporterID == "" || record.ConstituentID == "" {
+			continue
+		}
+		addRelation(constituentsBySupporter, record.SupporterID, record.ConstituentID)
+		addRelation(supportersByConstituent, record.ConstituentID, record.SupporterID)
+	}
+
+	report := &Report{
+		ConstituentsWithMultipleSupporters: multiples(supportersByConstituent),
+		SupportersWithMultipleConstituents: multiples(constituentsBySupporter),
+	}
+
+	if r.blackbaud != nil {
+		matches, err := r.emailMatches(ctx, sortedKeys(supportersByConstituent))
+		if err != nil {
+			return nil, err
+		}
+		report.EmailMatches = matches
+	}
+
+	return report, nil
+}
+
+// emailMatches searches Blackbaud for other constituents sharing each tracked constituent's email address.
+func (r *Reporter) emailMatches(ctx context.Context, constituentIDs []string) ([]ConstituentMatch, error) {
+	var matches []ConstituentMatch
+	for _, id := range constituentIDs {
+		constituent, err := r.blackbaud.Constituent(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("getting constituent %s: %w", id, err)
+		}
+		if constituent.Email == nil || constituent.Email.Address == "" {
+			continue
+		}
+
+		// Inactive records still hold gifts, so they count as duplicates too.
+		found, err := r.blackbaud.SearchConstituents(
+			ctx,
+			constituent.Email.Address,
+			blackbaud.SearchOptions{IncludeInactive: true, StrictEmail: true},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("searching constituents for %s: %w", id, err)
+		}
+
+		others := make(map[string]bool)
+		for _, c := range found {
+			if c.ID != "" && c.ID != id {
+				others[c.ID] = true
+			}
+		}
+		if len(others) == 0 {
+			continue
+		}
+
+		matches = append(matches, ConstituentMatch{
+			ConstituentID:          id,
+			Email:                  constituent.Email.Address,
+			MatchingConstituentIDs: sortedKeys(others),
+		})
+	}
+
+	return matches, nil
+}
+
+// addRelation records that key is related to value.
+func addRelation(relations map[string]map[string]bool, key string, value string) {
+	if relations[key] == nil {
+		relations[key] = make(map[string]bool)
+	}
+	relations[key][value] = true
+}
+
+// multiples returns the keys related to more than one value, sorted by key.
+func multiples(relations map[string]map[string]bool) []Mapping {
+	var mappings []Mapping
+	for _, key := range sortedKeys(relations) {
+		if len(relations[key]) > 1 {
+			mappings = append(mappings, Mapping{ID: key, Related: sortedKeys(relations[key])})
+		}
+	}
+	return mappings
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,161 @@
+package dedupe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+type mockBlackbaud struct {
+	constituents map[string]*blackbaud.Constituent
+	search       map[string][]blackbaud.Constituent
+}
+
+func (m *mockBlackbaud) Constituent(_ context.Context, constituentID string) (*blackbaud.Constituent, error) {
+	constituent, ok := m.constituents[constituentID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return constituent, nil
+}
+
+func (m *mockBlackbaud) SearchConstituents(
+	_ context.Context,
+	email string,
+	_ blackbaud.SearchOptions,
+) ([]blackbaud.Constituent, error) {
+	return m.search[email], nil
+}
+
+type mockTracker struct {
+	records []storage.DonationRecord
+}
+
+func (m *mockTracker) DonationsBetween(_ context.Context, _ time.Time, _ time.Time) ([]storage.DonationRecord, error) {
+	return m.records, nil
+}
+
+func TestNewReporter(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewReporter(nil, &mockBlackbaud{})
+	require.EqualError(t, err, "tracker is required")
+
+	reporter, err := NewReporter(&mockTracker{}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, reporter)
+}
+
+func TestReporter_Generate(t *testing.T) {
+	t.Parallel()
+
+	tracker := &mockTracker{
+		records: []storage.DonationRecord{
+			{ConstituentID: "const-1", DonationID: "don_1", SupporterID: "sup_1"},
+			{ConstituentID: "const-2", DonationID: "don_2", SupporterID: "sup_1"},
+			{ConstituentID: "const-3", DonationID: "don_3", SupporterID: "sup_2"},
+			{ConstituentID: "const-3", DonationID: "don_4", SupporterID: "sup_3"},
+			{ConstituentID: "const-4", DonationID: "don_5", SupporterID: "sup_4"},
+			{ConstituentID: "const-4", DonationID: "don_6", SupporterID: "sup_4"},
+		},
+	}
+	bb := &mockBlackbaud{
+		constituents: map[string]*blackbaud.Constituent{
+			"const-1": {ID: "const-1", Email: &blackbaud.Email{Address: "ada@example.com"}},
+			"const-2": {ID: "const-2"},
+			"const-3": {ID: "const-3", Email: &blackbaud.Email{Address: "grace@example.com"}},
+			"const-4": {ID: "const-4", Email: &blackbaud.Email{Address: "alan@example.com"}},
+		},
+		search: map[string][]blackbaud.Constituent{
+			"ada@example.com":   {{ID: "const-1"}},
+			"alan@example.com":  {{ID: "const-4"}, {ID: "const-9"}, {ID: "const-8"}},
+			"grace@example.com": {{ID: "const-3"}},
+		},
+	}
+
+	tests := map[string]struct {
+		blackbaud Blackbaud
+		want      *Report
+	}{
+		"tracker and search data": {
+			blackbaud: bb,
+			want: &Report{
+				ConstituentsWithMultipleSupporters: []Mapping{{ID: "const-3", Related: []string{"sup_2", "sup_3"}}},
+				EmailMatches: []ConstituentMatch{
+					{
+						ConstituentID:          "const-4",
+						Email:                  "alan@example.com",
+						MatchingConstituentIDs: []string{"const-8", "const-9"},
+					},
+				},
+				SupportersWithMultipleConstituents: []Mapping{{ID: "sup_1", Related: []string{"const-1", "const-2"}}},
+			},
+		},
+		"tracker data only": {
+			want: &Report{
+				ConstituentsWithMultipleSupporters: []Mapping{{ID: "const-3", Related: []string{"sup_2", "sup_3"}}},
+				SupportersWithMultipleConstituents: []Mapping{{ID: "sup_1", Related: []string{"const-1", "const-2"}}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reporter, err := NewReporter(tracker, tc.blackbaud)
+			require.NoError(t, err)
+
+			report, err := reporter.Generate(context.Background(), time.Time{})
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, report)
+		})
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		report *Report
+		want   string
+	}{
+		"empty": {
+			report: &Report{},
+			want:   "No likely duplicates found.\n",
+		},
+		"duplicates": {
+			report: &Report{
+				ConstituentsWithMultipleSupporters: []Mapping{{ID: "const-3", Related: []string{"sup_2", "sup_3"}}},
+				EmailMatches: []ConstituentMatch{
+					{ConstituentID: "const-4", Email: "alan@example.com", MatchingConstituentIDs: []string{"const-8"}},
+				},
+				SupportersWithMultipleConstituents: []Mapping{{ID: "sup_1", Related: []string{"const-1", "const-2"}}},
+			},
+			want: "FundraiseUp supporters linked to more than one constituent (merge in Raiser's Edge NXT):\n" +
+				"  sup_1 -> const-1, const-2\n\n" +
+				"Constituents sharing an email address (merge in Raiser's Edge NXT):\n" +
+				"  const-4 (alan@example.com) also matches const-8\n\n" +
+				"Constituents receiving gifts from more than one FundraiseUp supporter:\n" +
+				"  const-3 <- sup_2, sup_3\n\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, tc.report.Write(&buf))
+			require.Equal(t, tc.want, buf.String())
+		})
+	}
+}
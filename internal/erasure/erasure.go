@@ -0,0 +1,165 @@
+// Package erasure removes a FundraiseUp supporter's personal data from the donation tracker for data-subject
+// erasure requests, and lists the Raiser's Edge NXT records that must be erased by hand.
+package erasure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// Tracker defines the donation tracker operations needed to erase a supporter.
+type Tracker interface {
+	// Forget removes the supporter and constituent IDs from a tracked donation's record.
+	Forget(ctx context.Context, record storage.DonationRecord) error
+
+	// ForgetCachedConstituents removes the constituents remembered for a FundraiseUp supporter's emails,
+	// returning how many were removed.
+	ForgetCachedConstituents(ctx context.Context, supporterID string) (int, error)
+
+	// SupporterDonations returns all tracked donations made by a FundraiseUp supporter.
+	SupporterDonations(ctx context.Context, supporterID string) ([]storage.DonationRecord, error)
+}
+
+// Constituent is a Raiser's Edge NXT constituent the supporter's donations were synced to.
+type Constituent struct {
+	// GiftIDs are the gifts synced for the supporter's donations, ordered by ID.
+	GiftIDs []string
+
+	// ID is the Raiser's Edge NXT constituent ID.
+	ID string
+}
+
+// Report describes the tracker records that were, or in a dry run would be, erased,
+// and the Raiser's Edge NXT records that need manual action.
+type Report struct {
+	// CachedConstituents is the number of constituents remembered for the supporter's emails that were erased.
+	// Dry runs leave it zero.
+	CachedConstituents int
+
+	// Constituents are the constituents the supporter's donations were synced to, ordered by ID.
+	Constituents []Constituent
+
+	// DonationIDs are the tracked donations whose records were erased, ordered by ID.
+	DonationIDs []string
+
+	// SupporterID is the FundraiseUp supporter identifier.
+	SupporterID string
+
+	// UnknownGiftIDs are gifts tracked without a constituent ID, ordered by ID.
+	UnknownGiftIDs []string
+}
+
+// Eraser removes a supporter's personal data from the donation tracker.
+type Eraser struct {
+	tracker Tracker
+}
+
+// NewEraser creates a new supporter eraser.
+func NewEraser(tracker Tracker) (*Eraser, error) {
+	if tracker == nil {
+		return nil, errors.New("tracker is required")
+	}
+
+	return &Eraser{tracker: tracker}, nil
+}
+
+// Erase removes the supporter and constituent IDs from every tracked donation made by supporterID, then the
+// constituents remembered for the supporter's emails, which would otherwise link their email to a constituent.
+// Donations stay tracked with their gifts, so they are not synced again. In a dry run nothing is changed.
+// The report is returned with the records erased so far when a record fails.
+func (e *Eraser) Erase(ctx context.Context, supporterID string, dryRun bool) (*Report, error) {
+	if supporterID == "" {
+		return nil, errors.New("supporter ID is required")
+	}
+
+	records, err := e.tracker.SupporterDonations(ctx, supporterID)
+	if err != nil {
+		return nil, fmt.Errorf("getting tracked donations: %w", err)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].DonationID < records[j].DonationID })
+
+	report := plan(supporterID, records)
+	if dryRun {
+		return report, nil
+	}
+
+	report.DonationIDs = nil
+	for _, record := range records {
+		if err := e.tracker.Forget(ctx, record); err != nil {
+			return report, fmt.Errorf("erasing donation %s: %w", record.DonationID, err)
+		}
+		report.DonationIDs = append(report.DonationIDs, record.DonationID)
+	}
+
+	report.CachedConstituents, err = e.tracker.ForgetCachedConstituents(ctx, supporterID)
+	if err != nil {
+		return report, fmt.Errorf("erasing cached constituents: %w", err)
+	}
+
+	return report, nil
+}
+
+// plan groups a supporter's tracked donations by the constituent their gifts belong to.
+func plan(supporterID string, records []storage.DonationRecord) *Report {
+	report := &Report{SupporterID: supporterID}
+
+	gifts := make(map[string][]string)
+	for _, record := range records {
+		report.DonationIDs = append(report.DonationIDs, record.DonationID)
+		if record.ConstituentID == "" {
+			report.UnknownGiftIDs = append(report.UnknownGiftIDs, record.GiftID)
+			continue
+		}
+		gifts[record.ConstituentID] = append(gifts[record.ConstituentID], record.GiftID)
+	}
+
+	for id, giftIDs := range gifts {
+		sort.Strings(giftIDs)
+		report.Constituents = append(report.Constituents, Constituent{GiftIDs: giftIDs, ID: id})
+	}
+	sort.Slice(report.Constituents, func(i, j int) bool { return report.Constituents[i].ID < report.Constituents[j].ID })
+	sort.Strings(report.UnknownGiftIDs)
+
+	return report
+}
+
+// Write writes the report as text, describing the erasure as made or, in a dry run, as planned.
+func (r *Report) Write(w io.Writer, dryRun bool) error {
+	var b strings.Builder
+
+	if r.CachedConstituents > 0 {
+		fmt.Fprintf(&b, "Erased %d cached constituent matches for supporter %s.\n", r.CachedConstituents, r.SupporterID)
+	}
+
+	if len(r.DonationIDs) == 0 && len(r.Constituents) == 0 && len(r.UnknownGiftIDs) == 0 {
+		fmt.Fprintf(&b, "No tracked donations for supporter %s.\n", r.SupporterID)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	verb := "Erased"
+	if dryRun {
+		verb = "Would erase"
+	}
+	fmt.Fprintf(&b, "%s supporter %s from %d tracked donations: %s\n",
+		verb, r.SupporterID, len(r.DonationIDs), strings.Join(r.DonationIDs, ", "))
+
+	if len(r.Constituents) > 0 || len(r.UnknownGiftIDs) > 0 {
+		b.WriteString("Erase by hand in Raiser's Edge NXT:\n")
+	}
+	for _, constituent := range r.Constituents {
+		fmt.Fprintf(&b, "  constituent %s (gifts %s)\n", constituent.ID, strings.Join(constituent.GiftIDs, ", "))
+	}
+	if len(r.UnknownGiftIDs) > 0 {
+		fmt.Fprintf(&b, "  gifts %s (constituent not tracked)\n", strings.Join(r.UnknownGiftIDs, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,164 @@
+package erasure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+type mockTracker struct {
+	cached          int
+	cacheForgotten  bool
+	forgetCachedErr error
+	forgetErr       error
+	forgotten       []string
+	records         []storage.DonationRecord
+}
+
+func (m *mockTracker) Forget(_ context.Context, record storage.DonationRecord) error {
+	if m.forgetErr != nil && len(m.forgotten) > 0 {
+		return m.forgetErr
+	}
+	m.forgotten = append(m.forgotten, record.DonationID)
+	return nil
+}
+
+func (m *mockTracker) ForgetCachedConstituents(_ context.Context, _ string) (int, error) {
+	if m.forgetCachedErr != nil {
+		return 0, m.forgetCachedErr
+	}
+	m.cacheForgotten = true
+	return m.cached, nil
+}
+
+func (m *mockTracker) SupporterDonations(_ context.Context, _ string) ([]storage.DonationRecord, error) {
+	return m.records, nil
+}
+
+func TestNewEraser(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewEraser(nil)
+	require.EqualError(t, err, "tracker is required")
+
+	eraser, err := NewEraser(&mockTracker{})
+	require.NoError(t, err)
+	require.NotNil(t, eraser)
+}
+
+func TestEraserErase(t *testing.T) {
+	t.Parallel()
+
+	records := []storage.DonationRecord{
+		{ConstituentID: "const-2", DonationID: "don_3", GiftID: "gift-3", SupporterID: "sup_1"},
+		{ConstituentID: "const-1", DonationID: "don_1", GiftID: "gift-1", SupporterID: "sup_1"},
+		{DonationID: "don_4", GiftID: "gift-4", SupporterID: "sup_1"},
+		{ConstituentID: "const-1", DonationID: "don_2", GiftID: "gift-2", SupporterID: "sup_1"},
+	}
+
+	tests := map[string]struct {
+		cached             int
+		dryRun             bool
+		forgetCachedErr    error
+		forgetErr          error
+		records            []storage.DonationRecord
+		wantCacheForgotten bool
+		wantErr            string
+		wantForgotten      []string
+		wantOutput         string
+	}{
+		"erases every donation": {
+			cached:             2,
+			records:            records,
+			wantCacheForgotten: true,
+			wantForgotten:      []string{"don_1", "don_2", "don_3", "don_4"},
+			wantOutput: "Erased 2 cached constituent matches for supporter sup_1.\n" +
+				"Erased supporter sup_1 from 4 tracked donations: don_1, don_2, don_3, don_4\n" +
+				"Erase by hand in Raiser's Edge NXT:\n" +
+				"  constituent const-1 (gifts gift-1, gift-2)\n" +
+				"  constituent const-2 (gifts gift-3)\n" +
+				"  gifts gift-4 (constituent not tracked)\n",
+		},
+		"dry run changes nothing": {
+			dryRun:  true,
+			records: records[:2],
+			wantOutput: "Would erase supporter sup_1 from 2 tracked donations: don_1, don_3\n" +
+				"Erase by hand in Raiser's Edge NXT:\n" +
+				"  constituent const-1 (gifts gift-1)\n" +
+				"  constituent const-2 (gifts gift-3)\n",
+		},
+		"reports donations erased before a failure": {
+			forgetErr:     errors.New("throttled"),
+			records:       records[:2],
+			wantErr:       "erasing donation don_3: throttled",
+			wantForgotten: []string{"don_1"},
+			wantOutput: "Erased supporter sup_1 from 1 tracked donations: don_1\n" +
+				"Erase by hand in Raiser's Edge NXT:\n" +
+				"  constituent const-1 (gifts gift-1)\n" +
+				"  constituent const-2 (gifts gift-3)\n",
+		},
+		"cached constituents fail": {
+			forgetCachedErr: errors.New("throttled"),
+			records:         records[:1],
+			wantErr:         "erasing cached constituents: throttled",
+			wantForgotten:   []string{"don_3"},
+			wantOutput: "Erased supporter sup_1 from 1 tracked donations: don_3\n" +
+				"Erase by hand in Raiser's Edge NXT:\n" +
+				"  constituent const-2 (gifts gift-3)\n",
+		},
+		"no tracked donations": {
+			wantCacheForgotten: true,
+			wantOutput:         "No tracked donations for supporter sup_1.\n",
+		},
+		"only cached constituents": {
+			cached:             1,
+			wantCacheForgotten: true,
+			wantOutput: "Erased 1 cached constituent matches for supporter sup_1.\n" +
+				"No tracked donations for supporter sup_1.\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := &mockTracker{
+				cached:          tc.cached,
+				forgetCachedErr: tc.forgetCachedErr,
+				forgetErr:       tc.forgetErr,
+				records:         append([]storage.DonationRecord(nil), tc.records...),
+			}
+			eraser, err := NewEraser(tracker)
+			require.NoError(t, err)
+
+			report, err := eraser.Erase(context.Background(), "sup_1", tc.dryRun)
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.wantForgotten, tracker.forgotten)
+			require.Equal(t, tc.wantCacheForgotten, tracker.cacheForgotten)
+
+			var out bytes.Buffer
+			require.NoError(t, report.Write(&out, tc.dryRun))
+			require.Equal(t, tc.wantOutput, out.String())
+		})
+	}
+}
+
+func TestEraserEraseRequiresSupporter(t *testing.T) {
+	t.Parallel()
+
+	eraser, err := NewEraser(&mockTracker{})
+	require.NoError(t, err)
+
+	_, err = eraser.Erase(context.Background(), "", false)
+	require.EqualError(t, err, "supporter ID is required")
+}
@@ -0,0 +1,251 @@
+// Package fixtures turns real FundraiseUp donations into test fixtures, replacing donors' personal data with fake
+// values, so regression tests can use realistic donations without exposing donor data.
+package fixtures
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// fakeDomains are the email domains of fake supporters, reserved for documentation so no mail can reach anyone.
+var fakeDomains = []string{"example.com", "example.net", "example.org"}
+
+// fakeFirstNames are the first names given to fake supporters.
+var fakeFirstNames = []string{
+	"Alex", "Bailey", "Casey", "Charlie", "Dakota", "Drew", "Emery", "Finley",
+	"Harper", "Jamie", "Jordan", "Morgan", "Quinn", "Riley", "Rowan", "Taylor",
+}
+
+// fakeLastNames are the last names given to fake supporters.
+var fakeLastNames = []string{
+	"Abbott", "Bishop", "Carter", "Dawson", "Ellis", "Fletcher", "Griffin", "Hughes",
+	"Irving", "Jennings", "Keller", "Lawson", "Mercer", "Norris", "Palmer", "Sutton",
+}
+
+// fakeCities are the cities in fake supporters' addresses.
+var fakeCities = []string{
+	"Ashford", "Brookfield", "Clayton", "Fairview", "Greenville", "Kingston", "Milton", "Riverside",
+}
+
+// fakeStreets are the streets in fake supporters' addresses.
+var fakeStreets = []string{"Acacia Avenue", "Church Lane", "High Street", "Mill Road", "Park Road", "Station Road"}
+
+// fakeWords make up fake donor comments.
+var fakeWords = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit",
+	"sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et",
+}
+
+// Donations defines the FundraiseUp operations needed to generate fixtures.
+type Donations interface {
+	// DonationsEach calls fn for each donation created after the given time.
+	DonationsEach(ctx context.Context, since time.Time, fn func(fundraiseup.Donation) error) error
+}
+
+// Pseudonymizer replaces the personal data in donations with fake values. The same value is always replaced by the
+// same fake value for the same key, so a supporter's donations still share their fake supporter, and fixtures
+// generated with the same key agree with each other. The fake values cannot be traced back without the key.
+type Pseudonymizer struct {
+	// key keys the HMAC choosing each fake value.
+	key []byte
+}
+
+// NewPseudonymizer creates a Pseudonymizer choosing fake values with key.
+func NewPseudonymizer(key []byte) (*Pseudonymizer, error) {
+	if len(key) == 0 {
+		return nil, errors.New("key is required")
+	}
+
+	return &Pseudonymizer{key: key}, nil
+}
+
+// Collect fetches the donations created after since and before until, or every donation after since when until is
+// zero, and returns them pseudonymized in the order FundraiseUp returned them. It stops after limit donations,
+// unless limit is zero.
+func Collect(
+	ctx context.Context,
+	source Donations,
+	p *Pseudonymizer,
+	since time.Time,
+	until time.Time,
+	limit int,
+) ([]fundraiseup.Donation, error) {
+	var donations []fundraiseup.Donation
+	err := source.DonationsEach(ctx, since, func(donation fundraiseup.Donation) error {
+		if !until.IsZero() && !donation.CreatedAt.Before(until) {
+			return nil
+		}
+		donations = append(donations, p.Donation(donation))
+		if limit > 0 && len(donations) >= limit {
+			return fundraiseup.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching donations: %w", err)
+	}
+
+	return donations, nil
+}
+
+// Write encodes donations as a FundraiseUp donations response, so a fake FundraiseUp server can serve the fixture
+// as a single page.
+func Write(w io.Writer, donations []fundraiseup.Donation) error {
+	if donations == nil {
+		donations = []fundraiseup.Donation{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	//nolint:tagliatelle // External API uses snake_case.
+	return enc.Encode(struct {
+		Data    []fundraiseup.Donation `json:"data"`
+		HasMore bool                   `json:"has_more"`
+	}{Data: donations})
+}
+
+// Donation returns a copy of donation with its personal data replaced. Amounts, dates, statuses, campaigns,
+// designations and the supporter's country and region are kept, since syncing depends on them.
+func (p *Pseudonymizer) Donation(donation fundraiseup.Donation) fundraiseup.Donation {
+	donation.ID = p.id(donation.ID)
+	if donation.Comment != "" {
+		donation.Comment = p.comment(donation.Comment)
+	}
+	if donation.Payment != nil {
+		payment := *donation.Payment
+		payment.CardLast4 = p.mask("card", payment.CardLast4)
+		payment.CheckNumber = p.mask("check", payment.CheckNumber)
+		payment.CollectionReference = p.mask("collection", payment.CollectionReference)
+		payment.MandateReference = p.mask("mandate", payment.MandateReference)
+		donation.Payment = &payment
+	}
+	if donation.Payout != nil {
+		payout := *donation.Payout
+		payout.ID = p.id(payout.ID)
+		donation.Payout = &payout
+	}
+	if donation.RecurringPlan != nil {
+		plan := *donation.RecurringPlan
+		plan.ID = p.id(plan.ID)
+		donation.RecurringPlan = &plan
+	}
+	if donation.Supporter != nil {
+		donation.Supporter = p.supporter(*donation.Supporter)
+	}
+
+	return donation
+}
+
+// supporter returns a copy of supporter with a fake name, email, phone number and street address.
+func (p *Pseudonymizer) supporter(supporter fundraiseup.Supporter) *fundraiseup.Supporter {
+	supporter.ID = p.id(supporter.ID)
+	if supporter.Email != "" {
+		supporter.Email = p.email(supporter.Email)
+	}
+	if supporter.FirstName != "" {
+		supporter.FirstName = p.pick("first_name", supporter.FirstName, fakeFirstNames)
+	}
+	if supporter.LastName != "" {
+		supporter.LastName = p.pick("last_name", supporter.LastName, fakeLastNames)
+	}
+	supporter.Phone = p.mask("phone", supporter.Phone)
+	if supporter.Address != nil {
+		address := *supporter.Address
+		if address.City != "" {
+			address.City = p.pick("city", address.City, fakeCities)
+		}
+		if address.Line1 != "" {
+			address.Line1 = fmt.Sprintf("%d %s",
+				1+p.number("house", address.Line1)%200, p.pick("street", address.Line1, fakeStreets))
+		}
+		address.Line2 = p.mask("line2", address.Line2)
+		address.PostalCode = p.mask("postal_code", address.PostalCode)
+		supporter.Address = &address
+	}
+
+	return &supporter
+}
+
+// comment returns fake words as long as comment, so comments too long for a gift's reference stay too long.
+func (p *Pseudonymizer) comment(comment string) string {
+	length := len([]rune(comment))
+	var b strings.Builder
+	for i := 0; b.Len() < length; i++ {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(fakeWords[p.number(fmt.Sprintf("comment%d", i), comment)%uint64(len(fakeWords))])
+	}
+
+	return b.String()[:length]
+}
+
+// email returns a fake email address at a reserved domain, keeping any plus tag so tag handling can be tested.
+func (p *Pseudonymizer) email(email string) string {
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	var tag string
+	if i := strings.IndexByte(local, '+'); i >= 0 {
+		tag = "+" + p.mask("email_tag", local[i+1:])
+	}
+
+	return fmt.Sprintf("%s.%s%s@%s",
+		strings.ToLower(p.pick("first_name", email, fakeFirstNames)),
+		strings.ToLower(p.pick("last_name", email, fakeLastNames)),
+		tag,
+		p.pick("domain", email, fakeDomains))
+}
+
+// id returns a fake identifier shaped like id, keeping its prefix, such as "sup_" or the leading letter, so it still
+// looks like the kind of ID it was.
+func (p *Pseudonymizer) id(id string) string {
+	prefix := len(id[:strings.LastIndexByte(id, '_')+1])
+	if prefix == 0 && id != "" {
+		prefix = 1
+	}
+
+	return id[:prefix] + p.mask("id", id[prefix:])
+}
+
+// mask replaces each digit and letter in value with a fake one, keeping the value's length, case and punctuation,
+// so fake post codes, phone numbers and references keep the format of the real ones.
+func (p *Pseudonymizer) mask(kind string, value string) string {
+	runes := []rune(value)
+	for i, r := range runes {
+		n := p.number(fmt.Sprintf("%s%d", kind, i), value)
+		switch {
+		case r >= '0' && r <= '9':
+			runes[i] = rune('0' + n%10)
+		case unicode.IsUpper(r):
+			runes[i] = rune('A' + n%26)
+		case unicode.IsLower(r):
+			runes[i] = rune('a' + n%26)
+		}
+	}
+
+	return string(runes)
+}
+
+// pick returns the fake value from choices for value.
+func (p *Pseudonymizer) pick(kind string, value string, choices []string) string {
+	return choices[p.number(kind, value)%uint64(len(choices))]
+}
+
+// number returns the keyed hash of value for the kind of fake value being chosen.
+func (p *Pseudonymizer) number(kind string, value string) uint64 {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
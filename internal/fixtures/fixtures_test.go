@@ -0,0 +1,237 @@
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+type mockDonations struct {
+	donations []fundraiseup.Donation
+	err       error
+	since     time.Time
+}
+
+func (m *mockDonations) DonationsEach(
+	_ context.Context,
+	since time.Time,
+	fn func(fundraiseup.Donation) error,
+) error {
+	m.since = since
+	for _, donation := range m.donations {
+		if err := fn(donation); err != nil {
+			if errors.Is(err, fundraiseup.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	return m.err
+}
+
+func testDonation(id string, createdAt time.Time) fundraiseup.Donation {
+	return fundraiseup.Donation{
+		Amount:    "25.00",
+		Campaign:  &fundraiseup.Campaign{ID: "FUNCPJTZZQR", Name: "Spring Appeal"},
+		Comment:   "In memory of my mother, Margaret Smith",
+		CreatedAt: createdAt,
+		Currency:  "GBP",
+		ID:        id,
+		Payment: &fundraiseup.Payment{
+			CardBrand: "visa",
+			CardLast4: "4242",
+			Method:    "credit_card",
+		},
+		Status: "succeeded",
+		Supporter: &fundraiseup.Supporter{
+			Address: &fundraiseup.Address{
+				City:       "Manchester",
+				Country:    "GB",
+				Line1:      "14 Deansgate",
+				PostalCode: "M3 2BW",
+				Region:     "England",
+			},
+			Email:     "Jane.Smith+giving@gmail.com",
+			FirstName: "Jane",
+			ID:        "sup_4x7Kp2QmZ9",
+			LastName:  "Smith",
+			Phone:     "+44 161 496 0000",
+		},
+	}
+}
+
+func TestNewPseudonymizer(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg string
+		key    []byte
+	}{
+		"valid": {
+			key: []byte("fixture-key"),
+		},
+		"missing key": {
+			errMsg: "key is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewPseudonymizer(tc.key)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, p)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, p)
+		})
+	}
+}
+
+func TestPseudonymizerDonation(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPseudonymizer([]byte("fixture-key"))
+	require.NoError(t, err)
+
+	original := testDonation("DXXXXXXX", time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC))
+	fake := p.Donation(original)
+
+	// The original is left untouched.
+	require.Equal(t, testDonation("DXXXXXXX", original.CreatedAt), original)
+
+	// Nothing identifying the donor survives.
+	encoded, err := json.Marshal(fake)
+	require.NoError(t, err)
+	for _, pii := range []string{"Jane", "Smith", "gmail", "Margaret", "Manchester", "Deansgate", "M3 2BW", "4242",
+		"496", "4x7Kp2QmZ9", "DXXXXXXX"} {
+		require.NotContains(t, string(encoded), pii)
+	}
+
+	// Fields syncing depends on are kept, and fake values keep the format of the real ones.
+	require.Equal(t, original.Amount, fake.Amount)
+	require.Equal(t, original.Campaign, fake.Campaign)
+	require.Equal(t, original.CreatedAt, fake.CreatedAt)
+	require.Equal(t, original.Currency, fake.Currency)
+	require.Equal(t, original.Status, fake.Status)
+	require.Equal(t, "visa", fake.Payment.CardBrand)
+	require.Regexp(t, `^[0-9]{4}$`, fake.Payment.CardLast4)
+	require.Equal(t, "GB", fake.Supporter.Address.Country)
+	require.Equal(t, "England", fake.Supporter.Address.Region)
+	require.Regexp(t, `^[A-Z][0-9] [0-9][A-Z]{2}$`, fake.Supporter.Address.PostalCode)
+	require.Regexp(t, `^\+[0-9]{2} [0-9]{3} [0-9]{3} [0-9]{4}$`, fake.Supporter.Phone)
+	require.Regexp(t, `^D[A-Z]{7}$`, fake.ID)
+	require.Regexp(t, `^sup_[0-9a-zA-Z]{10}$`, fake.Supporter.ID)
+	require.Regexp(t, `^[a-z]+\.[a-z]+\+[a-z]{6}@example\.(com|net|org)$`, fake.Supporter.Email)
+	require.Regexp(t, `^[0-9]+ [A-Za-z ]+$`, fake.Supporter.Address.Line1)
+	require.Len(t, fake.Comment, len(original.Comment))
+
+	// The same key gives the same fakes, so a supporter's donations still share their fake supporter.
+	again := p.Donation(testDonation("DYYYYYYY", original.CreatedAt.Add(time.Hour)))
+	require.Equal(t, fake.Supporter, again.Supporter)
+	require.NotEqual(t, fake.ID, again.ID)
+
+	// Another key gives other fakes.
+	other, err := NewPseudonymizer([]byte("another-key"))
+	require.NoError(t, err)
+	require.NotEqual(t, fake.Supporter, other.Donation(original).Supporter)
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	donations := []fundraiseup.Donation{
+		testDonation("DAAAAAAA", since.Add(time.Hour)),
+		testDonation("DBBBBBBB", until),
+		testDonation("DCCCCCCC", since.Add(2*time.Hour)),
+		testDonation("DDDDDDDD", since.Add(3*time.Hour)),
+	}
+
+	tests := map[string]struct {
+		errMsg      string
+		fetchErr    error
+		limit       int
+		until       time.Time
+		wantCreated []time.Time
+	}{
+		"every donation since": {
+			wantCreated: []time.Time{since.Add(time.Hour), until, since.Add(2 * time.Hour), since.Add(3 * time.Hour)},
+		},
+		"donations before until": {
+			until:       until,
+			wantCreated: []time.Time{since.Add(time.Hour), since.Add(2 * time.Hour), since.Add(3 * time.Hour)},
+		},
+		"limited": {
+			limit:       2,
+			until:       until,
+			wantCreated: []time.Time{since.Add(time.Hour), since.Add(2 * time.Hour)},
+		},
+		"fetch fails": {
+			errMsg:   "fetching donations: unexpected status 503",
+			fetchErr: errors.New("unexpected status 503"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewPseudonymizer([]byte("fixture-key"))
+			require.NoError(t, err)
+			source := &mockDonations{donations: donations, err: tc.fetchErr}
+
+			got, err := Collect(context.Background(), source, p, since, tc.until, tc.limit)
+
+			require.Equal(t, since, source.since)
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			var created []time.Time
+			for _, donation := range got {
+				require.NotEqual(t, "Jane", donation.Supporter.FirstName)
+				created = append(created, donation.CreatedAt)
+			}
+			require.Equal(t, tc.wantCreated, created)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	var empty bytes.Buffer
+	require.NoError(t, Write(&empty, nil))
+	require.JSONEq(t, `{"data":[],"has_more":false}`, empty.String())
+
+	var buf bytes.Buffer
+	donation := fundraiseup.Donation{Amount: "10.00", Currency: "USD", ID: "DAAAAAAA", Status: "succeeded"}
+	require.NoError(t, Write(&buf, []fundraiseup.Donation{donation}))
+	require.True(t, strings.HasPrefix(buf.String(), "{\n  \"data\": ["))
+
+	// The fixture reads back as a FundraiseUp donations response.
+	var page struct {
+		Data    []fundraiseup.Donation `json:"data"`
+		HasMore bool                   `json:"has_more"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &page))
+	require.Equal(t, []fundraiseup.Donation{donation}, page.Data)
+	require.False(t, page.HasMore)
+}
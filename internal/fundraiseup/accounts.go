@@ -0,0 +1,312 @@
+package fundraiseup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Account is one of several FundraiseUp accounts synced together, such as the separate entities of one charity.
+type Account struct {
+	// Client is the API client for the account's API key.
+	Client *Client
+
+	// Name identifies the account, and is set as the Account of each of its donations.
+	// Empty for the main account, whose donations are left untagged.
+	Name string
+}
+
+// Accounts merges the donations and events of several FundraiseUp accounts, interleaving them by creation time
+// as though they came from a single account. Each account's donations are assumed to come oldest first.
+type Accounts struct {
+	// accounts are the merged accounts, in the order given.
+	accounts []Account
+}
+
+// NewAccounts creates Accounts merging the given accounts. Account names must be unique.
+func NewAccounts(accounts ...Account) (*Accounts, error) {
+	if len(accounts) == 0 {
+		return nil, errors.New("at least one account is required")
+	}
+
+	named := make(map[string]bool, len(accounts))
+	for i, account := range accounts {
+		if account.Client == nil {
+			return nil, fmt.Errorf("account %d: client is required", i+1)
+		}
+		if named[account.Name] {
+			return nil, fmt.Errorf("account %d: name %q is already used", i+1, account.Name)
+		}
+		named[account.Name] = true
+	}
+
+	return &Accounts{accounts: slices.Clone(accounts)}, nil
+}
+
+// Donation fetches a single donation by ID from whichever account has it.
+func (a *Accounts) Donation(ctx context.Context, id string) (*Donation, error) {
+	var err error
+	for _, account := range a.accounts {
+		var donation *Donation
+		donation, err = account.Client.Donation(ctx, id)
+		if err == nil {
+			donation.Account = account.Name
+			return donation, nil
+		}
+		if !isNotFound(err) {
+			return nil, account.wrap(err)
+		}
+	}
+	return nil, err
+}
+
+// DonationPages calls fn with each page of donations created after the given time in any of the accounts,
+// oldest first, starting after the donation with ID startingAfter (or from the beginning of the window when empty).
+// Pages are the first account's page size. Each account's donations are fetched a page at a time as the merge
+// reaches them, so only a page per account is held in memory.
+//
+// A run resumes after startingAfter by looking the donation up: its own account continues after it, and the others
+// from its creation time, so no account refetches the window already passed. A cursor no account has is an error.
+// Iteration stops when fn returns an error; returning ErrStop stops it without DonationPages returning an error.
+func (a *Accounts) DonationPages(
+	ctx context.Context,
+	since time.Time,
+	startingAfter string,
+	fn func([]Donation) error,
+) error {
+	streams := make([]iter.Seq2[Donation, error], len(a.accounts))
+	if startingAfter == "" {
+		for i, account := range a.accounts {
+			streams[i] = account.donations(ctx, since, "", nil)
+		}
+		return mergePages(ctx, streams, donationCreatedAt, a.pageSize(), fn)
+	}
+
+	cursor, err := a.Donation(ctx, startingAfter)
+	if err != nil {
+		return fmt.Errorf("finding donation %s to resume after: %w", startingAfter, err)
+	}
+	owner := slices.IndexFunc(a.accounts, func(account Account) bool { return account.Name == cursor.Account })
+	from := since
+	if cursor.CreatedAt.After(from) {
+		from = cursor.CreatedAt
+	}
+	for i, account := range a.accounts {
+		if i == owner {
+			streams[i] = account.donations(ctx, since, startingAfter, nil)
+			continue
+		}
+		// Donations created before the cursor were passed already, as were those created with it in earlier
+		// accounts, since the merge breaks ties by account order.
+		passed := func(donation Donation) bool {
+			order := donation.CreatedAt.Compare(cursor.CreatedAt)
+			return order < 0 || order == 0 && i < owner
+		}
+		streams[i] = account.donations(ctx, from, "", passed)
+	}
+	return mergePages(ctx, streams, donationCreatedAt, a.pageSize(), fn)
+}
+
+// EventPages calls fn with each page of events raised after the given time in any of the accounts, oldest first,
+// starting after the event with ID startingAfter (or from the beginning of the window when empty). Events are
+// fetched a page per account at a time as the merge reaches them. A cursor the merged events do not have is an
+// error. Donations in the events are tagged with their account.
+// Iteration stops when fn returns an error; returning ErrStop stops it without EventPages returning an error.
+func (a *Accounts) EventPages(
+	ctx context.Context,
+	since time.Time,
+	startingAfter string,
+	fn func([]Event) error,
+) error {
+	streams := make([]iter.Seq2[Event, error], len(a.accounts))
+	for i, account := range a.accounts {
+		streams[i] = account.events(ctx, since)
+	}
+
+	// Events have no lookup to resume from, so the merged events are passed over up to the cursor.
+	found := startingAfter == ""
+	err := mergePages(ctx, streams, eventCreatedAt, a.pageSize(), func(page []Event) error {
+		if !found {
+			i := slices.IndexFunc(page, func(event Event) bool { return event.ID == startingAfter })
+			if i < 0 {
+				return nil
+			}
+			found = true
+			if page = page[i+1:]; len(page) == 0 {
+				return nil
+			}
+		}
+		return fn(page)
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("event %s to resume after not found in any account", startingAfter)
+	}
+	return nil
+}
+
+// UnknownFields returns the paths of payload fields seen by any of the accounts' clients that the mapper does not
+// decode, in sorted order.
+func (a *Accounts) UnknownFields() []string {
+	found := make(map[string]struct{})
+	for _, account := range a.accounts {
+		for _, name := range account.Client.UnknownFields() {
+			found[name] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pageSize returns the number of donations or events passed to each callback.
+func (a *Accounts) pageSize() int {
+	return max(a.accounts[0].Client.pageSize, 1)
+}
+
+// wrap names the account in err, unless it is the unnamed main account.
+func (a Account) wrap(err error) error {
+	if a.Name == "" {
+		return err
+	}
+	return fmt.Errorf("FundraiseUp account %q: %w", a.Name, err)
+}
+
+// donations returns the account's donations created after since, starting after the donation with ID startingAfter,
+// tagged with the account and fetched a page at a time as they are pulled. Donations for which skip returns true
+// are left out. A failed fetch is the last value, named with the account.
+func (a Account) donations(
+	ctx context.Context,
+	since time.Time,
+	startingAfter string,
+	skip func(Donation) bool,
+) iter.Seq2[Donation, error] {
+	return func(yield func(Donation, error) bool) {
+		err := a.Client.DonationPages(ctx, since, startingAfter, func(page []Donation) error {
+			for _, donation := range page {
+				if skip != nil && skip(donation) {
+					continue
+				}
+				donation.Account = a.Name
+				if !yield(donation, nil) {
+					return ErrStop
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			yield(Donation{}, a.wrap(err))
+		}
+	}
+}
+
+// events returns the account's events raised after since, with their donations tagged with the account,
+// fetched a page at a time as they are pulled. A failed fetch is the last value, named with the account.
+func (a Account) events(ctx context.Context, since time.Time) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		err := a.Client.EventPages(ctx, since, "", func(page []Event) error {
+			for _, event := range page {
+				if event.Data.Donation != nil {
+					event.Data.Donation.Account = a.Name
+				}
+				if !yield(event, nil) {
+					return ErrStop
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			yield(Event{}, a.wrap(err))
+		}
+	}
+}
+
+// donationCreatedAt returns when a donation was created, the order donations are merged in.
+func donationCreatedAt(donation Donation) time.Time {
+	return donation.CreatedAt
+}
+
+// eventCreatedAt returns when an event was raised, the order events are merged in.
+func eventCreatedAt(event Event) time.Time {
+	return event.CreatedAt
+}
+
+// mergePages calls fn with successive pages of up to size items merged from the streams by creation time, earliest
+// first, with ties going to the earlier stream. Each stream is pulled only as the merge reaches it.
+func mergePages[T any](
+	ctx context.Context,
+	streams []iter.Seq2[T, error],
+	createdAt func(T) time.Time,
+	size int,
+	fn func([]T) error,
+) error {
+	type head struct {
+		// item is the stream's next item, valid when ok is true.
+		item T
+
+		// next pulls the item after it.
+		next func() (T, error, bool)
+
+		// ok is false once the stream is used up.
+		ok bool
+	}
+
+	heads := make([]head, len(streams))
+	for i, stream := range streams {
+		next, stop := iter.Pull2(stream)
+		defer stop()
+		item, err, ok := next()
+		if err != nil {
+			return err
+		}
+		heads[i] = head{item: item, next: next, ok: ok}
+	}
+
+	page := make([]T, 0, size)
+	for {
+		earliest := -1
+		for i, h := range heads {
+			if h.ok && (earliest < 0 || createdAt(h.item).Before(createdAt(heads[earliest].item))) {
+				earliest = i
+			}
+		}
+		if earliest >= 0 {
+			page = append(page, heads[earliest].item)
+			item, err, ok := heads[earliest].next()
+			if err != nil {
+				return err
+			}
+			heads[earliest].item, heads[earliest].ok = item, ok
+			if len(page) < size {
+				continue
+			}
+		}
+
+		// Pass on a full page, or the last one once every stream is used up.
+		if len(page) > 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(page); err != nil {
+				if errors.Is(err, ErrStop) {
+					return nil
+				}
+				return err
+			}
+			page = make([]T, 0, size)
+		}
+		if earliest < 0 {
+			return nil
+		}
+	}
+}
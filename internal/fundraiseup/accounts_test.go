@@ -0,0 +1,363 @@
+package fundraiseup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccounts(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient("test-key")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		accounts []Account
+		errMsg   string
+	}{
+		"main and named accounts": {
+			accounts: []Account{{Client: client}, {Client: client, Name: "trading"}},
+		},
+		"no accounts": {
+			errMsg: "at least one account is required",
+		},
+		"missing client": {
+			accounts: []Account{{Client: client}, {Name: "trading"}},
+			errMsg:   "account 2: client is required",
+		},
+		"duplicate name": {
+			accounts: []Account{{Client: client, Name: "trading"}, {Client: client, Name: "trading"}},
+			errMsg:   `account 2: name "trading" is already used`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			accounts, err := NewAccounts(tc.accounts...)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, accounts)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, accounts)
+		})
+	}
+}
+
+func TestAccounts_DonationPages(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour int) time.Time {
+		return time.Date(2025, 3, 2, hour, 0, 0, 0, time.UTC)
+	}
+	mainServer := newAccountServer(t, []Donation{
+		{ID: "don_a1", CreatedAt: at(9)},
+		{ID: "don_a2", CreatedAt: at(12)},
+	})
+	tradingServer := newAccountServer(t, []Donation{
+		{ID: "don_b1", CreatedAt: at(10)},
+		{ID: "don_b2", CreatedAt: at(11)},
+		{ID: "don_b3", CreatedAt: at(12)},
+	})
+
+	tests := map[string]struct {
+		errMsg        string
+		startingAfter string
+		wantAccounts  []string
+		wantPages     [][]string
+	}{
+		"interleaves donations by creation time": {
+			wantAccounts: []string{"", "trading", "trading", "", "trading"},
+			wantPages:    [][]string{{"don_a1", "don_b1", "don_b2"}, {"don_a2", "don_b3"}},
+		},
+		"resumes after a cursor in a named account": {
+			startingAfter: "don_b1",
+			wantAccounts:  []string{"trading", "", "trading"},
+			wantPages:     [][]string{{"don_b2", "don_a2", "don_b3"}},
+		},
+		"resumes after a cursor in the main account": {
+			startingAfter: "don_a1",
+			wantAccounts:  []string{"trading", "trading", "", "trading"},
+			wantPages:     [][]string{{"don_b1", "don_b2", "don_a2"}, {"don_b3"}},
+		},
+		"resumes between donations created together": {
+			startingAfter: "don_a2",
+			wantAccounts:  []string{"trading"},
+			wantPages:     [][]string{{"don_b3"}},
+		},
+		"resumes after the last donation": {
+			startingAfter: "don_b3",
+		},
+		"unknown cursor is an error": {
+			startingAfter: "don_gone",
+			errMsg:        "finding donation don_gone to resume after",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			mainClient, err := NewClient("main-key", WithBaseURL(mainServer.URL), WithPageSize(3))
+			require.NoError(t, err)
+			tradingClient, err := NewClient("trading-key", WithBaseURL(tradingServer.URL))
+			require.NoError(t, err)
+			accounts, err := NewAccounts(Account{Client: mainClient}, Account{Client: tradingClient, Name: "trading"})
+			require.NoError(t, err)
+
+			var pages [][]string
+			var names []string
+			err = accounts.DonationPages(context.Background(), at(0), tc.startingAfter, func(page []Donation) error {
+				var ids []string
+				for _, donation := range page {
+					ids = append(ids, donation.ID)
+					names = append(names, donation.Account)
+				}
+				pages = append(pages, ids)
+				return nil
+			})
+
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPages, pages)
+			require.Equal(t, tc.wantAccounts, names)
+		})
+	}
+}
+
+func TestAccounts_DonationPagesFetchesAsNeeded(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour int) time.Time {
+		return time.Date(2025, 3, 2, hour, 0, 0, 0, time.UTC)
+	}
+	mainServer := newAccountServer(t, []Donation{
+		{ID: "don_a1", CreatedAt: at(9)},
+		{ID: "don_a2", CreatedAt: at(11)},
+		{ID: "don_a3", CreatedAt: at(13)},
+	})
+	tradingServer := newAccountServer(t, []Donation{
+		{ID: "don_b1", CreatedAt: at(10)},
+		{ID: "don_b2", CreatedAt: at(12)},
+		{ID: "don_b3", CreatedAt: at(14)},
+	})
+	mainClient, err := NewClient("main-key", WithBaseURL(mainServer.URL), WithPageSize(1))
+	require.NoError(t, err)
+	tradingClient, err := NewClient("trading-key", WithBaseURL(tradingServer.URL), WithPageSize(1))
+	require.NoError(t, err)
+	accounts, err := NewAccounts(Account{Client: mainClient}, Account{Client: tradingClient, Name: "trading"})
+	require.NoError(t, err)
+
+	var ids []string
+	err = accounts.DonationPages(context.Background(), at(0), "", func(page []Donation) error {
+		ids = append(ids, page[0].ID)
+		if len(ids) == 2 {
+			return ErrStop
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"don_a1", "don_b1"}, ids)
+	// Each account has fetched one page past the donations passed on, not its whole window.
+	require.Equal(t, int32(2), mainServer.lists.Load())
+	require.Equal(t, int32(2), tradingServer.lists.Load())
+}
+
+func TestAccounts_EventPages(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour int) time.Time {
+		return time.Date(2025, 3, 2, hour, 0, 0, 0, time.UTC)
+	}
+	mainServer := newAccountServer(t, nil,
+		Event{ID: "evt_a1", CreatedAt: at(9)},
+		Event{ID: "evt_a2", CreatedAt: at(11)},
+	)
+	tradingServer := newAccountServer(t, nil,
+		Event{ID: "evt_b1", CreatedAt: at(10), Data: EventData{Donation: &Donation{ID: "don_b1"}}},
+	)
+
+	tests := map[string]struct {
+		errMsg        string
+		startingAfter string
+		wantIDs       []string
+	}{
+		"interleaves events by creation time": {
+			wantIDs: []string{"evt_a1", "evt_b1", "evt_a2"},
+		},
+		"resumes after the cursor": {
+			startingAfter: "evt_b1",
+			wantIDs:       []string{"evt_a2"},
+		},
+		"unknown cursor is an error": {
+			startingAfter: "evt_gone",
+			errMsg:        "event evt_gone to resume after not found in any account",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			mainClient, err := NewClient("main-key", WithBaseURL(mainServer.URL), WithPageSize(2))
+			require.NoError(t, err)
+			tradingClient, err := NewClient("trading-key", WithBaseURL(tradingServer.URL))
+			require.NoError(t, err)
+			accounts, err := NewAccounts(Account{Client: mainClient}, Account{Client: tradingClient, Name: "trading"})
+			require.NoError(t, err)
+
+			var ids []string
+			err = accounts.EventPages(context.Background(), at(0), tc.startingAfter, func(page []Event) error {
+				for _, event := range page {
+					ids = append(ids, event.ID)
+					if event.Data.Donation != nil {
+						require.Equal(t, "trading", event.Data.Donation.Account)
+					}
+				}
+				return nil
+			})
+
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
+
+func TestAccounts_Donation(t *testing.T) {
+	t.Parallel()
+
+	mainServer := newAccountServer(t, []Donation{{ID: "don_a1"}})
+	tradingServer := newAccountServer(t, []Donation{{ID: "don_b1"}})
+
+	tests := map[string]struct {
+		errMsg      string
+		id          string
+		wantAccount string
+	}{
+		"main account": {
+			id: "don_a1",
+		},
+		"named account": {
+			id:          "don_b1",
+			wantAccount: "trading",
+		},
+		"in no account": {
+			id:     "don_gone",
+			errMsg: "unexpected status 404",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			mainClient, err := NewClient("main-key", WithBaseURL(mainServer.URL))
+			require.NoError(t, err)
+			tradingClient, err := NewClient("trading-key", WithBaseURL(tradingServer.URL))
+			require.NoError(t, err)
+			accounts, err := NewAccounts(Account{Client: mainClient}, Account{Client: tradingClient, Name: "trading"})
+			require.NoError(t, err)
+
+			donation, err := accounts.Donation(context.Background(), tc.id)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.id, donation.ID)
+			require.Equal(t, tc.wantAccount, donation.Account)
+		})
+	}
+}
+
+// accountServer is a test server for one account.
+type accountServer struct {
+	*httptest.Server
+
+	// lists counts the requests listing donations.
+	lists atomic.Int32
+}
+
+// newAccountServer creates a test server for one account, listing its donations and events oldest first in pages of
+// the requested size, created at or after the requested time and after the requested cursor, and returning each
+// donation by ID.
+func newAccountServer(t *testing.T, donations []Donation, events ...Event) *accountServer {
+	t.Helper()
+
+	server := &accountServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/donations":
+			server.lists.Add(1)
+			page, hasMore := listPage(r, donations, func(d Donation) (string, time.Time) { return d.ID, d.CreatedAt })
+			_ = json.NewEncoder(w).Encode(donationsResponse{Data: page, HasMore: hasMore})
+			return
+		case "/events":
+			page, hasMore := listPage(r, events, func(e Event) (string, time.Time) { return e.ID, e.CreatedAt })
+			_ = json.NewEncoder(w).Encode(eventsResponse{Data: page, HasMore: hasMore})
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/donations/")
+		for _, donation := range donations {
+			if donation.ID == id {
+				_ = json.NewEncoder(w).Encode(donation)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// listPage returns the page of items a list request asks for, and whether more follow.
+func listPage[T any](r *http.Request, items []T, key func(T) (string, time.Time)) ([]T, bool) {
+	query := r.URL.Query()
+	since, _ := time.Parse(time.RFC3339, query.Get("created[gte]"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	cursor := query.Get("starting_after")
+
+	var listed []T
+	for _, item := range items {
+		id, createdAt := key(item)
+		if createdAt.Before(since) {
+			continue
+		}
+		if cursor != "" {
+			if id == cursor {
+				cursor = ""
+			}
+			continue
+		}
+		listed = append(listed, item)
+	}
+	if len(listed) > limit {
+		return listed[:limit], true
+	}
+	return listed, false
+}
@@ -2,15 +2,39 @@ package fundraiseup
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
 )
 
+// ErrStop can be returned by a DonationsEach, DonationPages or EventPages callback to stop iterating early
+// without an error.
+var ErrStop = errors.New("stop iteration")
+
+// statusError reports a response with an unexpected HTTP status.
+type statusError struct {
+	// body is the response body.
+	body string
+
+	// statusCode is the HTTP status code.
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.statusCode, e.body)
+}
+
+// isNotFound reports whether err is a response saying the requested object does not exist.
+func isNotFound(err error) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.statusCode == http.StatusNotFound
+}
+
 // Client is a FundraiseUp API client.
 type Client struct {
 	// apiKey is the API key for authentication.
@@ -19,8 +43,26 @@ type Client struct {
 	// baseURL is the base URL for API requests.
 	baseURL string
 
+	// campaignID restricts fetched donations to a single campaign when set.
+	campaignID string
+
 	// httpClient is the HTTP client for making requests.
 	httpClient *http.Client
+
+	// maxResponseSize is the largest response body read, in bytes.
+	maxResponseSize int
+
+	// pageSize is the number of donations requested per page.
+	pageSize int
+
+	// status restricts fetched donations to those with the given status when set.
+	status string
+
+	// unknownFields collects payload fields the mapper does not decode, when strict decoding is enabled.
+	unknownFields *unknownFields
+
+	// userAgent is the User-Agent header sent with every request.
+	userAgent string
 }
 
 // Donation fetches a single donation by ID.
@@ -38,6 +80,8 @@ func (c *Client) Donation(ctx context.Context, id string) (*Donation, error) {
 
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	httpclient.AcceptGzip(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -45,13 +89,22 @@ func (c *Client) Donation(ctx context.Context, id string) (*Donation, error) {
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if err := httpclient.Decompress(resp); err != nil {
+		return nil, fmt.Errorf("decompressing response: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		body := httpclient.ReadErrorBody(resp.Body, c.maxResponseSize)
+		return nil, &statusError{body: body, statusCode: resp.StatusCode}
+	}
+
+	body, err := httpclient.ReadBody(resp.Body, c.maxResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
 	var donation Donation
-	if err := json.NewDecoder(resp.Body).Decode(&donation); err != nil {
+	if err := c.decode(body, &donation, "donation"); err != nil {
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
@@ -59,25 +112,106 @@ func (c *Client) Donation(ctx context.Context, id string) (*Donation, error) {
 }
 
 // Donations fetches donations created after the given time.
+// All pages are held in memory; use DonationsEach to process long windows page by page.
 func (c *Client) Donations(ctx context.Context, since time.Time) ([]Donation, error) {
 	var allDonations []Donation
-	var startingAfter string
 
+	err := c.DonationsEach(ctx, since, func(donation Donation) error {
+		allDonations = append(allDonations, donation)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allDonations, nil
+}
+
+// DonationsEach calls fn for each donation created after the given time, fetching one page at a time,
+// so only the current page is held in memory.
+// Iteration stops when fn returns an error; returning ErrStop stops it without DonationsEach returning an error.
+func (c *Client) DonationsEach(ctx context.Context, since time.Time, fn func(Donation) error) error {
+	return c.DonationPages(ctx, since, "", func(donations []Donation) error {
+		for _, donation := range donations {
+			if err := fn(donation); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DonationPages calls fn with each page of donations created after the given time,
+// starting after the donation with ID startingAfter (or from the beginning of the window when empty).
+// The ID of the last donation in a page is the cursor for resuming after that page.
+// Iteration stops when fn returns an error; returning ErrStop stops it without DonationPages returning an error.
+func (c *Client) DonationPages(
+	ctx context.Context,
+	since time.Time,
+	startingAfter string,
+	fn func([]Donation) error,
+) error {
 	for {
+		// Stop between pages once cancelled, rather than waiting for the next request to fail.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		donations, hasMore, err := c.fetchDonationsPage(ctx, since, startingAfter)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		if len(donations) > 0 {
+			if err := fn(donations); err != nil {
+				if errors.Is(err, ErrStop) {
+					return nil
+				}
+				return err
+			}
 		}
-		allDonations = append(allDonations, donations...)
 
 		if !hasMore || len(donations) == 0 {
-			break
+			return nil
 		}
 		// Use the last donation ID as the cursor for the next page.
 		startingAfter = donations[len(donations)-1].ID
 	}
+}
 
-	return allDonations, nil
+// EventPages calls fn with each page of events raised after the given time, oldest first,
+// starting after the event with ID startingAfter (or from the beginning of the window when empty).
+// Iteration stops when fn returns an error; returning ErrStop stops it without EventPages returning an error.
+func (c *Client) EventPages(
+	ctx context.Context,
+	since time.Time,
+	startingAfter string,
+	fn func([]Event) error,
+) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		events, hasMore, err := c.fetchEventsPage(ctx, since, startingAfter)
+		if err != nil {
+			return err
+		}
+
+		if len(events) > 0 {
+			if err := fn(events); err != nil {
+				if errors.Is(err, ErrStop) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if !hasMore || len(events) == 0 {
+			return nil
+		}
+		startingAfter = events[len(events)-1].ID
+	}
 }
 
 // Supporter fetches a supporter by ID.
@@ -95,6 +229,8 @@ func (c *Client) Supporter(ctx context.Context, supporterID string) (*Supporter,
 
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	httpclient.AcceptGzip(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -102,13 +238,22 @@ func (c *Client) Supporter(ctx context.Context, supporterID string) (*Supporter,
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if err := httpclient.Decompress(resp); err != nil {
+		return nil, fmt.Errorf("decompressing response: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		body := httpclient.ReadErrorBody(resp.Body, c.maxResponseSize)
+		return nil, &statusError{body: body, statusCode: resp.StatusCode}
+	}
+
+	body, err := httpclient.ReadBody(resp.Body, c.maxResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
 	var supporter Supporter
-	if err := json.NewDecoder(resp.Body).Decode(&supporter); err != nil {
+	if err := c.decode(body, &supporter, "supporter"); err != nil {
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
@@ -123,7 +268,13 @@ func (c *Client) fetchDonationsPage(
 ) ([]Donation, bool, error) {
 	params := url.Values{}
 	params.Set("created[gte]", since.UTC().Format(time.RFC3339))
-	params.Set("limit", "100")
+	params.Set("limit", strconv.Itoa(c.pageSize))
+	if c.status != "" {
+		params.Set("status", c.status)
+	}
+	if c.campaignID != "" {
+		params.Set("campaign", c.campaignID)
+	}
 	if startingAfter != "" {
 		params.Set("starting_after", startingAfter)
 	}
@@ -137,6 +288,8 @@ func (c *Client) fetchDonationsPage(
 
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	httpclient.AcceptGzip(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -144,13 +297,75 @@ func (c *Client) fetchDonationsPage(
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if err := httpclient.Decompress(resp); err != nil {
+		return nil, false, fmt.Errorf("decompressing response: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, false, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		body := httpclient.ReadErrorBody(resp.Body, c.maxResponseSize)
+		return nil, false, &statusError{body: body, statusCode: resp.StatusCode}
+	}
+
+	body, err := httpclient.ReadBody(resp.Body, c.maxResponseSize)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading response: %w", err)
 	}
 
 	var result donationsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decode(body, &result, "donations"); err != nil {
+		return nil, false, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return result.Data, result.HasMore, nil
+}
+
+// fetchEventsPage fetches a single page of events from the API.
+func (c *Client) fetchEventsPage(
+	ctx context.Context,
+	since time.Time,
+	startingAfter string,
+) ([]Event, bool, error) {
+	params := url.Values{}
+	params.Set("created[gte]", since.UTC().Format(time.RFC3339))
+	params.Set("limit", strconv.Itoa(c.pageSize))
+	if startingAfter != "" {
+		params.Set("starting_after", startingAfter)
+	}
+
+	reqURL := fmt.Sprintf("%s/events?%s", c.baseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	httpclient.AcceptGzip(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("executing request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err := httpclient.Decompress(resp); err != nil {
+		return nil, false, fmt.Errorf("decompressing response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body := httpclient.ReadErrorBody(resp.Body, c.maxResponseSize)
+		return nil, false, &statusError{body: body, statusCode: resp.StatusCode}
+	}
+
+	body, err := httpclient.ReadBody(resp.Body, c.maxResponseSize)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading response: %w", err)
+	}
+
+	var result eventsResponse
+	if err := c.decode(body, &result, "events"); err != nil {
 		return nil, false, fmt.Errorf("decoding response: %w", err)
 	}
 
@@ -172,12 +387,22 @@ func NewClient(apiKey string, opts ...Option) (*Client, error) {
 
 	httpClient := o.httpClient
 	if httpClient == nil {
-		httpClient = &http.Client{Timeout: o.timeout}
+		httpClient = &http.Client{Timeout: o.timeout, Transport: o.transport}
+	}
+
+	client := &Client{
+		apiKey:          apiKey,
+		baseURL:         o.baseURL,
+		campaignID:      o.campaignID,
+		httpClient:      httpClient,
+		maxResponseSize: o.maxResponseSize,
+		pageSize:        o.pageSize,
+		status:          o.status,
+		userAgent:       o.userAgent,
+	}
+	if o.strictDecoding {
+		client.unknownFields = &unknownFields{names: make(map[string]struct{})}
 	}
 
-	return &Client{
-		apiKey:     apiKey,
-		baseURL:    o.baseURL,
-		httpClient: httpClient,
-	}, nil
+	return client, nil
 }
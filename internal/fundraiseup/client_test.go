@@ -1,14 +1,21 @@
 package fundraiseup
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 func TestNewClient(t *testing.T) {
@@ -153,6 +160,56 @@ func TestClient_Donations(t *testing.T) {
 		require.Equal(t, "don_2", result[1].ID)
 	})
 
+	t.Run("sends page size and filters", func(t *testing.T) {
+		t.Parallel()
+
+		var query url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(donationsResponse{})
+		}))
+		defer server.Close()
+
+		client, err := NewClient(
+			"test-key",
+			WithBaseURL(server.URL),
+			WithPageSize(25),
+			WithStatus("succeeded"),
+			WithCampaign("FUNCAMP1"),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Donations(context.Background(), time.Now())
+
+		require.NoError(t, err)
+		require.Equal(t, "25", query.Get("limit"))
+		require.Equal(t, "succeeded", query.Get("status"))
+		require.Equal(t, "FUNCAMP1", query.Get("campaign"))
+	})
+
+	t.Run("omits unset filters", func(t *testing.T) {
+		t.Parallel()
+
+		var query url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(donationsResponse{})
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		_, err = client.Donations(context.Background(), time.Now())
+
+		require.NoError(t, err)
+		require.Equal(t, "100", query.Get("limit"))
+		require.False(t, query.Has("status"))
+		require.False(t, query.Has("campaign"))
+	})
+
 	t.Run("returns error on non-200 response", func(t *testing.T) {
 		t.Parallel()
 
@@ -170,6 +227,258 @@ func TestClient_Donations(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "unexpected status 401")
 	})
+
+	t.Run("refuses a response over the size limit", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockDonationsServer(t, []donationsResponse{
+			{Data: []Donation{{ID: "don_1", Amount: "10.00", Comment: strings.Repeat("x", 2048)}}},
+		})
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL), WithMaxResponseSize(1024))
+		require.NoError(t, err)
+
+		_, err = client.Donations(context.Background(), time.Now())
+
+		require.ErrorIs(t, err, httpclient.ErrBodyTooLarge)
+		require.ErrorContains(t, err, "reading response: response body too large: more than 1024 bytes")
+	})
+}
+
+func TestClient_DonationsEach(t *testing.T) {
+	t.Parallel()
+
+	t.Run("calls fn for each donation across pages", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockDonationsServer(t, []donationsResponse{
+			{Data: []Donation{{ID: "don_1"}, {ID: "don_2"}}, HasMore: true},
+			{Data: []Donation{{ID: "don_3"}}, HasMore: false},
+		})
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		var ids []string
+		err = client.DonationsEach(context.Background(), time.Now(), func(d Donation) error {
+			ids = append(ids, d.ID)
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"don_1", "don_2", "don_3"}, ids)
+	})
+
+	t.Run("stops without fetching further pages on ErrStop", func(t *testing.T) {
+		t.Parallel()
+
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(donationsResponse{
+				Data:    []Donation{{ID: "don_1"}, {ID: "don_2"}},
+				HasMore: true,
+			})
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		var ids []string
+		err = client.DonationsEach(context.Background(), time.Now(), func(d Donation) error {
+			ids = append(ids, d.ID)
+			return ErrStop
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"don_1"}, ids)
+		require.Equal(t, 1, requests)
+	})
+
+	t.Run("returns error from fn", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockDonationsServer(t, []donationsResponse{
+			{Data: []Donation{{ID: "don_1"}}, HasMore: false},
+		})
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		wantErr := errors.New("processing failed")
+		err = client.DonationsEach(context.Background(), time.Now(), func(Donation) error {
+			return wantErr
+		})
+
+		require.ErrorIs(t, err, wantErr)
+	})
+}
+
+func TestClient_DonationPages(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resumes after the starting cursor", func(t *testing.T) {
+		t.Parallel()
+
+		var cursors []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cursor := r.URL.Query().Get("starting_after")
+			cursors = append(cursors, cursor)
+
+			resp := donationsResponse{Data: []Donation{{ID: "don_3"}, {ID: "don_4"}}, HasMore: true}
+			if cursor == "don_4" {
+				resp = donationsResponse{Data: []Donation{{ID: "don_5"}}, HasMore: false}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		var pages [][]string
+		err = client.DonationPages(context.Background(), time.Now(), "don_2", func(donations []Donation) error {
+			var ids []string
+			for _, d := range donations {
+				ids = append(ids, d.ID)
+			}
+			pages = append(pages, ids)
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, [][]string{{"don_3", "don_4"}, {"don_5"}}, pages)
+		require.Equal(t, []string{"don_2", "don_4"}, cursors)
+	})
+
+	t.Run("stops between pages when cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(donationsResponse{Data: []Donation{{ID: "don_1"}}, HasMore: true})
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err = client.DonationPages(ctx, time.Now(), "", func([]Donation) error {
+			cancel()
+			return nil
+		})
+
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, requests)
+	})
+
+	t.Run("stops on ErrStop", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockDonationsServer(t, []donationsResponse{
+			{Data: []Donation{{ID: "don_1"}}, HasMore: true},
+			{Data: []Donation{{ID: "don_2"}}, HasMore: false},
+		})
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		calls := 0
+		err = client.DonationPages(context.Background(), time.Now(), "", func([]Donation) error {
+			calls++
+			return ErrStop
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, calls)
+	})
+}
+
+func TestClient_EventPages(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pages through events after the cursor", func(t *testing.T) {
+		t.Parallel()
+
+		var cursors []string
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			cursor := r.URL.Query().Get("starting_after")
+			cursors = append(cursors, cursor)
+
+			resp := eventsResponse{
+				Data: []Event{
+					{
+						ID:   "evt_1",
+						Type: EventDonationUpdated,
+						Data: EventData{Donation: &Donation{ID: "don_1", Comment: "Edited"}},
+					},
+				},
+				HasMore: true,
+			}
+			if cursor == "evt_1" {
+				resp = eventsResponse{
+					Data: []Event{
+						{
+							ID:   "evt_2",
+							Type: EventSupporterUpdated,
+							Data: EventData{Supporter: &Supporter{ID: "sup_1"}},
+						},
+					},
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		var events []Event
+		err = client.EventPages(context.Background(), time.Now(), "", func(page []Event) error {
+			events = append(events, page...)
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "don_1", events[0].Data.Donation.ID)
+		require.Equal(t, "Edited", events[0].Data.Donation.Comment)
+		require.Equal(t, "sup_1", events[1].Data.Supporter.ID)
+		require.Equal(t, []string{"", "evt_1"}, cursors)
+		require.Equal(t, []string{"/events", "/events"}, paths)
+	})
+
+	t.Run("returns error on failure", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		err = client.EventPages(context.Background(), time.Now(), "", func([]Event) error { return nil })
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected status 404")
+	})
 }
 
 func TestClient_Supporter(t *testing.T) {
@@ -201,6 +510,50 @@ func TestClient_Supporter(t *testing.T) {
 		require.Equal(t, "Doe", result.LastName)
 	})
 
+	t.Run("sends the giftbridge user agent", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, version.UserAgent(""), r.Header.Get("User-Agent"))
+			_ = json.NewEncoder(w).Encode(Supporter{ID: "sup_123"})
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		_, err = client.Supporter(context.Background(), "sup_123")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("decompresses gzip response", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Content-Type", "application/json")
+			zw := gzip.NewWriter(w)
+			_ = json.NewEncoder(zw).Encode(Supporter{ID: "sup_123", Email: "test@example.com"})
+			_ = zw.Close()
+		}))
+		defer server.Close()
+
+		client, err := NewClient("test-key", WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		result, err := client.Supporter(context.Background(), "sup_123")
+
+		require.NoError(t, err)
+		require.Equal(t, "sup_123", result.ID)
+		require.Equal(t, "test@example.com", result.Email)
+	})
+
 	t.Run("returns error on non-200 response", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,115 @@
+package fundraiseup
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// unknownFields collects the names of payload fields with no matching struct field.
+type unknownFields struct {
+	// mu guards names.
+	mu sync.Mutex
+
+	// names holds each unknown field's path, such as "donation.payment.wallet".
+	names map[string]struct{}
+}
+
+// UnknownFields returns the paths of payload fields seen by this client that the mapper does not decode,
+// in sorted order. Always empty unless the client was created with WithStrictDecoding.
+func (c *Client) UnknownFields() []string {
+	if c.unknownFields == nil {
+		return nil
+	}
+
+	c.unknownFields.mu.Lock()
+	defer c.unknownFields.mu.Unlock()
+
+	names := make([]string, 0, len(c.unknownFields.names))
+	for name := range c.unknownFields.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decode unmarshals a response body into v. In strict mode, fields in the body with no matching
+// struct field are recorded under root rather than rejected, so new payload fields never fail a sync.
+func (c *Client) decode(body []byte, v any, root string) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return err
+	}
+	if c.unknownFields == nil {
+		return nil
+	}
+
+	// Most payloads match the structs exactly, so only walk the body when the strict decoder objects.
+	strict := json.NewDecoder(bytes.NewReader(body))
+	strict.DisallowUnknownFields()
+	if err := strict.Decode(reflect.New(reflect.TypeOf(v).Elem()).Interface()); err == nil {
+		return nil
+	}
+
+	c.unknownFields.mu.Lock()
+	defer c.unknownFields.mu.Unlock()
+	collectUnknownFields(body, reflect.TypeOf(v), root, c.unknownFields.names)
+
+	return nil
+}
+
+// collectUnknownFields adds the path of each JSON object key in data with no matching field in t to found.
+// Array elements are marked with "[]" in the path.
+func collectUnknownFields(data []byte, t reflect.Type, path string, found map[string]struct{}) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return
+		}
+		known := jsonFields(t)
+		for name, raw := range fields {
+			fieldType, ok := known[strings.ToLower(name)]
+			if !ok {
+				found[path+"."+name] = struct{}{}
+				continue
+			}
+			collectUnknownFields(raw, fieldType, path+"."+name, found)
+		}
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return
+		}
+		for _, item := range items {
+			collectUnknownFields(item, t.Elem(), path+"[]", found)
+		}
+	}
+}
+
+// jsonFields returns the types of a struct's JSON fields, keyed by lowercased name
+// since encoding/json matches keys case-insensitively.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
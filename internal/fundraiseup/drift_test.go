@@ -0,0 +1,92 @@
+package fundraiseup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_UnknownFields(t *testing.T) {
+	t.Parallel()
+
+	page := `{
+		"data": [
+			{
+				"id": "don_1",
+				"amount": "10.00",
+				"Currency": "GBP",
+				"created_at": "2024-01-15T10:30:00Z",
+				"fee": "0.50",
+				"payment": {"method": "credit_card", "wallet": "apple_pay"},
+				"supporter": {"id": "sup_1", "email": "ada@example.com", "pronouns": "she/her"}
+			},
+			{"id": "don_2", "amount": "5.00", "fee": "0.25", "tags": [{"name": "gala"}]}
+		],
+		"has_more": false,
+		"total": 2
+	}`
+
+	tests := map[string]struct {
+		opts []Option
+		want []string
+	}{
+		"strict decoding records unknown fields": {
+			opts: []Option{WithStrictDecoding()},
+			want: []string{
+				"donations.data[].fee",
+				"donations.data[].payment.wallet",
+				"donations.data[].supporter.pronouns",
+				"donations.data[].tags",
+				"donations.total",
+			},
+		},
+		"unknown fields ignored by default": {
+			want: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(page))
+			}))
+			defer server.Close()
+
+			client, err := NewClient("test-key", append(tc.opts, WithBaseURL(server.URL))...)
+			require.NoError(t, err)
+
+			donations, err := client.Donations(context.Background(), time.Time{})
+
+			require.NoError(t, err)
+			require.Len(t, donations, 2)
+			require.Equal(t, "GBP", donations[0].Currency)
+			require.Equal(t, tc.want, client.UnknownFields())
+		})
+	}
+}
+
+func TestClient_UnknownFieldsMatchingPayload(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "sup_1", "email": "ada@example.com", "address": {"city": "London"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-key", WithBaseURL(server.URL), WithStrictDecoding())
+	require.NoError(t, err)
+
+	supporter, err := client.Supporter(context.Background(), "sup_1")
+
+	require.NoError(t, err)
+	require.Equal(t, "London", supporter.Address.City)
+	require.Empty(t, client.UnknownFields())
+}
@@ -3,11 +3,40 @@ package fundraiseup
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/normalize"
 )
 
+// Issues returns problems found when mapping the address to Raiser's Edge NXT.
+// An address with issues is still mapped, with the affected values stored as supplied.
+func (a *Address) Issues() []string {
+	if a == nil {
+		return nil
+	}
+
+	var issues []string
+
+	code, _, ok := normalize.Country(a.Country)
+	if !ok && a.Country != "" {
+		issues = append(issues, fmt.Sprintf("unrecognised country %q stored as supplied", a.Country))
+	}
+
+	if ok {
+		if _, valid := normalize.PostCode(code, a.PostalCode); !valid {
+			issues = append(issues,
+				fmt.Sprintf("post code %q is not valid for %s, stored as supplied", a.PostalCode, code))
+		}
+	}
+
+	return issues
+}
+
 // ToDomainType converts an Address to its Blackbaud domain representation.
+// Country codes and names are mapped to the names Raiser's Edge NXT expects, the region is
+// stored as a county for countries that use them, and post codes are formatted for the country.
+// Values that cannot be mapped are stored as supplied; see Issues.
 func (a *Address) ToDomainType() *blackbaud.Address {
 	if a == nil {
 		return nil
@@ -18,7 +47,7 @@ func (a *Address) ToDomainType() *blackbaud.Address {
 		lines = fmt.Sprintf("%s\n%s", a.Line1, a.Line2)
 	}
 
-	return &blackbaud.Address{
+	address := &blackbaud.Address{
 		AddressLines: lines,
 		City:         a.City,
 		Country:      a.Country,
@@ -27,6 +56,22 @@ func (a *Address) ToDomainType() *blackbaud.Address {
 		State:        a.Region,
 		Type:         "Home",
 	}
+
+	code, name, ok := normalize.Country(a.Country)
+	if !ok {
+		return address
+	}
+
+	address.Country = name
+	if postCode, valid := normalize.PostCode(code, a.PostalCode); valid {
+		address.PostCode = postCode
+	}
+	if normalize.IsCountyCountry(code) {
+		address.County = a.Region
+		address.State = ""
+	}
+
+	return address
 }
 
 // ToDomainType converts a Donation to its Blackbaud domain representation.
@@ -50,6 +95,7 @@ func (d *Donation) ToDomainType() (*blackbaud.Gift, error) {
 
 	if d.Payment != nil && d.Payment.Method != "" {
 		gift.PaymentMethod = d.Payment.Method.ToDomainType()
+		gift.Payments = []blackbaud.GiftPayment{d.Payment.ToDomainType()}
 	}
 
 	if d.Comment != "" {
@@ -59,11 +105,75 @@ func (d *Donation) ToDomainType() (*blackbaud.Gift, error) {
 	return gift, nil
 }
 
+// ToDomainType converts a Payment to its Blackbaud representation.
+// Card payments are referenced by brand and last four digits, such as "Visa ending 4242", and direct debits by
+// mandate and collection, such as "Mandate MD-1001, collection CL-2024-03", so gifts can be reconciled against
+// the payment processor and bank statements.
+func (p *Payment) ToDomainType() blackbaud.GiftPayment {
+	payment := blackbaud.GiftPayment{
+		CheckNumber:   strings.TrimSpace(p.CheckNumber),
+		PaymentMethod: p.Method.ToDomainType(),
+	}
+
+	if p.Method.directDebit() {
+		payment.Reference = directDebitReference(p.MandateReference, p.CollectionReference)
+		return payment
+	}
+
+	brand := cardBrandName(p.CardBrand)
+	last4 := strings.TrimSpace(p.CardLast4)
+	switch {
+	case brand != "" && last4 != "":
+		payment.Reference = fmt.Sprintf("%s ending %s", brand, last4)
+	case last4 != "":
+		payment.Reference = "Card ending " + last4
+	case brand != "":
+		payment.Reference = brand
+	}
+
+	return payment
+}
+
+// cardBrandName returns the display name of a FundraiseUp card brand, or the brand as supplied if unrecognised.
+func cardBrandName(brand string) string {
+	brand = strings.TrimSpace(brand)
+	switch strings.ToLower(brand) {
+	case "amex", "american_express":
+		return "American Express"
+	case "diners", "diners_club":
+		return "Diners Club"
+	case "discover":
+		return "Discover"
+	case "jcb":
+		return "JCB"
+	case "mastercard":
+		return "Mastercard"
+	case "unionpay":
+		return "UnionPay"
+	case "visa":
+		return "Visa"
+	default:
+		return brand
+	}
+}
+
+// directDebit reports whether the payment method collects from the donor's bank account: BACS, SEPA or ACH.
+func (pm PaymentMethod) directDebit() bool {
+	switch pm {
+	case PaymentMethodACH, PaymentMethodBankTransfer, PaymentMethodSEPA:
+		return true
+	default:
+		return false
+	}
+}
+
 // ToDomainType converts a PaymentMethod to its Blackbaud payment method string.
 func (pm PaymentMethod) ToDomainType() string {
 	switch pm {
 	case PaymentMethodCard, PaymentMethodApplePay, PaymentMethodGooglePay:
 		return "Credit card"
+	case PaymentMethodCheck:
+		return "Personal check"
 	case PaymentMethodBankTransfer, PaymentMethodACH, PaymentMethodSEPA:
 		return "Direct debit"
 	case PaymentMethodPayPal:
@@ -73,6 +183,23 @@ func (pm PaymentMethod) ToDomainType() string {
 	}
 }
 
+// directDebitReference returns the reference of a direct debit payment from its mandate and collection,
+// either of which may be missing.
+func directDebitReference(mandate string, collection string) string {
+	mandate = strings.TrimSpace(mandate)
+	collection = strings.TrimSpace(collection)
+	switch {
+	case mandate != "" && collection != "":
+		return fmt.Sprintf("Mandate %s, collection %s", mandate, collection)
+	case mandate != "":
+		return "Mandate " + mandate
+	case collection != "":
+		return "Collection " + collection
+	default:
+		return ""
+	}
+}
+
 // ToDomainType converts a Supporter to its Blackbaud domain representation.
 func (s *Supporter) ToDomainType() *blackbaud.Constituent {
 	if s == nil {
@@ -116,11 +243,23 @@ func (d *Donation) InstallmentNumber() int {
 	return n
 }
 
+// IsChargedBack returns true if the donor's bank has reversed the donation's payment or the donor is disputing it.
+func (d *Donation) IsChargedBack() bool {
+	return d != nil && (strings.EqualFold(d.Status, DonationStatusChargedBack) ||
+		strings.EqualFold(d.Status, DonationStatusDisputed))
+}
+
 // IsRecurring returns true if the donation is part of a recurring plan.
 func (d *Donation) IsRecurring() bool {
 	return d != nil && d.RecurringPlan != nil
 }
 
+// IsTest returns true if the donation was made in test mode, so no money changed hands.
+// Donations whose mode is not reported are treated as live.
+func (d *Donation) IsTest() bool {
+	return d != nil && d.Livemode != nil && !*d.Livemode
+}
+
 // RecurringID returns the recurring plan ID, or empty string if not recurring.
 func (d *Donation) RecurringID() string {
 	if d == nil || d.RecurringPlan == nil {
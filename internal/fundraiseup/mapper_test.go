@@ -9,6 +9,44 @@ import (
 	"github.com/peteski22/giftbridge/internal/blackbaud"
 )
 
+func TestAddress_Issues(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		address *Address
+		want    []string
+	}{
+		"nil address": {
+			address: nil,
+			want:    nil,
+		},
+		"valid address": {
+			address: &Address{Country: "GB", PostalCode: "SW1A 1AA"},
+			want:    nil,
+		},
+		"missing country": {
+			address: &Address{PostalCode: "SW1A 1AA"},
+			want:    nil,
+		},
+		"unrecognised country": {
+			address: &Address{Country: "Atlantis", PostalCode: "abc"},
+			want:    []string{`unrecognised country "Atlantis" stored as supplied`},
+		},
+		"invalid post code": {
+			address: &Address{Country: "US", PostalCode: "1002"},
+			want:    []string{`post code "1002" is not valid for US, stored as supplied`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, tc.address.Issues())
+		})
+	}
+}
+
 func TestAddress_ToDomainType(t *testing.T) {
 	t.Parallel()
 
@@ -31,10 +69,10 @@ func TestAddress_ToDomainType(t *testing.T) {
 			want: &blackbaud.Address{
 				AddressLines: "123 Main Street",
 				City:         "London",
-				Country:      "UK",
+				Country:      "United Kingdom",
+				County:       "England",
 				PostCode:     "SW1A 1AA",
 				Primary:      true,
-				State:        "England",
 				Type:         "Home",
 			},
 		},
@@ -50,13 +88,67 @@ func TestAddress_ToDomainType(t *testing.T) {
 			want: &blackbaud.Address{
 				AddressLines: "456 Park Ave\nSuite 100",
 				City:         "New York",
-				Country:      "USA",
+				Country:      "United States",
 				PostCode:     "10022",
 				Primary:      true,
 				State:        "NY",
 				Type:         "Home",
 			},
 		},
+		"canadian province and post code": {
+			address: &Address{
+				City:       "Ottawa",
+				Country:    "CA",
+				Line1:      "24 Sussex Drive",
+				PostalCode: "k1m1m4",
+				Region:     "ON",
+			},
+			want: &blackbaud.Address{
+				AddressLines: "24 Sussex Drive",
+				City:         "Ottawa",
+				Country:      "Canada",
+				PostCode:     "K1M 1M4",
+				Primary:      true,
+				State:        "ON",
+				Type:         "Home",
+			},
+		},
+		"invalid post code is stored as supplied": {
+			address: &Address{
+				City:       "Leeds",
+				Country:    "GB",
+				Line1:      "1 High Street",
+				PostalCode: "LS1",
+				Region:     "West Yorkshire",
+			},
+			want: &blackbaud.Address{
+				AddressLines: "1 High Street",
+				City:         "Leeds",
+				Country:      "United Kingdom",
+				County:       "West Yorkshire",
+				PostCode:     "LS1",
+				Primary:      true,
+				Type:         "Home",
+			},
+		},
+		"unrecognised country is stored as supplied": {
+			address: &Address{
+				City:       "Somewhere",
+				Country:    "Atlantis",
+				Line1:      "1 Sea Road",
+				PostalCode: "abc",
+				Region:     "Deep",
+			},
+			want: &blackbaud.Address{
+				AddressLines: "1 Sea Road",
+				City:         "Somewhere",
+				Country:      "Atlantis",
+				PostCode:     "abc",
+				Primary:      true,
+				State:        "Deep",
+				Type:         "Home",
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -96,6 +188,22 @@ func TestDonation_ToDomainType(t *testing.T) {
 				Amount:        &blackbaud.GiftAmount{Value: 50.00},
 				Date:          "2024-01-15",
 				PaymentMethod: "Credit card",
+				Payments:      []blackbaud.GiftPayment{{PaymentMethod: "Credit card"}},
+			},
+			wantErr: false,
+		},
+		"donation with card details": {
+			donation: &Donation{
+				Amount:    "50.00",
+				CreatedAt: createdAt,
+				ID:        "don_456",
+				Payment:   &Payment{CardBrand: "visa", CardLast4: "4242", Method: PaymentMethodApplePay},
+			},
+			want: &blackbaud.Gift{
+				Amount:        &blackbaud.GiftAmount{Value: 50.00},
+				Date:          "2024-01-15",
+				PaymentMethod: "Credit card",
+				Payments:      []blackbaud.GiftPayment{{PaymentMethod: "Credit card", Reference: "Visa ending 4242"}},
 			},
 			wantErr: false,
 		},
@@ -111,6 +219,7 @@ func TestDonation_ToDomainType(t *testing.T) {
 				Amount:        &blackbaud.GiftAmount{Value: 100.00},
 				Date:          "2024-01-15",
 				PaymentMethod: "PayPal",
+				Payments:      []blackbaud.GiftPayment{{PaymentMethod: "PayPal"}},
 				Reference:     "In memory of John",
 			},
 			wantErr: false,
@@ -174,6 +283,10 @@ func TestPaymentMethod_ToDomainType(t *testing.T) {
 			pm:   PaymentMethodGooglePay,
 			want: "Credit card",
 		},
+		"check": {
+			pm:   PaymentMethodCheck,
+			want: "Personal check",
+		},
 		"bank transfer": {
 			pm:   PaymentMethodBankTransfer,
 			want: "Direct debit",
@@ -278,7 +391,7 @@ func TestSupporter_ToDomainType(t *testing.T) {
 				Address: &blackbaud.Address{
 					AddressLines: "123 Main St",
 					City:         "London",
-					Country:      "UK",
+					Country:      "United Kingdom",
 					PostCode:     "SW1A 1AA",
 					Primary:      true,
 					Type:         "Home",
@@ -308,7 +421,7 @@ func TestSupporter_ToDomainType(t *testing.T) {
 				Address: &blackbaud.Address{
 					AddressLines: "456 Park Ave\nApt 5",
 					City:         "New York",
-					Country:      "USA",
+					Country:      "United States",
 					PostCode:     "10022",
 					Primary:      true,
 					State:        "NY",
@@ -341,3 +454,149 @@ func TestSupporter_ToDomainType(t *testing.T) {
 		})
 	}
 }
+
+func TestPayment_ToDomainType(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		payment *Payment
+		want    blackbaud.GiftPayment
+	}{
+		"method only": {
+			payment: &Payment{Method: PaymentMethodPayPal},
+			want:    blackbaud.GiftPayment{PaymentMethod: "PayPal"},
+		},
+		"card brand and last four": {
+			payment: &Payment{CardBrand: "mastercard", CardLast4: "5555", Method: PaymentMethodCard},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Credit card", Reference: "Mastercard ending 5555"},
+		},
+		"american express": {
+			payment: &Payment{CardBrand: "AMEX", CardLast4: "0005", Method: PaymentMethodCard},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Credit card", Reference: "American Express ending 0005"},
+		},
+		"unrecognised brand kept as supplied": {
+			payment: &Payment{CardBrand: "Maestro", CardLast4: "0604", Method: PaymentMethodCard},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Credit card", Reference: "Maestro ending 0604"},
+		},
+		"last four without brand": {
+			payment: &Payment{CardLast4: " 4242 ", Method: PaymentMethodCard},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Credit card", Reference: "Card ending 4242"},
+		},
+		"brand without last four": {
+			payment: &Payment{CardBrand: "visa", Method: PaymentMethodGooglePay},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Credit card", Reference: "Visa"},
+		},
+		"check number": {
+			payment: &Payment{CheckNumber: " 001234 ", Method: PaymentMethodCheck},
+			want:    blackbaud.GiftPayment{CheckNumber: "001234", PaymentMethod: "Personal check"},
+		},
+		"bacs mandate and collection": {
+			payment: &Payment{
+				CollectionReference: "CL-2024-03",
+				MandateReference:    " MD-1001 ",
+				Method:              PaymentMethodBankTransfer,
+			},
+			want: blackbaud.GiftPayment{
+				PaymentMethod: "Direct debit",
+				Reference:     "Mandate MD-1001, collection CL-2024-03",
+			},
+		},
+		"sepa mandate only": {
+			payment: &Payment{MandateReference: "SEPA-77", Method: PaymentMethodSEPA},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Direct debit", Reference: "Mandate SEPA-77"},
+		},
+		"ach collection only": {
+			payment: &Payment{CollectionReference: "ACH-0042", Method: PaymentMethodACH},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Direct debit", Reference: "Collection ACH-0042"},
+		},
+		"direct debit ignores card details": {
+			payment: &Payment{CardLast4: "4242", MandateReference: "MD-1001", Method: PaymentMethodBankTransfer},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Direct debit", Reference: "Mandate MD-1001"},
+		},
+		"card ignores mandate": {
+			payment: &Payment{CardLast4: "4242", MandateReference: "MD-1001", Method: PaymentMethodCard},
+			want:    blackbaud.GiftPayment{PaymentMethod: "Credit card", Reference: "Card ending 4242"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.payment.ToDomainType()
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDonation_IsChargedBack(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		donation *Donation
+		want     bool
+	}{
+		"nil donation": {
+			donation: nil,
+			want:     false,
+		},
+		"succeeded": {
+			donation: &Donation{ID: "don_123", Status: "succeeded"},
+			want:     false,
+		},
+		"disputed": {
+			donation: &Donation{ID: "don_123", Status: DonationStatusDisputed},
+			want:     true,
+		},
+		"charged back": {
+			donation: &Donation{ID: "don_123", Status: "Charged_Back"},
+			want:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, tc.donation.IsChargedBack())
+		})
+	}
+}
+
+func TestDonation_IsTest(t *testing.T) {
+	t.Parallel()
+
+	live := true
+	test := false
+
+	tests := map[string]struct {
+		donation *Donation
+		want     bool
+	}{
+		"nil donation": {
+			donation: nil,
+			want:     false,
+		},
+		"mode not reported": {
+			donation: &Donation{ID: "don_123"},
+			want:     false,
+		},
+		"live mode": {
+			donation: &Donation{ID: "don_123", Livemode: &live},
+			want:     false,
+		},
+		"test mode": {
+			donation: &Donation{ID: "don_123", Livemode: &test},
+			want:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, tc.donation.IsTest())
+		})
+	}
+}
@@ -5,6 +5,17 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+	"github.com/peteski22/giftbridge/internal/version"
+)
+
+const (
+	// defaultPageSize is the number of donations requested per page.
+	defaultPageSize = 100
+
+	// maxPageSize is the largest page size the FundraiseUp API accepts.
+	maxPageSize = 100
 )
 
 // Option configures optional Client settings.
@@ -15,11 +26,32 @@ type options struct {
 	// baseURL is the base URL for API requests.
 	baseURL string
 
+	// campaignID restricts fetched donations to a single FundraiseUp campaign.
+	campaignID string
+
 	// httpClient is a custom HTTP client.
 	httpClient *http.Client
 
+	// maxResponseSize is the largest response body read, in bytes.
+	maxResponseSize int
+
+	// pageSize is the number of donations requested per page.
+	pageSize int
+
+	// status restricts fetched donations to those with the given status.
+	status string
+
+	// strictDecoding records payload fields the mapper does not decode.
+	strictDecoding bool
+
 	// timeout is the HTTP client timeout.
 	timeout time.Duration
+
+	// transport is the HTTP transport used when no custom HTTP client is set.
+	transport http.RoundTripper
+
+	// userAgent is the User-Agent header sent with every request.
+	userAgent string
 }
 
 // WithBaseURL sets a custom base URL for the API.
@@ -34,6 +66,18 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
+// WithCampaign restricts fetched donations to a single FundraiseUp campaign.
+func WithCampaign(campaignID string) Option {
+	return func(o *options) error {
+		campaignID = strings.TrimSpace(campaignID)
+		if campaignID == "" {
+			return fmt.Errorf("campaign ID cannot be empty")
+		}
+		o.campaignID = campaignID
+		return nil
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client. Overrides WithTimeout.
 func WithHTTPClient(httpClient *http.Client) Option {
 	return func(o *options) error {
@@ -45,6 +89,50 @@ func WithHTTPClient(httpClient *http.Client) Option {
 	}
 }
 
+// WithMaxResponseSize sets the largest response body read, in bytes, after decompression. Longer responses fail
+// rather than being held in memory. Defaults to httpclient.DefaultMaxBodySize.
+func WithMaxResponseSize(size int) Option {
+	return func(o *options) error {
+		if size <= 0 {
+			return fmt.Errorf("max response size must be positive, got %d", size)
+		}
+		o.maxResponseSize = size
+		return nil
+	}
+}
+
+// WithPageSize sets the number of donations requested per page (at most 100).
+func WithPageSize(size int) Option {
+	return func(o *options) error {
+		if size < 1 || size > maxPageSize {
+			return fmt.Errorf("page size must be between 1 and %d, got %d", maxPageSize, size)
+		}
+		o.pageSize = size
+		return nil
+	}
+}
+
+// WithStatus restricts fetched donations to those with the given status (e.g., "succeeded").
+func WithStatus(status string) Option {
+	return func(o *options) error {
+		status = strings.TrimSpace(status)
+		if status == "" {
+			return fmt.Errorf("status cannot be empty")
+		}
+		o.status = status
+		return nil
+	}
+}
+
+// WithStrictDecoding records the payload fields FundraiseUp sends that the mapper does not decode,
+// available from Client.UnknownFields. Unknown fields are still ignored, so decoding never fails because of them.
+func WithStrictDecoding() Option {
+	return func(o *options) error {
+		o.strictDecoding = true
+		return nil
+	}
+}
+
 // WithTimeout sets the HTTP client timeout.
 func WithTimeout(timeout time.Duration) Option {
 	return func(o *options) error {
@@ -56,10 +144,37 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithTransport sets the HTTP transport, so connections can be shared with other clients.
+// Ignored when WithHTTPClient is used.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) error {
+		if transport == nil {
+			return fmt.Errorf("transport cannot be nil")
+		}
+		o.transport = transport
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request, replacing the default giftbridge version.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) error {
+		userAgent = strings.TrimSpace(userAgent)
+		if userAgent == "" {
+			return fmt.Errorf("user agent cannot be empty")
+		}
+		o.userAgent = userAgent
+		return nil
+	}
+}
+
 // defaultOptions returns options with sensible defaults.
 func defaultOptions() *options {
 	return &options{
-		baseURL: "https://api.fundraiseup.com/v1",
-		timeout: 30 * time.Second,
+		baseURL:         "https://api.fundraiseup.com/v1",
+		maxResponseSize: httpclient.DefaultMaxBodySize,
+		pageSize:        defaultPageSize,
+		timeout:         30 * time.Second,
+		userAgent:       version.UserAgent(""),
 	}
 }
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 func TestDefaultOptions(t *testing.T) {
@@ -15,6 +18,11 @@ func TestDefaultOptions(t *testing.T) {
 
 	require.Equal(t, "https://api.fundraiseup.com/v1", opts.baseURL)
 	require.Equal(t, 30*time.Second, opts.timeout)
+	require.Equal(t, version.UserAgent(""), opts.userAgent)
+	require.Equal(t, defaultPageSize, opts.pageSize)
+	require.Equal(t, httpclient.DefaultMaxBodySize, opts.maxResponseSize)
+	require.Empty(t, opts.campaignID)
+	require.Empty(t, opts.status)
 	require.Nil(t, opts.httpClient)
 }
 
@@ -139,3 +147,202 @@ func TestWithTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestWithTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		transport http.RoundTripper
+		wantErr   bool
+	}{
+		"valid transport": {
+			transport: &http.Transport{},
+			wantErr:   false,
+		},
+		"nil transport": {
+			transport: nil,
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithTransport(tc.transport)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "transport cannot be nil")
+			} else {
+				require.NoError(t, err)
+				require.Same(t, tc.transport, opts.transport)
+			}
+		})
+	}
+}
+
+func TestWithPageSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		size    int
+		wantErr bool
+	}{
+		"minimum": {
+			size: 1,
+		},
+		"maximum": {
+			size: maxPageSize,
+		},
+		"zero": {
+			size:    0,
+			wantErr: true,
+		},
+		"above maximum": {
+			size:    maxPageSize + 1,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithPageSize(tc.size)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "page size must be between 1 and 100")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.size, opts.pageSize)
+			}
+		})
+	}
+}
+
+func TestWithMaxResponseSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		size    int
+		wantErr bool
+	}{
+		"positive": {
+			size: 1 << 20,
+		},
+		"zero": {
+			size:    0,
+			wantErr: true,
+		},
+		"negative": {
+			size:    -1,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithMaxResponseSize(tc.size)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "max response size must be positive")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.size, opts.maxResponseSize)
+			}
+		})
+	}
+}
+
+func TestDonationFilterOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg       string
+		opt          Option
+		wantCampaign string
+		wantErr      bool
+		wantStatus   string
+	}{
+		"status": {
+			opt:        WithStatus(" succeeded "),
+			wantStatus: "succeeded",
+		},
+		"empty status": {
+			opt:     WithStatus("  "),
+			wantErr: true,
+			errMsg:  "status cannot be empty",
+		},
+		"campaign": {
+			opt:          WithCampaign("FUNCAMP1"),
+			wantCampaign: "FUNCAMP1",
+		},
+		"empty campaign": {
+			opt:     WithCampaign(""),
+			wantErr: true,
+			errMsg:  "campaign ID cannot be empty",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := tc.opt(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantCampaign, opts.campaignID)
+				require.Equal(t, tc.wantStatus, opts.status)
+			}
+		})
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		userAgent string
+		expected  string
+		wantErr   bool
+	}{
+		"custom user agent": {
+			userAgent: " giftbridge/v1.4.0 (st-marys-hospice) ",
+			expected:  "giftbridge/v1.4.0 (st-marys-hospice)",
+		},
+		"empty user agent": {
+			userAgent: " ",
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := defaultOptions()
+			err := WithUserAgent(tc.userAgent)(opts)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "user agent cannot be empty")
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, opts.userAgent)
+			}
+		})
+	}
+}
@@ -10,6 +10,9 @@ const (
 	// PaymentMethodApplePay represents an Apple Pay payment.
 	PaymentMethodApplePay PaymentMethod = "apple_pay"
 
+	// PaymentMethodCheck represents a payment by check, typically entered offline.
+	PaymentMethodCheck PaymentMethod = "check"
+
 	// PaymentMethodBankTransfer represents a bank transfer payment.
 	PaymentMethodBankTransfer PaymentMethod = "bacs_direct_debit"
 
@@ -26,6 +29,23 @@ const (
 	PaymentMethodSEPA PaymentMethod = "sepa_direct_debit"
 )
 
+const (
+	// EventDonationUpdated is raised when a donation is changed after it was made,
+	// such as when the donor edits their comment.
+	EventDonationUpdated = "donation.updated"
+
+	// EventSupporterUpdated is raised when a supporter's details, such as their name, are corrected.
+	EventSupporterUpdated = "supporter.updated"
+)
+
+const (
+	// DonationStatusChargedBack is the status of a donation whose payment the donor's bank has reversed.
+	DonationStatusChargedBack = "charged_back"
+
+	// DonationStatusDisputed is the status of a donation whose payment the donor is disputing with their bank.
+	DonationStatusDisputed = "disputed"
+)
+
 // Address represents a supporter's address.
 type Address struct {
 	// City is the city name.
@@ -58,6 +78,10 @@ type Campaign struct {
 
 // Donation represents a donation from FundraiseUp.
 type Donation struct {
+	// Account is the name of the FundraiseUp account the donation was fetched from, when donations from several
+	// accounts are merged by Accounts. Empty for the main account's donations.
+	Account string `json:"-"`
+
 	// Amount is the donation amount as a decimal string.
 	Amount string `json:"amount"`
 
@@ -82,9 +106,16 @@ type Donation struct {
 	// Installment is the installment number for recurring donations (e.g., "1", "2").
 	Installment string `json:"installment"`
 
+	// Livemode is false for donations made in FundraiseUp's test mode, such as with a test card,
+	// and nil when the API does not report it.
+	Livemode *bool `json:"livemode"`
+
 	// Payment contains payment details.
 	Payment *Payment `json:"payment"`
 
+	// Payout is the payment processor payout that settled the donation, nil until it has been paid out.
+	Payout *Payout `json:"payout"`
+
 	// RecurringPlan contains recurring plan details, nil for one-off donations.
 	RecurringPlan *RecurringPlan `json:"recurring_plan"`
 
@@ -93,6 +124,12 @@ type Donation struct {
 
 	// Supporter is the person who made the donation.
 	Supporter *Supporter `json:"supporter"`
+
+	// Ticket is the event ticket the donation bought, nil for donations not made through an event.
+	Ticket *Ticket `json:"ticket"`
+
+	// Tracking describes where the donation came from, nil when the API does not report it.
+	Tracking *Tracking `json:"tracking"`
 }
 
 // Designation represents a fund designation.
@@ -104,8 +141,48 @@ type Designation struct {
 	Name string `json:"name"`
 }
 
+// Event records a change to a donation or supporter in FundraiseUp.
+type Event struct {
+	// CreatedAt is when the change was made.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Data holds the changed objects as they are after the change.
+	Data EventData `json:"data"`
+
+	// ID is the unique event identifier.
+	ID string `json:"id"`
+
+	// Type is the kind of change, such as EventDonationUpdated.
+	Type string `json:"type"`
+}
+
+// EventData holds the objects an event refers to. Which fields are set depends on the event type.
+type EventData struct {
+	// Donation is the donation, set for donation events.
+	Donation *Donation `json:"donation"`
+
+	// Supporter is the supporter, set for supporter events.
+	Supporter *Supporter `json:"supporter"`
+}
+
 // Payment contains payment details for a donation.
 type Payment struct {
+	// CardBrand is the card network, such as "visa", for card and wallet payments.
+	CardBrand string `json:"card_brand"`
+
+	// CardLast4 is the last four digits of the card number, for card and wallet payments.
+	CardLast4 string `json:"card_last4"`
+
+	// CheckNumber is the check number, for payments by check.
+	CheckNumber string `json:"check_number"`
+
+	// CollectionReference identifies the collection the payment was taken in, for direct debit payments.
+	CollectionReference string `json:"collection_reference"`
+
+	// MandateReference identifies the mandate authorising the collection, for direct debit payments.
+	// It appears on the donor's bank statement.
+	MandateReference string `json:"mandate_reference"`
+
 	// Method is the payment method used.
 	Method PaymentMethod `json:"method"`
 }
@@ -113,6 +190,18 @@ type Payment struct {
 // PaymentMethod represents a FundraiseUp payment method.
 type PaymentMethod string
 
+// Payout represents a transfer from a payment processor, such as Stripe or PayPal, to the organisation's bank.
+type Payout struct {
+	// ArrivalDate is the date the payout reaches the bank, in YYYY-MM-DD format.
+	ArrivalDate string `json:"arrival_date"`
+
+	// ID is the payment processor's payout identifier.
+	ID string `json:"id"`
+
+	// Processor is the payment processor that made the payout (e.g., "stripe", "paypal", "venmo").
+	Processor string `json:"processor"`
+}
+
 // RecurringPlan represents a recurring donation plan.
 type RecurringPlan struct {
 	// CreatedAt is when the recurring plan was created.
@@ -155,6 +244,48 @@ type Supporter struct {
 	Phone string `json:"phone"`
 }
 
+// Ticket is an event ticket bought with a donation.
+type Ticket struct {
+	// EventID is the unique identifier of the FundraiseUp event.
+	EventID string `json:"event_id"`
+
+	// EventName is the event name.
+	EventName string `json:"event_name"`
+
+	// Quantity is the number of tickets bought.
+	Quantity int `json:"quantity"`
+}
+
+// Tracking describes the visit a donation was made on.
+type Tracking struct {
+	// Device is the kind of device the donation was made on, such as "desktop" or "mobile".
+	Device string `json:"device"`
+
+	// UTMCampaign is the utm_campaign parameter of the page the donation was made on.
+	UTMCampaign string `json:"utm_campaign"`
+
+	// UTMContent is the utm_content parameter of the page the donation was made on.
+	UTMContent string `json:"utm_content"`
+
+	// UTMMedium is the utm_medium parameter of the page the donation was made on.
+	UTMMedium string `json:"utm_medium"`
+
+	// UTMSource is the utm_source parameter of the page the donation was made on.
+	UTMSource string `json:"utm_source"`
+
+	// UTMTerm is the utm_term parameter of the page the donation was made on.
+	UTMTerm string `json:"utm_term"`
+}
+
+// eventsResponse represents the API response for listing events.
+type eventsResponse struct {
+	// Data contains the list of events.
+	Data []Event `json:"data"`
+
+	// HasMore indicates if there are more results.
+	HasMore bool `json:"has_more"`
+}
+
 // donationsResponse represents the API response for listing donations.
 type donationsResponse struct {
 	// Data contains the list of donations.
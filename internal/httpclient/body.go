@@ -0,0 +1,34 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxBodySize is the largest response body read by default, far above any API page GiftBridge requests but
+// well within the memory of the smallest Lambda.
+const DefaultMaxBodySize = 16 << 20
+
+// ErrBodyTooLarge reports a response body longer than the limit it was read with.
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// ReadBody reads all of r, failing with ErrBodyTooLarge rather than reading more than limit bytes. Read a
+// decompressed body through it so a small gzipped response cannot expand past the limit in memory.
+func ReadBody(r io.Reader, limit int) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, limit)
+	}
+	return body, nil
+}
+
+// ReadErrorBody reads at most limit bytes of an error response's body, for reporting. Longer bodies are cut short
+// rather than failing, as the status alone already says what went wrong.
+func ReadErrorBody(r io.Reader, limit int) string {
+	body, _ := io.ReadAll(io.LimitReader(r, int64(limit)))
+	return string(body)
+}
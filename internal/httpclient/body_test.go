@@ -0,0 +1,58 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBody(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body    string
+		errMsg  string
+		limit   int
+		wantErr bool
+	}{
+		"under the limit": {
+			body:  `{"data":[]}`,
+			limit: 64,
+		},
+		"at the limit": {
+			body:  strings.Repeat("x", 64),
+			limit: 64,
+		},
+		"over the limit": {
+			body:    strings.Repeat("x", 65),
+			errMsg:  "response body too large: more than 64 bytes",
+			limit:   64,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body, err := ReadBody(strings.NewReader(tc.body), tc.limit)
+
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrBodyTooLarge)
+				require.EqualError(t, err, tc.errMsg)
+				require.Nil(t, body)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.body, string(body))
+		})
+	}
+}
+
+func TestReadErrorBody(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "rate limited", ReadErrorBody(strings.NewReader("rate limited"), 64))
+	require.Equal(t, strings.Repeat("x", 64), ReadErrorBody(strings.NewReader(strings.Repeat("x", 100)), 64))
+}
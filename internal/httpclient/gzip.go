@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipBody decompresses a response body, closing the underlying body when closed.
+type gzipBody struct {
+	// body is the compressed response body.
+	body io.ReadCloser
+
+	// reader decompresses body.
+	reader *gzip.Reader
+}
+
+// AcceptGzip asks the server to gzip the response body.
+// Setting the header explicitly turns off the transport's own decompression, so pair it with Decompress.
+func AcceptGzip(req *http.Request) {
+	req.Header.Set("Accept-Encoding", "gzip")
+}
+
+// Decompress replaces a gzip-encoded response body with one that decompresses it as it is read.
+// Responses that are not gzip-encoded, or were already decompressed by the transport, are left unchanged.
+func Decompress(resp *http.Response) error {
+	if resp.Uncompressed || !strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip") {
+		return nil
+	}
+
+	reader, err := gzip.NewReader(resp.Body)
+	switch {
+	case errors.Is(err, io.EOF):
+		// An empty body has nothing to decompress.
+	case err != nil:
+		return fmt.Errorf("creating gzip reader: %w", err)
+	default:
+		resp.Body = &gzipBody{body: resp.Body, reader: reader}
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return nil
+}
+
+// Close closes the decompressor and the underlying body.
+func (g *gzipBody) Close() error {
+	return errors.Join(g.reader.Close(), g.body.Close())
+}
+
+// Read reads decompressed data.
+func (g *gzipBody) Read(p []byte) (int, error) {
+	return g.reader.Read(p)
+}
@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptGzip(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com", nil)
+	require.NoError(t, err)
+
+	AcceptGzip(req)
+
+	require.Equal(t, "gzip", req.Header.Get("Accept-Encoding"))
+}
+
+func TestDecompress(t *testing.T) {
+	t.Parallel()
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, err := zw.Write([]byte(`{"value":[]}`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	tests := map[string]struct {
+		body           []byte
+		encoding       string
+		errMsg         string
+		uncompressed   bool
+		want           string
+		wantErr        bool
+		wantUnmodified bool
+	}{
+		"gzip body": {
+			body:     compressed.Bytes(),
+			encoding: "gzip",
+			want:     `{"value":[]}`,
+		},
+		"encoding is case insensitive": {
+			body:     compressed.Bytes(),
+			encoding: "GZIP",
+			want:     `{"value":[]}`,
+		},
+		"identity body is unchanged": {
+			body:           []byte(`{"value":[]}`),
+			want:           `{"value":[]}`,
+			wantUnmodified: true,
+		},
+		"already decompressed by transport": {
+			body:           []byte(`{"value":[]}`),
+			encoding:       "gzip",
+			uncompressed:   true,
+			want:           `{"value":[]}`,
+			wantUnmodified: true,
+		},
+		"empty gzip body": {
+			body:     nil,
+			encoding: "gzip",
+			want:     "",
+		},
+		"corrupt gzip body": {
+			body:     []byte("not gzip"),
+			encoding: "gzip",
+			wantErr:  true,
+			errMsg:   "creating gzip reader",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &http.Response{
+				Body:          io.NopCloser(bytes.NewReader(tc.body)),
+				ContentLength: int64(len(tc.body)),
+				Header:        http.Header{},
+				Uncompressed:  tc.uncompressed,
+			}
+			if tc.encoding != "" {
+				resp.Header.Set("Content-Encoding", tc.encoding)
+			}
+
+			err := Decompress(resp)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			got, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tc.want, string(got))
+
+			if tc.wantUnmodified {
+				require.Equal(t, int64(len(tc.body)), resp.ContentLength)
+				return
+			}
+			require.Empty(t, resp.Header.Get("Content-Encoding"))
+			require.Equal(t, int64(-1), resp.ContentLength)
+			require.True(t, resp.Uncompressed)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Package httpclient provides the HTTP transport and response compression shared by the giftbridge API clients.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultIdleConnTimeout is how long an idle connection is kept open for reuse.
+	defaultIdleConnTimeout = 90 * time.Second
+
+	// defaultMaxIdleConnsPerHost is the number of idle connections kept open per API host.
+	// The net/http default of 2 forces new TLS handshakes during bursts of requests to the same host.
+	defaultMaxIdleConnsPerHost = 10
+
+	// defaultTLSHandshakeTimeout bounds the time spent establishing a TLS connection.
+	defaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// Option configures the shared transport.
+type Option func(*options) error
+
+// options holds optional configuration for creating a transport.
+type options struct {
+	// clientCertificate is presented to servers that request a client certificate.
+	clientCertificate *tls.Certificate
+
+	// disableHTTP2 stops the transport negotiating HTTP/2.
+	disableHTTP2 bool
+
+	// idleConnTimeout is how long an idle connection is kept open for reuse.
+	idleConnTimeout time.Duration
+
+	// maxIdleConnsPerHost is the number of idle connections kept open per host.
+	maxIdleConnsPerHost int
+
+	// proxy selects the proxy for each request, replacing the HTTPS_PROXY and NO_PROXY environment variables.
+	proxy func(*http.Request) (*url.URL, error)
+
+	// rootCAs are the certificate authorities trusted for server certificates, including the system roots.
+	rootCAs *x509.CertPool
+
+	// tlsHandshakeTimeout bounds the time spent establishing a TLS connection.
+	tlsHandshakeTimeout time.Duration
+}
+
+// NewTransport creates an HTTP transport tuned for reusing connections to a small number of API hosts.
+// It keeps connections alive between requests and negotiates HTTP/2 where the server supports it,
+// so a burst of requests (or a warm Lambda invocation) does not pay for a new TLS handshake each time.
+// Share one transport between clients; it is safe for concurrent use.
+func NewTransport(opts ...Option) (*http.Transport, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, fmt.Errorf("applying option: %w", err)
+		}
+	}
+
+	// Start from the default transport to keep its proxy, dialer, and timeout settings.
+	// The default proxy honors the HTTPS_PROXY and NO_PROXY environment variables.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = false
+	transport.ForceAttemptHTTP2 = !o.disableHTTP2
+	transport.IdleConnTimeout = o.idleConnTimeout
+	transport.MaxIdleConnsPerHost = o.maxIdleConnsPerHost
+	transport.TLSHandshakeTimeout = o.tlsHandshakeTimeout
+	if o.proxy != nil {
+		transport.Proxy = o.proxy
+	}
+	if o.clientCertificate != nil || o.rootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: o.rootCAs}
+		if o.clientCertificate != nil {
+			transport.TLSClientConfig.Certificates = []tls.Certificate{*o.clientCertificate}
+		}
+	}
+	if transport.MaxIdleConns < o.maxIdleConnsPerHost {
+		transport.MaxIdleConns = o.maxIdleConnsPerHost
+	}
+
+	return transport, nil
+}
+
+// WithCABundle trusts the PEM-encoded certificate authorities in bundle, in addition to the system roots,
+// for servers behind TLS interception or private gateways.
+func WithCABundle(bundle []byte) Option {
+	return func(o *options) error {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return errors.New("CA bundle contains no PEM certificates")
+		}
+		o.rootCAs = pool
+		return nil
+	}
+}
+
+// WithClientCertificate presents the PEM-encoded certificate chain and private key to servers
+// that require mutual TLS.
+func WithClientCertificate(certPEM []byte, keyPEM []byte) Option {
+	return func(o *options) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("loading client certificate: %w", err)
+		}
+		o.clientCertificate = &cert
+		return nil
+	}
+}
+
+// WithHTTP2 enables or disables HTTP/2 (enabled by default).
+func WithHTTP2(enabled bool) Option {
+	return func(o *options) error {
+		o.disableHTTP2 = !enabled
+		return nil
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept open for reuse.
+func WithIdleConnTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout <= 0 {
+			return fmt.Errorf("idle connection timeout must be positive, got %v", timeout)
+		}
+		o.idleConnTimeout = timeout
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the number of idle connections kept open per host.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return errors.New("max idle connections per host must be positive")
+		}
+		o.maxIdleConnsPerHost = n
+		return nil
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyURL instead of the one named by the HTTPS_PROXY
+// environment variable. Requests to bypass hosts, their subdomains, and loopback addresses go direct.
+// A bypass entry of "*" sends every request direct.
+func WithProxy(proxyURL string, bypass []string) Option {
+	return func(o *options) error {
+		u, err := url.Parse(strings.TrimSpace(proxyURL))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("proxy URL must be an absolute http or https URL")
+		}
+		o.proxy = proxyFunc(u, bypass)
+		return nil
+	}
+}
+
+// WithTLSHandshakeTimeout sets the time allowed for establishing a TLS connection.
+func WithTLSHandshakeTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout <= 0 {
+			return fmt.Errorf("TLS handshake timeout must be positive, got %v", timeout)
+		}
+		o.tlsHandshakeTimeout = timeout
+		return nil
+	}
+}
+
+// defaultOptions returns options with sensible defaults.
+func defaultOptions() *options {
+	return &options{
+		idleConnTimeout:     defaultIdleConnTimeout,
+		maxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		tlsHandshakeTimeout: defaultTLSHandshakeTimeout,
+	}
+}
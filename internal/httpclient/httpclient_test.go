@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg       string
+		opts         []Option
+		wantErr      bool
+		wantHTTP2    bool
+		wantIdle     time.Duration
+		wantMaxConns int
+		wantPerHost  int
+		wantTLS      time.Duration
+	}{
+		"defaults": {
+			wantHTTP2:    true,
+			wantIdle:     defaultIdleConnTimeout,
+			wantPerHost:  defaultMaxIdleConnsPerHost,
+			wantTLS:      defaultTLSHandshakeTimeout,
+			wantMaxConns: 100,
+		},
+		"custom settings": {
+			opts: []Option{
+				WithHTTP2(false),
+				WithIdleConnTimeout(time.Minute),
+				WithMaxIdleConnsPerHost(200),
+				WithTLSHandshakeTimeout(5 * time.Second),
+			},
+			wantHTTP2:    false,
+			wantIdle:     time.Minute,
+			wantPerHost:  200,
+			wantTLS:      5 * time.Second,
+			wantMaxConns: 200,
+		},
+		"invalid idle timeout": {
+			opts:    []Option{WithIdleConnTimeout(0)},
+			wantErr: true,
+			errMsg:  "idle connection timeout must be positive",
+		},
+		"invalid max idle connections": {
+			opts:    []Option{WithMaxIdleConnsPerHost(-1)},
+			wantErr: true,
+			errMsg:  "max idle connections per host must be positive",
+		},
+		"invalid proxy URL": {
+			opts:    []Option{WithProxy("proxy.internal:3128", nil)},
+			wantErr: true,
+			errMsg:  "proxy URL must be an absolute http or https URL",
+		},
+		"invalid TLS handshake timeout": {
+			opts:    []Option{WithTLSHandshakeTimeout(-time.Second)},
+			wantErr: true,
+			errMsg:  "TLS handshake timeout must be positive",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			transport, err := NewTransport(tc.opts...)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, transport)
+				return
+			}
+
+			require.NoError(t, err)
+			require.False(t, transport.DisableKeepAlives)
+			require.Equal(t, tc.wantHTTP2, transport.ForceAttemptHTTP2)
+			require.Equal(t, tc.wantIdle, transport.IdleConnTimeout)
+			require.Equal(t, tc.wantPerHost, transport.MaxIdleConnsPerHost)
+			require.Equal(t, tc.wantTLS, transport.TLSHandshakeTimeout)
+			require.Equal(t, tc.wantMaxConns, transport.MaxIdleConns)
+			require.NotNil(t, transport.Proxy)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxyFunc returns a transport proxy function that sends requests through proxyURL,
+// except those to bypass hosts, their subdomains, and loopback addresses.
+func proxyFunc(proxyURL *url.URL, bypass []string) func(*http.Request) (*url.URL, error) {
+	var hosts []string
+	for _, host := range bypass {
+		host = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(host), "."))
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), hosts) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+}
+
+// bypassProxy reports whether requests to host should skip the proxy.
+func bypassProxy(host string, bypass []string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, b := range bypass {
+		if b == "*" || host == b || strings.HasSuffix(host, "."+b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProxy(t *testing.T) {
+	t.Parallel()
+
+	transport, err := NewTransport(WithProxy("http://proxy.internal:3128", []string{".Example.org", " ", "sky.test"}))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		url       string
+		wantProxy string
+	}{
+		"proxied": {
+			url:       "https://api.fundraiseup.com/v1/donations",
+			wantProxy: "http://proxy.internal:3128",
+		},
+		"bypassed host": {
+			url: "https://sky.test/token",
+		},
+		"bypassed subdomain ignoring case": {
+			url: "https://API.example.org:8443/gifts",
+		},
+		"suffix without a dot is proxied": {
+			url:       "https://notexample.org",
+			wantProxy: "http://proxy.internal:3128",
+		},
+		"localhost": {
+			url: "http://localhost:8080/callback",
+		},
+		"loopback address": {
+			url: "http://127.0.0.1:4566",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			proxyURL, err := transport.Proxy(req)
+			require.NoError(t, err)
+			if tc.wantProxy == "" {
+				require.Nil(t, proxyURL)
+				return
+			}
+			require.Equal(t, tc.wantProxy, proxyURL.String())
+		})
+	}
+}
+
+func TestWithProxyBypassAll(t *testing.T) {
+	t.Parallel()
+
+	transport, err := NewTransport(WithProxy("https://proxy.internal", []string{"*"}))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.sky.blackbaud.com", nil)
+	require.NoError(t, err)
+
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	require.Nil(t, proxyURL)
+}
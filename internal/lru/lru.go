@@ -0,0 +1,98 @@
+// Package lru provides a cache that holds a limited number of entries, evicting the least recently used,
+// so per-run lookups stay bounded on large backfills. Caches are safe for concurrent use.
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache maps keys to values, evicting the least recently used entry once its limit is reached.
+type Cache[K comparable, V any] struct {
+	entries map[K]*list.Element
+	limit   int
+	mu      sync.Mutex
+	order   *list.List
+	stats   Stats
+}
+
+// Stats counts how a cache has been used.
+type Stats struct {
+	// Evictions is the number of entries removed to make room for new ones.
+	Evictions int `json:"evictions"`
+
+	// Hits is the number of lookups that found an entry.
+	Hits int `json:"hits"`
+
+	// Misses is the number of lookups that found no entry.
+	Misses int `json:"misses"`
+}
+
+// entry is a key and its value, held in the cache's recency list.
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New creates a new cache holding at most limit entries. A limit of zero or less leaves the cache unbounded.
+func New[K comparable, V any](limit int) *Cache[K, V] {
+	return &Cache[K, V]{
+		entries: make(map[K]*list.Element),
+		limit:   limit,
+		order:   list.New(),
+	}
+}
+
+// Get returns the value cached for key and marks it most recently used.
+// Returns false if the key is not cached.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Len returns the number of entries cached.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Put caches value for key and marks it most recently used, evicting the least recently used entry if the cache
+// is full.
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value})
+	if c.limit > 0 && c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+		c.stats.Evictions++
+	}
+}
+
+// Stats returns how the cache has been used since it was created.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
@@ -0,0 +1,114 @@
+package lru
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Put(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		limit         int
+		puts          []string
+		gets          []string
+		wantCached    []string
+		wantEvictions int
+	}{
+		"evicts least recently put": {
+			limit:         2,
+			puts:          []string{"a", "b", "c"},
+			wantCached:    []string{"b", "c"},
+			wantEvictions: 1,
+		},
+		"evicts least recently used": {
+			limit:         2,
+			puts:          []string{"a", "b", "c"},
+			gets:          []string{"a"},
+			wantCached:    []string{"a", "c"},
+			wantEvictions: 1,
+		},
+		"replaces cached key without evicting": {
+			limit:      2,
+			puts:       []string{"a", "b", "a"},
+			wantCached: []string{"a", "b"},
+		},
+		"unbounded": {
+			puts:       []string{"a", "b", "c"},
+			wantCached: []string{"a", "b", "c"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cache := New[string, int](tc.limit)
+			for i, key := range tc.puts {
+				// Look up keys before the last put, so they are more recently used than the others.
+				if i == len(tc.puts)-1 {
+					for _, get := range tc.gets {
+						cache.Get(get)
+					}
+				}
+				cache.Put(key, i)
+			}
+
+			var cached []string
+			for _, key := range []string{"a", "b", "c"} {
+				if _, ok := cache.Get(key); ok {
+					cached = append(cached, key)
+				}
+			}
+
+			require.Equal(t, tc.wantCached, cached)
+			require.Equal(t, len(tc.wantCached), cache.Len())
+			require.Equal(t, tc.wantEvictions, cache.Stats().Evictions)
+		})
+	}
+}
+
+func TestCache_Get(t *testing.T) {
+	t.Parallel()
+
+	cache := New[string, []string](10)
+	cache.Put("const-1", []string{"gift-1"})
+
+	value, ok := cache.Get("const-1")
+	require.True(t, ok)
+	require.Equal(t, []string{"gift-1"}, value)
+
+	value, ok = cache.Get("const-2")
+	require.False(t, ok)
+	require.Nil(t, value)
+
+	require.Equal(t, Stats{Hits: 1, Misses: 1}, cache.Stats())
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	cache := New[string, int](50)
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				key := fmt.Sprintf("key-%d", (worker*100+i)%75)
+				if _, ok := cache.Get(key); !ok {
+					cache.Put(key, i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	require.Equal(t, 800, stats.Hits+stats.Misses)
+	require.LessOrEqual(t, cache.Len(), 50)
+}
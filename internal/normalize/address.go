@@ -0,0 +1,105 @@
+package normalize
+
+import (
+	"regexp"
+	"strings"
+)
+
+// countryAliases maps common non-ISO country spellings to ISO 3166-1 alpha-2 codes.
+var countryAliases = map[string]string{
+	"england":                  "GB",
+	"great britain":            "GB",
+	"northern ireland":         "GB",
+	"scotland":                 "GB",
+	"uk":                       "GB",
+	"united states of america": "US",
+	"usa":                      "US",
+	"wales":                    "GB",
+}
+
+// countryLookup maps lowercase alpha-2 codes, alpha-3 codes, names and aliases to countries.
+var countryLookup = buildCountryLookup()
+
+// postCodeFormats describes valid post codes for countries where the format is well defined.
+var postCodeFormats = map[string]postCodeFormat{
+	"AU": {pattern: regexp.MustCompile(`^\d{4}$`)},
+	"CA": {pattern: regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`), spaceBeforeLast: 3},
+	"DE": {pattern: regexp.MustCompile(`^\d{5}$`)},
+	"FR": {pattern: regexp.MustCompile(`^\d{5}$`)},
+	"GB": {pattern: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`), spaceBeforeLast: 3},
+	"IE": {pattern: regexp.MustCompile(`^[A-Z]\d[\dW] ?[A-Z\d]{4}$`), spaceBeforeLast: 4},
+	"NL": {pattern: regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`), spaceBeforeLast: 2},
+	"NZ": {pattern: regexp.MustCompile(`^\d{4}$`)},
+	"US": {pattern: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+}
+
+// countyCountries are the countries whose region is a county rather than a state or province.
+var countyCountries = map[string]bool{
+	"GB": true,
+	"IE": true,
+}
+
+// postCodeFormat is the expected shape of a country's post codes.
+type postCodeFormat struct {
+	// pattern matches a valid uppercase post code, with or without its separating space.
+	pattern *regexp.Regexp
+
+	// spaceBeforeLast is the number of trailing characters separated by a space in the
+	// canonical form, or zero if the canonical form has no space.
+	spaceBeforeLast int
+}
+
+// Country resolves a country code, alias or name to its ISO 3166-1 alpha-2 code and Raiser's Edge NXT name.
+// Returns false if the country is not recognised.
+func Country(value string) (string, string, bool) {
+	c, ok := countryLookup[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return "", "", false
+	}
+	return c.alpha2, c.name, true
+}
+
+// IsCountyCountry reports whether addresses in the country (an alpha-2 code) use a county rather than a state.
+func IsCountyCountry(code string) bool {
+	return countyCountries[code]
+}
+
+// PostCode returns the post code in its canonical form for the country (an alpha-2 code),
+// and whether it is valid there. Post codes for countries without a known format are
+// trimmed and treated as valid. Invalid post codes are returned trimmed.
+func PostCode(code string, postCode string) (string, bool) {
+	postCode = strings.TrimSpace(postCode)
+
+	format, ok := postCodeFormats[code]
+	if !ok || postCode == "" {
+		return postCode, true
+	}
+
+	upper := strings.ToUpper(strings.Join(strings.Fields(postCode), " "))
+	if !format.pattern.MatchString(upper) {
+		return postCode, false
+	}
+
+	if format.spaceBeforeLast == 0 {
+		return upper, true
+	}
+	compact := strings.ReplaceAll(upper, " ", "")
+	split := len(compact) - format.spaceBeforeLast
+	return compact[:split] + " " + compact[split:], true
+}
+
+// buildCountryLookup indexes countries by every form Country accepts.
+func buildCountryLookup() map[string]country {
+	lookup := make(map[string]country, len(countries)*3+len(countryAliases))
+	byCode := make(map[string]country, len(countries))
+	for _, c := range countries {
+		byCode[c.alpha2] = c
+		lookup[strings.ToLower(c.alpha2)] = c
+		lookup[strings.ToLower(c.alpha3)] = c
+		lookup[strings.ToLower(c.name)] = c
+	}
+	for alias, code := range countryAliases {
+		lookup[alias] = byCode[code]
+	}
+	return lookup
+}
@@ -0,0 +1,75 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountry(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value    string
+		wantCode string
+		wantName string
+		wantOK   bool
+	}{
+		"alpha-2":         {value: "gb", wantCode: "GB", wantName: "United Kingdom", wantOK: true},
+		"alpha-3":         {value: "USA", wantCode: "US", wantName: "United States", wantOK: true},
+		"name":            {value: " canada ", wantCode: "CA", wantName: "Canada", wantOK: true},
+		"alias":           {value: "UK", wantCode: "GB", wantName: "United Kingdom", wantOK: true},
+		"common name":     {value: "KR", wantCode: "KR", wantName: "South Korea", wantOK: true},
+		"unknown":         {value: "Atlantis", wantOK: false},
+		"empty":           {value: "", wantOK: false},
+		"reserved alpha2": {value: "XX", wantOK: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			code, countryName, ok := Country(tc.value)
+
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.wantCode, code)
+			require.Equal(t, tc.wantName, countryName)
+		})
+	}
+}
+
+func TestPostCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		code      string
+		postCode  string
+		want      string
+		wantValid bool
+	}{
+		"UK adds space and uppercases":  {code: "GB", postCode: "sw1a1aa", want: "SW1A 1AA", wantValid: true},
+		"UK short outward code":         {code: "GB", postCode: "M1 1AE", want: "M1 1AE", wantValid: true},
+		"UK invalid":                    {code: "GB", postCode: "12345", want: "12345", wantValid: false},
+		"Canada":                        {code: "CA", postCode: "k1a0b1", want: "K1A 0B1", wantValid: true},
+		"Canada invalid":                {code: "CA", postCode: "K1A", want: "K1A", wantValid: false},
+		"US ZIP":                        {code: "US", postCode: "10022", want: "10022", wantValid: true},
+		"US ZIP+4":                      {code: "US", postCode: "10022-1234", want: "10022-1234", wantValid: true},
+		"US invalid":                    {code: "US", postCode: "1002", want: "1002", wantValid: false},
+		"Ireland Eircode":               {code: "IE", postCode: "d02x285", want: "D02 X285", wantValid: true},
+		"Netherlands":                   {code: "NL", postCode: "1012ab", want: "1012 AB", wantValid: true},
+		"unknown format is kept":        {code: "BR", postCode: " 01310-100 ", want: "01310-100", wantValid: true},
+		"empty is valid":                {code: "GB", postCode: "", want: "", wantValid: true},
+		"unresolved country is trimmed": {code: "", postCode: " ABC ", want: "ABC", wantValid: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, valid := PostCode(tc.code, tc.postCode)
+
+			require.Equal(t, tc.want, got)
+			require.Equal(t, tc.wantValid, valid)
+		})
+	}
+}
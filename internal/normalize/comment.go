@@ -0,0 +1,95 @@
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+// scrubbedText replaces each part of a comment the scrubber removes, so readers can tell something was taken out.
+const scrubbedText = "[removed]"
+
+// cardNumberPattern matches runs of 13 to 19 digits, optionally grouped by single spaces or hyphens,
+// the lengths of payment card numbers.
+var cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// CommentScrubber removes personal data and unwanted words from donor comments.
+// The zero value removes nothing.
+type CommentScrubber struct {
+	// cardNumbers enables removing payment card numbers.
+	cardNumbers bool
+
+	// patterns match the text to remove, including a pattern matching the configured words.
+	patterns []*regexp.Regexp
+}
+
+// NewCommentScrubber compiles the patterns and words in cfg into a CommentScrubber.
+func NewCommentScrubber(cfg config.CommentScrubbing) (*CommentScrubber, error) {
+	s := &CommentScrubber{cardNumbers: cfg.CardNumbers}
+
+	for i, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("comment pattern %d: %w", i+1, err)
+		}
+		s.patterns = append(s.patterns, re)
+	}
+
+	var words []string
+	for _, word := range cfg.Words {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, regexp.QuoteMeta(word))
+		}
+	}
+	if len(words) > 0 {
+		s.patterns = append(s.patterns, regexp.MustCompile(`(?i)\b(?:`+strings.Join(words, "|")+`)\b`))
+	}
+
+	return s, nil
+}
+
+// Scrub returns comment with card numbers, pattern matches and words replaced by "[removed]".
+// Digit runs that fail the card check digit, such as phone numbers, are kept.
+func (s *CommentScrubber) Scrub(comment string) string {
+	if s == nil || comment == "" {
+		return comment
+	}
+
+	if s.cardNumbers {
+		comment = cardNumberPattern.ReplaceAllStringFunc(comment, func(match string) string {
+			if !luhnValid(match) {
+				return match
+			}
+			return scrubbedText
+		})
+	}
+	for _, re := range s.patterns {
+		comment = re.ReplaceAllLiteralString(comment, scrubbedText)
+	}
+
+	return comment
+}
+
+// luhnValid reports whether the digits in s pass the Luhn check used by payment card numbers.
+// Characters other than digits are ignored.
+func luhnValid(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		digit := int(s[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,66 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+func TestCommentScrubber(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg     config.CommentScrubbing
+		comment string
+		want    string
+	}{
+		"nothing removed by default": {
+			comment: "In memory of Mum, card 4242 4242 4242 4242",
+			want:    "In memory of Mum, card 4242 4242 4242 4242",
+		},
+		"card numbers": {
+			cfg:     config.CommentScrubbing{CardNumbers: true},
+			comment: "Paid with 4242 4242 4242 4242, or 5555-5555-5555-4444 next time",
+			want:    "Paid with [removed], or [removed] next time",
+		},
+		"digit runs failing the check digit are kept": {
+			cfg:     config.CommentScrubbing{CardNumbers: true},
+			comment: "Call me on 0207 946 0958 123",
+			want:    "Call me on 0207 946 0958 123",
+		},
+		"patterns": {
+			cfg:     config.CommentScrubbing{Patterns: []string{`\d+ [A-Z][a-z]+ (?:Road|Street)`}},
+			comment: "Send the receipt to 12 High Street please",
+			want:    "Send the receipt to [removed] please",
+		},
+		"whole words ignoring case": {
+			cfg:     config.CommentScrubbing{Words: []string{"darn", " ", "a.b"}},
+			comment: "Darn good cause, not darned; a.b but not axb",
+			want:    "[removed] good cause, not darned; [removed] but not axb",
+		},
+		"empty comment": {
+			cfg:     config.CommentScrubbing{CardNumbers: true, Words: []string{"darn"}},
+			comment: "",
+			want:    "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			scrubber, err := NewCommentScrubber(tc.cfg)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, scrubber.Scrub(tc.comment))
+		})
+	}
+}
+
+func TestNewCommentScrubberInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCommentScrubber(config.CommentScrubbing{Patterns: []string{"ok", "(unclosed"}})
+	require.ErrorContains(t, err, "comment pattern 2: error parsing regexp")
+}
@@ -0,0 +1,43 @@
+// Package normalize provides normalization of donor details (emails, names and addresses) before they are
+// matched or stored in Blackbaud.
+package normalize
+
+import (
+	"strings"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+const (
+	// gmailDomain is the canonical Gmail domain.
+	gmailDomain = "gmail.com"
+
+	// googlemailDomain is an alias of gmail.com used in some countries.
+	googlemailDomain = "googlemail.com"
+)
+
+// Email returns the form of address used to match constituents.
+// The address is always trimmed and lowercased; plus tags and Gmail dots are removed when enabled in cfg.
+// Addresses without exactly one @ are returned trimmed and lowercased only.
+func Email(address string, cfg config.EmailNormalization) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+
+	local, domain, ok := strings.Cut(address, "@")
+	if !ok || local == "" || domain == "" || strings.Contains(domain, "@") {
+		return address
+	}
+
+	isGmail := domain == gmailDomain || domain == googlemailDomain
+	if cfg.StripPlusTags || (cfg.FoldGmail && isGmail) {
+		if tagged, _, found := strings.Cut(local, "+"); found && tagged != "" {
+			local = tagged
+		}
+	}
+	if cfg.FoldGmail && isGmail {
+		// Gmail ignores dots in the local part and delivers googlemail.com to the same mailbox.
+		local = strings.ReplaceAll(local, ".", "")
+		domain = gmailDomain
+	}
+
+	return local + "@" + domain
+}
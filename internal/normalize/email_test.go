@@ -0,0 +1,70 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+func TestEmail(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		address string
+		cfg     config.EmailNormalization
+		want    string
+	}{
+		"trims and lowercases": {
+			address: "  John.Doe@Example.COM ",
+			want:    "john.doe@example.com",
+		},
+		"keeps plus tags and gmail dots by default": {
+			address: "John.Doe+fr@gmail.com",
+			want:    "john.doe+fr@gmail.com",
+		},
+		"folds gmail": {
+			address: "John.Doe+fr@gmail.com",
+			cfg:     config.EmailNormalization{FoldGmail: true},
+			want:    "johndoe@gmail.com",
+		},
+		"folds googlemail to gmail": {
+			address: "j.doe@googlemail.com",
+			cfg:     config.EmailNormalization{FoldGmail: true},
+			want:    "jdoe@gmail.com",
+		},
+		"gmail folding leaves other domains alone": {
+			address: "john.doe+fr@example.com",
+			cfg:     config.EmailNormalization{FoldGmail: true},
+			want:    "john.doe+fr@example.com",
+		},
+		"strips plus tags on any domain": {
+			address: "john.doe+newsletter@example.com",
+			cfg:     config.EmailNormalization{StripPlusTags: true},
+			want:    "john.doe@example.com",
+		},
+		"keeps leading plus": {
+			address: "+tag@example.com",
+			cfg:     config.EmailNormalization{StripPlusTags: true},
+			want:    "+tag@example.com",
+		},
+		"not an address": {
+			address: " Not An Email ",
+			cfg:     config.EmailNormalization{FoldGmail: true, StripPlusTags: true},
+			want:    "not an email",
+		},
+		"empty": {
+			address: "",
+			want:    "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, Email(tc.address, tc.cfg))
+		})
+	}
+}
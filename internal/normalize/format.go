@@ -0,0 +1,57 @@
+package normalize
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// NameFields are the fields a name format can refer to, such as {{.FirstName}}.
+type NameFields struct {
+	// FirstName is the constituent's first name.
+	FirstName string
+
+	// LastName is the constituent's last name.
+	LastName string
+}
+
+// NameFormatter formats a constituent's name for mail, such as an addressee or salutation,
+// from a text/template format. The zero value formats nothing.
+type NameFormatter struct {
+	// tmpl is the parsed format, nil when no format is set.
+	tmpl *template.Template
+}
+
+// NewNameFormatter parses format into a NameFormatter. An empty format formats nothing.
+// Fields that NameFields does not have are reported here, rather than when the first name is formatted.
+func NewNameFormatter(format string) (*NameFormatter, error) {
+	if strings.TrimSpace(format) == "" {
+		return &NameFormatter{}, nil
+	}
+
+	tmpl, err := template.New("name").Parse(format)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, NameFields{}); err != nil {
+		return nil, fmt.Errorf("checking fields: %w", err)
+	}
+
+	return &NameFormatter{tmpl: tmpl}, nil
+}
+
+// Format returns the formatted name with whitespace collapsed, so a missing name leaves no stray spaces.
+// Returns an empty string if there is no format or it produces nothing.
+func (f *NameFormatter) Format(fields NameFields) string {
+	if f == nil || f.tmpl == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := f.tmpl.Execute(&buf, fields); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
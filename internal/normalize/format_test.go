@@ -0,0 +1,94 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNameFormatter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg  string
+		format  string
+		wantErr bool
+	}{
+		"empty": {
+			format: " ",
+		},
+		"valid": {
+			format: "{{.FirstName}} {{.LastName}}",
+		},
+		"invalid syntax": {
+			format:  "{{.FirstName",
+			wantErr: true,
+			errMsg:  "unclosed action",
+		},
+		"unknown field": {
+			format:  "{{.Title}} {{.LastName}}",
+			wantErr: true,
+			errMsg:  "can't evaluate field Title",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := NewNameFormatter(tc.format)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, f)
+		})
+	}
+}
+
+func TestNameFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		fields NameFields
+		format string
+		want   string
+	}{
+		"no format": {
+			fields: NameFields{FirstName: "Jane", LastName: "Doe"},
+			want:   "",
+		},
+		"full name": {
+			fields: NameFields{FirstName: "Jane", LastName: "Doe"},
+			format: "{{.FirstName}} {{.LastName}}",
+			want:   "Jane Doe",
+		},
+		"collapses whitespace left by a missing name": {
+			fields: NameFields{LastName: "Doe"},
+			format: "  {{.FirstName}}   {{.LastName}} ",
+			want:   "Doe",
+		},
+		"falls back when first name is missing": {
+			fields: NameFields{LastName: "Doe"},
+			format: "Dear {{if .FirstName}}{{.FirstName}}{{else}}Friend{{end}}",
+			want:   "Dear Friend",
+		},
+		"blank result": {
+			format: "{{.FirstName}}",
+			want:   "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := NewNameFormatter(tc.format)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, f.Format(tc.fields))
+		})
+	}
+}
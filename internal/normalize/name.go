@@ -0,0 +1,146 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+// nameParticles are lowercase words that join surnames (e.g. "van der Berg") and keep their case when title-casing.
+var nameParticles = map[string]bool{
+	"bin":   true,
+	"da":    true,
+	"das":   true,
+	"de":    true,
+	"del":   true,
+	"della": true,
+	"den":   true,
+	"der":   true,
+	"di":    true,
+	"do":    true,
+	"dos":   true,
+	"du":    true,
+	"la":    true,
+	"le":    true,
+	"ten":   true,
+	"ter":   true,
+	"van":   true,
+	"von":   true,
+}
+
+// transliterations maps accented Latin letters to ASCII.
+// Letters not listed are kept as they are.
+var transliterations = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Ā': "A", 'Ă': "A", 'Ą': "A",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'Æ': "AE", 'æ': "ae",
+	'Ç': "C", 'Ć': "C", 'Č': "C", 'ç': "c", 'ć': "c", 'č': "c",
+	'Ď': "D", 'Đ': "D", 'Ð': "D", 'ď': "d", 'đ': "d", 'ð': "d",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ė': "E", 'Ę': "E", 'Ě': "E",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'Ğ': "G", 'ğ': "g",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ī': "I", 'İ': "I",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'Ł': "L", 'ł': "l",
+	'Ñ': "N", 'Ń': "N", 'Ň': "N", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O", 'Ō': "O", 'Ő': "O",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'Œ': "OE", 'œ': "oe",
+	'Ř': "R", 'ř': "r",
+	'Ś': "S", 'Š': "S", 'Ş': "S", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss",
+	'Ť': "T", 'Ţ': "T", 'ť': "t", 'ţ': "t",
+	'Þ': "Th", 'þ': "th",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ū': "U", 'Ů': "U", 'Ű': "U",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'Ý': "Y", 'Ÿ': "Y", 'ý': "y", 'ÿ': "y",
+	'Ź': "Z", 'Ż': "Z", 'Ž': "Z", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// Name returns a person's name with the normalization enabled in cfg applied.
+// Surrounding whitespace is always trimmed and internal runs of whitespace collapsed.
+func Name(name string, cfg config.NameNormalization) string {
+	name = strings.Join(strings.Fields(name), " ")
+
+	if cfg.Transliterate {
+		name = transliterate(name)
+	}
+	if cfg.TitleCase {
+		name = titleCase(name)
+	}
+
+	return name
+}
+
+// capitalize uppercases the first letter of a lowercase word segment, handling the "Mc" prefix.
+func capitalize(segment string) string {
+	runes := []rune(segment)
+	if len(runes) == 0 {
+		return segment
+	}
+
+	runes[0] = unicode.ToUpper(runes[0])
+	if len(runes) > 2 && runes[0] == 'M' && runes[1] == 'c' {
+		runes[2] = unicode.ToUpper(runes[2])
+	}
+
+	return string(runes)
+}
+
+// hasMixedCase reports whether s contains both upper and lower case letters.
+func hasMixedCase(s string) bool {
+	var upper, lower bool
+	for _, r := range s {
+		upper = upper || unicode.IsUpper(r)
+		lower = lower || unicode.IsLower(r)
+	}
+	return upper && lower
+}
+
+// titleCase capitalizes an all-lowercase or all-uppercase name, leaving name particles lowercase.
+// Names that already mix upper and lower case (e.g. "McDonald", "DeVito") are assumed to be
+// deliberate and are returned unchanged.
+func titleCase(name string) string {
+	if hasMixedCase(name) {
+		return name
+	}
+
+	words := strings.Split(strings.ToLower(name), " ")
+	for i, word := range words {
+		// A particle is only kept lowercase when a surname follows it.
+		if i < len(words)-1 && nameParticles[word] {
+			continue
+		}
+		words[i] = titleCaseWord(word)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// titleCaseWord capitalizes each part of a word separated by hyphens or apostrophes (e.g. "O'Brien-Smith").
+func titleCaseWord(word string) string {
+	var b strings.Builder
+	start := 0
+	for i, r := range word {
+		if r == '-' || r == '\'' || r == '’' {
+			b.WriteString(capitalize(word[start:i]))
+			b.WriteRune(r)
+			start = i + len(string(r))
+		}
+	}
+	b.WriteString(capitalize(word[start:]))
+	return b.String()
+}
+
+// transliterate replaces accented Latin letters with their closest ASCII equivalents.
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if replacement, ok := transliterations[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+func TestName(t *testing.T) {
+	t.Parallel()
+
+	titleCase := config.NameNormalization{TitleCase: true}
+
+	tests := map[string]struct {
+		cfg  config.NameNormalization
+		name string
+		want string
+	}{
+		"trims and collapses whitespace only by default": {
+			name: "  mary   jane ",
+			want: "mary jane",
+		},
+		"title-cases lowercase": {
+			cfg:  titleCase,
+			name: "john smith",
+			want: "John Smith",
+		},
+		"title-cases uppercase": {
+			cfg:  titleCase,
+			name: "JANE DOE",
+			want: "Jane Doe",
+		},
+		"keeps mixed case": {
+			cfg:  titleCase,
+			name: "DeVito",
+			want: "DeVito",
+		},
+		"keeps particles lowercase": {
+			cfg:  titleCase,
+			name: "van der berg",
+			want: "van der Berg",
+		},
+		"capitalizes a lone particle": {
+			cfg:  titleCase,
+			name: "de",
+			want: "De",
+		},
+		"hyphens and apostrophes": {
+			cfg:  titleCase,
+			name: "o'brien-smith",
+			want: "O'Brien-Smith",
+		},
+		"mc prefix": {
+			cfg:  titleCase,
+			name: "mcdonald",
+			want: "McDonald",
+		},
+		"non-ascii letters": {
+			cfg:  titleCase,
+			name: "élodie",
+			want: "Élodie",
+		},
+		"transliterates": {
+			cfg:  config.NameNormalization{Transliterate: true},
+			name: "José Müller-Straße",
+			want: "Jose Muller-Strasse",
+		},
+		"transliterates then title-cases": {
+			cfg:  config.NameNormalization{TitleCase: true, Transliterate: true},
+			name: "zoë łukasiewicz",
+			want: "Zoe Lukasiewicz",
+		},
+		"empty": {
+			cfg:  titleCase,
+			name: "",
+			want: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, Name(tc.name, tc.cfg))
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Package reconcile totals synced donations by the payment processor payout that settled them,
+// so gifts in Raiser's Edge NXT can be tied to bank deposits.
+package reconcile
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// payoutLookback is how long before the report window donations are fetched, so payouts arriving early in
+// the window include donations made before it. Processors pay out well within this.
+const payoutLookback = 31 * 24 * time.Hour
+
+// csvHeader is the header row written by WriteCSV.
+var csvHeader = []string{
+	"payout_id",
+	"processor",
+	"arrival_date",
+	"currency",
+	"donation_count",
+	"total_amount",
+	"synced_count",
+	"synced_amount",
+	"unsynced_donation_ids",
+}
+
+// Donations defines the FundraiseUp operations needed to reconcile payouts.
+type Donations interface {
+	// DonationsEach calls fn for each donation created after the given time.
+	DonationsEach(ctx context.Context, since time.Time, fn func(fundraiseup.Donation) error) error
+}
+
+// Tracker defines the donation tracker operations needed to reconcile payouts.
+type Tracker interface {
+	// DonationsBetween returns all tracked donations made in [from, to).
+	DonationsBetween(ctx context.Context, from time.Time, to time.Time) ([]storage.DonationRecord, error)
+}
+
+// Payout summarises the donations settled by one processor payout.
+type Payout struct {
+	// ArrivalDate is the date the payout reaches the bank, in YYYY-MM-DD format.
+	ArrivalDate string
+
+	// Currency is the ISO currency code of the donations.
+	Currency string
+
+	// DonationCount is the number of donations in the payout.
+	DonationCount int
+
+	// ID is the payment processor's payout identifier.
+	ID string
+
+	// Processor is the payment processor that made the payout.
+	Processor string
+
+	// SyncedCents is the total of the donations synced to Blackbaud, in minor currency units.
+	SyncedCents int64
+
+	// SyncedCount is the number of donations in the payout synced to Blackbaud.
+	SyncedCount int
+
+	// TotalCents is the total of all donations in the payout, in minor currency units.
+	TotalCents int64
+
+	// UnsyncedDonationIDs lists donations in the payout with no gift in Blackbaud, ordered by ID.
+	UnsyncedDonationIDs []string
+}
+
+// Reconciler totals donations by payout and checks which were synced.
+type Reconciler struct {
+	donations Donations
+	tracker   Tracker
+}
+
+// Report is the result of reconciling payouts.
+type Report struct {
+	// Payouts contains one entry per payout and currency, ordered by arrival date and then ID.
+	Payouts []Payout
+}
+
+// NewReconciler creates a new payout reconciler.
+func NewReconciler(donations Donations, tracker Tracker) (*Reconciler, error) {
+	if donations == nil {
+		return nil, errors.New("donations client is required")
+	}
+	if tracker == nil {
+		return nil, errors.New("tracker is required")
+	}
+
+	return &Reconciler{
+		donations: donations,
+		tracker:   tracker,
+	}, nil
+}
+
+// Reconcile totals the donations in each payout arriving in [from, to) and checks them against the tracker.
+// A donation counts as synced when the tracker holds a gift for it.
+func (r *Reconciler) Reconcile(ctx context.Context, from time.Time, to time.Time) (*Report, error) {
+	since := from.Add(-payoutLookback)
+
+	records, err := r.tracker.DonationsBetween(ctx, since, to)
+	if err != nil {
+		return nil, fmt.Errorf("listing tracked donations: %w", err)
+	}
+	synced := make(map[string]bool, len(records))
+	for _, record := range records {
+		if record.GiftID != "" {
+			synced[record.DonationID] = true
+		}
+	}
+
+	payouts := make(map[string]*Payout)
+	err = r.donations.DonationsEach(ctx, since, func(donation fundraiseup.Donation) error {
+		if donation.Payout == nil || donation.Payout.ID == "" {
+			return nil
+		}
+
+		arrival, err := time.Parse(time.DateOnly, donation.Payout.ArrivalDate)
+		if err != nil {
+			return fmt.Errorf("parsing arrival date of payout %s: %w", donation.Payout.ID, err)
+		}
+		if arrival.Before(from) || !arrival.Before(to) {
+			return nil
+		}
+
+		amount, err := strconv.ParseFloat(donation.Amount, 64)
+		if err != nil {
+			return fmt.Errorf("parsing amount of donation %s: %w", donation.ID, err)
+		}
+
+		key := donation.Payout.ID + "/" + donation.Currency
+		payout, ok := payouts[key]
+		if !ok {
+			payout = &Payout{
+				ArrivalDate: donation.Payout.ArrivalDate,
+				Currency:    donation.Currency,
+				ID:          donation.Payout.ID,
+				Processor:   donation.Payout.Processor,
+			}
+			payouts[key] = payout
+		}
+		payout.add(donation.ID, int64(math.Round(amount*100)), synced[donation.ID])
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing donations: %w", err)
+	}
+
+	report := &Report{Payouts: make([]Payout, 0, len(payouts))}
+	for _, payout := range payouts {
+		sort.Strings(payout.UnsyncedDonationIDs)
+		report.Payouts = append(report.Payouts, *payout)
+	}
+	sort.Slice(report.Payouts, func(i, j int) bool {
+		a, b := report.Payouts[i], report.Payouts[j]
+		if a.ArrivalDate != b.ArrivalDate {
+			return a.ArrivalDate < b.ArrivalDate
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Currency < b.Currency
+	})
+
+	return report, nil
+}
+
+// WriteCSV writes payouts as CSV with a header row. Unsynced donation IDs are separated by semicolons.
+func WriteCSV(w io.Writer, payouts []Payout) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, p := range payouts {
+		row := []string{
+			p.ID,
+			p.Processor,
+			p.ArrivalDate,
+			p.Currency,
+			strconv.Itoa(p.DonationCount),
+			formatCents(p.TotalCents),
+			strconv.Itoa(p.SyncedCount),
+			formatCents(p.SyncedCents),
+			strings.Join(p.UnsyncedDonationIDs, ";"),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("writing payout %s: %w", p.ID, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("flushing CSV: %w", err)
+	}
+
+	return nil
+}
+
+// add includes a donation in the payout.
+func (p *Payout) add(donationID string, cents int64, synced bool) {
+	p.DonationCount++
+	p.TotalCents += cents
+
+	if synced {
+		p.SyncedCount++
+		p.SyncedCents += cents
+		return
+	}
+	p.UnsyncedDonationIDs = append(p.UnsyncedDonationIDs, donationID)
+}
+
+// formatCents formats an amount in minor units with two decimal places.
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
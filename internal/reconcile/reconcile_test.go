@@ -0,0 +1,236 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+type mockDonations struct {
+	donations []fundraiseup.Donation
+	since     time.Time
+}
+
+func (m *mockDonations) DonationsEach(
+	_ context.Context,
+	since time.Time,
+	fn func(fundraiseup.Donation) error,
+) error {
+	m.since = since
+	for _, donation := range m.donations {
+		if err := fn(donation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mockTracker struct {
+	from    time.Time
+	records []storage.DonationRecord
+	to      time.Time
+}
+
+func (m *mockTracker) DonationsBetween(
+	_ context.Context,
+	from time.Time,
+	to time.Time,
+) ([]storage.DonationRecord, error) {
+	m.from = from
+	m.to = to
+	return m.records, nil
+}
+
+func TestNewReconciler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		donations Donations
+		tracker   Tracker
+		wantErr   string
+	}{
+		"valid": {
+			donations: &mockDonations{},
+			tracker:   &mockTracker{},
+		},
+		"missing donations": {
+			tracker: &mockTracker{},
+			wantErr: "donations client is required",
+		},
+		"missing tracker": {
+			donations: &mockDonations{},
+			wantErr:   "tracker is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reconciler, err := NewReconciler(tc.donations, tc.tracker)
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, reconciler)
+		})
+	}
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	stripe := &fundraiseup.Payout{ArrivalDate: "2024-03-04", ID: "po_stripe", Processor: "stripe"}
+	paypal := &fundraiseup.Payout{ArrivalDate: "2024-03-02", ID: "po_paypal", Processor: "paypal"}
+	donations := &mockDonations{
+		donations: []fundraiseup.Donation{
+			{Amount: "10.10", Currency: "GBP", ID: "don_1", Payout: stripe},
+			{Amount: "25.00", Currency: "GBP", ID: "don_2", Payout: stripe},
+			{Amount: "5.00", Currency: "GBP", ID: "don_3", Payout: stripe},
+			{Amount: "40.00", Currency: "USD", ID: "don_4", Payout: stripe},
+			{Amount: "15.00", Currency: "GBP", ID: "don_5", Payout: paypal},
+			// Not yet paid out.
+			{Amount: "99.00", Currency: "GBP", ID: "don_6"},
+			// Paid out after the window.
+			{
+				Amount:   "50.00",
+				Currency: "GBP",
+				ID:       "don_7",
+				Payout:   &fundraiseup.Payout{ArrivalDate: "2024-04-01", ID: "po_april", Processor: "stripe"},
+			},
+		},
+	}
+	tracker := &mockTracker{
+		records: []storage.DonationRecord{
+			{DonationID: "don_1", GiftID: "gift-1"},
+			{DonationID: "don_2", GiftID: "gift-2"},
+			{DonationID: "don_4", GiftID: "gift-4"},
+			{DonationID: "don_5", GiftID: "gift-5"},
+		},
+	}
+
+	reconciler, err := NewReconciler(donations, tracker)
+	require.NoError(t, err)
+
+	report, err := reconciler.Reconcile(context.Background(), from, to)
+
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.January, 30, 0, 0, 0, 0, time.UTC), donations.since)
+	require.Equal(t, donations.since, tracker.from)
+	require.Equal(t, to, tracker.to)
+	require.Equal(t, []Payout{
+		{
+			ArrivalDate:   "2024-03-02",
+			Currency:      "GBP",
+			DonationCount: 1,
+			ID:            "po_paypal",
+			Processor:     "paypal",
+			SyncedCents:   1500,
+			SyncedCount:   1,
+			TotalCents:    1500,
+		},
+		{
+			ArrivalDate:         "2024-03-04",
+			Currency:            "GBP",
+			DonationCount:       3,
+			ID:                  "po_stripe",
+			Processor:           "stripe",
+			SyncedCents:         3510,
+			SyncedCount:         2,
+			TotalCents:          4010,
+			UnsyncedDonationIDs: []string{"don_3"},
+		},
+		{
+			ArrivalDate:   "2024-03-04",
+			Currency:      "USD",
+			DonationCount: 1,
+			ID:            "po_stripe",
+			Processor:     "stripe",
+			SyncedCents:   4000,
+			SyncedCount:   1,
+			TotalCents:    4000,
+		},
+	}, report.Payouts)
+}
+
+func TestReconciler_ReconcileInvalidDonation(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := map[string]struct {
+		donation fundraiseup.Donation
+		wantErr  string
+	}{
+		"invalid arrival date": {
+			donation: fundraiseup.Donation{
+				Amount: "10.00",
+				ID:     "don_1",
+				Payout: &fundraiseup.Payout{ArrivalDate: "March 4th", ID: "po_1"},
+			},
+			wantErr: "parsing arrival date of payout po_1",
+		},
+		"invalid amount": {
+			donation: fundraiseup.Donation{
+				Amount: "ten",
+				ID:     "don_1",
+				Payout: &fundraiseup.Payout{ArrivalDate: "2024-03-04", ID: "po_1"},
+			},
+			wantErr: "parsing amount of donation don_1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			donations := &mockDonations{donations: []fundraiseup.Donation{tc.donation}}
+			reconciler, err := NewReconciler(donations, &mockTracker{})
+			require.NoError(t, err)
+
+			report, err := reconciler.Reconcile(context.Background(), from, to)
+
+			require.ErrorContains(t, err, tc.wantErr)
+			require.Nil(t, report)
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Payout{
+		{
+			ArrivalDate:         "2024-03-04",
+			Currency:            "GBP",
+			DonationCount:       3,
+			ID:                  "po_stripe",
+			Processor:           "stripe",
+			SyncedCents:         3510,
+			SyncedCount:         1,
+			TotalCents:          4010,
+			UnsyncedDonationIDs: []string{"don_2", "don_3"},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t,
+		"payout_id,processor,arrival_date,currency,donation_count,total_amount,"+
+			"synced_count,synced_amount,unsynced_donation_ids\n"+
+			"po_stripe,stripe,2024-03-04,GBP,3,40.10,1,35.10,don_2;don_3\n",
+		buf.String(),
+	)
+}
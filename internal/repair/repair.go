@@ -0,0 +1,248 @@
+// Package repair fixes the links between the gifts of a recurring series in Raiser's Edge NXT,
+// for series created before the sync linked each payment to its recurring gift.
+package repair
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// Blackbaud defines the Blackbaud operations needed to repair a recurring series.
+type Blackbaud interface {
+	// ListGiftsByConstituent returns all gifts for a constituent, optionally filtered by gift type.
+	ListGiftsByConstituent(
+		ctx context.Context,
+		constituentID string,
+		giftTypes []blackbaud.GiftType,
+	) ([]blackbaud.Gift, error)
+
+	// UpdateGift updates an existing gift by ID.
+	UpdateGift(ctx context.Context, giftID string, gift *blackbaud.Gift) error
+}
+
+// Tracker defines the donation tracker operations needed to find the gifts of a recurring series.
+type Tracker interface {
+	// RecurringDonations returns all tracked donations for a recurring plan.
+	RecurringDonations(ctx context.Context, recurringID string) ([]storage.DonationRecord, error)
+}
+
+// Link is a payment whose links to the recurring gift are wrong, with the links it should have.
+type Link struct {
+	// From is the links the payment has now.
+	From []string
+
+	// GiftID is the Blackbaud ID of the payment.
+	GiftID string
+
+	// To is the links the payment should have.
+	To []string
+}
+
+// Report describes a recurring series and the links that were, or in a dry run would be, fixed.
+type Report struct {
+	// DuplicateParentIDs are further recurring gifts in the series, ordered by date and then ID.
+	// Payments linked to them are relinked to ParentID; the gifts themselves are left for review.
+	DuplicateParentIDs []string
+
+	// GiftCount is the number of gifts found for the series.
+	GiftCount int
+
+	// Links are the payments relinked to ParentID, ordered by gift ID.
+	Links []Link
+
+	// ParentID is the earliest recurring gift in the series, which payments are linked to.
+	// Empty when the series has no recurring gift, in which case nothing is relinked.
+	ParentID string
+
+	// RecurringID is the FundraiseUp recurring plan identifier.
+	RecurringID string
+}
+
+// Repairer finds and fixes missing or wrong links in recurring series.
+type Repairer struct {
+	blackbaud Blackbaud
+	tracker   Tracker
+}
+
+// NewRepairer creates a new recurring series repairer.
+func NewRepairer(bb Blackbaud, tracker Tracker) (*Repairer, error) {
+	if bb == nil {
+		return nil, errors.New("blackbaud client is required")
+	}
+	if tracker == nil {
+		return nil, errors.New("tracker is required")
+	}
+
+	return &Repairer{
+		blackbaud: bb,
+		tracker:   tracker,
+	}, nil
+}
+
+// Repair links every payment in the recurring series to its earliest recurring gift.
+// In a dry run the links are reported but not changed.
+func (r *Repairer) Repair(ctx context.Context, recurringID string, dryRun bool) (*Report, error) {
+	gifts, err := r.seriesGifts(ctx, recurringID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := plan(recurringID, gifts)
+	if dryRun {
+		return report, nil
+	}
+
+	byID := make(map[string]blackbaud.Gift, len(gifts))
+	for _, gift := range gifts {
+		byID[gift.ID] = gift
+	}
+	for _, link := range report.Links {
+		// Send the gift as read, so fields the update does not mean to change are kept.
+		update := byID[link.GiftID]
+		update.ID = ""
+		update.LinkedGifts = link.To
+		if err := r.blackbaud.UpdateGift(ctx, link.GiftID, &update); err != nil {
+			return report, fmt.Errorf("linking gift %s to %s: %w", link.GiftID, report.ParentID, err)
+		}
+	}
+
+	return report, nil
+}
+
+// seriesGifts returns the recurring gifts and payments of a recurring series, across every constituent
+// the tracker recorded a payment for. Gifts are matched by tracked ID, lookup ID or origin,
+// so series created under either reference field are found.
+func (r *Repairer) seriesGifts(ctx context.Context, recurringID string) ([]blackbaud.Gift, error) {
+	records, err := r.tracker.RecurringDonations(ctx, recurringID)
+	if err != nil {
+		return nil, fmt.Errorf("getting tracked donations: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no tracked donations for recurring plan %s", recurringID)
+	}
+
+	tracked := make(map[string]bool, len(records))
+	var constituentIDs []string
+	for _, record := range records {
+		tracked[record.GiftID] = true
+		if record.ConstituentID != "" && !slices.Contains(constituentIDs, record.ConstituentID) {
+			constituentIDs = append(constituentIDs, record.ConstituentID)
+		}
+	}
+	sort.Strings(constituentIDs)
+
+	giftTypes := []blackbaud.GiftType{blackbaud.GiftTypeRecurringGift, blackbaud.GiftTypeRecurringGiftPayment}
+	var gifts []blackbaud.Gift
+	for _, constituentID := range constituentIDs {
+		constituentGifts, err := r.blackbaud.ListGiftsByConstituent(ctx, constituentID, giftTypes)
+		if err != nil {
+			return nil, fmt.Errorf("listing gifts for constituent %s: %w", constituentID, err)
+		}
+		for _, gift := range constituentGifts {
+			origin, _ := blackbaud.ParseGiftOrigin(gift.Origin)
+			if tracked[gift.ID] || gift.LookupID == recurringID || origin.RecurringID == recurringID {
+				gifts = append(gifts, gift)
+			}
+		}
+	}
+
+	return gifts, nil
+}
+
+// plan works out which payments need relinking so every payment links to the earliest recurring gift.
+// Links to other gifts, such as a duplicate recurring gift, are replaced; unrelated links are kept.
+func plan(recurringID string, gifts []blackbaud.Gift) *Report {
+	report := &Report{GiftCount: len(gifts), RecurringID: recurringID}
+
+	var parents []blackbaud.Gift
+	for _, gift := range gifts {
+		if gift.Type == blackbaud.GiftTypeRecurringGift {
+			parents = append(parents, gift)
+		}
+	}
+	if len(parents) == 0 {
+		return report
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		if parents[i].Date != parents[j].Date {
+			return parents[i].Date < parents[j].Date
+		}
+		return parents[i].ID < parents[j].ID
+	})
+	report.ParentID = parents[0].ID
+	for _, parent := range parents[1:] {
+		report.DuplicateParentIDs = append(report.DuplicateParentIDs, parent.ID)
+	}
+
+	for _, gift := range gifts {
+		if gift.Type != blackbaud.GiftTypeRecurringGiftPayment {
+			continue
+		}
+		stale := slices.ContainsFunc(gift.LinkedGifts, func(id string) bool {
+			return slices.Contains(report.DuplicateParentIDs, id)
+		})
+		if slices.Contains(gift.LinkedGifts, report.ParentID) && !stale {
+			continue
+		}
+
+		to := []string{report.ParentID}
+		for _, id := range gift.LinkedGifts {
+			if id != report.ParentID && !slices.Contains(report.DuplicateParentIDs, id) {
+				to = append(to, id)
+			}
+		}
+		report.Links = append(report.Links, Link{From: gift.LinkedGifts, GiftID: gift.ID, To: to})
+	}
+	sort.Slice(report.Links, func(i, j int) bool { return report.Links[i].GiftID < report.Links[j].GiftID })
+
+	return report
+}
+
+// Write writes the report as text, describing the changes as made or, in a dry run, as planned.
+func (r *Report) Write(w io.Writer, dryRun bool) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Recurring plan %s: %d gifts found\n", r.RecurringID, r.GiftCount)
+
+	if r.ParentID == "" {
+		b.WriteString("No recurring gift found for the plan, so payments cannot be linked.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	fmt.Fprintf(&b, "Recurring gift: %s\n", r.ParentID)
+
+	if len(r.DuplicateParentIDs) > 0 {
+		fmt.Fprintf(&b, "Further recurring gifts for the plan (review in Raiser's Edge NXT): %s\n",
+			strings.Join(r.DuplicateParentIDs, ", "))
+	}
+
+	if len(r.Links) == 0 {
+		b.WriteString("All payments are linked to the recurring gift.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	verb := "Relinked"
+	if dryRun {
+		verb = "Would relink"
+	}
+	fmt.Fprintf(&b, "%s %d payments:\n", verb, len(r.Links))
+	for _, link := range r.Links {
+		from := strings.Join(link.From, ", ")
+		if from == "" {
+			from = "none"
+		}
+		fmt.Fprintf(&b, "  %s: %s -> %s\n", link.GiftID, from, strings.Join(link.To, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
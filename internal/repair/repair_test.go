@@ -0,0 +1,240 @@
+package repair
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+type mockBlackbaud struct {
+	gifts   map[string][]blackbaud.Gift
+	updates map[string]blackbaud.Gift
+}
+
+func (m *mockBlackbaud) ListGiftsByConstituent(
+	_ context.Context,
+	constituentID string,
+	_ []blackbaud.GiftType,
+) ([]blackbaud.Gift, error) {
+	return m.gifts[constituentID], nil
+}
+
+func (m *mockBlackbaud) UpdateGift(_ context.Context, giftID string, gift *blackbaud.Gift) error {
+	if m.updates == nil {
+		m.updates = make(map[string]blackbaud.Gift)
+	}
+	m.updates[giftID] = *gift
+	return nil
+}
+
+type mockTracker struct {
+	records []storage.DonationRecord
+}
+
+func (m *mockTracker) RecurringDonations(_ context.Context, _ string) ([]storage.DonationRecord, error) {
+	return m.records, nil
+}
+
+func TestNewRepairer(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		blackbaud Blackbaud
+		tracker   Tracker
+		wantErr   string
+	}{
+		"valid": {
+			blackbaud: &mockBlackbaud{},
+			tracker:   &mockTracker{},
+		},
+		"missing blackbaud": {
+			tracker: &mockTracker{},
+			wantErr: "blackbaud client is required",
+		},
+		"missing tracker": {
+			blackbaud: &mockBlackbaud{},
+			wantErr:   "tracker is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repairer, err := NewRepairer(tc.blackbaud, tc.tracker)
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, repairer)
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	t.Parallel()
+
+	records := []storage.DonationRecord{
+		{ConstituentID: "const-1", DonationID: "don_1", GiftID: "gift-1", RecurringID: "rec_1"},
+		{ConstituentID: "const-1", DonationID: "don_2", GiftID: "gift-2", RecurringID: "rec_1"},
+	}
+
+	tests := map[string]struct {
+		gifts      []blackbaud.Gift
+		records    []storage.DonationRecord
+		wantErr    string
+		wantReport *Report
+	}{
+		"already linked": {
+			gifts: []blackbaud.Gift{
+				{Date: "2024-01-01", ID: "gift-1", LookupID: "rec_1", Type: blackbaud.GiftTypeRecurringGift},
+				{
+					Date:        "2024-02-01",
+					ID:          "gift-2",
+					LinkedGifts: []string{"gift-1"},
+					LookupID:    "rec_1",
+					Type:        blackbaud.GiftTypeRecurringGiftPayment,
+				},
+			},
+			records:    records,
+			wantReport: &Report{GiftCount: 2, ParentID: "gift-1", RecurringID: "rec_1"},
+		},
+		"missing and duplicate links": {
+			gifts: []blackbaud.Gift{
+				{Date: "2024-01-01", ID: "gift-1", LookupID: "rec_1", Type: blackbaud.GiftTypeRecurringGift},
+				{Date: "2024-02-01", ID: "gift-2", LookupID: "rec_1", Type: blackbaud.GiftTypeRecurringGiftPayment},
+				{
+					Date:   "2024-03-01",
+					ID:     "gift-3",
+					Origin: `{"name":"FundraiseUp","donation_id":"don_3","recurring_id":"rec_1"}`,
+					Type:   blackbaud.GiftTypeRecurringGift,
+				},
+				{
+					Date:        "2024-04-01",
+					ID:          "gift-4",
+					LinkedGifts: []string{"gift-3", "other"},
+					LookupID:    "rec_1",
+					Type:        blackbaud.GiftTypeRecurringGiftPayment,
+				},
+				{ID: "gift-5", LookupID: "rec_2", Type: blackbaud.GiftTypeRecurringGiftPayment},
+			},
+			records: records,
+			wantReport: &Report{
+				DuplicateParentIDs: []string{"gift-3"},
+				GiftCount:          4,
+				Links: []Link{
+					{GiftID: "gift-2", To: []string{"gift-1"}},
+					{From: []string{"gift-3", "other"}, GiftID: "gift-4", To: []string{"gift-1", "other"}},
+				},
+				ParentID:    "gift-1",
+				RecurringID: "rec_1",
+			},
+		},
+		"no recurring gift": {
+			gifts: []blackbaud.Gift{
+				{ID: "gift-2", LookupID: "rec_1", Type: blackbaud.GiftTypeRecurringGiftPayment},
+			},
+			records:    records,
+			wantReport: &Report{GiftCount: 1, RecurringID: "rec_1"},
+		},
+		"untracked plan": {
+			wantErr: "no tracked donations for recurring plan rec_1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bb := &mockBlackbaud{gifts: map[string][]blackbaud.Gift{"const-1": tc.gifts}}
+			repairer, err := NewRepairer(bb, &mockTracker{records: tc.records})
+			require.NoError(t, err)
+
+			report, err := repairer.Repair(context.Background(), "rec_1", true)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantReport, report)
+			require.Empty(t, bb.updates, "dry run must not update gifts")
+
+			report, err = repairer.Repair(context.Background(), "rec_1", false)
+			require.NoError(t, err)
+			require.Len(t, bb.updates, len(report.Links))
+			for _, link := range report.Links {
+				update := bb.updates[link.GiftID]
+				require.Equal(t, link.To, update.LinkedGifts)
+				require.Empty(t, update.ID)
+				require.Equal(t, "rec_1", update.LookupID, "fields not being changed are sent as read")
+			}
+		})
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	t.Parallel()
+
+	report := &Report{
+		DuplicateParentIDs: []string{"gift-3"},
+		GiftCount:          4,
+		Links: []Link{
+			{GiftID: "gift-2", To: []string{"gift-1"}},
+			{From: []string{"gift-3"}, GiftID: "gift-4", To: []string{"gift-1"}},
+		},
+		ParentID:    "gift-1",
+		RecurringID: "rec_1",
+	}
+
+	tests := map[string]struct {
+		dryRun bool
+		report *Report
+		want   string
+	}{
+		"dry run": {
+			dryRun: true,
+			report: report,
+			want: "Recurring plan rec_1: 4 gifts found\n" +
+				"Recurring gift: gift-1\n" +
+				"Further recurring gifts for the plan (review in Raiser's Edge NXT): gift-3\n" +
+				"Would relink 2 payments:\n" +
+				"  gift-2: none -> gift-1\n" +
+				"  gift-4: gift-3 -> gift-1\n",
+		},
+		"applied": {
+			report: &Report{GiftCount: 1, Links: report.Links[:1], ParentID: "gift-1", RecurringID: "rec_1"},
+			want: "Recurring plan rec_1: 1 gifts found\n" +
+				"Recurring gift: gift-1\n" +
+				"Relinked 1 payments:\n" +
+				"  gift-2: none -> gift-1\n",
+		},
+		"nothing to fix": {
+			report: &Report{GiftCount: 1, ParentID: "gift-1", RecurringID: "rec_1"},
+			want: "Recurring plan rec_1: 1 gifts found\n" +
+				"Recurring gift: gift-1\n" +
+				"All payments are linked to the recurring gift.\n",
+		},
+		"no recurring gift": {
+			report: &Report{GiftCount: 1, RecurringID: "rec_1"},
+			want: "Recurring plan rec_1: 1 gifts found\n" +
+				"No recurring gift found for the plan, so payments cannot be linked.\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, tc.report.Write(&buf, tc.dryRun))
+			require.Equal(t, tc.want, buf.String())
+		})
+	}
+}
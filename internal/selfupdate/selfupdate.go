@@ -0,0 +1,261 @@
+// Package selfupdate replaces the running giftbridge binary with the latest GitHub release,
+// after checking the download against the SHA-256 checksums published with the release. The checksums catch a
+// corrupted download, but releases are not signed, so they do not prove who published the binary.
+package selfupdate
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// checksumsAsset is the release asset listing the SHA-256 checksum of each binary, in sha256sum format.
+	checksumsAsset = "checksums.txt"
+
+	// maxChecksumsSize caps how much of the checksums asset is read.
+	maxChecksumsSize = 64 << 10
+)
+
+// Asset is a file attached to a release.
+//
+//nolint:tagliatelle // External API uses snake_case.
+type Asset struct {
+	// DownloadURL is where the file is downloaded from.
+	DownloadURL string `json:"browser_download_url"`
+
+	// Name is the file name, such as giftbridge-linux-amd64.
+	Name string `json:"name"`
+}
+
+// Release is a published giftbridge release.
+//
+//nolint:tagliatelle // External API uses snake_case.
+type Release struct {
+	// Assets are the files attached to the release.
+	Assets []Asset `json:"assets"`
+
+	// Tag is the release's version tag, such as v1.4.0.
+	Tag string `json:"tag_name"`
+}
+
+// Updater installs giftbridge releases published on GitHub.
+type Updater struct {
+	// baseURL is the GitHub API URL of the repository whose releases are installed.
+	baseURL string
+
+	// httpClient is the HTTP client for making requests.
+	httpClient *http.Client
+}
+
+// NewUpdater creates a new Updater for the giftbridge repository's releases.
+func NewUpdater(opts ...Option) (*Updater, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, fmt.Errorf("applying option: %w", err)
+		}
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: o.timeout}
+	}
+
+	return &Updater{
+		baseURL:    o.baseURL,
+		httpClient: httpClient,
+	}, nil
+}
+
+// AssetName returns the name of the release binary built for the given platform, such as
+// giftbridge-linux-amd64 or giftbridge-windows-amd64.exe.
+func AssetName(goos string, goarch string) string {
+	name := "giftbridge-" + goos + "-" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Apply downloads the release binary for the running platform, checks it against the release's checksums
+// and replaces the executable at path with it. The executable is left unchanged if any step fails.
+func (u *Updater) Apply(ctx context.Context, release *Release, path string) error {
+	if release == nil {
+		return errors.New("release is required")
+	}
+	if path == "" {
+		return errors.New("path is required")
+	}
+
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	binary, ok := release.asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+	checksums, ok := release.asset(checksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s to verify the download against", release.Tag, checksumsAsset)
+	}
+
+	want, err := u.checksum(ctx, checksums, name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := u.download(ctx, binary, filepath.Dir(path), want)
+	if err != nil {
+		return err
+	}
+	if err := replace(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// Latest returns the latest published release.
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	resp, err := u.get(ctx, u.baseURL+"/releases/latest", "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, errors.New("latest release has no tag")
+	}
+
+	return &release, nil
+}
+
+// asset returns the release asset with the given name.
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// checksum downloads the checksums asset and returns the hex-encoded SHA-256 checksum it lists for name.
+func (u *Updater) checksum(ctx context.Context, checksums Asset, name string) (string, error) {
+	resp, err := u.get(ctx, checksums.DownloadURL, "")
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", checksums.Name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxChecksumsSize))
+	for scanner.Scan() {
+		// Lines are "<checksum>  <name>", with a "*" before the name for files hashed in binary mode.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading %s: %w", checksums.Name, err)
+	}
+
+	return "", fmt.Errorf("%s does not list %s", checksums.Name, name)
+}
+
+// download saves the binary asset to a temporary file in dir and returns its path, once its SHA-256 checksum
+// matches want. Keeping the file in the executable's directory lets it be renamed into place.
+func (u *Updater) download(ctx context.Context, binary Asset, dir string, want string) (string, error) {
+	resp, err := u.get(ctx, binary.DownloadURL, "")
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", binary.Name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	tmp, err := os.CreateTemp(dir, ".giftbridge-update-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary file: %w", err)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("downloading %s: %w", binary.Name, err)
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("checksum mismatch for %s: got %s, want %s", binary.Name, got, want)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("making %s executable: %w", binary.Name, err)
+	}
+
+	return tmp.Name(), nil
+}
+
+// get sends a GET request to reqURL and returns the response when it succeeded.
+// The caller must close the response body.
+func (u *Updater) get(ctx context.Context, reqURL string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
+
+// replace moves the binary at newPath over the executable at path. The running executable is renamed aside
+// first, since Windows cannot overwrite a running executable but can rename it, and is put back if the new
+// binary cannot be moved into place.
+func replace(newPath string, path string) error {
+	old := path + ".old"
+	// Clear an aside binary left by an earlier update.
+	_ = os.Remove(old)
+
+	if err := os.Rename(path, old); err != nil {
+		return fmt.Errorf("moving current binary aside: %w", err)
+	}
+	if err := os.Rename(newPath, path); err != nil {
+		_ = os.Rename(old, path)
+		return fmt.Errorf("installing new binary: %w", err)
+	}
+
+	// Windows refuses while the old binary is still running; it is cleared by the next update instead.
+	_ = os.Remove(old)
+	return nil
+}
@@ -0,0 +1,238 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		goarch string
+		goos   string
+		want   string
+	}{
+		"linux": {
+			goarch: "amd64",
+			goos:   "linux",
+			want:   "giftbridge-linux-amd64",
+		},
+		"macOS": {
+			goarch: "arm64",
+			goos:   "darwin",
+			want:   "giftbridge-darwin-arm64",
+		},
+		"windows adds exe": {
+			goarch: "amd64",
+			goos:   "windows",
+			want:   "giftbridge-windows-amd64.exe",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, AssetName(tc.goos, tc.goarch))
+		})
+	}
+}
+
+func TestNewUpdater(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg string
+		opts   []Option
+	}{
+		"defaults": {},
+		"custom base URL": {
+			opts: []Option{WithBaseURL("https://github.example.com/api/repos/acme/giftbridge")},
+		},
+		"empty base URL": {
+			opts:   []Option{WithBaseURL(" ")},
+			errMsg: "base URL cannot be empty",
+		},
+		"nil HTTP client": {
+			opts:   []Option{WithHTTPClient(nil)},
+			errMsg: "HTTP client cannot be nil",
+		},
+		"zero timeout": {
+			opts:   []Option{WithTimeout(0)},
+			errMsg: "timeout must be positive",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			updater, err := NewUpdater(tc.opts...)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, updater)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, updater)
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body    string
+		errMsg  string
+		status  int
+		wantTag string
+	}{
+		"latest release": {
+			body:    `{"tag_name":"v1.4.0","assets":[{"name":"checksums.txt","browser_download_url":"https://x/c"}]}`,
+			status:  http.StatusOK,
+			wantTag: "v1.4.0",
+		},
+		"no releases": {
+			body:   `{"message":"Not Found"}`,
+			status: http.StatusNotFound,
+			errMsg: "unexpected status 404",
+		},
+		"missing tag": {
+			body:   `{}`,
+			status: http.StatusOK,
+			errMsg: "latest release has no tag",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/releases/latest", r.URL.Path)
+				require.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(server.Close)
+
+			updater, err := NewUpdater(WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			release, err := updater.Latest(context.Background())
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantTag, release.Tag)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("new giftbridge binary")
+	sum := sha256.Sum256(binary)
+	binaryName := AssetName(runtime.GOOS, runtime.GOARCH)
+
+	tests := map[string]struct {
+		checksums  string
+		errMsg     string
+		omitAssets []string
+	}{
+		"replaces the executable": {
+			checksums: "0000  giftbridge-other-arch\n" + hex.EncodeToString(sum[:]) + "  " + binaryName + "\n",
+		},
+		"binary mode checksum line": {
+			checksums: hex.EncodeToString(sum[:]) + " *" + binaryName + "\n",
+		},
+		"checksum mismatch": {
+			checksums: hex.EncodeToString(make([]byte, sha256.Size)) + "  " + binaryName + "\n",
+			errMsg:    "checksum mismatch for " + binaryName,
+		},
+		"binary not listed in checksums": {
+			checksums: hex.EncodeToString(sum[:]) + "  giftbridge-other-arch\n",
+			errMsg:    "checksums.txt does not list " + binaryName,
+		},
+		"no binary for platform": {
+			omitAssets: []string{binaryName},
+			errMsg:     "release v1.4.0 has no binary for " + runtime.GOOS + "/" + runtime.GOARCH,
+		},
+		"no checksums": {
+			omitAssets: []string{checksumsAsset},
+			errMsg:     "release v1.4.0 has no checksums.txt",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/download/binary", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(binary)
+			})
+			mux.HandleFunc("/download/checksums", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tc.checksums))
+			})
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			release := &Release{Tag: "v1.4.0"}
+			assets := map[string]string{
+				binaryName:     server.URL + "/download/binary",
+				checksumsAsset: server.URL + "/download/checksums",
+			}
+			for _, omit := range tc.omitAssets {
+				delete(assets, omit)
+			}
+			for assetName, downloadURL := range assets {
+				release.Assets = append(release.Assets, Asset{DownloadURL: downloadURL, Name: assetName})
+			}
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "giftbridge")
+			require.NoError(t, os.WriteFile(path, []byte("old giftbridge binary"), 0o755))
+
+			updater, err := NewUpdater(WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			err = updater.Apply(context.Background(), release, path)
+
+			data, readErr := os.ReadFile(path)
+			require.NoError(t, readErr)
+			entries, readErr := os.ReadDir(dir)
+			require.NoError(t, readErr)
+			require.Len(t, entries, 1, "only the executable should remain")
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Equal(t, "old giftbridge binary", string(data))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, string(binary), string(data))
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			require.NotZero(t, info.Mode().Perm()&0o100, "binary should be executable")
+		})
+	}
+}
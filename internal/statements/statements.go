@@ -0,0 +1,287 @@
+// Package statements builds year-end giving statements from the gifts giftbridge has created in Blackbaud.
+package statements
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// csvHeader is the header row written by WriteCSV.
+var csvHeader = []string{
+	"constituent_id",
+	"first_name",
+	"last_name",
+	"email",
+	"address_lines",
+	"city",
+	"state",
+	"post_code",
+	"country",
+	"currency",
+	"gift_count",
+	"total_amount",
+	"first_gift_date",
+	"last_gift_date",
+}
+
+// Blackbaud defines the Blackbaud operations needed to build statements.
+type Blackbaud interface {
+	// Constituent returns the constituent with the given ID.
+	Constituent(ctx context.Context, constituentID string) (*blackbaud.Constituent, error)
+
+	// ListGiftsByConstituent returns all gifts for a constituent, optionally filtered by gift type.
+	ListGiftsByConstituent(
+		ctx context.Context,
+		constituentID string,
+		giftTypes []blackbaud.GiftType,
+	) ([]blackbaud.Gift, error)
+}
+
+// Tracker defines the donation tracker operations needed to build statements.
+type Tracker interface {
+	// DonationsBetween returns all tracked donations made in [from, to).
+	DonationsBetween(ctx context.Context, from time.Time, to time.Time) ([]storage.DonationRecord, error)
+}
+
+// Generator builds statements from tracked donations and the matching Blackbaud records.
+type Generator struct {
+	blackbaud Blackbaud
+	tracker   Tracker
+}
+
+// Report is the result of generating statements for a year.
+type Report struct {
+	// MissingGiftIDs lists tracked gifts that no longer exist in Blackbaud and were excluded.
+	MissingGiftIDs []string
+
+	// Statements contains one statement per constituent and currency, ordered by constituent ID.
+	Statements []Statement
+}
+
+// Statement summarises a constituent's giving in a single currency.
+type Statement struct {
+	// Address is the constituent's address, if known.
+	Address blackbaud.Address
+
+	// ConstituentID is the Blackbaud constituent identifier.
+	ConstituentID string
+
+	// Currency is the ISO currency code of the gifts.
+	Currency string
+
+	// Email is the constituent's email address, if known.
+	Email string
+
+	// FirstGiftDate is the date of the earliest gift in the statement.
+	FirstGiftDate string
+
+	// FirstName is the constituent's first name.
+	FirstName string
+
+	// GiftCount is the number of gifts in the statement.
+	GiftCount int
+
+	// LastGiftDate is the date of the latest gift in the statement.
+	LastGiftDate string
+
+	// LastName is the constituent's last name.
+	LastName string
+
+	// TotalCents is the total amount given, in minor currency units.
+	TotalCents int64
+}
+
+// NewGenerator creates a new statement generator.
+func NewGenerator(tracker Tracker, bb Blackbaud) (*Generator, error) {
+	if tracker == nil {
+		return nil, errors.New("tracker is required")
+	}
+	if bb == nil {
+		return nil, errors.New("blackbaud client is required")
+	}
+
+	return &Generator{
+		blackbaud: bb,
+		tracker:   tracker,
+	}, nil
+}
+
+// Generate builds statements for gifts giftbridge created for donations made in the given calendar year (UTC).
+// Amounts and dates are taken from Blackbaud, so edits made in Raiser's Edge NXT are reflected.
+func (g *Generator) Generate(ctx context.Context, year int) (*Report, error) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	records, err := g.tracker.DonationsBetween(ctx, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("listing tracked donations: %w", err)
+	}
+
+	// Group tracked gifts by constituent so each constituent is fetched once.
+	tracked := make(map[string]map[string]storage.DonationRecord)
+	for _, record := range records {
+		if record.ConstituentID == "" || record.GiftID == "" {
+			continue
+		}
+		if tracked[record.ConstituentID] == nil {
+			tracked[record.ConstituentID] = make(map[string]storage.DonationRecord)
+		}
+		tracked[record.ConstituentID][record.GiftID] = record
+	}
+
+	constituentIDs := make([]string, 0, len(tracked))
+	for id := range tracked {
+		constituentIDs = append(constituentIDs, id)
+	}
+	sort.Strings(constituentIDs)
+
+	report := &Report{}
+	for _, constituentID := range constituentIDs {
+		statements, missing, err := g.constituentStatements(ctx, constituentID, tracked[constituentID])
+		if err != nil {
+			return nil, err
+		}
+		report.Statements = append(report.Statements, statements...)
+		report.MissingGiftIDs = append(report.MissingGiftIDs, missing...)
+	}
+	sort.Strings(report.MissingGiftIDs)
+
+	return report, nil
+}
+
+// constituentStatements builds the statements for one constituent and returns any tracked gifts missing in Blackbaud.
+func (g *Generator) constituentStatements(
+	ctx context.Context,
+	constituentID string,
+	tracked map[string]storage.DonationRecord,
+) ([]Statement, []string, error) {
+	constituent, err := g.blackbaud.Constituent(ctx, constituentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting constituent %s: %w", constituentID, err)
+	}
+
+	gifts, err := g.blackbaud.ListGiftsByConstituent(ctx, constituentID, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing gifts for constituent %s: %w", constituentID, err)
+	}
+
+	byCurrency := make(map[string]*Statement)
+	found := make(map[string]bool, len(tracked))
+	for _, gift := range gifts {
+		record, ok := tracked[gift.ID]
+		if !ok || gift.Amount == nil {
+			continue
+		}
+		found[gift.ID] = true
+
+		statement, ok := byCurrency[record.Currency]
+		if !ok {
+			statement = newStatement(constituentID, constituent, record.Currency)
+			byCurrency[record.Currency] = statement
+		}
+		statement.add(gift)
+	}
+
+	var missing []string
+	for giftID := range tracked {
+		if !found[giftID] {
+			missing = append(missing, giftID)
+		}
+	}
+
+	statements := make([]Statement, 0, len(byCurrency))
+	for _, statement := range byCurrency {
+		statements = append(statements, *statement)
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].Currency < statements[j].Currency
+	})
+
+	return statements, missing, nil
+}
+
+// WriteCSV writes statements as CSV with a header row, suitable for mail merges.
+func WriteCSV(w io.Writer, statements []Statement) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, s := range statements {
+		row := []string{
+			s.ConstituentID,
+			s.FirstName,
+			s.LastName,
+			s.Email,
+			s.Address.AddressLines,
+			s.Address.City,
+			s.Address.State,
+			s.Address.PostCode,
+			s.Address.Country,
+			s.Currency,
+			strconv.Itoa(s.GiftCount),
+			formatCents(s.TotalCents),
+			s.FirstGiftDate,
+			s.LastGiftDate,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("writing statement for %s: %w", s.ConstituentID, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("flushing CSV: %w", err)
+	}
+
+	return nil
+}
+
+// add includes a gift in the statement.
+func (s *Statement) add(gift blackbaud.Gift) {
+	s.GiftCount++
+	s.TotalCents += int64(math.Round(gift.Amount.Value * 100))
+
+	// Gift dates are YYYY-MM-DD, so string comparison orders them.
+	if s.FirstGiftDate == "" || gift.Date < s.FirstGiftDate {
+		s.FirstGiftDate = gift.Date
+	}
+	if gift.Date > s.LastGiftDate {
+		s.LastGiftDate = gift.Date
+	}
+}
+
+// formatCents formats an amount in minor units with two decimal places.
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// newStatement creates an empty statement populated with the constituent's contact details.
+func newStatement(constituentID string, constituent *blackbaud.Constituent, currency string) *Statement {
+	statement := &Statement{
+		ConstituentID: constituentID,
+		Currency:      currency,
+		FirstName:     constituent.FirstName,
+		LastName:      constituent.LastName,
+	}
+	if constituent.Address != nil {
+		statement.Address = *constituent.Address
+	}
+	if constituent.Email != nil {
+		statement.Email = constituent.Email.Address
+	}
+	return statement
+}
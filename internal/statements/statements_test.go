@@ -0,0 +1,199 @@
+package statements
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+type mockBlackbaud struct {
+	constituents map[string]*blackbaud.Constituent
+	gifts        map[string][]blackbaud.Gift
+}
+
+func (m *mockBlackbaud) Constituent(_ context.Context, constituentID string) (*blackbaud.Constituent, error) {
+	constituent, ok := m.constituents[constituentID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return constituent, nil
+}
+
+func (m *mockBlackbaud) ListGiftsByConstituent(
+	_ context.Context,
+	constituentID string,
+	_ []blackbaud.GiftType,
+) ([]blackbaud.Gift, error) {
+	return m.gifts[constituentID], nil
+}
+
+type mockTracker struct {
+	from    time.Time
+	records []storage.DonationRecord
+	to      time.Time
+}
+
+func (m *mockTracker) DonationsBetween(
+	_ context.Context,
+	from time.Time,
+	to time.Time,
+) ([]storage.DonationRecord, error) {
+	m.from = from
+	m.to = to
+	return m.records, nil
+}
+
+func TestNewGenerator(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		blackbaud Blackbaud
+		tracker   Tracker
+		wantErr   string
+	}{
+		"valid": {
+			blackbaud: &mockBlackbaud{},
+			tracker:   &mockTracker{},
+		},
+		"missing tracker": {
+			blackbaud: &mockBlackbaud{},
+			wantErr:   "tracker is required",
+		},
+		"missing blackbaud": {
+			tracker: &mockTracker{},
+			wantErr: "blackbaud client is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			generator, err := NewGenerator(tc.tracker, tc.blackbaud)
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, generator)
+		})
+	}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	tracker := &mockTracker{
+		records: []storage.DonationRecord{
+			{ConstituentID: "const-2", Currency: "GBP", DonationID: "don_1", GiftID: "gift-1"},
+			{ConstituentID: "const-2", Currency: "GBP", DonationID: "don_2", GiftID: "gift-2"},
+			{ConstituentID: "const-2", Currency: "USD", DonationID: "don_3", GiftID: "gift-3"},
+			{ConstituentID: "const-1", Currency: "GBP", DonationID: "don_4", GiftID: "gift-4"},
+			{ConstituentID: "const-1", Currency: "GBP", DonationID: "don_5", GiftID: "gift-deleted"},
+		},
+	}
+	bb := &mockBlackbaud{
+		constituents: map[string]*blackbaud.Constituent{
+			"const-1": {FirstName: "Ada", LastName: "Lovelace"},
+			"const-2": {
+				Address:   &blackbaud.Address{AddressLines: "1 High Street", City: "London", PostCode: "N1 1AA"},
+				Email:     &blackbaud.Email{Address: "grace@example.com"},
+				FirstName: "Grace",
+				LastName:  "Hopper",
+			},
+		},
+		gifts: map[string][]blackbaud.Gift{
+			"const-1": {
+				{Amount: &blackbaud.GiftAmount{Value: 10}, Date: "2024-05-01", ID: "gift-4"},
+				// Gifts entered manually in Raiser's Edge NXT are not part of the statement.
+				{Amount: &blackbaud.GiftAmount{Value: 500}, Date: "2024-05-02", ID: "gift-manual"},
+			},
+			"const-2": {
+				{Amount: &blackbaud.GiftAmount{Value: 25.5}, Date: "2024-09-01", ID: "gift-2"},
+				{Amount: &blackbaud.GiftAmount{Value: 10.1}, Date: "2024-02-01", ID: "gift-1"},
+				{Amount: &blackbaud.GiftAmount{Value: 40}, Date: "2024-03-01", ID: "gift-3"},
+			},
+		},
+	}
+
+	generator, err := NewGenerator(tracker, bb)
+	require.NoError(t, err)
+
+	report, err := generator.Generate(context.Background(), 2024)
+
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), tracker.from)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), tracker.to)
+	require.Equal(t, []string{"gift-deleted"}, report.MissingGiftIDs)
+	require.Equal(t, []Statement{
+		{
+			ConstituentID: "const-1",
+			Currency:      "GBP",
+			FirstGiftDate: "2024-05-01",
+			FirstName:     "Ada",
+			GiftCount:     1,
+			LastGiftDate:  "2024-05-01",
+			LastName:      "Lovelace",
+			TotalCents:    1000,
+		},
+		{
+			Address:       blackbaud.Address{AddressLines: "1 High Street", City: "London", PostCode: "N1 1AA"},
+			ConstituentID: "const-2",
+			Currency:      "GBP",
+			Email:         "grace@example.com",
+			FirstGiftDate: "2024-02-01",
+			FirstName:     "Grace",
+			GiftCount:     2,
+			LastGiftDate:  "2024-09-01",
+			LastName:      "Hopper",
+			TotalCents:    3560,
+		},
+		{
+			Address:       blackbaud.Address{AddressLines: "1 High Street", City: "London", PostCode: "N1 1AA"},
+			ConstituentID: "const-2",
+			Currency:      "USD",
+			Email:         "grace@example.com",
+			FirstGiftDate: "2024-03-01",
+			FirstName:     "Grace",
+			GiftCount:     1,
+			LastGiftDate:  "2024-03-01",
+			LastName:      "Hopper",
+			TotalCents:    4000,
+		},
+	}, report.Statements)
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Statement{
+		{
+			Address:       blackbaud.Address{AddressLines: "1 High Street, Flat 2", City: "London", PostCode: "N1 1AA"},
+			ConstituentID: "const-1",
+			Currency:      "GBP",
+			FirstGiftDate: "2024-02-01",
+			FirstName:     "Grace",
+			GiftCount:     2,
+			LastGiftDate:  "2024-09-01",
+			LastName:      "Hopper",
+			TotalCents:    3505,
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t,
+		"constituent_id,first_name,last_name,email,address_lines,city,state,post_code,country,"+
+			"currency,gift_count,total_amount,first_gift_date,last_gift_date\n"+
+			"const-1,Grace,Hopper,,\"1 High Street, Flat 2\",London,,N1 1AA,,GBP,2,35.05,2024-02-01,2024-09-01\n",
+		buf.String(),
+	)
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// AzureKeyVaultResource is the resource access tokens for Azure Key Vault are issued for.
+	AzureKeyVaultResource = "https://vault.azure.net"
+
+	// AzureStorageResource is the resource access tokens for Azure Storage are issued for.
+	AzureStorageResource = "https://storage.azure.com/"
+
+	// azureTablePartitionKey is the partition key of the entity holding the sync state.
+	azureTablePartitionKey = "giftbridge"
+
+	// azureTableRowKey is the row key of the entity holding the sync state.
+	azureTableRowKey = "sync-state"
+
+	// azureTableVersion is the Table Storage API version, the first to accept access tokens.
+	azureTableVersion = "2019-02-02"
+
+	// keyVaultAPIVersion is the Key Vault API version.
+	keyVaultAPIVersion = "7.4"
+)
+
+// AzureOption configures an Azure store.
+type AzureOption func(*azureOptions)
+
+// azureOptions holds the settings shared by the Azure stores.
+type azureOptions struct {
+	// client sends requests to the API.
+	client *http.Client
+}
+
+// WithAzureHTTPClient sets the HTTP client used to reach the store's API.
+func WithAzureHTTPClient(client *http.Client) AzureOption {
+	return func(o *azureOptions) {
+		if client != nil {
+			o.client = client
+		}
+	}
+}
+
+// newAzureOptions applies opts over the defaults.
+func newAzureOptions(opts []AzureOption) azureOptions {
+	o := azureOptions{client: defaultCloudClient}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// KeyVaultTokenStore manages OAuth refresh tokens in Azure Key Vault, for deployments on Azure.
+type KeyVaultTokenStore struct {
+	azureOptions
+
+	// secretURL is the URL of the secret storing the refresh token.
+	secretURL string
+
+	// tokens authorizes requests to Key Vault, with tokens for AzureKeyVaultResource.
+	tokens AccessTokenSource
+}
+
+// NewKeyVaultTokenStore creates a token store keeping the refresh token in the named secret of the Key Vault at
+// vaultURL. The managed identity needs the Key Vault Secrets Officer role, or get and set secret permissions.
+func NewKeyVaultTokenStore(
+	tokens AccessTokenSource,
+	vaultURL string,
+	secretName string,
+	opts ...AzureOption,
+) (*KeyVaultTokenStore, error) {
+	if tokens == nil {
+		return nil, errors.New("access token source is required")
+	}
+	if vaultURL == "" {
+		return nil, errors.New("key vault URL is required")
+	}
+	if secretName == "" {
+		return nil, errors.New("secret name is required")
+	}
+
+	return &KeyVaultTokenStore{
+		azureOptions: newAzureOptions(opts),
+		secretURL:    strings.TrimRight(vaultURL, "/") + "/secrets/" + url.PathEscape(secretName),
+		tokens:       tokens,
+	}, nil
+}
+
+// RefreshToken returns the current refresh token from Key Vault.
+func (k *KeyVaultTokenStore) RefreshToken(ctx context.Context) (string, error) {
+	var secret struct {
+		Value string `json:"value"`
+	}
+	err := k.request(http.MethodGet, nil).send(ctx, &secret)
+	if errors.Is(err, errDocumentNotFound) {
+		return "", fmt.Errorf("secret %s not found", k.secretURL)
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting secret from Key Vault: %w", err)
+	}
+	if secret.Value == "" {
+		return "", errors.New("secret has no value")
+	}
+
+	return secret.Value, nil
+}
+
+// SaveRefreshToken stores a new refresh token in Key Vault, as a new version of the secret.
+func (k *KeyVaultTokenStore) SaveRefreshToken(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.New("token cannot be empty")
+	}
+
+	if err := k.request(http.MethodPut, map[string]string{"value": token}).send(ctx, nil); err != nil {
+		return fmt.Errorf("putting secret to Key Vault: %w", err)
+	}
+
+	return nil
+}
+
+// request returns a request to the Key Vault API for the secret.
+func (k *KeyVaultTokenStore) request(method string, payload any) cloudRequest {
+	return cloudRequest{
+		client:  k.client,
+		method:  method,
+		payload: payload,
+		tokens:  k.tokens,
+		url:     k.secretURL + "?api-version=" + keyVaultAPIVersion,
+	}
+}
+
+// AzureTableStateStore keeps the sync state in the string properties of an Azure Table Storage entity, for
+// deployments on Azure. The managed identity needs the Storage Table Data Contributor role.
+type AzureTableStateStore struct {
+	fieldState
+}
+
+// NewAzureTableStateStore creates a state store keeping the sync state in an entity of the named table, which must
+// exist, at the storage account's Table Storage endpoint. The entity is created on the first write.
+func NewAzureTableStateStore(
+	tokens AccessTokenSource,
+	endpoint string,
+	tableName string,
+	opts ...AzureOption,
+) (*AzureTableStateStore, error) {
+	if tokens == nil {
+		return nil, errors.New("access token source is required")
+	}
+	if endpoint == "" {
+		return nil, errors.New("table endpoint is required")
+	}
+	if tableName == "" {
+		return nil, errors.New("table name is required")
+	}
+
+	entityURL := fmt.Sprintf("%s/%s(PartitionKey='%s',RowKey='%s')",
+		strings.TrimRight(endpoint, "/"), url.PathEscape(tableName), azureTablePartitionKey, azureTableRowKey)
+
+	return &AzureTableStateStore{
+		fieldState: fieldState{
+			document: &azureTableEntity{
+				azureOptions: newAzureOptions(opts),
+				tokens:       tokens,
+				url:          entityURL,
+			},
+		},
+	}, nil
+}
+
+// azureTableEntity reads and writes the properties of a Table Storage entity through the Table Storage REST API.
+type azureTableEntity struct {
+	azureOptions
+
+	// tokens authorizes requests to Table Storage, with tokens for AzureStorageResource.
+	tokens AccessTokenSource
+
+	// url is the URL of the entity.
+	url string
+}
+
+func (a *azureTableEntity) field(ctx context.Context, name string) (string, error) {
+	var entity map[string]any
+	req := a.request(http.MethodGet, url.Values{"$select": {name}}, nil)
+	if err := req.send(ctx, &entity); err != nil && !errors.Is(err, errDocumentNotFound) {
+		return "", fmt.Errorf("getting %s from Table Storage: %w", name, err)
+	}
+
+	value, _ := entity[name].(string)
+	return value, nil
+}
+
+// setField merges the property into the entity, inserting the entity when it does not exist.
+func (a *azureTableEntity) setField(ctx context.Context, name string, value string) error {
+	if err := a.request(http.MethodPatch, nil, map[string]string{name: value}).send(ctx, nil); err != nil {
+		return fmt.Errorf("putting %s to Table Storage: %w", name, err)
+	}
+
+	return nil
+}
+
+// request returns a request to the Table Storage API for the entity.
+func (a *azureTableEntity) request(method string, query url.Values, payload any) cloudRequest {
+	endpoint := a.url
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	return cloudRequest{
+		client: a.client,
+		header: http.Header{
+			"Accept":       {"application/json;odata=nometadata"},
+			"X-Ms-Date":    {time.Now().UTC().Format(http.TimeFormat)},
+			"X-Ms-Version": {azureTableVersion},
+		},
+		method:  method,
+		payload: payload,
+		tokens:  a.tokens,
+		url:     endpoint,
+	}
+}
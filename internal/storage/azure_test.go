@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeyVault serves the latest version of Key Vault secrets, as Azure Key Vault would.
+type fakeKeyVault struct {
+	mu sync.Mutex
+
+	// secrets holds the value of each secret, by name.
+	secrets map[string]string
+}
+
+func (f *fakeKeyVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !requireBearer(w, r) {
+		return
+	}
+	if r.URL.Query().Get("api-version") != keyVaultAPIVersion {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := r.URL.Path[len("/secrets/"):]
+	switch r.Method {
+	case http.MethodGet:
+		value, ok := f.secrets[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"SecretNotFound","message":"A secret was not found."}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": r.URL.Path, "value": value})
+	case http.MethodPut:
+		var body struct {
+			Value string `json:"value"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.secrets[name] = body.Value
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": r.URL.Path, "value": body.Value})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestNewKeyVaultTokenStore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg     string
+		secretName string
+		tokens     AccessTokenSource
+		vaultURL   string
+	}{
+		"valid": {
+			secretName: "blackbaud-token",
+			tokens:     staticTokens("test-token"),
+			vaultURL:   "https://charity.vault.azure.net",
+		},
+		"missing token source": {
+			errMsg:     "access token source is required",
+			secretName: "blackbaud-token",
+			vaultURL:   "https://charity.vault.azure.net",
+		},
+		"missing vault URL": {
+			errMsg:     "key vault URL is required",
+			secretName: "blackbaud-token",
+			tokens:     staticTokens("test-token"),
+		},
+		"missing secret name": {
+			errMsg:   "secret name is required",
+			tokens:   staticTokens("test-token"),
+			vaultURL: "https://charity.vault.azure.net",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewKeyVaultTokenStore(tc.tokens, tc.vaultURL, tc.secretName)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, store)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, store)
+		})
+	}
+}
+
+func TestKeyVaultTokenStore(t *testing.T) {
+	t.Parallel()
+
+	keyVault := &fakeKeyVault{secrets: map[string]string{}}
+	server := httptest.NewServer(keyVault)
+	t.Cleanup(server.Close)
+
+	store, err := NewKeyVaultTokenStore(staticTokens("test-token"), server.URL+"/", "blackbaud-token",
+		WithAzureHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	_, err = store.RefreshToken(context.Background())
+	require.ErrorContains(t, err, "secret "+server.URL+"/secrets/blackbaud-token not found")
+
+	require.ErrorContains(t, store.SaveRefreshToken(context.Background(), ""), "token cannot be empty")
+	require.NoError(t, store.SaveRefreshToken(context.Background(), "new-token"))
+
+	token, err := store.RefreshToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "new-token", token)
+
+	unauthorized, err := NewKeyVaultTokenStore(staticTokens("expired"), server.URL, "blackbaud-token",
+		WithAzureHTTPClient(server.Client()))
+	require.NoError(t, err)
+	require.ErrorContains(t, unauthorized.SaveRefreshToken(context.Background(), "new-token"),
+		"putting secret to Key Vault: unexpected status 401: Request had invalid authentication credentials.")
+}
+
+// fakeTableStorage serves the string properties of Table Storage entities, as Azure Table Storage would.
+type fakeTableStorage struct {
+	mu sync.Mutex
+
+	// entities holds the properties of each entity, by path.
+	entities map[string]map[string]string
+}
+
+func (f *fakeTableStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !requireBearer(w, r) {
+		return
+	}
+	if r.Header.Get("X-Ms-Version") != azureTableVersion || r.Header.Get("X-Ms-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		properties, ok := f.entities[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"odata.error":{"code":"ResourceNotFound",` +
+				`"message":{"lang":"en-US","value":"The specified resource does not exist."}}}`))
+			return
+		}
+		entity := map[string]string{"PartitionKey": azureTablePartitionKey, "RowKey": azureTableRowKey}
+		if value, ok := properties[r.URL.Query().Get("$select")]; ok {
+			entity[r.URL.Query().Get("$select")] = value
+		}
+		_ = json.NewEncoder(w).Encode(entity)
+	case http.MethodPatch:
+		var properties map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&properties); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if f.entities[r.URL.Path] == nil {
+			f.entities[r.URL.Path] = map[string]string{}
+		}
+		for name, value := range properties {
+			f.entities[r.URL.Path][name] = value
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestNewAzureTableStateStore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		endpoint  string
+		errMsg    string
+		tableName string
+		tokens    AccessTokenSource
+	}{
+		"valid": {
+			endpoint:  "https://charity.table.core.windows.net",
+			tableName: "giftbridge",
+			tokens:    staticTokens("test-token"),
+		},
+		"missing token source": {
+			endpoint:  "https://charity.table.core.windows.net",
+			errMsg:    "access token source is required",
+			tableName: "giftbridge",
+		},
+		"missing endpoint": {
+			errMsg:    "table endpoint is required",
+			tableName: "giftbridge",
+			tokens:    staticTokens("test-token"),
+		},
+		"missing table name": {
+			endpoint: "https://charity.table.core.windows.net",
+			errMsg:   "table name is required",
+			tokens:   staticTokens("test-token"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewAzureTableStateStore(tc.tokens, tc.endpoint, tc.tableName)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, store)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, store)
+		})
+	}
+}
+
+func TestAzureTableStateStore(t *testing.T) {
+	t.Parallel()
+
+	tables := &fakeTableStorage{entities: map[string]map[string]string{}}
+	server := httptest.NewServer(tables)
+	t.Cleanup(server.Close)
+
+	store, err := NewAzureTableStateStore(staticTokens("test-token"), server.URL, "giftbridge",
+		WithAzureHTTPClient(server.Client()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Nothing is recorded before the entity is first written.
+	lastSync, err := store.LastSyncTime(ctx)
+	require.NoError(t, err)
+	require.True(t, lastSync.IsZero())
+	pending, err := store.PendingDonationIDs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	syncedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SetLastSyncTime(ctx, syncedAt))
+	require.NoError(t, store.SetPendingDonationIDs(ctx, []string{"don_1", "don_2"}))
+	require.NoError(t, store.RemovePendingDonationID(ctx, "don_1"))
+	require.NoError(t, store.SetFetchState(ctx, &FetchState{Cursor: "don_2", Since: syncedAt}))
+
+	tables.mu.Lock()
+	require.Equal(t, map[string]string{
+		"FetchState":         `{"cursor":"don_2","since":"2025-03-01T12:00:00Z"}`,
+		"LastSyncTime":       "2025-03-01T12:00:00Z",
+		"PendingDonationIDs": "don_2",
+	}, tables.entities["/giftbridge(PartitionKey='giftbridge',RowKey='sync-state')"])
+	tables.mu.Unlock()
+
+	lastSync, err = store.LastSyncTime(ctx)
+	require.NoError(t, err)
+	require.Equal(t, syncedAt, lastSync)
+	pending, err = store.PendingDonationIDs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"don_2"}, pending)
+	fetchState, err := store.FetchState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &FetchState{Cursor: "don_2", Since: syncedAt}, fetchState)
+
+	unauthorized, err := NewAzureTableStateStore(staticTokens("expired"), server.URL, "giftbridge",
+		WithAzureHTTPClient(server.Client()))
+	require.NoError(t, err)
+	_, err = unauthorized.LastSyncTime(ctx)
+	require.ErrorContains(t, err, "getting LastSyncTime from Table Storage: unexpected status 401")
+}
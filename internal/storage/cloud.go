@@ -0,0 +1,402 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+)
+
+const (
+	// accessTokenExpiryMargin is how long before an access token expires that a new one is fetched.
+	accessTokenExpiryMargin = time.Minute
+
+	// azureIdentityAPIVersion is the version of the App Service managed identity API.
+	azureIdentityAPIVersion = "2019-08-01"
+
+	// cloudTimeout is the timeout of the default client for Google Cloud and Azure APIs and their token endpoints.
+	cloudTimeout = 30 * time.Second
+
+	// defaultGoogleMetadataHost is the host of the metadata server on Google Cloud.
+	defaultGoogleMetadataHost = "metadata.google.internal"
+
+	// fetchStateField is the document field holding the checkpoint of an unfinished fetch.
+	fetchStateField = "FetchState"
+
+	// lastSyncTimeField is the document field holding the time of the last successful sync.
+	lastSyncTimeField = "LastSyncTime"
+
+	// pendingDonationIDsField is the document field holding the donation IDs still to be processed.
+	pendingDonationIDsField = "PendingDonationIDs"
+)
+
+var (
+	// defaultCloudClient sends requests to Google Cloud and Azure when no client is given, with a timeout so a
+	// stalled API or token endpoint cannot hang a run.
+	defaultCloudClient = &http.Client{Timeout: cloudTimeout}
+
+	// errDocumentNotFound is returned by a cloud API request for a document, secret or entity that does not exist.
+	errDocumentNotFound = errors.New("not found")
+)
+
+// AccessTokenSource provides OAuth access tokens for a cloud provider's APIs.
+type AccessTokenSource interface {
+	// AccessToken returns a token that is valid for at least another minute.
+	AccessToken(ctx context.Context) (string, error)
+}
+
+// AzureManagedIdentity is the managed identity of an Azure Functions or App Service app. The platform sets
+// Endpoint and Header in the IDENTITY_ENDPOINT and IDENTITY_HEADER environment variables.
+type AzureManagedIdentity struct {
+	// ClientID is the client ID of a user-assigned identity, or empty for the app's system-assigned identity.
+	ClientID string
+
+	// Endpoint is the URL of the app's local token endpoint.
+	Endpoint string
+
+	// Header is the secret sent to the token endpoint to prove the request comes from the app.
+	Header string
+}
+
+// cachedTokenSource reuses an access token until shortly before it expires.
+type cachedTokenSource struct {
+	// fetch requests a new access token, returning it with its expiry.
+	fetch func(ctx context.Context) (string, time.Time, error)
+
+	// mu guards expiry and token.
+	mu sync.Mutex
+
+	// expiry is when token expires.
+	expiry time.Time
+
+	// token is the current access token.
+	token string
+}
+
+// AccessToken returns the cached access token, fetching a new one when it is about to expire.
+func (c *cachedTokenSource) AccessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Add(accessTokenExpiryMargin).Before(c.expiry) {
+		return c.token, nil
+	}
+
+	token, expiry, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expiry = token, expiry
+
+	return token, nil
+}
+
+// NewAzureTokenSource returns access tokens for the Azure resource, such as https://vault.azure.net, issued to the
+// app's managed identity. The client defaults to one with a 30 second timeout.
+func NewAzureTokenSource(client *http.Client, identity AzureManagedIdentity, resource string) AccessTokenSource {
+	if client == nil {
+		client = defaultCloudClient
+	}
+
+	return &cachedTokenSource{
+		fetch: func(ctx context.Context) (string, time.Time, error) {
+			if identity.Endpoint == "" || identity.Header == "" {
+				return "", time.Time{}, errors.New("managed identity is not enabled for this app")
+			}
+
+			query := url.Values{"api-version": {azureIdentityAPIVersion}, "resource": {resource}}
+			if identity.ClientID != "" {
+				query.Set("client_id", identity.ClientID)
+			}
+			header := http.Header{"X-Identity-Header": {identity.Header}}
+
+			//nolint:tagliatelle // External API uses snake_case.
+			var resp struct {
+				AccessToken string          `json:"access_token"`
+				ExpiresOn   json.RawMessage `json:"expires_on"`
+			}
+			if err := fetchToken(ctx, client, identity.Endpoint+"?"+query.Encode(), header, &resp); err != nil {
+				return "", time.Time{}, fmt.Errorf("getting Azure access token for %s: %w", resource, err)
+			}
+
+			// The token endpoint sends the expiry as a string of seconds since the epoch.
+			expiresOn, err := strconv.ParseInt(strings.Trim(string(resp.ExpiresOn), `"`), 10, 64)
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("parsing Azure access token expiry: %w", err)
+			}
+
+			return resp.AccessToken, time.Unix(expiresOn, 0), nil
+		},
+	}
+}
+
+// NewGoogleTokenSource returns access tokens for the service account the code runs as on Google Cloud, from the
+// metadata server at host (default: metadata.google.internal). The client defaults to one with a 30 second timeout.
+func NewGoogleTokenSource(client *http.Client, host string) AccessTokenSource {
+	if client == nil {
+		client = defaultCloudClient
+	}
+	if host == "" {
+		host = defaultGoogleMetadataHost
+	}
+	endpoint := "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token"
+
+	return &cachedTokenSource{
+		fetch: func(ctx context.Context) (string, time.Time, error) {
+			//nolint:tagliatelle // External API uses snake_case.
+			var resp struct {
+				AccessToken string `json:"access_token"`
+				ExpiresIn   int    `json:"expires_in"`
+			}
+			header := http.Header{"Metadata-Flavor": {"Google"}}
+			if err := fetchToken(ctx, client, endpoint, header, &resp); err != nil {
+				return "", time.Time{}, fmt.Errorf("getting Google Cloud access token: %w", err)
+			}
+
+			return resp.AccessToken, time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second), nil
+		},
+	}
+}
+
+// fetchToken requests an access token from a local token endpoint, decoding the response into v.
+func fetchToken(ctx context.Context, client *http.Client, endpoint string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body := httpclient.ReadErrorBody(resp.Body, httpclient.DefaultMaxBodySize)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, cloudAPIError([]byte(body)))
+	}
+
+	body, err := httpclient.ReadBody(resp.Body, httpclient.DefaultMaxBodySize)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding token: %w", err)
+	}
+
+	return nil
+}
+
+// cloudRequest is a request to a Google Cloud or Azure API, authorized with an access token.
+type cloudRequest struct {
+	// client sends the request.
+	client *http.Client
+
+	// header holds headers sent as well as the authorization.
+	header http.Header
+
+	// method is the HTTP method.
+	method string
+
+	// payload is encoded as the JSON body, when set.
+	payload any
+
+	// tokens provides the access token.
+	tokens AccessTokenSource
+
+	// url is the request URL.
+	url string
+}
+
+// send sends the request, decoding a successful response into v when set.
+// A 404 response returns errDocumentNotFound.
+func (r cloudRequest) send(ctx context.Context, v any) error {
+	token, err := r.tokens.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if r.payload != nil {
+		data, err := json.Marshal(r.payload)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if r.payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errDocumentNotFound
+	case resp.StatusCode >= http.StatusBadRequest:
+		respBody := httpclient.ReadErrorBody(resp.Body, httpclient.DefaultMaxBodySize)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, cloudAPIError([]byte(respBody)))
+	}
+
+	respBody, err := httpclient.ReadBody(resp.Body, httpclient.DefaultMaxBodySize)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if v == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// cloudAPIError returns the message of a Google Cloud, Key Vault or Table Storage error response,
+// or the body itself when it has none.
+func cloudAPIError(body []byte) string {
+	//nolint:tagliatelle // External API names the Table Storage error field odata.error.
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		ODataError struct {
+			Message struct {
+				Value string `json:"value"`
+			} `json:"message"`
+		} `json:"odata.error"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil {
+		if resp.Error.Message != "" {
+			return resp.Error.Message
+		}
+		if resp.ODataError.Message.Value != "" {
+			return resp.ODataError.Message.Value
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// fieldDocument reads and writes the string fields of a document in a cloud database.
+type fieldDocument interface {
+	// field returns the value of the named field, or an empty string when it, or the document, does not exist.
+	field(ctx context.Context, name string) (string, error)
+
+	// setField sets the named field, creating the document when it does not exist.
+	setField(ctx context.Context, name string, value string) error
+}
+
+// fieldState keeps the sync state in the fields of one document, for the Google Cloud and Azure state stores.
+// The pending donation IDs and fetch checkpoint are kept beside the last sync time, so interrupted runs resume.
+type fieldState struct {
+	// document holds the state's fields.
+	document fieldDocument
+}
+
+// LastSyncTime returns the timestamp of the last successful sync, or zero if none has been recorded.
+func (f *fieldState) LastSyncTime(ctx context.Context) (time.Time, error) {
+	value, err := f.document.field(ctx, lastSyncTimeField)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing last sync time: %w", err)
+	}
+
+	return t, nil
+}
+
+// SetLastSyncTime updates the last sync timestamp.
+func (f *fieldState) SetLastSyncTime(ctx context.Context, t time.Time) error {
+	return f.document.setField(ctx, lastSyncTimeField, t.Format(time.RFC3339))
+}
+
+// PendingDonationIDs returns the list of donation IDs still to be processed.
+func (f *fieldState) PendingDonationIDs(ctx context.Context) ([]string, error) {
+	value, err := f.document.field(ctx, pendingDonationIDsField)
+	if err != nil || value == "" {
+		return nil, err
+	}
+
+	return strings.Split(value, ","), nil
+}
+
+// SetPendingDonationIDs stores the list of donation IDs to be processed.
+func (f *fieldState) SetPendingDonationIDs(ctx context.Context, ids []string) error {
+	return f.document.setField(ctx, pendingDonationIDsField, strings.Join(ids, ","))
+}
+
+// RemovePendingDonationID removes a single ID from the pending list after processing.
+func (f *fieldState) RemovePendingDonationID(ctx context.Context, id string) error {
+	ids, err := f.PendingDonationIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("getting pending IDs: %w", err)
+	}
+
+	remaining := make([]string, 0, len(ids))
+	for _, existingID := range ids {
+		if existingID != id {
+			remaining = append(remaining, existingID)
+		}
+	}
+
+	return f.SetPendingDonationIDs(ctx, remaining)
+}
+
+// FetchState returns the checkpoint of an unfinished fetch, or nil if no fetch is in progress.
+func (f *fieldState) FetchState(ctx context.Context) (*FetchState, error) {
+	value, err := f.document.field(ctx, fetchStateField)
+	if err != nil || value == "" {
+		return nil, err
+	}
+
+	var state FetchState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return nil, fmt.Errorf("parsing fetch state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// SetFetchState stores the checkpoint of an unfinished fetch. A nil state clears it once the fetch completes.
+func (f *fieldState) SetFetchState(ctx context.Context, state *FetchState) error {
+	value := ""
+	if state != nil {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("encoding fetch state: %w", err)
+		}
+		value = string(data)
+	}
+
+	return f.document.setField(ctx, fetchStateField, value)
+}
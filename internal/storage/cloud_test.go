@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// staticTokens is an AccessTokenSource that always returns the same token.
+type staticTokens string
+
+func (s staticTokens) AccessToken(_ context.Context) (string, error) {
+	return string(s), nil
+}
+
+// requireBearer fails the request unless it carries the static test token.
+func requireBearer(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"code":401,"message":"Request had invalid authentication credentials."}}`))
+		return false
+	}
+	return true
+}
+
+func TestNewGoogleTokenSource(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		require.Equal(t, "/computeMetadata/v1/instance/service-accounts/default/token", r.URL.Path)
+		_, _ = w.Write([]byte(`{"access_token":"ya29.token","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	tokens := NewGoogleTokenSource(server.Client(), strings.TrimPrefix(server.URL, "http://"))
+
+	for range 2 {
+		token, err := tokens.AccessToken(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "ya29.token", token)
+	}
+	require.Equal(t, int32(1), requests.Load(), "token should be reused until it is about to expire")
+}
+
+func TestNewAzureTokenSource(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg    string
+		expiresOn string
+		identity  AzureManagedIdentity
+		status    int
+		wantCalls int32
+	}{
+		"system-assigned identity": {
+			expiresOn: `"` + expiry(time.Hour) + `"`,
+			identity:  AzureManagedIdentity{Header: "identity-secret"},
+			status:    http.StatusOK,
+			wantCalls: 1,
+		},
+		"user-assigned identity": {
+			expiresOn: `"` + expiry(time.Hour) + `"`,
+			identity:  AzureManagedIdentity{ClientID: "client-id", Header: "identity-secret"},
+			status:    http.StatusOK,
+			wantCalls: 1,
+		},
+		"token about to expire is fetched again": {
+			expiresOn: `"` + expiry(30*time.Second) + `"`,
+			identity:  AzureManagedIdentity{Header: "identity-secret"},
+			status:    http.StatusOK,
+			wantCalls: 2,
+		},
+		"identity not enabled": {
+			errMsg:   "managed identity is not enabled for this app",
+			identity: AzureManagedIdentity{},
+		},
+		"identity rejected": {
+			errMsg:    "getting Azure access token for https://vault.azure.net: unexpected status 400",
+			expiresOn: `"` + expiry(time.Hour) + `"`,
+			identity:  AzureManagedIdentity{Header: "identity-secret"},
+			status:    http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				require.Equal(t, "identity-secret", r.Header.Get("X-Identity-Header"))
+				require.Equal(t, AzureKeyVaultResource, r.URL.Query().Get("resource"))
+				require.Equal(t, azureIdentityAPIVersion, r.URL.Query().Get("api-version"))
+				require.Equal(t, tc.identity.ClientID, r.URL.Query().Get("client_id"))
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(`{"access_token":"eyJ0.token","expires_on":` + tc.expiresOn + `}`))
+			}))
+			t.Cleanup(server.Close)
+
+			if tc.identity.Header != "" {
+				tc.identity.Endpoint = server.URL + "/msi/token"
+			}
+			tokens := NewAzureTokenSource(server.Client(), tc.identity, AzureKeyVaultResource)
+
+			for range 2 {
+				token, err := tokens.AccessToken(context.Background())
+				if tc.errMsg != "" {
+					require.Error(t, err)
+					require.Contains(t, err.Error(), tc.errMsg)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, "eyJ0.token", token)
+			}
+			require.Equal(t, tc.wantCalls, calls.Load())
+		})
+	}
+}
+
+// expiry returns the Unix time after d, as the Azure token endpoint formats it.
+func expiry(d time.Duration) string {
+	return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+}
@@ -0,0 +1,1295 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// DonationTableSchemaVersion is the schema version written by this release of the tracker.
+	DonationTableSchemaVersion = 4
+
+	// CreatedMonthIndexName is the global secondary index used to find donations by the month they were made.
+	CreatedMonthIndexName = "CreatedMonthIndex"
+
+	// RecurringIDIndexName is the global secondary index used to find donations by recurring plan.
+	RecurringIDIndexName = "RecurringIdIndex"
+
+	attrAmount        = "amount"
+	attrConstituentID = "constituent_id"
+	attrCreatedAt     = "created_at"
+	attrCreatedMonth  = "created_month"
+	attrCurrency      = "currency"
+	attrDonationID    = "donation_id"
+	attrExcludedAt    = "excluded_at"
+	attrExpiresAt     = "expires_at"
+	attrFrequency     = "frequency"
+	attrGiftID        = "gift_id"
+	attrGiftType      = "gift_type"
+	attrRecurringID   = "recurring_id"
+	attrSchemaVersion = "schema_version"
+	attrSupporterID   = "supporter_id"
+	attrTrackedAt     = "tracked_at"
+
+	// createdMonthLayout formats the created month partition key of the created month index.
+	createdMonthLayout = "2006-01"
+
+	// maxQueriedMonths is the longest window, in months, read through the created month index.
+	// Longer windows are scanned, since one scan costs less than a query per month once most months are empty.
+	maxQueriedMonths = 36
+
+	// defaultBatchRetryDelay is how long to wait before first retrying items a batch read or write left unprocessed.
+	defaultBatchRetryDelay = 100 * time.Millisecond
+
+	// maxBatchAttempts is how many times a batch read or write is attempted before unprocessed items are given up on.
+	maxBatchAttempts = 5
+
+	// maxBatchWriteItems is the most items DynamoDB accepts in one batch write.
+	maxBatchWriteItems = 25
+
+	// defaultTablePollInterval is how often table status is checked while waiting for it to become active.
+	defaultTablePollInterval = 2 * time.Second
+
+	// schemaItemKey is the partition key of the item recording the table's schema version.
+	// It cannot collide with a FundraiseUp donation ID.
+	schemaItemKey = "#schema"
+)
+
+// donationTableMigrations upgrade a donation table one schema version at a time.
+// Migrations must be idempotent, since a table created by Terraform or CDK may already match a later version.
+var donationTableMigrations = []tableMigration{
+	{version: 2, apply: (*DonationTracker).addRecurringIDIndex},
+	{version: 3, apply: (*DonationTracker).addCreatedMonthIndex},
+	{version: 4, apply: (*DonationTracker).enableTimeToLive},
+}
+
+// DynamoDBAPI defines the DynamoDB operations used by the donation tracker.
+type DynamoDBAPI interface {
+	// BatchGetItem retrieves up to 100 items by key, possibly leaving some unprocessed.
+	BatchGetItem(
+		ctx context.Context,
+		params *dynamodb.BatchGetItemInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.BatchGetItemOutput, error)
+
+	// BatchWriteItem stores or deletes up to 25 items, possibly leaving some unprocessed.
+	BatchWriteItem(
+		ctx context.Context,
+		params *dynamodb.BatchWriteItemInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.BatchWriteItemOutput, error)
+
+	// CreateTable creates a table.
+	CreateTable(
+		ctx context.Context,
+		params *dynamodb.CreateTableInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.CreateTableOutput, error)
+
+	// DescribeTable retrieves table metadata.
+	DescribeTable(
+		ctx context.Context,
+		params *dynamodb.DescribeTableInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.DescribeTableOutput, error)
+
+	// DescribeTimeToLive retrieves the table's time to live settings.
+	DescribeTimeToLive(
+		ctx context.Context,
+		params *dynamodb.DescribeTimeToLiveInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.DescribeTimeToLiveOutput, error)
+
+	// GetItem retrieves a single item by key.
+	GetItem(
+		ctx context.Context,
+		params *dynamodb.GetItemInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.GetItemOutput, error)
+
+	// PutItem stores a single item.
+	PutItem(
+		ctx context.Context,
+		params *dynamodb.PutItemInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.PutItemOutput, error)
+
+	// Query retrieves items by key condition.
+	Query(
+		ctx context.Context,
+		params *dynamodb.QueryInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.QueryOutput, error)
+
+	// Scan reads every item in a table, optionally filtered.
+	Scan(
+		ctx context.Context,
+		params *dynamodb.ScanInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.ScanOutput, error)
+
+	// UpdateTable modifies table settings and indexes.
+	UpdateTable(
+		ctx context.Context,
+		params *dynamodb.UpdateTableInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.UpdateTableOutput, error)
+
+	// UpdateTimeToLive enables or disables time to live on the table.
+	UpdateTimeToLive(
+		ctx context.Context,
+		params *dynamodb.UpdateTimeToLiveInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+// AlreadyTrackedError is returned when a donation is already tracked with a different gift,
+// for example because a concurrent run created a gift for it first. The existing record is left unchanged.
+type AlreadyTrackedError struct {
+	// DonationID is the FundraiseUp donation identifier.
+	DonationID string
+
+	// GiftID is the gift the donation is already tracked with.
+	GiftID string
+}
+
+// Error implements error.
+func (e *AlreadyTrackedError) Error() string {
+	return fmt.Sprintf("donation %s is already tracked with gift %s", e.DonationID, e.GiftID)
+}
+
+// BatchTrackError reports the records of a TrackBatch call that were not written.
+type BatchTrackError struct {
+	// AlreadyTracked lists the donations left unchanged because they are tracked with a different gift.
+	AlreadyTracked []*AlreadyTrackedError
+
+	// Failed holds the errors that stopped other records being written, one per failed batch.
+	Failed []error
+}
+
+// Error implements error.
+func (e *BatchTrackError) Error() string {
+	return errors.Join(e.Unwrap()...).Error()
+}
+
+// Unwrap returns the individual errors, so errors.As finds an AlreadyTrackedError among them.
+func (e *BatchTrackError) Unwrap() []error {
+	errs := make([]error, 0, len(e.AlreadyTracked)+len(e.Failed))
+	for _, err := range e.AlreadyTracked {
+		errs = append(errs, err)
+	}
+	return append(errs, e.Failed...)
+}
+
+// DonationRecord maps a FundraiseUp donation to the Blackbaud gift created for it.
+type DonationRecord struct {
+	// Amount is the donation amount as a decimal string.
+	Amount string
+
+	// ConstituentID is the Blackbaud constituent the gift belongs to.
+	ConstituentID string
+
+	// CreatedAt is when the donation was made in FundraiseUp.
+	CreatedAt time.Time
+
+	// Currency is the three-letter currency code.
+	Currency string
+
+	// DonationID is the FundraiseUp donation identifier.
+	DonationID string
+
+	// ExcludedAt is when the donation was excluded from syncing because its gift was deleted in Raiser's Edge NXT.
+	// Zero for donations that are still synced.
+	ExcludedAt time.Time
+
+	// ExpiresAt is when DynamoDB may delete the record. Zero for records kept until deleted by hand.
+	ExpiresAt time.Time
+
+	// Frequency is how often the donation's recurring plan took installments when it was made, such as "monthly".
+	// Empty for one-off donations and donations tracked before frequencies were recorded.
+	Frequency string
+
+	// GiftID is the Blackbaud gift identifier.
+	GiftID string
+
+	// GiftType is the type of the Blackbaud gift, e.g. Donation or RecurringGiftPayment.
+	// Empty for donations tracked before gift types were recorded.
+	GiftType string
+
+	// RecurringID is the FundraiseUp recurring plan identifier, empty for one-off donations.
+	RecurringID string
+
+	// SupporterID is the FundraiseUp supporter identifier.
+	SupporterID string
+
+	// TrackedAt is when the record was written.
+	TrackedAt time.Time
+}
+
+// DonationCounts summarises the donations tracked over a period.
+type DonationCounts struct {
+	// ByDay counts donations by the UTC day they were made, keyed by date in YYYY-MM-DD format.
+	ByDay map[string]int
+
+	// ByGiftType counts donations by the type of gift created for them.
+	// Donations tracked before gift types were recorded are counted under an empty type.
+	ByGiftType map[string]int
+
+	// ByRecurringID counts recurring donation payments by recurring plan. One-off donations are not included.
+	ByRecurringID map[string]int
+
+	// Total is the number of donations counted.
+	Total int
+}
+
+// DonationTableStatus describes what EnsureDonationTable did.
+type DonationTableStatus struct {
+	// Created indicates the table did not exist and was created.
+	Created bool
+
+	// PreviousVersion is the schema version found before any migrations ran, zero if the table was created.
+	PreviousVersion int
+
+	// Version is the schema version of the table after EnsureDonationTable returned.
+	Version int
+}
+
+// Migrated returns true if any schema migrations were applied to an existing table.
+func (s *DonationTableStatus) Migrated() bool {
+	return !s.Created && s.PreviousVersion != s.Version
+}
+
+// DonationTracker records which donations have been synced in a DynamoDB table.
+type DonationTracker struct {
+	// batchRetryDelay is how long to wait before first retrying unprocessed batch items, doubling each retry.
+	batchRetryDelay time.Duration
+
+	// client is the DynamoDB API client.
+	client DynamoDBAPI
+
+	// pollInterval is how often table status is checked while waiting for it to become active.
+	pollInterval time.Duration
+
+	// retention is how long after a donation was made its record expires, or zero to keep records.
+	retention time.Duration
+
+	// tableName is the DynamoDB table name.
+	tableName string
+}
+
+// DonationTrackerOption configures a DonationTracker.
+type DonationTrackerOption func(*DonationTracker)
+
+// tableMigration upgrades the donation table to version.
+type tableMigration struct {
+	// apply performs the migration.
+	apply func(t *DonationTracker, ctx context.Context, table *types.TableDescription) error
+
+	// version is the schema version the table is at once apply succeeds.
+	version int
+}
+
+// WithBatchRetryDelay sets how long to wait before first retrying items a batch read or write left unprocessed.
+// The delay doubles on each further retry.
+func WithBatchRetryDelay(delay time.Duration) DonationTrackerOption {
+	return func(t *DonationTracker) {
+		t.batchRetryDelay = delay
+	}
+}
+
+// WithTablePollInterval sets how often table status is checked while waiting for it to become active.
+func WithTablePollInterval(interval time.Duration) DonationTrackerOption {
+	return func(t *DonationTracker) {
+		t.pollInterval = interval
+	}
+}
+
+// WithRetention sets how long after a donation was made its record expires from the table.
+// Records tracked without a donation time expire that long after they are tracked.
+func WithRetention(retention time.Duration) DonationTrackerOption {
+	return func(t *DonationTracker) {
+		t.retention = retention
+	}
+}
+
+// IsAlreadyTracked reports whether err is a donation already being tracked with a different gift.
+func IsAlreadyTracked(err error) bool {
+	var trackedErr *AlreadyTrackedError
+	return errors.As(err, &trackedErr)
+}
+
+// NewDonationTracker creates a new DynamoDB-backed donation tracker.
+func NewDonationTracker(client DynamoDBAPI, tableName string, opts ...DonationTrackerOption) (*DonationTracker, error) {
+	if client == nil {
+		return nil, errors.New("dynamodb client is required")
+	}
+	if tableName == "" {
+		return nil, errors.New("table name is required")
+	}
+
+	tracker := &DonationTracker{
+		batchRetryDelay: defaultBatchRetryDelay,
+		client:          client,
+		pollInterval:    defaultTablePollInterval,
+		tableName:       tableName,
+	}
+
+	for _, opt := range opts {
+		opt(tracker)
+	}
+
+	return tracker, nil
+}
+
+// EnsureDonationTable creates the donation table with on-demand billing if it does not exist,
+// then applies any schema migrations needed to bring an existing table up to DonationTableSchemaVersion.
+// It waits for the table and its indexes to become active, so it is safe to call before the first Track.
+func (t *DonationTracker) EnsureDonationTable(ctx context.Context) (*DonationTableStatus, error) {
+	table, err := t.describeTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if table == nil {
+		if err := t.createTable(ctx); err != nil {
+			return nil, err
+		}
+		if err := t.setSchemaVersion(ctx, DonationTableSchemaVersion); err != nil {
+			return nil, err
+		}
+		return &DonationTableStatus{Created: true, Version: DonationTableSchemaVersion}, nil
+	}
+
+	if table, err = t.waitForTable(ctx); err != nil {
+		return nil, err
+	}
+
+	version, err := t.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version > DonationTableSchemaVersion {
+		return nil, fmt.Errorf(
+			"table %s has schema version %d, newer than supported version %d",
+			t.tableName,
+			version,
+			DonationTableSchemaVersion,
+		)
+	}
+
+	status := &DonationTableStatus{PreviousVersion: version, Version: version}
+
+	for _, m := range donationTableMigrations {
+		if m.version <= version {
+			continue
+		}
+		if err := m.apply(t, ctx, table); err != nil {
+			return nil, fmt.Errorf("migrating table %s to schema version %d: %w", t.tableName, m.version, err)
+		}
+		if table, err = t.waitForTable(ctx); err != nil {
+			return nil, err
+		}
+		if err := t.setSchemaVersion(ctx, m.version); err != nil {
+			return nil, err
+		}
+		status.Version = m.version
+	}
+
+	return status, nil
+}
+
+// DonationCounts counts the tracked donations made in [from, to) by day, gift type and recurring plan.
+// Only the attributes counted are read.
+func (t *DonationTracker) DonationCounts(ctx context.Context, from time.Time, to time.Time) (*DonationCounts, error) {
+	counts := &DonationCounts{
+		ByDay:         make(map[string]int),
+		ByGiftType:    make(map[string]int),
+		ByRecurringID: make(map[string]int),
+	}
+
+	projection := "#created, #gift_type, #recurring_id"
+	err := t.eachCreated(ctx, from, to, projection, func(item map[string]types.AttributeValue) error {
+		created, err := timeAttr(item, attrCreatedAt)
+		if err != nil {
+			return fmt.Errorf("decoding donation: %w", err)
+		}
+
+		counts.ByDay[created.UTC().Format(time.DateOnly)]++
+		counts.ByGiftType[stringAttr(item, attrGiftType)]++
+		if recurringID := stringAttr(item, attrRecurringID); recurringID != "" {
+			counts.ByRecurringID[recurringID]++
+		}
+		counts.Total++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// DonationsBetween returns all tracked donations made in [from, to).
+// Windows of up to three years are read through the created month index rather than scanning the table.
+func (t *DonationTracker) DonationsBetween(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) ([]DonationRecord, error) {
+	var records []DonationRecord
+
+	err := t.eachCreated(ctx, from, to, "", func(item map[string]types.AttributeValue) error {
+		record, err := recordFromItem(item)
+		if err != nil {
+			return fmt.Errorf("decoding donation: %w", err)
+		}
+		records = append(records, *record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Forget removes the supporter and constituent IDs from a tracked donation's record, for data-subject erasure
+// requests. The donation stays tracked with its gift, so it is still skipped rather than synced again.
+// Records that no longer belong to record's supporter, or that have been deleted, are left unchanged.
+func (t *DonationTracker) Forget(ctx context.Context, record DonationRecord) error {
+	if record.SupporterID == "" {
+		return fmt.Errorf("supporter ID is required for donation %s", record.DonationID)
+	}
+
+	supporterID := record.SupporterID
+	record.ConstituentID = ""
+	record.SupporterID = ""
+
+	_, err := t.client.PutItem(ctx, &dynamodb.PutItemInput{
+		ConditionExpression:       aws.String("#supporter_id = :supporter_id"),
+		ExpressionAttributeNames:  map[string]string{"#supporter_id": attrSupporterID},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":supporter_id": stringValue(supporterID)},
+		Item:                      recordToItem(record),
+		TableName:                 aws.String(t.tableName),
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("putting donation %s to DynamoDB: %w", record.DonationID, err)
+	}
+
+	return nil
+}
+
+// Lookup returns the record for a donation, or nil if the donation has not been tracked.
+func (t *DonationTracker) Lookup(ctx context.Context, donationID string) (*DonationRecord, error) {
+	output, err := t.client.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:       donationKey(donationID),
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting donation %s from DynamoDB: %w", donationID, err)
+	}
+
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+
+	record, err := recordFromItem(output.Item)
+	if err != nil {
+		return nil, fmt.Errorf("decoding donation %s: %w", donationID, err)
+	}
+
+	return record, nil
+}
+
+// RecurringDonations returns all tracked donations for a recurring plan, using the recurring ID index.
+func (t *DonationTracker) RecurringDonations(ctx context.Context, recurringID string) ([]DonationRecord, error) {
+	var (
+		records  []DonationRecord
+		startKey map[string]types.AttributeValue
+	)
+
+	for {
+		output, err := t.client.Query(ctx, &dynamodb.QueryInput{
+			ExclusiveStartKey:         startKey,
+			ExpressionAttributeValues: map[string]types.AttributeValue{":rid": stringValue(recurringID)},
+			IndexName:                 aws.String(RecurringIDIndexName),
+			KeyConditionExpression:    aws.String(attrRecurringID + " = :rid"),
+			TableName:                 aws.String(t.tableName),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("querying recurring donations from DynamoDB: %w", err)
+		}
+
+		for _, item := range output.Items {
+			record, err := recordFromItem(item)
+			if err != nil {
+				return nil, fmt.Errorf("decoding recurring donation: %w", err)
+			}
+			records = append(records, *record)
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return records, nil
+		}
+		startKey = output.LastEvaluatedKey
+	}
+}
+
+// ReplaceGift records a new gift for a tracked donation, for example after its gift was deleted in Raiser's Edge NXT.
+// It fails with an AlreadyTrackedError if the donation has since been tracked with a gift other than previousGiftID.
+func (t *DonationTracker) ReplaceGift(ctx context.Context, record DonationRecord, previousGiftID string) error {
+	if previousGiftID == "" {
+		return fmt.Errorf("previous gift ID is required for donation %s", record.DonationID)
+	}
+	return t.put(ctx, record, previousGiftID)
+}
+
+// SupporterDonations returns all tracked donations made by a FundraiseUp supporter.
+// There is no index by supporter, so the whole table is scanned.
+func (t *DonationTracker) SupporterDonations(ctx context.Context, supporterID string) ([]DonationRecord, error) {
+	var records []DonationRecord
+
+	err := t.scanCreated(ctx, &dynamodb.ScanInput{
+		ExpressionAttributeNames:  map[string]string{"#supporter_id": attrSupporterID},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":supporter_id": stringValue(supporterID)},
+		FilterExpression:          aws.String("#supporter_id = :supporter_id"),
+		TableName:                 aws.String(t.tableName),
+	}, func(item map[string]types.AttributeValue) error {
+		record, err := recordFromItem(item)
+		if err != nil {
+			return fmt.Errorf("decoding donation: %w", err)
+		}
+		records = append(records, *record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Track records the gift created for a one-off donation.
+// It fails with an AlreadyTrackedError if the donation is already tracked with a different gift,
+// so concurrent runs and replays cannot silently replace a donation's gift. Tracking the same gift again succeeds.
+func (t *DonationTracker) Track(ctx context.Context, record DonationRecord) error {
+	return t.put(ctx, record, "")
+}
+
+// TrackRecurring records the gift created for a recurring donation payment.
+// The record must include the recurring ID so the payment can be found through the recurring ID index.
+// Like Track, it fails with an AlreadyTrackedError if the donation is already tracked with a different gift.
+func (t *DonationTracker) TrackRecurring(ctx context.Context, record DonationRecord) error {
+	if record.RecurringID == "" {
+		return fmt.Errorf("recurring ID is required for donation %s", record.DonationID)
+	}
+	return t.put(ctx, record, "")
+}
+
+// TrackBatch records the gifts created for several donations, writing up to 25 records per DynamoDB call.
+// Records with a recurring ID are found through the recurring ID index, as with TrackRecurring.
+// Batch writes cannot be conditional, so each batch's donations are read first: those already tracked are
+// written one at a time with Track's conditional put, and only untracked donations are batch written.
+// A donation first tracked by a concurrent run between the read and the write can still be overwritten.
+// Items DynamoDB leaves unprocessed, for example when throttled, are retried with backoff.
+// Every record is validated before any is written. Records that are not written, because their donation is
+// tracked with a different gift or their batch failed, are reported in a *BatchTrackError; the rest are still written.
+func (t *DonationTracker) TrackBatch(ctx context.Context, records []DonationRecord) error {
+	prepared := make([]DonationRecord, 0, len(records))
+	for _, record := range records {
+		record, err := t.prepare(record)
+		if err != nil {
+			return err
+		}
+		prepared = append(prepared, record)
+	}
+
+	batchErr := &BatchTrackError{}
+	for start := 0; start < len(prepared); start += maxBatchWriteItems {
+		end := min(start+maxBatchWriteItems, len(prepared))
+		if err := t.trackChunk(ctx, prepared[start:end], batchErr); err != nil {
+			batchErr.Failed = append(batchErr.Failed,
+				fmt.Errorf("tracking donations %d to %d of %d: %w", start+1, end, len(prepared), err))
+		}
+	}
+
+	if len(batchErr.AlreadyTracked) == 0 && len(batchErr.Failed) == 0 {
+		return nil
+	}
+	return batchErr
+}
+
+// addCreatedMonthIndex adds the created month index to tables created before schema version 3,
+// then sets the created month on existing donations so they appear in it.
+func (t *DonationTracker) addCreatedMonthIndex(ctx context.Context, table *types.TableDescription) error {
+	if !hasIndex(table, CreatedMonthIndexName) {
+		_, err := t.client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String(attrCreatedAt), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String(attrCreatedMonth), AttributeType: types.ScalarAttributeTypeS},
+			},
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+				{Create: &types.CreateGlobalSecondaryIndexAction{
+					IndexName:  aws.String(CreatedMonthIndexName),
+					KeySchema:  createdMonthKeySchema(),
+					Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+				}},
+			},
+			TableName: aws.String(t.tableName),
+		})
+		if err != nil {
+			return fmt.Errorf("creating index %s: %w", CreatedMonthIndexName, err)
+		}
+	}
+
+	return t.backfillCreatedMonth(ctx)
+}
+
+// addRecurringIDIndex adds the recurring ID index to tables created before schema version 2.
+func (t *DonationTracker) addRecurringIDIndex(ctx context.Context, table *types.TableDescription) error {
+	if hasIndex(table, RecurringIDIndexName) {
+		return nil
+	}
+
+	_, err := t.client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(attrRecurringID), AttributeType: types.ScalarAttributeTypeS},
+		},
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+			{Create: &types.CreateGlobalSecondaryIndexAction{
+				IndexName:  aws.String(RecurringIDIndexName),
+				KeySchema:  recurringIDKeySchema(),
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			}},
+		},
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("creating index %s: %w", RecurringIDIndexName, err)
+	}
+
+	return nil
+}
+
+// backfillCreatedMonth sets the created month on donations tracked before schema version 3.
+// Donations without a created time stay out of the created month index, as they match no report window.
+func (t *DonationTracker) backfillCreatedMonth(ctx context.Context) error {
+	var startKey map[string]types.AttributeValue
+
+	for {
+		output, err := t.client.Scan(ctx, &dynamodb.ScanInput{
+			ExclusiveStartKey: startKey,
+			ExpressionAttributeNames: map[string]string{
+				"#created": attrCreatedAt,
+				"#month":   attrCreatedMonth,
+			},
+			FilterExpression: aws.String("attribute_exists(#created) AND attribute_not_exists(#month)"),
+			TableName:        aws.String(t.tableName),
+		})
+		if err != nil {
+			return fmt.Errorf("scanning donations without a created month: %w", err)
+		}
+
+		for _, item := range output.Items {
+			record, err := recordFromItem(item)
+			if err != nil {
+				return fmt.Errorf("decoding donation: %w", err)
+			}
+			// A donation whose gift was replaced since the scan was rewritten with its created month already.
+			if err := t.put(ctx, *record, ""); err != nil && !IsAlreadyTracked(err) {
+				return err
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = output.LastEvaluatedKey
+	}
+}
+
+// createTable creates the donation table and its indexes, waits for them to become active,
+// then enables time to live.
+func (t *DonationTracker) createTable(ctx context.Context) error {
+	_, err := t.client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(attrCreatedAt), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(attrCreatedMonth), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(attrDonationID), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(attrRecurringID), AttributeType: types.ScalarAttributeTypeS},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName:  aws.String(CreatedMonthIndexName),
+				KeySchema:  createdMonthKeySchema(),
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+			{
+				IndexName:  aws.String(RecurringIDIndexName),
+				KeySchema:  recurringIDKeySchema(),
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(attrDonationID), KeyType: types.KeyTypeHash},
+		},
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("creating table %s: %w", t.tableName, err)
+	}
+
+	table, err := t.waitForTable(ctx)
+	if err != nil {
+		return err
+	}
+
+	return t.enableTimeToLive(ctx, table)
+}
+
+// describeTable returns the table description, or nil if the table does not exist.
+func (t *DonationTracker) describeTable(ctx context.Context) (*types.TableDescription, error) {
+	output, err := t.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(t.tableName)})
+	if err != nil {
+		var notFoundErr *types.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("describing table %s: %w", t.tableName, err)
+	}
+
+	return output.Table, nil
+}
+
+// enableTimeToLive lets DynamoDB delete records once their expiry time has passed.
+// Records without an expiry time are never deleted, so this changes nothing until a retention is set.
+func (t *DonationTracker) enableTimeToLive(ctx context.Context, _ *types.TableDescription) error {
+	output, err := t.client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("describing time to live on table %s: %w", t.tableName, err)
+	}
+
+	if ttl := output.TimeToLiveDescription; ttl != nil {
+		switch ttl.TimeToLiveStatus {
+		case types.TimeToLiveStatusEnabled, types.TimeToLiveStatusEnabling:
+			if attr := aws.ToString(ttl.AttributeName); attr != attrExpiresAt {
+				return fmt.Errorf("table %s already expires items by %s, not %s", t.tableName, attr, attrExpiresAt)
+			}
+			return nil
+		}
+	}
+
+	_, err = t.client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(t.tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attrExpiresAt),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("enabling time to live on table %s: %w", t.tableName, err)
+	}
+
+	return nil
+}
+
+// trackChunk writes up to maxBatchWriteItems prepared records. Records for donations already tracked are
+// written with conditional puts, and those tracked with a different gift are added to batchErr.
+func (t *DonationTracker) trackChunk(ctx context.Context, records []DonationRecord, batchErr *BatchTrackError) error {
+	tracked, err := t.trackedDonations(ctx, records)
+	if err != nil {
+		return err
+	}
+
+	requests := make([]types.WriteRequest, 0, len(records))
+	var errs []error
+	for _, record := range records {
+		if !tracked[record.DonationID] {
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: recordToItem(record)}})
+			continue
+		}
+
+		var trackedErr *AlreadyTrackedError
+		err := t.put(ctx, record, "")
+		switch {
+		case errors.As(err, &trackedErr):
+			batchErr.AlreadyTracked = append(batchErr.AlreadyTracked, trackedErr)
+		case err != nil:
+			errs = append(errs, err)
+		}
+	}
+
+	if err := t.batchWrite(ctx, requests); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// trackedDonations returns which of the records' donations are already in the table.
+// Keys DynamoDB leaves unprocessed are retried with backoff.
+func (t *DonationTracker) trackedDonations(ctx context.Context, records []DonationRecord) (map[string]bool, error) {
+	keys := make([]map[string]types.AttributeValue, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, donationKey(record.DonationID))
+	}
+
+	tracked := make(map[string]bool)
+	delay := t.batchRetryDelay
+	for attempt := 1; ; attempt++ {
+		output, err := t.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{t.tableName: {
+				ConsistentRead:           aws.Bool(true),
+				ExpressionAttributeNames: map[string]string{"#donation_id": attrDonationID},
+				Keys:                     keys,
+				ProjectionExpression:     aws.String("#donation_id"),
+			}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("batch reading from DynamoDB: %w", err)
+		}
+
+		for _, item := range output.Responses[t.tableName] {
+			tracked[stringAttr(item, attrDonationID)] = true
+		}
+
+		keys = output.UnprocessedKeys[t.tableName].Keys
+		if len(keys) == 0 {
+			return tracked, nil
+		}
+		if attempt == maxBatchAttempts {
+			return nil, fmt.Errorf("%d donations still unread after %d attempts", len(keys), attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("retrying %d unread donations: %w", len(keys), ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// batchWrite writes up to maxBatchWriteItems requests, retrying any DynamoDB leaves unprocessed.
+func (t *DonationTracker) batchWrite(ctx context.Context, requests []types.WriteRequest) error {
+	delay := t.batchRetryDelay
+	for attempt := 1; len(requests) > 0; attempt++ {
+		output, err := t.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{t.tableName: requests},
+		})
+		if err != nil {
+			return fmt.Errorf("batch writing to DynamoDB: %w", err)
+		}
+
+		requests = output.UnprocessedItems[t.tableName]
+		if len(requests) == 0 {
+			return nil
+		}
+		if attempt == maxBatchAttempts {
+			return fmt.Errorf("%d donations still unprocessed after %d attempts", len(requests), attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("retrying %d unprocessed donations: %w", len(requests), ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return nil
+}
+
+// prepare validates a donation record and stamps it with the tracked time and expiry time.
+func (t *DonationTracker) prepare(record DonationRecord) (DonationRecord, error) {
+	if record.DonationID == "" {
+		return record, errors.New("donation ID is required")
+	}
+	if record.GiftID == "" {
+		return record, fmt.Errorf("gift ID is required for donation %s", record.DonationID)
+	}
+	if record.TrackedAt.IsZero() {
+		record.TrackedAt = time.Now()
+	}
+	if record.ExpiresAt.IsZero() && t.retention > 0 {
+		from := record.CreatedAt
+		if from.IsZero() {
+			from = record.TrackedAt
+		}
+		record.ExpiresAt = from.Add(t.retention)
+	}
+
+	return record, nil
+}
+
+// put writes a donation record, stamping it with the tracked time, expiry time and current schema version.
+// The write only succeeds while the donation is untracked, or tracked with the record's gift or, when set,
+// replacing, so a donation's gift is never silently replaced. Otherwise it fails with an AlreadyTrackedError
+// naming the gift the donation is tracked with, which DynamoDB returns with the failed condition.
+func (t *DonationTracker) put(ctx context.Context, record DonationRecord, replacing string) error {
+	record, err := t.prepare(record)
+	if err != nil {
+		return err
+	}
+
+	condition := "attribute_not_exists(#donation_id) OR #gift_id = :gift_id"
+	values := map[string]types.AttributeValue{":gift_id": stringValue(record.GiftID)}
+	if replacing != "" {
+		condition = "attribute_not_exists(#donation_id) OR #gift_id IN (:gift_id, :replacing)"
+		values[":replacing"] = stringValue(replacing)
+	}
+
+	_, err = t.client.PutItem(ctx, &dynamodb.PutItemInput{
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            map[string]string{"#donation_id": attrDonationID, "#gift_id": attrGiftID},
+		ExpressionAttributeValues:           values,
+		Item:                                recordToItem(record),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		TableName:                           aws.String(t.tableName),
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return alreadyTracked(record.DonationID, conditionErr.Item)
+	}
+	if err != nil {
+		return fmt.Errorf("putting donation %s to DynamoDB: %w", record.DonationID, err)
+	}
+
+	return nil
+}
+
+// eachCreated calls fn for each donation made in [from, to).
+// Windows of up to maxQueriedMonths are read through the created month index one month at a time;
+// longer ones, such as "everything since the first donation", are read in a single filtered scan.
+// A non-empty projection limits the attributes read; it may refer to #created, #gift_type and #recurring_id.
+func (t *DonationTracker) eachCreated(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	projection string,
+	fn func(item map[string]types.AttributeValue) error,
+) error {
+	// RFC3339 timestamps in UTC sort lexically, and are stored to the second,
+	// so the window's last second is an inclusive upper bound equivalent to the exclusive end.
+	start := from.UTC()
+	end := to.Add(-time.Second).UTC()
+	first := stringValue(start.Format(time.RFC3339))
+	last := stringValue(end.Format(time.RFC3339))
+	if last.Value < first.Value {
+		return nil
+	}
+
+	names := map[string]string{"#created": attrCreatedAt}
+	var projectionExpression *string
+	if projection != "" {
+		names["#gift_type"] = attrGiftType
+		names["#recurring_id"] = attrRecurringID
+		projectionExpression = aws.String(projection)
+	}
+
+	firstMonth := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	if months > maxQueriedMonths {
+		return t.scanCreated(ctx, &dynamodb.ScanInput{
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: map[string]types.AttributeValue{":first": first, ":last": last},
+			FilterExpression:          aws.String("#created BETWEEN :first AND :last"),
+			ProjectionExpression:      projectionExpression,
+			TableName:                 aws.String(t.tableName),
+		}, fn)
+	}
+
+	names["#month"] = attrCreatedMonth
+	for month := firstMonth; !month.After(end); month = month.AddDate(0, 1, 0) {
+		err := t.queryCreated(ctx, &dynamodb.QueryInput{
+			ExpressionAttributeNames: names,
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":first": first,
+				":last":  last,
+				":month": stringValue(month.Format(createdMonthLayout)),
+			},
+			IndexName:              aws.String(CreatedMonthIndexName),
+			KeyConditionExpression: aws.String("#month = :month AND #created BETWEEN :first AND :last"),
+			ProjectionExpression:   projectionExpression,
+			TableName:              aws.String(t.tableName),
+		}, fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryCreated calls fn for each item returned by a query, following pagination.
+func (t *DonationTracker) queryCreated(
+	ctx context.Context,
+	input *dynamodb.QueryInput,
+	fn func(item map[string]types.AttributeValue) error,
+) error {
+	for {
+		output, err := t.client.Query(ctx, input)
+		if err != nil {
+			return fmt.Errorf("querying donations from DynamoDB: %w", err)
+		}
+
+		for _, item := range output.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// scanCreated calls fn for each item returned by a scan, following pagination.
+func (t *DonationTracker) scanCreated(
+	ctx context.Context,
+	input *dynamodb.ScanInput,
+	fn func(item map[string]types.AttributeValue) error,
+) error {
+	for {
+		output, err := t.client.Scan(ctx, input)
+		if err != nil {
+			return fmt.Errorf("scanning donations from DynamoDB: %w", err)
+		}
+
+		for _, item := range output.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// schemaVersion returns the schema version recorded in the table.
+// Tables without a version item predate versioning and are treated as version 1.
+func (t *DonationTracker) schemaVersion(ctx context.Context) (int, error) {
+	output, err := t.client.GetItem(ctx, &dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            donationKey(schemaItemKey),
+		TableName:      aws.String(t.tableName),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("getting schema version from table %s: %w", t.tableName, err)
+	}
+
+	value, ok := output.Item[attrSchemaVersion].(*types.AttributeValueMemberN)
+	if !ok {
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(value.Value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing schema version from table %s: %w", t.tableName, err)
+	}
+
+	return version, nil
+}
+
+// setSchemaVersion records the table's schema version.
+func (t *DonationTracker) setSchemaVersion(ctx context.Context, version int) error {
+	_, err := t.client.PutItem(ctx, &dynamodb.PutItemInput{
+		Item: map[string]types.AttributeValue{
+			attrDonationID:    stringValue(schemaItemKey),
+			attrSchemaVersion: &types.AttributeValueMemberN{Value: strconv.Itoa(version)},
+		},
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("setting schema version on table %s: %w", t.tableName, err)
+	}
+
+	return nil
+}
+
+// waitForTable polls until the table and all of its global secondary indexes are active.
+func (t *DonationTracker) waitForTable(ctx context.Context) (*types.TableDescription, error) {
+	for {
+		table, err := t.describeTable(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if table != nil && tableActive(table) {
+			return table, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for table %s to become active: %w", t.tableName, ctx.Err())
+		case <-time.After(t.pollInterval):
+		}
+	}
+}
+
+// alreadyTracked returns the error for a donation found tracked as item when writing a different gift.
+func alreadyTracked(donationID string, item map[string]types.AttributeValue) *AlreadyTrackedError {
+	return &AlreadyTrackedError{DonationID: donationID, GiftID: stringAttr(item, attrGiftID)}
+}
+
+// createdMonthKeySchema returns the key schema of the created month index.
+func createdMonthKeySchema() []types.KeySchemaElement {
+	return []types.KeySchemaElement{
+		{AttributeName: aws.String(attrCreatedMonth), KeyType: types.KeyTypeHash},
+		{AttributeName: aws.String(attrCreatedAt), KeyType: types.KeyTypeRange},
+	}
+}
+
+// donationKey returns the primary key for a donation item.
+func donationKey(donationID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{attrDonationID: stringValue(donationID)}
+}
+
+// recordFromItem decodes a DynamoDB item into a DonationRecord.
+func recordFromItem(item map[string]types.AttributeValue) (*DonationRecord, error) {
+	record := &DonationRecord{
+		Amount:        stringAttr(item, attrAmount),
+		ConstituentID: stringAttr(item, attrConstituentID),
+		Currency:      stringAttr(item, attrCurrency),
+		DonationID:    stringAttr(item, attrDonationID),
+		Frequency:     stringAttr(item, attrFrequency),
+		GiftID:        stringAttr(item, attrGiftID),
+		GiftType:      stringAttr(item, attrGiftType),
+		RecurringID:   stringAttr(item, attrRecurringID),
+		SupporterID:   stringAttr(item, attrSupporterID),
+	}
+
+	var err error
+	if record.CreatedAt, err = timeAttr(item, attrCreatedAt); err != nil {
+		return nil, err
+	}
+	if record.ExcludedAt, err = timeAttr(item, attrExcludedAt); err != nil {
+		return nil, err
+	}
+	if value, ok := item[attrExpiresAt].(*types.AttributeValueMemberN); ok {
+		seconds, err := strconv.ParseInt(value.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", attrExpiresAt, err)
+		}
+		record.ExpiresAt = time.Unix(seconds, 0).UTC()
+	}
+	if record.TrackedAt, err = timeAttr(item, attrTrackedAt); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// recordToItem encodes a DonationRecord as a DynamoDB item, omitting empty attributes.
+// Omitting an empty recurring ID keeps one-off donations out of the sparse recurring ID index,
+// and donations without a created time are likewise kept out of the created month index.
+func recordToItem(record DonationRecord) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		attrDonationID:    stringValue(record.DonationID),
+		attrGiftID:        stringValue(record.GiftID),
+		attrSchemaVersion: &types.AttributeValueMemberN{Value: strconv.Itoa(DonationTableSchemaVersion)},
+		attrTrackedAt:     stringValue(record.TrackedAt.UTC().Format(time.RFC3339)),
+	}
+
+	optional := map[string]string{
+		attrAmount:        record.Amount,
+		attrConstituentID: record.ConstituentID,
+		attrCurrency:      record.Currency,
+		attrFrequency:     record.Frequency,
+		attrGiftType:      record.GiftType,
+		attrRecurringID:   record.RecurringID,
+		attrSupporterID:   record.SupporterID,
+	}
+	for name, value := range optional {
+		if value != "" {
+			item[name] = stringValue(value)
+		}
+	}
+	if !record.CreatedAt.IsZero() {
+		item[attrCreatedAt] = stringValue(record.CreatedAt.UTC().Format(time.RFC3339))
+		item[attrCreatedMonth] = stringValue(record.CreatedAt.UTC().Format(createdMonthLayout))
+	}
+	if !record.ExcludedAt.IsZero() {
+		item[attrExcludedAt] = stringValue(record.ExcludedAt.UTC().Format(time.RFC3339))
+	}
+	if !record.ExpiresAt.IsZero() {
+		// DynamoDB time to live reads expiry times as epoch seconds.
+		item[attrExpiresAt] = &types.AttributeValueMemberN{Value: strconv.FormatInt(record.ExpiresAt.Unix(), 10)}
+	}
+
+	return item
+}
+
+// hasIndex returns true if the table has the named global secondary index.
+func hasIndex(table *types.TableDescription, name string) bool {
+	for _, index := range table.GlobalSecondaryIndexes {
+		if aws.ToString(index.IndexName) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// recurringIDKeySchema returns the key schema of the recurring ID index.
+func recurringIDKeySchema() []types.KeySchemaElement {
+	return []types.KeySchemaElement{{AttributeName: aws.String(attrRecurringID), KeyType: types.KeyTypeHash}}
+}
+
+// stringAttr returns the string value of an item attribute, or empty string if absent.
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if value, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
+
+// stringValue returns a DynamoDB string attribute value.
+func stringValue(s string) *types.AttributeValueMemberS {
+	return &types.AttributeValueMemberS{Value: s}
+}
+
+// tableActive returns true if the table and all of its global secondary indexes are active.
+func tableActive(table *types.TableDescription) bool {
+	if table.TableStatus != types.TableStatusActive {
+		return false
+	}
+	for _, index := range table.GlobalSecondaryIndexes {
+		if index.IndexStatus != types.IndexStatusActive {
+			return false
+		}
+	}
+	return true
+}
+
+// timeAttr parses an RFC3339 item attribute, returning zero time if absent.
+func timeAttr(item map[string]types.AttributeValue, name string) (time.Time, error) {
+	value := stringAttr(item, name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	attrDeferredEvents = "deferred_events"
+	attrVersion        = "version"
+
+	// deferralRetention is how long after the latest event was deferred under a key the deferred events expire,
+	// for events whose donation never arrives, such as one outside the synced campaign.
+	deferralRetention = 7 * 24 * time.Hour
+
+	// deferredItemPrefix prefixes the partition key of items holding deferred webhook events.
+	// Like the schema item key, it cannot collide with a FundraiseUp donation ID.
+	deferredItemPrefix = "#deferred/"
+
+	// eventItemPrefix prefixes the partition key of items recording handled webhook events.
+	eventItemPrefix = "#event/"
+
+	// eventRetention is how long a handled webhook event is remembered, well beyond FundraiseUp's redelivery period.
+	eventRetention = 30 * 24 * time.Hour
+
+	// maxDeferralAttempts is how many times deferring or releasing events is attempted when another
+	// webhook delivery changes the same item at the same time.
+	maxDeferralAttempts = 5
+)
+
+// EventHandled reports whether the webhook event with the given ID has been recorded as handled.
+func (t *DonationTracker) EventHandled(ctx context.Context, eventID string) (bool, error) {
+	output, err := t.client.GetItem(ctx, &dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            donationKey(eventItemPrefix + eventID),
+		TableName:      aws.String(t.tableName),
+	})
+	if err != nil {
+		return false, fmt.Errorf("getting event %s from DynamoDB: %w", eventID, err)
+	}
+
+	return len(output.Item) > 0, nil
+}
+
+// RecordEvent records that the webhook event with the given ID has been handled.
+// The record expires once FundraiseUp can no longer redeliver the event.
+func (t *DonationTracker) RecordEvent(ctx context.Context, eventID string, handledAt time.Time) error {
+	_, err := t.client.PutItem(ctx, &dynamodb.PutItemInput{
+		Item: map[string]types.AttributeValue{
+			attrDonationID: stringValue(eventItemPrefix + eventID),
+			attrExpiresAt:  expiresAtValue(handledAt.Add(eventRetention)),
+			attrTrackedAt:  stringValue(handledAt.UTC().Format(time.RFC3339)),
+		},
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("putting event %s to DynamoDB: %w", eventID, err)
+	}
+
+	return nil
+}
+
+// DeferEvent holds a webhook event body under key until ReleaseEvents is called for the same key.
+// Events deferred under a key expire a week after the latest one, if they are never released.
+func (t *DonationTracker) DeferEvent(ctx context.Context, key string, body []byte, deferredAt time.Time) error {
+	return t.updateDeferred(ctx, key, func(bodies [][]byte) [][]byte {
+		return append(bodies, body)
+	}, deferredAt)
+}
+
+// ReleaseEvents removes and returns the webhook event bodies held under key, in the order they were deferred.
+func (t *DonationTracker) ReleaseEvents(ctx context.Context, key string) ([][]byte, error) {
+	var released [][]byte
+	err := t.updateDeferred(ctx, key, func(bodies [][]byte) [][]byte {
+		released = bodies
+		return nil
+	}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return released, nil
+}
+
+// updateDeferred replaces the events deferred under key with the result of update. The item is versioned,
+// so concurrent deliveries deferring or releasing events under the same key never lose each other's changes.
+func (t *DonationTracker) updateDeferred(
+	ctx context.Context,
+	key string,
+	update func(bodies [][]byte) [][]byte,
+	at time.Time,
+) error {
+	itemKey := deferredItemPrefix + key
+
+	for range maxDeferralAttempts {
+		output, err := t.client.GetItem(ctx, &dynamodb.GetItemInput{
+			ConsistentRead: aws.Bool(true),
+			Key:            donationKey(itemKey),
+			TableName:      aws.String(t.tableName),
+		})
+		if err != nil {
+			return fmt.Errorf("getting events deferred under %s from DynamoDB: %w", key, err)
+		}
+
+		bodies, version, err := deferredFromItem(output.Item)
+		if err != nil {
+			return fmt.Errorf("decoding events deferred under %s: %w", key, err)
+		}
+		updated := update(bodies)
+		if len(bodies) == 0 && len(updated) == 0 {
+			return nil
+		}
+
+		events := make([]types.AttributeValue, 0, len(updated))
+		for _, body := range updated {
+			events = append(events, &types.AttributeValueMemberB{Value: body})
+		}
+
+		input := &dynamodb.PutItemInput{
+			ConditionExpression:      aws.String("attribute_not_exists(#key)"),
+			ExpressionAttributeNames: map[string]string{"#key": attrDonationID},
+			Item: map[string]types.AttributeValue{
+				attrDeferredEvents: &types.AttributeValueMemberL{Value: events},
+				attrDonationID:     stringValue(itemKey),
+				attrExpiresAt:      expiresAtValue(at.Add(deferralRetention)),
+				attrVersion:        &types.AttributeValueMemberN{Value: strconv.Itoa(version + 1)},
+			},
+			TableName: aws.String(t.tableName),
+		}
+		if len(output.Item) > 0 {
+			input.ConditionExpression = aws.String("#version = :version")
+			input.ExpressionAttributeNames = map[string]string{"#version": attrVersion}
+			input.ExpressionAttributeValues = map[string]types.AttributeValue{
+				":version": &types.AttributeValueMemberN{Value: strconv.Itoa(version)},
+			}
+		}
+
+		_, err = t.client.PutItem(ctx, input)
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("putting events deferred under %s to DynamoDB: %w", key, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("updating events deferred under %s: changed by another delivery %d times", key, maxDeferralAttempts)
+}
+
+// deferredFromItem decodes the event bodies and version of a deferred events item.
+// A missing item holds no events at version zero.
+func deferredFromItem(item map[string]types.AttributeValue) ([][]byte, int, error) {
+	if len(item) == 0 {
+		return nil, 0, nil
+	}
+
+	version := 0
+	if value, ok := item[attrVersion].(*types.AttributeValueMemberN); ok {
+		v, err := strconv.Atoi(value.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parsing %s: %w", attrVersion, err)
+		}
+		version = v
+	}
+
+	var bodies [][]byte
+	if list, ok := item[attrDeferredEvents].(*types.AttributeValueMemberL); ok {
+		for _, value := range list.Value {
+			body, ok := value.(*types.AttributeValueMemberB)
+			if !ok {
+				return nil, 0, fmt.Errorf("%s holds a %T, not binary", attrDeferredEvents, value)
+			}
+			bodies = append(bodies, body.Value)
+		}
+	}
+
+	return bodies, version, nil
+}
+
+// expiresAtValue returns a time to live attribute value, which DynamoDB reads as epoch seconds.
+func expiresAtValue(at time.Time) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(at.Unix(), 10)}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockItemTable simulates the items of a single DynamoDB table keyed by donation ID,
+// honouring the conditions the tracker puts items with and scan filters of the form "#name = :value".
+type mockItemTable struct {
+	// conflicts is how many conditional puts fail as if another writer got there first.
+	conflicts int
+
+	items map[string]map[string]types.AttributeValue
+	puts  int
+}
+
+func (m *mockItemTable) client() *mockDynamoDBClient {
+	return &mockDynamoDBClient{
+		batchWriteFunc: func(
+			_ context.Context,
+			params *dynamodb.BatchWriteItemInput,
+			_ ...func(*dynamodb.Options),
+		) (*dynamodb.BatchWriteItemOutput, error) {
+			for _, requests := range params.RequestItems {
+				for _, request := range requests {
+					switch {
+					case request.DeleteRequest != nil:
+						delete(m.items, stringAttr(request.DeleteRequest.Key, attrDonationID))
+					case request.PutRequest != nil:
+						m.items[stringAttr(request.PutRequest.Item, attrDonationID)] = request.PutRequest.Item
+					}
+				}
+			}
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+		getItemFunc: func(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{Item: m.items[stringAttr(params.Key, attrDonationID)]}, nil
+		},
+		putItemFunc: func(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			m.puts++
+			if params.ConditionExpression != nil && m.conflicts > 0 {
+				m.conflicts--
+				return nil, &types.ConditionalCheckFailedException{}
+			}
+			m.items[stringAttr(params.Item, attrDonationID)] = params.Item
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		scanFunc: func(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+			name, value, _ := strings.Cut(*params.FilterExpression, " = ")
+			attr := params.ExpressionAttributeNames[name]
+			want := stringAttr(params.ExpressionAttributeValues, value)
+			var items []map[string]types.AttributeValue
+			for _, item := range m.items {
+				if stringAttr(item, attr) == want {
+					items = append(items, item)
+				}
+			}
+			return &dynamodb.ScanOutput{Items: items}, nil
+		},
+	}
+}
+
+func TestDonationTracker_Events(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	handledAt := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	table := &mockItemTable{items: map[string]map[string]types.AttributeValue{}}
+	tracker, err := NewDonationTracker(table.client(), "donations")
+	require.NoError(t, err)
+
+	handled, err := tracker.EventHandled(ctx, "evt_1")
+	require.NoError(t, err)
+	require.False(t, handled)
+
+	require.NoError(t, tracker.RecordEvent(ctx, "evt_1", handledAt))
+
+	handled, err = tracker.EventHandled(ctx, "evt_1")
+	require.NoError(t, err)
+	require.True(t, handled)
+
+	item := table.items["#event/evt_1"]
+	require.Equal(t, &types.AttributeValueMemberN{Value: "1771063200"}, item[attrExpiresAt])
+	require.Empty(t, stringAttr(item, attrCreatedAt), "event records must stay out of the created month index")
+}
+
+func TestDonationTracker_DeferAndReleaseEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	deferredAt := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		conflicts int
+		deferred  [][]byte
+		wantErr   string
+		wantPuts  int
+	}{
+		"nothing deferred": {
+			wantPuts: 0,
+		},
+		"events released in order": {
+			deferred: [][]byte{[]byte(`{"id":"evt_2"}`), []byte(`{"id":"evt_3"}`)},
+			wantPuts: 3,
+		},
+		"retried when another delivery changes the item": {
+			conflicts: 2,
+			deferred:  [][]byte{[]byte(`{"id":"evt_2"}`)},
+			wantPuts:  4,
+		},
+		"gives up when the item keeps changing": {
+			conflicts: maxDeferralAttempts,
+			deferred:  [][]byte{[]byte(`{"id":"evt_2"}`)},
+			wantErr:   "updating events deferred under donation/don_1: changed by another delivery 5 times",
+			wantPuts:  maxDeferralAttempts,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			table := &mockItemTable{conflicts: tc.conflicts, items: map[string]map[string]types.AttributeValue{}}
+			tracker, err := NewDonationTracker(table.client(), "donations")
+			require.NoError(t, err)
+
+			for _, body := range tc.deferred {
+				err = tracker.DeferEvent(ctx, "donation/don_1", body, deferredAt)
+				if tc.wantErr != "" {
+					require.EqualError(t, err, tc.wantErr)
+					require.Equal(t, tc.wantPuts, table.puts)
+					return
+				}
+				require.NoError(t, err)
+			}
+
+			released, err := tracker.ReleaseEvents(ctx, "donation/don_1")
+			require.NoError(t, err)
+			require.Equal(t, tc.deferred, released)
+			require.Equal(t, tc.wantPuts, table.puts)
+
+			released, err = tracker.ReleaseEvents(ctx, "donation/don_1")
+			require.NoError(t, err)
+			require.Empty(t, released, "released events must not be released again")
+		})
+	}
+}
+
+func TestDeferredFromItem(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := deferredFromItem(map[string]types.AttributeValue{
+		attrDeferredEvents: &types.AttributeValueMemberL{Value: []types.AttributeValue{stringValue("evt")}},
+		attrDonationID:     stringValue("#deferred/donation/don_1"),
+	})
+	require.EqualError(t, err, "deferred_events holds a *types.AttributeValueMemberS, not binary")
+}
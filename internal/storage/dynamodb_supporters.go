@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// supporterItemPrefix prefixes the partition key of items remembering the constituent a supporter email matched.
+	// Like the schema item key, it cannot collide with a FundraiseUp donation ID.
+	supporterItemPrefix = "#supporter/"
+
+	// attrCachedSupporterID is the FundraiseUp supporter whose email a supporter item remembers, so the item can be
+	// erased with the supporter. It is not attrSupporterID, so supporter items are never taken for donations.
+	attrCachedSupporterID = "cached_supporter_id"
+)
+
+// CachedConstituent returns the constituent ID remembered for a normalized supporter email,
+// or an empty ID if none is remembered or the entry has expired.
+func (t *DonationTracker) CachedConstituent(ctx context.Context, email string) (string, error) {
+	output, err := t.client.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:       donationKey(supporterKey(email)),
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting cached constituent from DynamoDB: %w", err)
+	}
+
+	// DynamoDB deletes expired items some time after they expire, so check the expiry too.
+	if value, ok := output.Item[attrExpiresAt].(*types.AttributeValueMemberN); ok {
+		expiresAt, err := strconv.ParseInt(value.Value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("parsing %s: %w", attrExpiresAt, err)
+		}
+		if !time.Now().Before(time.Unix(expiresAt, 0)) {
+			return "", nil
+		}
+	}
+
+	return stringAttr(output.Item, attrConstituentID), nil
+}
+
+// CacheConstituent remembers the constituent ID matched for a normalized supporter email until expiresAt.
+// Only a hash of the email is stored, alongside the FundraiseUp supporter ID so ForgetCachedConstituents can
+// erase it.
+func (t *DonationTracker) CacheConstituent(
+	ctx context.Context,
+	email string,
+	supporterID string,
+	constituentID string,
+	expiresAt time.Time,
+) error {
+	item := map[string]types.AttributeValue{
+		attrConstituentID: stringValue(constituentID),
+		attrDonationID:    stringValue(supporterKey(email)),
+		attrExpiresAt:     expiresAtValue(expiresAt),
+	}
+	if supporterID != "" {
+		item[attrCachedSupporterID] = stringValue(supporterID)
+	}
+
+	_, err := t.client.PutItem(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("putting cached constituent to DynamoDB: %w", err)
+	}
+
+	return nil
+}
+
+// ForgetConstituent removes the constituent ID remembered for a normalized supporter email, for example once the
+// constituent has been merged or deleted in Raiser's Edge NXT. Forgetting an email with nothing remembered succeeds.
+func (t *DonationTracker) ForgetConstituent(ctx context.Context, email string) error {
+	if err := t.batchWrite(ctx, []types.WriteRequest{supporterDeleteRequest(supporterKey(email))}); err != nil {
+		return fmt.Errorf("deleting cached constituent: %w", err)
+	}
+
+	return nil
+}
+
+// ForgetCachedConstituents removes every constituent ID remembered for a FundraiseUp supporter's emails, for
+// data-subject erasure requests, and returns how many were removed.
+// There is no index by supporter, so the whole table is scanned.
+func (t *DonationTracker) ForgetCachedConstituents(ctx context.Context, supporterID string) (int, error) {
+	var requests []types.WriteRequest
+
+	err := t.scanCreated(ctx, &dynamodb.ScanInput{
+		ExpressionAttributeNames:  map[string]string{"#cached_supporter_id": attrCachedSupporterID},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":supporter_id": stringValue(supporterID)},
+		FilterExpression:          aws.String("#cached_supporter_id = :supporter_id"),
+		TableName:                 aws.String(t.tableName),
+	}, func(item map[string]types.AttributeValue) error {
+		requests = append(requests, supporterDeleteRequest(stringAttr(item, attrDonationID)))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		if err := t.batchWrite(ctx, requests[start:min(start+maxBatchWriteItems, len(requests))]); err != nil {
+			return start, fmt.Errorf("deleting cached constituents: %w", err)
+		}
+	}
+
+	return len(requests), nil
+}
+
+// supporterDeleteRequest returns the batch write request deleting the item with the given partition key.
+func supporterDeleteRequest(key string) types.WriteRequest {
+	return types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: donationKey(key)}}
+}
+
+// supporterKey returns the partition key of the item remembering the constituent for a normalized email.
+func supporterKey(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return supporterItemPrefix + hex.EncodeToString(sum[:])
+}
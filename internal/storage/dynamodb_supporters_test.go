@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDonationTracker_SupporterCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	table := &mockItemTable{items: map[string]map[string]types.AttributeValue{}}
+	tracker, err := NewDonationTracker(table.client(), "donations")
+	require.NoError(t, err)
+
+	constituentID, err := tracker.CachedConstituent(ctx, "jane@example.com")
+	require.NoError(t, err)
+	require.Empty(t, constituentID)
+
+	expiresAt := time.Now().Add(time.Hour)
+	require.NoError(t, tracker.CacheConstituent(ctx, "jane@example.com", "sup_jane", "const-123", expiresAt))
+
+	constituentID, err = tracker.CachedConstituent(ctx, "jane@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "const-123", constituentID)
+
+	constituentID, err = tracker.CachedConstituent(ctx, "john@example.com")
+	require.NoError(t, err)
+	require.Empty(t, constituentID)
+
+	require.Len(t, table.items, 1)
+	for key, item := range table.items {
+		require.True(t, strings.HasPrefix(key, supporterItemPrefix))
+		require.NotContains(t, key, "jane")
+		require.Equal(t, expiresAtValue(expiresAt), item[attrExpiresAt])
+	}
+
+	t.Run("expired entry is ignored before DynamoDB deletes it", func(t *testing.T) {
+		expired := time.Now().Add(-time.Minute)
+		require.NoError(t, tracker.CacheConstituent(ctx, "jane@example.com", "sup_jane", "const-123", expired))
+
+		constituentID, err := tracker.CachedConstituent(ctx, "jane@example.com")
+		require.NoError(t, err)
+		require.Empty(t, constituentID)
+	})
+}
+
+func TestDonationTracker_ForgetConstituent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	table := &mockItemTable{items: map[string]map[string]types.AttributeValue{}}
+	tracker, err := NewDonationTracker(table.client(), "donations")
+	require.NoError(t, err)
+	require.NoError(t, tracker.CacheConstituent(ctx, "jane@example.com", "sup_jane", "const-123", expiresAt))
+	require.NoError(t, tracker.CacheConstituent(ctx, "john@example.com", "sup_john", "const-456", expiresAt))
+
+	require.NoError(t, tracker.ForgetConstituent(ctx, "jane@example.com"))
+
+	constituentID, err := tracker.CachedConstituent(ctx, "jane@example.com")
+	require.NoError(t, err)
+	require.Empty(t, constituentID)
+	constituentID, err = tracker.CachedConstituent(ctx, "john@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "const-456", constituentID)
+
+	// Forgetting an email with nothing remembered succeeds.
+	require.NoError(t, tracker.ForgetConstituent(ctx, "jane@example.com"))
+}
+
+func TestDonationTracker_ForgetCachedConstituents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	table := &mockItemTable{items: map[string]map[string]types.AttributeValue{
+		"don_1": {attrDonationID: stringValue("don_1"), attrSupporterID: stringValue("sup_jane")},
+	}}
+	tracker, err := NewDonationTracker(table.client(), "donations")
+	require.NoError(t, err)
+	require.NoError(t, tracker.CacheConstituent(ctx, "jane@example.com", "sup_jane", "const-123", expiresAt))
+	require.NoError(t, tracker.CacheConstituent(ctx, "jane@work.example", "sup_jane", "const-123", expiresAt))
+	require.NoError(t, tracker.CacheConstituent(ctx, "john@example.com", "sup_john", "const-456", expiresAt))
+
+	forgotten, err := tracker.ForgetCachedConstituents(ctx, "sup_jane")
+
+	require.NoError(t, err)
+	require.Equal(t, 2, forgotten)
+	for _, email := range []string{"jane@example.com", "jane@work.example"} {
+		constituentID, err := tracker.CachedConstituent(ctx, email)
+		require.NoError(t, err)
+		require.Empty(t, constituentID)
+	}
+	constituentID, err := tracker.CachedConstituent(ctx, "john@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "const-456", constituentID)
+
+	// The supporter's donation records are left for Forget.
+	require.Contains(t, table.items, "don_1")
+}
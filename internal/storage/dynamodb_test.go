@@ -0,0 +1,1120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDynamoDBClient struct {
+	batchGetFunc      func(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	batchWriteFunc    func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	createTableFunc   func(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	describeTableFunc func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	describeTTLFunc   func(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	getItemFunc       func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	putItemFunc       func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	queryFunc         func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	scanFunc          func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	updateTableFunc   func(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error)
+	updateTTLFunc     func(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+func (m *mockDynamoDBClient) BatchGetItem(
+	ctx context.Context,
+	params *dynamodb.BatchGetItemInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.BatchGetItemOutput, error) {
+	if m.batchGetFunc != nil {
+		return m.batchGetFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.BatchGetItemOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) BatchWriteItem(
+	ctx context.Context,
+	params *dynamodb.BatchWriteItemInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.BatchWriteItemOutput, error) {
+	if m.batchWriteFunc != nil {
+		return m.batchWriteFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) CreateTable(
+	ctx context.Context,
+	params *dynamodb.CreateTableInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.CreateTableOutput, error) {
+	if m.createTableFunc != nil {
+		return m.createTableFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) DescribeTable(
+	ctx context.Context,
+	params *dynamodb.DescribeTableInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.DescribeTableOutput, error) {
+	if m.describeTableFunc != nil {
+		return m.describeTableFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableStatus: types.TableStatusActive}}, nil
+}
+
+func (m *mockDynamoDBClient) DescribeTimeToLive(
+	ctx context.Context,
+	params *dynamodb.DescribeTimeToLiveInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	if m.describeTTLFunc != nil {
+		return m.describeTTLFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.DescribeTimeToLiveOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) GetItem(
+	ctx context.Context,
+	params *dynamodb.GetItemInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.GetItemOutput, error) {
+	if m.getItemFunc != nil {
+		return m.getItemFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) PutItem(
+	ctx context.Context,
+	params *dynamodb.PutItemInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.PutItemOutput, error) {
+	if m.putItemFunc != nil {
+		return m.putItemFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) Query(
+	ctx context.Context,
+	params *dynamodb.QueryInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.QueryOutput, error) {
+	if m.queryFunc != nil {
+		return m.queryFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.QueryOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) Scan(
+	ctx context.Context,
+	params *dynamodb.ScanInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.ScanOutput, error) {
+	if m.scanFunc != nil {
+		return m.scanFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.ScanOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) UpdateTable(
+	ctx context.Context,
+	params *dynamodb.UpdateTableInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.UpdateTableOutput, error) {
+	if m.updateTableFunc != nil {
+		return m.updateTableFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.UpdateTableOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) UpdateTimeToLive(
+	ctx context.Context,
+	params *dynamodb.UpdateTimeToLiveInput,
+	optFns ...func(*dynamodb.Options),
+) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	if m.updateTTLFunc != nil {
+		return m.updateTTLFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+// mockDonationTable simulates a single DynamoDB table for EnsureDonationTable tests.
+type mockDonationTable struct {
+	exists        bool
+	indexes       []string
+	items         []map[string]types.AttributeValue
+	puts          []string
+	schemaVersion string
+	ttlAttribute  string
+	updates       int
+}
+
+func (m *mockDonationTable) client() *mockDynamoDBClient {
+	return &mockDynamoDBClient{
+		createTableFunc: func(_ context.Context, params *dynamodb.CreateTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+			m.exists = true
+			for _, index := range params.GlobalSecondaryIndexes {
+				m.indexes = append(m.indexes, aws.ToString(index.IndexName))
+			}
+			return &dynamodb.CreateTableOutput{}, nil
+		},
+		describeTableFunc: func(_ context.Context, _ *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+			if !m.exists {
+				return nil, &types.ResourceNotFoundException{}
+			}
+			table := &types.TableDescription{TableStatus: types.TableStatusActive}
+			for _, name := range m.indexes {
+				table.GlobalSecondaryIndexes = append(table.GlobalSecondaryIndexes, types.GlobalSecondaryIndexDescription{
+					IndexName:   aws.String(name),
+					IndexStatus: types.IndexStatusActive,
+				})
+			}
+			return &dynamodb.DescribeTableOutput{Table: table}, nil
+		},
+		describeTTLFunc: func(_ context.Context, _ *dynamodb.DescribeTimeToLiveInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+			ttl := &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}
+			if m.ttlAttribute != "" {
+				ttl = &types.TimeToLiveDescription{
+					AttributeName:    aws.String(m.ttlAttribute),
+					TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+				}
+			}
+			return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: ttl}, nil
+		},
+		getItemFunc: func(_ context.Context, _ *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			if m.schemaVersion == "" {
+				return &dynamodb.GetItemOutput{}, nil
+			}
+			return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+				attrSchemaVersion: &types.AttributeValueMemberN{Value: m.schemaVersion},
+			}}, nil
+		},
+		putItemFunc: func(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			key := params.Item[attrDonationID].(*types.AttributeValueMemberS).Value
+			if key != schemaItemKey {
+				m.puts = append(m.puts, params.Item[attrCreatedMonth].(*types.AttributeValueMemberS).Value)
+				return &dynamodb.PutItemOutput{}, nil
+			}
+			m.schemaVersion = params.Item[attrSchemaVersion].(*types.AttributeValueMemberN).Value
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		scanFunc: func(_ context.Context, _ *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+			return &dynamodb.ScanOutput{Items: m.items}, nil
+		},
+		updateTableFunc: func(_ context.Context, params *dynamodb.UpdateTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
+			for _, update := range params.GlobalSecondaryIndexUpdates {
+				m.indexes = append(m.indexes, aws.ToString(update.Create.IndexName))
+			}
+			m.updates++
+			return &dynamodb.UpdateTableOutput{}, nil
+		},
+		updateTTLFunc: func(_ context.Context, params *dynamodb.UpdateTimeToLiveInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+			m.ttlAttribute = aws.ToString(params.TimeToLiveSpecification.AttributeName)
+			m.updates++
+			return &dynamodb.UpdateTimeToLiveOutput{}, nil
+		},
+	}
+}
+
+func TestNewDonationTracker(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client    DynamoDBAPI
+		errMsg    string
+		tableName string
+		wantErr   bool
+	}{
+		"valid inputs": {
+			client:    &mockDynamoDBClient{},
+			tableName: "giftbridge-donations",
+			wantErr:   false,
+		},
+		"nil client": {
+			client:    nil,
+			tableName: "giftbridge-donations",
+			wantErr:   true,
+			errMsg:    "dynamodb client is required",
+		},
+		"empty table name": {
+			client:    &mockDynamoDBClient{},
+			tableName: "",
+			wantErr:   true,
+			errMsg:    "table name is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker, err := NewDonationTracker(tc.client, tc.tableName)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, tracker)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, tracker)
+			}
+		})
+	}
+}
+
+func TestDonationTracker_EnsureDonationTable(t *testing.T) {
+	t.Parallel()
+
+	bothIndexes := []string{RecurringIDIndexName, CreatedMonthIndexName}
+	legacyItems := []map[string]types.AttributeValue{
+		{
+			attrCreatedAt:  stringValue("2024-03-01T09:00:00Z"),
+			attrDonationID: stringValue("don_1"),
+			attrGiftID:     stringValue("gift-1"),
+		},
+	}
+
+	tests := map[string]struct {
+		table       *mockDonationTable
+		want        *DonationTableStatus
+		wantPuts    []string
+		wantUpdates int
+	}{
+		"creates missing table": {
+			table:       &mockDonationTable{},
+			want:        &DonationTableStatus{Created: true, Version: DonationTableSchemaVersion},
+			wantUpdates: 1,
+		},
+		"leaves current table unchanged": {
+			table: &mockDonationTable{
+				exists:        true,
+				indexes:       bothIndexes,
+				schemaVersion: "4",
+				ttlAttribute:  attrExpiresAt,
+			},
+			want: &DonationTableStatus{PreviousVersion: 4, Version: 4},
+		},
+		"adds indexes and time to live to version 1 table": {
+			table:       &mockDonationTable{exists: true},
+			want:        &DonationTableStatus{PreviousVersion: 1, Version: 4},
+			wantUpdates: 3,
+		},
+		"adds created month index and backfills version 2 table": {
+			table: &mockDonationTable{
+				exists:        true,
+				indexes:       []string{RecurringIDIndexName},
+				items:         legacyItems,
+				schemaVersion: "2",
+			},
+			want:        &DonationTableStatus{PreviousVersion: 2, Version: 4},
+			wantPuts:    []string{"2024-03"},
+			wantUpdates: 2,
+		},
+		"enables time to live on version 3 table": {
+			table:       &mockDonationTable{exists: true, indexes: bothIndexes, schemaVersion: "3"},
+			want:        &DonationTableStatus{PreviousVersion: 3, Version: 4},
+			wantUpdates: 1,
+		},
+		"records version on table created outside giftbridge": {
+			table: &mockDonationTable{exists: true, indexes: bothIndexes, ttlAttribute: attrExpiresAt},
+			want:  &DonationTableStatus{PreviousVersion: 1, Version: 4},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker, err := NewDonationTracker(tc.table.client(), "giftbridge-donations")
+			require.NoError(t, err)
+
+			status, err := tracker.EnsureDonationTable(context.Background())
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, status)
+			require.ElementsMatch(t, bothIndexes, tc.table.indexes)
+			require.Equal(t, "4", tc.table.schemaVersion)
+			require.Equal(t, attrExpiresAt, tc.table.ttlAttribute)
+			require.Equal(t, tc.wantPuts, tc.table.puts)
+			require.Equal(t, tc.wantUpdates, tc.table.updates)
+		})
+	}
+}
+
+func TestDonationTracker_EnsureDonationTableErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client *mockDynamoDBClient
+		errMsg string
+	}{
+		"newer schema version": {
+			client: (&mockDonationTable{exists: true, schemaVersion: "99"}).client(),
+			errMsg: "newer than supported version",
+		},
+		"time to live on another attribute": {
+			client: (&mockDonationTable{exists: true, schemaVersion: "3", ttlAttribute: "ttl"}).client(),
+			errMsg: "table giftbridge-donations already expires items by ttl, not expires_at",
+		},
+		"describe fails": {
+			client: &mockDynamoDBClient{
+				describeTableFunc: func(_ context.Context, _ *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+					return nil, errors.New("access denied")
+				},
+			},
+			errMsg: "describing table giftbridge-donations",
+		},
+		"create fails": {
+			client: &mockDynamoDBClient{
+				createTableFunc: func(_ context.Context, _ *dynamodb.CreateTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+					return nil, errors.New("limit exceeded")
+				},
+				describeTableFunc: func(_ context.Context, _ *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+					return nil, &types.ResourceNotFoundException{}
+				},
+			},
+			errMsg: "creating table giftbridge-donations",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker, err := NewDonationTracker(tc.client, "giftbridge-donations")
+			require.NoError(t, err)
+
+			status, err := tracker.EnsureDonationTable(context.Background())
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+			require.Nil(t, status)
+		})
+	}
+}
+
+func TestDonationTracker_EnsureDonationTableWaitsForActive(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := &mockDynamoDBClient{
+		describeTableFunc: func(_ context.Context, _ *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+			calls++
+			status := types.TableStatusCreating
+			if calls > 2 {
+				status = types.TableStatusActive
+			}
+			return &dynamodb.DescribeTableOutput{Table: &types.TableDescription{
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{
+					IndexName:   aws.String(RecurringIDIndexName),
+					IndexStatus: types.IndexStatusActive,
+				}},
+				TableStatus: status,
+			}}, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations", WithTablePollInterval(time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = tracker.EnsureDonationTable(context.Background())
+
+	require.NoError(t, err)
+	// Two polls while creating, one once active, and one after each of the version 2 to 4 migrations.
+	require.Equal(t, 6, calls)
+}
+
+func TestDonationTracker_TrackAndLookup(t *testing.T) {
+	t.Parallel()
+
+	items := map[string]map[string]types.AttributeValue{}
+	client := &mockDynamoDBClient{
+		getItemFunc: func(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			key := params.Key[attrDonationID].(*types.AttributeValueMemberS).Value
+			return &dynamodb.GetItemOutput{Item: items[key]}, nil
+		},
+		putItemFunc: func(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			key := params.Item[attrDonationID].(*types.AttributeValueMemberS).Value
+			items[key] = params.Item
+			return &dynamodb.PutItemOutput{}, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	record := DonationRecord{
+		Amount:        "25.00",
+		ConstituentID: "const-1",
+		CreatedAt:     time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Currency:      "GBP",
+		DonationID:    "don_1",
+		GiftID:        "gift-1",
+		GiftType:      "Donation",
+		SupporterID:   "sup_1",
+		TrackedAt:     time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, tracker.Track(context.Background(), record))
+
+	got, err := tracker.Lookup(context.Background(), "don_1")
+	require.NoError(t, err)
+	require.Equal(t, &record, got)
+	require.NotContains(t, items["don_1"], attrRecurringID)
+	require.Equal(t, &types.AttributeValueMemberN{Value: "4"}, items["don_1"][attrSchemaVersion])
+	require.Equal(t, stringValue("2024-01"), items["don_1"][attrCreatedMonth])
+
+	require.NotContains(t, items["don_1"], attrExcludedAt)
+
+	record.ExcludedAt = time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, tracker.Track(context.Background(), record))
+
+	got, err = tracker.Lookup(context.Background(), "don_1")
+	require.NoError(t, err)
+	require.Equal(t, &record, got)
+
+	missing, err := tracker.Lookup(context.Background(), "don_unknown")
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
+
+func TestDonationTracker_TrackWithRetention(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	trackedAt := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		record    DonationRecord
+		retention time.Duration
+		want      types.AttributeValue
+	}{
+		"no retention": {
+			record: DonationRecord{CreatedAt: createdAt},
+		},
+		"expires after donation": {
+			record:    DonationRecord{CreatedAt: createdAt},
+			retention: 24 * time.Hour,
+			want:      &types.AttributeValueMemberN{Value: "1705401000"},
+		},
+		"expires after tracking without donation time": {
+			record:    DonationRecord{},
+			retention: 24 * time.Hour,
+			want:      &types.AttributeValueMemberN{Value: "1705482000"},
+		},
+		"keeps existing expiry": {
+			record:    DonationRecord{CreatedAt: createdAt, ExpiresAt: expiresAt},
+			retention: 24 * time.Hour,
+			want:      &types.AttributeValueMemberN{Value: "1735689600"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var item map[string]types.AttributeValue
+			client := &mockDynamoDBClient{
+				putItemFunc: func(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+					item = params.Item
+					return &dynamodb.PutItemOutput{}, nil
+				},
+			}
+
+			tracker, err := NewDonationTracker(client, "giftbridge-donations", WithRetention(tc.retention))
+			require.NoError(t, err)
+
+			record := tc.record
+			record.DonationID = "don_1"
+			record.GiftID = "gift-1"
+			record.TrackedAt = trackedAt
+			require.NoError(t, tracker.Track(context.Background(), record))
+
+			require.Equal(t, tc.want, item[attrExpiresAt])
+			if tc.want != nil {
+				got, err := recordFromItem(item)
+				require.NoError(t, err)
+				require.Equal(t, tc.want.(*types.AttributeValueMemberN).Value, strconv.FormatInt(got.ExpiresAt.Unix(), 10))
+			}
+		})
+	}
+}
+
+func TestDonationTracker_TrackErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg    string
+		record    DonationRecord
+		recurring bool
+	}{
+		"missing donation ID": {
+			record: DonationRecord{GiftID: "gift-1"},
+			errMsg: "donation ID is required",
+		},
+		"missing gift ID": {
+			record: DonationRecord{DonationID: "don_1"},
+			errMsg: "gift ID is required",
+		},
+		"recurring without recurring ID": {
+			record:    DonationRecord{DonationID: "don_1", GiftID: "gift-1"},
+			recurring: true,
+			errMsg:    "recurring ID is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker, err := NewDonationTracker(&mockDynamoDBClient{}, "giftbridge-donations")
+			require.NoError(t, err)
+
+			if tc.recurring {
+				err = tracker.TrackRecurring(context.Background(), tc.record)
+			} else {
+				err = tracker.Track(context.Background(), tc.record)
+			}
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestDonationTracker_TrackConditional(t *testing.T) {
+	t.Parallel()
+
+	items := map[string]map[string]types.AttributeValue{}
+	client := &mockDynamoDBClient{
+		putItemFunc: func(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			key := params.Item[attrDonationID].(*types.AttributeValueMemberS).Value
+			if !conditionHolds(items[key], params.ExpressionAttributeValues) {
+				require.Equal(t,
+					types.ReturnValuesOnConditionCheckFailureAllOld, params.ReturnValuesOnConditionCheckFailure)
+				return nil, &types.ConditionalCheckFailedException{Item: items[key]}
+			}
+			items[key] = params.Item
+			return &dynamodb.PutItemOutput{}, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	record := DonationRecord{DonationID: "don_1", GiftID: "gift-1", RecurringID: "rec_1"}
+	require.NoError(t, tracker.Track(ctx, record))
+
+	// Replays of the same gift succeed.
+	require.NoError(t, tracker.TrackRecurring(ctx, record))
+
+	other := record
+	other.GiftID = "gift-2"
+	for _, track := range []func(context.Context, DonationRecord) error{tracker.Track, tracker.TrackRecurring} {
+		err = track(ctx, other)
+		require.True(t, IsAlreadyTracked(err))
+		require.Equal(t, &AlreadyTrackedError{DonationID: "don_1", GiftID: "gift-1"}, err)
+		require.EqualError(t, err, "donation don_1 is already tracked with gift gift-1")
+	}
+
+	err = tracker.ReplaceGift(ctx, other, "gift-3")
+	require.Equal(t, &AlreadyTrackedError{DonationID: "don_1", GiftID: "gift-1"}, err)
+
+	require.EqualError(t, tracker.ReplaceGift(ctx, other, ""), "previous gift ID is required for donation don_1")
+	require.Equal(t, stringValue("gift-1"), items["don_1"][attrGiftID])
+
+	require.NoError(t, tracker.ReplaceGift(ctx, other, "gift-1"))
+	require.Equal(t, stringValue("gift-2"), items["don_1"][attrGiftID])
+
+	// Replacing again, as a retried run would, is harmless.
+	require.NoError(t, tracker.ReplaceGift(ctx, other, "gift-1"))
+
+	require.False(t, IsAlreadyTracked(errors.New("throttled")))
+}
+
+func TestDonationTracker_TrackBatch(t *testing.T) {
+	t.Parallel()
+
+	records := make([]DonationRecord, 30)
+	for i := range records {
+		records[i] = DonationRecord{DonationID: "don_" + strconv.Itoa(i), GiftID: "gift-" + strconv.Itoa(i)}
+	}
+	records[3].RecurringID = "rec_1"
+
+	tests := map[string]struct {
+		// existing holds gifts already tracked, by donation ID.
+		existing map[string]string
+		// unprocessed is how many items each batch write leaves unprocessed, until it runs out.
+		unprocessed []int
+		wantErr     string
+		wantPuts    []string
+		wantTracked []*AlreadyTrackedError
+		wantWrites  []int
+		wantWritten int
+	}{
+		"writes in chunks": {
+			wantWrites:  []int{25, 5},
+			wantWritten: 30,
+		},
+		"puts tracked donations conditionally": {
+			existing:   map[string]string{"don_1": "gift-other", "don_2": "gift-2", "don_27": "gift-other"},
+			wantPuts:   []string{"don_1", "don_2", "don_27"},
+			wantWrites: []int{23, 4},
+			wantTracked: []*AlreadyTrackedError{
+				{DonationID: "don_1", GiftID: "gift-other"},
+				{DonationID: "don_27", GiftID: "gift-other"},
+			},
+			wantWritten: 28,
+		},
+		"retries unprocessed items": {
+			unprocessed: []int{10, 4},
+			wantWrites:  []int{25, 10, 4, 5},
+			wantWritten: 30,
+		},
+		"keeps writing after a failed chunk": {
+			unprocessed: []int{10, 9, 8, 7, 6},
+			wantErr:     "tracking donations 1 to 25 of 30: 6 donations still unprocessed after 5 attempts",
+			wantWrites:  []int{25, 10, 9, 8, 7, 5},
+			wantWritten: 24,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			items := make(map[string]map[string]types.AttributeValue)
+			for donationID, giftID := range tc.existing {
+				items[donationID] = map[string]types.AttributeValue{
+					attrDonationID: stringValue(donationID),
+					attrGiftID:     stringValue(giftID),
+				}
+			}
+
+			var puts []string
+			var writes []int
+			written := make(map[string]DonationRecord)
+			client := &mockDynamoDBClient{
+				batchGetFunc: func(_ context.Context, params *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+					request := params.RequestItems["giftbridge-donations"]
+					require.True(t, aws.ToBool(request.ConsistentRead))
+					var found []map[string]types.AttributeValue
+					for _, key := range request.Keys {
+						if item, ok := items[stringAttr(key, attrDonationID)]; ok {
+							found = append(found, item)
+						}
+					}
+					return &dynamodb.BatchGetItemOutput{
+						Responses: map[string][]map[string]types.AttributeValue{"giftbridge-donations": found},
+					}, nil
+				},
+				batchWriteFunc: func(_ context.Context, params *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+					requests := params.RequestItems["giftbridge-donations"]
+					writes = append(writes, len(requests))
+
+					left := 0
+					if len(writes) <= len(tc.unprocessed) {
+						left = tc.unprocessed[len(writes)-1]
+					}
+					for _, request := range requests[:len(requests)-left] {
+						record, err := recordFromItem(request.PutRequest.Item)
+						require.NoError(t, err)
+						written[record.DonationID] = *record
+					}
+
+					output := &dynamodb.BatchWriteItemOutput{}
+					if left > 0 {
+						output.UnprocessedItems = map[string][]types.WriteRequest{
+							"giftbridge-donations": requests[len(requests)-left:],
+						}
+					}
+					return output, nil
+				},
+				putItemFunc: func(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+					key := stringAttr(params.Item, attrDonationID)
+					puts = append(puts, key)
+					if !conditionHolds(items[key], params.ExpressionAttributeValues) {
+						return nil, &types.ConditionalCheckFailedException{Item: items[key]}
+					}
+					record, err := recordFromItem(params.Item)
+					require.NoError(t, err)
+					written[key] = *record
+					return &dynamodb.PutItemOutput{}, nil
+				},
+			}
+
+			tracker, err := NewDonationTracker(client, "giftbridge-donations", WithBatchRetryDelay(time.Millisecond))
+			require.NoError(t, err)
+
+			err = tracker.TrackBatch(context.Background(), records)
+			require.Equal(t, tc.wantWrites, writes)
+			require.Equal(t, tc.wantPuts, puts)
+			require.Len(t, written, tc.wantWritten)
+			require.Equal(t, "rec_1", written["don_3"].RecurringID)
+			require.False(t, written["don_29"].TrackedAt.IsZero())
+
+			if tc.wantErr == "" && len(tc.wantTracked) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var batchErr *BatchTrackError
+			require.ErrorAs(t, err, &batchErr)
+			require.Equal(t, tc.wantTracked, batchErr.AlreadyTracked)
+			if tc.wantErr != "" {
+				require.Len(t, batchErr.Failed, 1)
+				require.EqualError(t, batchErr.Failed[0], tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDonationTracker_TrackBatchErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		batchGetErr   error
+		batchWriteErr error
+		records       []DonationRecord
+		wantErr       string
+	}{
+		"invalid record": {
+			records: []DonationRecord{{DonationID: "don_1", GiftID: "gift-1"}, {DonationID: "don_2"}},
+			wantErr: "gift ID is required for donation don_2",
+		},
+		"batch read error": {
+			batchGetErr: errors.New("access denied"),
+			records:     []DonationRecord{{DonationID: "don_1", GiftID: "gift-1"}},
+			wantErr:     "tracking donations 1 to 1 of 1: batch reading from DynamoDB: access denied",
+		},
+		"batch write error": {
+			batchWriteErr: errors.New("throttled"),
+			records:       []DonationRecord{{DonationID: "don_1", GiftID: "gift-1"}},
+			wantErr:       "tracking donations 1 to 1 of 1: batch writing to DynamoDB: throttled",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			client := &mockDynamoDBClient{
+				batchGetFunc: func(_ context.Context, _ *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+					calls++
+					if tc.batchGetErr != nil {
+						return nil, tc.batchGetErr
+					}
+					return &dynamodb.BatchGetItemOutput{}, nil
+				},
+				batchWriteFunc: func(_ context.Context, _ *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+					calls++
+					return nil, tc.batchWriteErr
+				},
+			}
+
+			tracker, err := NewDonationTracker(client, "giftbridge-donations")
+			require.NoError(t, err)
+
+			err = tracker.TrackBatch(context.Background(), tc.records)
+			require.EqualError(t, err, tc.wantErr)
+			if tc.batchGetErr == nil && tc.batchWriteErr == nil {
+				require.Zero(t, calls, "no records should be written when any is invalid")
+			}
+		})
+	}
+}
+
+// conditionHolds reports whether a conditional put with values may write over existing,
+// which it may when existing is missing or tracked with one of the gift IDs in values.
+func conditionHolds(existing map[string]types.AttributeValue, values map[string]types.AttributeValue) bool {
+	if existing == nil {
+		return true
+	}
+	for _, value := range values {
+		if stringAttr(existing, attrGiftID) == value.(*types.AttributeValueMemberS).Value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDonationTracker_RecurringDonations(t *testing.T) {
+	t.Parallel()
+
+	pages := []*dynamodb.QueryOutput{
+		{
+			Items: []map[string]types.AttributeValue{
+				{
+					attrDonationID:  stringValue("don_1"),
+					attrFrequency:   stringValue("monthly"),
+					attrGiftID:      stringValue("gift-1"),
+					attrRecurringID: stringValue("rec_1"),
+				},
+			},
+			LastEvaluatedKey: map[string]types.AttributeValue{attrDonationID: stringValue("don_1")},
+		},
+		{
+			Items: []map[string]types.AttributeValue{
+				{attrDonationID: stringValue("don_2"), attrGiftID: stringValue("gift-2"), attrRecurringID: stringValue("rec_1")},
+			},
+		},
+	}
+
+	var indexNames []string
+	client := &mockDynamoDBClient{
+		queryFunc: func(_ context.Context, params *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			indexNames = append(indexNames, aws.ToString(params.IndexName))
+			page := pages[0]
+			pages = pages[1:]
+			return page, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	records, err := tracker.RecurringDonations(context.Background(), "rec_1")
+
+	require.NoError(t, err)
+	require.Equal(t, []DonationRecord{
+		{DonationID: "don_1", Frequency: "monthly", GiftID: "gift-1", RecurringID: "rec_1"},
+		{DonationID: "don_2", GiftID: "gift-2", RecurringID: "rec_1"},
+	}, records)
+	require.Equal(t, []string{RecurringIDIndexName, RecurringIDIndexName}, indexNames)
+}
+
+func TestDonationTracker_SupporterDonationsAndForget(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var (
+		filter string
+		puts   []*dynamodb.PutItemInput
+	)
+	client := &mockDynamoDBClient{
+		scanFunc: func(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+			filter = aws.ToString(params.FilterExpression)
+			return &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{{
+				attrConstituentID: stringValue("const-1"),
+				attrDonationID:    stringValue("don_1"),
+				attrGiftID:        stringValue("gift-1"),
+				attrSupporterID:   stringValue("sup_1"),
+				attrTrackedAt:     stringValue("2025-03-01T10:00:00Z"),
+			}}}, nil
+		},
+		putItemFunc: func(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			puts = append(puts, params)
+			if len(puts) > 1 {
+				return nil, &types.ConditionalCheckFailedException{}
+			}
+			return &dynamodb.PutItemOutput{}, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	records, err := tracker.SupporterDonations(ctx, "sup_1")
+	require.NoError(t, err)
+	require.Equal(t, "#supporter_id = :supporter_id", filter)
+	require.Len(t, records, 1)
+	require.Equal(t, "const-1", records[0].ConstituentID)
+
+	require.NoError(t, tracker.Forget(ctx, records[0]))
+	require.Len(t, puts, 1)
+	require.Equal(t, stringValue("sup_1"), puts[0].ExpressionAttributeValues[":supporter_id"])
+	require.NotContains(t, puts[0].Item, attrSupporterID)
+	require.NotContains(t, puts[0].Item, attrConstituentID)
+	require.Equal(t, stringValue("gift-1"), puts[0].Item[attrGiftID])
+
+	// A record already forgotten, or since tracked for someone else, is left unchanged.
+	require.NoError(t, tracker.Forget(ctx, records[0]))
+
+	records[0].SupporterID = ""
+	require.EqualError(t, tracker.Forget(ctx, records[0]), "supporter ID is required for donation don_1")
+}
+
+func TestDonationTracker_DonationsBetween(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string][]*dynamodb.QueryOutput{
+		"2024-03": {
+			{
+				Items: []map[string]types.AttributeValue{
+					{
+						attrCreatedAt:  stringValue("2024-03-01T09:00:00Z"),
+						attrDonationID: stringValue("don_1"),
+						attrGiftID:     stringValue("gift-1"),
+					},
+				},
+				LastEvaluatedKey: map[string]types.AttributeValue{attrDonationID: stringValue("don_1")},
+			},
+			{
+				Items: []map[string]types.AttributeValue{
+					{
+						attrCreatedAt:  stringValue("2024-03-30T18:00:00Z"),
+						attrDonationID: stringValue("don_2"),
+						attrGiftID:     stringValue("gift-2"),
+					},
+				},
+			},
+		},
+	}
+
+	var queries []*dynamodb.QueryInput
+	client := &mockDynamoDBClient{
+		queryFunc: func(
+			_ context.Context,
+			params *dynamodb.QueryInput,
+			_ ...func(*dynamodb.Options),
+		) (*dynamodb.QueryOutput, error) {
+			values := make(map[string]types.AttributeValue, len(params.ExpressionAttributeValues))
+			for k, v := range params.ExpressionAttributeValues {
+				values[k] = v
+			}
+			queries = append(queries, &dynamodb.QueryInput{
+				ExclusiveStartKey:         params.ExclusiveStartKey,
+				ExpressionAttributeValues: values,
+				IndexName:                 params.IndexName,
+			})
+
+			month := params.ExpressionAttributeValues[":month"].(*types.AttributeValueMemberS).Value
+			if len(pages[month]) == 0 {
+				return &dynamodb.QueryOutput{}, nil
+			}
+			page := pages[month][0]
+			pages[month] = pages[month][1:]
+			return page, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	from := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	records, err := tracker.DonationsBetween(context.Background(), from, to)
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "don_1", records[0].DonationID)
+	require.Equal(t, time.Date(2024, 3, 30, 18, 0, 0, 0, time.UTC), records[1].CreatedAt)
+
+	// February, then two pages of March; the exclusive end excludes April.
+	require.Len(t, queries, 3)
+	var months []string
+	for _, query := range queries {
+		require.Equal(t, CreatedMonthIndexName, aws.ToString(query.IndexName))
+		require.Equal(t, stringValue("2024-02-15T00:00:00Z"), query.ExpressionAttributeValues[":first"])
+		require.Equal(t, stringValue("2024-03-31T23:59:59Z"), query.ExpressionAttributeValues[":last"])
+		months = append(months, query.ExpressionAttributeValues[":month"].(*types.AttributeValueMemberS).Value)
+	}
+	require.Equal(t, []string{"2024-02", "2024-03", "2024-03"}, months)
+	require.Nil(t, queries[1].ExclusiveStartKey)
+	require.Equal(t, stringValue("don_1"), queries[2].ExclusiveStartKey[attrDonationID])
+}
+
+func TestDonationTracker_DonationsBetweenEmptyWindow(t *testing.T) {
+	t.Parallel()
+
+	client := &mockDynamoDBClient{
+		queryFunc: func(_ context.Context, _ *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			return nil, errors.New("unexpected query")
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	records, err := tracker.DonationsBetween(context.Background(), from, from.Add(time.Millisecond))
+
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestDonationTracker_DonationsBetweenScansLongWindows(t *testing.T) {
+	t.Parallel()
+
+	var scans []*dynamodb.ScanInput
+	client := &mockDynamoDBClient{
+		queryFunc: func(_ context.Context, _ *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			return nil, errors.New("unexpected query")
+		},
+		scanFunc: func(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+			scans = append(scans, params)
+			return &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{
+				{
+					attrCreatedAt:  stringValue("2024-03-01T09:00:00Z"),
+					attrDonationID: stringValue("don_1"),
+					attrGiftID:     stringValue("gift-1"),
+				},
+			}}, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	records, err := tracker.DonationsBetween(context.Background(), time.Time{}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Len(t, scans, 1)
+	require.Nil(t, scans[0].IndexName)
+	require.Equal(t, stringValue("0001-01-01T00:00:00Z"), scans[0].ExpressionAttributeValues[":first"])
+	require.Equal(t, stringValue("2024-12-31T23:59:59Z"), scans[0].ExpressionAttributeValues[":last"])
+}
+
+func TestDonationTracker_DonationCounts(t *testing.T) {
+	t.Parallel()
+
+	items := []map[string]types.AttributeValue{
+		{attrCreatedAt: stringValue("2024-03-01T09:00:00Z"), attrGiftType: stringValue("Donation")},
+		{attrCreatedAt: stringValue("2024-03-01T12:00:00Z")},
+		{
+			attrCreatedAt:   stringValue("2024-03-02T09:00:00Z"),
+			attrGiftType:    stringValue("RecurringGift"),
+			attrRecurringID: stringValue("rec_1"),
+		},
+		{
+			attrCreatedAt:   stringValue("2024-03-02T10:00:00Z"),
+			attrGiftType:    stringValue("RecurringGiftPayment"),
+			attrRecurringID: stringValue("rec_1"),
+		},
+	}
+
+	var projections []string
+	client := &mockDynamoDBClient{
+		queryFunc: func(_ context.Context, params *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			projections = append(projections, aws.ToString(params.ProjectionExpression))
+			return &dynamodb.QueryOutput{Items: items}, nil
+		},
+	}
+
+	tracker, err := NewDonationTracker(client, "giftbridge-donations")
+	require.NoError(t, err)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	counts, err := tracker.DonationCounts(context.Background(), from, from.AddDate(0, 1, 0))
+
+	require.NoError(t, err)
+	require.Equal(t, &DonationCounts{
+		ByDay:         map[string]int{"2024-03-01": 2, "2024-03-02": 2},
+		ByGiftType:    map[string]int{"": 1, "Donation": 1, "RecurringGift": 1, "RecurringGiftPayment": 1},
+		ByRecurringID: map[string]int{"rec_1": 2},
+		Total:         4,
+	}, counts)
+	require.Equal(t, []string{"#created, #gift_type, #recurring_id"}, projections)
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// firestoreURL is the base URL of the Firestore API.
+	firestoreURL = "https://firestore.googleapis.com"
+
+	// googleSecretManagerURL is the base URL of the Google Secret Manager API.
+	googleSecretManagerURL = "https://secretmanager.googleapis.com"
+)
+
+// GoogleOption configures a Google Cloud store.
+type GoogleOption func(*googleOptions)
+
+// googleOptions holds the settings shared by the Google Cloud stores.
+type googleOptions struct {
+	// baseURL is the base URL of the store's API.
+	baseURL string
+
+	// client sends requests to the API.
+	client *http.Client
+}
+
+// WithGoogleBaseURL sets the base URL of the store's API, such as the address of the Firestore emulator.
+func WithGoogleBaseURL(baseURL string) GoogleOption {
+	return func(o *googleOptions) {
+		if baseURL != "" {
+			o.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithGoogleHTTPClient sets the HTTP client used to reach the store's API.
+func WithGoogleHTTPClient(client *http.Client) GoogleOption {
+	return func(o *googleOptions) {
+		if client != nil {
+			o.client = client
+		}
+	}
+}
+
+// newGoogleOptions applies opts over the defaults for the API at baseURL.
+func newGoogleOptions(baseURL string, opts []GoogleOption) googleOptions {
+	o := googleOptions{baseURL: baseURL, client: defaultCloudClient}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// GoogleSecretTokenStore manages OAuth refresh tokens in Google Secret Manager, for deployments on Google Cloud.
+type GoogleSecretTokenStore struct {
+	googleOptions
+
+	// mu guards readVersion.
+	mu sync.Mutex
+
+	// readVersion is the name of the secret version the refresh token was last read from, destroyed once a newer
+	// token is saved so versions do not accumulate.
+	readVersion string
+
+	// secret is the resource name of the secret, as projects/{project}/secrets/{secret}.
+	secret string
+
+	// tokens authorizes requests to Secret Manager.
+	tokens AccessTokenSource
+}
+
+// NewGoogleSecretTokenStore creates a token store keeping the refresh token in the Secret Manager secret with the
+// given ID. The service account needs the Secret Manager Secret Accessor and Secret Version Manager roles on it.
+func NewGoogleSecretTokenStore(
+	tokens AccessTokenSource,
+	project string,
+	secretID string,
+	opts ...GoogleOption,
+) (*GoogleSecretTokenStore, error) {
+	if tokens == nil {
+		return nil, errors.New("access token source is required")
+	}
+	if project == "" {
+		return nil, errors.New("project is required")
+	}
+	if secretID == "" {
+		return nil, errors.New("secret ID is required")
+	}
+
+	return &GoogleSecretTokenStore{
+		googleOptions: newGoogleOptions(googleSecretManagerURL, opts),
+		secret:        "projects/" + url.PathEscape(project) + "/secrets/" + url.PathEscape(secretID),
+		tokens:        tokens,
+	}, nil
+}
+
+// RefreshToken returns the current refresh token from the secret's latest version.
+func (g *GoogleSecretTokenStore) RefreshToken(ctx context.Context) (string, error) {
+	var resp struct {
+		Name    string `json:"name"`
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	err := g.request(http.MethodGet, g.secret+"/versions/latest:access", nil).send(ctx, &resp)
+	if errors.Is(err, errDocumentNotFound) {
+		return "", fmt.Errorf("secret %s not found or has no enabled version", g.secret)
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting secret from Secret Manager: %w", err)
+	}
+
+	token, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decoding secret payload: %w", err)
+	}
+	if len(token) == 0 {
+		return "", errors.New("secret has no value")
+	}
+
+	g.mu.Lock()
+	g.readVersion = resp.Name
+	g.mu.Unlock()
+
+	return string(token), nil
+}
+
+// SaveRefreshToken stores a new refresh token as a new version of the secret, then destroys the version it replaced.
+func (g *GoogleSecretTokenStore) SaveRefreshToken(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.New("token cannot be empty")
+	}
+
+	payload := map[string]any{
+		"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte(token))},
+	}
+	var added struct {
+		Name string `json:"name"`
+	}
+	if err := g.request(http.MethodPost, g.secret+":addVersion", payload).send(ctx, &added); err != nil {
+		return fmt.Errorf("adding secret version to Secret Manager: %w", err)
+	}
+
+	g.mu.Lock()
+	previous := g.readVersion
+	g.readVersion = added.Name
+	g.mu.Unlock()
+
+	// The new token is saved, so a version left behind costs storage but does not affect the sync.
+	if previous != "" && previous != added.Name {
+		if err := g.request(http.MethodPost, previous+":destroy", map[string]any{}).send(ctx, nil); err != nil {
+			slog.WarnContext(ctx, "failed to destroy previous refresh token version",
+				"version", previous, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// request returns a request to the Secret Manager API for the resource at path.
+func (g *GoogleSecretTokenStore) request(method string, path string, payload any) cloudRequest {
+	return cloudRequest{
+		client:  g.client,
+		method:  method,
+		payload: payload,
+		tokens:  g.tokens,
+		url:     g.baseURL + "/v1/" + path,
+	}
+}
+
+// FirestoreStateStore keeps the sync state in the string fields of a Firestore document, for deployments on
+// Google Cloud. The service account needs the Cloud Datastore User role.
+type FirestoreStateStore struct {
+	fieldState
+}
+
+// NewFirestoreStateStore creates a state store keeping the sync state in the document at documentPath, given as
+// collection/document, in the project's default Firestore database. The document is created on the first write.
+func NewFirestoreStateStore(
+	tokens AccessTokenSource,
+	project string,
+	documentPath string,
+	opts ...GoogleOption,
+) (*FirestoreStateStore, error) {
+	if tokens == nil {
+		return nil, errors.New("access token source is required")
+	}
+	if project == "" {
+		return nil, errors.New("project is required")
+	}
+	collection, document, ok := strings.Cut(strings.Trim(documentPath, "/"), "/")
+	if !ok || collection == "" || document == "" || strings.Contains(document, "/") {
+		return nil, errors.New("document path must be a collection and document ID")
+	}
+
+	return &FirestoreStateStore{
+		fieldState: fieldState{
+			document: &firestoreDocument{
+				googleOptions: newGoogleOptions(firestoreURL, opts),
+				name: "projects/" + url.PathEscape(project) + "/databases/(default)/documents/" +
+					url.PathEscape(collection) + "/" + url.PathEscape(document),
+				tokens: tokens,
+			},
+		},
+	}, nil
+}
+
+// firestoreDocument reads and writes the fields of a Firestore document through the Firestore REST API.
+type firestoreDocument struct {
+	googleOptions
+
+	// name is the resource name of the document.
+	name string
+
+	// tokens authorizes requests to Firestore.
+	tokens AccessTokenSource
+}
+
+// firestoreFields are the fields of a Firestore document, holding string values.
+type firestoreFields map[string]struct {
+	StringValue string `json:"stringValue"`
+}
+
+func (f *firestoreDocument) field(ctx context.Context, name string) (string, error) {
+	var doc struct {
+		Fields firestoreFields `json:"fields"`
+	}
+	req := f.request(http.MethodGet, url.Values{"mask.fieldPaths": {name}}, nil)
+	if err := req.send(ctx, &doc); err != nil && !errors.Is(err, errDocumentNotFound) {
+		return "", fmt.Errorf("getting %s from Firestore: %w", name, err)
+	}
+
+	return doc.Fields[name].StringValue, nil
+}
+
+func (f *firestoreDocument) setField(ctx context.Context, name string, value string) error {
+	payload := map[string]any{
+		"fields": map[string]any{name: map[string]string{"stringValue": value}},
+	}
+	req := f.request(http.MethodPatch, url.Values{"updateMask.fieldPaths": {name}}, payload)
+	if err := req.send(ctx, nil); err != nil {
+		return fmt.Errorf("putting %s to Firestore: %w", name, err)
+	}
+
+	return nil
+}
+
+// request returns a request to the Firestore API for the document.
+func (f *firestoreDocument) request(method string, query url.Values, payload any) cloudRequest {
+	return cloudRequest{
+		client:  f.client,
+		method:  method,
+		payload: payload,
+		tokens:  f.tokens,
+		url:     f.baseURL + "/v1/" + f.name + "?" + query.Encode(),
+	}
+}
@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretManager serves the Secret Manager versions of one secret, as Google Secret Manager would.
+type fakeSecretManager struct {
+	mu sync.Mutex
+
+	// destroyed lists the versions destroyed, by number.
+	destroyed []int
+
+	// versions holds the payload of each version, numbered from 1.
+	versions []string
+}
+
+func (f *fakeSecretManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !requireBearer(w, r) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const secret = "/v1/projects/charity/secrets/blackbaud-token"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == secret+"/versions/latest:access":
+		if len(f.versions) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Secret Version not found."}}`))
+			return
+		}
+		latest := f.versions[len(f.versions)-1]
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"name":    fmt.Sprintf("projects/123/secrets/blackbaud-token/versions/%d", len(f.versions)),
+			"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte(latest))},
+		})
+	case r.Method == http.MethodPost && r.URL.Path == secret+":addVersion":
+		var body struct {
+			Payload struct {
+				Data []byte `json:"data"`
+			} `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.versions = append(f.versions, string(body.Payload.Data))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"name": fmt.Sprintf("projects/123/secrets/blackbaud-token/versions/%d", len(f.versions)),
+		})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":destroy"):
+		var version int
+		_, err := fmt.Sscanf(r.URL.Path, "/v1/projects/123/secrets/blackbaud-token/versions/%d:destroy", &version)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.destroyed = append(f.destroyed, version)
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestNewGoogleSecretTokenStore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg   string
+		project  string
+		secretID string
+		tokens   AccessTokenSource
+	}{
+		"valid": {
+			project:  "charity",
+			secretID: "blackbaud-token",
+			tokens:   staticTokens("test-token"),
+		},
+		"missing token source": {
+			errMsg:   "access token source is required",
+			project:  "charity",
+			secretID: "blackbaud-token",
+		},
+		"missing project": {
+			errMsg:   "project is required",
+			secretID: "blackbaud-token",
+			tokens:   staticTokens("test-token"),
+		},
+		"missing secret ID": {
+			errMsg:  "secret ID is required",
+			project: "charity",
+			tokens:  staticTokens("test-token"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewGoogleSecretTokenStore(tc.tokens, tc.project, tc.secretID)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, store)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, store)
+		})
+	}
+}
+
+func TestGoogleSecretTokenStore(t *testing.T) {
+	t.Parallel()
+
+	secretManager := &fakeSecretManager{}
+	server := httptest.NewServer(secretManager)
+	t.Cleanup(server.Close)
+
+	store, err := NewGoogleSecretTokenStore(staticTokens("test-token"), "charity", "blackbaud-token",
+		WithGoogleBaseURL(server.URL), WithGoogleHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	_, err = store.RefreshToken(context.Background())
+	require.ErrorContains(t, err, "secret projects/charity/secrets/blackbaud-token not found or has no enabled version")
+
+	secretManager.mu.Lock()
+	secretManager.versions = []string{"first-token"}
+	secretManager.mu.Unlock()
+	token, err := store.RefreshToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "first-token", token)
+
+	// Saving replaces the version read, so versions do not accumulate with each rotation.
+	require.ErrorContains(t, store.SaveRefreshToken(context.Background(), ""), "token cannot be empty")
+	require.NoError(t, store.SaveRefreshToken(context.Background(), "second-token"))
+	require.NoError(t, store.SaveRefreshToken(context.Background(), "third-token"))
+	secretManager.mu.Lock()
+	require.Equal(t, []int{1, 2}, secretManager.destroyed)
+	secretManager.mu.Unlock()
+
+	token, err = store.RefreshToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "third-token", token)
+
+	unauthorized, err := NewGoogleSecretTokenStore(staticTokens("expired"), "charity", "blackbaud-token",
+		WithGoogleBaseURL(server.URL), WithGoogleHTTPClient(server.Client()))
+	require.NoError(t, err)
+	_, err = unauthorized.RefreshToken(context.Background())
+	require.ErrorContains(t, err,
+		"getting secret from Secret Manager: unexpected status 401: Request had invalid authentication credentials.")
+}
+
+// fakeFirestore serves the string fields of Firestore documents, as the Firestore REST API would.
+type fakeFirestore struct {
+	mu sync.Mutex
+
+	// documents holds the fields of each document, by resource name.
+	documents map[string]map[string]string
+}
+
+func (f *fakeFirestore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !requireBearer(w, r) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/v1/")
+	switch r.Method {
+	case http.MethodGet:
+		fields, ok := f.documents[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Document not found."}}`))
+			return
+		}
+		doc := map[string]any{}
+		for _, path := range r.URL.Query()["mask.fieldPaths"] {
+			if value, ok := fields[path]; ok {
+				doc[path] = map[string]string{"stringValue": value}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": name, "fields": doc})
+	case http.MethodPatch:
+		var body struct {
+			Fields map[string]struct {
+				StringValue string `json:"stringValue"`
+			} `json:"fields"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if f.documents[name] == nil {
+			f.documents[name] = map[string]string{}
+		}
+		for _, path := range r.URL.Query()["updateMask.fieldPaths"] {
+			f.documents[name][path] = body.Fields[path].StringValue
+		}
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestNewFirestoreStateStore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		documentPath string
+		errMsg       string
+		project      string
+		tokens       AccessTokenSource
+	}{
+		"valid": {
+			documentPath: "giftbridge/sync-state",
+			project:      "charity",
+			tokens:       staticTokens("test-token"),
+		},
+		"missing token source": {
+			documentPath: "giftbridge/sync-state",
+			errMsg:       "access token source is required",
+			project:      "charity",
+		},
+		"missing project": {
+			documentPath: "giftbridge/sync-state",
+			errMsg:       "project is required",
+			tokens:       staticTokens("test-token"),
+		},
+		"collection without document": {
+			documentPath: "giftbridge",
+			errMsg:       "document path must be a collection and document ID",
+			project:      "charity",
+			tokens:       staticTokens("test-token"),
+		},
+		"nested document": {
+			documentPath: "giftbridge/state/runs/latest",
+			errMsg:       "document path must be a collection and document ID",
+			project:      "charity",
+			tokens:       staticTokens("test-token"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewFirestoreStateStore(tc.tokens, tc.project, tc.documentPath)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, store)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, store)
+		})
+	}
+}
+
+func TestFirestoreStateStore(t *testing.T) {
+	t.Parallel()
+
+	firestore := &fakeFirestore{documents: map[string]map[string]string{}}
+	server := httptest.NewServer(firestore)
+	t.Cleanup(server.Close)
+
+	store, err := NewFirestoreStateStore(staticTokens("test-token"), "charity", "giftbridge/sync-state",
+		WithGoogleBaseURL(server.URL), WithGoogleHTTPClient(server.Client()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Nothing is recorded before the document is first written.
+	lastSync, err := store.LastSyncTime(ctx)
+	require.NoError(t, err)
+	require.True(t, lastSync.IsZero())
+	pending, err := store.PendingDonationIDs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	fetchState, err := store.FetchState(ctx)
+	require.NoError(t, err)
+	require.Nil(t, fetchState)
+
+	syncedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SetLastSyncTime(ctx, syncedAt))
+	require.NoError(t, store.SetPendingDonationIDs(ctx, []string{"don_1", "don_2", "don_3"}))
+	require.NoError(t, store.RemovePendingDonationID(ctx, "don_2"))
+	require.NoError(t, store.SetFetchState(ctx, &FetchState{Cursor: "don_3", Since: syncedAt}))
+
+	firestore.mu.Lock()
+	require.Equal(t, map[string]string{
+		"FetchState":         `{"cursor":"don_3","since":"2025-03-01T12:00:00Z"}`,
+		"LastSyncTime":       "2025-03-01T12:00:00Z",
+		"PendingDonationIDs": "don_1,don_3",
+	}, firestore.documents["projects/charity/databases/(default)/documents/giftbridge/sync-state"])
+	firestore.mu.Unlock()
+
+	lastSync, err = store.LastSyncTime(ctx)
+	require.NoError(t, err)
+	require.Equal(t, syncedAt, lastSync)
+	pending, err = store.PendingDonationIDs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"don_1", "don_3"}, pending)
+	fetchState, err = store.FetchState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &FetchState{Cursor: "don_3", Since: syncedAt}, fetchState)
+
+	require.NoError(t, store.SetFetchState(ctx, nil))
+	fetchState, err = store.FetchState(ctx)
+	require.NoError(t, err)
+	require.Nil(t, fetchState)
+}
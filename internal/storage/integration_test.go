@@ -0,0 +1,220 @@
+//go:build integration
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests run against LocalStack (or DynamoDB Local for the tracker tests):
+//
+//	docker run --rm -p 4566:4566 localstack/localstack
+//	make test-integration
+//
+// Set LOCALSTACK_ENDPOINT to use an endpoint other than http://localhost:4566, and
+// DYNAMODB_ENDPOINT to run the tracker tests against a separate DynamoDB Local instance.
+const defaultLocalStackEndpoint = "http://localhost:4566"
+
+// integrationConfig returns an AWS config with static test credentials that never touches a real account.
+func integrationConfig() aws.Config {
+	return aws.Config{
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		Region:      "us-east-1",
+	}
+}
+
+// integrationEndpoint returns the value of envVar, falling back to LOCALSTACK_ENDPOINT and then the default.
+func integrationEndpoint(envVar string) string {
+	for _, key := range []string{envVar, "LOCALSTACK_ENDPOINT"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return defaultLocalStackEndpoint
+}
+
+// uniqueName returns a resource name that does not collide between test runs.
+func uniqueName(t *testing.T, prefix string) string {
+	t.Helper()
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+func newIntegrationDynamoDBClient() *dynamodb.Client {
+	return dynamodb.NewFromConfig(integrationConfig(), func(o *dynamodb.Options) {
+		o.BaseEndpoint = aws.String(integrationEndpoint("DYNAMODB_ENDPOINT"))
+	})
+}
+
+func newIntegrationSecretsManagerClient() *secretsmanager.Client {
+	return secretsmanager.NewFromConfig(integrationConfig(), func(o *secretsmanager.Options) {
+		o.BaseEndpoint = aws.String(integrationEndpoint("SECRETSMANAGER_ENDPOINT"))
+	})
+}
+
+func newIntegrationSSMClient() *ssm.Client {
+	return ssm.NewFromConfig(integrationConfig(), func(o *ssm.Options) {
+		o.BaseEndpoint = aws.String(integrationEndpoint("SSM_ENDPOINT"))
+	})
+}
+
+func TestIntegrationStateStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	prefix := "/" + uniqueName(t, "giftbridge") + "/"
+
+	store, err := NewStateStore(newIntegrationSSMClient(), prefix+"last-sync-time")
+	require.NoError(t, err)
+
+	lastSync, err := store.LastSyncTime(ctx)
+	require.NoError(t, err)
+	require.True(t, lastSync.IsZero())
+
+	want := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	require.NoError(t, store.SetLastSyncTime(ctx, want))
+
+	lastSync, err = store.LastSyncTime(ctx)
+	require.NoError(t, err)
+	require.True(t, want.Equal(lastSync))
+
+	require.NoError(t, store.SetPendingDonationIDs(ctx, []string{"don_1", "don_2", "don_3"}))
+	require.NoError(t, store.RemovePendingDonationID(ctx, "don_2"))
+
+	pending, err := store.PendingDonationIDs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"don_1", "don_3"}, pending)
+}
+
+func TestIntegrationTokenStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := newIntegrationSecretsManagerClient()
+
+	created, err := client.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+		Name:         aws.String(uniqueName(t, "giftbridge/blackbaud-refresh-token")),
+		SecretString: aws.String("initial-token"),
+	})
+	require.NoError(t, err)
+
+	store, err := NewTokenStore(client, aws.ToString(created.ARN))
+	require.NoError(t, err)
+
+	token, err := store.RefreshToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "initial-token", token)
+
+	require.NoError(t, store.SaveRefreshToken(ctx, "rotated-token"))
+
+	token, err = store.RefreshToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "rotated-token", token)
+}
+
+func TestIntegrationDonationTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tracker, err := NewDonationTracker(
+		newIntegrationDynamoDBClient(),
+		uniqueName(t, "giftbridge-donations"),
+		WithTablePollInterval(100*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	status, err := tracker.EnsureDonationTable(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Created)
+	require.Equal(t, DonationTableSchemaVersion, status.Version)
+
+	// A second call must find the table at the current version and change nothing.
+	status, err = tracker.EnsureDonationTable(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &DonationTableStatus{
+		PreviousVersion: DonationTableSchemaVersion,
+		Version:         DonationTableSchemaVersion,
+	}, status)
+
+	oneOff := DonationRecord{
+		Amount:        "25.00",
+		ConstituentID: "const-1",
+		CreatedAt:     time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Currency:      "GBP",
+		DonationID:    "don_1",
+		GiftID:        "gift-1",
+		GiftType:      "Donation",
+		SupporterID:   "sup_1",
+		TrackedAt:     time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, tracker.Track(ctx, oneOff))
+
+	// A different gift for the same donation is refused, leaving the record unchanged.
+	duplicate := oneOff
+	duplicate.GiftID = "gift-duplicate"
+	err = tracker.Track(ctx, duplicate)
+	require.Equal(t, &AlreadyTrackedError{DonationID: "don_1", GiftID: "gift-1"}, err)
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, tracker.TrackRecurring(ctx, DonationRecord{
+			DonationID:  fmt.Sprintf("don_rec_%d", i),
+			GiftID:      fmt.Sprintf("gift-rec-%d", i),
+			RecurringID: "rec_1",
+		}))
+	}
+
+	// More records than fit in one batch write.
+	batch := make([]DonationRecord, 30)
+	for i := range batch {
+		batch[i] = DonationRecord{DonationID: fmt.Sprintf("don_batch_%d", i), GiftID: fmt.Sprintf("gift-batch-%d", i)}
+	}
+	require.NoError(t, tracker.TrackBatch(ctx, batch))
+
+	batched, err := tracker.Lookup(ctx, "don_batch_29")
+	require.NoError(t, err)
+	require.Equal(t, "gift-batch-29", batched.GiftID)
+
+	// Batches refuse a different gift for a tracked donation too, while writing the rest.
+	err = tracker.TrackBatch(ctx, []DonationRecord{
+		duplicate,
+		{DonationID: "don_batch_30", GiftID: "gift-batch-30"},
+	})
+	var batchErr *BatchTrackError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, []*AlreadyTrackedError{{DonationID: "don_1", GiftID: "gift-1"}}, batchErr.AlreadyTracked)
+	batched, err = tracker.Lookup(ctx, "don_batch_30")
+	require.NoError(t, err)
+	require.NotNil(t, batched)
+
+	got, err := tracker.Lookup(ctx, "don_1")
+	require.NoError(t, err)
+	require.Equal(t, &oneOff, got)
+
+	missing, err := tracker.Lookup(ctx, "don_unknown")
+	require.NoError(t, err)
+	require.Nil(t, missing)
+
+	recurring, err := tracker.RecurringDonations(ctx, "rec_1")
+	require.NoError(t, err)
+	require.Len(t, recurring, 3)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	between, err := tracker.DonationsBetween(ctx, from, from.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	require.Equal(t, []DonationRecord{oneOff}, between)
+
+	counts, err := tracker.DonationCounts(ctx, from, from.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"2024-01-15": 1}, counts.ByDay)
+}
@@ -40,3 +40,13 @@ func (s *NoopStateStore) SetPendingDonationIDs(_ context.Context, _ []string) er
 func (s *NoopStateStore) RemovePendingDonationID(_ context.Context, _ string) error {
 	return nil
 }
+
+// FetchState always returns nil.
+func (s *NoopStateStore) FetchState(_ context.Context) (*FetchState, error) {
+	return nil, nil
+}
+
+// SetFetchState does nothing.
+func (s *NoopStateStore) SetFetchState(_ context.Context, _ *FetchState) error {
+	return nil
+}
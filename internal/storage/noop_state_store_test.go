@@ -131,3 +131,17 @@ func TestNoopStateStorePendingMethods(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestNoopStateStoreFetchStateMethods(t *testing.T) {
+	t.Parallel()
+
+	store := NewNoopStateStore(time.Now())
+
+	err := store.SetFetchState(context.Background(), &FetchState{Cursor: "DABCDEFG", Since: time.Now()})
+	require.NoError(t, err)
+
+	// State is never persisted.
+	state, err := store.FetchState(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
@@ -37,18 +37,7 @@ type TokenStore struct {
 
 // RefreshToken returns the current refresh token from Secrets Manager.
 func (t *TokenStore) RefreshToken(ctx context.Context) (string, error) {
-	output, err := t.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
-		SecretId: aws.String(t.secretARN),
-	})
-	if err != nil {
-		return "", fmt.Errorf("getting secret from Secrets Manager: %w", err)
-	}
-
-	if output.SecretString == nil {
-		return "", errors.New("secret has no string value")
-	}
-
-	return *output.SecretString, nil
+	return SecretString(ctx, t.client, t.secretARN)
 }
 
 // SaveRefreshToken stores a new refresh token in Secrets Manager.
@@ -68,6 +57,22 @@ func (t *TokenStore) SaveRefreshToken(ctx context.Context, token string) error {
 	return nil
 }
 
+// SecretString returns the string value of the Secrets Manager secret with the given ARN.
+func SecretString(ctx context.Context, client SecretsManagerAPI, secretARN string) (string, error) {
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretARN),
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting secret from Secrets Manager: %w", err)
+	}
+
+	if output.SecretString == nil {
+		return "", errors.New("secret has no string value")
+	}
+
+	return *output.SecretString, nil
+}
+
 // NewTokenStore creates a new Secrets Manager-backed token store.
 func NewTokenStore(client SecretsManagerAPI, secretARN string) (*TokenStore, error) {
 	if client == nil {
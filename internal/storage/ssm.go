@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -12,6 +13,23 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
 )
 
+const (
+	// maxFailureLength limits how much of a failed run's error is kept in its summary.
+	maxFailureLength = 200
+
+	// maxParameterSize is the largest value a standard tier SSM parameter holds, in bytes.
+	maxParameterSize = 4096
+
+	// maxPoisonPills is how many poison pills are kept, newest first.
+	maxPoisonPills = 5
+
+	// maxStackLength limits how much of a poison pill's stack trace is kept.
+	maxStackLength = 1500
+
+	// maxRunHistory is how many run summaries are kept, newest first.
+	maxRunHistory = 10
+)
+
 // SSMAPI defines the SSM operations used by the state store.
 type SSMAPI interface {
 	// GetParameter retrieves a parameter from SSM.
@@ -29,16 +47,157 @@ type SSMAPI interface {
 	) (*ssm.PutParameterOutput, error)
 }
 
+// FetchState records how far a sync has paged through the FundraiseUp donations window,
+// so an interrupted fetch resumes from the last checkpointed page instead of refetching the window.
+type FetchState struct {
+	// Cursor is the ID of the last donation fetched; the next page starts after it.
+	Cursor string `json:"cursor"`
+
+	// Since is the start of the donations window being fetched.
+	Since time.Time `json:"since"`
+}
+
+// GiftReview records a donation held back because its gift failed the configured gift checks,
+// so it can be reviewed and released to be tried again.
+type GiftReview struct {
+	// DonationID is the FundraiseUp ID of the donation.
+	DonationID string `json:"donationId"`
+
+	// HeldAt is when the donation last failed the checks.
+	HeldAt time.Time `json:"heldAt"`
+
+	// Violations describe each check the gift failed.
+	Violations []string `json:"violations"`
+}
+
+// HealthSnapshot is a compact summary of the sync's health, published after each run
+// so external monitors can check its freshness without invoking it.
+type HealthSnapshot struct {
+	// BacklogSize is the number of donations waiting to be resumed by the next run.
+	BacklogSize int `json:"backlogSize"`
+
+	// FetchInProgress indicates a donations fetch was left unfinished, so more donations may be waiting.
+	FetchInProgress bool `json:"fetchInProgress"`
+
+	// LastRunSucceeded indicates the last run finished without errors.
+	LastRunSucceeded bool `json:"lastRunSucceeded"`
+
+	// LastSuccessAt is when a run last finished without errors, or zero if none has.
+	LastSuccessAt time.Time `json:"lastSuccessAt"`
+
+	// TokenRefreshedAt is when the Blackbaud refresh token was last replaced, or zero if not yet seen.
+	TokenRefreshedAt time.Time `json:"tokenRefreshedAt"`
+
+	// UpdatedAt is when the snapshot was published, at the end of the last run.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// PoisonPill records a donation whose processing panicked, so it can be inspected and fixed by hand.
+type PoisonPill struct {
+	// DonationID is the FundraiseUp ID of the donation.
+	DonationID string `json:"donationId"`
+
+	// OccurredAt is when processing the donation panicked.
+	OccurredAt time.Time `json:"occurredAt"`
+
+	// Panic is the value the processing panicked with.
+	Panic string `json:"panic"`
+
+	// Stack is the stack trace of the panic, shortened.
+	Stack string `json:"stack,omitempty"`
+}
+
+// RetryEntry schedules another attempt at a donation that failed with an error expected to clear.
+type RetryEntry struct {
+	// Attempts is the number of times the donation has failed.
+	Attempts int `json:"attempts"`
+
+	// NextAttemptAt is when the donation is next due to be tried.
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+}
+
+// RunSummary records the outcome of a sync run, kept in the run history the status command shows.
+type RunSummary struct {
+	// ConstituentsCreated is the number of new constituents created.
+	ConstituentsCreated int `json:"constituentsCreated,omitempty"`
+
+	// DonationsProcessed is the number of donations processed.
+	DonationsProcessed int `json:"donationsProcessed"`
+
+	// Duration is how long the run took.
+	Duration time.Duration `json:"duration"`
+
+	// ErrorCategories counts the run's donation errors by category, such as "blackbaud_400" or "network".
+	ErrorCategories map[string]int `json:"errorCategories,omitempty"`
+
+	// Errors is the number of donations that failed.
+	Errors int `json:"errors,omitempty"`
+
+	// Failure is the error that stopped the run, shortened, when the run failed.
+	Failure string `json:"failure,omitempty"`
+
+	// GiftsChargedBack is the number of gifts given the chargeback status because their donation was charged back.
+	GiftsChargedBack int `json:"giftsChargedBack,omitempty"`
+
+	// GiftsCreated is the number of new gifts created.
+	GiftsCreated int `json:"giftsCreated,omitempty"`
+
+	// GiftsSkippedExisting is the number of gifts skipped because they already existed.
+	GiftsSkippedExisting int `json:"giftsSkippedExisting,omitempty"`
+
+	// GiftsSkippedExistingBy counts the gifts skipped because they already existed by how they were found,
+	// such as "lookup_id", "origin" or "tracker".
+	GiftsSkippedExistingBy map[string]int `json:"giftsSkippedExistingBy,omitempty"`
+
+	// GiftsUpdated is the number of existing gifts updated.
+	GiftsUpdated int `json:"giftsUpdated,omitempty"`
+
+	// Interrupted indicates the run was cancelled or timed out before finishing.
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	// PausedForQuota indicates the run stopped early to leave Blackbaud call quota for other integrations.
+	PausedForQuota bool `json:"pausedForQuota,omitempty"`
+
+	// StartedAt is when the run started.
+	StartedAt time.Time `json:"startedAt"`
+
+	// StoppedOnError indicates the run stopped at the first donation that failed, in fail-fast mode.
+	StoppedOnError bool `json:"stoppedOnError,omitempty"`
+}
+
 // StateStore manages sync state in AWS SSM Parameter Store.
 type StateStore struct {
 	// client is the SSM API client.
 	client SSMAPI
 
+	// fetchStateParameterName is the SSM parameter name for the fetch checkpoint.
+	fetchStateParameterName string
+
+	// giftReviewParameterName is the SSM parameter name for donations held for review.
+	// Donations failing the gift checks are only reported when empty.
+	giftReviewParameterName string
+
+	// healthParameterName is the SSM parameter name for the health snapshot.
+	// No snapshot is kept when empty.
+	healthParameterName string
+
 	// lastSyncParameterName is the SSM parameter name for last sync time.
 	lastSyncParameterName string
 
 	// pendingParameterName is the SSM parameter name for pending donation IDs.
 	pendingParameterName string
+
+	// poisonPillParameterName is the SSM parameter name for donations whose processing panicked.
+	// Poison pills are only logged when empty.
+	poisonPillParameterName string
+
+	// retryParameterName is the SSM parameter name for the retry schedule.
+	// Failed donations are not retried when empty.
+	retryParameterName string
+
+	// runHistoryParameterName is the SSM parameter name for recent run summaries.
+	// Run history is not kept when empty.
+	runHistoryParameterName string
 }
 
 // LastSyncTime returns the timestamp of the last successful sync.
@@ -143,6 +302,411 @@ func (s *StateStore) RemovePendingDonationID(ctx context.Context, id string) err
 	return s.SetPendingDonationIDs(ctx, remaining)
 }
 
+// FetchState returns the checkpoint of an unfinished fetch, or nil if no fetch is in progress.
+func (s *StateStore) FetchState(ctx context.Context) (*FetchState, error) {
+	output, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.fetchStateParameterName),
+	})
+	if err != nil {
+		var notFoundErr *types.ParameterNotFound
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting fetch state from SSM: %w", err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil || *output.Parameter.Value == "" {
+		return nil, nil
+	}
+
+	var state FetchState
+	if err := json.Unmarshal([]byte(*output.Parameter.Value), &state); err != nil {
+		return nil, fmt.Errorf("parsing fetch state from parameter: %w", err)
+	}
+
+	return &state, nil
+}
+
+// SetFetchState stores the checkpoint of an unfinished fetch. A nil state clears it once the fetch completes.
+func (s *StateStore) SetFetchState(ctx context.Context, state *FetchState) error {
+	value := ""
+	if state != nil {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("encoding fetch state: %w", err)
+		}
+		value = string(data)
+	}
+
+	_, err := s.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.fetchStateParameterName),
+		Overwrite: aws.Bool(true),
+		Type:      types.ParameterTypeString,
+		Value:     aws.String(value),
+	})
+	if err != nil {
+		return fmt.Errorf("putting fetch state to SSM: %w", err)
+	}
+
+	return nil
+}
+
+// HealthSnapshot returns the last published health snapshot.
+// Returns nil when none has been published, or no snapshot is kept.
+func (s *StateStore) HealthSnapshot(ctx context.Context) (*HealthSnapshot, error) {
+	if s.healthParameterName == "" {
+		return nil, nil
+	}
+
+	output, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.healthParameterName),
+	})
+	if err != nil {
+		var notFoundErr *types.ParameterNotFound
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting health snapshot from SSM: %w", err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil || *output.Parameter.Value == "" {
+		return nil, nil
+	}
+
+	var snapshot HealthSnapshot
+	if err := json.Unmarshal([]byte(*output.Parameter.Value), &snapshot); err != nil {
+		return nil, fmt.Errorf("parsing health snapshot from parameter: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// SetHealthSnapshot publishes the health snapshot, replacing the previous one.
+// Does nothing when no snapshot is kept.
+func (s *StateStore) SetHealthSnapshot(ctx context.Context, snapshot HealthSnapshot) error {
+	if s.healthParameterName == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("encoding health snapshot: %w", err)
+	}
+
+	_, err = s.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.healthParameterName),
+		Overwrite: aws.Bool(true),
+		Type:      types.ParameterTypeString,
+		Value:     aws.String(string(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("putting health snapshot to SSM: %w", err)
+	}
+
+	return nil
+}
+
+// GiftReviews returns the donations held for review because their gifts failed the gift checks, newest first.
+// Returns nil when none are held, or held donations are not kept.
+func (s *StateStore) GiftReviews(ctx context.Context) ([]GiftReview, error) {
+	if s.giftReviewParameterName == "" {
+		return nil, nil
+	}
+
+	output, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.giftReviewParameterName),
+	})
+	if err != nil {
+		var notFoundErr *types.ParameterNotFound
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting gift reviews from SSM: %w", err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil || *output.Parameter.Value == "" {
+		return nil, nil
+	}
+
+	var reviews []GiftReview
+	if err := json.Unmarshal([]byte(*output.Parameter.Value), &reviews); err != nil {
+		return nil, fmt.Errorf("parsing gift reviews from parameter: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// HoldForReview adds a donation whose gift failed the gift checks to the front of the donations held for review.
+// An earlier entry for the same donation is replaced. The oldest donations are dropped when they no longer fit in
+// the parameter. Does nothing when held donations are not kept.
+func (s *StateStore) HoldForReview(ctx context.Context, review GiftReview) error {
+	if s.giftReviewParameterName == "" {
+		return nil
+	}
+
+	existing, err := s.GiftReviews(ctx)
+	if err != nil {
+		return fmt.Errorf("getting gift reviews: %w", err)
+	}
+
+	reviews := []GiftReview{review}
+	for _, r := range existing {
+		if r.DonationID != review.DonationID {
+			reviews = append(reviews, r)
+		}
+	}
+
+	return s.putGiftReviews(ctx, reviews)
+}
+
+// ReleaseGiftReviews schedules donations held for review to be tried again by the next run, after the gift checks
+// or the donations have been fixed, and stops holding them. The donations are scheduled before they are released,
+// so a failure part way leaves them held rather than lost. A donation that is not held is an error.
+func (s *StateStore) ReleaseGiftReviews(ctx context.Context, donationIDs []string, now time.Time) error {
+	if s.giftReviewParameterName == "" || s.retryParameterName == "" {
+		return errors.New("releasing gift reviews needs the gift review and retry schedule parameters")
+	}
+
+	reviews, err := s.GiftReviews(ctx)
+	if err != nil {
+		return fmt.Errorf("getting gift reviews: %w", err)
+	}
+	release := make(map[string]bool, len(donationIDs))
+	for _, id := range donationIDs {
+		release[id] = true
+	}
+	var kept []GiftReview
+	for _, review := range reviews {
+		if release[review.DonationID] {
+			delete(release, review.DonationID)
+			continue
+		}
+		kept = append(kept, review)
+	}
+	for _, id := range donationIDs {
+		if release[id] {
+			return fmt.Errorf("donation %s is not held for review", id)
+		}
+	}
+
+	schedule, err := s.RetrySchedule(ctx)
+	if err != nil {
+		return fmt.Errorf("getting retry schedule: %w", err)
+	}
+	if schedule == nil {
+		schedule = make(map[string]RetryEntry, len(donationIDs))
+	}
+	for _, id := range donationIDs {
+		schedule[id] = RetryEntry{NextAttemptAt: now.UTC()}
+	}
+	if err := s.SetRetrySchedule(ctx, schedule); err != nil {
+		return fmt.Errorf("scheduling released donations: %w", err)
+	}
+
+	return s.putGiftReviews(ctx, kept)
+}
+
+// PoisonPills returns the donations whose processing recently panicked, newest first.
+// Returns nil when none have been recorded, or poison pills are not kept.
+func (s *StateStore) PoisonPills(ctx context.Context) ([]PoisonPill, error) {
+	if s.poisonPillParameterName == "" {
+		return nil, nil
+	}
+
+	output, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.poisonPillParameterName),
+	})
+	if err != nil {
+		var notFoundErr *types.ParameterNotFound
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting poison pills from SSM: %w", err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil || *output.Parameter.Value == "" {
+		return nil, nil
+	}
+
+	var pills []PoisonPill
+	if err := json.Unmarshal([]byte(*output.Parameter.Value), &pills); err != nil {
+		return nil, fmt.Errorf("parsing poison pills from parameter: %w", err)
+	}
+
+	return pills, nil
+}
+
+// RecordPoisonPill adds a donation whose processing panicked to the front of the poison pills, keeping the 5
+// most recent, or fewer when they do not fit in the parameter. An earlier entry for the same donation is replaced.
+// Does nothing when poison pills are not kept.
+func (s *StateStore) RecordPoisonPill(ctx context.Context, pill PoisonPill) error {
+	if s.poisonPillParameterName == "" {
+		return nil
+	}
+
+	existing, err := s.PoisonPills(ctx)
+	if err != nil {
+		return fmt.Errorf("getting poison pills: %w", err)
+	}
+
+	if len(pill.Stack) > maxStackLength {
+		pill.Stack = pill.Stack[:maxStackLength] + "..."
+	}
+	pills := []PoisonPill{pill}
+	for _, p := range existing {
+		if p.DonationID != pill.DonationID && len(pills) < maxPoisonPills {
+			pills = append(pills, p)
+		}
+	}
+
+	data, err := fitParameter(pills)
+	if err != nil {
+		return fmt.Errorf("encoding poison pills: %w", err)
+	}
+
+	_, err = s.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.poisonPillParameterName),
+		Overwrite: aws.Bool(true),
+		Type:      types.ParameterTypeString,
+		Value:     aws.String(string(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("putting poison pills to SSM: %w", err)
+	}
+
+	return nil
+}
+
+// RetrySchedule returns the donations scheduled to be tried again, by donation ID.
+// Returns nil when none are scheduled, or no schedule is kept.
+func (s *StateStore) RetrySchedule(ctx context.Context) (map[string]RetryEntry, error) {
+	if s.retryParameterName == "" {
+		return nil, nil
+	}
+
+	output, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.retryParameterName),
+	})
+	if err != nil {
+		var notFoundErr *types.ParameterNotFound
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting retry schedule from SSM: %w", err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil || *output.Parameter.Value == "" {
+		return nil, nil
+	}
+
+	var schedule map[string]RetryEntry
+	if err := json.Unmarshal([]byte(*output.Parameter.Value), &schedule); err != nil {
+		return nil, fmt.Errorf("parsing retry schedule from parameter: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// SetRetrySchedule stores the donations scheduled to be tried again, replacing the previous schedule.
+// An empty schedule clears it. Does nothing when no schedule is kept.
+func (s *StateStore) SetRetrySchedule(ctx context.Context, schedule map[string]RetryEntry) error {
+	if s.retryParameterName == "" {
+		return nil
+	}
+
+	value := ""
+	if len(schedule) > 0 {
+		data, err := json.Marshal(schedule)
+		if err != nil {
+			return fmt.Errorf("encoding retry schedule: %w", err)
+		}
+		if len(data) > maxParameterSize {
+			return fmt.Errorf("retry schedule of %d donations is too large for an SSM parameter", len(schedule))
+		}
+		value = string(data)
+	}
+
+	_, err := s.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.retryParameterName),
+		Overwrite: aws.Bool(true),
+		Type:      types.ParameterTypeString,
+		Value:     aws.String(value),
+	})
+	if err != nil {
+		return fmt.Errorf("putting retry schedule to SSM: %w", err)
+	}
+
+	return nil
+}
+
+// RunHistory returns the summaries of recent runs, newest first.
+// Returns nil when no runs have been recorded, or run history is not kept.
+func (s *StateStore) RunHistory(ctx context.Context) ([]RunSummary, error) {
+	if s.runHistoryParameterName == "" {
+		return nil, nil
+	}
+
+	output, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.runHistoryParameterName),
+	})
+	if err != nil {
+		var notFoundErr *types.ParameterNotFound
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting run history from SSM: %w", err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil || *output.Parameter.Value == "" {
+		return nil, nil
+	}
+
+	var history []RunSummary
+	if err := json.Unmarshal([]byte(*output.Parameter.Value), &history); err != nil {
+		return nil, fmt.Errorf("parsing run history from parameter: %w", err)
+	}
+
+	return history, nil
+}
+
+// RecordRun adds a run's summary to the front of the run history, keeping the 10 most recent runs,
+// or fewer when their summaries do not fit in the parameter. Does nothing when run history is not kept.
+func (s *StateStore) RecordRun(ctx context.Context, summary RunSummary) error {
+	if s.runHistoryParameterName == "" {
+		return nil
+	}
+
+	history, err := s.RunHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("getting run history: %w", err)
+	}
+
+	if len(summary.Failure) > maxFailureLength {
+		summary.Failure = summary.Failure[:maxFailureLength] + "..."
+	}
+	history = append([]RunSummary{summary}, history...)
+	if len(history) > maxRunHistory {
+		history = history[:maxRunHistory]
+	}
+
+	data, err := fitParameter(history)
+	if err != nil {
+		return fmt.Errorf("encoding run history: %w", err)
+	}
+
+	_, err = s.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.runHistoryParameterName),
+		Overwrite: aws.Bool(true),
+		Type:      types.ParameterTypeString,
+		Value:     aws.String(string(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("putting run history to SSM: %w", err)
+	}
+
+	return nil
+}
+
 // StateStoreOption configures a StateStore.
 type StateStoreOption func(*StateStore)
 
@@ -153,6 +717,48 @@ func WithPendingParameter(name string) StateStoreOption {
 	}
 }
 
+// WithFetchStateParameter sets the SSM parameter name for the fetch checkpoint.
+func WithFetchStateParameter(name string) StateStoreOption {
+	return func(s *StateStore) {
+		s.fetchStateParameterName = name
+	}
+}
+
+// WithGiftReviewParameter sets the SSM parameter name for donations held for review.
+func WithGiftReviewParameter(name string) StateStoreOption {
+	return func(s *StateStore) {
+		s.giftReviewParameterName = name
+	}
+}
+
+// WithHealthParameter sets the SSM parameter name for the health snapshot.
+func WithHealthParameter(name string) StateStoreOption {
+	return func(s *StateStore) {
+		s.healthParameterName = name
+	}
+}
+
+// WithPoisonPillParameter sets the SSM parameter name for donations whose processing panicked.
+func WithPoisonPillParameter(name string) StateStoreOption {
+	return func(s *StateStore) {
+		s.poisonPillParameterName = name
+	}
+}
+
+// WithRetryParameter sets the SSM parameter name for the retry schedule.
+func WithRetryParameter(name string) StateStoreOption {
+	return func(s *StateStore) {
+		s.retryParameterName = name
+	}
+}
+
+// WithRunHistoryParameter sets the SSM parameter name for recent run summaries.
+func WithRunHistoryParameter(name string) StateStoreOption {
+	return func(s *StateStore) {
+		s.runHistoryParameterName = name
+	}
+}
+
 // NewStateStore creates a new SSM-backed state store.
 func NewStateStore(client SSMAPI, lastSyncParameterName string, opts ...StateStoreOption) (*StateStore, error) {
 	if client == nil {
@@ -171,18 +777,82 @@ func NewStateStore(client SSMAPI, lastSyncParameterName string, opts ...StateSto
 		opt(store)
 	}
 
-	// Default pending parameter name if not set.
-	if store.pendingParameterName == "" {
-		const suffix = "last-sync-time"
+	// Derive unset parameter names from the sync time parameter by replacing its suffix.
+	const suffix = "last-sync-time"
+	if store.pendingParameterName == "" || store.fetchStateParameterName == "" {
 		if !strings.HasSuffix(lastSyncParameterName, suffix) {
 			return nil, fmt.Errorf(
-				"lastSyncParameterName must end with %q for default pending parameter derivation, or use WithPendingParameter option",
+				"lastSyncParameterName must end with %q for default parameter derivation, "+
+					"or use the WithPendingParameter and WithFetchStateParameter options",
 				suffix,
 			)
 		}
-		// Derive from the sync time parameter by replacing the suffix.
-		store.pendingParameterName = strings.TrimSuffix(lastSyncParameterName, suffix) + "pending-donations"
+	}
+	prefix := strings.TrimSuffix(lastSyncParameterName, suffix)
+	if store.pendingParameterName == "" {
+		store.pendingParameterName = prefix + "pending-donations"
+	}
+	if store.fetchStateParameterName == "" {
+		store.fetchStateParameterName = prefix + "fetch-state"
+	}
+	// Run history, health, retries, poison pills and gift reviews came later,
+	// so stores named without the suffix keep working without them.
+	if strings.HasSuffix(lastSyncParameterName, suffix) {
+		if store.giftReviewParameterName == "" {
+			store.giftReviewParameterName = prefix + "gift-reviews"
+		}
+		if store.healthParameterName == "" {
+			store.healthParameterName = prefix + "health"
+		}
+		if store.poisonPillParameterName == "" {
+			store.poisonPillParameterName = prefix + "poison-pills"
+		}
+		if store.retryParameterName == "" {
+			store.retryParameterName = prefix + "retry-schedule"
+		}
+		if store.runHistoryParameterName == "" {
+			store.runHistoryParameterName = prefix + "run-history"
+		}
 	}
 
 	return store, nil
 }
+
+// putGiftReviews stores the donations held for review, replacing those held before. No reviews clears them.
+func (s *StateStore) putGiftReviews(ctx context.Context, reviews []GiftReview) error {
+	value := ""
+	if len(reviews) > 0 {
+		data, err := fitParameter(reviews)
+		if err != nil {
+			return fmt.Errorf("encoding gift reviews: %w", err)
+		}
+		value = string(data)
+	}
+
+	_, err := s.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.giftReviewParameterName),
+		Overwrite: aws.Bool(true),
+		Type:      types.ParameterTypeString,
+		Value:     aws.String(value),
+	})
+	if err != nil {
+		return fmt.Errorf("putting gift reviews to SSM: %w", err)
+	}
+
+	return nil
+}
+
+// fitParameter encodes items as JSON, dropping the last, oldest, items until they fit in a parameter.
+// At least one item is always kept.
+func fitParameter[T any](items []T) ([]byte, error) {
+	for {
+		data, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) <= maxParameterSize || len(items) <= 1 {
+			return data, nil
+		}
+		items = items[:len(items)-1]
+	}
+}
@@ -2,7 +2,10 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -470,3 +473,1001 @@ func TestStateStore_WithPendingParameter(t *testing.T) {
 		require.Equal(t, "/mystack/pending-donations", calledWithName)
 	})
 }
+
+func TestStateStore_FetchState(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client  *mockSSMClient
+		errMsg  string
+		want    *FetchState
+		wantErr bool
+	}{
+		"returns state when found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					require.Equal(t, "/app/fetch-state", *params.Name)
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{
+							Value: aws.String(`{"cursor":"DHIJKLMN","since":"2024-01-15T10:30:00Z"}`),
+						},
+					}, nil
+				},
+			},
+			want: &FetchState{
+				Cursor: "DHIJKLMN",
+				Since:  time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			},
+		},
+		"returns nil when parameter not found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, &types.ParameterNotFound{}
+				},
+			},
+			want: nil,
+		},
+		"returns nil when value is empty": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{Value: aws.String("")},
+					}, nil
+				},
+			},
+			want: nil,
+		},
+		"returns error on invalid value": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{Value: aws.String("DHIJKLMN")},
+					}, nil
+				},
+			},
+			wantErr: true,
+			errMsg:  "parsing fetch state from parameter",
+		},
+		"returns error on ssm error": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, errors.New("ssm error")
+				},
+			},
+			wantErr: true,
+			errMsg:  "getting fetch state from SSM",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewStateStore(tc.client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			got, err := store.FetchState(context.Background())
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateStore_SetFetchState(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		errMsg    string
+		putErr    error
+		state     *FetchState
+		wantErr   bool
+		wantValue string
+	}{
+		"stores state": {
+			state: &FetchState{
+				Cursor: "DHIJKLMN",
+				Since:  time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			},
+			wantValue: `{"cursor":"DHIJKLMN","since":"2024-01-15T10:30:00Z"}`,
+		},
+		"nil state clears parameter": {
+			state:     nil,
+			wantValue: "",
+		},
+		"ssm error": {
+			state:   &FetchState{Cursor: "DHIJKLMN"},
+			putErr:  errors.New("ssm error"),
+			wantErr: true,
+			errMsg:  "putting fetch state to SSM",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var savedName, savedValue string
+			client := &mockSSMClient{
+				putParameterFunc: func(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+					if tc.putErr != nil {
+						return nil, tc.putErr
+					}
+					savedName = *params.Name
+					savedValue = *params.Value
+					return &ssm.PutParameterOutput{}, nil
+				},
+			}
+
+			store, err := NewStateStore(client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			err = store.SetFetchState(context.Background(), tc.state)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "/app/fetch-state", savedName)
+			require.Equal(t, tc.wantValue, savedValue)
+		})
+	}
+}
+
+func TestStateStore_WithFetchStateParameter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses custom parameter names without the default suffix", func(t *testing.T) {
+		t.Parallel()
+
+		var calledWithName string
+		client := &mockSSMClient{
+			getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+				calledWithName = *params.Name
+				return &ssm.GetParameterOutput{}, nil
+			},
+		}
+
+		store, err := NewStateStore(
+			client,
+			"/custom/sync-time",
+			WithPendingParameter("/custom/pending"),
+			WithFetchStateParameter("/custom/fetch"),
+		)
+		require.NoError(t, err)
+
+		_, err = store.FetchState(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "/custom/fetch", calledWithName)
+	})
+
+	t.Run("requires default suffix when fetch state parameter is derived", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := NewStateStore(&mockSSMClient{}, "/custom/sync-time", WithPendingParameter("/custom/pending"))
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "WithFetchStateParameter")
+		require.Nil(t, store)
+	})
+}
+
+func TestStateStore_RunHistory(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client  *mockSSMClient
+		errMsg  string
+		want    []RunSummary
+		wantErr bool
+	}{
+		"returns runs when found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					require.Equal(t, "/app/run-history", *params.Name)
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{
+							Value: aws.String(`[{"donationsProcessed":3,"duration":1000000000,"startedAt":"2024-01-15T10:30:00Z"}]`),
+						},
+					}, nil
+				},
+			},
+			want: []RunSummary{
+				{
+					DonationsProcessed: 3,
+					Duration:           time.Second,
+					StartedAt:          time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		"returns nil when parameter not found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, &types.ParameterNotFound{}
+				},
+			},
+			want: nil,
+		},
+		"returns error on invalid value": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{Value: aws.String("not-json")},
+					}, nil
+				},
+			},
+			wantErr: true,
+			errMsg:  "parsing run history from parameter",
+		},
+		"returns error on ssm error": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, errors.New("ssm error")
+				},
+			},
+			wantErr: true,
+			errMsg:  "getting run history from SSM",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewStateStore(tc.client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			got, err := store.RunHistory(context.Background())
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateStore_RecordRun(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	history := func(n int) string {
+		runs := make([]RunSummary, n)
+		for i := range runs {
+			runs[i] = RunSummary{DonationsProcessed: i, StartedAt: started.Add(-time.Duration(i+1) * time.Hour)}
+		}
+		data, err := json.Marshal(runs)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	tests := map[string]struct {
+		existing    string
+		summary     RunSummary
+		trimmed     bool
+		wantLen     int
+		wantFailure string
+	}{
+		"adds first run": {
+			summary: RunSummary{DonationsProcessed: 5, StartedAt: started},
+			wantLen: 1,
+		},
+		"prepends to existing runs": {
+			existing: history(3),
+			summary:  RunSummary{DonationsProcessed: 5, StartedAt: started},
+			wantLen:  4,
+		},
+		"keeps the most recent runs": {
+			existing: history(maxRunHistory),
+			summary:  RunSummary{DonationsProcessed: 5, StartedAt: started},
+			wantLen:  maxRunHistory,
+		},
+		"shortens long failures": {
+			summary:     RunSummary{Failure: strings.Repeat("x", 300), StartedAt: started},
+			wantLen:     1,
+			wantFailure: strings.Repeat("x", maxFailureLength) + "...",
+		},
+		"drops oldest runs that do not fit": {
+			existing: func() string {
+				runs := make([]RunSummary, maxRunHistory-1)
+				for i := range runs {
+					runs[i] = RunSummary{Failure: strings.Repeat("x", 1000), StartedAt: started}
+				}
+				data, err := json.Marshal(runs)
+				require.NoError(t, err)
+				return string(data)
+			}(),
+			summary: RunSummary{DonationsProcessed: 5, StartedAt: started},
+			trimmed: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var put string
+			client := &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					if tc.existing == "" {
+						return nil, &types.ParameterNotFound{}
+					}
+					return &ssm.GetParameterOutput{Parameter: &types.Parameter{Value: aws.String(tc.existing)}}, nil
+				},
+				putParameterFunc: func(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+					require.Equal(t, "/app/run-history", *params.Name)
+					put = *params.Value
+					return &ssm.PutParameterOutput{}, nil
+				},
+			}
+
+			store, err := NewStateStore(client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			err = store.RecordRun(context.Background(), tc.summary)
+			require.NoError(t, err)
+
+			require.LessOrEqual(t, len(put), maxParameterSize)
+			var got []RunSummary
+			require.NoError(t, json.Unmarshal([]byte(put), &got))
+			if tc.trimmed {
+				require.Less(t, len(got), maxRunHistory)
+			} else {
+				require.Len(t, got, tc.wantLen)
+			}
+			require.Equal(t, tc.summary.DonationsProcessed, got[0].DonationsProcessed)
+			if tc.wantFailure != "" {
+				require.Equal(t, tc.wantFailure, got[0].Failure)
+			}
+		})
+	}
+}
+
+func TestStateStore_WithRunHistoryParameter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses custom parameter name", func(t *testing.T) {
+		t.Parallel()
+
+		var calledWithName string
+		client := &mockSSMClient{
+			getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+				calledWithName = *params.Name
+				return &ssm.GetParameterOutput{}, nil
+			},
+		}
+
+		store, err := NewStateStore(client, "/app/last-sync-time", WithRunHistoryParameter("/custom/runs"))
+		require.NoError(t, err)
+
+		_, err = store.RunHistory(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "/custom/runs", calledWithName)
+	})
+
+	t.Run("keeps no history without the default suffix", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := NewStateStore(
+			&mockSSMClient{},
+			"/custom/sync-time",
+			WithPendingParameter("/custom/pending"),
+			WithFetchStateParameter("/custom/fetch"),
+		)
+		require.NoError(t, err)
+
+		got, err := store.RunHistory(context.Background())
+		require.NoError(t, err)
+		require.Nil(t, got)
+		require.NoError(t, store.RecordRun(context.Background(), RunSummary{}))
+	})
+}
+
+func TestStateStore_HealthSnapshot(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client  *mockSSMClient
+		errMsg  string
+		want    *HealthSnapshot
+		wantErr bool
+	}{
+		"returns snapshot when found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					require.Equal(t, "/app/health", *params.Name)
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{
+							Value: aws.String(`{"backlogSize":2,"lastSuccessAt":"2024-01-15T10:30:00Z"}`),
+						},
+					}, nil
+				},
+			},
+			want: &HealthSnapshot{
+				BacklogSize:   2,
+				LastSuccessAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			},
+		},
+		"returns nil when parameter not found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, &types.ParameterNotFound{}
+				},
+			},
+			want: nil,
+		},
+		"returns error on invalid value": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{Value: aws.String("not-json")},
+					}, nil
+				},
+			},
+			wantErr: true,
+			errMsg:  "parsing health snapshot from parameter",
+		},
+		"returns error on ssm error": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, errors.New("ssm error")
+				},
+			},
+			wantErr: true,
+			errMsg:  "getting health snapshot from SSM",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewStateStore(tc.client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			got, err := store.HealthSnapshot(context.Background())
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateStore_SetHealthSnapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("publishes snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		var put string
+		client := &mockSSMClient{
+			putParameterFunc: func(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+				require.Equal(t, "/app/health", *params.Name)
+				require.True(t, *params.Overwrite)
+				put = *params.Value
+				return &ssm.PutParameterOutput{}, nil
+			},
+		}
+
+		store, err := NewStateStore(client, "/app/last-sync-time")
+		require.NoError(t, err)
+
+		snapshot := HealthSnapshot{
+			BacklogSize: 3,
+			UpdatedAt:   time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		}
+		require.NoError(t, store.SetHealthSnapshot(context.Background(), snapshot))
+
+		var got HealthSnapshot
+		require.NoError(t, json.Unmarshal([]byte(put), &got))
+		require.Equal(t, snapshot, got)
+	})
+
+	t.Run("returns error on ssm error", func(t *testing.T) {
+		t.Parallel()
+
+		client := &mockSSMClient{
+			putParameterFunc: func(_ context.Context, _ *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+				return nil, errors.New("ssm error")
+			},
+		}
+
+		store, err := NewStateStore(client, "/app/last-sync-time", WithHealthParameter("/custom/health"))
+		require.NoError(t, err)
+
+		err = store.SetHealthSnapshot(context.Background(), HealthSnapshot{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "putting health snapshot to SSM")
+	})
+}
+
+func TestStateStore_RetrySchedule(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client  *mockSSMClient
+		errMsg  string
+		want    map[string]RetryEntry
+		wantErr bool
+	}{
+		"returns schedule when found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					require.Equal(t, "/app/retry-schedule", *params.Name)
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{
+							Value: aws.String(`{"DFQLCFEN":{"attempts":2,"nextAttemptAt":"2024-01-15T10:30:00Z"}}`),
+						},
+					}, nil
+				},
+			},
+			want: map[string]RetryEntry{
+				"DFQLCFEN": {Attempts: 2, NextAttemptAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+			},
+		},
+		"returns nil when parameter not found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, &types.ParameterNotFound{}
+				},
+			},
+			want: nil,
+		},
+		"returns nil when value is empty": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{Value: aws.String("")},
+					}, nil
+				},
+			},
+			want: nil,
+		},
+		"returns error on invalid value": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{Value: aws.String("DFQLCFEN")},
+					}, nil
+				},
+			},
+			wantErr: true,
+			errMsg:  "parsing retry schedule from parameter",
+		},
+		"returns error on ssm error": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, errors.New("ssm error")
+				},
+			},
+			wantErr: true,
+			errMsg:  "getting retry schedule from SSM",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewStateStore(tc.client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			got, err := store.RetrySchedule(context.Background())
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateStore_SetRetrySchedule(t *testing.T) {
+	t.Parallel()
+
+	next := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	large := make(map[string]RetryEntry)
+	for i := range 100 {
+		large[fmt.Sprintf("DON%05d", i)] = RetryEntry{Attempts: 1, NextAttemptAt: next}
+	}
+
+	tests := map[string]struct {
+		errMsg    string
+		schedule  map[string]RetryEntry
+		wantErr   bool
+		wantValue string
+	}{
+		"stores schedule": {
+			schedule:  map[string]RetryEntry{"DFQLCFEN": {Attempts: 1, NextAttemptAt: next}},
+			wantValue: `{"DFQLCFEN":{"attempts":1,"nextAttemptAt":"2024-01-15T10:30:00Z"}}`,
+		},
+		"clears empty schedule": {
+			schedule:  map[string]RetryEntry{},
+			wantValue: "",
+		},
+		"rejects schedule too large for the parameter": {
+			schedule: large,
+			wantErr:  true,
+			errMsg:   "retry schedule of 100 donations is too large",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var put *string
+			client := &mockSSMClient{
+				putParameterFunc: func(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+					require.Equal(t, "/app/retry-schedule", *params.Name)
+					put = params.Value
+					return &ssm.PutParameterOutput{}, nil
+				},
+			}
+
+			store, err := NewStateStore(client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			err = store.SetRetrySchedule(context.Background(), tc.schedule)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, put)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantValue, *put)
+			}
+		})
+	}
+}
+
+func TestStateStore_PoisonPills(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client  *mockSSMClient
+		errMsg  string
+		want    []PoisonPill
+		wantErr bool
+	}{
+		"returns poison pills when found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					require.Equal(t, "/app/poison-pills", *params.Name)
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{
+							Value: aws.String(`[{"donationId":"d1","occurredAt":"2024-01-15T10:30:00Z","panic":"boom"}]`),
+						},
+					}, nil
+				},
+			},
+			want: []PoisonPill{
+				{DonationID: "d1", OccurredAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), Panic: "boom"},
+			},
+		},
+		"returns nil when parameter not found": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, &types.ParameterNotFound{}
+				},
+			},
+			want: nil,
+		},
+		"returns error on invalid value": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return &ssm.GetParameterOutput{
+						Parameter: &types.Parameter{Value: aws.String("not-json")},
+					}, nil
+				},
+			},
+			wantErr: true,
+			errMsg:  "parsing poison pills from parameter",
+		},
+		"returns error on ssm error": {
+			client: &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					return nil, errors.New("ssm error")
+				},
+			},
+			wantErr: true,
+			errMsg:  "getting poison pills from SSM",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewStateStore(tc.client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			got, err := store.PoisonPills(context.Background())
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStateStore_RecordPoisonPill(t *testing.T) {
+	t.Parallel()
+
+	occurred := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	pills := func(ids ...string) string {
+		existing := make([]PoisonPill, len(ids))
+		for i, id := range ids {
+			existing[i] = PoisonPill{DonationID: id, OccurredAt: occurred.Add(-time.Duration(i+1) * time.Hour)}
+		}
+		data, err := json.Marshal(existing)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	tests := map[string]struct {
+		existing  string
+		pill      PoisonPill
+		wantIDs   []string
+		wantStack string
+	}{
+		"adds first poison pill": {
+			pill:    PoisonPill{DonationID: "new", OccurredAt: occurred, Panic: "boom"},
+			wantIDs: []string{"new"},
+		},
+		"prepends to existing poison pills": {
+			existing: pills("d1", "d2"),
+			pill:     PoisonPill{DonationID: "new", OccurredAt: occurred, Panic: "boom"},
+			wantIDs:  []string{"new", "d1", "d2"},
+		},
+		"replaces an earlier entry for the donation": {
+			existing: pills("d1", "new", "d2"),
+			pill:     PoisonPill{DonationID: "new", OccurredAt: occurred, Panic: "boom"},
+			wantIDs:  []string{"new", "d1", "d2"},
+		},
+		"keeps the most recent poison pills": {
+			existing: pills("d1", "d2", "d3", "d4", "d5"),
+			pill:     PoisonPill{DonationID: "new", OccurredAt: occurred, Panic: "boom"},
+			wantIDs:  []string{"new", "d1", "d2", "d3", "d4"},
+		},
+		"shortens long stacks": {
+			pill: PoisonPill{
+				DonationID: "new",
+				OccurredAt: occurred,
+				Panic:      "boom",
+				Stack:      strings.Repeat("x", 2000),
+			},
+			wantIDs:   []string{"new"},
+			wantStack: strings.Repeat("x", maxStackLength) + "...",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var put string
+			client := &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					if tc.existing == "" {
+						return nil, &types.ParameterNotFound{}
+					}
+					return &ssm.GetParameterOutput{Parameter: &types.Parameter{Value: aws.String(tc.existing)}}, nil
+				},
+				putParameterFunc: func(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+					require.Equal(t, "/app/poison-pills", *params.Name)
+					put = *params.Value
+					return &ssm.PutParameterOutput{}, nil
+				},
+			}
+
+			store, err := NewStateStore(client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			err = store.RecordPoisonPill(context.Background(), tc.pill)
+			require.NoError(t, err)
+
+			require.LessOrEqual(t, len(put), maxParameterSize)
+			var got []PoisonPill
+			require.NoError(t, json.Unmarshal([]byte(put), &got))
+			gotIDs := make([]string, len(got))
+			for i, pill := range got {
+				gotIDs[i] = pill.DonationID
+			}
+			require.Equal(t, tc.wantIDs, gotIDs)
+			if tc.wantStack != "" {
+				require.Equal(t, tc.wantStack, got[0].Stack)
+			}
+		})
+	}
+}
+
+func TestStateStore_WithPoisonPillParameter(t *testing.T) {
+	t.Parallel()
+
+	var calledWithName string
+	client := &mockSSMClient{
+		getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+			calledWithName = *params.Name
+			return &ssm.GetParameterOutput{}, nil
+		},
+	}
+
+	store, err := NewStateStore(client, "/app/last-sync-time", WithPoisonPillParameter("/custom/pills"))
+	require.NoError(t, err)
+
+	_, err = store.PoisonPills(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "/custom/pills", calledWithName)
+}
+
+func TestStateStore_HoldForReview(t *testing.T) {
+	t.Parallel()
+
+	held := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	reviews := func(ids ...string) string {
+		existing := make([]GiftReview, len(ids))
+		for i, id := range ids {
+			existing[i] = GiftReview{DonationID: id, HeldAt: held.Add(-time.Duration(i+1) * time.Hour)}
+		}
+		data, err := json.Marshal(existing)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	tests := map[string]struct {
+		existing string
+		wantIDs  []string
+	}{
+		"holds first donation": {
+			wantIDs: []string{"new"},
+		},
+		"prepends to held donations": {
+			existing: reviews("d1", "d2"),
+			wantIDs:  []string{"new", "d1", "d2"},
+		},
+		"replaces an earlier entry for the donation": {
+			existing: reviews("d1", "new", "d2"),
+			wantIDs:  []string{"new", "d1", "d2"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var put string
+			client := &mockSSMClient{
+				getParameterFunc: func(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					if tc.existing == "" {
+						return nil, &types.ParameterNotFound{}
+					}
+					return &ssm.GetParameterOutput{Parameter: &types.Parameter{Value: aws.String(tc.existing)}}, nil
+				},
+				putParameterFunc: func(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+					require.Equal(t, "/app/gift-reviews", *params.Name)
+					put = *params.Value
+					return &ssm.PutParameterOutput{}, nil
+				},
+			}
+
+			store, err := NewStateStore(client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			review := GiftReview{DonationID: "new", HeldAt: held, Violations: []string{"amount over 10000"}}
+			err = store.HoldForReview(context.Background(), review)
+			require.NoError(t, err)
+
+			var got []GiftReview
+			require.NoError(t, json.Unmarshal([]byte(put), &got))
+			gotIDs := make([]string, len(got))
+			for i, r := range got {
+				gotIDs[i] = r.DonationID
+			}
+			require.Equal(t, tc.wantIDs, gotIDs)
+			require.Equal(t, review, got[0])
+		})
+	}
+}
+
+func TestStateStore_ReleaseGiftReviews(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		errMsg       string
+		release      []string
+		retries      string
+		wantReviews  string
+		wantSchedule map[string]RetryEntry
+	}{
+		"schedules released donations and keeps the rest held": {
+			release:     []string{"d1"},
+			wantReviews: `[{"donationId":"d2","heldAt":"2024-01-15T09:00:00Z","violations":["no fund"]}]`,
+			wantSchedule: map[string]RetryEntry{
+				"d1": {NextAttemptAt: now},
+			},
+		},
+		"adds to the existing retry schedule": {
+			release:     []string{"d1", "d2"},
+			retries:     `{"d3":{"attempts":2,"nextAttemptAt":"2024-01-16T00:00:00Z"}}`,
+			wantReviews: "",
+			wantSchedule: map[string]RetryEntry{
+				"d1": {NextAttemptAt: now},
+				"d2": {NextAttemptAt: now},
+				"d3": {Attempts: 2, NextAttemptAt: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		"refuses a donation that is not held": {
+			release: []string{"d1", "d9"},
+			errMsg:  "donation d9 is not held for review",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			values := map[string]string{
+				"/app/gift-reviews": `[` +
+					`{"donationId":"d1","heldAt":"2024-01-15T10:00:00Z","violations":["too large"]},` +
+					`{"donationId":"d2","heldAt":"2024-01-15T09:00:00Z","violations":["no fund"]}]`,
+				"/app/retry-schedule": tc.retries,
+			}
+			var puts []string
+			client := &mockSSMClient{
+				getParameterFunc: func(_ context.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+					value := aws.String(values[*params.Name])
+					return &ssm.GetParameterOutput{Parameter: &types.Parameter{Value: value}}, nil
+				},
+				putParameterFunc: func(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+					puts = append(puts, *params.Name)
+					values[*params.Name] = *params.Value
+					return &ssm.PutParameterOutput{}, nil
+				},
+			}
+
+			store, err := NewStateStore(client, "/app/last-sync-time")
+			require.NoError(t, err)
+
+			err = store.ReleaseGiftReviews(context.Background(), tc.release, now)
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				require.Empty(t, puts)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, []string{"/app/retry-schedule", "/app/gift-reviews"}, puts)
+			require.Equal(t, tc.wantReviews, values["/app/gift-reviews"])
+			var schedule map[string]RetryEntry
+			require.NoError(t, json.Unmarshal([]byte(values["/app/retry-schedule"]), &schedule))
+			require.Equal(t, tc.wantSchedule, schedule)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/httpclient"
+)
+
+const (
+	// defaultVaultKVMount is the path of Vault's KV version 2 secrets engine in a new Vault server.
+	defaultVaultKVMount = "secret"
+
+	// vaultRefreshTokenKey is the key of the refresh token within the Vault secret.
+	vaultRefreshTokenKey = "refresh_token"
+
+	// vaultTimeout is the timeout of the default client for Vault.
+	vaultTimeout = 30 * time.Second
+)
+
+// VaultTokenStore manages OAuth refresh tokens in a HashiCorp Vault KV version 2 secrets engine, for organisations
+// that keep their secrets in Vault rather than AWS Secrets Manager.
+type VaultTokenStore struct {
+	// address is the Vault server's URL.
+	address string
+
+	// client sends requests to Vault.
+	client *http.Client
+
+	// mount is the path the KV secrets engine is mounted at.
+	mount string
+
+	// namespace is the Vault Enterprise namespace of the secret, if any.
+	namespace string
+
+	// secretPath is the path of the secret holding the refresh token, within mount.
+	secretPath string
+
+	// token authenticates requests to Vault.
+	token string
+}
+
+// VaultTokenStoreOption configures a VaultTokenStore.
+type VaultTokenStoreOption func(*VaultTokenStore)
+
+// WithVaultHTTPClient sets the HTTP client used to reach Vault, such as one sending requests through a proxy.
+func WithVaultHTTPClient(client *http.Client) VaultTokenStoreOption {
+	return func(v *VaultTokenStore) {
+		if client != nil {
+			v.client = client
+		}
+	}
+}
+
+// WithVaultKVMount sets the path the KV version 2 secrets engine is mounted at (default: secret).
+func WithVaultKVMount(mount string) VaultTokenStoreOption {
+	return func(v *VaultTokenStore) {
+		if mount = strings.Trim(mount, "/"); mount != "" {
+			v.mount = mount
+		}
+	}
+}
+
+// WithVaultNamespace sets the Vault Enterprise namespace of the secret.
+func WithVaultNamespace(namespace string) VaultTokenStoreOption {
+	return func(v *VaultTokenStore) {
+		v.namespace = namespace
+	}
+}
+
+// NewVaultTokenStore creates a token store keeping the refresh token under the "refresh_token" key of the Vault
+// secret at secretPath. Saving a token replaces the whole secret, so the secret should hold nothing else.
+func NewVaultTokenStore(
+	address string,
+	token string,
+	secretPath string,
+	opts ...VaultTokenStoreOption,
+) (*VaultTokenStore, error) {
+	if address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if token == "" {
+		return nil, errors.New("vault token is required")
+	}
+	if secretPath = strings.Trim(secretPath, "/"); secretPath == "" {
+		return nil, errors.New("vault secret path is required")
+	}
+
+	v := &VaultTokenStore{
+		address:    strings.TrimRight(address, "/"),
+		client:     &http.Client{Timeout: vaultTimeout},
+		mount:      defaultVaultKVMount,
+		secretPath: secretPath,
+		token:      token,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v, nil
+}
+
+// RefreshToken returns the current refresh token from Vault.
+func (v *VaultTokenStore) RefreshToken(ctx context.Context) (string, error) {
+	body, err := v.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return "", fmt.Errorf("getting secret from Vault: %w", err)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("decoding Vault secret: %w", err)
+	}
+
+	token, _ := secret.Data.Data[vaultRefreshTokenKey].(string)
+	if token == "" {
+		return "", fmt.Errorf("vault secret %s has no %s value", v.secretPath, vaultRefreshTokenKey)
+	}
+
+	return token, nil
+}
+
+// SaveRefreshToken stores a new refresh token in Vault, as a new version of the secret.
+func (v *VaultTokenStore) SaveRefreshToken(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.New("token cannot be empty")
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"data": map[string]string{vaultRefreshTokenKey: token},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding Vault secret: %w", err)
+	}
+
+	if _, err := v.do(ctx, http.MethodPost, payload); err != nil {
+		return fmt.Errorf("putting secret to Vault: %w", err)
+	}
+
+	return nil
+}
+
+// do sends a request for the secret to Vault's KV version 2 API, returning the response body.
+func (v *VaultTokenStore) do(ctx context.Context, method string, payload []byte) ([]byte, error) {
+	endpoint, err := url.JoinPath(v.address, "v1", v.mount, "data", v.secretPath)
+	if err != nil {
+		return nil, fmt.Errorf("building Vault URL: %w", err)
+	}
+
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("secret %s not found in mount %s", v.secretPath, v.mount)
+	case resp.StatusCode >= http.StatusBadRequest:
+		body := httpclient.ReadErrorBody(resp.Body, httpclient.DefaultMaxBodySize)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, vaultErrors([]byte(body)))
+	}
+
+	body, err := httpclient.ReadBody(resp.Body, httpclient.DefaultMaxBodySize)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	return body, nil
+}
+
+// vaultErrors returns the errors listed in a Vault error response, or the body itself when it lists none.
+func vaultErrors(body []byte) string {
+	var resp struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Errors) == 0 {
+		return strings.TrimSpace(string(body))
+	}
+	return strings.Join(resp.Errors, "; ")
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves a KV version 2 secrets engine holding one secret, as Vault would.
+type fakeVault struct {
+	// data is the latest version of the secret, or nil when it does not exist.
+	data map[string]any
+
+	// namespace is the namespace requests must name.
+	namespace string
+
+	// path is the API path of the secret.
+	path string
+
+	// token is the token requests must present.
+	token string
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("X-Vault-Token") != f.token || r.Header.Get("X-Vault-Namespace") != f.namespace {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	if r.URL.Path != f.path {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if f.data == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"data": f.data, "metadata": map[string]any{"version": 1}},
+		})
+	case http.MethodPost:
+		var body struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.data = body.Data
+		_, _ = w.Write([]byte(`{"data":{"version":2}}`))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestNewVaultTokenStore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		address    string
+		errMsg     string
+		secretPath string
+		token      string
+	}{
+		"valid": {
+			address:    "https://vault.example.org:8200",
+			secretPath: "giftbridge/blackbaud",
+			token:      "hvs.token",
+		},
+		"missing address": {
+			errMsg:     "vault address is required",
+			secretPath: "giftbridge/blackbaud",
+			token:      "hvs.token",
+		},
+		"missing token": {
+			address:    "https://vault.example.org:8200",
+			errMsg:     "vault token is required",
+			secretPath: "giftbridge/blackbaud",
+		},
+		"missing secret path": {
+			address:    "https://vault.example.org:8200",
+			errMsg:     "vault secret path is required",
+			secretPath: "/",
+			token:      "hvs.token",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewVaultTokenStore(tc.address, tc.token, tc.secretPath)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				require.Nil(t, store)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, store)
+		})
+	}
+}
+
+func TestVaultTokenStore_RefreshToken(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		data      map[string]any
+		errMsg    string
+		token     string
+		wantToken string
+	}{
+		"returns token successfully": {
+			data:      map[string]any{"refresh_token": "refresh-token-value"},
+			token:     "hvs.token",
+			wantToken: "refresh-token-value",
+		},
+		"secret not found": {
+			errMsg: "secret giftbridge/blackbaud not found in mount kv",
+			token:  "hvs.token",
+		},
+		"secret without refresh token": {
+			data:   map[string]any{"api_key": "other"},
+			errMsg: "vault secret giftbridge/blackbaud has no refresh_token value",
+			token:  "hvs.token",
+		},
+		"permission denied": {
+			data:   map[string]any{"refresh_token": "refresh-token-value"},
+			errMsg: "getting secret from Vault: unexpected status 403: permission denied",
+			token:  "hvs.expired",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			vault := &fakeVault{
+				data:      tc.data,
+				namespace: "fundraising",
+				path:      "/v1/kv/data/giftbridge/blackbaud",
+				token:     "hvs.token",
+			}
+			server := httptest.NewServer(vault)
+			t.Cleanup(server.Close)
+
+			store, err := NewVaultTokenStore(server.URL, tc.token, "/giftbridge/blackbaud",
+				WithVaultHTTPClient(server.Client()), WithVaultKVMount("kv/"), WithVaultNamespace("fundraising"))
+			require.NoError(t, err)
+
+			token, err := store.RefreshToken(context.Background())
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantToken, token)
+		})
+	}
+}
+
+func TestVaultTokenStore_SaveRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	vault := &fakeVault{
+		data:  map[string]any{"refresh_token": "old-token"},
+		path:  "/v1/secret/data/giftbridge/blackbaud",
+		token: "hvs.token",
+	}
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	store, err := NewVaultTokenStore(server.URL, "hvs.token", "giftbridge/blackbaud",
+		WithVaultHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	require.ErrorContains(t, store.SaveRefreshToken(context.Background(), ""), "token cannot be empty")
+	require.NoError(t, store.SaveRefreshToken(context.Background(), "new-token"))
+
+	token, err := store.RefreshToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "new-token", token)
+}
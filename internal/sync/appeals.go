@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+)
+
+// recordAppealResponses records the constituent's response to each appeal of a new gift that they have not
+// already responded to, so appeal performance in Raiser's Edge NXT includes the gift. A constituent created for
+// the gift has no appeals yet, so theirs are not listed. The appeals of each constituent are listed once a run.
+// A response that cannot be recorded does not fail the donation, as the gift already exists;
+// it is returned as a warning so the response can be added by hand.
+func (s *Service) recordAppealResponses(
+	ctx context.Context,
+	constituentID string,
+	constituentCreated bool,
+	gift *blackbaud.Gift,
+) []string {
+	if !s.giftDefaults.AppealResponses {
+		return nil
+	}
+	responder, ok := s.blackbaud.(AppealResponder)
+	if !ok {
+		return nil
+	}
+
+	var appealIDs []string
+	for _, split := range gift.GiftSplits {
+		if split.AppealID != "" {
+			appealIDs = append(appealIDs, split.AppealID)
+		}
+	}
+	if len(appealIDs) == 0 {
+		return nil
+	}
+
+	if s.constituentAppeals == nil {
+		s.constituentAppeals = make(map[string]map[string]bool)
+	}
+	responded, listed := s.constituentAppeals[constituentID]
+	if !listed {
+		responded = make(map[string]bool)
+		if !constituentCreated {
+			appeals, err := responder.ConstituentAppeals(ctx, constituentID)
+			if err != nil {
+				return []string{fmt.Sprintf("listing constituent appeals: %v", err)}
+			}
+			for _, appeal := range appeals {
+				responded[appeal.AppealID] = true
+			}
+		}
+		s.constituentAppeals[constituentID] = responded
+	}
+
+	var warnings []string
+	for _, appealID := range appealIDs {
+		if responded[appealID] {
+			continue
+		}
+		appeal := &blackbaud.ConstituentAppeal{
+			AppealID:      appealID,
+			ConstituentID: constituentID,
+			Date:          gift.Date,
+		}
+		if _, err := responder.CreateConstituentAppeal(ctx, appeal); err != nil {
+			warnings = append(warnings, fmt.Sprintf("recording response to appeal %q: %v", appealID, err))
+			continue
+		}
+		responded[appealID] = true
+	}
+
+	return warnings
+}
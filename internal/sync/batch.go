@@ -0,0 +1,58 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// runDonations processes the donations listed in Config.DonationIDs, fetched by ID in order, as each batch of a
+// backfill is. Nothing is read from or written to the state store, so batches processed in parallel do not contend
+// for the pending list or the sync time. Donations not reached when the run is cancelled or pauses for quota are
+// returned in the result's RemainingDonationIDs, so the batch can be run again from there, as are the donation that
+// failed in fail-fast mode and those after it.
+func (s *Service) runDonations(ctx context.Context, result *Result) (*Result, error) {
+	s.logger.Info("processing donations by ID",
+		"count", len(s.donationIDs),
+		"dry_run", s.dryRun)
+
+	for i, donationID := range s.donationIDs {
+		if err := ctx.Err(); err != nil {
+			result.RemainingDonationIDs = slices.Clone(s.donationIDs[i:])
+			return s.interrupt(result, err)
+		}
+		if s.quotaLow(result) {
+			result.RemainingDonationIDs = slices.Clone(s.donationIDs[i:])
+			return s.pauseForQuota(result), nil
+		}
+
+		fetchStart := time.Now()
+		donation, err := s.fundraiseup.Donation(ctx, donationID)
+		s.metrics.FundraiseUp.observe(fetchStart)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				result.RemainingDonationIDs = slices.Clone(s.donationIDs[i:])
+				return s.interrupt(result, ctxErr)
+			}
+			s.logger.Error("failed to fetch donation",
+				"donation_id", donationID,
+				"error", err)
+			result.Errors = append(result.Errors, fmt.Errorf("fetching donation %s: %w", donationID, err))
+			if s.failFast {
+				result.RemainingDonationIDs = slices.Clone(s.donationIDs[i:])
+				return s.stopOnError(result), nil
+			}
+			continue
+		}
+
+		// Like finishDonation, a donation runs to completion once started, even if the run is cancelled.
+		if err := s.attemptDonation(context.WithoutCancel(ctx), result, *donation); s.failingFast(err) {
+			result.RemainingDonationIDs = slices.Clone(s.donationIDs[i:])
+			return s.stopOnError(result), nil
+		}
+	}
+
+	s.logSyncComplete(result)
+	return result, nil
+}
@@ -11,6 +11,9 @@ type BlackbaudClient interface {
 	// CreateConstituent creates a new constituent and returns the new constituent ID.
 	CreateConstituent(ctx context.Context, constituent *blackbaud.Constituent) (string, error)
 
+	// CreateConstituentCode adds a constituent code to a constituent and returns the new constituent code ID.
+	CreateConstituentCode(ctx context.Context, code *blackbaud.ConstituentCode) (string, error)
+
 	// CreateGift creates a new gift and returns the new gift ID.
 	CreateGift(ctx context.Context, gift *blackbaud.Gift) (string, error)
 
@@ -22,8 +25,73 @@ type BlackbaudClient interface {
 	) ([]blackbaud.Gift, error)
 
 	// SearchConstituents searches for constituents matching the given email address.
-	SearchConstituents(ctx context.Context, email string) ([]blackbaud.Constituent, error)
+	SearchConstituents(
+		ctx context.Context,
+		email string,
+		opts blackbaud.SearchOptions,
+	) ([]blackbaud.Constituent, error)
 
 	// UpdateGift updates an existing gift by ID.
 	UpdateGift(ctx context.Context, giftID string, gift *blackbaud.Gift) error
 }
+
+// AppealResponder is implemented by Blackbaud clients that can record constituents' responses to appeals,
+// which appeal responses require.
+type AppealResponder interface {
+	// ConstituentAppeals returns the appeals recorded for a constituent.
+	ConstituentAppeals(ctx context.Context, constituentID string) ([]blackbaud.ConstituentAppeal, error)
+
+	// CreateConstituentAppeal records a constituent's response to an appeal and returns the new constituent appeal ID.
+	CreateConstituentAppeal(ctx context.Context, appeal *blackbaud.ConstituentAppeal) (string, error)
+}
+
+// EmailAdder is implemented by Blackbaud clients that can add email addresses to existing constituents,
+// which adding missing emails requires.
+type EmailAdder interface {
+	// CreateEmailAddress adds an email address to a constituent and returns the new email address ID.
+	CreateEmailAddress(ctx context.Context, email *blackbaud.EmailAddress) (string, error)
+}
+
+// EventRegistrar is implemented by Blackbaud clients that can add constituents to events as participants,
+// which linked events require.
+type EventRegistrar interface {
+	// CreateEventParticipant adds a participant to an event and returns the new participant ID.
+	CreateEventParticipant(ctx context.Context, eventID string, participant *blackbaud.Participant) (string, error)
+
+	// EventParticipants returns the participants of an event.
+	EventParticipants(ctx context.Context, eventID string) ([]blackbaud.Participant, error)
+}
+
+// GiftCustomFieldCreator is implemented by Blackbaud clients that can add custom fields to gifts,
+// which GiftDefaults.CreatorCustomField requires.
+type GiftCustomFieldCreator interface {
+	// CreateGiftCustomField adds a custom field to a gift and returns the new custom field ID.
+	CreateGiftCustomField(ctx context.Context, field *blackbaud.GiftCustomField) (string, error)
+}
+
+// GiftNoteCreator is implemented by Blackbaud clients that can add notes to gifts,
+// which GiftDefaults.ReferenceNoteType requires.
+type GiftNoteCreator interface {
+	// CreateGiftNote adds a note to a gift and returns the new note ID.
+	CreateGiftNote(ctx context.Context, note *blackbaud.GiftNote) (string, error)
+}
+
+// GiftReader is implemented by Blackbaud clients that can read a single gift, which verification requires.
+type GiftReader interface {
+	// Gift returns the gift with the given ID.
+	Gift(ctx context.Context, giftID string) (*blackbaud.Gift, error)
+}
+
+// NoteCreator is implemented by Blackbaud clients that can add notes to constituents,
+// which donation notes, plan change notes and chargeback notes require.
+type NoteCreator interface {
+	// CreateConstituentNote adds a note to a constituent and returns the new note ID.
+	CreateConstituentNote(ctx context.Context, note *blackbaud.ConstituentNote) (string, error)
+}
+
+// QuotaReporter is implemented by Blackbaud clients that report the SKY API call quota remaining.
+type QuotaReporter interface {
+	// Quota returns the call quota reported by the most recent API response.
+	// Returns false if no quota has been reported.
+	Quota() (blackbaud.Quota, bool)
+}
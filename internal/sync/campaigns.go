@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// donationCampaignID returns the FundraiseUp campaign of a donation, or "" when it has none.
+func donationCampaignID(donation fundraiseup.Donation) string {
+	if donation.Campaign == nil {
+		return ""
+	}
+	return donation.Campaign.ID
+}
+
+// skipOutsideCampaignWindow reports whether a donation is left out of Raiser's Edge NXT because its campaign is
+// excluded, or it was made outside its campaign's window. Donations of campaigns without a window are all synced.
+func (s *Service) skipOutsideCampaignWindow(donation fundraiseup.Donation) bool {
+	window, ok := s.campaignWindows[donationCampaignID(donation)]
+	switch {
+	case !ok:
+		return false
+	case window.Exclude:
+		return true
+	case !window.From.IsZero() && donation.CreatedAt.Before(window.From):
+		return true
+	default:
+		return !window.Until.IsZero() && !donation.CreatedAt.Before(window.Until)
+	}
+}
+
+// countCampaign adds a processed donation to its campaign's counts in the result, when campaign windows are
+// configured, so the effect of each window can be seen.
+func (s *Service) countCampaign(result *Result, donation fundraiseup.Donation, donationResult DonationResult) {
+	if len(s.campaignWindows) == 0 {
+		return
+	}
+	if result.Campaigns == nil {
+		result.Campaigns = make(map[string]CampaignCounts)
+	}
+
+	campaignID := donationCampaignID(donation)
+	counts := result.Campaigns[campaignID]
+	counts.Donations++
+	if donationResult.GiftCreated {
+		counts.GiftsCreated++
+	}
+	if donationResult.SkippedCampaign {
+		counts.SkippedOutsideWindow++
+	}
+	result.Campaigns[campaignID] = counts
+}
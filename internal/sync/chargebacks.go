@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// applyChargeback gives the tracked gift of a disputed or charged back donation the chargeback status, and adds a
+// note to the gift's constituent when a chargeback note type is set. It returns true when the gift was updated,
+// and false when the donation has no tracked gift or the gift already has the status, so a chargeback read again
+// is not applied twice. A note that cannot be added does not fail the chargeback, as the gift is already updated;
+// it is returned as a warning so the note can be added by hand.
+func (s *Service) applyChargeback(
+	ctx context.Context,
+	donation fundraiseup.Donation,
+	at time.Time,
+) (bool, []string, error) {
+	record, err := s.lookupTracked(ctx, donation.ID)
+	if err != nil {
+		return false, nil, fmt.Errorf("looking up tracked donation %s: %w", donation.ID, err)
+	}
+	if record == nil || record.GiftID == "" {
+		return false, nil, nil
+	}
+
+	reader, ok := s.blackbaud.(GiftReader)
+	if !ok {
+		return false, nil, nil
+	}
+	current, err := reader.Gift(ctx, record.GiftID)
+	if err != nil {
+		return false, nil, fmt.Errorf("reading gift %s: %w", record.GiftID, err)
+	}
+	if strings.EqualFold(current.GiftStatus, s.chargebackStatus) {
+		return false, nil, nil
+	}
+
+	if err := s.blackbaud.UpdateGift(ctx, record.GiftID, &blackbaud.Gift{GiftStatus: s.chargebackStatus}); err != nil {
+		return false, nil, fmt.Errorf("updating gift %s: %w", record.GiftID, err)
+	}
+
+	s.logger.Warn("marked gift of charged back donation",
+		"donation_id", donation.ID,
+		"gift_id", record.GiftID,
+		"donation_status", donation.Status,
+		"gift_status", s.chargebackStatus)
+
+	constituentID := current.ConstituentID
+	if constituentID == "" {
+		constituentID = record.ConstituentID
+	}
+	return true, s.recordChargebackNote(ctx, constituentID, record.GiftID, donation, at), nil
+}
+
+// recordChargebackNote adds a note to the constituent of a gift given the chargeback status, saying why,
+// so gift officers see the reversed payment in the donor's history. The note is dated when the chargeback
+// was raised in FundraiseUp.
+func (s *Service) recordChargebackNote(
+	ctx context.Context,
+	constituentID string,
+	giftID string,
+	donation fundraiseup.Donation,
+	at time.Time,
+) []string {
+	if s.chargebackNoteType == "" || constituentID == "" {
+		return nil
+	}
+	creator, ok := s.blackbaud.(NoteCreator)
+	if !ok {
+		return nil
+	}
+
+	summary, reason := "FundraiseUp chargeback ", "charged back"
+	if strings.EqualFold(donation.Status, fundraiseup.DonationStatusDisputed) {
+		summary, reason = "FundraiseUp dispute ", "disputed"
+	}
+
+	note := &blackbaud.ConstituentNote{
+		ConstituentID: constituentID,
+		Date: &blackbaud.FuzzyDate{
+			Day:   at.Day(),
+			Month: int(at.Month()),
+			Year:  at.Year(),
+		},
+		Summary: summary + donation.ID,
+		Text: fmt.Sprintf("Online donation %s of %s was %s on %s, so gift %s was marked %s.",
+			donation.ID,
+			strings.TrimSpace(donation.Amount+" "+strings.ToUpper(donation.Currency)),
+			reason,
+			at.UTC().Format("2006-01-02"),
+			giftID,
+			s.chargebackStatus),
+		Type: s.chargebackNoteType,
+	}
+	if _, err := creator.CreateConstituentNote(ctx, note); err != nil {
+		return []string{fmt.Sprintf("adding chargeback note: %v", err)}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+)
+
+// runID identifies the run started at start, as the UTC time it started to the second.
+// It matches the start time the run history records, so a gift's creator custom field leads to its run.
+func runID(start time.Time) string {
+	return start.UTC().Format(time.RFC3339)
+}
+
+// recordCreator adds the creator custom field to a new gift, recording that GiftBridge created it and in which run,
+// so Raiser's Edge NXT users can tell integration gifts from those entered by hand. A custom field that cannot be
+// added does not fail the donation, as the gift already exists; it is returned as a warning instead.
+func (s *Service) recordCreator(ctx context.Context, giftID string) []string {
+	creator, ok := s.blackbaud.(GiftCustomFieldCreator)
+	if s.giftDefaults.CreatorCustomField == "" || !ok {
+		return nil
+	}
+
+	field := &blackbaud.GiftCustomField{
+		Category: s.giftDefaults.CreatorCustomField,
+		ParentID: giftID,
+		Value:    "GiftBridge run " + s.runID,
+	}
+	if _, err := creator.CreateGiftCustomField(ctx, field); err != nil {
+		return []string{fmt.Sprintf("adding creator custom field: %v", err)}
+	}
+
+	return nil
+}
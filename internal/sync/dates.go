@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// GiftDateError reports that a donation's gift date is implausible, being in the future, or older than the configured
+// maximum age.
+type GiftDateError struct {
+	// Date is the gift's date, in YYYY-MM-DD format.
+	Date string
+
+	// Reason describes what is wrong with the date.
+	Reason string
+}
+
+// Error implements error.
+func (e *GiftDateError) Error() string {
+	return fmt.Sprintf("gift date %q %s", e.Date, e.Reason)
+}
+
+// checkGiftDate checks the date a donation's gift is given, the day the donation was made in UTC, when a gift date
+// policy is set, returning a *GiftDateError for a date in the future or more than the maximum age before now.
+// A day's leeway is allowed for future dates, since a supporter ahead of UTC may give on what is tomorrow in UTC.
+func (s *Service) checkGiftDate(createdAt time.Time, now time.Time) error {
+	if s.giftDefaults.DatePolicy == "" {
+		return nil
+	}
+
+	createdAt = createdAt.UTC()
+	date := time.Date(createdAt.Year(), createdAt.Month(), createdAt.Day(), 0, 0, 0, 0, time.UTC)
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if date.After(today.AddDate(0, 0, 1)) {
+		return &GiftDateError{Date: date.Format("2006-01-02"), Reason: "is in the future"}
+	}
+	if maxAge := s.giftDefaults.MaxAgeDays; maxAge > 0 && date.Before(today.AddDate(0, 0, -maxAge)) {
+		return &GiftDateError{Date: date.Format("2006-01-02"), Reason: fmt.Sprintf("is more than %d days old", maxAge)}
+	}
+	return nil
+}
+
+// guardGiftDate applies the gift date policy to a donation before anything is created for it. Under
+// config.GiftDatePolicyRefuse an implausible date is returned as an error, so neither the constituent nor the gift is
+// created; under config.GiftDatePolicyFlag it is returned as a warning, and the gift is created as mapped.
+func (s *Service) guardGiftDate(donation fundraiseup.Donation) (string, error) {
+	err := s.checkGiftDate(donation.CreatedAt, time.Now())
+	if err == nil {
+		return "", nil
+	}
+	if s.giftDefaults.DatePolicy == config.GiftDatePolicyRefuse {
+		s.logger.Warn("gift date is implausible, not creating it", "donation_id", donation.ID, "error", err)
+		return "", err
+	}
+	s.logger.Warn("gift date is implausible", "donation_id", donation.ID, "error", err)
+	return err.Error(), nil
+}
@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// skipTracked decides what to do with a donation the tracker already holds a gift for.
+// It returns true when the donation is finished with, and false when its gift should be created again.
+// Without a deleted gift policy the tracker is trusted, so the gift is not checked. Gifts tracked longer ago
+// than the deleted gift check age are trusted too, so donations seen again on every run do not each cost a call.
+func (s *Service) skipTracked(
+	ctx context.Context,
+	donation fundraiseup.Donation,
+	record storage.DonationRecord,
+	result *DonationResult,
+) bool {
+	result.GiftID = record.GiftID
+
+	if !record.ExcludedAt.IsZero() {
+		s.logger.Info("donation excluded after its gift was deleted, skipping",
+			"donation_id", donation.ID,
+			"gift_id", record.GiftID,
+			"excluded_at", record.ExcludedAt)
+		result.Excluded = true
+		return true
+	}
+
+	deleted := false
+	if reader, ok := s.blackbaud.(GiftReader); ok && s.checksDeletedGift(record) {
+		start := time.Now()
+		_, err := reader.Gift(ctx, record.GiftID)
+		s.metrics.GiftChecks.observe(start)
+		switch {
+		case blackbaud.IsNotFound(err):
+			deleted = true
+		case err != nil:
+			result.Error = fmt.Errorf("checking tracked gift %s: %w", record.GiftID, err)
+			return true
+		}
+	}
+
+	if !deleted {
+		s.logger.Info("donation already tracked, skipping",
+			"donation_id", donation.ID,
+			"gift_id", record.GiftID)
+		result.DuplicateFoundBy = DuplicateTracker
+		result.GiftSkippedExisting = true
+		return true
+	}
+
+	s.logger.Warn("tracked gift was deleted in Blackbaud",
+		"donation_id", donation.ID,
+		"gift_id", record.GiftID,
+		"policy", s.deletedGiftPolicy)
+	result.GiftDeleted = true
+
+	switch s.deletedGiftPolicy {
+	case config.DeletedGiftPolicyRecreate:
+		result.GiftID = ""
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("gift %s was deleted in Raiser's Edge NXT and is being created again", record.GiftID))
+		return false
+	case config.DeletedGiftPolicyExclude:
+		err := s.excludeDonation(ctx, record)
+		if storage.IsAlreadyTracked(err) {
+			// A concurrent run tracked a new gift for the donation, so there is nothing to exclude.
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"gift %s was deleted in Raiser's Edge NXT, and the donation is now tracked with another gift",
+				record.GiftID))
+			return true
+		}
+		if err != nil {
+			result.Error = err
+			return true
+		}
+		result.Excluded = true
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("gift %s was deleted in Raiser's Edge NXT, so the donation will no longer be synced",
+				record.GiftID))
+	default:
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("gift %s was deleted in Raiser's Edge NXT and was not created again", record.GiftID))
+	}
+	return true
+}
+
+// checksDeletedGift reports whether a tracked record's gift should be checked for deletion:
+// a deleted gift policy is set, and the gift was tracked within the check age, if there is one.
+// Records without a tracked time are only checked when there is no check age.
+func (s *Service) checksDeletedGift(record storage.DonationRecord) bool {
+	if s.deletedGiftPolicy == "" {
+		return false
+	}
+	if s.deletedGiftCheckAge <= 0 {
+		return true
+	}
+	return !record.TrackedAt.IsZero() && time.Since(record.TrackedAt) <= s.deletedGiftCheckAge
+}
+
+// excludeDonation marks a tracked donation as excluded, so later runs skip it without checking Blackbaud.
+func (s *Service) excludeDonation(ctx context.Context, record storage.DonationRecord) error {
+	if s.dryRun {
+		return nil
+	}
+
+	// Write buffered records first, so a buffered record for this donation cannot overwrite the exclusion.
+	s.flushTracked(ctx)
+
+	record.ExcludedAt = time.Now()
+
+	var err error
+	if record.RecurringID != "" {
+		err = s.tracker.TrackRecurring(ctx, record)
+	} else {
+		err = s.tracker.Track(ctx, record)
+	}
+	if err != nil {
+		return fmt.Errorf("excluding donation: %w", err)
+	}
+	return nil
+}
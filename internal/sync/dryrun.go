@@ -2,18 +2,22 @@ package sync
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
-	"sync/atomic"
+	"strings"
 
 	"github.com/peteski22/giftbridge/internal/blackbaud"
 )
 
 // dryRunClient wraps a BlackbaudClient and logs write operations instead of executing them.
+// The fake IDs it returns are derived from the records they stand for, so two dry runs over the same donations log
+// the same IDs and can be compared to spot mapping changes.
 type dryRunClient struct {
-	client  BlackbaudClient
-	logger  *slog.Logger
-	counter uint64
+	client BlackbaudClient
+	logger *slog.Logger
 }
 
 // newDryRunClient creates a new dryRunClient that wraps the given BlackbaudClient.
@@ -24,32 +28,123 @@ func newDryRunClient(client BlackbaudClient, logger *slog.Logger) *dryRunClient
 	}
 }
 
+// ConstituentAppeals delegates to the real client, if it can record appeal responses.
+func (d *dryRunClient) ConstituentAppeals(
+	ctx context.Context,
+	constituentID string,
+) ([]blackbaud.ConstituentAppeal, error) {
+	responder, ok := d.client.(AppealResponder)
+	if !ok {
+		return nil, errors.New("blackbaud client cannot record appeal responses")
+	}
+	return responder.ConstituentAppeals(ctx, constituentID)
+}
+
 // CreateConstituent logs what would be created and returns a fake ID.
 // Note: We intentionally log constituent details (name, email) in dry-run mode because:
 // 1. This output goes only to the user's local terminal, not to any logging service.
 // 2. The user needs to verify the correct data would be synced from their own FundraiseUp account.
 // 3. Dry-run mode is for local development/testing, not production use.
 func (d *dryRunClient) CreateConstituent(ctx context.Context, constituent *blackbaud.Constituent) (string, error) {
-	fakeID := d.nextFakeID("constituent")
-
 	email := ""
 	if constituent.Email != nil {
 		email = constituent.Email.Address
 	}
+	fakeID := dryRunID("constituent", strings.ToLower(email), constituent.FirstName, constituent.LastName)
+	var addressee, salutation string
+	if constituent.PrimaryAddressee != nil {
+		addressee = constituent.PrimaryAddressee.FormattedName
+	}
+	if constituent.PrimarySalutation != nil {
+		salutation = constituent.PrimarySalutation.FormattedName
+	}
 
 	d.logger.Info("[DRY-RUN] would create constituent",
 		"fake_id", fakeID,
 		"first_name", constituent.FirstName,
 		"last_name", constituent.LastName,
 		"email", email,
+		"addressee", addressee,
+		"salutation", salutation,
 		"type", constituent.Type)
 
 	return fakeID, nil
 }
 
+// CreateConstituentAppeal logs what would be created and returns a fake ID.
+func (d *dryRunClient) CreateConstituentAppeal(
+	ctx context.Context,
+	appeal *blackbaud.ConstituentAppeal,
+) (string, error) {
+	fakeID := dryRunID("constituent-appeal", appeal.ConstituentID, appeal.AppealID)
+
+	d.logger.Info("[DRY-RUN] would record appeal response",
+		"fake_id", fakeID,
+		"constituent_id", appeal.ConstituentID,
+		"appeal_id", appeal.AppealID,
+		"date", appeal.Date)
+
+	return fakeID, nil
+}
+
+// CreateConstituentCode logs what would be created and returns a fake ID.
+func (d *dryRunClient) CreateConstituentCode(ctx context.Context, code *blackbaud.ConstituentCode) (string, error) {
+	fakeID := dryRunID("constituent-code", code.ConstituentID, code.Description)
+
+	d.logger.Info("[DRY-RUN] would create constituent code",
+		"fake_id", fakeID,
+		"constituent_id", code.ConstituentID,
+		"description", code.Description)
+
+	return fakeID, nil
+}
+
+// CreateConstituentNote logs what would be created and returns a fake ID.
+func (d *dryRunClient) CreateConstituentNote(ctx context.Context, note *blackbaud.ConstituentNote) (string, error) {
+	fakeID := dryRunID("constituent-note", note.ConstituentID, note.Type, note.Summary, note.Text)
+
+	d.logger.Info("[DRY-RUN] would add constituent note",
+		"fake_id", fakeID,
+		"constituent_id", note.ConstituentID,
+		"type", note.Type,
+		"summary", note.Summary,
+		"text", note.Text)
+
+	return fakeID, nil
+}
+
+// CreateEmailAddress logs what would be created and returns a fake ID.
+func (d *dryRunClient) CreateEmailAddress(ctx context.Context, email *blackbaud.EmailAddress) (string, error) {
+	fakeID := dryRunID("email-address", email.ConstituentID, strings.ToLower(email.Address))
+
+	d.logger.Info("[DRY-RUN] would add email address",
+		"fake_id", fakeID,
+		"constituent_id", email.ConstituentID,
+		"address", email.Address)
+
+	return fakeID, nil
+}
+
+// CreateEventParticipant logs what would be created and returns a fake ID.
+func (d *dryRunClient) CreateEventParticipant(
+	ctx context.Context,
+	eventID string,
+	participant *blackbaud.Participant,
+) (string, error) {
+	fakeID := dryRunID("participant", eventID, participant.ConstituentID)
+
+	d.logger.Info("[DRY-RUN] would add event participant",
+		"fake_id", fakeID,
+		"event_id", eventID,
+		"constituent_id", participant.ConstituentID,
+		"rsvp_status", participant.RSVPStatus)
+
+	return fakeID, nil
+}
+
 // CreateGift logs what would be created and returns a fake ID.
 func (d *dryRunClient) CreateGift(ctx context.Context, gift *blackbaud.Gift) (string, error) {
-	fakeID := d.nextFakeID("gift")
+	fakeID := dryRunGiftID(gift)
 
 	amount := 0.0
 	if gift.Amount != nil {
@@ -67,6 +162,51 @@ func (d *dryRunClient) CreateGift(ctx context.Context, gift *blackbaud.Gift) (st
 	return fakeID, nil
 }
 
+// CreateGiftCustomField logs what would be created and returns a fake ID.
+func (d *dryRunClient) CreateGiftCustomField(ctx context.Context, field *blackbaud.GiftCustomField) (string, error) {
+	fakeID := dryRunID("gift-custom-field", field.ParentID, field.Category)
+
+	d.logger.Info("[DRY-RUN] would add gift custom field",
+		"fake_id", fakeID,
+		"gift_id", field.ParentID,
+		"category", field.Category,
+		"value", field.Value)
+
+	return fakeID, nil
+}
+
+// CreateGiftNote logs what would be created and returns a fake ID.
+func (d *dryRunClient) CreateGiftNote(ctx context.Context, note *blackbaud.GiftNote) (string, error) {
+	fakeID := dryRunID("gift-note", note.GiftID, note.Type, note.Summary)
+
+	d.logger.Info("[DRY-RUN] would add gift note",
+		"fake_id", fakeID,
+		"gift_id", note.GiftID,
+		"type", note.Type,
+		"summary", note.Summary,
+		"text", note.Text)
+
+	return fakeID, nil
+}
+
+// EventParticipants delegates to the real client, if it can add event participants.
+func (d *dryRunClient) EventParticipants(ctx context.Context, eventID string) ([]blackbaud.Participant, error) {
+	registrar, ok := d.client.(EventRegistrar)
+	if !ok {
+		return nil, errors.New("blackbaud client cannot add event participants")
+	}
+	return registrar.EventParticipants(ctx, eventID)
+}
+
+// Gift delegates to the real client, if it can read gifts.
+func (d *dryRunClient) Gift(ctx context.Context, giftID string) (*blackbaud.Gift, error) {
+	reader, ok := d.client.(GiftReader)
+	if !ok {
+		return nil, errors.New("blackbaud client cannot read gifts")
+	}
+	return reader.Gift(ctx, giftID)
+}
+
 // ListGiftsByConstituent delegates to the real client.
 func (d *dryRunClient) ListGiftsByConstituent(
 	ctx context.Context,
@@ -76,9 +216,22 @@ func (d *dryRunClient) ListGiftsByConstituent(
 	return d.client.ListGiftsByConstituent(ctx, constituentID, giftTypes)
 }
 
+// Quota delegates to the real client, if it reports a quota.
+func (d *dryRunClient) Quota() (blackbaud.Quota, bool) {
+	reporter, ok := d.client.(QuotaReporter)
+	if !ok {
+		return blackbaud.Quota{}, false
+	}
+	return reporter.Quota()
+}
+
 // SearchConstituents delegates to the real client.
-func (d *dryRunClient) SearchConstituents(ctx context.Context, email string) ([]blackbaud.Constituent, error) {
-	return d.client.SearchConstituents(ctx, email)
+func (d *dryRunClient) SearchConstituents(
+	ctx context.Context,
+	email string,
+	opts blackbaud.SearchOptions,
+) ([]blackbaud.Constituent, error) {
+	return d.client.SearchConstituents(ctx, email, opts)
 }
 
 // UpdateGift logs what would be updated and returns nil.
@@ -92,13 +245,22 @@ func (d *dryRunClient) UpdateGift(ctx context.Context, giftID string, gift *blac
 		"gift_id", giftID,
 		"amount", amount,
 		"type", gift.Type,
-		"lookup_id", gift.LookupID)
+		"lookup_id", gift.LookupID,
+		"gift_status", gift.GiftStatus)
 
 	return nil
 }
 
-// nextFakeID generates a unique fake ID for dry-run operations.
-func (d *dryRunClient) nextFakeID(prefix string) string {
-	n := atomic.AddUint64(&d.counter, 1)
-	return fmt.Sprintf("dry-run-%s-%d", prefix, n)
+// dryRunID returns the dry-run ID of a record of the given kind, derived from the values identifying it.
+func dryRunID(kind string, key ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(key, "\x00")))
+	return fmt.Sprintf("dry-run-%s-%s", kind, hex.EncodeToString(sum[:6]))
+}
+
+// dryRunGiftID returns the dry-run ID of a gift, naming the donation by its lookup ID where the gift has one.
+func dryRunGiftID(gift *blackbaud.Gift) string {
+	if gift.LookupID != "" {
+		return "dry-run-gift-" + gift.LookupID
+	}
+	return dryRunID("gift", gift.Origin, gift.ConstituentID, gift.Date)
 }
@@ -0,0 +1,40 @@
+package sync
+
+import (
+	"context"
+	"strings"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+)
+
+// emailType is the email type given to supporter emails added to Raiser's Edge NXT.
+const emailType = "Email"
+
+// addMissingEmail adds the supporter's email, as they typed it, to a matched constituent with no email on file,
+// so later donations match the constituent by email. The non-strict search can match a constituent by name or
+// partial text alone. A failure is logged rather than failing the donation: the constituent still lacks an email,
+// so it is tried again when the supporter next gives.
+func (s *Service) addMissingEmail(ctx context.Context, constituent *blackbaud.Constituent, address string) {
+	if !s.emailNormalization.AddMissing {
+		return
+	}
+	if constituent.Email != nil && constituent.Email.Address != "" {
+		return
+	}
+	adder, ok := s.blackbaud.(EmailAdder)
+	if !ok {
+		return
+	}
+
+	email := &blackbaud.EmailAddress{
+		Address:       strings.TrimSpace(address),
+		ConstituentID: constituent.ID,
+		Primary:       true,
+		Type:          emailType,
+	}
+	if _, err := adder.CreateEmailAddress(ctx, email); err != nil {
+		s.logger.Warn("failed to add email to constituent", "constituent_id", constituent.ID, "error", err)
+		return
+	}
+	s.logger.Info("added email to constituent without one", "constituent_id", constituent.ID)
+}
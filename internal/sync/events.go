@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// registerEventParticipant adds the constituent as an attending participant of the Raiser's Edge NXT event linked
+// to the FundraiseUp event a donation bought a ticket for, unless they already take part. Tickets for events that
+// are not linked are ignored. The participants of each event are listed once a run.
+// A participant that cannot be added does not fail the donation, as the gift already exists;
+// it is returned as a warning so the registration can be added by hand.
+func (s *Service) registerEventParticipant(
+	ctx context.Context,
+	constituentID string,
+	donation fundraiseup.Donation,
+) []string {
+	if donation.Ticket == nil {
+		return nil
+	}
+	eventID, ok := s.eventLinks[donation.Ticket.EventID]
+	if !ok {
+		return nil
+	}
+	registrar, ok := s.blackbaud.(EventRegistrar)
+	if !ok {
+		return nil
+	}
+
+	if s.eventParticipants == nil {
+		s.eventParticipants = make(map[string]map[string]bool)
+	}
+	participants, listed := s.eventParticipants[eventID]
+	if !listed {
+		existing, err := registrar.EventParticipants(ctx, eventID)
+		if err != nil {
+			return []string{fmt.Sprintf("listing participants of event %q: %v", eventID, err)}
+		}
+		participants = make(map[string]bool, len(existing))
+		for _, participant := range existing {
+			participants[participant.ConstituentID] = true
+		}
+		s.eventParticipants[eventID] = participants
+	}
+	if participants[constituentID] {
+		return nil
+	}
+
+	participant := &blackbaud.Participant{
+		ConstituentID: constituentID,
+		RSVPStatus:    blackbaud.RSVPStatusAttending,
+	}
+	if _, err := registrar.CreateEventParticipant(ctx, eventID, participant); err != nil {
+		return []string{fmt.Sprintf("adding constituent to event %q: %v", eventID, err)}
+	}
+	participants[constituentID] = true
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// DonationSource is the FundraiseUp API donations are synced from. A *fundraiseup.Client reads a single account,
+// and *fundraiseup.Accounts merges several.
+type DonationSource interface {
+	// Donation fetches a single donation by ID.
+	Donation(ctx context.Context, id string) (*fundraiseup.Donation, error)
+
+	// DonationPages calls fn with each page of donations created after since, oldest first,
+	// starting after the donation with ID startingAfter (or from the beginning of the window when empty).
+	DonationPages(ctx context.Context, since time.Time, startingAfter string, fn func([]fundraiseup.Donation) error) error
+
+	// EventPages calls fn with each page of events raised after since, oldest first,
+	// starting after the event with ID startingAfter (or from the beginning of the window when empty).
+	EventPages(ctx context.Context, since time.Time, startingAfter string, fn func([]fundraiseup.Event) error) error
+
+	// UnknownFields returns the paths of payload fields seen that the mapper does not decode, in sorted order.
+	UnknownFields() []string
+}
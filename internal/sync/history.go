@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// errorCategory groups a donation error for the run history: Blackbaud errors by status code,
+// timeouts, network failures, panics, failed gift checks, and everything else as "other".
+func errorCategory(err error) string {
+	var statusErr *blackbaud.StatusError
+	var netErr net.Error
+	var panicErr *PanicError
+	var checkErr *GiftCheckError
+	var dateErr *GiftDateError
+	switch {
+	case errors.As(err, &panicErr):
+		return "panic"
+	case errors.As(err, &checkErr):
+		return "gift_check"
+	case errors.As(err, &dateErr):
+		return "gift_date"
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("blackbaud_%d", statusErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "network"
+	default:
+		return "other"
+	}
+}
+
+// recordRun adds a summary of the run to the state store's run history, when it keeps one.
+// Dry runs are not recorded, since they change nothing. Failing to record is logged rather than failing the run.
+func (s *Service) recordRun(ctx context.Context, start time.Time, result *Result, runErr error) {
+	if s.runRecorder == nil || s.dryRun {
+		return
+	}
+
+	summary := storage.RunSummary{
+		Duration:  time.Since(start),
+		StartedAt: start.UTC(),
+	}
+	if runErr != nil {
+		summary.Failure = runErr.Error()
+	}
+	if result != nil {
+		summary.ConstituentsCreated = result.ConstituentsCreated
+		summary.DonationsProcessed = result.DonationsProcessed
+		summary.Errors = len(result.Errors)
+		summary.GiftsChargedBack = result.GiftsChargedBack
+		summary.GiftsCreated = result.GiftsCreated
+		summary.GiftsSkippedExisting = result.GiftsSkippedExisting
+		summary.GiftsSkippedExistingBy = result.GiftsSkippedExistingBy
+		summary.GiftsUpdated = result.GiftsUpdated
+		summary.Interrupted = result.Interrupted
+		summary.PausedForQuota = result.PausedForQuota
+		summary.StoppedOnError = result.StoppedOnError
+		for _, err := range result.Errors {
+			if summary.ErrorCategories == nil {
+				summary.ErrorCategories = make(map[string]int)
+			}
+			summary.ErrorCategories[errorCategory(err)]++
+		}
+	}
+
+	// Record interrupted runs too, since those are the ones support staff look for.
+	if err := s.runRecorder.RecordRun(context.WithoutCancel(ctx), summary); err != nil {
+		s.logger.Warn("failed to record run history", "error", err)
+	}
+}
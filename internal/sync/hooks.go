@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// Hook customises the records a sync creates in Blackbaud, so deployments can compile in their own
+// transformations, such as stamping custom values, without changing the service.
+// Embed NopHook to implement only the methods needed.
+type Hook interface {
+	// BeforeConstituentCreate is called with a new constituent before it is created, and may change it.
+	// Returning an error fails the donation without creating the constituent.
+	BeforeConstituentCreate(
+		ctx context.Context,
+		donation fundraiseup.Donation,
+		constituent *blackbaud.Constituent,
+	) error
+
+	// BeforeGiftCreate is called with a new gift before it is created, and may change it.
+	// Returning an error fails the donation without creating the gift.
+	BeforeGiftCreate(ctx context.Context, donation fundraiseup.Donation, gift *blackbaud.Gift) error
+
+	// AfterGiftCreate is called once a gift has been created. It is not called in dry-run mode.
+	// The gift already exists, so a returned error is reported as a warning on the donation.
+	AfterGiftCreate(ctx context.Context, donation fundraiseup.Donation, giftID string, gift *blackbaud.Gift) error
+}
+
+// NopHook implements Hook with methods that do nothing.
+type NopHook struct{}
+
+// AfterGiftCreate does nothing.
+func (NopHook) AfterGiftCreate(context.Context, fundraiseup.Donation, string, *blackbaud.Gift) error {
+	return nil
+}
+
+// BeforeConstituentCreate does nothing.
+func (NopHook) BeforeConstituentCreate(context.Context, fundraiseup.Donation, *blackbaud.Constituent) error {
+	return nil
+}
+
+// BeforeGiftCreate does nothing.
+func (NopHook) BeforeGiftCreate(context.Context, fundraiseup.Donation, *blackbaud.Gift) error {
+	return nil
+}
+
+// afterGiftCreate runs each hook's AfterGiftCreate in order, returning their errors as warnings.
+func (s *Service) afterGiftCreate(
+	ctx context.Context,
+	donation fundraiseup.Donation,
+	giftID string,
+	gift *blackbaud.Gift,
+) []string {
+	if s.dryRun {
+		return nil
+	}
+
+	var warnings []string
+	for _, hook := range s.hooks {
+		if err := hook.AfterGiftCreate(ctx, donation, giftID, gift); err != nil {
+			warnings = append(warnings, fmt.Sprintf("after gift create hook: %v", err))
+		}
+	}
+	return warnings
+}
+
+// beforeConstituentCreate runs each hook's BeforeConstituentCreate in order, stopping at the first error.
+func (s *Service) beforeConstituentCreate(
+	ctx context.Context,
+	donation fundraiseup.Donation,
+	constituent *blackbaud.Constituent,
+) error {
+	for _, hook := range s.hooks {
+		if err := hook.BeforeConstituentCreate(ctx, donation, constituent); err != nil {
+			return fmt.Errorf("before constituent create hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// beforeGiftCreate runs each hook's BeforeGiftCreate in order, stopping at the first error.
+func (s *Service) beforeGiftCreate(ctx context.Context, donation fundraiseup.Donation, gift *blackbaud.Gift) error {
+	for _, hook := range s.hooks {
+		if err := hook.BeforeGiftCreate(ctx, donation, gift); err != nil {
+			return fmt.Errorf("before gift create hook: %w", err)
+		}
+	}
+	return nil
+}
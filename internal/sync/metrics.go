@@ -0,0 +1,428 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/lru"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// CallMetrics counts the calls made to a dependency and the time spent waiting on them.
+type CallMetrics struct {
+	// Calls is the number of calls made.
+	Calls int `json:"calls"`
+
+	// Duration is the total time spent in those calls.
+	Duration time.Duration `json:"duration"`
+}
+
+// Metrics breaks down where a sync spent its time, so a slow run can be traced to
+// FundraiseUp, Blackbaud or the state store.
+type Metrics struct {
+	// Blackbaud covers calls to the Blackbaud SKY API. Writes simulated in dry-run mode are not counted.
+	Blackbaud CallMetrics `json:"blackbaud"`
+
+	// FetchDuration is the time spent fetching donation pages from FundraiseUp.
+	FetchDuration time.Duration `json:"fetchDuration"`
+
+	// FundraiseUp covers calls to the FundraiseUp API: donation pages and single donations fetched on resume.
+	FundraiseUp CallMetrics `json:"fundraiseup"`
+
+	// GiftCache counts lookups of constituents' gifts answered from the run's cache, and constituents evicted
+	// from it once full.
+	GiftCache lru.Stats `json:"giftCache"`
+
+	// GiftChecks covers the Blackbaud gift reads checking that tracked gifts were not deleted.
+	// They are also counted in Blackbaud.
+	GiftChecks CallMetrics `json:"giftChecks"`
+
+	// ProcessDuration is the total time spent processing donations, including the Blackbaud and tracker calls made.
+	ProcessDuration time.Duration `json:"processDuration"`
+
+	// StateStore covers reads and writes of the sync state.
+	StateStore CallMetrics `json:"stateStore"`
+
+	// TotalDuration is the wall-clock time of the whole run.
+	TotalDuration time.Duration `json:"totalDuration"`
+
+	// Tracker covers donation tracker lookups and writes.
+	Tracker CallMetrics `json:"tracker"`
+}
+
+// AverageDonationDuration returns the mean time spent processing each donation, or zero if none were processed.
+func (r *Result) AverageDonationDuration() time.Duration {
+	if r.DonationsProcessed == 0 {
+		return 0
+	}
+	return r.Metrics.ProcessDuration / time.Duration(r.DonationsProcessed)
+}
+
+// observe records a call that started at start.
+func (c *CallMetrics) observe(start time.Time) {
+	c.Calls++
+	c.Duration += time.Since(start)
+}
+
+// logMetrics logs the timing breakdown of the run.
+func (s *Service) logMetrics(result *Result) {
+	m := result.Metrics
+
+	s.logger.Info("sync metrics",
+		"total_duration", m.TotalDuration,
+		"fetch_duration", m.FetchDuration,
+		"process_duration", m.ProcessDuration,
+		"avg_donation_duration", result.AverageDonationDuration(),
+		"fundraiseup_calls", m.FundraiseUp.Calls,
+		"fundraiseup_duration", m.FundraiseUp.Duration,
+		"blackbaud_calls", m.Blackbaud.Calls,
+		"blackbaud_duration", m.Blackbaud.Duration,
+		"gift_cache_hits", m.GiftCache.Hits,
+		"gift_cache_misses", m.GiftCache.Misses,
+		"gift_cache_evictions", m.GiftCache.Evictions,
+		"gift_check_calls", m.GiftChecks.Calls,
+		"gift_check_duration", m.GiftChecks.Duration,
+		"state_store_calls", m.StateStore.Calls,
+		"state_store_duration", m.StateStore.Duration,
+		"tracker_calls", m.Tracker.Calls,
+		"tracker_duration", m.Tracker.Duration)
+}
+
+// timedBlackbaudClient wraps a BlackbaudClient and records the calls made through it.
+type timedBlackbaudClient struct {
+	client  BlackbaudClient
+	metrics *CallMetrics
+}
+
+// ConstituentAppeals delegates to the wrapped client, if it can record appeal responses.
+func (t *timedBlackbaudClient) ConstituentAppeals(
+	ctx context.Context,
+	constituentID string,
+) ([]blackbaud.ConstituentAppeal, error) {
+	responder, ok := t.client.(AppealResponder)
+	if !ok {
+		return nil, errors.New("blackbaud client cannot record appeal responses")
+	}
+	defer t.metrics.observe(time.Now())
+	return responder.ConstituentAppeals(ctx, constituentID)
+}
+
+// CreateConstituent delegates to the wrapped client.
+func (t *timedBlackbaudClient) CreateConstituent(
+	ctx context.Context,
+	constituent *blackbaud.Constituent,
+) (string, error) {
+	defer t.metrics.observe(time.Now())
+	return t.client.CreateConstituent(ctx, constituent)
+}
+
+// CreateConstituentAppeal delegates to the wrapped client, if it can record appeal responses.
+func (t *timedBlackbaudClient) CreateConstituentAppeal(
+	ctx context.Context,
+	appeal *blackbaud.ConstituentAppeal,
+) (string, error) {
+	responder, ok := t.client.(AppealResponder)
+	if !ok {
+		return "", errors.New("blackbaud client cannot record appeal responses")
+	}
+	defer t.metrics.observe(time.Now())
+	return responder.CreateConstituentAppeal(ctx, appeal)
+}
+
+// CreateConstituentCode delegates to the wrapped client.
+func (t *timedBlackbaudClient) CreateConstituentCode(
+	ctx context.Context,
+	code *blackbaud.ConstituentCode,
+) (string, error) {
+	defer t.metrics.observe(time.Now())
+	return t.client.CreateConstituentCode(ctx, code)
+}
+
+// CreateConstituentNote delegates to the wrapped client, if it can add constituent notes.
+func (t *timedBlackbaudClient) CreateConstituentNote(
+	ctx context.Context,
+	note *blackbaud.ConstituentNote,
+) (string, error) {
+	creator, ok := t.client.(NoteCreator)
+	if !ok {
+		return "", errors.New("blackbaud client cannot add constituent notes")
+	}
+	defer t.metrics.observe(time.Now())
+	return creator.CreateConstituentNote(ctx, note)
+}
+
+// CreateEmailAddress delegates to the wrapped client, if it can add email addresses.
+func (t *timedBlackbaudClient) CreateEmailAddress(ctx context.Context, email *blackbaud.EmailAddress) (string, error) {
+	adder, ok := t.client.(EmailAdder)
+	if !ok {
+		return "", errors.New("blackbaud client cannot add email addresses")
+	}
+	defer t.metrics.observe(time.Now())
+	return adder.CreateEmailAddress(ctx, email)
+}
+
+// CreateEventParticipant delegates to the wrapped client, if it can add event participants.
+func (t *timedBlackbaudClient) CreateEventParticipant(
+	ctx context.Context,
+	eventID string,
+	participant *blackbaud.Participant,
+) (string, error) {
+	registrar, ok := t.client.(EventRegistrar)
+	if !ok {
+		return "", errors.New("blackbaud client cannot add event participants")
+	}
+	defer t.metrics.observe(time.Now())
+	return registrar.CreateEventParticipant(ctx, eventID, participant)
+}
+
+// CreateGift delegates to the wrapped client.
+func (t *timedBlackbaudClient) CreateGift(ctx context.Context, gift *blackbaud.Gift) (string, error) {
+	defer t.metrics.observe(time.Now())
+	return t.client.CreateGift(ctx, gift)
+}
+
+// CreateGiftCustomField delegates to the wrapped client, if it can add gift custom fields.
+func (t *timedBlackbaudClient) CreateGiftCustomField(
+	ctx context.Context,
+	field *blackbaud.GiftCustomField,
+) (string, error) {
+	creator, ok := t.client.(GiftCustomFieldCreator)
+	if !ok {
+		return "", errors.New("blackbaud client cannot add gift custom fields")
+	}
+	defer t.metrics.observe(time.Now())
+	return creator.CreateGiftCustomField(ctx, field)
+}
+
+// CreateGiftNote delegates to the wrapped client, if it can add gift notes.
+func (t *timedBlackbaudClient) CreateGiftNote(ctx context.Context, note *blackbaud.GiftNote) (string, error) {
+	creator, ok := t.client.(GiftNoteCreator)
+	if !ok {
+		return "", errors.New("blackbaud client cannot add gift notes")
+	}
+	defer t.metrics.observe(time.Now())
+	return creator.CreateGiftNote(ctx, note)
+}
+
+// EventParticipants delegates to the wrapped client, if it can add event participants.
+func (t *timedBlackbaudClient) EventParticipants(ctx context.Context, eventID string) ([]blackbaud.Participant, error) {
+	registrar, ok := t.client.(EventRegistrar)
+	if !ok {
+		return nil, errors.New("blackbaud client cannot add event participants")
+	}
+	defer t.metrics.observe(time.Now())
+	return registrar.EventParticipants(ctx, eventID)
+}
+
+// Gift delegates to the wrapped client, if it can read gifts.
+func (t *timedBlackbaudClient) Gift(ctx context.Context, giftID string) (*blackbaud.Gift, error) {
+	reader, ok := t.client.(GiftReader)
+	if !ok {
+		return nil, errors.New("blackbaud client cannot read gifts")
+	}
+	defer t.metrics.observe(time.Now())
+	return reader.Gift(ctx, giftID)
+}
+
+// ListGiftsByConstituent delegates to the wrapped client.
+func (t *timedBlackbaudClient) ListGiftsByConstituent(
+	ctx context.Context,
+	constituentID string,
+	giftTypes []blackbaud.GiftType,
+) ([]blackbaud.Gift, error) {
+	defer t.metrics.observe(time.Now())
+	return t.client.ListGiftsByConstituent(ctx, constituentID, giftTypes)
+}
+
+// Quota delegates to the wrapped client, if it reports a quota.
+func (t *timedBlackbaudClient) Quota() (blackbaud.Quota, bool) {
+	reporter, ok := t.client.(QuotaReporter)
+	if !ok {
+		return blackbaud.Quota{}, false
+	}
+	return reporter.Quota()
+}
+
+// SearchConstituents delegates to the wrapped client.
+func (t *timedBlackbaudClient) SearchConstituents(
+	ctx context.Context,
+	email string,
+	opts blackbaud.SearchOptions,
+) ([]blackbaud.Constituent, error) {
+	defer t.metrics.observe(time.Now())
+	return t.client.SearchConstituents(ctx, email, opts)
+}
+
+// UpdateGift delegates to the wrapped client.
+func (t *timedBlackbaudClient) UpdateGift(ctx context.Context, giftID string, gift *blackbaud.Gift) error {
+	defer t.metrics.observe(time.Now())
+	return t.client.UpdateGift(ctx, giftID, gift)
+}
+
+// timedPendingStore wraps a state store that keeps pending donations and records the calls made through it.
+type timedPendingStore struct {
+	timedStateStore
+
+	pending PendingStore
+}
+
+// FetchState delegates to the wrapped store.
+func (t *timedPendingStore) FetchState(ctx context.Context) (*storage.FetchState, error) {
+	defer t.metrics.observe(time.Now())
+	return t.pending.FetchState(ctx)
+}
+
+// PendingDonationIDs delegates to the wrapped store.
+func (t *timedPendingStore) PendingDonationIDs(ctx context.Context) ([]string, error) {
+	defer t.metrics.observe(time.Now())
+	return t.pending.PendingDonationIDs(ctx)
+}
+
+// RemovePendingDonationID delegates to the wrapped store.
+func (t *timedPendingStore) RemovePendingDonationID(ctx context.Context, id string) error {
+	defer t.metrics.observe(time.Now())
+	return t.pending.RemovePendingDonationID(ctx, id)
+}
+
+// SetFetchState delegates to the wrapped store.
+func (t *timedPendingStore) SetFetchState(ctx context.Context, state *storage.FetchState) error {
+	defer t.metrics.observe(time.Now())
+	return t.pending.SetFetchState(ctx, state)
+}
+
+// SetPendingDonationIDs delegates to the wrapped store.
+func (t *timedPendingStore) SetPendingDonationIDs(ctx context.Context, ids []string) error {
+	defer t.metrics.observe(time.Now())
+	return t.pending.SetPendingDonationIDs(ctx, ids)
+}
+
+// timedRetryStore wraps a RetryStore and records the calls made through it.
+type timedRetryStore struct {
+	metrics *CallMetrics
+	store   RetryStore
+}
+
+// RetrySchedule delegates to the wrapped store.
+func (t *timedRetryStore) RetrySchedule(ctx context.Context) (map[string]storage.RetryEntry, error) {
+	defer t.metrics.observe(time.Now())
+	return t.store.RetrySchedule(ctx)
+}
+
+// SetRetrySchedule delegates to the wrapped store.
+func (t *timedRetryStore) SetRetrySchedule(ctx context.Context, schedule map[string]storage.RetryEntry) error {
+	defer t.metrics.observe(time.Now())
+	return t.store.SetRetrySchedule(ctx, schedule)
+}
+
+// timedStateStore wraps a StateStore and records the calls made through it.
+type timedStateStore struct {
+	metrics *CallMetrics
+	store   StateStore
+}
+
+// LastSyncTime delegates to the wrapped store.
+func (t *timedStateStore) LastSyncTime(ctx context.Context) (time.Time, error) {
+	defer t.metrics.observe(time.Now())
+	return t.store.LastSyncTime(ctx)
+}
+
+// SetLastSyncTime delegates to the wrapped store.
+func (t *timedStateStore) SetLastSyncTime(ctx context.Context, syncTime time.Time) error {
+	defer t.metrics.observe(time.Now())
+	return t.store.SetLastSyncTime(ctx, syncTime)
+}
+
+// timedBatchTracker wraps a tracker that writes in batches and records the calls made through it.
+type timedBatchTracker struct {
+	timedTracker
+
+	batch BatchTracker
+}
+
+// TrackBatch delegates to the wrapped tracker.
+func (t *timedBatchTracker) TrackBatch(ctx context.Context, records []storage.DonationRecord) error {
+	defer t.metrics.observe(time.Now())
+	return t.batch.TrackBatch(ctx, records)
+}
+
+// timedTracker wraps a DonationTracker and records the calls made through it.
+type timedTracker struct {
+	metrics *CallMetrics
+	tracker DonationTracker
+}
+
+// Lookup delegates to the wrapped tracker.
+func (t *timedTracker) Lookup(ctx context.Context, donationID string) (*storage.DonationRecord, error) {
+	defer t.metrics.observe(time.Now())
+	return t.tracker.Lookup(ctx, donationID)
+}
+
+// CacheConstituent delegates to the wrapped tracker, if it can cache constituents.
+func (t *timedTracker) CacheConstituent(
+	ctx context.Context,
+	email string,
+	supporterID string,
+	constituentID string,
+	expiresAt time.Time,
+) error {
+	cache, ok := t.tracker.(SupporterCache)
+	if !ok {
+		return errors.New("donation tracker cannot cache constituents")
+	}
+	defer t.metrics.observe(time.Now())
+	return cache.CacheConstituent(ctx, email, supporterID, constituentID, expiresAt)
+}
+
+// CachedConstituent delegates to the wrapped tracker, if it can cache constituents.
+func (t *timedTracker) CachedConstituent(ctx context.Context, email string) (string, error) {
+	cache, ok := t.tracker.(SupporterCache)
+	if !ok {
+		return "", errors.New("donation tracker cannot cache constituents")
+	}
+	defer t.metrics.observe(time.Now())
+	return cache.CachedConstituent(ctx, email)
+}
+
+// ForgetConstituent delegates to the wrapped tracker, if it can cache constituents.
+func (t *timedTracker) ForgetConstituent(ctx context.Context, email string) error {
+	cache, ok := t.tracker.(SupporterCache)
+	if !ok {
+		return errors.New("donation tracker cannot cache constituents")
+	}
+	defer t.metrics.observe(time.Now())
+	return cache.ForgetConstituent(ctx, email)
+}
+
+// RecurringDonations delegates to the wrapped tracker, if it can list recurring donations.
+func (t *timedTracker) RecurringDonations(ctx context.Context, recurringID string) ([]storage.DonationRecord, error) {
+	history, ok := t.tracker.(RecurringHistory)
+	if !ok {
+		return nil, errors.New("donation tracker cannot list recurring donations")
+	}
+	defer t.metrics.observe(time.Now())
+	return history.RecurringDonations(ctx, recurringID)
+}
+
+// ReplaceGift delegates to the wrapped tracker, which New checks can replace gifts when the policy needs it.
+func (t *timedTracker) ReplaceGift(ctx context.Context, record storage.DonationRecord, previousGiftID string) error {
+	replacer, ok := t.tracker.(GiftReplacer)
+	if !ok {
+		return errors.New("donation tracker cannot replace gifts")
+	}
+	defer t.metrics.observe(time.Now())
+	return replacer.ReplaceGift(ctx, record, previousGiftID)
+}
+
+// Track delegates to the wrapped tracker.
+func (t *timedTracker) Track(ctx context.Context, record storage.DonationRecord) error {
+	defer t.metrics.observe(time.Now())
+	return t.tracker.Track(ctx, record)
+}
+
+// TrackRecurring delegates to the wrapped tracker.
+func (t *timedTracker) TrackRecurring(ctx context.Context, record storage.DonationRecord) error {
+	defer t.metrics.observe(time.Now())
+	return t.tracker.TrackRecurring(ctx, record)
+}
@@ -0,0 +1,342 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// referenceEllipsis ends a reference truncated to fit the gift, showing that text is missing.
+const referenceEllipsis = "…"
+
+// defaultDonationNoteFormat summarises the donation when no donation note format is configured.
+const defaultDonationNoteFormat = `Online donation {{.DonationID}} of {{.Amount}} {{.Currency}}` +
+	`{{with .Frequency}} ({{.}}){{end}}{{with .Campaign}} to the {{.}} campaign{{end}}.
+{{with .UTMSource}}Source: {{.}}{{with $.UTMMedium}} / {{.}}{{end}}{{with $.UTMCampaign}} / {{.}}{{end}}
+{{end}}{{with .Device}}Device: {{.}}
+{{end}}{{with .Comment}}Comment: {{.}}{{end}}`
+
+// DonationNoteFields are the fields a donation note format can refer to, such as {{.Campaign}}.
+// Fields the donation does not have are empty.
+type DonationNoteFields struct {
+	// Amount is the donation amount as a decimal string.
+	Amount string
+
+	// Campaign is the name of the FundraiseUp campaign.
+	Campaign string
+
+	// Comment is the donor's comment, after comment scrubbing.
+	Comment string
+
+	// Currency is the three-letter currency code.
+	Currency string
+
+	// Designation is the name of the FundraiseUp designation.
+	Designation string
+
+	// Device is the kind of device the donation was made on, such as "mobile".
+	Device string
+
+	// DonationID is the FundraiseUp donation identifier.
+	DonationID string
+
+	// Frequency is how often a recurring donation is made, such as "monthly".
+	Frequency string
+
+	// PaymentMethod is the payment method, such as "credit_card".
+	PaymentMethod string
+
+	// UTMCampaign is the utm_campaign parameter of the page the donation was made on.
+	UTMCampaign string
+
+	// UTMContent is the utm_content parameter of the page the donation was made on.
+	UTMContent string
+
+	// UTMMedium is the utm_medium parameter of the page the donation was made on.
+	UTMMedium string
+
+	// UTMSource is the utm_source parameter of the page the donation was made on.
+	UTMSource string
+
+	// UTMTerm is the utm_term parameter of the page the donation was made on.
+	UTMTerm string
+}
+
+// parseDonationNote parses the donation note format, or the default format when none is set.
+// It returns nil when donation notes are disabled. Fields that DonationNoteFields does not have are reported here,
+// rather than when the first note is written.
+func parseDonationNote(defaults config.ConstituentDefaults) (*template.Template, error) {
+	if defaults.DonationNoteType == "" {
+		return nil, nil
+	}
+
+	format := defaults.DonationNoteFormat
+	if strings.TrimSpace(format) == "" {
+		format = defaultDonationNoteFormat
+	}
+	tmpl, err := template.New("donation note").Parse(format)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, DonationNoteFields{}); err != nil {
+		return nil, fmt.Errorf("checking fields: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// donationNoteFields returns the fields of a donation that a donation note format can refer to.
+func donationNoteFields(donation fundraiseup.Donation) DonationNoteFields {
+	fields := DonationNoteFields{
+		Amount:     donation.Amount,
+		Comment:    donation.Comment,
+		Currency:   strings.ToUpper(donation.Currency),
+		DonationID: donation.ID,
+	}
+	if donation.Campaign != nil {
+		fields.Campaign = donation.Campaign.Name
+	}
+	if donation.Designation != nil {
+		fields.Designation = donation.Designation.Name
+	}
+	if donation.Payment != nil {
+		fields.PaymentMethod = string(donation.Payment.Method)
+	}
+	if donation.RecurringPlan != nil {
+		fields.Frequency = donation.RecurringPlan.Frequency
+	}
+	if donation.Tracking != nil {
+		fields.Device = donation.Tracking.Device
+		fields.UTMCampaign = donation.Tracking.UTMCampaign
+		fields.UTMContent = donation.Tracking.UTMContent
+		fields.UTMMedium = donation.Tracking.UTMMedium
+		fields.UTMSource = donation.Tracking.UTMSource
+		fields.UTMTerm = donation.Tracking.UTMTerm
+	}
+	return fields
+}
+
+// recordDonationNote adds a note describing the online donation to the constituent of its new gift, giving gift
+// officers the campaign, traffic source and comment the gift record cannot hold. No note is added when the format
+// produces nothing. A note that cannot be added does not fail the donation, as the gift already exists;
+// it is returned as a warning so the note can be added by hand.
+func (s *Service) recordDonationNote(
+	ctx context.Context,
+	constituentID string,
+	donation fundraiseup.Donation,
+) []string {
+	if s.donationNote == nil {
+		return nil
+	}
+	creator, ok := s.blackbaud.(NoteCreator)
+	if !ok {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := s.donationNote.Execute(&buf, donationNoteFields(donation)); err != nil {
+		return []string{fmt.Sprintf("formatting donation note: %v", err)}
+	}
+	text := strings.TrimSpace(buf.String())
+	if text == "" {
+		return nil
+	}
+
+	note := &blackbaud.ConstituentNote{
+		ConstituentID: constituentID,
+		Date: &blackbaud.FuzzyDate{
+			Day:   donation.CreatedAt.Day(),
+			Month: int(donation.CreatedAt.Month()),
+			Year:  donation.CreatedAt.Year(),
+		},
+		Summary: "FundraiseUp donation " + donation.ID,
+		Text:    text,
+		Type:    s.constituentDefaults.DonationNoteType,
+	}
+	if _, err := creator.CreateConstituentNote(ctx, note); err != nil {
+		return []string{fmt.Sprintf("adding donation note: %v", err)}
+	}
+
+	return nil
+}
+
+// truncateReference shortens the gift's reference to the most Raiser's Edge NXT accepts, which would otherwise reject
+// the whole gift, ending it with an ellipsis at a word boundary where one is near. It returns the full reference when
+// it was shortened, or an empty string when it fits.
+func truncateReference(gift *blackbaud.Gift) string {
+	runes := []rune(gift.Reference)
+	if len(runes) <= blackbaud.MaxGiftReferenceLength {
+		return ""
+	}
+
+	kept := runes[:blackbaud.MaxGiftReferenceLength-len([]rune(referenceEllipsis))]
+	// Break at the last space when it keeps most of the text, rather than mid-word.
+	for i := len(kept) - 1; i >= len(kept)*3/4; i-- {
+		if unicode.IsSpace(kept[i]) {
+			kept = kept[:i]
+			break
+		}
+	}
+
+	full := gift.Reference
+	gift.Reference = strings.TrimRightFunc(string(kept), unicode.IsSpace) + referenceEllipsis
+	return full
+}
+
+// recordReferenceNote adds a note holding the full reference to a gift whose reference was truncated, so the rest of
+// the donor's comment is not lost. Without a reference note type the truncation is only logged. A note that cannot be
+// added does not fail the donation, as the gift already exists; it is returned as a warning so the note can be added
+// by hand.
+func (s *Service) recordReferenceNote(
+	ctx context.Context,
+	giftID string,
+	donation fundraiseup.Donation,
+	fullReference string,
+) []string {
+	if fullReference == "" {
+		return nil
+	}
+	creator, ok := s.blackbaud.(GiftNoteCreator)
+	if s.giftDefaults.ReferenceNoteType == "" || !ok {
+		s.logger.Warn("gift reference too long, truncated",
+			"donation_id", donation.ID,
+			"gift_id", giftID,
+			"length", len([]rune(fullReference)))
+		return nil
+	}
+
+	note := &blackbaud.GiftNote{
+		Date: &blackbaud.FuzzyDate{
+			Day:   donation.CreatedAt.Day(),
+			Month: int(donation.CreatedAt.Month()),
+			Year:  donation.CreatedAt.Year(),
+		},
+		GiftID:  giftID,
+		Summary: "Full reference",
+		Text:    fullReference,
+		Type:    s.giftDefaults.ReferenceNoteType,
+	}
+	if _, err := creator.CreateGiftNote(ctx, note); err != nil {
+		return []string{fmt.Sprintf("reference truncated, adding gift note with the full reference: %v", err)}
+	}
+
+	return nil
+}
+
+// recordPlanChange adds a note to the constituent when a recurring donation's amount, currency or frequency differs
+// from the plan's previous tracked installment, so upgrades and downgrades stay in their stewardship history.
+// The first installment of a plan adds no note, and frequencies are only compared when both installments record one.
+// A note that cannot be added does not fail the donation, as the gift already exists;
+// it is returned as a warning so the note can be added by hand.
+func (s *Service) recordPlanChange(ctx context.Context, constituentID string, donation fundraiseup.Donation) []string {
+	if s.planChangeNoteType == "" || !donation.IsRecurring() || donation.RecurringID() == "" {
+		return nil
+	}
+	history, ok := s.tracker.(RecurringHistory)
+	if !ok {
+		return nil
+	}
+	creator, ok := s.blackbaud.(NoteCreator)
+	if !ok {
+		return nil
+	}
+
+	recurringID := donation.RecurringID()
+	records, err := history.RecurringDonations(ctx, recurringID)
+	if err != nil {
+		return []string{fmt.Sprintf("listing donations of recurring plan %q: %v", recurringID, err)}
+	}
+	// Installments tracked earlier in this run may still be waiting to be written.
+	for _, record := range s.trackBuffer {
+		if record.RecurringID == recurringID {
+			records = append(records, record)
+		}
+	}
+
+	var previous *storage.DonationRecord
+	for i := range records {
+		record := &records[i]
+		if record.DonationID == donation.ID || !record.CreatedAt.Before(donation.CreatedAt) {
+			continue
+		}
+		if previous == nil || record.CreatedAt.After(previous.CreatedAt) {
+			previous = record
+		}
+	}
+	if previous == nil {
+		return nil
+	}
+
+	frequency := donation.RecurringPlan.Frequency
+	summary, changed := planChangeSummary(previous, donation.Amount, donation.Currency, frequency)
+	if !changed {
+		return nil
+	}
+
+	note := &blackbaud.ConstituentNote{
+		ConstituentID: constituentID,
+		Date: &blackbaud.FuzzyDate{
+			Day:   donation.CreatedAt.Day(),
+			Month: int(donation.CreatedAt.Month()),
+			Year:  donation.CreatedAt.Year(),
+		},
+		Summary: summary,
+		Text: fmt.Sprintf("Recurring plan %s changed from %s to %s with donation %s on %s.",
+			recurringID,
+			describePlan(previous.Amount, previous.Currency, previous.Frequency),
+			describePlan(donation.Amount, donation.Currency, frequency),
+			donation.ID,
+			donation.CreatedAt.UTC().Format("2006-01-02")),
+		Type: s.planChangeNoteType,
+	}
+	if _, err := creator.CreateConstituentNote(ctx, note); err != nil {
+		return []string{fmt.Sprintf("adding plan change note for recurring plan %q: %v", recurringID, err)}
+	}
+
+	return nil
+}
+
+// planChangeSummary returns the note summary for a plan moving from the previous installment to the given amount,
+// currency and frequency, and whether the plan changed at all. Frequencies are only compared when both are known.
+func planChangeSummary(
+	previous *storage.DonationRecord,
+	amount string,
+	currency string,
+	frequency string,
+) (string, bool) {
+	before, beforeErr := strconv.ParseFloat(previous.Amount, 64)
+	after, afterErr := strconv.ParseFloat(amount, 64)
+	amountChanged := beforeErr == nil && afterErr == nil && before != after
+	currencyChanged := previous.Currency != "" && currency != "" && !strings.EqualFold(previous.Currency, currency)
+	frequencyChanged := previous.Frequency != "" && frequency != "" && !strings.EqualFold(previous.Frequency, frequency)
+
+	switch {
+	case !amountChanged && !currencyChanged && !frequencyChanged:
+		return "", false
+	case amountChanged && !currencyChanged && !frequencyChanged && after > before:
+		return "Recurring gift increased", true
+	case amountChanged && !currencyChanged && !frequencyChanged:
+		return "Recurring gift decreased", true
+	default:
+		return "Recurring gift changed", true
+	}
+}
+
+// describePlan formats an installment's amount, currency and frequency for a plan change note.
+func describePlan(amount string, currency string, frequency string) string {
+	description := strings.TrimSpace(amount + " " + strings.ToUpper(currency))
+	if frequency != "" {
+		description += " " + strings.ToLower(frequency)
+	}
+	return description
+}
@@ -0,0 +1,65 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// limitDonationSize keeps a donation within the service's size limit, measured as its JSON encoding, so an
+// unexpectedly huge payload cannot swell the gifts, notes and logs made from it beyond the Lambda's memory.
+// A donation over the limit has its comment, the only free text donors write, shortened to fit, and a warning is
+// returned. A donation still over the limit without its comment fails.
+func (s *Service) limitDonationSize(donation fundraiseup.Donation) (fundraiseup.Donation, string, error) {
+	// A zero limit, as on a Service not made by New, allows any size.
+	size := donationSize(donation)
+	if s.maxDonationSize <= 0 || size <= s.maxDonationSize {
+		return donation, "", nil
+	}
+
+	comment := donation.Comment
+	donation.Comment = ""
+	if base := donationSize(donation); base > s.maxDonationSize {
+		return donation, "", fmt.Errorf("donation is %d bytes, over the %d byte limit even without its comment",
+			size, s.maxDonationSize)
+	}
+
+	// Escaping can make the encoded comment longer than its text, so shorten until the whole donation fits.
+	donation.Comment = comment
+	for size > s.maxDonationSize && donation.Comment != "" {
+		donation.Comment = truncateUTF8(donation.Comment, len(donation.Comment)-(size-s.maxDonationSize))
+		size = donationSize(donation)
+	}
+
+	s.logger.Warn("donation over the size limit, comment shortened",
+		"donation_id", donation.ID,
+		"comment_length", len(comment),
+		"limit", s.maxDonationSize)
+	return donation, fmt.Sprintf("comment shortened from %d to %d bytes to keep the donation within %d bytes",
+		len(comment), len(donation.Comment), s.maxDonationSize), nil
+}
+
+// donationSize returns the length of the donation's JSON encoding.
+func donationSize(donation fundraiseup.Donation) int {
+	encoded, err := json.Marshal(donation)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
+
+// truncateUTF8 returns the longest prefix of s of at most n bytes that does not split a character.
+func truncateUTF8(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
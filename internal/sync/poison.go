@@ -0,0 +1,68 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// PanicError reports that processing a donation panicked, so the donation was skipped
+// rather than aborting the run.
+type PanicError struct {
+	// DonationID is the FundraiseUp ID of the donation.
+	DonationID string
+
+	// Stack is the stack trace of the panic.
+	Stack []byte
+
+	// Value is the value the processing panicked with.
+	Value any
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic processing donation %s: %v", e.DonationID, e.Value)
+}
+
+// processDonationSafely processes a donation, recovering if it panics so one malformed donation cannot abort
+// the run. A donation whose processing panicked fails with a PanicError and is recorded as a poison pill.
+func (s *Service) processDonationSafely(ctx context.Context, donation fundraiseup.Donation) (result DonationResult) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		panicErr := &PanicError{DonationID: donation.ID, Stack: debug.Stack(), Value: value}
+		result = DonationResult{DonationID: donation.ID, Error: panicErr}
+		s.logger.Error("recovered from panic processing donation",
+			"donation_id", donation.ID,
+			"panic", fmt.Sprint(value),
+			"stack", string(panicErr.Stack))
+		s.recordPoisonPill(ctx, panicErr)
+	}()
+
+	return s.processDonation(ctx, donation)
+}
+
+// recordPoisonPill records a donation whose processing panicked in the state store, when it keeps them.
+// Dry runs are not recorded. Failing to record is logged rather than failing the run.
+func (s *Service) recordPoisonPill(ctx context.Context, panicErr *PanicError) {
+	if s.poisonPills == nil || s.dryRun {
+		return
+	}
+
+	pill := storage.PoisonPill{
+		DonationID: panicErr.DonationID,
+		OccurredAt: time.Now().UTC(),
+		Panic:      fmt.Sprint(panicErr.Value),
+		Stack:      string(panicErr.Stack),
+	}
+	if err := s.poisonPills.RecordPoisonPill(context.WithoutCancel(ctx), pill); err != nil {
+		s.logger.Error("failed to record poison pill", "donation_id", panicErr.DonationID, "error", err)
+	}
+}
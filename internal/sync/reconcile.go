@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+const (
+	// defaultReconcileWindow is how far back a reconcile-only run looks for untracked donations by default.
+	defaultReconcileWindow = 7 * 24 * time.Hour
+
+	// reconcileSettle is how old a donation must be before a reconcile-only run creates its gift,
+	// leaving time for the webhook delivering it to finish so both do not create a gift.
+	reconcileSettle = 15 * time.Minute
+)
+
+// reconcile processes the donations in the reconcile window that the webhook has not tracked.
+// Tracked donations are skipped by the tracker lookup in processDonation. Nothing is written to the state store:
+// the window is fetched again in full by every run, so there is nothing to resume and no sync time to advance.
+func (s *Service) reconcile(ctx context.Context, result *Result) (*Result, error) {
+	now := time.Now()
+	since := now.Add(-s.reconcileWindow)
+	if s.sinceOverride != nil {
+		since = *s.sinceOverride
+		s.logger.Info("using override sync time", "since", since)
+	}
+	settled := now.Add(-reconcileSettle)
+
+	s.logger.Info("starting reconcile-only sync",
+		"since", since,
+		"dry_run", s.dryRun)
+
+	// Time spent in the callback processing donations is excluded from the fetch duration.
+	var processDuration time.Duration
+	fetched := 0
+	unsettled := 0
+	paused := false
+	stopped := false
+	fetchStart := time.Now()
+	err := s.fundraiseup.DonationPages(ctx, since, "", func(page []fundraiseup.Donation) error {
+		s.metrics.FundraiseUp.Calls++
+		defer func(start time.Time) { processDuration += time.Since(start) }(time.Now())
+
+		fetched += len(page)
+		for _, donation := range page {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if donation.CreatedAt.After(settled) {
+				unsettled++
+				continue
+			}
+			if s.quotaLow(result) {
+				paused = true
+				return fundraiseup.ErrStop
+			}
+
+			if err := s.finishDonation(ctx, result, donation); s.failingFast(err) {
+				stopped = true
+				return fundraiseup.ErrStop
+			}
+		}
+		return nil
+	})
+	fetchDuration := time.Since(fetchStart) - processDuration
+	s.metrics.FetchDuration += fetchDuration
+	s.metrics.FundraiseUp.Duration += fetchDuration
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return s.interrupt(result, ctxErr)
+		}
+		return nil, fmt.Errorf("fetching donations: %w", err)
+	}
+
+	s.logger.Info("fetched donations", "count", fetched, "left_for_webhook", unsettled)
+
+	if paused {
+		return s.pauseForQuota(result), nil
+	}
+	if stopped {
+		return s.stopOnError(result), nil
+	}
+
+	s.applyDonationEvents(ctx, result, since)
+
+	s.logSyncComplete(result)
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package sync
+
+import "encoding/json"
+
+// MarshalJSON encodes the donation's outcome, with its error as a message.
+func (r DonationResult) MarshalJSON() ([]byte, error) {
+	// donationResult has the fields but not the methods of DonationResult, so encoding it does not recurse.
+	type donationResult DonationResult
+	var message string
+	if r.Error != nil {
+		message = r.Error.Error()
+	}
+
+	return json.Marshal(struct {
+		donationResult
+
+		// Error replaces the embedded error, which would otherwise encode as an empty object.
+		Error string `json:"error,omitempty"`
+	}{donationResult: donationResult(r), Error: message})
+}
+
+// MarshalJSON encodes the sync's outcome, with its errors as messages, for consumers of the handler's response.
+func (r Result) MarshalJSON() ([]byte, error) {
+	// result has the fields but not the methods of Result, so encoding it does not recurse.
+	type result Result
+	messages := make([]string, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return json.Marshal(struct {
+		result
+
+		// Errors replaces the embedded errors, which would otherwise encode as empty objects.
+		Errors []string `json:"errors,omitempty"`
+	}{result: result(r), Errors: messages})
+}
@@ -0,0 +1,157 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+const (
+	// defaultRetryBaseDelay is how long after its first failure a donation is tried again by default.
+	defaultRetryBaseDelay = 15 * time.Minute
+
+	// defaultRetryMaxAttempts is how many times a donation is tried by default before it is given up on.
+	defaultRetryMaxAttempts = 5
+
+	// maxRetryDelay caps the backoff between attempts at a donation.
+	maxRetryDelay = 24 * time.Hour
+)
+
+// transient reports whether err is expected to clear if the donation is tried again later:
+// Blackbaud rate limits, timeouts and server errors, and network failures.
+func transient(err error) bool {
+	var statusErr *blackbaud.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusRequestTimeout ||
+			statusErr.StatusCode == http.StatusTooManyRequests ||
+			statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
+// retryDelay returns how long to wait before trying a donation again after its given number of failures,
+// doubling from the base delay after each failure up to a day.
+func (s *Service) retryDelay(attempts int) time.Duration {
+	delay := s.retryBaseDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// retryDue loads the retry schedule and tries again the donations whose next attempt is due, before the run
+// moves on to pending and new donations. It returns true when the run stopped early, because it was cancelled,
+// the Blackbaud quota ran low or a retry failed in fail-fast mode, leaving the remaining retries due for the next run.
+// Samples skip retries, since they preview a window.
+func (s *Service) retryDue(ctx context.Context, result *Result) (bool, error) {
+	s.retries = nil
+	if s.retryStore == nil || s.sample > 0 {
+		return false, nil
+	}
+
+	schedule, err := s.retryStore.RetrySchedule(ctx)
+	if err != nil {
+		return false, fmt.Errorf("getting retry schedule: %w", err)
+	}
+	s.retries = schedule
+
+	now := time.Now()
+	var due []string
+	for donationID, entry := range s.retries {
+		if !entry.NextAttemptAt.After(now) {
+			due = append(due, donationID)
+		}
+	}
+	if len(due) == 0 {
+		return false, nil
+	}
+	sort.Strings(due)
+
+	s.logger.Info("retrying failed donations", "due_count", len(due), "scheduled_count", len(s.retries))
+
+	for _, donationID := range due {
+		if err := ctx.Err(); err != nil {
+			_, err := s.interrupt(result, err)
+			return true, err
+		}
+		if s.quotaLow(result) {
+			s.pauseForQuota(result)
+			return true, nil
+		}
+
+		fetchStart := time.Now()
+		donation, err := s.fundraiseup.Donation(ctx, donationID)
+		s.metrics.FundraiseUp.observe(fetchStart)
+		if err != nil {
+			// Leave the retry due when the fetch failed because the run was cancelled.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				_, err := s.interrupt(result, ctxErr)
+				return true, err
+			}
+			s.logger.Error("failed to fetch donation for retry",
+				"donation_id", donationID,
+				"error", err)
+			result.Errors = append(result.Errors, fmt.Errorf("fetching donation %s: %w", donationID, err))
+			if s.failFast {
+				s.stopOnError(result)
+				return true, nil
+			}
+			s.updateRetry(context.WithoutCancel(ctx), donationID, err)
+			continue
+		}
+
+		if err := s.attemptDonation(context.WithoutCancel(ctx), result, *donation); s.failingFast(err) {
+			s.stopOnError(result)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// updateRetry schedules another attempt at a donation that failed with an error expected to clear, and removes
+// a donation from the retry schedule once it succeeds, fails with another error, or has used its attempts.
+// Nothing is scheduled in dry-run or reconcile-only runs, or when the state store keeps no schedule.
+func (s *Service) updateRetry(ctx context.Context, donationID string, err error) {
+	if s.retryStore == nil || s.dryRun || s.reconcileOnly {
+		return
+	}
+
+	entry, scheduled := s.retries[donationID]
+	switch {
+	case err != nil && transient(err) && entry.Attempts+1 < s.retryMaxAttempts:
+		entry.Attempts++
+		entry.NextAttemptAt = time.Now().Add(s.retryDelay(entry.Attempts)).UTC()
+		if s.retries == nil {
+			s.retries = make(map[string]storage.RetryEntry)
+		}
+		s.retries[donationID] = entry
+		s.logger.Warn("scheduled failed donation for retry",
+			"donation_id", donationID,
+			"attempts", entry.Attempts,
+			"next_attempt_at", entry.NextAttemptAt)
+	case scheduled:
+		delete(s.retries, donationID)
+		if err != nil {
+			s.logger.Error("giving up retrying donation",
+				"donation_id", donationID,
+				"attempts", entry.Attempts+1,
+				"error", err)
+		}
+	default:
+		return
+	}
+
+	if err := s.retryStore.SetRetrySchedule(ctx, s.retries); err != nil {
+		s.logger.Error("failed to store retry schedule", "donation_id", donationID, "error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/normalize"
+)
+
+// compileCountryRoutes indexes the configured country routes by ISO 3166-1 alpha-2 code, so a country given as
+// a code, alias or name finds its route. A country listed by more than one route uses the first.
+func compileCountryRoutes(routes []config.CountryRoute) (map[string]config.CountryRoute, error) {
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	compiled := make(map[string]config.CountryRoute)
+	for i, route := range routes {
+		for _, country := range route.Countries {
+			code, _, ok := normalize.Country(country)
+			if !ok {
+				return nil, fmt.Errorf("country route %d: unrecognised country %q", i+1, country)
+			}
+			if _, exists := compiled[code]; !exists {
+				compiled[code] = route
+			}
+		}
+	}
+	return compiled, nil
+}
+
+// routeByCountry sets the fund, campaign and appeal of the route for the supporter's country on the gift's split,
+// leaving those the route does not set at their defaults. Gifts from supporters without a routed country are
+// left unchanged.
+func (s *Service) routeByCountry(donation fundraiseup.Donation, gift *blackbaud.Gift) {
+	if len(s.countryRoutes) == 0 || donation.Supporter == nil || donation.Supporter.Address == nil {
+		return
+	}
+	code, _, ok := normalize.Country(donation.Supporter.Address.Country)
+	if !ok {
+		return
+	}
+	route, ok := s.countryRoutes[code]
+	if !ok {
+		return
+	}
+
+	for i := range gift.GiftSplits {
+		if route.AppealID != "" {
+			gift.GiftSplits[i].AppealID = route.AppealID
+		}
+		if route.CampaignID != "" {
+			gift.GiftSplits[i].CampaignID = route.CampaignID
+		}
+		if route.FundID != "" {
+			gift.GiftSplits[i].FundID = route.FundID
+		}
+	}
+}
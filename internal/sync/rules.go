@@ -0,0 +1,204 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/transform"
+)
+
+// GiftCheckError reports that a mapped gift failed the configured gift checks, so it was not created.
+type GiftCheckError struct {
+	// Violations describe each check the gift failed.
+	Violations []string
+}
+
+// Error implements error.
+func (e *GiftCheckError) Error() string {
+	return "gift failed checks: " + strings.Join(e.Violations, "; ")
+}
+
+// compileGiftChecks compiles the configured gift checks.
+func compileGiftChecks(checks []config.GiftCheck) ([]transform.Check, error) {
+	compiled := make([]transform.Check, 0, len(checks))
+	for i, check := range checks {
+		c, err := transform.NewCheck(check.Assert, check.Message)
+		if err != nil {
+			return nil, fmt.Errorf("gift check %d: %w", i+1, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// compileGiftRules compiles the configured gift rules.
+func compileGiftRules(rules []config.GiftRule) ([]transform.Rule, error) {
+	compiled := make([]transform.Rule, 0, len(rules))
+	for i, rule := range rules {
+		r, err := transform.NewRule(rule.Field, rule.When, rule.Value)
+		if err != nil {
+			return nil, fmt.Errorf("gift rule %d: %w", i+1, err)
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+// applyGiftRules applies the gift rules in order, so each rule sees the fields set by the rules before it.
+func (s *Service) applyGiftRules(donation fundraiseup.Donation, gift *blackbaud.Gift) error {
+	if len(s.giftRules) == 0 {
+		return nil
+	}
+
+	vars := giftRuleVars(donation)
+	for i, rule := range s.giftRules {
+		setGiftRuleVars(vars, gift)
+		value, ok, err := rule.Apply(vars)
+		if err != nil {
+			return fmt.Errorf("gift rule %d (%s): %w", i+1, rule.Field, err)
+		}
+		if !ok {
+			continue
+		}
+		if err := setGiftField(gift, rule.Field, value); err != nil {
+			return fmt.Errorf("gift rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// checkDonation evaluates the gift checks that only refer to the donation and supporter, before a constituent is
+// found or created for the donation, returning a *GiftCheckError listing every check it fails.
+func (s *Service) checkDonation(donation fundraiseup.Donation) error {
+	if len(s.giftChecks) == 0 {
+		return nil
+	}
+
+	return s.evalGiftChecks(giftRuleVars(donation), false)
+}
+
+// checkGift evaluates the gift checks that refer to the gift against the gift as it is about to be created,
+// returning a *GiftCheckError listing every check it fails.
+func (s *Service) checkGift(donation fundraiseup.Donation, gift *blackbaud.Gift) error {
+	if len(s.giftChecks) == 0 {
+		return nil
+	}
+
+	vars := giftRuleVars(donation)
+	setGiftRuleVars(vars, gift)
+	return s.evalGiftChecks(vars, true)
+}
+
+// evalGiftChecks evaluates the gift checks that refer to the gift, or those that do not, returning a
+// *GiftCheckError listing every check that fails.
+func (s *Service) evalGiftChecks(vars transform.Vars, usesGift bool) error {
+	var violations []string
+	for i, check := range s.giftChecks {
+		if check.UsesGift() != usesGift {
+			continue
+		}
+		holds, err := check.Holds(vars)
+		if err != nil {
+			return fmt.Errorf("gift check %d: %w", i+1, err)
+		}
+		if !holds {
+			violations = append(violations, check.Violation())
+		}
+	}
+	if len(violations) > 0 {
+		return &GiftCheckError{Violations: violations}
+	}
+	return nil
+}
+
+// refuseGift logs a donation whose gift is not created because it failed the gift checks, and holds it for review
+// in the state store, when it keeps them, so it is not lost once the run moves past it. Dry runs are not held.
+// Failing to hold the donation is logged rather than failing the run.
+func (s *Service) refuseGift(ctx context.Context, donation fundraiseup.Donation, err error) {
+	s.logger.Warn("gift failed checks, not creating it", "donation_id", donation.ID, "error", err)
+
+	var checkErr *GiftCheckError
+	if s.giftReviews == nil || s.dryRun || !errors.As(err, &checkErr) {
+		return
+	}
+
+	review := storage.GiftReview{
+		DonationID: donation.ID,
+		HeldAt:     time.Now().UTC(),
+		Violations: checkErr.Violations,
+	}
+	if err := s.giftReviews.HoldForReview(context.WithoutCancel(ctx), review); err != nil {
+		s.logger.Error("failed to hold donation for review", "donation_id", donation.ID, "error", err)
+	}
+}
+
+// giftRuleVars returns the donation and supporter variables available to gift rules.
+func giftRuleVars(donation fundraiseup.Donation) transform.Vars {
+	amount, _ := strconv.ParseFloat(donation.Amount, 64)
+	vars := transform.Vars{
+		transform.VarDonationAmount:      amount,
+		transform.VarDonationComment:     donation.Comment,
+		transform.VarDonationCurrency:    donation.Currency,
+		transform.VarDonationDate:        donation.CreatedAt.Format("2006-01-02"),
+		transform.VarDonationID:          donation.ID,
+		transform.VarDonationInstallment: int64(donation.InstallmentNumber()),
+		transform.VarDonationRecurring:   donation.IsRecurring(),
+	}
+	if donation.Campaign != nil {
+		vars[transform.VarDonationCampaignID] = donation.Campaign.ID
+		vars[transform.VarDonationCampaignName] = donation.Campaign.Name
+	}
+	if donation.Designation != nil {
+		vars[transform.VarDonationDesignationID] = donation.Designation.ID
+		vars[transform.VarDonationDesignationName] = donation.Designation.Name
+	}
+	if donation.Supporter != nil {
+		vars[transform.VarSupporterEmail] = donation.Supporter.Email
+		vars[transform.VarSupporterFirstName] = donation.Supporter.FirstName
+		vars[transform.VarSupporterLastName] = donation.Supporter.LastName
+	}
+	return vars
+}
+
+// setGiftField sets a gift field named by a rule. Fund, campaign and appeal are set on every split.
+func setGiftField(gift *blackbaud.Gift, field string, value string) error {
+	switch field {
+	case transform.FieldAppealID:
+		for i := range gift.GiftSplits {
+			gift.GiftSplits[i].AppealID = value
+		}
+	case transform.FieldCampaignID:
+		for i := range gift.GiftSplits {
+			gift.GiftSplits[i].CampaignID = value
+		}
+	case transform.FieldFundID:
+		if value == "" {
+			return fmt.Errorf("%s must not be empty", field)
+		}
+		for i := range gift.GiftSplits {
+			gift.GiftSplits[i].FundID = value
+		}
+	case transform.FieldReference:
+		gift.Reference = value
+	}
+	return nil
+}
+
+// setGiftRuleVars sets the gift variables available to gift rules from the gift's current fields.
+func setGiftRuleVars(vars transform.Vars, gift *blackbaud.Gift) {
+	vars[transform.VarGiftReference] = gift.Reference
+	vars[transform.VarGiftType] = string(gift.Type)
+	if len(gift.GiftSplits) > 0 {
+		vars[transform.VarGiftAppealID] = gift.GiftSplits[0].AppealID
+		vars[transform.VarGiftCampaignID] = gift.GiftSplits[0].CampaignID
+		vars[transform.VarGiftFundID] = gift.GiftSplits[0].FundID
+	}
+}
@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// reservoir keeps a uniform random sample of a fixed size from a stream of donations of unknown length.
+type reservoir struct {
+	// items holds the sampled donations with their positions in the stream.
+	items []sampledDonation
+
+	// rng chooses which donations are kept.
+	rng *rand.Rand
+
+	// seen is the number of donations offered so far.
+	seen int
+
+	// size is the number of donations to keep.
+	size int
+}
+
+// sampledDonation is a donation kept by a reservoir.
+type sampledDonation struct {
+	// donation is the sampled donation.
+	donation fundraiseup.Donation
+
+	// index is the donation's position in the stream.
+	index int
+}
+
+// newReservoir returns a reservoir keeping size donations, chosen deterministically from seed.
+func newReservoir(size int, seed int64) *reservoir {
+	return &reservoir{
+		items: make([]sampledDonation, 0, size),
+		rng:   rand.New(rand.NewPCG(uint64(seed), 0)),
+		size:  size,
+	}
+}
+
+// add offers a donation to the sample.
+func (r *reservoir) add(donation fundraiseup.Donation) {
+	item := sampledDonation{donation: donation, index: r.seen}
+	r.seen++
+
+	if len(r.items) < r.size {
+		r.items = append(r.items, item)
+		return
+	}
+	if i := r.rng.IntN(r.seen); i < r.size {
+		r.items[i] = item
+	}
+}
+
+// donations returns the sampled donations in the order they were offered,
+// so recurring installments are still processed oldest first.
+func (r *reservoir) donations() []fundraiseup.Donation {
+	sort.Slice(r.items, func(i, j int) bool { return r.items[i].index < r.items[j].index })
+
+	donations := make([]fundraiseup.Donation, len(r.items))
+	for i, item := range r.items {
+		donations[i] = item.donation
+	}
+	return donations
+}
+
+// sampleAndProcess fetches the whole donations window and processes a random sample of it.
+// Sampling is only allowed in dry-run mode, so no checkpoint or sync time is stored.
+func (s *Service) sampleAndProcess(
+	ctx context.Context,
+	result *Result,
+	state *storage.FetchState,
+) (*Result, error) {
+	s.logger.Info("sampling donations",
+		"since", state.Since,
+		"sample", s.sample,
+		"seed", s.sampleSeed)
+
+	sample := newReservoir(s.sample, s.sampleSeed)
+	fetchStart := time.Now()
+	err := s.fundraiseup.DonationPages(ctx, state.Since, state.Cursor, func(page []fundraiseup.Donation) error {
+		s.metrics.FundraiseUp.Calls++
+		for _, donation := range page {
+			sample.add(donation)
+		}
+		return nil
+	})
+	fetchDuration := time.Since(fetchStart)
+	s.metrics.FetchDuration += fetchDuration
+	s.metrics.FundraiseUp.Duration += fetchDuration
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return s.interrupt(result, ctxErr)
+		}
+		return nil, fmt.Errorf("fetching donations: %w", err)
+	}
+
+	donations := sample.donations()
+	result.DonationsSampledFrom = sample.seen
+	s.logger.Info("sampled donations", "count", len(donations), "window", sample.seen)
+
+	for _, donation := range donations {
+		if err := ctx.Err(); err != nil {
+			return s.interrupt(result, err)
+		}
+		if s.quotaLow(result) {
+			return s.pauseForQuota(result), nil
+		}
+
+		if err := s.finishDonation(ctx, result, donation); s.failingFast(err) {
+			return s.stopOnError(result), nil
+		}
+	}
+
+	s.logSyncComplete(result)
+	return result, nil
+}
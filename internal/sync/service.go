@@ -5,16 +5,33 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/peteski22/giftbridge/internal/blackbaud"
 	"github.com/peteski22/giftbridge/internal/config"
 	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/lru"
+	"github.com/peteski22/giftbridge/internal/normalize"
+	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/transform"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 const (
 	defaultSyncDays = -30
 	originName      = "FundraiseUp"
+	originSource    = "giftbridge"
+
+	// defaultGiftCacheSize is how many constituents' gifts are cached during a run by default, enough for
+	// a Lambda run while keeping backfills over thousands of constituents from holding every gift in memory.
+	defaultGiftCacheSize = 1000
+
+	// defaultMaxDonationSize is the largest a donation's JSON encoding may be by default, far above any donation
+	// made through a FundraiseUp form.
+	defaultMaxDonationSize = 64 << 10
 
 	// defaultMaxDonationsPerRun limits donations processed per Lambda invocation.
 	// This limit exists because pending donation IDs are stored in SSM Parameter Store
@@ -23,6 +40,14 @@ const (
 	// If you have sustained volumes exceeding 300 donations per sync interval,
 	// consider increasing the sync frequency (e.g., every 15 minutes instead of hourly).
 	defaultMaxDonationsPerRun = 300
+
+	// syncAdvanceInterval is how many donations are processed between advances of the last sync time,
+	// so a run that stops part way through a large window is not left with all of it to fetch again.
+	syncAdvanceInterval = 25
+
+	// trackBatchSize is how many tracked gifts are buffered before being written together,
+	// matching the most items DynamoDB writes in one batch.
+	trackBatchSize = 25
 )
 
 // Config holds the required configuration for creating a Service.
@@ -30,28 +55,147 @@ type Config struct {
 	// Blackbaud is the Blackbaud API client.
 	Blackbaud BlackbaudClient
 
+	// CampaignWindows limit the donations of the listed FundraiseUp campaigns synced to those made within a window,
+	// or exclude a campaign's donations altogether. Donations skipped are counted in Result.Campaigns, along with
+	// every other campaign's. A campaign listed twice uses its last window.
+	CampaignWindows []config.CampaignWindow
+
+	// ChargebackNoteType is the Raiser's Edge NXT note type of the note added to a constituent when the donation of
+	// one of their tracked gifts is charged back. Requires ChargebackStatus and a Blackbaud client implementing
+	// NoteCreator. When empty, no notes are added.
+	ChargebackNoteType string
+
+	// ChargebackStatus is the gift status set on the tracked gift of each donation disputed or charged back,
+	// read from the FundraiseUp events since the previous sync. Requires a Tracker and a Blackbaud client
+	// implementing GiftReader. When empty, chargebacks are not applied.
+	ChargebackStatus string
+
+	// CommentScrubbing controls what is removed from donor comments before they are mapped to gifts,
+	// so rules and hooks only see the scrubbed comment.
+	CommentScrubbing config.CommentScrubbing
+
+	// ConstituentDefaults contains default values for constituents created in Raiser's Edge.
+	ConstituentDefaults config.ConstituentDefaults
+
+	// DeletedGiftPolicy is what to do when a tracked gift has been deleted in Blackbaud:
+	// config.DeletedGiftPolicyReport, config.DeletedGiftPolicyRecreate or config.DeletedGiftPolicyExclude.
+	// Requires a Tracker and a Blackbaud client implementing GiftReader. When empty, tracked gifts are not checked.
+	DeletedGiftPolicy string
+
+	// DeletedGiftCheckAge limits deleted gift checks to gifts tracked within this long, since each check is a
+	// Blackbaud call for every tracked donation seen again. Zero checks every tracked gift.
+	DeletedGiftCheckAge time.Duration
+
+	// DonationIDs limits the run to these donations, fetched by ID and processed in order, as each batch of a
+	// backfill is. The state store is neither read nor written, so the sync time, pending donations and retry
+	// schedule are left alone. Cannot be combined with ReconcileOnly or Sample.
+	DonationIDs []string
+
 	// DryRun indicates whether to skip writes to Blackbaud.
 	DryRun bool
 
-	// FundraiseUp is the FundraiseUp API client.
-	FundraiseUp *fundraiseup.Client
+	// EmailNormalization controls how supporter emails are normalized when matching constituents.
+	EmailNormalization config.EmailNormalization
+
+	// FailFast stops the run at the first donation that fails, rather than carrying on with the rest, for testing a
+	// new configuration. The failed donation and those after it stay pending, and the last sync time is unchanged,
+	// so the next run starts with them. The failed donation is not scheduled for a retry.
+	FailFast bool
+
+	// FetchOverlap is how far before the last sync time each fresh sync starts fetching, so donations FundraiseUp
+	// only lists after a run has fetched past their creation time are not missed. Donations fetched again are
+	// skipped as existing. Not applied to SinceOverride or the first sync. Zero disables the overlap.
+	FetchOverlap time.Duration
+
+	// FundraiseUp is the FundraiseUp API client, or *fundraiseup.Accounts to sync several accounts together.
+	FundraiseUp DonationSource
+
+	// GiftCacheSize is how many constituents' gifts are cached during a run, evicting the least recently used
+	// constituent's once full. Default is 1000.
+	GiftCacheSize int
 
 	// GiftDefaults contains default values for gifts in Raiser's Edge.
 	GiftDefaults config.GiftDefaults
 
+	// Hooks customise new constituents and gifts, and are run in order (optional).
+	Hooks []Hook
+
 	// Logger is the structured logger for the service.
 	Logger *slog.Logger
 
+	// MaxDonationSize is the largest a donation may be, in bytes of its JSON encoding. A larger donation has its
+	// comment shortened to fit, with a warning, or fails if it is too large even without one. Zero uses the default
+	// of 64KB.
+	MaxDonationSize int
+
 	// MaxDonationsPerRun limits donations processed per Lambda invocation.
 	// Default is 300. This limit exists because pending donation IDs are stored
 	// in SSM Parameter Store (4KB limit). Do not exceed 400.
 	MaxDonationsPerRun int
 
+	// NameNormalization controls how supporter names are cleaned up when creating constituents.
+	NameNormalization config.NameNormalization
+
+	// PlanChangeNoteType is the Raiser's Edge NXT note type of the note added to a constituent when their recurring
+	// plan's amount or frequency changes, so the change is kept with their stewardship history. Each installment is
+	// compared with the plan's previous tracked one. Requires a Tracker implementing RecurringHistory and a Blackbaud
+	// client implementing NoteCreator. When empty, no notes are added.
+	PlanChangeNoteType string
+
+	// QuotaReserve pauses processing until the next run when the Blackbaud call quota remaining drops below it,
+	// leaving calls for other integrations on the same subscription. Zero disables the limit.
+	QuotaReserve int
+
+	// ReconcileOnly limits runs to donations with no tracker record, for deployments where a webhook creates gifts
+	// as donations arrive. Each run looks back over ReconcileWindow, or from SinceOverride, and keeps no pending
+	// donations, fetch checkpoint or last sync time, since the webhook covers the window. Requires a Tracker.
+	ReconcileOnly bool
+
+	// ReconcileWindow is how far back a reconcile-only run looks for untracked donations. Default is 7 days.
+	ReconcileWindow time.Duration
+
+	// RetryBaseDelay is how long after its first failure a donation is tried again, doubling after each further
+	// failure up to a day. Only used when the StateStore implements RetryStore. Default is 15 minutes.
+	RetryBaseDelay time.Duration
+
+	// RetryMaxAttempts is how many times a donation failing with an error expected to clear is tried before
+	// it is given up on, including the first. Only used when the StateStore implements RetryStore.
+	// Default is 5; 1 disables retries.
+	RetryMaxAttempts int
+
+	// Sample processes a random sample of this many donations from the window instead of all of them.
+	// Only allowed in dry-run mode. Zero processes every donation.
+	Sample int
+
+	// SampleSeed chooses the sample, so the same seed picks the same donations from the same window.
+	SampleSeed int64
+
 	// SinceOverride optionally overrides the last sync time.
 	SinceOverride *time.Time
 
-	// StateStore manages sync state persistence.
+	// SupporterCacheTTL is how long the constituent a supporter's email matched is remembered across runs, so
+	// repeat donors are not searched for again. Requires a Tracker implementing SupporterCache.
+	// Zero disables the cache.
+	SupporterCacheTTL time.Duration
+
+	// StateStore manages sync state persistence. Runs are only resumed when it also implements PendingStore,
+	// failed donations only retried when it implements RetryStore, and runs only recorded in a run history
+	// when it implements RunRecorder.
 	StateStore StateStore
+
+	// Tracker optionally records the gift created for each donation.
+	// When set, tracked donations are skipped without querying Blackbaud.
+	// Trackers implementing BatchTracker have their writes buffered and flushed in batches.
+	Tracker DonationTracker
+
+	// UpdateComments applies comments donors edit in FundraiseUp after their gift was created to the gift's
+	// reference on the next run, read from the FundraiseUp events since the previous sync.
+	// Requires a Tracker and a Blackbaud client implementing GiftReader.
+	UpdateComments bool
+
+	// Verify re-reads each gift created during a run and reports fields stored differently from what was sent.
+	// Requires a real run and a Blackbaud client implementing GiftReader.
+	Verify bool
 }
 
 // validate checks that all required Config fields are set.
@@ -66,23 +210,215 @@ func (c *Config) validate() error {
 	if c.GiftDefaults.FundID == "" {
 		errs = append(errs, errors.New("gift defaults fund ID is required"))
 	}
+	if err := checkGiftSplits(c.GiftDefaults.Splits); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Sample < 0 {
+		errs = append(errs, errors.New("sample must not be negative"))
+	}
+	if c.Sample > 0 && !c.DryRun {
+		errs = append(errs, errors.New("sample requires dry run"))
+	}
+	if len(c.DonationIDs) > 0 && c.ReconcileOnly {
+		errs = append(errs, errors.New("donation IDs cannot be combined with reconcile only"))
+	}
+	if len(c.DonationIDs) > 0 && c.Sample > 0 {
+		errs = append(errs, errors.New("donation IDs cannot be combined with sample"))
+	}
+	if _, ok := c.Blackbaud.(AppealResponder); c.GiftDefaults.AppealResponses && c.Blackbaud != nil && !ok {
+		errs = append(errs, errors.New("appeal responses require a blackbaud client that can record appeal responses"))
+	}
+	if _, ok := c.Blackbaud.(EmailAdder); c.EmailNormalization.AddMissing && c.Blackbaud != nil && !ok {
+		errs = append(errs, errors.New("adding missing emails requires a blackbaud client that can add emails"))
+	}
+	if _, ok := c.Blackbaud.(EventRegistrar); len(c.ConstituentDefaults.Events) > 0 && c.Blackbaud != nil && !ok {
+		errs = append(errs, errors.New("event links require a blackbaud client that can add event participants"))
+	}
+	if _, ok := c.Blackbaud.(NoteCreator); c.ConstituentDefaults.DonationNoteType != "" && c.Blackbaud != nil && !ok {
+		errs = append(errs, errors.New("donation notes require a blackbaud client that can add notes"))
+	}
+	if _, ok := c.Blackbaud.(GiftCustomFieldCreator); c.GiftDefaults.CreatorCustomField != "" &&
+		c.Blackbaud != nil && !ok {
+		errs = append(errs, errors.New("creator custom fields require a blackbaud client that can add custom fields"))
+	}
+	if _, ok := c.Blackbaud.(GiftNoteCreator); c.GiftDefaults.ReferenceNoteType != "" && c.Blackbaud != nil && !ok {
+		errs = append(errs, errors.New("reference notes require a blackbaud client that can add gift notes"))
+	}
+	if c.ChargebackStatus != "" {
+		if c.Tracker == nil {
+			errs = append(errs, errors.New("chargebacks require a donation tracker"))
+		}
+		if _, ok := c.Blackbaud.(GiftReader); c.Blackbaud != nil && !ok {
+			errs = append(errs, errors.New("chargebacks require a blackbaud client that can read gifts"))
+		}
+	}
+	if c.ChargebackNoteType != "" {
+		if c.ChargebackStatus == "" {
+			errs = append(errs, errors.New("chargeback notes require a chargeback status"))
+		}
+		if _, ok := c.Blackbaud.(NoteCreator); c.Blackbaud != nil && !ok {
+			errs = append(errs, errors.New("chargeback notes require a blackbaud client that can add notes"))
+		}
+	}
+	if c.Verify {
+		if c.DryRun {
+			errs = append(errs, errors.New("verify requires a real run"))
+		}
+		if _, ok := c.Blackbaud.(GiftReader); c.Blackbaud != nil && !ok {
+			errs = append(errs, errors.New("verify requires a blackbaud client that can read gifts"))
+		}
+	}
+	if c.DeletedGiftPolicy != "" {
+		switch c.DeletedGiftPolicy {
+		case config.DeletedGiftPolicyExclude, config.DeletedGiftPolicyRecreate, config.DeletedGiftPolicyReport:
+		default:
+			errs = append(errs, fmt.Errorf("unknown deleted gift policy %q", c.DeletedGiftPolicy))
+		}
+		if c.Tracker == nil {
+			errs = append(errs, errors.New("deleted gift policy requires a donation tracker"))
+		}
+		if _, ok := c.Blackbaud.(GiftReader); c.Blackbaud != nil && !ok {
+			errs = append(errs, errors.New("deleted gift policy requires a blackbaud client that can read gifts"))
+		}
+		if _, ok := c.Tracker.(GiftReplacer); c.DeletedGiftPolicy == config.DeletedGiftPolicyRecreate &&
+			c.Tracker != nil && !ok {
+			errs = append(errs, errors.New("recreate deleted gift policy requires a tracker that can replace gifts"))
+		}
+	}
+	switch c.GiftDefaults.RecurringMode {
+	case "", config.GiftRecurringModeFlat, config.GiftRecurringModeLinked:
+	default:
+		errs = append(errs, fmt.Errorf("unknown recurring mode %q", c.GiftDefaults.RecurringMode))
+	}
+	switch c.GiftDefaults.DatePolicy {
+	case "", config.GiftDatePolicyFlag, config.GiftDatePolicyRefuse:
+	default:
+		errs = append(errs, fmt.Errorf("unknown gift date policy %q", c.GiftDefaults.DatePolicy))
+	}
+	if c.GiftDefaults.MaxAgeDays < 0 {
+		errs = append(errs, errors.New("gift max age must not be negative"))
+	}
+	switch c.GiftDefaults.TestDonations {
+	case "", config.TestDonationsSkip:
+		if c.GiftDefaults.TestFundID != "" {
+			errs = append(errs, errors.New("test fund requires syncing test donations"))
+		}
+	case config.TestDonationsSync:
+	default:
+		errs = append(errs, fmt.Errorf("unknown test donations policy %q", c.GiftDefaults.TestDonations))
+	}
+	if c.FetchOverlap < 0 {
+		errs = append(errs, errors.New("fetch overlap must not be negative"))
+	}
+	if c.GiftCacheSize < 0 {
+		errs = append(errs, errors.New("gift cache size must not be negative"))
+	}
+	if c.MaxDonationSize < 0 {
+		errs = append(errs, errors.New("max donation size must not be negative"))
+	}
+	if c.PlanChangeNoteType != "" {
+		if _, ok := c.Tracker.(RecurringHistory); !ok {
+			errs = append(errs, errors.New("plan change notes require a tracker that can list recurring donations"))
+		}
+		if _, ok := c.Blackbaud.(NoteCreator); c.Blackbaud != nil && !ok {
+			errs = append(errs, errors.New("plan change notes require a blackbaud client that can add notes"))
+		}
+	}
+	if c.ReconcileOnly && c.Tracker == nil {
+		errs = append(errs, errors.New("reconcile only requires a donation tracker"))
+	}
+	if c.ReconcileWindow < 0 {
+		errs = append(errs, errors.New("reconcile window must not be negative"))
+	}
+	if c.RetryBaseDelay < 0 {
+		errs = append(errs, errors.New("retry base delay must not be negative"))
+	}
+	if c.RetryMaxAttempts < 0 {
+		errs = append(errs, errors.New("retry max attempts must not be negative"))
+	}
 	if c.StateStore == nil {
 		errs = append(errs, errors.New("state store is required"))
 	}
+	if c.SupporterCacheTTL < 0 {
+		errs = append(errs, errors.New("supporter cache TTL must not be negative"))
+	}
+	if _, ok := c.Tracker.(SupporterCache); c.SupporterCacheTTL > 0 && !ok {
+		errs = append(errs, errors.New("supporter cache requires a donation tracker that can cache constituents"))
+	}
+	if c.UpdateComments {
+		if c.Tracker == nil {
+			errs = append(errs, errors.New("update comments requires a donation tracker"))
+		}
+		if _, ok := c.Blackbaud.(GiftReader); c.Blackbaud != nil && !ok {
+			errs = append(errs, errors.New("update comments requires a blackbaud client that can read gifts"))
+		}
+	}
 	return errors.Join(errs...)
 }
 
 // Service orchestrates the sync between FundraiseUp and Blackbaud.
 type Service struct {
-	blackbaud          BlackbaudClient
-	dryRun             bool
-	fundraiseup        *fundraiseup.Client
-	giftCache          map[string][]blackbaud.Gift
-	giftDefaults       config.GiftDefaults
-	logger             *slog.Logger
-	maxDonationsPerRun int
-	sinceOverride      *time.Time
-	stateStore         StateStore
+	addresseeFormatter     *normalize.NameFormatter
+	blackbaud              BlackbaudClient
+	campaignWindows        map[string]config.CampaignWindow
+	chargebackNoteType     string
+	chargebackStatus       string
+	commentScrubber        *normalize.CommentScrubber
+	constituentAppeals     map[string]map[string]bool
+	constituentCache       map[string]string
+	constituentDefaults    config.ConstituentDefaults
+	countryRoutes          map[string]config.CountryRoute
+	createdGifts           []createdGift
+	deletedGiftCheckAge    time.Duration
+	deletedGiftPolicy      string
+	donationIDs            []string
+	donationNote           *template.Template
+	dryRun                 bool
+	emailNormalization     config.EmailNormalization
+	eventLinks             map[string]string
+	eventParticipants      map[string]map[string]bool
+	failFast               bool
+	fetchOverlap           time.Duration
+	fundraiseup            DonationSource
+	giftCache              *lru.Cache[string, []blackbaud.Gift]
+	giftCacheSize          int
+	giftChecks             []transform.Check
+	giftDefaults           config.GiftDefaults
+	giftReviews            GiftReviewQueue
+	giftRules              []transform.Rule
+	hooks                  []Hook
+	lastSync               *time.Time
+	logger                 *slog.Logger
+	maxDonationSize        int
+	maxDonationsPerRun     int
+	metrics                Metrics
+	nameNormalization      config.NameNormalization
+	planChangeNoteType     string
+	poisonPills            PoisonPillRecorder
+	quotaReserve           int
+	reconcileOnly          bool
+	reconcileWindow        time.Duration
+	rememberedConstituents map[string]string
+	retries                map[string]storage.RetryEntry
+	retryBaseDelay         time.Duration
+	retryMaxAttempts       int
+	retryStore             RetryStore
+	runID                  string
+	runRecorder            RunRecorder
+	salutationFormatter    *normalize.NameFormatter
+	sample                 int
+	sampleSeed             int64
+	sinceOverride          *time.Time
+	staleConstituents      map[string]bool
+	stateStore             StateStore
+	supporterCacheTTL      time.Duration
+	syncWatermark          time.Time
+	syncedUntil            time.Time
+	trackBuffer            []storage.DonationRecord
+	trackWarnings          []string
+	tracker                DonationTracker
+	updateComments         bool
+	verify                 bool
 }
 
 // recurringContext contains context for processing a recurring donation.
@@ -98,14 +434,61 @@ func New(cfg Config) (*Service, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
+	giftRules, err := compileGiftRules(cfg.GiftDefaults.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	giftChecks, err := compileGiftChecks(cfg.GiftDefaults.Checks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	countryRoutes, err := compileCountryRoutes(cfg.GiftDefaults.CountryRoutes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	campaignWindows := make(map[string]config.CampaignWindow, len(cfg.CampaignWindows))
+	for _, window := range cfg.CampaignWindows {
+		campaignWindows[window.CampaignID] = window
+	}
+
+	eventLinks := make(map[string]string, len(cfg.ConstituentDefaults.Events))
+	for _, link := range cfg.ConstituentDefaults.Events {
+		eventLinks[link.FundraiseUpEventID] = link.EventID
+	}
+
+	commentScrubber, err := normalize.NewCommentScrubber(cfg.CommentScrubbing)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	addresseeFormatter, err := normalize.NewNameFormatter(cfg.ConstituentDefaults.AddresseeFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: addressee format: %w", err)
+	}
+	salutationFormatter, err := normalize.NewNameFormatter(cfg.ConstituentDefaults.SalutationFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: salutation format: %w", err)
+	}
+	donationNote, err := parseDonationNote(cfg.ConstituentDefaults)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: donation note format: %w", err)
+	}
+
 	logger := cfg.Logger
 	if logger == nil {
 		logger = slog.Default()
 	}
 
-	bbClient := cfg.Blackbaud
-	if cfg.DryRun {
-		bbClient = newDryRunClient(cfg.Blackbaud, logger)
+	giftCacheSize := cfg.GiftCacheSize
+	if giftCacheSize == 0 {
+		giftCacheSize = defaultGiftCacheSize
+	}
+
+	maxDonationSize := cfg.MaxDonationSize
+	if maxDonationSize == 0 {
+		maxDonationSize = defaultMaxDonationSize
 	}
 
 	maxDonations := cfg.MaxDonationsPerRun
@@ -113,27 +496,171 @@ func New(cfg Config) (*Service, error) {
 		maxDonations = defaultMaxDonationsPerRun
 	}
 
-	return &Service{
-		blackbaud:          bbClient,
-		dryRun:             cfg.DryRun,
-		fundraiseup:        cfg.FundraiseUp,
-		giftDefaults:       cfg.GiftDefaults,
-		logger:             logger,
-		maxDonationsPerRun: maxDonations,
-		sinceOverride:      cfg.SinceOverride,
-		stateStore:         cfg.StateStore,
-	}, nil
+	reconcileWindow := cfg.ReconcileWindow
+	if reconcileWindow == 0 {
+		reconcileWindow = defaultReconcileWindow
+	}
+
+	retryBaseDelay := cfg.RetryBaseDelay
+	if retryBaseDelay == 0 {
+		retryBaseDelay = defaultRetryBaseDelay
+	}
+
+	retryMaxAttempts := cfg.RetryMaxAttempts
+	if retryMaxAttempts == 0 {
+		retryMaxAttempts = defaultRetryMaxAttempts
+	}
+
+	s := &Service{
+		addresseeFormatter:  addresseeFormatter,
+		campaignWindows:     campaignWindows,
+		chargebackNoteType:  cfg.ChargebackNoteType,
+		chargebackStatus:    cfg.ChargebackStatus,
+		commentScrubber:     commentScrubber,
+		constituentDefaults: cfg.ConstituentDefaults,
+		countryRoutes:       countryRoutes,
+		deletedGiftCheckAge: cfg.DeletedGiftCheckAge,
+		deletedGiftPolicy:   cfg.DeletedGiftPolicy,
+		donationIDs:         slices.Clone(cfg.DonationIDs),
+		donationNote:        donationNote,
+		dryRun:              cfg.DryRun,
+		emailNormalization:  cfg.EmailNormalization,
+		eventLinks:          eventLinks,
+		failFast:            cfg.FailFast,
+		fetchOverlap:        cfg.FetchOverlap,
+		fundraiseup:         cfg.FundraiseUp,
+		giftCacheSize:       giftCacheSize,
+		giftChecks:          giftChecks,
+		giftDefaults:        cfg.GiftDefaults,
+		giftRules:           giftRules,
+		hooks:               cfg.Hooks,
+		logger:              logger,
+		maxDonationSize:     maxDonationSize,
+		maxDonationsPerRun:  maxDonations,
+		nameNormalization:   cfg.NameNormalization,
+		planChangeNoteType:  cfg.PlanChangeNoteType,
+		quotaReserve:        cfg.QuotaReserve,
+		reconcileOnly:       cfg.ReconcileOnly,
+		reconcileWindow:     reconcileWindow,
+		retryBaseDelay:      retryBaseDelay,
+		retryMaxAttempts:    retryMaxAttempts,
+		salutationFormatter: salutationFormatter,
+		sample:              cfg.Sample,
+		sampleSeed:          cfg.SampleSeed,
+		sinceOverride:       cfg.SinceOverride,
+		supporterCacheTTL:   cfg.SupporterCacheTTL,
+		updateComments:      cfg.UpdateComments,
+		verify:              cfg.Verify,
+	}
+
+	// Record the calls made to each dependency. The dry-run client wraps the timed one,
+	// so simulated writes are not counted as Blackbaud calls.
+	var bbClient BlackbaudClient = &timedBlackbaudClient{client: cfg.Blackbaud, metrics: &s.metrics.Blackbaud}
+	if cfg.DryRun {
+		bbClient = newDryRunClient(bbClient, logger)
+	}
+	s.blackbaud = bbClient
+	timedStore := timedStateStore{metrics: &s.metrics.StateStore, store: cfg.StateStore}
+	s.stateStore = &timedStore
+	if pending, ok := cfg.StateStore.(PendingStore); ok {
+		s.stateStore = &timedPendingStore{pending: pending, timedStateStore: timedStore}
+	}
+	if retryStore, ok := cfg.StateStore.(RetryStore); ok {
+		s.retryStore = &timedRetryStore{metrics: &s.metrics.StateStore, store: retryStore}
+	}
+	if recorder, ok := cfg.StateStore.(RunRecorder); ok {
+		s.runRecorder = recorder
+	}
+	if recorder, ok := cfg.StateStore.(PoisonPillRecorder); ok {
+		s.poisonPills = recorder
+	}
+	if queue, ok := cfg.StateStore.(GiftReviewQueue); ok {
+		s.giftReviews = queue
+	}
+	if cfg.Tracker != nil {
+		timedTracker := timedTracker{metrics: &s.metrics.Tracker, tracker: cfg.Tracker}
+		s.tracker = &timedTracker
+		if batch, ok := cfg.Tracker.(BatchTracker); ok {
+			s.tracker = &timedBatchTracker{batch: batch, timedTracker: timedTracker}
+		}
+	}
+
+	return s, nil
 }
 
 // Run executes a full sync cycle.
+// The result includes a timing breakdown of the run, which is also logged.
 func (s *Service) Run(ctx context.Context) (*Result, error) {
-	result := &Result{DryRun: s.dryRun}
+	start := time.Now()
+	s.metrics = Metrics{}
+	s.runID = runID(start)
+
+	s.createdGifts = nil
+	s.lastSync = nil
+	s.syncWatermark = time.Time{}
+	s.syncedUntil = time.Time{}
+	s.trackBuffer = nil
+	s.trackWarnings = nil
+
+	result, err := s.run(ctx)
+	// Write gifts still buffered, even when the run was cancelled, so the next run skips their donations.
+	s.flushTracked(context.WithoutCancel(ctx))
+	if result != nil {
+		result.Warnings = append(result.Warnings, s.trackWarnings...)
+		s.verifyGifts(ctx, result)
+		s.metrics.TotalDuration = time.Since(start)
+		if s.giftCache != nil {
+			s.metrics.GiftCache = s.giftCache.Stats()
+		}
+		result.Metrics = s.metrics
+		s.logMetrics(result)
+		s.logUnknownFields()
+	}
+	s.recordRun(ctx, start, result, err)
+
+	return result, err
+}
+
+// run executes a sync cycle, resuming an interrupted run if donations are still pending.
+func (s *Service) run(ctx context.Context) (*Result, error) {
+	result := &Result{DryRun: s.dryRun, RunID: s.runID}
+
+	// Gifts are cached per constituent for Blackbaud lookups, up to a limit so backfills stay within memory.
+	s.giftCache = lru.New[string, []blackbaud.Gift](s.giftCacheSize)
+
+	// Constituent IDs are cached by normalized email so repeat donors in a run are matched once.
+	s.constituentCache = make(map[string]string, s.maxDonationsPerRun)
+
+	// Constituents remembered from earlier runs are noted, so one since merged or deleted can be forgotten.
+	s.rememberedConstituents = make(map[string]string)
+	s.staleConstituents = make(map[string]bool)
+
+	// The appeals each constituent has responded to are listed once a run, when their first gift has an appeal.
+	s.constituentAppeals = make(map[string]map[string]bool)
 
-	// Initialize gift cache for Blackbaud lookups (sized for worst case: one constituent per donation).
-	s.giftCache = make(map[string][]blackbaud.Gift, s.maxDonationsPerRun)
+	// The participants of each linked event are listed once a run, when its first ticket is bought.
+	s.eventParticipants = make(map[string]map[string]bool)
+
+	if len(s.donationIDs) > 0 {
+		return s.runDonations(ctx, result)
+	}
+
+	if s.reconcileOnly {
+		return s.reconcile(ctx, result)
+	}
+
+	if stopped, err := s.retryDue(ctx, result); stopped || err != nil {
+		return result, err
+	}
+
+	pending, ok := s.pendingStore()
+	if !ok {
+		s.logger.Info("state store does not keep pending donations, runs will not be resumed")
+		return s.runFresh(ctx, result)
+	}
 
 	// Check for pending donations from a previous interrupted run.
-	pendingIDs, err := s.stateStore.PendingDonationIDs(ctx)
+	pendingIDs, err := pending.PendingDonationIDs(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("getting pending donation IDs: %w", err)
 	}
@@ -147,17 +674,49 @@ func (s *Service) Run(ctx context.Context) (*Result, error) {
 	return s.runFresh(ctx, result)
 }
 
-// runFresh executes a fresh sync cycle, fetching all donations since last sync.
+// runFresh executes a fresh sync cycle, fetching all donations since last sync,
+// or continuing an unfinished fetch from its checkpoint.
 func (s *Service) runFresh(ctx context.Context, result *Result) (*Result, error) {
-	since, err := s.stateStore.LastSyncTime(ctx)
+	var state *storage.FetchState
+	if pending, ok := s.pendingStore(); ok {
+		var err error
+		state, err = pending.FetchState(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting fetch state: %w", err)
+		}
+	}
+
+	// An override starts a new window rather than continuing an unfinished one.
+	if state == nil || s.sinceOverride != nil {
+		since, err := s.syncStart(ctx)
+		if err != nil {
+			return nil, err
+		}
+		state = &storage.FetchState{Since: since}
+	}
+
+	if s.sample > 0 {
+		return s.sampleAndProcess(ctx, result, state)
+	}
+	return s.fetchAndProcess(ctx, result, state)
+}
+
+// syncStart returns the start of the donations window for a fresh sync.
+func (s *Service) syncStart(ctx context.Context) (time.Time, error) {
+	since, err := s.previousSyncTime(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("getting last sync time: %w", err)
+		return time.Time{}, err
 	}
 
 	// Allow override for testing.
 	if s.sinceOverride != nil {
 		since = *s.sinceOverride
 		s.logger.Info("using override sync time", "since", since)
+	} else if !since.IsZero() && s.fetchOverlap > 0 {
+		// Fetch again from a little before the last sync, for donations FundraiseUp listed only after the previous
+		// run had fetched past their creation time.
+		since = since.Add(-s.fetchOverlap)
+		s.logger.Info("fetching with overlap", "since", since, "overlap", s.fetchOverlap)
 	}
 
 	if since.IsZero() {
@@ -165,60 +724,249 @@ func (s *Service) runFresh(ctx context.Context, result *Result) (*Result, error)
 		s.logger.Info("initial sync detected", "since", since)
 	}
 
-	s.logger.Info("starting fresh sync",
-		"since", since,
-		"dry_run", s.dryRun,
-		"max_donations", s.maxDonationsPerRun)
+	return since, nil
+}
 
-	donations, err := s.fundraiseup.Donations(ctx, since)
+// fetchAndProcess fetches donations page by page, starting from the checkpoint in state, and processes them.
+// Once the fetch completes and every donation is processed, the checkpoint is cleared and the sync time updated.
+// When the per-run limit cuts the fetch short, the checkpoint is kept so the next run continues the window.
+func (s *Service) fetchAndProcess(
+	ctx context.Context,
+	result *Result,
+	state *storage.FetchState,
+) (*Result, error) {
+	if _, ok := s.pendingStore(); !ok {
+		return s.streamAndProcess(ctx, result, state)
+	}
+
+	limit := s.maxDonationsPerRun - result.DonationsProcessed
+	if limit <= 0 {
+		s.logger.Info("limiting donations to max per run, continuing fetch next run",
+			"limit", s.maxDonationsPerRun,
+			"cursor", state.Cursor)
+		s.logSyncComplete(result)
+		return result, nil
+	}
+
+	if state.Cursor == "" {
+		s.logger.Info("starting fresh sync",
+			"since", state.Since,
+			"dry_run", s.dryRun,
+			"max_donations", limit)
+	} else {
+		s.logger.Info("continuing fetch from checkpoint",
+			"since", state.Since,
+			"cursor", state.Cursor,
+			"dry_run", s.dryRun,
+			"max_donations", limit)
+	}
+
+	// Stream pages and stop once the per-run limit is reached, rather than fetching the whole window.
+	// Time spent in the callback checkpointing pages is excluded from the fetch duration.
+	var donations []fundraiseup.Donation
+	var checkpointDuration time.Duration
+	limited := false
+	fetchStart := time.Now()
+	err := s.fundraiseup.DonationPages(ctx, state.Since, state.Cursor, func(page []fundraiseup.Donation) error {
+		s.metrics.FundraiseUp.Calls++
+		defer func(start time.Time) { checkpointDuration += time.Since(start) }(time.Now())
+
+		if len(donations) >= limit {
+			limited = true
+			return fundraiseup.ErrStop
+		}
+		if remaining := limit - len(donations); len(page) > remaining {
+			page = page[:remaining]
+			limited = true
+		}
+		donations = append(donations, page...)
+
+		if err := s.checkpointFetch(ctx, state, donations); err != nil {
+			return err
+		}
+		if limited {
+			return fundraiseup.ErrStop
+		}
+		return nil
+	})
+	fetchDuration := time.Since(fetchStart) - checkpointDuration
+	s.metrics.FetchDuration += fetchDuration
+	s.metrics.FundraiseUp.Duration += fetchDuration
 	if err != nil {
+		// Pages fetched before the cancellation are already pending, so the next run resumes them.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return s.interrupt(result, ctxErr)
+		}
 		return nil, fmt.Errorf("fetching donations: %w", err)
 	}
 
 	s.logger.Info("fetched donations", "count", len(donations))
 
-	if len(donations) == 0 {
+	if len(donations) == 0 && state.Cursor == "" {
 		s.logger.Info("no donations to process")
 		return result, nil
 	}
 
-	// Apply max donations limit.
-	if s.maxDonationsPerRun > 0 && len(donations) > s.maxDonationsPerRun {
-		s.logger.Info("limiting donations to max per run",
-			"total", len(donations),
-			"limit", s.maxDonationsPerRun)
-		donations = donations[:s.maxDonationsPerRun]
+	if limited {
+		s.logger.Info("limiting donations to max per run", "limit", s.maxDonationsPerRun)
+	} else {
+		// The rest of the window is done with, so once the donations are processed oldest first, the sync time
+		// can follow them: every donation created before the last one processed has been processed.
+		slices.SortStableFunc(donations, func(a, b fundraiseup.Donation) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+	}
+
+	// Process each donation.
+	for i, donation := range donations {
+		if err := ctx.Err(); err != nil {
+			return s.interrupt(result, err)
+		}
+		if s.quotaLow(result) {
+			return s.pauseForQuota(result), nil
+		}
+
+		if err := s.finishDonation(ctx, result, donation); s.failingFast(err) {
+			return s.stopOnError(result), nil
+		}
+		s.markSynced(donation)
+
+		if !limited && (i+1)%syncAdvanceInterval == 0 {
+			if err := s.advanceSyncTime(ctx, donation.CreatedAt); err != nil {
+				// Not fatal: the sync time is updated again once the window is processed.
+				s.logger.Error("failed to advance last sync time", "error", err)
+			}
+		}
+	}
+
+	if limited {
+		// Keep the checkpoint so the next run continues the window where this one stopped.
+		s.logger.Info("continuing fetch from checkpoint next run", "cursor", state.Cursor)
+		s.logSyncComplete(result)
+		return result, nil
+	}
+
+	return s.completeSync(ctx, result)
+}
+
+// streamAndProcess processes the donations window page by page as it is fetched, for state stores that do not
+// keep pending donations. A limited run could not continue the window, so it is fetched in full, but only one
+// page is held at a time. An interrupted run is not resumed: the next run fetches the window again and relies
+// on duplicate detection for the donations this one finished.
+func (s *Service) streamAndProcess(
+	ctx context.Context,
+	result *Result,
+	state *storage.FetchState,
+) (*Result, error) {
+	s.logger.Info("starting fresh sync",
+		"since", state.Since,
+		"dry_run", s.dryRun)
+
+	// Time spent in the callback processing donations is excluded from the fetch duration.
+	var processDuration time.Duration
+	fetched := 0
+	paused := false
+	stopped := false
+	fetchStart := time.Now()
+	err := s.fundraiseup.DonationPages(ctx, state.Since, "", func(page []fundraiseup.Donation) error {
+		s.metrics.FundraiseUp.Calls++
+		defer func(start time.Time) { processDuration += time.Since(start) }(time.Now())
+
+		fetched += len(page)
+		for _, donation := range page {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if s.quotaLow(result) {
+				paused = true
+				return fundraiseup.ErrStop
+			}
+
+			if err := s.finishDonation(ctx, result, donation); s.failingFast(err) {
+				stopped = true
+				return fundraiseup.ErrStop
+			}
+			s.markSynced(donation)
+		}
+		return nil
+	})
+	fetchDuration := time.Since(fetchStart) - processDuration
+	s.metrics.FetchDuration += fetchDuration
+	s.metrics.FundraiseUp.Duration += fetchDuration
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return s.interrupt(result, ctxErr)
+		}
+		return nil, fmt.Errorf("fetching donations: %w", err)
+	}
+
+	s.logger.Info("fetched donations", "count", fetched)
+
+	if paused {
+		return s.pauseForQuota(result), nil
+	}
+	if stopped {
+		return s.stopOnError(result), nil
+	}
+	if fetched == 0 {
+		s.logger.Info("no donations to process")
+		return result, nil
+	}
+
+	return s.completeSync(ctx, result)
+}
+
+// checkpointFetch stores the donations fetched so far as pending, then checkpoints the cursor after the last one,
+// so an interrupted run resumes from the last page fetched rather than refetching the whole window.
+// Pending IDs are stored first: a crash between the two writes refetches a page rather than skipping it.
+func (s *Service) checkpointFetch(
+	ctx context.Context,
+	state *storage.FetchState,
+	donations []fundraiseup.Donation,
+) error {
+	state.Cursor = donations[len(donations)-1].ID
+
+	// Skip in dry-run, or when there is nowhere to keep the checkpoint.
+	pending, ok := s.pendingStore()
+	if s.dryRun || !ok {
+		return nil
 	}
 
-	// Extract IDs for pending list.
 	pendingIDs := make([]string, len(donations))
 	for i, d := range donations {
 		pendingIDs[i] = d.ID
 	}
 
-	// Store pending list before processing (skip in dry-run).
-	if !s.dryRun {
-		if err := s.stateStore.SetPendingDonationIDs(ctx, pendingIDs); err != nil {
-			return nil, fmt.Errorf("storing pending donation IDs: %w", err)
-		}
+	if err := pending.SetPendingDonationIDs(ctx, pendingIDs); err != nil {
+		return fmt.Errorf("storing pending donation IDs: %w", err)
+	}
+	if err := pending.SetFetchState(ctx, state); err != nil {
+		return fmt.Errorf("storing fetch state: %w", err)
 	}
 
-	// Process each donation.
-	for _, donation := range donations {
-		s.processAndRecord(ctx, result, donation)
+	return nil
+}
 
-		// Remove from pending after processing (success or failure).
-		if !s.dryRun {
-			if err := s.stateStore.RemovePendingDonationID(ctx, donation.ID); err != nil {
-				s.logger.Error("failed to remove from pending", "donation_id", donation.ID, "error", err)
-			}
+// completeSync clears the fetch checkpoint and updates the sync time once every donation in the window is processed.
+// The sync time becomes the creation time of the newest donation processed rather than the current time,
+// so donations FundraiseUp only lists after the window was fetched are picked up by the next run.
+func (s *Service) completeSync(ctx context.Context, result *Result) (*Result, error) {
+	if s.updateComments || s.chargebackStatus != "" {
+		since, err := s.eventsSince(ctx)
+		if err != nil {
+			return result, err
 		}
+		s.applyDonationEvents(ctx, result, since)
 	}
 
-	// All done - update sync time.
 	if !s.dryRun {
-		if err := s.stateStore.SetLastSyncTime(ctx, time.Now()); err != nil {
-			return result, fmt.Errorf("updating last sync time: %w", err)
+		if pending, ok := s.pendingStore(); ok {
+			if err := pending.SetFetchState(ctx, nil); err != nil {
+				return result, fmt.Errorf("clearing fetch state: %w", err)
+			}
+		}
+		if err := s.advanceSyncTime(ctx, s.syncWatermark); err != nil {
+			return result, err
 		}
 	}
 
@@ -226,62 +974,190 @@ func (s *Service) runFresh(ctx context.Context, result *Result) (*Result, error)
 	return result, nil
 }
 
-// runResume resumes processing from a previous interrupted run.
+// previousSyncTime returns the last sync time stored before this run, reading it once a run.
+func (s *Service) previousSyncTime(ctx context.Context) (time.Time, error) {
+	if s.lastSync == nil {
+		lastSync, err := s.stateStore.LastSyncTime(ctx)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("getting last sync time: %w", err)
+		}
+		s.lastSync = &lastSync
+	}
+	return *s.lastSync, nil
+}
+
+// markSynced moves the sync watermark up to the creation time of a processed donation from the window.
+func (s *Service) markSynced(donation fundraiseup.Donation) {
+	if donation.CreatedAt.After(s.syncWatermark) {
+		s.syncWatermark = donation.CreatedAt
+	}
+}
+
+// advanceSyncTime stores until as the last sync time, so the next run fetches donations created from then on.
+// Donations created at until itself are fetched again and found to exist already. The sync time never moves
+// backwards, which an override into the past would otherwise do, and is left alone in dry-run mode.
+func (s *Service) advanceSyncTime(ctx context.Context, until time.Time) error {
+	if s.dryRun || until.IsZero() || !until.After(s.syncedUntil) {
+		return nil
+	}
+
+	lastSync, err := s.previousSyncTime(ctx)
+	if err != nil {
+		return err
+	}
+	if !until.After(lastSync) {
+		return nil
+	}
+
+	if err := s.stateStore.SetLastSyncTime(ctx, until); err != nil {
+		return fmt.Errorf("updating last sync time: %w", err)
+	}
+	s.syncedUntil = until
+	s.logger.Debug("advanced last sync time", "last_sync", until)
+
+	return nil
+}
+
+// logUnknownFields warns about FundraiseUp payload fields the mapper does not decode, so integration owners
+// learn when FundraiseUp adds data worth mapping. Only reported when the client decodes strictly.
+func (s *Service) logUnknownFields() {
+	if fields := s.fundraiseup.UnknownFields(); len(fields) > 0 {
+		s.logger.Warn("FundraiseUp sent fields that are not mapped", "fields", fields)
+	}
+}
+
+// runResume resumes processing from a previous interrupted run,
+// then continues an unfinished fetch from its checkpoint.
 func (s *Service) runResume(ctx context.Context, result *Result, pendingIDs []string) (*Result, error) {
 	s.logger.Info("resuming interrupted sync",
 		"pending_count", len(pendingIDs),
 		"dry_run", s.dryRun)
 
 	for _, donationID := range pendingIDs {
+		if err := ctx.Err(); err != nil {
+			return s.interrupt(result, err)
+		}
+		if s.quotaLow(result) {
+			return s.pauseForQuota(result), nil
+		}
+
 		// Fetch fresh donation data by ID.
+		fetchStart := time.Now()
 		donation, err := s.fundraiseup.Donation(ctx, donationID)
+		s.metrics.FundraiseUp.observe(fetchStart)
 		if err != nil {
+			// Keep the donation pending when the fetch failed because the run was cancelled.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return s.interrupt(result, ctxErr)
+			}
 			s.logger.Error("failed to fetch donation for resume",
 				"donation_id", donationID,
 				"error", err)
 			result.Errors = append(result.Errors, fmt.Errorf("fetching donation %s: %w", donationID, err))
-
-			// Remove from pending to avoid infinite retry loop.
-			if !s.dryRun {
-				if rmErr := s.stateStore.RemovePendingDonationID(ctx, donationID); rmErr != nil {
-					s.logger.Error("failed to remove from pending", "donation_id", donationID, "error", rmErr)
-				}
+			if s.failFast {
+				return s.stopOnError(result), nil
 			}
+
+			// Remove from pending rather than retrying every run; a transient failure is retried later instead.
+			s.updateRetry(ctx, donationID, err)
+			s.removePending(ctx, donationID)
 			continue
 		}
 
-		s.processAndRecord(ctx, result, *donation)
-
-		// Remove from pending after processing.
-		if !s.dryRun {
-			if err := s.stateStore.RemovePendingDonationID(ctx, donationID); err != nil {
-				s.logger.Error("failed to remove from pending", "donation_id", donationID, "error", err)
-			}
+		if err := s.finishDonation(ctx, result, *donation); s.failingFast(err) {
+			return s.stopOnError(result), nil
 		}
+		s.markSynced(*donation)
+	}
+
+	pending, _ := s.pendingStore()
+	state, err := pending.FetchState(ctx)
+	if err != nil {
+		return result, fmt.Errorf("getting fetch state: %w", err)
+	}
+	if state != nil {
+		return s.fetchAndProcess(ctx, result, state)
 	}
 
 	// All pending processed - update sync time.
-	if !s.dryRun {
-		if err := s.stateStore.SetLastSyncTime(ctx, time.Now()); err != nil {
-			return result, fmt.Errorf("updating last sync time: %w", err)
-		}
+	return s.completeSync(ctx, result)
+}
+
+// finishDonation processes a donation and removes it from pending (success or failure), returning the error it
+// failed with. In fail-fast mode a failed donation stays pending instead, so the next run starts with it.
+// Cancelling ctx does not cut the donation short: it runs to completion so a shutdown never
+// leaves a constituent created without its gift, and the loop stops before the next donation.
+func (s *Service) finishDonation(ctx context.Context, result *Result, donation fundraiseup.Donation) error {
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.attemptDonation(ctx, result, donation)
+	if !s.failingFast(err) {
+		s.removePending(ctx, donation.ID)
 	}
+	return err
+}
 
-	s.logSyncComplete(result)
-	return result, nil
+// attemptDonation processes a donation, then schedules it to be tried again if it failed with an error
+// expected to clear, or removes it from the retry schedule once it is done with. It returns the error the
+// donation failed with. In fail-fast mode a failed donation's retry schedule is left as it was, since the run
+// stops and the donation is tried again first thing next run.
+func (s *Service) attemptDonation(ctx context.Context, result *Result, donation fundraiseup.Donation) error {
+	processStart := time.Now()
+	err := s.processAndRecord(ctx, result, donation)
+	s.metrics.ProcessDuration += time.Since(processStart)
+
+	if !s.failingFast(err) {
+		s.updateRetry(ctx, donation.ID, err)
+	}
+	return err
+}
+
+// failingFast reports whether the run stops at a donation that failed with err, which it does in fail-fast mode.
+func (s *Service) failingFast(err error) bool {
+	return err != nil && s.failFast
 }
 
-// processAndRecord processes a single donation and records the result.
-func (s *Service) processAndRecord(ctx context.Context, result *Result, donation fundraiseup.Donation) {
-	donationResult := s.processDonation(ctx, donation)
+// pendingStore returns the state store as a PendingStore, and false when it does not keep pending donations.
+func (s *Service) pendingStore() (PendingStore, bool) {
+	pending, ok := s.stateStore.(PendingStore)
+	return pending, ok
+}
+
+// removePending removes a donation from the pending list, unless this is a dry run, a reconcile-only run,
+// or nothing is pending.
+func (s *Service) removePending(ctx context.Context, donationID string) {
+	pending, ok := s.pendingStore()
+	if s.dryRun || s.reconcileOnly || !ok {
+		return
+	}
+	if err := pending.RemovePendingDonationID(ctx, donationID); err != nil {
+		s.logger.Error("failed to remove from pending", "donation_id", donationID, "error", err)
+	}
+}
+
+// processAndRecord processes a single donation and records the result, returning the error it failed with.
+func (s *Service) processAndRecord(ctx context.Context, result *Result, donation fundraiseup.Donation) error {
+	donationResult := s.processDonationSafely(ctx, donation)
 	result.DonationsProcessed++
+	s.countCampaign(result, donation, donationResult)
 
 	if donationResult.Error != nil {
 		result.Errors = append(result.Errors, donationResult.Error)
 		s.logger.Error("failed to process donation",
 			"donation_id", donation.ID,
 			"error", donationResult.Error)
-		return
+		return donationResult.Error
+	}
+
+	// Donations outside their campaign's window and test-mode donations are skipped before a constituent is
+	// looked for.
+	if donationResult.SkippedCampaign {
+		result.DonationsSkippedCampaign++
+		return nil
+	}
+	if donationResult.SkippedTest {
+		result.DonationsSkippedTest++
+		return nil
 	}
 
 	if donationResult.ConstituentCreated {
@@ -297,6 +1173,22 @@ func (s *Service) processAndRecord(ctx context.Context, result *Result, donation
 	}
 	if donationResult.GiftSkippedExisting {
 		result.GiftsSkippedExisting++
+		if result.GiftsSkippedExistingBy == nil {
+			result.GiftsSkippedExistingBy = make(map[string]int)
+		}
+		result.GiftsSkippedExistingBy[donationResult.DuplicateFoundBy]++
+	}
+	if donationResult.GiftDeleted {
+		result.GiftsDeleted++
+	}
+	if donationResult.Excluded {
+		result.DonationsExcluded++
+	}
+	for _, warning := range donationResult.Warnings {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("donation %s: %s", donation.ID, warning))
+		s.logger.Warn("donation processed with warning",
+			"donation_id", donation.ID,
+			"warning", warning)
 	}
 
 	s.logger.Info("processed donation",
@@ -305,60 +1197,143 @@ func (s *Service) processAndRecord(ctx context.Context, result *Result, donation
 		"created", donationResult.GiftCreated,
 		"updated", donationResult.GiftUpdated,
 		"skipped_existing", donationResult.GiftSkippedExisting)
+	return nil
+}
+
+// quotaLow reports whether the Blackbaud call quota remaining, less any hedged calls it does not include yet,
+// has fallen below the reserve. Always returns false when no reserve is configured or the client does not
+// report a quota.
+func (s *Service) quotaLow(result *Result) bool {
+	s.recordQuota(result)
+
+	return s.quotaReserve > 0 &&
+		result.BlackbaudQuota != nil &&
+		result.BlackbaudQuota.Available() < s.quotaReserve
+}
+
+// recordQuota stores the Blackbaud call quota remaining in the result, if the client reports one.
+func (s *Service) recordQuota(result *Result) {
+	reporter, ok := s.blackbaud.(QuotaReporter)
+	if !ok {
+		return
+	}
+
+	if quota, ok := reporter.Quota(); ok {
+		result.BlackbaudQuota = &quota
+	}
+}
+
+// pauseForQuota marks the result as paused and logs the summary, leaving unprocessed donations pending
+// and the last sync time unchanged so the next run resumes where this one stopped.
+func (s *Service) pauseForQuota(result *Result) *Result {
+	result.PausedForQuota = true
+
+	s.logger.Warn("pausing sync until next run, Blackbaud quota below reserve",
+		"quota_remaining", result.BlackbaudQuota.Remaining,
+		"quota_reserve", s.quotaReserve,
+		"quota_reset_at", result.BlackbaudQuota.ResetAt)
+
+	s.logSyncComplete(result)
+	return result
+}
+
+// stopOnError marks the result as stopped at the first failed donation in fail-fast mode and logs the summary,
+// leaving the failed and unprocessed donations pending and the last sync time unchanged so the next run starts
+// with them.
+func (s *Service) stopOnError(result *Result) *Result {
+	result.StoppedOnError = true
+
+	s.logger.Warn("stopping sync at first failed donation, remaining donations left pending",
+		"error", result.Errors[len(result.Errors)-1])
+
+	s.logSyncComplete(result)
+	return result
+}
+
+// interrupt marks the result as interrupted and logs the summary when the run is cancelled or times out.
+// Unprocessed donations stay pending, and the fetch checkpoint and last sync time are unchanged,
+// so the next run resumes where this one stopped.
+func (s *Service) interrupt(result *Result, err error) (*Result, error) {
+	result.Interrupted = true
+
+	s.logger.Warn("sync interrupted, remaining donations left pending", "error", err)
+
+	s.logSyncComplete(result)
+	return result, fmt.Errorf("sync interrupted: %w", err)
 }
 
 // logSyncComplete logs the final sync summary.
 func (s *Service) logSyncComplete(result *Result) {
-	s.logger.Info("sync completed",
+	s.recordQuota(result)
+
+	attrs := []any{
 		"donations_processed", result.DonationsProcessed,
 		"gifts_created", result.GiftsCreated,
 		"gifts_updated", result.GiftsUpdated,
 		"gifts_skipped_existing", result.GiftsSkippedExisting,
+		"gifts_skipped_by_lookup_id", result.GiftsSkippedExistingBy[DuplicateLookupID],
+		"gifts_skipped_by_origin", result.GiftsSkippedExistingBy[DuplicateOrigin],
+		"gifts_skipped_by_tracker", result.GiftsSkippedExistingBy[DuplicateTracker],
+		"gifts_deleted", result.GiftsDeleted,
+		"gifts_charged_back", result.GiftsChargedBack,
+		"donations_excluded", result.DonationsExcluded,
+		"donations_skipped_campaign", result.DonationsSkippedCampaign,
+		"donations_skipped_test", result.DonationsSkippedTest,
 		"constituents_created", result.ConstituentsCreated,
 		"errors", len(result.Errors),
-		"dry_run", s.dryRun)
+		"warnings", len(result.Warnings),
+		"paused_for_quota", result.PausedForQuota,
+		"interrupted", result.Interrupted,
+		"stopped_on_error", result.StoppedOnError,
+		"dry_run", s.dryRun,
+		"run_id", result.RunID,
+	}
+	if result.BlackbaudQuota != nil {
+		attrs = append(attrs,
+			"quota_remaining", result.BlackbaudQuota.Remaining,
+			"quota_limit", result.BlackbaudQuota.Limit)
+	}
+
+	s.logger.Info("sync completed", attrs...)
 }
 
 // findExistingGift searches Blackbaud for a gift that was already created for this donation.
 // For one-time donations, it matches by lookup_id = donation_id.
 // For recurring donations, it matches by lookup_id = recurring_id AND origin.donation_id.
-// Returns nil if no matching gift exists.
+// Gifts whose origin names the donation match under either scheme, so switching the
+// configured reference field does not duplicate gifts created before the switch.
+// Returns the gift and how it was found, DuplicateOrigin or DuplicateLookupID, or nil if no matching gift exists.
 func (s *Service) findExistingGift(
 	ctx context.Context,
 	constituentID string,
 	donation fundraiseup.Donation,
-) (*blackbaud.Gift, error) {
+) (*blackbaud.Gift, string, error) {
 	gifts, err := s.getConstituentGifts(ctx, constituentID)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	if donation.IsRecurring() && donation.RecurringID() != "" {
-		// For recurring: lookup_id = recurring_id, match by Origin.DonationID.
-		lookupID := donation.RecurringID()
-		for i := range gifts {
-			if gifts[i].LookupID != lookupID {
-				continue
-			}
-			origin, _ := blackbaud.ParseGiftOrigin(gifts[i].Origin)
-			if origin.DonationID == donation.ID {
-				return &gifts[i], nil
-			}
-		}
-	} else {
-		// For one-time: lookup_id = donation_id.
-		for i := range gifts {
-			if gifts[i].LookupID == donation.ID {
-				return &gifts[i], nil
-			}
+	recurringID := s.recurringID(donation)
+	recurring := recurringID != ""
+	for i := range gifts {
+		origin, _ := blackbaud.ParseGiftOrigin(gifts[i].Origin)
+		switch {
+		case origin.Name == originName && origin.DonationID == donation.ID:
+			// Origin reference, or a recurring gift under either scheme.
+			return &gifts[i], DuplicateOrigin, nil
+		case recurring && gifts[i].LookupID == recurringID && origin.DonationID == donation.ID:
+			return &gifts[i], DuplicateLookupID, nil
+		case !recurring && gifts[i].LookupID == donation.ID:
+			return &gifts[i], DuplicateLookupID, nil
 		}
 	}
 
-	return nil, nil
+	return nil, "", nil
 }
 
 // findFirstRecurringGift locates the initial RecurringGift in a donation series.
 // This is needed to link subsequent RecurringGiftPayment records back to the parent gift.
+// The series is matched by lookup_id or origin.recurring_id, depending on the reference field it was created with.
 // Returns nil if no RecurringGift exists for the given recurring ID.
 func (s *Service) findFirstRecurringGift(
 	ctx context.Context,
@@ -371,8 +1346,14 @@ func (s *Service) findFirstRecurringGift(
 	}
 
 	for i := range gifts {
-		if gifts[i].LookupID == recurringID &&
-			gifts[i].Type == blackbaud.GiftTypeRecurringGift {
+		if gifts[i].Type != blackbaud.GiftTypeRecurringGift {
+			continue
+		}
+		if gifts[i].LookupID == recurringID {
+			return &gifts[i], nil
+		}
+		origin, _ := blackbaud.ParseGiftOrigin(gifts[i].Origin)
+		if origin.Name == originName && origin.RecurringID == recurringID {
 			return &gifts[i], nil
 		}
 	}
@@ -391,31 +1372,127 @@ func (s *Service) findOrCreateConstituent(
 	}
 
 	supporter := donation.Supporter
+	email := normalize.Email(supporter.Email, s.emailNormalization)
 
-	if supporter.Email != "" {
-		constituents, err := s.blackbaud.SearchConstituents(ctx, supporter.Email)
-		if err != nil {
-			return "", false, fmt.Errorf("searching constituents: %w", err)
+	if email != "" {
+		if constituentID, ok := s.constituentCache[email]; ok {
+			return constituentID, false, nil
 		}
 
-		if len(constituents) > 0 {
-			return constituents[0].ID, false, nil
+		if constituentID := s.cachedSupporter(ctx, email); constituentID != "" {
+			s.cacheConstituent(email, constituentID)
+			return constituentID, false, nil
+		}
+
+		matched, err := s.searchConstituent(ctx, email, supporter.Email)
+		if err != nil {
+			return "", false, err
+		}
+		if matched != nil {
+			s.addMissingEmail(ctx, matched, supporter.Email)
+			s.cacheConstituent(email, matched.ID)
+			s.cacheSupporter(ctx, email, supporter.ID, matched.ID)
+			return matched.ID, false, nil
 		}
 	}
 
 	constituent := supporter.ToDomainType()
+	constituent.FirstName = normalize.Name(constituent.FirstName, s.nameNormalization)
+	constituent.LastName = normalize.Name(constituent.LastName, s.nameNormalization)
+	names := normalize.NameFields{FirstName: constituent.FirstName, LastName: constituent.LastName}
+	constituent.PrimaryAddressee = customName(s.addresseeFormatter.Format(names))
+	constituent.PrimarySalutation = customName(s.salutationFormatter.Format(names))
+	if err := s.beforeConstituentCreate(ctx, donation, constituent); err != nil {
+		return "", false, err
+	}
+
 	constituentID, err := s.blackbaud.CreateConstituent(ctx, constituent)
 	if err != nil {
 		return "", false, fmt.Errorf("creating constituent: %w", err)
 	}
 
+	if email != "" {
+		s.cacheConstituent(email, constituentID)
+		s.cacheSupporter(ctx, email, supporter.ID, constituentID)
+	}
+
 	return constituentID, true, nil
 }
 
+// addConstituentCodes adds the configured constituent codes to a new constituent, starting from their first gift.
+// A code that cannot be added does not fail the donation, as the constituent already exists;
+// it is returned as a warning so the code can be added by hand.
+func (s *Service) addConstituentCodes(ctx context.Context, constituentID string, firstGift time.Time) []string {
+	var warnings []string
+
+	for _, description := range s.constituentDefaults.Codes {
+		code := &blackbaud.ConstituentCode{
+			ConstituentID: constituentID,
+			Description:   description,
+			Start: &blackbaud.FuzzyDate{
+				Day:   firstGift.Day(),
+				Month: int(firstGift.Month()),
+				Year:  firstGift.Year(),
+			},
+		}
+		if _, err := s.blackbaud.CreateConstituentCode(ctx, code); err != nil {
+			warnings = append(warnings, fmt.Sprintf("adding constituent code %q: %v", description, err))
+		}
+	}
+
+	return warnings
+}
+
+// customName returns name as a custom formatted name, or nil to keep the organisation's default format
+// when name is empty.
+func customName(name string) *blackbaud.FormattedName {
+	if name == "" {
+		return nil
+	}
+	return &blackbaud.FormattedName{CustomFormat: true, FormattedName: name}
+}
+
+// cacheConstituent records the constituent ID for a normalized email for the rest of the sync run.
+func (s *Service) cacheConstituent(email string, constituentID string) {
+	if s.constituentCache == nil {
+		s.constituentCache = make(map[string]string)
+	}
+	s.constituentCache[email] = constituentID
+}
+
+// searchConstituent returns the first constituent matching the normalized email,
+// falling back to the email as supplied when normalization changed more than its case and whitespace.
+// Returns nil if no constituent matches.
+func (s *Service) searchConstituent(
+	ctx context.Context,
+	email string,
+	original string,
+) (*blackbaud.Constituent, error) {
+	candidates := []string{email}
+	if trimmed := strings.TrimSpace(original); !strings.EqualFold(trimmed, email) {
+		candidates = append(candidates, trimmed)
+	}
+
+	for _, candidate := range candidates {
+		constituents, err := s.blackbaud.SearchConstituents(ctx, candidate, blackbaud.SearchOptions{
+			IncludeInactive: s.emailNormalization.IncludeInactive,
+			StrictEmail:     s.emailNormalization.StrictSearch,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching constituents: %w", err)
+		}
+		if len(constituents) > 0 {
+			return &constituents[0], nil
+		}
+	}
+
+	return nil, nil
+}
+
 // getConstituentGifts retrieves all gifts for a constituent from Blackbaud.
 // Results are cached per-constituent for the duration of the sync run to minimise API calls.
 func (s *Service) getConstituentGifts(ctx context.Context, constituentID string) ([]blackbaud.Gift, error) {
-	if cached, ok := s.giftCache[constituentID]; ok {
+	if cached, ok := s.giftCache.Get(constituentID); ok {
 		return cached, nil
 	}
 
@@ -425,20 +1502,52 @@ func (s *Service) getConstituentGifts(ctx context.Context, constituentID string)
 		return nil, fmt.Errorf("listing constituent gifts: %w", err)
 	}
 
-	s.giftCache[constituentID] = gifts
+	s.giftCache.Put(constituentID, gifts)
 	return gifts, nil
 }
 
+// recurringID returns the recurring plan ID a donation's gift is linked to its series by, or empty text for a
+// one-off donation, or for any donation when recurring donations are recorded as plain gifts.
+func (s *Service) recurringID(donation fundraiseup.Donation) string {
+	if s.giftDefaults.RecurringMode == config.GiftRecurringModeFlat || !donation.IsRecurring() {
+		return ""
+	}
+	return donation.RecurringID()
+}
+
+// giftOrigin returns the provenance recorded in the origin of a donation's gift: the donation, its account and
+// campaign, its recurring plan and installment when recurringID links it to a series, and the giftbridge version
+// that created it.
+func giftOrigin(donation fundraiseup.Donation, recurringID string) blackbaud.GiftOrigin {
+	origin := blackbaud.GiftOrigin{
+		Account:     donation.Account,
+		DonationID:  donation.ID,
+		Name:        originName,
+		RecurringID: recurringID,
+		Source:      originSource,
+		Version:     version.Version,
+	}
+	if donation.Campaign != nil {
+		origin.CampaignID = donation.Campaign.ID
+	}
+	if recurringID != "" {
+		origin.Installment = max(donation.InstallmentNumber(), 1)
+	}
+	return origin
+}
+
 // getRecurringContext determines the recurring donation context for gift creation.
 // For the first payment in a series, it returns isFirstInSeries=true.
 // For subsequent payments, it locates the first gift to enable linking.
 // If the first gift cannot be found, it treats this payment as the first in series.
+// In the flat recurring mode every donation is treated as one-off, so no gifts are looked up.
 func (s *Service) getRecurringContext(
 	ctx context.Context,
 	constituentID string,
 	donation fundraiseup.Donation,
 ) (recurringContext, error) {
-	if !donation.IsRecurring() || donation.RecurringID() == "" {
+	recurringID := s.recurringID(donation)
+	if recurringID == "" {
 		return recurringContext{}, nil
 	}
 
@@ -448,7 +1557,7 @@ func (s *Service) getRecurringContext(
 
 	if !isFirst {
 		// Look for the first gift in Blackbaud.
-		firstGift, err := s.findFirstRecurringGift(ctx, constituentID, donation.RecurringID())
+		firstGift, err := s.findFirstRecurringGift(ctx, constituentID, recurringID)
 		if err != nil {
 			return recurringContext{}, fmt.Errorf("finding first recurring gift: %w", err)
 		}
@@ -471,8 +1580,9 @@ func (s *Service) getRecurringContext(
 }
 
 // mapDonationToGift converts a FundraiseUp donation to a Blackbaud gift.
-// It applies gift defaults (fund, campaign, appeal) and handles recurring gift linking.
-// For recurring donations, it sets the appropriate gift type and links to the first gift.
+// It applies gift defaults (fund, campaign, appeal), country routes and splits, and handles recurring gift linking.
+// For recurring donations, it sets the appropriate gift type and links to the first gift, unless the flat recurring
+// mode records them as plain gifts of the default type.
 func (s *Service) mapDonationToGift(
 	donation fundraiseup.Donation,
 	recCtx recurringContext,
@@ -485,19 +1595,17 @@ func (s *Service) mapDonationToGift(
 	gift.BatchPrefix = originName
 	gift.IsManual = true
 	gift.GiftSplits = []blackbaud.GiftSplit{{
-		Amount:     gift.Amount,
-		FundID:     s.giftDefaults.FundID,
-		CampaignID: s.giftDefaults.CampaignID,
+		Amount:     &blackbaud.GiftAmount{Value: gift.Amount.Value},
 		AppealID:   s.giftDefaults.AppealID,
+		CampaignID: s.giftDefaults.CampaignID,
+		FundID:     s.giftDefaults.FundID,
 	}}
 
-	if donation.IsRecurring() && donation.RecurringID() != "" {
-		gift.LookupID = donation.RecurringID()
+	recurringID := s.recurringID(donation)
+	if recurringID != "" {
+		gift.LookupID = recurringID
 		gift.Subtype = blackbaud.GiftSubtypeRecurring
-		gift.Origin = blackbaud.GiftOrigin{
-			DonationID: donation.ID,
-			Name:       originName,
-		}.String()
+		gift.Origin = giftOrigin(donation, recurringID).String()
 
 		if recCtx.isFirstInSeries {
 			gift.Type = blackbaud.GiftTypeRecurringGift
@@ -512,6 +1620,44 @@ func (s *Service) mapDonationToGift(
 		gift.LookupID = donation.ID
 	}
 
+	// Leave the lookup ID to Raiser's Edge NXT when the organisation uses it for its own references,
+	// and record the FundraiseUp IDs in the origin instead.
+	if s.giftDefaults.ReferenceField == config.GiftReferenceFieldOrigin {
+		gift.LookupID = ""
+		gift.Origin = giftOrigin(donation, recurringID).String()
+	}
+
+	// Tag gifts from further FundraiseUp accounts with the account, so their source can be told apart.
+	if donation.Account != "" && gift.Origin == "" {
+		gift.Origin = giftOrigin(donation, recurringID).String()
+	}
+
+	// Leave the post status to the Raiser's Edge default unless the organisation's GL workflow needs one.
+	if s.giftDefaults.PostStatus != "" {
+		gift.PostStatus = blackbaud.GiftPostStatus(s.giftDefaults.PostStatus)
+	}
+	if gift.PostStatus == blackbaud.GiftPostStatusNotPosted {
+		gift.PostDate = gift.Date
+		if s.giftDefaults.PostDate == config.GiftPostDateSync {
+			gift.PostDate = time.Now().UTC().Format("2006-01-02")
+		}
+	}
+
+	// Route by country before the rules, so they see the routed fund, campaign and appeal and can override them.
+	s.routeByCountry(donation, gift)
+
+	if err := s.applyGiftRules(donation, gift); err != nil {
+		return nil, err
+	}
+
+	// Split after the rules, so the fund, campaign and appeal they choose receive the remainder.
+	if err := s.splitGift(gift, donation.Currency); err != nil {
+		return nil, err
+	}
+
+	// Route test gifts last, so nothing else can send them to a real fund.
+	s.routeTestDonation(donation, gift)
+
 	return gift, nil
 }
 
@@ -523,6 +1669,71 @@ func (s *Service) processDonation(
 	donation fundraiseup.Donation,
 ) DonationResult {
 	result := DonationResult{DonationID: donation.ID}
+	if s.skipOutsideCampaignWindow(donation) {
+		s.logger.Info("donation outside its campaign's window, skipping",
+			"donation_id", donation.ID,
+			"campaign_id", donationCampaignID(donation))
+		result.SkippedCampaign = true
+		return result
+	}
+	if s.skipTestDonation(donation) {
+		s.logger.Info("test-mode donation, skipping", "donation_id", donation.ID)
+		result.SkippedTest = true
+		return result
+	}
+	donation, sizeWarning, err := s.limitDonationSize(donation)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	if sizeWarning != "" {
+		result.Warnings = append(result.Warnings, sizeWarning)
+	}
+	donation.Comment = s.commentScrubber.Scrub(donation.Comment)
+
+	return s.syncDonation(ctx, donation, result)
+}
+
+// syncDonation creates the gift for a donation that is to be synced, adding to the result begun by processDonation.
+// A constituent remembered from an earlier run that Blackbaud no longer has is forgotten and the donation synced
+// again from the start, so the supporter is searched for instead.
+func (s *Service) syncDonation(
+	ctx context.Context,
+	donation fundraiseup.Donation,
+	result DonationResult,
+) DonationResult {
+	initial := result
+
+	// A tracked donation already has a gift, so skip it unless the gift was deleted and is to be recreated.
+	var replacedGiftID string
+	if s.tracker != nil {
+		record, err := s.lookupTracked(ctx, donation.ID)
+		if err != nil {
+			result.Error = fmt.Errorf("looking up tracked donation: %w", err)
+			return result
+		}
+		if record != nil {
+			if s.skipTracked(ctx, donation, *record, &result) {
+				return result
+			}
+			replacedGiftID = record.GiftID
+		}
+	}
+
+	// Check what can be checked from the donation alone, including the gift date, before anything is created for it.
+	if err := s.checkDonation(donation); err != nil {
+		s.refuseGift(ctx, donation, err)
+		result.Error = err
+		return result
+	}
+	dateWarning, err := s.guardGiftDate(donation)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	if dateWarning != "" {
+		result.Warnings = append(result.Warnings, dateWarning)
+	}
 
 	// Find or create constituent first - we need the ID for Blackbaud queries.
 	constituentID, created, err := s.findOrCreateConstituent(ctx, donation)
@@ -531,9 +1742,16 @@ func (s *Service) processDonation(
 		return result
 	}
 	result.ConstituentCreated = created
+	if created {
+		result.Warnings = append(result.Warnings, donation.Supporter.Address.Issues()...)
+		result.Warnings = append(result.Warnings, s.addConstituentCodes(ctx, constituentID, donation.CreatedAt)...)
+	}
 
 	// Check if gift already exists in Blackbaud.
-	existingGift, err := s.findExistingGift(ctx, constituentID, donation)
+	existingGift, foundBy, err := s.findExistingGift(ctx, constituentID, donation)
+	if s.dropStaleSupporter(ctx, constituentID, err) {
+		return s.syncDonation(ctx, donation, initial)
+	}
 	if err != nil {
 		result.Error = fmt.Errorf("checking for existing gift: %w", err)
 		return result
@@ -543,9 +1761,14 @@ func (s *Service) processDonation(
 		// Gift already exists - skip.
 		s.logger.Warn("gift already exists in Blackbaud, skipping",
 			"donation_id", donation.ID,
-			"existing_gift_id", existingGift.ID)
+			"existing_gift_id", existingGift.ID,
+			"found_by", foundBy)
+		result.DuplicateFoundBy = foundBy
 		result.GiftID = existingGift.ID
 		result.GiftSkippedExisting = true
+
+		// Backfill the tracker so later runs skip this donation without querying Blackbaud.
+		s.trackDonation(ctx, &result, donation, constituentID, existingGift.ID, existingGift.Type, replacedGiftID)
 		return result
 	}
 
@@ -562,18 +1785,184 @@ func (s *Service) processDonation(
 		return result
 	}
 	gift.ConstituentID = constituentID
-
+	if err := s.beforeGiftCreate(ctx, donation, gift); err != nil {
+		result.Error = err
+		return result
+	}
+	// Shorten a reference Raiser's Edge NXT would reject once the hooks have finished with it, keeping the full text
+	// for a gift note.
+	fullReference := truncateReference(gift)
+	// Check the gift once the hooks have finished with it, so the checks see what would be created.
+	if err := s.checkGift(donation, gift); err != nil {
+		s.refuseGift(ctx, donation, err)
+		result.Error = err
+		return result
+	}
 	giftID, err := s.blackbaud.CreateGift(ctx, gift)
+	if s.dropStaleSupporter(ctx, constituentID, err) {
+		return s.syncDonation(ctx, donation, initial)
+	}
 	if err != nil {
 		result.Error = fmt.Errorf("creating gift: %w", err)
 		return result
 	}
 	result.GiftID = giftID
 	result.GiftCreated = true
+	s.recordCreatedGift(donation.ID, giftID, gift)
+	result.Warnings = append(result.Warnings, s.recordAppealResponses(ctx, constituentID, created, gift)...)
+	result.Warnings = append(result.Warnings, s.registerEventParticipant(ctx, constituentID, donation)...)
+	result.Warnings = append(result.Warnings, s.recordDonationNote(ctx, constituentID, donation)...)
+	result.Warnings = append(result.Warnings, s.recordReferenceNote(ctx, giftID, donation, fullReference)...)
+	result.Warnings = append(result.Warnings, s.recordCreator(ctx, giftID)...)
+	result.Warnings = append(result.Warnings, s.recordPlanChange(ctx, constituentID, donation)...)
+	result.Warnings = append(result.Warnings, s.afterGiftCreate(ctx, donation, giftID, gift)...)
+
+	s.trackDonation(ctx, &result, donation, constituentID, giftID, gift.Type, replacedGiftID)
 
 	return result
 }
 
+// trackDonation records the gift for a donation in the tracker, if one is configured.
+// A non-empty replacedGiftID is the deleted gift the new one replaces.
+// Failures are logged rather than returned: the gift exists in Blackbaud, where findExistingGift will find it.
+// A donation already tracked with another gift is skipped with a warning on result.
+func (s *Service) trackDonation(
+	ctx context.Context,
+	result *DonationResult,
+	donation fundraiseup.Donation,
+	constituentID string,
+	giftID string,
+	giftType blackbaud.GiftType,
+	replacedGiftID string,
+) {
+	// Dry-run gift IDs are placeholders, so they must never be tracked.
+	if s.tracker == nil || s.dryRun {
+		return
+	}
+
+	record := storage.DonationRecord{
+		Amount:        donation.Amount,
+		ConstituentID: constituentID,
+		CreatedAt:     donation.CreatedAt,
+		Currency:      donation.Currency,
+		DonationID:    donation.ID,
+		GiftID:        giftID,
+		GiftType:      string(giftType),
+	}
+	if donation.Supporter != nil {
+		record.SupporterID = donation.Supporter.ID
+	}
+
+	if donation.IsRecurring() {
+		record.Frequency = donation.RecurringPlan.Frequency
+		record.RecurringID = donation.RecurringID()
+	}
+
+	// Replacements are conditional on the deleted gift, so they are written on their own.
+	replacer, ok := s.tracker.(GiftReplacer)
+	replacing := ok && replacedGiftID != ""
+	if _, ok := s.tracker.(BatchTracker); ok && !replacing {
+		s.bufferTracked(ctx, record)
+		return
+	}
+
+	var err error
+	switch {
+	case replacing:
+		err = replacer.ReplaceGift(ctx, record, replacedGiftID)
+	case record.RecurringID != "":
+		err = s.tracker.TrackRecurring(ctx, record)
+	default:
+		err = s.tracker.Track(ctx, record)
+	}
+	if warning := s.logTrackError(record, err); warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+}
+
+// logTrackError logs a failure to track a donation's gift.
+// A donation already tracked with another gift is skipped, as a concurrent run or replay tracked it first;
+// the gift this run found or created may duplicate it in Blackbaud, so a warning for the run summary is returned.
+func (s *Service) logTrackError(record storage.DonationRecord, err error) string {
+	var trackedErr *storage.AlreadyTrackedError
+	switch {
+	case err == nil:
+	case errors.As(err, &trackedErr):
+		s.logger.Warn("donation already tracked with another gift, check for a duplicate",
+			"donation_id", record.DonationID,
+			"gift_id", record.GiftID,
+			"tracked_gift_id", trackedErr.GiftID)
+		return fmt.Sprintf("already tracked with gift %s, so gift %s was not tracked; check it is not a duplicate",
+			trackedErr.GiftID, record.GiftID)
+	default:
+		s.logger.Error("failed to track donation",
+			"donation_id", record.DonationID,
+			"gift_id", record.GiftID,
+			"error", err)
+	}
+	return ""
+}
+
+// bufferTracked queues a record for the next batch write, writing the queue once it holds a full batch.
+func (s *Service) bufferTracked(ctx context.Context, record storage.DonationRecord) {
+	s.trackBuffer = append(s.trackBuffer, record)
+	if len(s.trackBuffer) >= trackBatchSize {
+		s.flushTracked(ctx)
+	}
+}
+
+// flushTracked writes the buffered records in one batch, if the tracker writes in batches.
+// Failures are logged rather than returned, as in trackDonation. Warnings for donations already tracked
+// with another gift are added to the run's result once the run ends.
+func (s *Service) flushTracked(ctx context.Context) {
+	batch, ok := s.tracker.(BatchTracker)
+	if !ok || len(s.trackBuffer) == 0 {
+		return
+	}
+
+	records := s.trackBuffer
+	s.trackBuffer = nil
+	err := batch.TrackBatch(ctx, records)
+	if err == nil {
+		return
+	}
+
+	var batchErr *storage.BatchTrackError
+	if !errors.As(err, &batchErr) {
+		s.logger.Error("failed to track donations",
+			"count", len(records),
+			"error", err)
+		return
+	}
+
+	byDonation := make(map[string]storage.DonationRecord, len(records))
+	for _, record := range records {
+		byDonation[record.DonationID] = record
+	}
+	for _, trackedErr := range batchErr.AlreadyTracked {
+		if warning := s.logTrackError(byDonation[trackedErr.DonationID], trackedErr); warning != "" {
+			s.trackWarnings = append(s.trackWarnings, fmt.Sprintf("donation %s: %s", trackedErr.DonationID, warning))
+		}
+	}
+	for _, err := range batchErr.Failed {
+		s.logger.Error("failed to track donations",
+			"count", len(records),
+			"error", err)
+	}
+}
+
+// lookupTracked returns the tracked record for a donation, including one still buffered,
+// or nil if the donation has not been tracked.
+func (s *Service) lookupTracked(ctx context.Context, donationID string) (*storage.DonationRecord, error) {
+	for i := range s.trackBuffer {
+		if s.trackBuffer[i].DonationID == donationID {
+			record := s.trackBuffer[i]
+			return &record, nil
+		}
+	}
+	return s.tracker.Lookup(ctx, donationID)
+}
+
 // defaultSyncStart returns the default start time for initial syncs.
 func defaultSyncStart() time.Time {
 	return time.Now().AddDate(0, 0, defaultSyncDays)
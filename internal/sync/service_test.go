@@ -1,23 +1,40 @@
 package sync
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/peteski22/giftbridge/internal/blackbaud"
 	"github.com/peteski22/giftbridge/internal/config"
 	"github.com/peteski22/giftbridge/internal/fundraiseup"
+	"github.com/peteski22/giftbridge/internal/lru"
+	"github.com/peteski22/giftbridge/internal/normalize"
+	"github.com/peteski22/giftbridge/internal/storage"
+	"github.com/peteski22/giftbridge/internal/version"
 )
 
 // mockStateStore implements StateStore for testing.
 type mockStateStore struct {
+	fetchState *storage.FetchState
 	lastSync   time.Time
 	pendingIDs []string
+	runs       []storage.RunSummary
 	setErr     error
+	syncTimes  []time.Time
 }
 
 // LastSyncTime returns the last sync time.
@@ -31,6 +48,7 @@ func (m *mockStateStore) SetLastSyncTime(_ context.Context, t time.Time) error {
 		return m.setErr
 	}
 	m.lastSync = t
+	m.syncTimes = append(m.syncTimes, t)
 	return nil
 }
 
@@ -57,22 +75,259 @@ func (m *mockStateStore) RemovePendingDonationID(_ context.Context, id string) e
 	return nil
 }
 
+// FetchState returns the fetch checkpoint.
+func (m *mockStateStore) FetchState(_ context.Context) (*storage.FetchState, error) {
+	return m.fetchState, nil
+}
+
+// SetFetchState sets the fetch checkpoint.
+func (m *mockStateStore) SetFetchState(_ context.Context, state *storage.FetchState) error {
+	if state != nil {
+		copied := *state
+		state = &copied
+	}
+	m.fetchState = state
+	return nil
+}
+
+// RecordRun records a run summary.
+func (m *mockStateStore) RecordRun(_ context.Context, summary storage.RunSummary) error {
+	m.runs = append(m.runs, summary)
+	return nil
+}
+
+// mockPoisonPillStateStore implements StateStore and PoisonPillRecorder.
+type mockPoisonPillStateStore struct {
+	mockStateStore
+
+	pills []storage.PoisonPill
+}
+
+// RecordPoisonPill records the poison pill.
+func (m *mockPoisonPillStateStore) RecordPoisonPill(_ context.Context, pill storage.PoisonPill) error {
+	m.pills = append(m.pills, pill)
+	return nil
+}
+
+// mockGiftReviewStateStore implements StateStore and GiftReviewQueue.
+type mockGiftReviewStateStore struct {
+	mockStateStore
+
+	reviews []storage.GiftReview
+}
+
+// HoldForReview records the held donation.
+func (m *mockGiftReviewStateStore) HoldForReview(_ context.Context, review storage.GiftReview) error {
+	m.reviews = append(m.reviews, review)
+	return nil
+}
+
+// mockRetryStateStore implements StateStore, PendingStore and RetryStore for testing.
+type mockRetryStateStore struct {
+	mockStateStore
+
+	schedule map[string]storage.RetryEntry
+}
+
+// RetrySchedule returns the retry schedule.
+func (m *mockRetryStateStore) RetrySchedule(_ context.Context) (map[string]storage.RetryEntry, error) {
+	return maps.Clone(m.schedule), nil
+}
+
+// SetRetrySchedule sets the retry schedule.
+func (m *mockRetryStateStore) SetRetrySchedule(_ context.Context, schedule map[string]storage.RetryEntry) error {
+	m.schedule = maps.Clone(schedule)
+	return nil
+}
+
+// mockSyncTimeStore implements StateStore without PendingStore, like a custom store that only keeps the sync time.
+type mockSyncTimeStore struct {
+	lastSync time.Time
+}
+
+// LastSyncTime returns the last sync time.
+func (m *mockSyncTimeStore) LastSyncTime(_ context.Context) (time.Time, error) {
+	return m.lastSync, nil
+}
+
+// SetLastSyncTime sets the last sync time.
+func (m *mockSyncTimeStore) SetLastSyncTime(_ context.Context, t time.Time) error {
+	m.lastSync = t
+	return nil
+}
+
+// mockTracker implements DonationTracker for testing.
+type mockTracker struct {
+	records   map[string]storage.DonationRecord
+	recurring []string
+}
+
+// Lookup returns the tracked record for a donation.
+func (m *mockTracker) Lookup(_ context.Context, donationID string) (*storage.DonationRecord, error) {
+	record, ok := m.records[donationID]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// ReplaceGift records a new gift for a donation still tracked with previousGiftID.
+func (m *mockTracker) ReplaceGift(_ context.Context, record storage.DonationRecord, previousGiftID string) error {
+	if existing, ok := m.records[record.DonationID]; ok && existing.GiftID != previousGiftID {
+		return &storage.AlreadyTrackedError{DonationID: record.DonationID, GiftID: existing.GiftID}
+	}
+	m.records[record.DonationID] = record
+	return nil
+}
+
+// Track records a one-off donation, unless it is tracked with another gift.
+func (m *mockTracker) Track(_ context.Context, record storage.DonationRecord) error {
+	if err := m.checkGift(record); err != nil {
+		return err
+	}
+	m.records[record.DonationID] = record
+	return nil
+}
+
+// TrackRecurring records a recurring donation payment, unless it is tracked with another gift.
+func (m *mockTracker) TrackRecurring(_ context.Context, record storage.DonationRecord) error {
+	if err := m.checkGift(record); err != nil {
+		return err
+	}
+	m.records[record.DonationID] = record
+	m.recurring = append(m.recurring, record.DonationID)
+	return nil
+}
+
+// checkGift returns an AlreadyTrackedError if the donation is tracked with a gift other than the record's.
+func (m *mockTracker) checkGift(record storage.DonationRecord) error {
+	if existing, ok := m.records[record.DonationID]; ok && existing.GiftID != record.GiftID {
+		return &storage.AlreadyTrackedError{DonationID: record.DonationID, GiftID: existing.GiftID}
+	}
+	return nil
+}
+
+// staleTracker is a tracker whose lookups return a record a concurrent run has since replaced.
+type staleTracker struct {
+	mockTracker
+
+	stale storage.DonationRecord
+}
+
+// Lookup returns the stale record.
+func (s *staleTracker) Lookup(_ context.Context, _ string) (*storage.DonationRecord, error) {
+	record := s.stale
+	return &record, nil
+}
+
+// racingTracker is a tracker whose donations are tracked by a concurrent run after they are looked up.
+type racingTracker struct {
+	mockTracker
+}
+
+// Lookup finds nothing, as the concurrent run has not yet tracked the donation.
+func (r *racingTracker) Lookup(_ context.Context, _ string) (*storage.DonationRecord, error) {
+	return nil, nil
+}
+
+// historyTracker is a mockTracker that lists the donations tracked for each recurring plan.
+type historyTracker struct {
+	mockTracker
+
+	historyErr error
+}
+
+// RecurringDonations returns the tracked records of the recurring plan, failing with historyErr when set.
+func (h *historyTracker) RecurringDonations(_ context.Context, recurringID string) ([]storage.DonationRecord, error) {
+	if h.historyErr != nil {
+		return nil, h.historyErr
+	}
+	var records []storage.DonationRecord
+	for _, record := range h.records {
+		if record.RecurringID == recurringID {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// mockBatchTracker implements BatchTracker for testing.
+type mockBatchTracker struct {
+	mockTracker
+
+	batches []int
+}
+
+// TrackBatch records each donation not tracked with another gift, and the size of the batch.
+func (m *mockBatchTracker) TrackBatch(_ context.Context, records []storage.DonationRecord) error {
+	batchErr := &storage.BatchTrackError{}
+	for _, record := range records {
+		var trackedErr *storage.AlreadyTrackedError
+		if errors.As(m.checkGift(record), &trackedErr) {
+			batchErr.AlreadyTracked = append(batchErr.AlreadyTracked, trackedErr)
+			continue
+		}
+		m.records[record.DonationID] = record
+	}
+	m.batches = append(m.batches, len(records))
+	if len(batchErr.AlreadyTracked) == 0 {
+		return nil
+	}
+	// Wrapped, as a tracker adding context would, so the service must not rely on the concrete type.
+	return fmt.Errorf("tracking batch: %w", batchErr)
+}
+
 // mockBlackbaudClient implements BlackbaudClient for testing.
 type mockBlackbaudClient struct {
-	gifts        map[string][]blackbaud.Gift
-	constituents []blackbaud.Constituent
+	gifts         map[string][]blackbaud.Gift
+	codeErr       error
+	createGiftErr error
+	codes         []*blackbaud.ConstituentCode
+	constituents  []blackbaud.Constituent
+	created       []*blackbaud.Constituent
+	createdGifts  []*blackbaud.Gift
+	searchOpts    []blackbaud.SearchOptions
+	searches      []string
+	storedGifts   map[string]*blackbaud.Gift
+	updatedGifts  map[string]*blackbaud.Gift
 }
 
 // CreateConstituent creates a new constituent.
-func (m *mockBlackbaudClient) CreateConstituent(_ context.Context, _ *blackbaud.Constituent) (string, error) {
+func (m *mockBlackbaudClient) CreateConstituent(_ context.Context, constituent *blackbaud.Constituent) (string, error) {
+	m.created = append(m.created, constituent)
 	return "constituent-123", nil
 }
 
-// CreateGift creates a new gift.
-func (m *mockBlackbaudClient) CreateGift(_ context.Context, _ *blackbaud.Gift) (string, error) {
+// CreateConstituentCode records the constituent code, failing with codeErr when set.
+func (m *mockBlackbaudClient) CreateConstituentCode(
+	_ context.Context,
+	code *blackbaud.ConstituentCode,
+) (string, error) {
+	if m.codeErr != nil {
+		return "", m.codeErr
+	}
+	m.codes = append(m.codes, code)
+	return "code-123", nil
+}
+
+// CreateGift records the gift and returns a fixed ID, failing with createGiftErr when set.
+func (m *mockBlackbaudClient) CreateGift(_ context.Context, gift *blackbaud.Gift) (string, error) {
+	if m.createGiftErr != nil {
+		return "", m.createGiftErr
+	}
+	m.createdGifts = append(m.createdGifts, gift)
 	return "gift-123", nil
 }
 
+// Gift returns the stored gift with the given ID.
+func (m *mockBlackbaudClient) Gift(_ context.Context, giftID string) (*blackbaud.Gift, error) {
+	gift, ok := m.storedGifts[giftID]
+	if !ok {
+		return nil, &blackbaud.StatusError{Body: "gift not found", StatusCode: http.StatusNotFound}
+	}
+	return gift, nil
+}
+
 // ListGiftsByConstituent lists gifts for a constituent.
 func (m *mockBlackbaudClient) ListGiftsByConstituent(
 	_ context.Context,
@@ -86,12 +341,22 @@ func (m *mockBlackbaudClient) ListGiftsByConstituent(
 }
 
 // SearchConstituents searches for constituents.
-func (m *mockBlackbaudClient) SearchConstituents(_ context.Context, _ string) ([]blackbaud.Constituent, error) {
+func (m *mockBlackbaudClient) SearchConstituents(
+	_ context.Context,
+	email string,
+	opts blackbaud.SearchOptions,
+) ([]blackbaud.Constituent, error) {
+	m.searchOpts = append(m.searchOpts, opts)
+	m.searches = append(m.searches, email)
 	return m.constituents, nil
 }
 
-// UpdateGift updates a gift.
-func (m *mockBlackbaudClient) UpdateGift(_ context.Context, _ string, _ *blackbaud.Gift) error {
+// UpdateGift records the update by gift ID.
+func (m *mockBlackbaudClient) UpdateGift(_ context.Context, giftID string, gift *blackbaud.Gift) error {
+	if m.updatedGifts == nil {
+		m.updatedGifts = make(map[string]*blackbaud.Gift)
+	}
+	m.updatedGifts[giftID] = gift
 	return nil
 }
 
@@ -144,6 +409,59 @@ func TestNew(t *testing.T) {
 			wantErr: true,
 			errMsg:  "gift defaults fund ID is required",
 		},
+		"invalid gift rule": {
+			config: Config{
+				Blackbaud:   &blackbaud.Client{},
+				FundraiseUp: &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{
+					FundID: "fund-123",
+					Rules:  []config.GiftRule{{Field: "fund_id", Value: "donation.fee"}},
+					Type:   "Donation",
+				},
+				StateStore: &mockStateStore{},
+			},
+			wantErr: true,
+			errMsg:  "gift rule 1: value: undeclared reference to 'donation' (in container '') at position 1",
+		},
+		"unrecognised route country": {
+			config: Config{
+				Blackbaud:   &blackbaud.Client{},
+				FundraiseUp: &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{
+					CountryRoutes: []config.CountryRoute{{Countries: []string{"Atlantis"}, FundID: "fund-gift-aid"}},
+					FundID:        "fund-123",
+					Type:          "Donation",
+				},
+				StateStore: &mockStateStore{},
+			},
+			wantErr: true,
+			errMsg:  `country route 1: unrecognised country "Atlantis"`,
+		},
+		"unknown salutation format field": {
+			config: Config{
+				Blackbaud:           &blackbaud.Client{},
+				ConstituentDefaults: config.ConstituentDefaults{SalutationFormat: "Dear {{.Title}} {{.LastName}}"},
+				FundraiseUp:         &fundraiseup.Client{},
+				GiftDefaults:        validGiftDefaults,
+				StateStore:          &mockStateStore{},
+			},
+			wantErr: true,
+			errMsg:  "salutation format: checking fields",
+		},
+		"unknown donation note format field": {
+			config: Config{
+				Blackbaud: &blackbaud.Client{},
+				ConstituentDefaults: config.ConstituentDefaults{
+					DonationNoteFormat: "Donated via {{.Channel}}",
+					DonationNoteType:   "Online giving",
+				},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: validGiftDefaults,
+				StateStore:   &mockStateStore{},
+			},
+			wantErr: true,
+			errMsg:  "donation note format: checking fields",
+		},
 		"missing state store": {
 			config: Config{
 				Blackbaud:    &blackbaud.Client{},
@@ -199,6 +517,285 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"sample without dry run": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123"},
+				Sample:       10,
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"sample requires dry run"},
+		},
+		"donation IDs with reconcile only": {
+			config: Config{
+				Blackbaud:     &blackbaud.Client{},
+				DonationIDs:   []string{"don_1"},
+				FundraiseUp:   &fundraiseup.Client{},
+				GiftDefaults:  config.GiftDefaults{FundID: "fund-123"},
+				ReconcileOnly: true,
+				StateStore:    &mockStateStore{},
+				Tracker:       &mockTracker{},
+			},
+			wantErr:      true,
+			errFragments: []string{"donation IDs cannot be combined with reconcile only"},
+		},
+		"unknown recurring mode": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123", RecurringMode: "series"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{`unknown recurring mode "series"`},
+		},
+		"unknown gift date policy": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{DatePolicy: "warn", FundID: "fund-123"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{`unknown gift date policy "warn"`},
+		},
+		"unknown test donations policy": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123", TestDonations: "route"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{`unknown test donations policy "route"`},
+		},
+		"test fund without syncing test donations": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123", TestFundID: "fund-sandbox"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"test fund requires syncing test donations"},
+		},
+		"appeal responses without a client that can record them": {
+			config: Config{
+				Blackbaud:    &mockBlackbaudClient{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{AppealResponses: true, FundID: "fund-123"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"appeal responses require a blackbaud client that can record appeal responses"},
+		},
+		"supporter cache without a tracker that can cache constituents": {
+			config: Config{
+				Blackbaud:         &blackbaud.Client{},
+				FundraiseUp:       &fundraiseup.Client{},
+				GiftDefaults:      config.GiftDefaults{FundID: "fund-123"},
+				StateStore:        &mockStateStore{},
+				SupporterCacheTTL: time.Hour,
+				Tracker:           &mockTracker{},
+			},
+			wantErr:      true,
+			errFragments: []string{"supporter cache requires a donation tracker that can cache constituents"},
+		},
+		"adding missing emails without a client that can add email addresses": {
+			config: Config{
+				Blackbaud:          &mockBlackbaudClient{},
+				EmailNormalization: config.EmailNormalization{AddMissing: true},
+				FundraiseUp:        &fundraiseup.Client{},
+				GiftDefaults:       config.GiftDefaults{FundID: "fund-123"},
+				StateStore:         &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"adding missing emails requires a blackbaud client that can add emails"},
+		},
+		"event links without a client that can add participants": {
+			config: Config{
+				Blackbaud: &mockBlackbaudClient{},
+				ConstituentDefaults: config.ConstituentDefaults{
+					Events: []config.EventLink{{EventID: "EVENT-1", FundraiseUpEventID: "EVTGALA"}},
+				},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"event links require a blackbaud client that can add event participants"},
+		},
+		"verify in dry run": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				DryRun:       true,
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123"},
+				StateStore:   &mockStateStore{},
+				Verify:       true,
+			},
+			wantErr:      true,
+			errFragments: []string{"verify requires a real run"},
+		},
+		"negative sample": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				DryRun:       true,
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123"},
+				Sample:       -1,
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"sample must not be negative"},
+		},
+		"recreate without a tracker that can replace gifts": {
+			config: Config{
+				Blackbaud:         &blackbaud.Client{},
+				DeletedGiftPolicy: config.DeletedGiftPolicyRecreate,
+				FundraiseUp:       &fundraiseup.Client{},
+				GiftDefaults:      config.GiftDefaults{FundID: "fund-123"},
+				StateStore:        &mockStateStore{},
+				Tracker:           struct{ DonationTracker }{},
+			},
+			wantErr:      true,
+			errFragments: []string{"recreate deleted gift policy requires a tracker that can replace gifts"},
+		},
+		"donation notes without note support": {
+			config: Config{
+				Blackbaud:           &mockBlackbaudClient{},
+				ConstituentDefaults: config.ConstituentDefaults{DonationNoteType: "Online giving"},
+				FundraiseUp:         &fundraiseup.Client{},
+				GiftDefaults:        config.GiftDefaults{FundID: "fund-123"},
+				StateStore:          &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"donation notes require a blackbaud client that can add notes"},
+		},
+		"creator custom field without gift custom field support": {
+			config: Config{
+				Blackbaud:    &mockBlackbaudClient{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{CreatorCustomField: "Created by", FundID: "fund-123"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"creator custom fields require a blackbaud client that can add custom fields"},
+		},
+		"reference notes without gift note support": {
+			config: Config{
+				Blackbaud:    &mockBlackbaudClient{},
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123", ReferenceNoteType: "Comment"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"reference notes require a blackbaud client that can add gift notes"},
+		},
+		"plan change notes without recurring history or note support": {
+			config: Config{
+				Blackbaud:          &mockBlackbaudClient{},
+				FundraiseUp:        &fundraiseup.Client{},
+				GiftDefaults:       config.GiftDefaults{FundID: "fund-123"},
+				PlanChangeNoteType: "Stewardship",
+				StateStore:         &mockStateStore{},
+				Tracker:            struct{ DonationTracker }{},
+			},
+			wantErr: true,
+			errFragments: []string{
+				"plan change notes require a tracker that can list recurring donations",
+				"plan change notes require a blackbaud client that can add notes",
+			},
+		},
+		"reconcile only without a tracker": {
+			config: Config{
+				Blackbaud:       &blackbaud.Client{},
+				FundraiseUp:     &fundraiseup.Client{},
+				GiftDefaults:    config.GiftDefaults{FundID: "fund-123"},
+				ReconcileOnly:   true,
+				ReconcileWindow: -time.Hour,
+				StateStore:      &mockStateStore{},
+			},
+			wantErr: true,
+			errFragments: []string{
+				"reconcile only requires a donation tracker",
+				"reconcile window must not be negative",
+			},
+		},
+		"negative fetch overlap": {
+			config: Config{
+				Blackbaud:    &blackbaud.Client{},
+				FetchOverlap: -time.Minute,
+				FundraiseUp:  &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{FundID: "fund-123"},
+				StateStore:   &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"fetch overlap must not be negative"},
+		},
+		"negative max donation size": {
+			config: Config{
+				Blackbaud:       &blackbaud.Client{},
+				FundraiseUp:     &fundraiseup.Client{},
+				GiftDefaults:    config.GiftDefaults{FundID: "fund-123"},
+				MaxDonationSize: -1,
+				StateStore:      &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"max donation size must not be negative"},
+		},
+		"gift splits leaving nothing for the default fund": {
+			config: Config{
+				Blackbaud:   &blackbaud.Client{},
+				FundraiseUp: &fundraiseup.Client{},
+				GiftDefaults: config.GiftDefaults{
+					FundID: "fund-123",
+					Splits: []config.GiftSplit{{FundID: "admin", Percent: 50}, {FundID: "reserves", Percent: 50}},
+				},
+				StateStore: &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"gift split percentages must total less than 100"},
+		},
+		"chargebacks without tracker or note support": {
+			config: Config{
+				Blackbaud:          &mockBlackbaudClient{},
+				ChargebackNoteType: "Finance",
+				ChargebackStatus:   "Held",
+				FundraiseUp:        &fundraiseup.Client{},
+				GiftDefaults:       config.GiftDefaults{FundID: "fund-123"},
+				StateStore:         &mockStateStore{},
+			},
+			wantErr: true,
+			errFragments: []string{
+				"chargebacks require a donation tracker",
+				"chargeback notes require a blackbaud client that can add notes",
+			},
+		},
+		"chargeback notes without status": {
+			config: Config{
+				Blackbaud:          &noteBlackbaudClient{},
+				ChargebackNoteType: "Finance",
+				FundraiseUp:        &fundraiseup.Client{},
+				GiftDefaults:       config.GiftDefaults{FundID: "fund-123"},
+				StateStore:         &mockStateStore{},
+			},
+			wantErr:      true,
+			errFragments: []string{"chargeback notes require a chargeback status"},
+		},
+		"update comments without tracker": {
+			config: Config{
+				Blackbaud:      &blackbaud.Client{},
+				FundraiseUp:    &fundraiseup.Client{},
+				GiftDefaults:   config.GiftDefaults{FundID: "fund-123"},
+				StateStore:     &mockStateStore{},
+				UpdateComments: true,
+			},
+			wantErr:      true,
+			errFragments: []string{"update comments requires a donation tracker"},
+		},
 		"all fields missing": {
 			config:  Config{},
 			wantErr: true,
@@ -243,10 +840,11 @@ func TestGetRecurringContext(t *testing.T) {
 	t.Parallel()
 
 	tests := map[string]struct {
-		bbClient *mockBlackbaudClient
-		donation fundraiseup.Donation
-		want     recurringContext
-		wantErr  bool
+		bbClient      *mockBlackbaudClient
+		donation      fundraiseup.Donation
+		recurringMode string
+		want          recurringContext
+		wantErr       bool
 	}{
 		"non-recurring donation returns empty context": {
 			bbClient: &mockBlackbaudClient{},
@@ -294,8 +892,29 @@ func TestGetRecurringContext(t *testing.T) {
 			},
 			wantErr: false,
 		},
-		"subsequent recurring donation without prior gifts treated as first": {
-			bbClient: &mockBlackbaudClient{},
+		"flat recurring mode treats recurring donation as one-off without looking up the first gift": {
+			bbClient: &mockBlackbaudClient{
+				gifts: map[string][]blackbaud.Gift{
+					"constituent-123": {
+						{
+							ID:       "gift_001",
+							LookupID: "rec_456",
+							Type:     blackbaud.GiftTypeRecurringGift,
+						},
+					},
+				},
+			},
+			donation: fundraiseup.Donation{
+				ID:            "don_002",
+				Installment:   "2",
+				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
+			},
+			recurringMode: config.GiftRecurringModeFlat,
+			want:          recurringContext{},
+			wantErr:       false,
+		},
+		"subsequent recurring donation without prior gifts treated as first": {
+			bbClient: &mockBlackbaudClient{},
 			donation: fundraiseup.Donation{
 				ID:            "don_003",
 				Installment:   "3",
@@ -314,8 +933,9 @@ func TestGetRecurringContext(t *testing.T) {
 			t.Parallel()
 
 			svc := &Service{
-				blackbaud: tc.bbClient,
-				giftCache: make(map[string][]blackbaud.Gift),
+				blackbaud:    tc.bbClient,
+				giftCache:    lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: config.GiftDefaults{RecurringMode: tc.recurringMode},
 			}
 
 			got, err := svc.getRecurringContext(context.Background(), "constituent-123", tc.donation)
@@ -338,11 +958,13 @@ func TestMapDonationToGift(t *testing.T) {
 	tests := map[string]struct {
 		donation        fundraiseup.Donation
 		recCtx          recurringContext
+		recurringMode   string
+		referenceField  string
 		wantBatchPrefix string
 		wantIsManual    bool
 		wantLinkedGifts []string
 		wantLookupID    string
-		wantOrigin      string
+		wantOrigin      blackbaud.GiftOrigin
 		wantSubtype     blackbaud.GiftSubtype
 		wantType        blackbaud.GiftType
 	}{
@@ -358,7 +980,7 @@ func TestMapDonationToGift(t *testing.T) {
 			wantIsManual:    true,
 			wantLinkedGifts: nil,
 			wantLookupID:    "don_123",
-			wantOrigin:      "",
+			wantOrigin:      blackbaud.GiftOrigin{},
 			wantSubtype:     "",
 			wantType:        blackbaud.GiftTypeDonation,
 		},
@@ -377,15 +999,24 @@ func TestMapDonationToGift(t *testing.T) {
 			wantIsManual:    true,
 			wantLinkedGifts: nil,
 			wantLookupID:    "rec_456",
-			wantOrigin:      `{"donation_id":"don_123","name":"FundraiseUp"}`,
-			wantSubtype:     blackbaud.GiftSubtypeRecurring,
-			wantType:        blackbaud.GiftTypeRecurringGift,
+			wantOrigin: blackbaud.GiftOrigin{
+				DonationID:  "don_123",
+				Installment: 1,
+				Name:        "FundraiseUp",
+				RecurringID: "rec_456",
+				Source:      "giftbridge",
+				Version:     version.Dev,
+			},
+			wantSubtype: blackbaud.GiftSubtypeRecurring,
+			wantType:    blackbaud.GiftTypeRecurringGift,
 		},
 		"subsequent recurring donation uses RecurringGiftPayment type with LinkedGifts": {
 			donation: fundraiseup.Donation{
 				ID:            "don_124",
 				Amount:        "50.00",
+				Campaign:      &fundraiseup.Campaign{ID: "FUNSPRING"},
 				CreatedAt:     testTime,
+				Installment:   "2",
 				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
 			},
 			recCtx: recurringContext{
@@ -397,9 +1028,146 @@ func TestMapDonationToGift(t *testing.T) {
 			wantIsManual:    true,
 			wantLinkedGifts: []string{"gift_001"},
 			wantLookupID:    "rec_456",
-			wantOrigin:      `{"donation_id":"don_124","name":"FundraiseUp"}`,
-			wantSubtype:     blackbaud.GiftSubtypeRecurring,
-			wantType:        blackbaud.GiftTypeRecurringGiftPayment,
+			wantOrigin: blackbaud.GiftOrigin{
+				CampaignID:  "FUNSPRING",
+				DonationID:  "don_124",
+				Installment: 2,
+				Name:        "FundraiseUp",
+				RecurringID: "rec_456",
+				Source:      "giftbridge",
+				Version:     version.Dev,
+			},
+			wantSubtype: blackbaud.GiftSubtypeRecurring,
+			wantType:    blackbaud.GiftTypeRecurringGiftPayment,
+		},
+		"flat recurring mode records recurring donation as a plain gift": {
+			donation: fundraiseup.Donation{
+				ID:            "don_124",
+				Amount:        "50.00",
+				CreatedAt:     testTime,
+				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
+			},
+			recCtx:          recurringContext{},
+			recurringMode:   config.GiftRecurringModeFlat,
+			wantBatchPrefix: "FundraiseUp",
+			wantIsManual:    true,
+			wantLookupID:    "don_124",
+			wantType:        blackbaud.GiftTypeDonation,
+		},
+		"flat recurring mode leaves recurring ID out of origin": {
+			donation: fundraiseup.Donation{
+				ID:            "don_124",
+				Amount:        "50.00",
+				CreatedAt:     testTime,
+				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
+			},
+			recCtx:          recurringContext{},
+			recurringMode:   config.GiftRecurringModeFlat,
+			referenceField:  config.GiftReferenceFieldOrigin,
+			wantBatchPrefix: "FundraiseUp",
+			wantIsManual:    true,
+			wantLookupID:    "",
+			wantOrigin: blackbaud.GiftOrigin{
+				DonationID: "don_124",
+				Name:       "FundraiseUp",
+				Source:     "giftbridge",
+				Version:    version.Dev,
+			},
+			wantType: blackbaud.GiftTypeDonation,
+		},
+		"origin reference field leaves LookupID empty for one-off donation": {
+			donation: fundraiseup.Donation{
+				ID:        "don_123",
+				Amount:    "50.00",
+				CreatedAt: testTime,
+			},
+			recCtx:          recurringContext{},
+			referenceField:  config.GiftReferenceFieldOrigin,
+			wantBatchPrefix: "FundraiseUp",
+			wantIsManual:    true,
+			wantLookupID:    "",
+			wantOrigin: blackbaud.GiftOrigin{
+				DonationID: "don_123",
+				Name:       "FundraiseUp",
+				Source:     "giftbridge",
+				Version:    version.Dev,
+			},
+			wantType: blackbaud.GiftTypeDonation,
+		},
+		"origin reference field records recurring ID in origin": {
+			donation: fundraiseup.Donation{
+				ID:            "don_124",
+				Amount:        "50.00",
+				CreatedAt:     testTime,
+				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
+			},
+			recCtx: recurringContext{
+				firstGiftID:     "gift_001",
+				isFirstInSeries: false,
+				sequenceNumber:  2,
+			},
+			referenceField:  config.GiftReferenceFieldOrigin,
+			wantBatchPrefix: "FundraiseUp",
+			wantIsManual:    true,
+			wantLinkedGifts: []string{"gift_001"},
+			wantLookupID:    "",
+			wantOrigin: blackbaud.GiftOrigin{
+				DonationID:  "don_124",
+				Installment: 1,
+				Name:        "FundraiseUp",
+				RecurringID: "rec_456",
+				Source:      "giftbridge",
+				Version:     version.Dev,
+			},
+			wantSubtype: blackbaud.GiftSubtypeRecurring,
+			wantType:    blackbaud.GiftTypeRecurringGiftPayment,
+		},
+		"one-off donation from a further account records the account in origin": {
+			donation: fundraiseup.Donation{
+				Account:   "trading",
+				ID:        "don_125",
+				Amount:    "50.00",
+				CreatedAt: testTime,
+			},
+			recCtx:          recurringContext{},
+			wantBatchPrefix: "FundraiseUp",
+			wantIsManual:    true,
+			wantLookupID:    "don_125",
+			wantOrigin: blackbaud.GiftOrigin{
+				Account:    "trading",
+				DonationID: "don_125",
+				Name:       "FundraiseUp",
+				Source:     "giftbridge",
+				Version:    version.Dev,
+			},
+			wantType: blackbaud.GiftTypeDonation,
+		},
+		"recurring donation from a further account records the account in origin": {
+			donation: fundraiseup.Donation{
+				Account:       "trading",
+				ID:            "don_126",
+				Amount:        "50.00",
+				CreatedAt:     testTime,
+				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_789"},
+			},
+			recCtx: recurringContext{
+				isFirstInSeries: true,
+				sequenceNumber:  1,
+			},
+			wantBatchPrefix: "FundraiseUp",
+			wantIsManual:    true,
+			wantLookupID:    "rec_789",
+			wantOrigin: blackbaud.GiftOrigin{
+				Account:     "trading",
+				DonationID:  "don_126",
+				Installment: 1,
+				Name:        "FundraiseUp",
+				RecurringID: "rec_789",
+				Source:      "giftbridge",
+				Version:     version.Dev,
+			},
+			wantSubtype: blackbaud.GiftSubtypeRecurring,
+			wantType:    blackbaud.GiftTypeRecurringGift,
 		},
 	}
 
@@ -409,8 +1177,10 @@ func TestMapDonationToGift(t *testing.T) {
 
 			svc := &Service{
 				giftDefaults: config.GiftDefaults{
-					FundID: "fund-123",
-					Type:   "Donation",
+					FundID:         "fund-123",
+					RecurringMode:  tc.recurringMode,
+					ReferenceField: tc.referenceField,
+					Type:           "Donation",
 				},
 			}
 
@@ -421,7 +1191,9 @@ func TestMapDonationToGift(t *testing.T) {
 			require.Equal(t, tc.wantIsManual, got.IsManual)
 			require.Equal(t, tc.wantLinkedGifts, got.LinkedGifts)
 			require.Equal(t, tc.wantLookupID, got.LookupID)
-			require.Equal(t, tc.wantOrigin, got.Origin)
+			origin, err := blackbaud.ParseGiftOrigin(got.Origin)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantOrigin, origin)
 			require.Equal(t, tc.wantSubtype, got.Subtype)
 			require.Equal(t, tc.wantType, got.Type)
 			require.Len(t, got.GiftSplits, 1)
@@ -430,14 +1202,420 @@ func TestMapDonationToGift(t *testing.T) {
 	}
 }
 
+func TestMapDonationToGiftSplits(t *testing.T) {
+	t.Parallel()
+
+	// split is a gift split's fund and amount.
+	type split struct {
+		fund   string
+		amount float64
+	}
+
+	tests := map[string]struct {
+		amount     string
+		currency   string
+		rules      []config.GiftRule
+		splits     []config.GiftSplit
+		wantAmount float64
+		wantSplits []split
+	}{
+		"no splits": {
+			amount:     "50.00",
+			currency:   "GBP",
+			wantSplits: []split{{"fund-123", 50}},
+		},
+		"percentage split": {
+			amount:     "50.00",
+			currency:   "GBP",
+			splits:     []config.GiftSplit{{FundID: "admin", Percent: 10}},
+			wantSplits: []split{{"fund-123", 45}, {"admin", 5}},
+		},
+		"rounding goes to default fund": {
+			amount:   "10.00",
+			currency: "GBP",
+			splits: []config.GiftSplit{
+				{FundID: "admin", Percent: 33.3333},
+				{FundID: "reserves", Percent: 33.3333},
+			},
+			wantSplits: []split{{"fund-123", 3.34}, {"admin", 3.33}, {"reserves", 3.33}},
+		},
+		"zero decimal currency": {
+			amount:     "1005",
+			currency:   "jpy",
+			splits:     []config.GiftSplit{{FundID: "admin", Percent: 10}},
+			wantSplits: []split{{"fund-123", 905}, {"admin", 100}},
+		},
+		"three decimal currency": {
+			amount:     "1.005",
+			currency:   "KWD",
+			splits:     []config.GiftSplit{{FundID: "admin", Percent: 10}},
+			wantSplits: []split{{"fund-123", 0.905}, {"admin", 0.1}},
+		},
+		"split rounding to nothing is left out": {
+			amount:     "0.05",
+			currency:   "GBP",
+			splits:     []config.GiftSplit{{FundID: "admin", Percent: 10}},
+			wantSplits: []split{{"fund-123", 0.05}},
+		},
+		"fixed amount then remainder": {
+			amount:     "80.00",
+			currency:   "GBP",
+			splits:     []config.GiftSplit{{Amount: 50, FundID: "fund-a"}},
+			wantSplits: []split{{"fund-123", 30}, {"fund-a", 50}},
+		},
+		"fixed amount taking the whole gift leaves no remainder": {
+			amount:     "50.00",
+			currency:   "GBP",
+			splits:     []config.GiftSplit{{Amount: 50, FundID: "fund-a"}},
+			wantSplits: []split{{"fund-a", 50}},
+		},
+		"fixed amount larger than the gift is capped": {
+			amount:     "30.00",
+			currency:   "GBP",
+			splits:     []config.GiftSplit{{Amount: 50, FundID: "fund-a"}, {Amount: 10, FundID: "fund-b"}},
+			wantSplits: []split{{"fund-a", 30}},
+		},
+		"fixed amount then percentage": {
+			amount:   "100.00",
+			currency: "GBP",
+			splits: []config.GiftSplit{
+				{Amount: 50, FundID: "fund-a"},
+				{FundID: "fund-b", Percent: 10},
+			},
+			wantSplits: []split{{"fund-123", 40}, {"fund-a", 50}, {"fund-b", 10}},
+		},
+		"percentage capped by what fixed amounts leave": {
+			amount:   "55.00",
+			currency: "GBP",
+			splits: []config.GiftSplit{
+				{Amount: 50, FundID: "fund-a"},
+				{FundID: "fund-b", Percent: 20},
+			},
+			wantSplits: []split{{"fund-a", 50}, {"fund-b", 5}},
+		},
+		"fixed amount rounded to the minor unit": {
+			amount:     "1.00",
+			currency:   "GBP",
+			splits:     []config.GiftSplit{{Amount: 0.333, FundID: "fund-a"}},
+			wantSplits: []split{{"fund-123", 0.67}, {"fund-a", 0.33}},
+		},
+		"fixed amount in a zero decimal currency": {
+			amount:     "5000",
+			currency:   "JPY",
+			splits:     []config.GiftSplit{{Amount: 1000.4, FundID: "fund-a"}},
+			wantSplits: []split{{"fund-123", 4000}, {"fund-a", 1000}},
+		},
+		"gift amount finer than the minor unit": {
+			amount:     "10.006",
+			currency:   "GBP",
+			splits:     []config.GiftSplit{{FundID: "fund-a", Percent: 50}},
+			wantAmount: 10.01,
+			wantSplits: []split{{"fund-123", 5.01}, {"fund-a", 5}},
+		},
+		"rule chooses the remainder fund": {
+			amount:     "80.00",
+			currency:   "GBP",
+			rules:      []config.GiftRule{{Field: "fund_id", Value: "'fund-major'"}},
+			splits:     []config.GiftSplit{{Amount: 50, FundID: "fund-a"}},
+			wantSplits: []split{{"fund-major", 30}, {"fund-a", 50}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := compileGiftRules(tc.rules)
+			require.NoError(t, err)
+			svc := &Service{
+				giftDefaults: config.GiftDefaults{
+					CampaignID: "campaign-1",
+					FundID:     "fund-123",
+					Splits:     tc.splits,
+					Type:       "Donation",
+				},
+				giftRules: rules,
+			}
+			donation := fundraiseup.Donation{Amount: tc.amount, Currency: tc.currency, ID: "don_123"}
+
+			got, err := svc.mapDonationToGift(donation, recurringContext{})
+
+			require.NoError(t, err)
+			if tc.wantAmount != 0 {
+				require.InDelta(t, tc.wantAmount, got.Amount.Value, 1e-9)
+			}
+			require.Len(t, got.GiftSplits, len(tc.wantSplits))
+			total := 0.0
+			for i, want := range tc.wantSplits {
+				require.Equal(t, want.fund, got.GiftSplits[i].FundID)
+				require.InDelta(t, want.amount, got.GiftSplits[i].Amount.Value, 1e-9)
+				require.Equal(t, "campaign-1", got.GiftSplits[i].CampaignID)
+				total += got.GiftSplits[i].Amount.Value
+			}
+			require.InDelta(t, got.Amount.Value, total, 1e-9)
+
+			// Split amounts are copies, so changing one leaves the gift amount alone.
+			got.GiftSplits[0].Amount.Value = 0
+			require.NotZero(t, got.Amount.Value)
+		})
+	}
+}
+
+func TestMapDonationToGiftPosting(t *testing.T) {
+	t.Parallel()
+
+	donation := fundraiseup.Donation{
+		ID:        "don_123",
+		Amount:    "50.00",
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	tests := map[string]struct {
+		postDate       string
+		postStatus     string
+		wantPostDate   string
+		wantPostStatus blackbaud.GiftPostStatus
+		wantSyncDate   bool
+	}{
+		"unset leaves the Raiser's Edge default": {
+			wantPostDate:   "",
+			wantPostStatus: "",
+		},
+		"not posted defaults to the donation date": {
+			postStatus:     config.GiftPostStatusNotPosted,
+			wantPostDate:   "2024-01-15",
+			wantPostStatus: blackbaud.GiftPostStatusNotPosted,
+		},
+		"not posted on the donation date": {
+			postDate:       config.GiftPostDateDonation,
+			postStatus:     config.GiftPostStatusNotPosted,
+			wantPostDate:   "2024-01-15",
+			wantPostStatus: blackbaud.GiftPostStatusNotPosted,
+		},
+		"not posted on the sync date": {
+			postDate:       config.GiftPostDateSync,
+			postStatus:     config.GiftPostStatusNotPosted,
+			wantPostStatus: blackbaud.GiftPostStatusNotPosted,
+			wantSyncDate:   true,
+		},
+		"do not post has no post date": {
+			postStatus:     config.GiftPostStatusDoNotPost,
+			wantPostDate:   "",
+			wantPostStatus: blackbaud.GiftPostStatusDoNotPost,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{
+				giftDefaults: config.GiftDefaults{
+					FundID:     "fund-123",
+					PostDate:   tc.postDate,
+					PostStatus: tc.postStatus,
+					Type:       "Donation",
+				},
+			}
+
+			before := time.Now().UTC().Format("2006-01-02")
+			got, err := svc.mapDonationToGift(donation, recurringContext{})
+			after := time.Now().UTC().Format("2006-01-02")
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPostStatus, got.PostStatus)
+			if tc.wantSyncDate {
+				require.Contains(t, []string{before, after}, got.PostDate)
+			} else {
+				require.Equal(t, tc.wantPostDate, got.PostDate)
+			}
+		})
+	}
+}
+
+func TestMapDonationToGiftRules(t *testing.T) {
+	t.Parallel()
+
+	donation := fundraiseup.Donation{
+		Amount:    "1500.00",
+		Campaign:  &fundraiseup.Campaign{ID: "camp_1", Name: "Winter Appeal"},
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Currency:  "GBP",
+		ID:        "don_123",
+		Supporter: &fundraiseup.Supporter{Email: "jane@example.com", LastName: "Smith"},
+	}
+
+	tests := map[string]struct {
+		rules         []config.GiftRule
+		wantAppealID  string
+		wantErr       string
+		wantFundID    string
+		wantReference string
+	}{
+		"no rules keeps defaults": {
+			wantAppealID: "appeal-1",
+			wantFundID:   "fund-1",
+		},
+		"builds reference": {
+			rules: []config.GiftRule{{
+				Field: "reference",
+				Value: "donation.campaign_name + ' ' + donation.date + ' ' + upper(supporter.last_name)",
+			}},
+			wantAppealID:  "appeal-1",
+			wantFundID:    "fund-1",
+			wantReference: "Winter Appeal 2024-01-15 SMITH",
+		},
+		"overrides fund by amount": {
+			rules: []config.GiftRule{
+				{Field: "fund_id", Value: "'fund-major'", When: "donation.amount >= 1000"},
+				{Field: "fund_id", Value: "'fund-small'", When: "donation.amount < 10"},
+			},
+			wantAppealID: "appeal-1",
+			wantFundID:   "fund-major",
+		},
+		"later rules see earlier results": {
+			rules: []config.GiftRule{
+				{Field: "fund_id", Value: "'fund-major'", When: "donation.amount >= 1000"},
+				{Field: "appeal_id", Value: "gift.fund_id == 'fund-major' ? 'appeal-major' : gift.appeal_id"},
+			},
+			wantAppealID: "appeal-major",
+			wantFundID:   "fund-major",
+		},
+		"evaluation error fails mapping": {
+			rules:   []config.GiftRule{{Field: "reference", Value: "string(10 / donation.installment)"}},
+			wantErr: "gift rule 1 (reference): value: division by zero",
+		},
+		"empty fund fails mapping": {
+			rules:   []config.GiftRule{{Field: "fund_id", Value: "donation.designation_id"}},
+			wantErr: "gift rule 1: fund_id must not be empty",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := compileGiftRules(tc.rules)
+			require.NoError(t, err)
+			svc := &Service{
+				giftDefaults: config.GiftDefaults{AppealID: "appeal-1", FundID: "fund-1", Type: "Donation"},
+				giftRules:    rules,
+			}
+
+			got, err := svc.mapDonationToGift(donation, recurringContext{})
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got.GiftSplits, 1)
+			require.Equal(t, tc.wantAppealID, got.GiftSplits[0].AppealID)
+			require.Equal(t, tc.wantFundID, got.GiftSplits[0].FundID)
+			require.Equal(t, tc.wantReference, got.Reference)
+		})
+	}
+}
+
+func TestMapDonationToGiftCountryRoutes(t *testing.T) {
+	t.Parallel()
+
+	routes := []config.CountryRoute{
+		{Countries: []string{"GB", "Isle of Man"}, FundID: "fund-gift-aid"},
+		{AppealID: "appeal-us", Countries: []string{"United States"}, FundID: "fund-501c3"},
+		{Countries: []string{"UK"}, FundID: "fund-unused"},
+	}
+
+	tests := map[string]struct {
+		country      string
+		rules        []config.GiftRule
+		splits       []config.GiftSplit
+		wantAppealID string
+		wantFundIDs  []string
+	}{
+		"routes by country code": {
+			country:      "GB",
+			wantAppealID: "appeal-1",
+			wantFundIDs:  []string{"fund-gift-aid"},
+		},
+		"routes by country name using the first matching route": {
+			country:      "united kingdom",
+			wantAppealID: "appeal-1",
+			wantFundIDs:  []string{"fund-gift-aid"},
+		},
+		"sets the appeal the route gives": {
+			country:      "US",
+			wantAppealID: "appeal-us",
+			wantFundIDs:  []string{"fund-501c3"},
+		},
+		"keeps defaults for other countries": {
+			country:      "FR",
+			wantAppealID: "appeal-1",
+			wantFundIDs:  []string{"fund-1"},
+		},
+		"keeps defaults without an address": {
+			wantAppealID: "appeal-1",
+			wantFundIDs:  []string{"fund-1"},
+		},
+		"rules override the route": {
+			country:      "GB",
+			rules:        []config.GiftRule{{Field: "fund_id", Value: "'fund-major'", When: "donation.amount >= 1000"}},
+			wantAppealID: "appeal-1",
+			wantFundIDs:  []string{"fund-major"},
+		},
+		"routed fund receives the split remainder": {
+			country:      "GB",
+			splits:       []config.GiftSplit{{FundID: "fund-admin", Percent: 10}},
+			wantAppealID: "appeal-1",
+			wantFundIDs:  []string{"fund-gift-aid", "fund-admin"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			countryRoutes, err := compileCountryRoutes(routes)
+			require.NoError(t, err)
+			rules, err := compileGiftRules(tc.rules)
+			require.NoError(t, err)
+			svc := &Service{
+				countryRoutes: countryRoutes,
+				giftDefaults: config.GiftDefaults{
+					AppealID: "appeal-1",
+					FundID:   "fund-1",
+					Splits:   tc.splits,
+					Type:     "Donation",
+				},
+				giftRules: rules,
+			}
+			donation := testDonation("don_123")
+			donation.Amount = "1500.00"
+			if tc.country != "" {
+				donation.Supporter.Address = &fundraiseup.Address{Country: tc.country}
+			}
+
+			got, err := svc.mapDonationToGift(donation, recurringContext{})
+			require.NoError(t, err)
+
+			var fundIDs []string
+			for _, split := range got.GiftSplits {
+				fundIDs = append(fundIDs, split.FundID)
+				require.Equal(t, tc.wantAppealID, split.AppealID)
+			}
+			require.Equal(t, tc.wantFundIDs, fundIDs)
+		})
+	}
+}
+
 func TestFindExistingGift(t *testing.T) {
 	t.Parallel()
 
 	tests := map[string]struct {
-		bbClient   *mockBlackbaudClient
-		donation   fundraiseup.Donation
-		wantGiftID string
-		wantFound  bool
+		bbClient    *mockBlackbaudClient
+		donation    fundraiseup.Donation
+		wantFoundBy string
+		wantGiftID  string
+		wantFound   bool
 	}{
 		"one-time donation found by lookup_id": {
 			bbClient: &mockBlackbaudClient{
@@ -450,8 +1628,9 @@ func TestFindExistingGift(t *testing.T) {
 			donation: fundraiseup.Donation{
 				ID: "don_123",
 			},
-			wantGiftID: "gift_001",
-			wantFound:  true,
+			wantFoundBy: DuplicateLookupID,
+			wantGiftID:  "gift_001",
+			wantFound:   true,
 		},
 		"one-time donation not found": {
 			bbClient: &mockBlackbaudClient{},
@@ -478,17 +1657,18 @@ func TestFindExistingGift(t *testing.T) {
 				ID:            "don_123",
 				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
 			},
-			wantGiftID: "gift_001",
-			wantFound:  true,
+			wantFoundBy: DuplicateOrigin,
+			wantGiftID:  "gift_001",
+			wantFound:   true,
 		},
-		"recurring donation not found when origin doesn't match": {
+		"recurring donation found by lookup_id and unnamed origin": {
 			bbClient: &mockBlackbaudClient{
 				gifts: map[string][]blackbaud.Gift{
 					"constituent-123": {
 						{
 							ID:       "gift_001",
 							LookupID: "rec_456",
-							Origin:   `{"donation_id":"don_different","name":"FundraiseUp"}`,
+							Origin:   `{"donation_id":"don_123"}`,
 							Type:     blackbaud.GiftTypeRecurringGift,
 						},
 					},
@@ -498,23 +1678,90 @@ func TestFindExistingGift(t *testing.T) {
 				ID:            "don_123",
 				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
 			},
-			wantGiftID: "",
-			wantFound:  false,
+			wantFoundBy: DuplicateLookupID,
+			wantGiftID:  "gift_001",
+			wantFound:   true,
 		},
-	}
-
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-
-			svc := &Service{
-				blackbaud: tc.bbClient,
-				giftCache: make(map[string][]blackbaud.Gift),
-			}
-
-			got, err := svc.findExistingGift(context.Background(), "constituent-123", tc.donation)
-
+		"recurring donation not found when origin doesn't match": {
+			bbClient: &mockBlackbaudClient{
+				gifts: map[string][]blackbaud.Gift{
+					"constituent-123": {
+						{
+							ID:       "gift_001",
+							LookupID: "rec_456",
+							Origin:   `{"donation_id":"don_different","name":"FundraiseUp"}`,
+							Type:     blackbaud.GiftTypeRecurringGift,
+						},
+					},
+				},
+			},
+			donation: fundraiseup.Donation{
+				ID:            "don_123",
+				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
+			},
+			wantGiftID: "",
+			wantFound:  false,
+		},
+		"one-time donation found by origin donation_id": {
+			bbClient: &mockBlackbaudClient{
+				gifts: map[string][]blackbaud.Gift{
+					"constituent-123": {
+						{
+							ID:       "gift_001",
+							LookupID: "LEGACY-41",
+							Origin:   `{"donation_id":"don_999","name":"FundraiseUp"}`,
+						},
+						{
+							ID:       "gift_002",
+							LookupID: "LEGACY-42",
+							Origin:   `{"donation_id":"don_123","name":"FundraiseUp"}`,
+						},
+					},
+				},
+			},
+			donation: fundraiseup.Donation{
+				ID: "don_123",
+			},
+			wantFoundBy: DuplicateOrigin,
+			wantGiftID:  "gift_002",
+			wantFound:   true,
+		},
+		"recurring donation found by origin without lookup_id": {
+			bbClient: &mockBlackbaudClient{
+				gifts: map[string][]blackbaud.Gift{
+					"constituent-123": {
+						{
+							ID:       "gift_001",
+							LookupID: "LEGACY-42",
+							Origin:   `{"donation_id":"don_123","name":"FundraiseUp","recurring_id":"rec_456"}`,
+							Type:     blackbaud.GiftTypeRecurringGift,
+						},
+					},
+				},
+			},
+			donation: fundraiseup.Donation{
+				ID:            "don_123",
+				RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_456"},
+			},
+			wantFoundBy: DuplicateOrigin,
+			wantGiftID:  "gift_001",
+			wantFound:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{
+				blackbaud: tc.bbClient,
+				giftCache: lru.New[string, []blackbaud.Gift](0),
+			}
+
+			got, foundBy, err := svc.findExistingGift(context.Background(), "constituent-123", tc.donation)
+
 			require.NoError(t, err)
+			require.Equal(t, tc.wantFoundBy, foundBy)
 			if tc.wantFound {
 				require.NotNil(t, got)
 				require.Equal(t, tc.wantGiftID, got.ID)
@@ -571,6 +1818,23 @@ func TestFindFirstRecurringGift(t *testing.T) {
 			wantGiftID:  "",
 			wantFound:   false,
 		},
+		"first gift found by origin recurring_id": {
+			bbClient: &mockBlackbaudClient{
+				gifts: map[string][]blackbaud.Gift{
+					"constituent-123": {
+						{
+							ID:       "gift_001",
+							LookupID: "LEGACY-42",
+							Origin:   `{"donation_id":"don_123","name":"FundraiseUp","recurring_id":"rec_456"}`,
+							Type:     blackbaud.GiftTypeRecurringGift,
+						},
+					},
+				},
+			},
+			recurringID: "rec_456",
+			wantGiftID:  "gift_001",
+			wantFound:   true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -579,7 +1843,7 @@ func TestFindFirstRecurringGift(t *testing.T) {
 
 			svc := &Service{
 				blackbaud: tc.bbClient,
-				giftCache: make(map[string][]blackbaud.Gift),
+				giftCache: lru.New[string, []blackbaud.Gift](0),
 			}
 
 			got, err := svc.findFirstRecurringGift(context.Background(), "constituent-123", tc.recurringID)
@@ -613,7 +1877,7 @@ func TestGetConstituentGifts(t *testing.T) {
 
 		svc := &Service{
 			blackbaud: client,
-			giftCache: make(map[string][]blackbaud.Gift),
+			giftCache: lru.New[string, []blackbaud.Gift](0),
 		}
 
 		// First call should hit the client.
@@ -643,7 +1907,7 @@ func TestGetConstituentGifts(t *testing.T) {
 
 		svc := &Service{
 			blackbaud: client,
-			giftCache: make(map[string][]blackbaud.Gift),
+			giftCache: lru.New[string, []blackbaud.Gift](0),
 		}
 
 		giftsA, err := svc.getConstituentGifts(context.Background(), "constituent-A")
@@ -656,6 +1920,32 @@ func TestGetConstituentGifts(t *testing.T) {
 		require.Equal(t, "gift_B", giftsB[0].ID)
 		require.Equal(t, 2, callCount) // Second call for different constituent.
 	})
+
+	t.Run("evicts least recently used constituent once full", func(t *testing.T) {
+		t.Parallel()
+
+		callCount := 0
+		client := &countingBlackbaudClient{
+			gifts: map[string][]blackbaud.Gift{
+				"constituent-A": {{ID: "gift_A"}},
+				"constituent-B": {{ID: "gift_B"}},
+			},
+			callCount: &callCount,
+		}
+
+		svc := &Service{
+			blackbaud: client,
+			giftCache: lru.New[string, []blackbaud.Gift](1),
+		}
+
+		for _, id := range []string{"constituent-A", "constituent-B", "constituent-A"} {
+			_, err := svc.getConstituentGifts(context.Background(), id)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 3, callCount)
+		require.Equal(t, lru.Stats{Evictions: 2, Misses: 3}, svc.giftCache.Stats())
+	})
 }
 
 // countingBlackbaudClient tracks how many times ListGiftsByConstituent is called.
@@ -673,6 +1963,14 @@ func (c *countingBlackbaudClient) CreateConstituent(
 	return "constituent-123", nil
 }
 
+// CreateConstituentCode creates a new constituent code.
+func (c *countingBlackbaudClient) CreateConstituentCode(
+	_ context.Context,
+	_ *blackbaud.ConstituentCode,
+) (string, error) {
+	return "code-123", nil
+}
+
 // CreateGift creates a new gift.
 func (c *countingBlackbaudClient) CreateGift(_ context.Context, _ *blackbaud.Gift) (string, error) {
 	return "gift-123", nil
@@ -695,6 +1993,7 @@ func (c *countingBlackbaudClient) ListGiftsByConstituent(
 func (c *countingBlackbaudClient) SearchConstituents(
 	_ context.Context,
 	_ string,
+	_ blackbaud.SearchOptions,
 ) ([]blackbaud.Constituent, error) {
 	return c.constituents, nil
 }
@@ -778,82 +2077,4430 @@ func TestFindOrCreateConstituent(t *testing.T) {
 	}
 }
 
-func TestProcessDonation(t *testing.T) {
+func TestFindOrCreateConstituentEmailNormalization(t *testing.T) {
 	t.Parallel()
 
-	t.Run("skips existing gift", func(t *testing.T) {
-		t.Parallel()
+	tests := map[string]struct {
+		bbClient     *mockBlackbaudClient
+		cfg          config.EmailNormalization
+		email        string
+		wantSearches []string
+	}{
+		"searches lowercased email": {
+			bbClient:     &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "existing-123"}}},
+			email:        " John.Doe@Example.com ",
+			wantSearches: []string{"john.doe@example.com"},
+		},
+		"searches folded gmail address": {
+			bbClient:     &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "existing-123"}}},
+			cfg:          config.EmailNormalization{FoldGmail: true},
+			email:        "John.Doe+fr@gmail.com",
+			wantSearches: []string{"johndoe@gmail.com"},
+		},
+		"falls back to original address when folded address not found": {
+			bbClient:     &mockBlackbaudClient{},
+			cfg:          config.EmailNormalization{FoldGmail: true},
+			email:        "John.Doe+fr@gmail.com",
+			wantSearches: []string{"johndoe@gmail.com", "John.Doe+fr@gmail.com"},
+		},
+	}
 
-		svc := &Service{
-			blackbaud: &mockBlackbaudClient{
-				constituents: []blackbaud.Constituent{{ID: "const-123"}},
-				gifts: map[string][]blackbaud.Gift{
-					"const-123": {{ID: "existing-gift", LookupID: "don_123"}},
-				},
-			},
-			giftCache:    make(map[string][]blackbaud.Gift),
-			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
-			logger:       slog.Default(),
-		}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-		donation := fundraiseup.Donation{
-			ID:        "don_123",
-			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
-		}
+			svc := &Service{
+				blackbaud:          tc.bbClient,
+				emailNormalization: tc.cfg,
+			}
+			donation := fundraiseup.Donation{
+				ID:        "don_123",
+				Supporter: &fundraiseup.Supporter{Email: tc.email},
+			}
 
-		result := svc.processDonation(context.Background(), donation)
+			_, _, err := svc.findOrCreateConstituent(context.Background(), donation)
 
-		require.NoError(t, result.Error)
-		require.True(t, result.GiftSkippedExisting)
-		require.False(t, result.GiftCreated)
-		require.Equal(t, "existing-gift", result.GiftID)
-	})
+			require.NoError(t, err)
+			require.Equal(t, tc.wantSearches, tc.bbClient.searches)
+		})
+	}
+}
 
-	t.Run("creates new gift when none exists", func(t *testing.T) {
-		t.Parallel()
+func TestFindOrCreateConstituentCachesByNormalizedEmail(t *testing.T) {
+	t.Parallel()
 
-		svc := &Service{
-			blackbaud: &mockBlackbaudClient{
-				constituents: []blackbaud.Constituent{{ID: "const-123"}},
-				gifts:        nil,
-			},
-			giftCache:    make(map[string][]blackbaud.Gift),
-			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
-			logger:       slog.Default(),
-		}
+	bbClient := &mockBlackbaudClient{}
+	svc := &Service{
+		blackbaud:          bbClient,
+		emailNormalization: config.EmailNormalization{FoldGmail: true},
+	}
 
-		donation := fundraiseup.Donation{
-			ID:        "don_456",
-			Amount:    "50.00",
-			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
-		}
+	first := fundraiseup.Donation{ID: "don_1", Supporter: &fundraiseup.Supporter{Email: "johndoe@gmail.com"}}
+	id, created, err := svc.findOrCreateConstituent(context.Background(), first)
+	require.NoError(t, err)
+	require.True(t, created)
 
-		result := svc.processDonation(context.Background(), donation)
+	// A later donation in the same run with an equivalent address reuses the new constituent.
+	second := fundraiseup.Donation{ID: "don_2", Supporter: &fundraiseup.Supporter{Email: "John.Doe+fr@GMAIL.com"}}
+	secondID, created, err := svc.findOrCreateConstituent(context.Background(), second)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, id, secondID)
+	require.Equal(t, []string{"johndoe@gmail.com"}, bbClient.searches)
+	require.Equal(t, []blackbaud.SearchOptions{{}}, bbClient.searchOpts)
+}
 
-		require.NoError(t, result.Error)
-		require.False(t, result.GiftSkippedExisting)
-		require.True(t, result.GiftCreated)
-		require.Equal(t, "gift-123", result.GiftID) // From mock.
-	})
+func TestFindOrCreateConstituentSupporterCache(t *testing.T) {
+	t.Parallel()
 
-	t.Run("returns error when no supporter", func(t *testing.T) {
-		t.Parallel()
+	tests := map[string]struct {
+		cached       map[string]string
+		constituents []blackbaud.Constituent
+		dryRun       bool
+		lookupErr    error
+		ttl          time.Duration
+		wantCached   map[string]string
+		wantCreated  bool
+		wantID       string
+		wantSearches []string
+	}{
+		"uses constituent matched in an earlier run": {
+			cached:     map[string]string{"jane@example.com": "const-9"},
+			ttl:        time.Hour,
+			wantCached: map[string]string{"jane@example.com": "const-9"},
+			wantID:     "const-9",
+		},
+		"remembers constituent found by search": {
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			ttl:          time.Hour,
+			wantCached:   map[string]string{"jane@example.com": "const-123"},
+			wantID:       "const-123",
+			wantSearches: []string{"jane@example.com"},
+		},
+		"remembers new constituent": {
+			ttl:          time.Hour,
+			wantCached:   map[string]string{"jane@example.com": "constituent-123"},
+			wantCreated:  true,
+			wantID:       "constituent-123",
+			wantSearches: []string{"jane@example.com"},
+		},
+		"dry run remembers nothing": {
+			dryRun:       true,
+			ttl:          time.Hour,
+			wantCached:   map[string]string{},
+			wantCreated:  true,
+			wantID:       "constituent-123",
+			wantSearches: []string{"jane@example.com"},
+		},
+		"failed lookup falls back to search": {
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			lookupErr:    errors.New("throttled"),
+			ttl:          time.Hour,
+			wantCached:   map[string]string{"jane@example.com": "const-123"},
+			wantID:       "const-123",
+			wantSearches: []string{"jane@example.com"},
+		},
+		"disabled": {
+			cached:       map[string]string{"jane@example.com": "const-9"},
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			wantCached:   map[string]string{"jane@example.com": "const-9"},
+			wantID:       "const-123",
+			wantSearches: []string{"jane@example.com"},
+		},
+	}
 
-		svc := &Service{
-			blackbaud:    &mockBlackbaudClient{},
-			giftCache:    make(map[string][]blackbaud.Gift),
-			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
-			logger:       slog.Default(),
-		}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-		donation := fundraiseup.Donation{
-			ID:        "don_789",
-			Supporter: nil,
-		}
+			cached := tc.cached
+			if cached == nil {
+				cached = map[string]string{}
+			}
+			tracker := &supporterCacheTracker{cached: cached, lookupErr: tc.lookupErr}
+			bbClient := &mockBlackbaudClient{constituents: tc.constituents}
+			svc := &Service{
+				blackbaud:         bbClient,
+				dryRun:            tc.dryRun,
+				logger:            slog.Default(),
+				supporterCacheTTL: tc.ttl,
+				tracker:           tracker,
+			}
 
-		result := svc.processDonation(context.Background(), donation)
+			donation := fundraiseup.Donation{
+				ID:        "don_1",
+				Supporter: &fundraiseup.Supporter{Email: " Jane@Example.com", ID: "sup_jane"},
+			}
+			id, created, err := svc.findOrCreateConstituent(context.Background(), donation)
 
-		require.Error(t, result.Error)
-		require.Contains(t, result.Error.Error(), "donation has no supporter")
-	})
+			require.NoError(t, err)
+			require.Equal(t, tc.wantID, id)
+			require.Equal(t, tc.wantCreated, created)
+			require.Equal(t, tc.wantSearches, bbClient.searches)
+			require.Equal(t, tc.wantCached, tracker.cached)
+			if len(tc.wantSearches) > 0 && !tc.dryRun && tc.ttl > 0 {
+				require.Equal(t, "sup_jane", tracker.supporterID)
+			}
+		})
+	}
+}
+
+func TestProcessDonationStaleSupporterCache(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		dryRun        bool
+		listGone      bool
+		wantCached    map[string]string
+		wantForgotten []string
+	}{
+		"constituent gone when listing gifts": {
+			listGone:      true,
+			wantCached:    map[string]string{"jane@example.com": "const-123"},
+			wantForgotten: []string{"jane@example.com"},
+		},
+		"constituent gone when creating gift": {
+			wantCached:    map[string]string{"jane@example.com": "const-123"},
+			wantForgotten: []string{"jane@example.com"},
+		},
+		"dry run forgets nothing": {
+			dryRun:     true,
+			listGone:   true,
+			wantCached: map[string]string{"jane@example.com": "const-merged"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := &supporterCacheTracker{
+				cached:      map[string]string{"jane@example.com": "const-merged"},
+				mockTracker: mockTracker{records: make(map[string]storage.DonationRecord)},
+			}
+			bbClient := &goneConstituentClient{
+				gone:                "const-merged",
+				listGone:            tc.listGone,
+				mockBlackbaudClient: mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+			}
+			svc := &Service{
+				blackbaud:         bbClient,
+				dryRun:            tc.dryRun,
+				giftCache:         lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:      config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:            slog.New(slog.DiscardHandler),
+				supporterCacheTTL: time.Hour,
+				tracker:           tracker,
+			}
+			donation := testDonation("don_123")
+			donation.Supporter = &fundraiseup.Supporter{Email: "jane@example.com", ID: "sup_jane"}
+
+			result := svc.processDonation(context.Background(), donation)
+
+			require.NoError(t, result.Error)
+			require.Equal(t, []string{"jane@example.com"}, bbClient.searches)
+			require.Equal(t, tc.wantCached, tracker.cached)
+			require.Equal(t, tc.wantForgotten, tracker.forgotten)
+			if !tc.dryRun {
+				require.Len(t, bbClient.createdGifts, 1)
+				require.Equal(t, "const-123", bbClient.createdGifts[0].ConstituentID)
+			}
+
+			// Later donations by the supporter this run use the constituent found by search.
+			later := testDonation("don_124")
+			later.Supporter = donation.Supporter
+			result = svc.processDonation(context.Background(), later)
+			require.NoError(t, result.Error)
+			require.Len(t, bbClient.searches, 1)
+		})
+	}
+}
+
+func TestFindOrCreateConstituentAddMissingEmail(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		addErr       error
+		addMissing   bool
+		constituents []blackbaud.Constituent
+		wantAdded    []*blackbaud.EmailAddress
+	}{
+		"adds email to constituent without one": {
+			addMissing:   true,
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			wantAdded: []*blackbaud.EmailAddress{
+				{Address: "Jane@Example.com", ConstituentID: "const-123", Primary: true, Type: "Email"},
+			},
+		},
+		"adds email to constituent with a blank email": {
+			addMissing:   true,
+			constituents: []blackbaud.Constituent{{Email: &blackbaud.Email{}, ID: "const-123"}},
+			wantAdded: []*blackbaud.EmailAddress{
+				{Address: "Jane@Example.com", ConstituentID: "const-123", Primary: true, Type: "Email"},
+			},
+		},
+		"leaves constituent with an email alone": {
+			addMissing: true,
+			constituents: []blackbaud.Constituent{
+				{Email: &blackbaud.Email{Address: "jane@example.com"}, ID: "const-123"},
+			},
+		},
+		"failure still matches constituent": {
+			addErr:       errors.New("bad request"),
+			addMissing:   true,
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+		},
+		"disabled": {
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &emailBlackbaudClient{
+				addErr:              tc.addErr,
+				mockBlackbaudClient: mockBlackbaudClient{constituents: tc.constituents},
+			}
+			svc := &Service{
+				blackbaud:          bbClient,
+				emailNormalization: config.EmailNormalization{AddMissing: tc.addMissing},
+				logger:             slog.Default(),
+			}
+
+			donation := fundraiseup.Donation{ID: "don_1", Supporter: &fundraiseup.Supporter{Email: " Jane@Example.com"}}
+			id, created, err := svc.findOrCreateConstituent(context.Background(), donation)
+
+			require.NoError(t, err)
+			require.Equal(t, "const-123", id)
+			require.False(t, created)
+			require.Equal(t, tc.wantAdded, bbClient.added)
+		})
+	}
+}
+
+func TestFindOrCreateConstituentSearchOptions(t *testing.T) {
+	t.Parallel()
+
+	bbClient := &mockBlackbaudClient{}
+	svc := &Service{
+		blackbaud:          bbClient,
+		emailNormalization: config.EmailNormalization{IncludeInactive: true, StrictSearch: true},
+	}
+
+	donation := fundraiseup.Donation{ID: "don_1", Supporter: &fundraiseup.Supporter{Email: "jane@example.com"}}
+	_, _, err := svc.findOrCreateConstituent(context.Background(), donation)
+	require.NoError(t, err)
+	require.Equal(t, []blackbaud.SearchOptions{{IncludeInactive: true, StrictEmail: true}}, bbClient.searchOpts)
+}
+
+func TestFindOrCreateConstituentNameNormalization(t *testing.T) {
+	t.Parallel()
+
+	bbClient := &mockBlackbaudClient{}
+	svc := &Service{
+		blackbaud:         bbClient,
+		nameNormalization: config.NameNormalization{TitleCase: true},
+	}
+	donation := fundraiseup.Donation{
+		ID: "don_123",
+		Supporter: &fundraiseup.Supporter{
+			Email:     "jan@example.com",
+			FirstName: "jan",
+			LastName:  "van der berg",
+		},
+	}
+
+	_, created, err := svc.findOrCreateConstituent(context.Background(), donation)
+
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Len(t, bbClient.created, 1)
+	require.Equal(t, "Jan", bbClient.created[0].FirstName)
+	require.Equal(t, "van der Berg", bbClient.created[0].LastName)
+}
+
+func TestFindOrCreateConstituentNameFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		addressee      string
+		firstName      string
+		salutation     string
+		wantAddressee  *blackbaud.FormattedName
+		wantSalutation *blackbaud.FormattedName
+	}{
+		"formats addressee and salutation": {
+			addressee:      "{{.FirstName}} {{.LastName}}",
+			firstName:      "jan",
+			salutation:     "Dear {{.FirstName}}",
+			wantAddressee:  &blackbaud.FormattedName{CustomFormat: true, FormattedName: "Jan van der Berg"},
+			wantSalutation: &blackbaud.FormattedName{CustomFormat: true, FormattedName: "Dear Jan"},
+		},
+		"falls back without a first name": {
+			addressee:      "{{.FirstName}} {{.LastName}}",
+			salutation:     "Dear {{if .FirstName}}{{.FirstName}}{{else}}Friend{{end}}",
+			wantAddressee:  &blackbaud.FormattedName{CustomFormat: true, FormattedName: "van der Berg"},
+			wantSalutation: &blackbaud.FormattedName{CustomFormat: true, FormattedName: "Dear Friend"},
+		},
+		"keeps default formats when unset": {
+			firstName: "jan",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			addressee, err := normalize.NewNameFormatter(tc.addressee)
+			require.NoError(t, err)
+			salutation, err := normalize.NewNameFormatter(tc.salutation)
+			require.NoError(t, err)
+
+			bbClient := &mockBlackbaudClient{}
+			svc := &Service{
+				addresseeFormatter:  addressee,
+				blackbaud:           bbClient,
+				nameNormalization:   config.NameNormalization{TitleCase: true},
+				salutationFormatter: salutation,
+			}
+			donation := fundraiseup.Donation{
+				ID: "don_123",
+				Supporter: &fundraiseup.Supporter{
+					Email:     "jan@example.com",
+					FirstName: tc.firstName,
+					LastName:  "van der berg",
+				},
+			}
+
+			_, created, err := svc.findOrCreateConstituent(context.Background(), donation)
+
+			require.NoError(t, err)
+			require.True(t, created)
+			require.Len(t, bbClient.created, 1)
+			require.Equal(t, tc.wantAddressee, bbClient.created[0].PrimaryAddressee)
+			require.Equal(t, tc.wantSalutation, bbClient.created[0].PrimarySalutation)
+		})
+	}
+}
+
+func TestProcessDonation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("skips existing gift", func(t *testing.T) {
+		t.Parallel()
+
+		svc := &Service{
+			blackbaud: &mockBlackbaudClient{
+				constituents: []blackbaud.Constituent{{ID: "const-123"}},
+				gifts: map[string][]blackbaud.Gift{
+					"const-123": {{ID: "existing-gift", LookupID: "don_123"}},
+				},
+			},
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:       slog.Default(),
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_123",
+			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.True(t, result.GiftSkippedExisting)
+		require.False(t, result.GiftCreated)
+		require.Equal(t, "existing-gift", result.GiftID)
+	})
+
+	t.Run("creates new gift when none exists", func(t *testing.T) {
+		t.Parallel()
+
+		svc := &Service{
+			blackbaud: &mockBlackbaudClient{
+				constituents: []blackbaud.Constituent{{ID: "const-123"}},
+				gifts:        nil,
+			},
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:       slog.Default(),
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_456",
+			Amount:    "50.00",
+			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.False(t, result.GiftSkippedExisting)
+		require.True(t, result.GiftCreated)
+		require.Equal(t, "gift-123", result.GiftID) // From mock.
+	})
+
+	t.Run("reports address issues for new constituent", func(t *testing.T) {
+		t.Parallel()
+
+		svc := &Service{
+			blackbaud:    &mockBlackbaudClient{},
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:       slog.Default(),
+		}
+
+		donation := fundraiseup.Donation{
+			ID:     "don_457",
+			Amount: "50.00",
+			Supporter: &fundraiseup.Supporter{
+				Address: &fundraiseup.Address{Country: "GB", Line1: "1 High Street", PostalCode: "LS1"},
+				Email:   "new@example.com",
+			},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.True(t, result.ConstituentCreated)
+		require.True(t, result.GiftCreated)
+		require.Equal(t, []string{`post code "LS1" is not valid for GB, stored as supplied`}, result.Warnings)
+	})
+
+	t.Run("adds constituent codes to new constituent", func(t *testing.T) {
+		t.Parallel()
+
+		bbClient := &mockBlackbaudClient{}
+		svc := &Service{
+			blackbaud:           bbClient,
+			constituentDefaults: config.ConstituentDefaults{Codes: []string{"Online Donor", "Newsletter"}},
+			giftCache:           lru.New[string, []blackbaud.Gift](0),
+			giftDefaults:        config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:              slog.Default(),
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_458",
+			Amount:    "50.00",
+			CreatedAt: time.Date(2025, time.March, 7, 10, 0, 0, 0, time.UTC),
+			Supporter: &fundraiseup.Supporter{Email: "new@example.com"},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.Empty(t, result.Warnings)
+		start := &blackbaud.FuzzyDate{Day: 7, Month: 3, Year: 2025}
+		require.Equal(t, []*blackbaud.ConstituentCode{
+			{ConstituentID: "constituent-123", Description: "Online Donor", Start: start},
+			{ConstituentID: "constituent-123", Description: "Newsletter", Start: start},
+		}, bbClient.codes)
+	})
+
+	t.Run("does not add constituent codes to existing constituent", func(t *testing.T) {
+		t.Parallel()
+
+		bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+		svc := &Service{
+			blackbaud:           bbClient,
+			constituentDefaults: config.ConstituentDefaults{Codes: []string{"Online Donor"}},
+			giftCache:           lru.New[string, []blackbaud.Gift](0),
+			giftDefaults:        config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:              slog.Default(),
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_459",
+			Amount:    "50.00",
+			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.Empty(t, bbClient.codes)
+	})
+
+	t.Run("reports constituent code failure as warning", func(t *testing.T) {
+		t.Parallel()
+
+		svc := &Service{
+			blackbaud:           &mockBlackbaudClient{codeErr: errors.New("unknown code")},
+			constituentDefaults: config.ConstituentDefaults{Codes: []string{"Online Donor"}},
+			giftCache:           lru.New[string, []blackbaud.Gift](0),
+			giftDefaults:        config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:              slog.Default(),
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_460",
+			Amount:    "50.00",
+			Supporter: &fundraiseup.Supporter{Email: "new@example.com"},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.True(t, result.GiftCreated)
+		require.Equal(t, []string{`adding constituent code "Online Donor": unknown code`}, result.Warnings)
+	})
+
+	t.Run("returns error when no supporter", func(t *testing.T) {
+		t.Parallel()
+
+		svc := &Service{
+			blackbaud:    &mockBlackbaudClient{},
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:       slog.Default(),
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_789",
+			Supporter: nil,
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.Error(t, result.Error)
+		require.Contains(t, result.Error.Error(), "donation has no supporter")
+	})
+	t.Run("skips tracked donation without querying Blackbaud", func(t *testing.T) {
+		t.Parallel()
+
+		callCount := 0
+		svc := &Service{
+			blackbaud: &countingBlackbaudClient{
+				callCount:    &callCount,
+				constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			},
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:       slog.Default(),
+			tracker: &mockTracker{records: map[string]storage.DonationRecord{
+				"don_123": {DonationID: "don_123", GiftID: "tracked-gift"},
+			}},
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_123",
+			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.True(t, result.GiftSkippedExisting)
+		require.Equal(t, "tracked-gift", result.GiftID)
+		require.Equal(t, 0, callCount)
+	})
+
+	t.Run("tracks created gifts", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := &mockTracker{records: map[string]storage.DonationRecord{}}
+		svc := &Service{
+			blackbaud: &mockBlackbaudClient{
+				constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			},
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:       slog.Default(),
+			tracker:      tracker,
+		}
+
+		oneOff := fundraiseup.Donation{
+			ID:        "don_456",
+			Amount:    "50.00",
+			Currency:  "GBP",
+			Supporter: &fundraiseup.Supporter{Email: "test@example.com", ID: "sup_1"},
+		}
+		recurring := fundraiseup.Donation{
+			ID:            "don_789",
+			Amount:        "10.00",
+			RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_1"},
+			Supporter:     &fundraiseup.Supporter{Email: "test@example.com", ID: "sup_1"},
+		}
+
+		require.NoError(t, svc.processDonation(context.Background(), oneOff).Error)
+		require.NoError(t, svc.processDonation(context.Background(), recurring).Error)
+
+		require.Equal(t, storage.DonationRecord{
+			Amount:        "50.00",
+			ConstituentID: "const-123",
+			Currency:      "GBP",
+			DonationID:    "don_456",
+			GiftID:        "gift-123",
+			GiftType:      "Donation",
+			SupporterID:   "sup_1",
+		}, tracker.records["don_456"])
+		require.Equal(t, "rec_1", tracker.records["don_789"].RecurringID)
+		require.Equal(t, string(blackbaud.GiftTypeRecurringGift), tracker.records["don_789"].GiftType)
+		require.Equal(t, []string{"don_789"}, tracker.recurring)
+	})
+
+	t.Run("does not track in dry-run", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := &mockTracker{records: map[string]storage.DonationRecord{}}
+		svc := &Service{
+			blackbaud: &mockBlackbaudClient{
+				constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			},
+			dryRun:       true,
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:       slog.Default(),
+			tracker:      tracker,
+		}
+
+		donation := fundraiseup.Donation{
+			ID:        "don_456",
+			Amount:    "50.00",
+			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+		}
+
+		result := svc.processDonation(context.Background(), donation)
+
+		require.NoError(t, result.Error)
+		require.True(t, result.GiftCreated)
+		require.Empty(t, tracker.records)
+	})
+}
+
+func TestProcessDonationAppealResponses(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		appealErr    error
+		appeals      map[string][]blackbaud.ConstituentAppeal
+		constituents []blackbaud.Constituent
+		giftDefaults config.GiftDefaults
+		wantCreated  []*blackbaud.ConstituentAppeal
+		wantListed   []string
+		wantWarnings []string
+	}{
+		"records response of existing constituent": {
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			giftDefaults: config.GiftDefaults{AppealID: "APPEAL-1", AppealResponses: true, FundID: "fund-1"},
+			wantCreated: []*blackbaud.ConstituentAppeal{
+				{AppealID: "APPEAL-1", ConstituentID: "const-123", Date: "2024-01-15"},
+			},
+			wantListed: []string{"const-123"},
+		},
+		"skips appeal already recorded": {
+			appeals: map[string][]blackbaud.ConstituentAppeal{
+				"const-123": {{AppealID: "APPEAL-1", ConstituentID: "const-123", ID: "ca-1"}},
+			},
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			giftDefaults: config.GiftDefaults{AppealID: "APPEAL-1", AppealResponses: true, FundID: "fund-1"},
+			wantListed:   []string{"const-123"},
+		},
+		"new constituent has no appeals to list": {
+			giftDefaults: config.GiftDefaults{AppealID: "APPEAL-1", AppealResponses: true, FundID: "fund-1"},
+			wantCreated: []*blackbaud.ConstituentAppeal{
+				{AppealID: "APPEAL-1", ConstituentID: "constituent-123", Date: "2024-01-15"},
+			},
+		},
+		"split gift records its appeal once": {
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			giftDefaults: config.GiftDefaults{
+				AppealID:        "APPEAL-1",
+				AppealResponses: true,
+				FundID:          "fund-1",
+				Splits:          []config.GiftSplit{{FundID: "gala", Percent: 10}},
+			},
+			wantCreated: []*blackbaud.ConstituentAppeal{
+				{AppealID: "APPEAL-1", ConstituentID: "const-123", Date: "2024-01-15"},
+			},
+			wantListed: []string{"const-123"},
+		},
+		"gift without appeal": {
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			giftDefaults: config.GiftDefaults{AppealResponses: true, FundID: "fund-1"},
+		},
+		"disabled": {
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			giftDefaults: config.GiftDefaults{AppealID: "APPEAL-1", FundID: "fund-1"},
+		},
+		"failure reported as warning": {
+			appealErr:    errors.New("appeal not found"),
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			giftDefaults: config.GiftDefaults{AppealID: "APPEAL-1", AppealResponses: true, FundID: "fund-1"},
+			wantListed:   []string{"const-123"},
+			wantWarnings: []string{`recording response to appeal "APPEAL-1": appeal not found`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &appealBlackbaudClient{
+				appealErr:           tc.appealErr,
+				appeals:             tc.appeals,
+				mockBlackbaudClient: mockBlackbaudClient{constituents: tc.constituents},
+			}
+			svc := &Service{
+				blackbaud:    bbClient,
+				giftCache:    lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: tc.giftDefaults,
+				logger:       slog.Default(),
+			}
+
+			result := svc.processDonation(context.Background(), fundraiseup.Donation{
+				Amount:    "50.00",
+				CreatedAt: time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC),
+				Currency:  "GBP",
+				ID:        "don_123",
+				Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+			})
+
+			require.NoError(t, result.Error)
+			require.True(t, result.GiftCreated)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+			require.Equal(t, tc.wantCreated, bbClient.createdAppeals)
+			require.Equal(t, tc.wantListed, bbClient.listedAppeals)
+		})
+	}
+
+	t.Run("lists each constituent's appeals once a run", func(t *testing.T) {
+		t.Parallel()
+
+		bbClient := &appealBlackbaudClient{
+			mockBlackbaudClient: mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		}
+		svc := &Service{
+			blackbaud:    bbClient,
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{AppealID: "APPEAL-1", AppealResponses: true, FundID: "fund-1"},
+			logger:       slog.Default(),
+		}
+
+		for _, id := range []string{"don_123", "don_124"} {
+			result := svc.processDonation(context.Background(), fundraiseup.Donation{
+				Amount:    "50.00",
+				CreatedAt: time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC),
+				ID:        id,
+				Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+			})
+			require.NoError(t, result.Error)
+		}
+
+		require.Equal(t, []string{"const-123"}, bbClient.listedAppeals)
+		require.Len(t, bbClient.createdAppeals, 1)
+	})
+}
+
+func TestProcessDonationDeletedGift(t *testing.T) {
+	t.Parallel()
+
+	excludedAt := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	old := time.Now().Add(-60 * 24 * time.Hour).Truncate(time.Second)
+
+	tests := map[string]struct {
+		checkAge     time.Duration
+		dryRun       bool
+		excludedAt   time.Time
+		policy       string
+		storedGifts  map[string]*blackbaud.Gift
+		trackedAt    time.Time
+		wantChecks   int
+		wantCreated  bool
+		wantDeleted  bool
+		wantExcluded bool
+		wantGiftID   string
+		wantSkipped  bool
+		wantTracked  storage.DonationRecord
+		wantWarnings []string
+	}{
+		"no policy trusts the tracker": {
+			wantGiftID:  "tracked-gift",
+			wantSkipped: true,
+			wantTracked: storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"},
+		},
+		"gift still exists": {
+			policy:      config.DeletedGiftPolicyReport,
+			storedGifts: map[string]*blackbaud.Gift{"tracked-gift": {ID: "tracked-gift"}},
+			wantChecks:  1,
+			wantGiftID:  "tracked-gift",
+			wantSkipped: true,
+			wantTracked: storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"},
+		},
+		"gift tracked within the check age is checked": {
+			checkAge:     30 * 24 * time.Hour,
+			policy:       config.DeletedGiftPolicyReport,
+			trackedAt:    recent,
+			wantChecks:   1,
+			wantDeleted:  true,
+			wantGiftID:   "tracked-gift",
+			wantTracked:  storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift", TrackedAt: recent},
+			wantWarnings: []string{"gift tracked-gift was deleted in Raiser's Edge NXT and was not created again"},
+		},
+		"gift tracked before the check age is trusted": {
+			checkAge:    30 * 24 * time.Hour,
+			policy:      config.DeletedGiftPolicyReport,
+			trackedAt:   old,
+			wantGiftID:  "tracked-gift",
+			wantSkipped: true,
+			wantTracked: storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift", TrackedAt: old},
+		},
+		"gift without tracked time is trusted with a check age": {
+			checkAge:    30 * 24 * time.Hour,
+			policy:      config.DeletedGiftPolicyReport,
+			wantGiftID:  "tracked-gift",
+			wantSkipped: true,
+			wantTracked: storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"},
+		},
+		"report": {
+			policy:       config.DeletedGiftPolicyReport,
+			wantChecks:   1,
+			wantDeleted:  true,
+			wantGiftID:   "tracked-gift",
+			wantTracked:  storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"},
+			wantWarnings: []string{"gift tracked-gift was deleted in Raiser's Edge NXT and was not created again"},
+		},
+		"recreate": {
+			policy:      config.DeletedGiftPolicyRecreate,
+			wantChecks:  1,
+			wantCreated: true,
+			wantDeleted: true,
+			wantGiftID:  "gift-123",
+			wantTracked: storage.DonationRecord{
+				Amount:        "50.00",
+				ConstituentID: "const-123",
+				DonationID:    "don_123",
+				GiftID:        "gift-123",
+				GiftType:      "Donation",
+			},
+			wantWarnings: []string{"gift tracked-gift was deleted in Raiser's Edge NXT and is being created again"},
+		},
+		"exclude": {
+			policy:       config.DeletedGiftPolicyExclude,
+			wantChecks:   1,
+			wantDeleted:  true,
+			wantExcluded: true,
+			wantGiftID:   "tracked-gift",
+			wantWarnings: []string{
+				"gift tracked-gift was deleted in Raiser's Edge NXT, so the donation will no longer be synced",
+			},
+		},
+		"exclude in dry run": {
+			dryRun:       true,
+			policy:       config.DeletedGiftPolicyExclude,
+			wantChecks:   1,
+			wantDeleted:  true,
+			wantExcluded: true,
+			wantGiftID:   "tracked-gift",
+			wantTracked:  storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"},
+			wantWarnings: []string{
+				"gift tracked-gift was deleted in Raiser's Edge NXT, so the donation will no longer be synced",
+			},
+		},
+		"already excluded": {
+			excludedAt:   excludedAt,
+			policy:       config.DeletedGiftPolicyExclude,
+			wantExcluded: true,
+			wantGiftID:   "tracked-gift",
+			wantTracked:  storage.DonationRecord{DonationID: "don_123", ExcludedAt: excludedAt, GiftID: "tracked-gift"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bb := &mockBlackbaudClient{
+				constituents: []blackbaud.Constituent{{ID: "const-123"}},
+				storedGifts:  tc.storedGifts,
+			}
+			tracker := &mockTracker{records: map[string]storage.DonationRecord{
+				"don_123": {DonationID: "don_123", ExcludedAt: tc.excludedAt, GiftID: "tracked-gift", TrackedAt: tc.trackedAt},
+			}}
+			svc := &Service{
+				blackbaud:           bb,
+				deletedGiftCheckAge: tc.checkAge,
+				deletedGiftPolicy:   tc.policy,
+				dryRun:              tc.dryRun,
+				giftCache:           lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:        config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:              slog.Default(),
+				tracker:             tracker,
+			}
+
+			result := svc.processDonation(context.Background(), fundraiseup.Donation{
+				Amount:    "50.00",
+				ID:        "don_123",
+				Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+			})
+
+			require.NoError(t, result.Error)
+			require.Equal(t, tc.wantCreated, result.GiftCreated)
+			require.Equal(t, tc.wantDeleted, result.GiftDeleted)
+			require.Equal(t, tc.wantExcluded, result.Excluded)
+			require.Equal(t, tc.wantGiftID, result.GiftID)
+			require.Equal(t, tc.wantSkipped, result.GiftSkippedExisting)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+			require.Equal(t, tc.wantChecks, svc.metrics.GiftChecks.Calls)
+
+			tracked := tracker.records["don_123"]
+			if tc.policy == config.DeletedGiftPolicyExclude && tc.excludedAt.IsZero() && !tc.dryRun {
+				require.False(t, tracked.ExcludedAt.IsZero())
+				tracked.ExcludedAt = time.Time{}
+				tc.wantTracked = storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"}
+			}
+			require.Equal(t, tc.wantTracked, tracked)
+		})
+	}
+
+	t.Run("reports errors checking the gift", func(t *testing.T) {
+		t.Parallel()
+
+		svc := &Service{
+			blackbaud:         &failingGiftReader{},
+			deletedGiftPolicy: config.DeletedGiftPolicyRecreate,
+			logger:            slog.Default(),
+			tracker: &mockTracker{records: map[string]storage.DonationRecord{
+				"don_123": {DonationID: "don_123", GiftID: "tracked-gift"},
+			}},
+		}
+
+		result := svc.processDonation(context.Background(), fundraiseup.Donation{ID: "don_123"})
+
+		require.EqualError(t, result.Error, "checking tracked gift tracked-gift: unexpected status 500: unavailable")
+		require.False(t, result.GiftDeleted)
+	})
+
+	t.Run("does not exclude a donation tracked with another gift meanwhile", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := &staleTracker{
+			mockTracker: mockTracker{records: map[string]storage.DonationRecord{
+				"don_123": {DonationID: "don_123", GiftID: "new-gift"},
+			}},
+			stale: storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"},
+		}
+		svc := &Service{
+			blackbaud:         &mockBlackbaudClient{},
+			deletedGiftPolicy: config.DeletedGiftPolicyExclude,
+			logger:            slog.Default(),
+			tracker:           tracker,
+		}
+
+		result := svc.processDonation(context.Background(), fundraiseup.Donation{ID: "don_123"})
+
+		require.NoError(t, result.Error)
+		require.True(t, result.GiftDeleted)
+		require.False(t, result.Excluded)
+		require.Equal(t, []string{
+			"gift tracked-gift was deleted in Raiser's Edge NXT, and the donation is now tracked with another gift",
+		}, result.Warnings)
+		require.Equal(t, storage.DonationRecord{DonationID: "don_123", GiftID: "new-gift"}, tracker.records["don_123"])
+	})
+
+	t.Run("does not replace a gift tracked meanwhile", func(t *testing.T) {
+		t.Parallel()
+
+		var logs bytes.Buffer
+		tracker := &staleTracker{
+			mockTracker: mockTracker{records: map[string]storage.DonationRecord{
+				"don_123": {DonationID: "don_123", GiftID: "new-gift"},
+			}},
+			stale: storage.DonationRecord{DonationID: "don_123", GiftID: "tracked-gift"},
+		}
+		svc := &Service{
+			blackbaud: &mockBlackbaudClient{
+				constituents: []blackbaud.Constituent{{ID: "const-123"}},
+			},
+			deletedGiftPolicy: config.DeletedGiftPolicyRecreate,
+			giftCache:         lru.New[string, []blackbaud.Gift](0),
+			giftDefaults:      config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:            slog.New(slog.NewTextHandler(&logs, nil)),
+			tracker:           tracker,
+		}
+
+		result := svc.processDonation(context.Background(), fundraiseup.Donation{
+			Amount:    "50.00",
+			ID:        "don_123",
+			Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+		})
+
+		require.NoError(t, result.Error)
+		require.True(t, result.GiftCreated)
+		require.Equal(t, storage.DonationRecord{DonationID: "don_123", GiftID: "new-gift"}, tracker.records["don_123"])
+		require.Contains(t, logs.String(), "donation already tracked with another gift")
+		require.Contains(t, logs.String(), "tracked_gift_id=new-gift")
+	})
+}
+
+func TestProcessDonationEventParticipants(t *testing.T) {
+	t.Parallel()
+
+	links := map[string]string{"EVTGALA": "EVENT-1"}
+
+	tests := map[string]struct {
+		eventLinks       map[string]string
+		participantErr   error
+		participants     map[string][]blackbaud.Participant
+		ticket           *fundraiseup.Ticket
+		wantCreated      []*blackbaud.Participant
+		wantListedEvents []string
+		wantWarnings     []string
+	}{
+		"adds ticket buyer to linked event": {
+			eventLinks: links,
+			ticket:     &fundraiseup.Ticket{EventID: "EVTGALA", EventName: "Gala", Quantity: 2},
+			wantCreated: []*blackbaud.Participant{
+				{ConstituentID: "const-123", RSVPStatus: blackbaud.RSVPStatusAttending},
+			},
+			wantListedEvents: []string{"EVENT-1"},
+		},
+		"skips constituent already taking part": {
+			eventLinks: links,
+			participants: map[string][]blackbaud.Participant{
+				"EVENT-1": {{ConstituentID: "const-123", ID: "participant-1"}},
+			},
+			ticket:           &fundraiseup.Ticket{EventID: "EVTGALA"},
+			wantListedEvents: []string{"EVENT-1"},
+		},
+		"ignores event that is not linked": {
+			eventLinks: links,
+			ticket:     &fundraiseup.Ticket{EventID: "EVTOTHER"},
+		},
+		"ignores donation without ticket": {
+			eventLinks: links,
+		},
+		"failure reported as warning": {
+			eventLinks:       links,
+			participantErr:   errors.New("event not found"),
+			ticket:           &fundraiseup.Ticket{EventID: "EVTGALA"},
+			wantListedEvents: []string{"EVENT-1"},
+			wantWarnings:     []string{`adding constituent to event "EVENT-1": event not found`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &eventBlackbaudClient{
+				mockBlackbaudClient: mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				participantErr:      tc.participantErr,
+				participants:        tc.participants,
+			}
+			svc := &Service{
+				blackbaud:    bbClient,
+				eventLinks:   tc.eventLinks,
+				giftCache:    lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: config.GiftDefaults{FundID: "fund-1"},
+				logger:       slog.Default(),
+			}
+
+			result := svc.processDonation(context.Background(), fundraiseup.Donation{
+				Amount:    "50.00",
+				CreatedAt: time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC),
+				Currency:  "GBP",
+				ID:        "don_123",
+				Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+				Ticket:    tc.ticket,
+			})
+
+			require.NoError(t, result.Error)
+			require.True(t, result.GiftCreated)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+			require.Equal(t, tc.wantCreated, bbClient.createdParticipants)
+			require.Equal(t, tc.wantListedEvents, bbClient.listedEvents)
+		})
+	}
+
+	t.Run("lists each event's participants once a run", func(t *testing.T) {
+		t.Parallel()
+
+		bbClient := &eventBlackbaudClient{
+			mockBlackbaudClient: mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		}
+		svc := &Service{
+			blackbaud:    bbClient,
+			eventLinks:   links,
+			giftCache:    lru.New[string, []blackbaud.Gift](0),
+			giftDefaults: config.GiftDefaults{FundID: "fund-1"},
+			logger:       slog.Default(),
+		}
+
+		for _, id := range []string{"don_123", "don_124"} {
+			result := svc.processDonation(context.Background(), fundraiseup.Donation{
+				Amount:    "50.00",
+				CreatedAt: time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC),
+				ID:        id,
+				Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+				Ticket:    &fundraiseup.Ticket{EventID: "EVTGALA"},
+			})
+			require.NoError(t, result.Error)
+		}
+
+		require.Equal(t, []string{"EVENT-1"}, bbClient.listedEvents)
+		require.Len(t, bbClient.createdParticipants, 1)
+	})
+}
+
+func TestProcessDonationDonationNotes(t *testing.T) {
+	t.Parallel()
+
+	donation := fundraiseup.Donation{
+		Amount:        "25.00",
+		Campaign:      &fundraiseup.Campaign{ID: "camp_1", Name: "Spring Appeal"},
+		Comment:       "In memory of Mum",
+		CreatedAt:     time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC),
+		Currency:      "gbp",
+		ID:            "don_123",
+		RecurringPlan: &fundraiseup.RecurringPlan{Frequency: "monthly", ID: "rec_1"},
+		Supporter:     &fundraiseup.Supporter{Email: "test@example.com"},
+		Tracking:      &fundraiseup.Tracking{Device: "mobile", UTMMedium: "email", UTMSource: "newsletter"},
+	}
+
+	tests := map[string]struct {
+		donation     fundraiseup.Donation
+		format       string
+		noteErr      error
+		noteType     string
+		wantText     string
+		wantWarnings []string
+	}{
+		"default format": {
+			donation: donation,
+			noteType: "Online giving",
+			wantText: "Online donation don_123 of 25.00 GBP (monthly) to the Spring Appeal campaign.\n" +
+				"Source: newsletter / email\nDevice: mobile\nComment: In memory of Mum",
+		},
+		"default format without optional fields": {
+			donation: fundraiseup.Donation{
+				Amount:    "10.00",
+				CreatedAt: time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC),
+				Currency:  "USD",
+				ID:        "don_123",
+				Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+			},
+			noteType: "Online giving",
+			wantText: "Online donation don_123 of 10.00 USD.",
+		},
+		"custom format": {
+			donation: donation,
+			format:   "{{.Campaign}} via {{.UTMSource}} on {{.Device}}",
+			noteType: "Online giving",
+			wantText: "Spring Appeal via newsletter on mobile",
+		},
+		"format producing nothing adds no note": {
+			donation: donation,
+			format:   "{{.UTMTerm}}",
+			noteType: "Online giving",
+		},
+		"disabled": {
+			donation: donation,
+		},
+		"failure reported as warning": {
+			donation:     donation,
+			noteErr:      errors.New("invalid note type"),
+			noteType:     "Online giving",
+			wantWarnings: []string{"adding donation note: invalid note type"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			defaults := config.ConstituentDefaults{DonationNoteFormat: tc.format, DonationNoteType: tc.noteType}
+			donationNote, err := parseDonationNote(defaults)
+			require.NoError(t, err)
+
+			bbClient := &noteBlackbaudClient{
+				mockBlackbaudClient: mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				noteErr:             tc.noteErr,
+			}
+			svc := &Service{
+				blackbaud:           bbClient,
+				constituentDefaults: defaults,
+				donationNote:        donationNote,
+				giftCache:           lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:        config.GiftDefaults{FundID: "fund-1"},
+				logger:              slog.Default(),
+			}
+
+			result := svc.processDonation(context.Background(), tc.donation)
+
+			require.NoError(t, result.Error)
+			require.True(t, result.GiftCreated)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+			if tc.wantText == "" {
+				require.Empty(t, bbClient.notes)
+				return
+			}
+			require.Equal(t, []*blackbaud.ConstituentNote{
+				{
+					ConstituentID: "const-123",
+					Date:          &blackbaud.FuzzyDate{Day: 1, Month: 3, Year: 2025},
+					Summary:       "FundraiseUp donation don_123",
+					Text:          tc.wantText,
+					Type:          "Online giving",
+				},
+			}, bbClient.notes)
+		})
+	}
+}
+
+func TestProcessDonationPlanChangeNotes(t *testing.T) {
+	t.Parallel()
+
+	previous := storage.DonationRecord{
+		Amount:      "10.00",
+		CreatedAt:   time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC),
+		Currency:    "GBP",
+		DonationID:  "don_1",
+		Frequency:   "monthly",
+		GiftID:      "gift-1",
+		RecurringID: "rec_1",
+	}
+
+	tests := map[string]struct {
+		amount       string
+		frequency    string
+		historyErr   error
+		noteErr      error
+		noteType     string
+		records      []storage.DonationRecord
+		wantNotes    []*blackbaud.ConstituentNote
+		wantWarnings []string
+	}{
+		"increase adds note": {
+			amount:    "15.00",
+			frequency: "monthly",
+			noteType:  "Stewardship",
+			records:   []storage.DonationRecord{previous},
+			wantNotes: []*blackbaud.ConstituentNote{
+				{
+					ConstituentID: "const-123",
+					Date:          &blackbaud.FuzzyDate{Day: 1, Month: 3, Year: 2025},
+					Summary:       "Recurring gift increased",
+					Text: "Recurring plan rec_1 changed from 10.00 GBP monthly to 15.00 GBP monthly " +
+						"with donation don_2 on 2025-03-01.",
+					Type: "Stewardship",
+				},
+			},
+		},
+		"decrease adds note": {
+			amount:    "5",
+			frequency: "monthly",
+			noteType:  "Stewardship",
+			records:   []storage.DonationRecord{previous},
+			wantNotes: []*blackbaud.ConstituentNote{
+				{
+					ConstituentID: "const-123",
+					Date:          &blackbaud.FuzzyDate{Day: 1, Month: 3, Year: 2025},
+					Summary:       "Recurring gift decreased",
+					Text: "Recurring plan rec_1 changed from 10.00 GBP monthly to 5 GBP monthly " +
+						"with donation don_2 on 2025-03-01.",
+					Type: "Stewardship",
+				},
+			},
+		},
+		"frequency change adds note": {
+			amount:    "10.00",
+			frequency: "yearly",
+			noteType:  "Stewardship",
+			records:   []storage.DonationRecord{previous},
+			wantNotes: []*blackbaud.ConstituentNote{
+				{
+					ConstituentID: "const-123",
+					Date:          &blackbaud.FuzzyDate{Day: 1, Month: 3, Year: 2025},
+					Summary:       "Recurring gift changed",
+					Text: "Recurring plan rec_1 changed from 10.00 GBP monthly to 10.00 GBP yearly " +
+						"with donation don_2 on 2025-03-01.",
+					Type: "Stewardship",
+				},
+			},
+		},
+		"same amount written differently adds no note": {
+			amount:    "10",
+			frequency: "Monthly",
+			noteType:  "Stewardship",
+			records:   []storage.DonationRecord{previous},
+		},
+		"first installment adds no note": {
+			amount:    "15.00",
+			frequency: "monthly",
+			noteType:  "Stewardship",
+		},
+		"compares with latest earlier installment": {
+			amount:    "15.00",
+			frequency: "monthly",
+			noteType:  "Stewardship",
+			records: []storage.DonationRecord{
+				{
+					Amount:      "10.00",
+					CreatedAt:   time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC),
+					Currency:    "GBP",
+					DonationID:  "don_0",
+					Frequency:   "monthly",
+					GiftID:      "gift-0",
+					RecurringID: "rec_1",
+				},
+				{
+					Amount:      "15.00",
+					CreatedAt:   time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC),
+					Currency:    "GBP",
+					DonationID:  "don_1",
+					Frequency:   "monthly",
+					GiftID:      "gift-1",
+					RecurringID: "rec_1",
+				},
+			},
+		},
+		"disabled": {
+			amount:    "15.00",
+			frequency: "monthly",
+			records:   []storage.DonationRecord{previous},
+		},
+		"history failure reported as warning": {
+			amount:       "15.00",
+			frequency:    "monthly",
+			historyErr:   errors.New("throttled"),
+			noteType:     "Stewardship",
+			wantWarnings: []string{`listing donations of recurring plan "rec_1": throttled`},
+		},
+		"note failure reported as warning": {
+			amount:       "15.00",
+			frequency:    "monthly",
+			noteErr:      errors.New("invalid note type"),
+			noteType:     "Stewardship",
+			records:      []storage.DonationRecord{previous},
+			wantWarnings: []string{`adding plan change note for recurring plan "rec_1": invalid note type`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := &historyTracker{
+				historyErr:  tc.historyErr,
+				mockTracker: mockTracker{records: make(map[string]storage.DonationRecord)},
+			}
+			for _, record := range tc.records {
+				tracker.records[record.DonationID] = record
+			}
+			bbClient := &noteBlackbaudClient{
+				mockBlackbaudClient: mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				noteErr:             tc.noteErr,
+			}
+			svc := &Service{
+				blackbaud:          bbClient,
+				giftCache:          lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:       config.GiftDefaults{FundID: "fund-1"},
+				logger:             slog.Default(),
+				planChangeNoteType: tc.noteType,
+				tracker:            tracker,
+			}
+
+			result := svc.processDonation(context.Background(), fundraiseup.Donation{
+				Amount:        tc.amount,
+				CreatedAt:     time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC),
+				Currency:      "GBP",
+				ID:            "don_2",
+				RecurringPlan: &fundraiseup.RecurringPlan{Frequency: tc.frequency, ID: "rec_1"},
+				Supporter:     &fundraiseup.Supporter{Email: "test@example.com"},
+			})
+
+			require.NoError(t, result.Error)
+			require.True(t, result.GiftCreated)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+			require.Equal(t, tc.wantNotes, bbClient.notes)
+			require.Equal(t, tc.frequency, tracker.records["don_2"].Frequency)
+		})
+	}
+}
+
+func TestProcessDonationAlreadyTracked(t *testing.T) {
+	t.Parallel()
+
+	// A concurrent run tracks the donation after this run looked it up and before it tracks its own gift.
+	var logs bytes.Buffer
+	tracker := &racingTracker{mockTracker: mockTracker{records: map[string]storage.DonationRecord{
+		"don_123": {DonationID: "don_123", GiftID: "other-gift"},
+	}}}
+	svc := &Service{
+		blackbaud: &mockBlackbaudClient{
+			constituents: []blackbaud.Constituent{{ID: "const-123"}},
+		},
+		giftCache:    lru.New[string, []blackbaud.Gift](0),
+		giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		logger:       slog.New(slog.NewTextHandler(&logs, nil)),
+		tracker:      tracker,
+	}
+
+	result := svc.processDonation(context.Background(), fundraiseup.Donation{
+		Amount:    "50.00",
+		ID:        "don_123",
+		Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+	})
+
+	require.NoError(t, result.Error)
+	require.True(t, result.GiftCreated)
+	require.Equal(t, "other-gift", tracker.records["don_123"].GiftID)
+	require.Contains(t, logs.String(), "donation already tracked with another gift, check for a duplicate")
+	require.Contains(t, logs.String(), "gift_id=gift-123 tracked_gift_id=other-gift")
+	require.NotContains(t, logs.String(), "failed to track donation")
+	require.Equal(t, []string{
+		"already tracked with gift other-gift, so gift gift-123 was not tracked; check it is not a duplicate",
+	}, result.Warnings)
+}
+
+func TestProcessDonationCampaignWindows(t *testing.T) {
+	t.Parallel()
+
+	// Test donations are made on 2 March 2025.
+	march := func(day int) time.Time { return time.Date(2025, time.March, day, 0, 0, 0, 0, time.UTC) }
+
+	tests := map[string]struct {
+		campaign    *fundraiseup.Campaign
+		windows     []config.CampaignWindow
+		wantSkipped bool
+	}{
+		"syncs donations without windows": {
+			campaign: &fundraiseup.Campaign{ID: "FUNSPRING"},
+		},
+		"syncs donations of campaigns without a window": {
+			campaign: &fundraiseup.Campaign{ID: "FUNAUTUMN"},
+			windows:  []config.CampaignWindow{{CampaignID: "FUNSPRING", Exclude: true}},
+		},
+		"syncs donations without a campaign": {
+			windows: []config.CampaignWindow{{CampaignID: "FUNSPRING", Exclude: true}},
+		},
+		"skips donations of excluded campaigns": {
+			campaign:    &fundraiseup.Campaign{ID: "FUNLEGACY"},
+			windows:     []config.CampaignWindow{{CampaignID: "FUNLEGACY", Exclude: true}},
+			wantSkipped: true,
+		},
+		"skips donations made before the window": {
+			campaign:    &fundraiseup.Campaign{ID: "FUNSPRING"},
+			windows:     []config.CampaignWindow{{CampaignID: "FUNSPRING", From: march(3)}},
+			wantSkipped: true,
+		},
+		"syncs donations made within the window": {
+			campaign: &fundraiseup.Campaign{ID: "FUNSPRING"},
+			windows:  []config.CampaignWindow{{CampaignID: "FUNSPRING", From: march(1), Until: march(3)}},
+		},
+		"skips donations made after the window": {
+			campaign:    &fundraiseup.Campaign{ID: "FUNSPRING"},
+			windows:     []config.CampaignWindow{{CampaignID: "FUNSPRING", Until: march(2)}},
+			wantSkipped: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			windows := make(map[string]config.CampaignWindow)
+			for _, window := range tc.windows {
+				windows[window.CampaignID] = window
+			}
+			bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+			svc := &Service{
+				blackbaud:       bbClient,
+				campaignWindows: windows,
+				giftCache:       lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:    config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:          slog.Default(),
+			}
+			donation := testDonation("don_123")
+			donation.Campaign = tc.campaign
+
+			result := svc.processDonation(context.Background(), donation)
+
+			require.NoError(t, result.Error)
+			require.Equal(t, tc.wantSkipped, result.SkippedCampaign)
+			require.Equal(t, !tc.wantSkipped, result.GiftCreated)
+			if tc.wantSkipped {
+				require.Empty(t, bbClient.searches, "skipped donations should not look for a constituent")
+			}
+		})
+	}
+}
+
+func TestProcessAndRecordCountsCampaigns(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{
+		blackbaud: &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		campaignWindows: map[string]config.CampaignWindow{
+			"FUNLEGACY": {CampaignID: "FUNLEGACY", Exclude: true},
+		},
+		constituentCache: make(map[string]string),
+		giftCache:        lru.New[string, []blackbaud.Gift](0),
+		giftDefaults:     config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		logger:           slog.Default(),
+	}
+	result := &Result{}
+
+	for _, campaignID := range []string{"FUNLEGACY", "FUNSPRING", "FUNLEGACY", ""} {
+		donation := testDonation("don_" + campaignID)
+		if campaignID != "" {
+			donation.Campaign = &fundraiseup.Campaign{ID: campaignID}
+		}
+		require.NoError(t, svc.processAndRecord(context.Background(), result, donation))
+	}
+
+	require.Equal(t, 4, result.DonationsProcessed)
+	require.Equal(t, 2, result.DonationsSkippedCampaign)
+	require.Equal(t, 2, result.GiftsCreated)
+	require.Equal(t, map[string]CampaignCounts{
+		"":          {Donations: 1, GiftsCreated: 1},
+		"FUNLEGACY": {Donations: 2, SkippedOutsideWindow: 2},
+		"FUNSPRING": {Donations: 1, GiftsCreated: 1},
+	}, result.Campaigns)
+}
+
+func TestProcessDonationTestMode(t *testing.T) {
+	t.Parallel()
+
+	live := true
+	test := false
+
+	tests := map[string]struct {
+		livemode      *bool
+		splits        []config.GiftSplit
+		testDonations string
+		testFundID    string
+		wantFundIDs   []string
+		wantSkipped   bool
+	}{
+		"skips test donations by default": {
+			livemode:    &test,
+			wantSkipped: true,
+		},
+		"skips test donations": {
+			livemode:      &test,
+			testDonations: config.TestDonationsSkip,
+			wantSkipped:   true,
+		},
+		"syncs live donations": {
+			livemode:    &live,
+			wantFundIDs: []string{"fund-1"},
+		},
+		"syncs donations whose mode is not reported": {
+			wantFundIDs: []string{"fund-1"},
+		},
+		"syncs test donations when enabled": {
+			livemode:      &test,
+			testDonations: config.TestDonationsSync,
+			wantFundIDs:   []string{"fund-1"},
+		},
+		"routes test donations to the test fund in place of splits": {
+			livemode:      &test,
+			splits:        []config.GiftSplit{{FundID: "fund-admin", Percent: 10}},
+			testDonations: config.TestDonationsSync,
+			testFundID:    "fund-sandbox",
+			wantFundIDs:   []string{"fund-sandbox"},
+		},
+		"keeps live donations out of the test fund": {
+			livemode:      &live,
+			testDonations: config.TestDonationsSync,
+			testFundID:    "fund-sandbox",
+			wantFundIDs:   []string{"fund-1"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+			svc := &Service{
+				blackbaud: bbClient,
+				giftCache: lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: config.GiftDefaults{
+					FundID:        "fund-1",
+					Splits:        tc.splits,
+					TestDonations: tc.testDonations,
+					TestFundID:    tc.testFundID,
+					Type:          "Donation",
+				},
+				logger: slog.Default(),
+			}
+			donation := testDonation("don_123")
+			donation.Livemode = tc.livemode
+
+			result := svc.processDonation(context.Background(), donation)
+
+			require.NoError(t, result.Error)
+			require.Equal(t, tc.wantSkipped, result.SkippedTest)
+			if tc.wantSkipped {
+				require.False(t, result.GiftCreated)
+				require.Empty(t, bbClient.searches, "skipped donations should not look for a constituent")
+				require.Empty(t, bbClient.createdGifts)
+				return
+			}
+			require.True(t, result.GiftCreated)
+			require.Len(t, bbClient.createdGifts, 1)
+			var fundIDs []string
+			for _, split := range bbClient.createdGifts[0].GiftSplits {
+				fundIDs = append(fundIDs, split.FundID)
+			}
+			require.Equal(t, tc.wantFundIDs, fundIDs)
+			require.InDelta(t, 10.0, bbClient.createdGifts[0].GiftSplits[0].Amount.Value, 0.001)
+		})
+	}
+}
+
+func TestProcessDonationGiftChecks(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		checks          []config.GiftCheck
+		dryRun          bool
+		wantConstituent bool
+		wantCreated     bool
+		wantErr         string
+		wantHeld        bool
+		wantViolations  []string
+	}{
+		"no checks creates the gift": {
+			wantConstituent: true,
+			wantCreated:     true,
+		},
+		"passing checks create the gift": {
+			checks: []config.GiftCheck{
+				{Assert: "gift.fund_id != ''"},
+				{Assert: "donation.amount < 1000", Message: "large gifts need review"},
+			},
+			wantConstituent: true,
+			wantCreated:     true,
+		},
+		"failing donation checks block the constituent": {
+			checks: []config.GiftCheck{
+				{Assert: "gift.fund_id == 'fund-restricted'", Message: "gift must go to the restricted fund"},
+				{Assert: "donation.amount < 1000"},
+				{Assert: "donation.currency == 'GBP'"},
+				{Assert: "supporter.email != ''", Message: "supporter needs an email"},
+			},
+			wantHeld:       true,
+			wantViolations: []string{"failed check donation.currency == 'GBP'"},
+		},
+		"failing gift checks block the gift": {
+			checks: []config.GiftCheck{
+				{Assert: "gift.fund_id == 'fund-restricted'", Message: "gift must go to the restricted fund"},
+				{Assert: "gift.type == 'Pledge'"},
+				{Assert: "donation.amount < 1000"},
+			},
+			wantConstituent: true,
+			wantHeld:        true,
+			wantViolations: []string{
+				"gift must go to the restricted fund",
+				"failed check gift.type == 'Pledge'",
+			},
+		},
+		"dry runs are not held": {
+			checks:         []config.GiftCheck{{Assert: "donation.currency == 'GBP'"}},
+			dryRun:         true,
+			wantViolations: []string{"failed check donation.currency == 'GBP'"},
+		},
+		"evaluation error blocks the gift": {
+			checks:  []config.GiftCheck{{Assert: "10 / donation.installment > 1"}},
+			wantErr: "gift check 1: assert: division by zero",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			checks, err := compileGiftChecks(tc.checks)
+			require.NoError(t, err)
+			bbClient := &mockBlackbaudClient{}
+			stateStore := &mockGiftReviewStateStore{}
+			svc := &Service{
+				blackbaud:    bbClient,
+				dryRun:       tc.dryRun,
+				giftCache:    lru.New[string, []blackbaud.Gift](0),
+				giftChecks:   checks,
+				giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				giftReviews:  stateStore,
+				logger:       slog.Default(),
+			}
+
+			result := svc.processDonation(context.Background(), testDonation("don_123"))
+
+			require.Equal(t, tc.wantConstituent, len(bbClient.created) == 1)
+			require.Equal(t, tc.wantCreated, result.GiftCreated)
+			if tc.wantHeld {
+				require.Len(t, stateStore.reviews, 1)
+				require.Equal(t, "don_123", stateStore.reviews[0].DonationID)
+				require.Equal(t, tc.wantViolations, stateStore.reviews[0].Violations)
+			} else {
+				require.Empty(t, stateStore.reviews)
+			}
+			if tc.wantCreated {
+				require.NoError(t, result.Error)
+				require.Len(t, bbClient.createdGifts, 1)
+				return
+			}
+			require.Empty(t, bbClient.createdGifts)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, result.Error, tc.wantErr)
+				return
+			}
+			var checkErr *GiftCheckError
+			require.ErrorAs(t, result.Error, &checkErr)
+			require.Equal(t, tc.wantViolations, checkErr.Violations)
+		})
+	}
+}
+
+func TestCheckGiftDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.June, 15, 23, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		createdAt  time.Time
+		maxAgeDays int
+		policy     string
+		wantDate   string
+		wantReason string
+	}{
+		"no policy leaves dates unchecked": {
+			createdAt: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"today passes": {
+			createdAt: time.Date(2025, time.June, 15, 8, 0, 0, 0, time.UTC),
+			policy:    config.GiftDatePolicyRefuse,
+		},
+		"tomorrow passes for supporters ahead of UTC": {
+			createdAt: time.Date(2025, time.June, 16, 10, 0, 0, 0, time.FixedZone("NZST", 12*60*60)),
+			policy:    config.GiftDatePolicyRefuse,
+		},
+		"future date": {
+			createdAt:  time.Date(2025, time.June, 17, 0, 0, 0, 0, time.UTC),
+			policy:     config.GiftDatePolicyRefuse,
+			wantDate:   "2025-06-17",
+			wantReason: "is in the future",
+		},
+		"old date without a maximum age passes": {
+			createdAt: time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
+			policy:    config.GiftDatePolicyFlag,
+		},
+		"date at the maximum age passes": {
+			createdAt:  time.Date(2025, time.May, 16, 0, 0, 0, 0, time.UTC),
+			maxAgeDays: 30,
+			policy:     config.GiftDatePolicyFlag,
+		},
+		"date older than the maximum age": {
+			createdAt:  time.Date(2025, time.May, 15, 23, 59, 0, 0, time.UTC),
+			maxAgeDays: 30,
+			policy:     config.GiftDatePolicyFlag,
+			wantDate:   "2025-05-15",
+			wantReason: "is more than 30 days old",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{
+				giftDefaults: config.GiftDefaults{DatePolicy: tc.policy, MaxAgeDays: tc.maxAgeDays},
+			}
+
+			err := svc.checkGiftDate(tc.createdAt, now)
+
+			if tc.wantReason == "" {
+				require.NoError(t, err)
+				return
+			}
+			var dateErr *GiftDateError
+			require.ErrorAs(t, err, &dateErr)
+			require.Equal(t, tc.wantDate, dateErr.Date)
+			require.Equal(t, tc.wantReason, dateErr.Reason)
+		})
+	}
+}
+
+func TestProcessDonationGiftDatePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		policy       string
+		wantCreated  bool
+		wantWarnings int
+	}{
+		"flag creates the gift with a warning": {
+			policy:       config.GiftDatePolicyFlag,
+			wantCreated:  true,
+			wantWarnings: 1,
+		},
+		"refuse blocks the constituent and gift": {
+			policy: config.GiftDatePolicyRefuse,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &mockBlackbaudClient{}
+			svc := &Service{
+				blackbaud: bbClient,
+				giftCache: lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: config.GiftDefaults{
+					DatePolicy: tc.policy,
+					FundID:     "fund-1",
+					MaxAgeDays: 30,
+					Type:       "Donation",
+				},
+				logger: slog.Default(),
+			}
+
+			// The test donation was made in March 2025, long before the maximum age.
+			result := svc.processDonation(context.Background(), testDonation("don_123"))
+
+			require.Equal(t, tc.wantCreated, result.GiftCreated)
+			require.Len(t, result.Warnings, tc.wantWarnings)
+			if tc.wantCreated {
+				require.NoError(t, result.Error)
+				require.Len(t, bbClient.created, 1)
+				require.Len(t, bbClient.createdGifts, 1)
+				require.Contains(t, result.Warnings[0], "is more than 30 days old")
+				return
+			}
+			require.Empty(t, bbClient.created)
+			require.Empty(t, bbClient.createdGifts)
+			var dateErr *GiftDateError
+			require.ErrorAs(t, result.Error, &dateErr)
+		})
+	}
+}
+
+// failingGiftReader is a mockBlackbaudClient whose gift reads fail with a server error.
+type failingGiftReader struct {
+	mockBlackbaudClient
+}
+
+// Gift always fails.
+func (f *failingGiftReader) Gift(_ context.Context, _ string) (*blackbaud.Gift, error) {
+	return nil, &blackbaud.StatusError{Body: "unavailable", StatusCode: http.StatusInternalServerError}
+}
+
+// quotaBlackbaudClient is a mockBlackbaudClient that reports a fixed call quota.
+type quotaBlackbaudClient struct {
+	mockBlackbaudClient
+
+	quota *blackbaud.Quota
+}
+
+// Quota returns the configured quota, if any.
+func (q *quotaBlackbaudClient) Quota() (blackbaud.Quota, bool) {
+	if q.quota == nil {
+		return blackbaud.Quota{}, false
+	}
+	return *q.quota, true
+}
+
+// appealBlackbaudClient is a mockBlackbaudClient that records constituents' responses to appeals.
+type appealBlackbaudClient struct {
+	mockBlackbaudClient
+
+	appealErr      error
+	appeals        map[string][]blackbaud.ConstituentAppeal
+	createdAppeals []*blackbaud.ConstituentAppeal
+	listedAppeals  []string
+}
+
+// ConstituentAppeals returns the configured appeals for the constituent, recording the lookup.
+func (a *appealBlackbaudClient) ConstituentAppeals(
+	_ context.Context,
+	constituentID string,
+) ([]blackbaud.ConstituentAppeal, error) {
+	a.listedAppeals = append(a.listedAppeals, constituentID)
+	return a.appeals[constituentID], nil
+}
+
+// CreateConstituentAppeal records the appeal response, failing with appealErr when set.
+func (a *appealBlackbaudClient) CreateConstituentAppeal(
+	_ context.Context,
+	appeal *blackbaud.ConstituentAppeal,
+) (string, error) {
+	if a.appealErr != nil {
+		return "", a.appealErr
+	}
+	a.createdAppeals = append(a.createdAppeals, appeal)
+	return "constituent-appeal-123", nil
+}
+
+// supporterCacheTracker is a mockTracker that remembers the constituent each email matched.
+type supporterCacheTracker struct {
+	mockTracker
+
+	cached      map[string]string
+	forgotten   []string
+	lookupErr   error
+	supporterID string
+}
+
+// CacheConstituent remembers the constituent for the email, and the supporter it was remembered for.
+func (s *supporterCacheTracker) CacheConstituent(
+	_ context.Context,
+	email string,
+	supporterID string,
+	constituentID string,
+	_ time.Time,
+) error {
+	s.cached[email] = constituentID
+	s.supporterID = supporterID
+	return nil
+}
+
+// CachedConstituent returns the constituent remembered for the email, failing with lookupErr when set.
+func (s *supporterCacheTracker) CachedConstituent(_ context.Context, email string) (string, error) {
+	if s.lookupErr != nil {
+		return "", s.lookupErr
+	}
+	return s.cached[email], nil
+}
+
+// ForgetConstituent forgets the constituent remembered for the email.
+func (s *supporterCacheTracker) ForgetConstituent(_ context.Context, email string) error {
+	delete(s.cached, email)
+	s.forgotten = append(s.forgotten, email)
+	return nil
+}
+
+// goneConstituentClient is a mockBlackbaudClient that no longer has one constituent, as after a merge or deletion,
+// failing gift lists or gifts for it with not found.
+type goneConstituentClient struct {
+	mockBlackbaudClient
+
+	gone     string
+	listGone bool
+}
+
+// CreateGift fails with not found for the gone constituent.
+func (g *goneConstituentClient) CreateGift(ctx context.Context, gift *blackbaud.Gift) (string, error) {
+	if gift.ConstituentID == g.gone {
+		return "", &blackbaud.StatusError{Body: "constituent not found", StatusCode: http.StatusNotFound}
+	}
+	return g.mockBlackbaudClient.CreateGift(ctx, gift)
+}
+
+// ListGiftsByConstituent fails with not found for the gone constituent when listGone is set.
+func (g *goneConstituentClient) ListGiftsByConstituent(
+	ctx context.Context,
+	constituentID string,
+	types []blackbaud.GiftType,
+) ([]blackbaud.Gift, error) {
+	if g.listGone && constituentID == g.gone {
+		return nil, &blackbaud.StatusError{Body: "constituent not found", StatusCode: http.StatusNotFound}
+	}
+	return g.mockBlackbaudClient.ListGiftsByConstituent(ctx, constituentID, types)
+}
+
+// emailBlackbaudClient is a mockBlackbaudClient that adds email addresses to constituents.
+type emailBlackbaudClient struct {
+	mockBlackbaudClient
+
+	addErr error
+	added  []*blackbaud.EmailAddress
+}
+
+// CreateEmailAddress records the email address, failing with addErr when set.
+func (e *emailBlackbaudClient) CreateEmailAddress(_ context.Context, email *blackbaud.EmailAddress) (string, error) {
+	if e.addErr != nil {
+		return "", e.addErr
+	}
+	e.added = append(e.added, email)
+	return "email-123", nil
+}
+
+// eventBlackbaudClient is a mockBlackbaudClient that adds constituents to events as participants.
+type eventBlackbaudClient struct {
+	mockBlackbaudClient
+
+	createdParticipants []*blackbaud.Participant
+	listedEvents        []string
+	participantErr      error
+	participants        map[string][]blackbaud.Participant
+}
+
+// CreateEventParticipant records the participant, failing with participantErr when set.
+func (e *eventBlackbaudClient) CreateEventParticipant(
+	_ context.Context,
+	_ string,
+	participant *blackbaud.Participant,
+) (string, error) {
+	if e.participantErr != nil {
+		return "", e.participantErr
+	}
+	e.createdParticipants = append(e.createdParticipants, participant)
+	return "participant-123", nil
+}
+
+// EventParticipants returns the configured participants of the event, recording the lookup.
+func (e *eventBlackbaudClient) EventParticipants(_ context.Context, eventID string) ([]blackbaud.Participant, error) {
+	e.listedEvents = append(e.listedEvents, eventID)
+	return e.participants[eventID], nil
+}
+
+// noteBlackbaudClient is a mockBlackbaudClient that adds notes to constituents.
+type noteBlackbaudClient struct {
+	mockBlackbaudClient
+
+	noteErr error
+	notes   []*blackbaud.ConstituentNote
+}
+
+// CreateConstituentNote records the note, failing with noteErr when set.
+func (n *noteBlackbaudClient) CreateConstituentNote(
+	_ context.Context,
+	note *blackbaud.ConstituentNote,
+) (string, error) {
+	if n.noteErr != nil {
+		return "", n.noteErr
+	}
+	n.notes = append(n.notes, note)
+	return "note-123", nil
+}
+
+// giftNoteBlackbaudClient is a mockBlackbaudClient that adds notes to gifts.
+type giftNoteBlackbaudClient struct {
+	mockBlackbaudClient
+
+	giftNoteErr error
+	giftNotes   []*blackbaud.GiftNote
+}
+
+// CreateGiftNote records the note, failing with giftNoteErr when set.
+func (g *giftNoteBlackbaudClient) CreateGiftNote(_ context.Context, note *blackbaud.GiftNote) (string, error) {
+	if g.giftNoteErr != nil {
+		return "", g.giftNoteErr
+	}
+	g.giftNotes = append(g.giftNotes, note)
+	return "gift-note-123", nil
+}
+
+// giftCustomFieldBlackbaudClient is a mockBlackbaudClient that adds custom fields to gifts.
+type giftCustomFieldBlackbaudClient struct {
+	mockBlackbaudClient
+
+	customFieldErr error
+	customFields   []*blackbaud.GiftCustomField
+}
+
+// CreateGiftCustomField records the custom field, failing with customFieldErr when set.
+func (g *giftCustomFieldBlackbaudClient) CreateGiftCustomField(
+	_ context.Context,
+	field *blackbaud.GiftCustomField,
+) (string, error) {
+	if g.customFieldErr != nil {
+		return "", g.customFieldErr
+	}
+	g.customFields = append(g.customFields, field)
+	return "gift-custom-field-123", nil
+}
+
+// recordingHook is a Hook that stamps new records and records the gifts it sees created.
+type recordingHook struct {
+	NopHook
+
+	afterErr        error
+	beforeGiftErr   error
+	constituentType string
+	createdGiftIDs  []string
+}
+
+// AfterGiftCreate records the created gift's ID, failing with afterErr when set.
+func (h *recordingHook) AfterGiftCreate(
+	_ context.Context,
+	_ fundraiseup.Donation,
+	giftID string,
+	_ *blackbaud.Gift,
+) error {
+	h.createdGiftIDs = append(h.createdGiftIDs, giftID)
+	return h.afterErr
+}
+
+// BeforeConstituentCreate stamps the constituent's type.
+func (h *recordingHook) BeforeConstituentCreate(
+	_ context.Context,
+	_ fundraiseup.Donation,
+	constituent *blackbaud.Constituent,
+) error {
+	constituent.Type = h.constituentType
+	return nil
+}
+
+// BeforeGiftCreate stamps the gift's reference with the donation ID, failing with beforeGiftErr when set.
+func (h *recordingHook) BeforeGiftCreate(_ context.Context, donation fundraiseup.Donation, gift *blackbaud.Gift) error {
+	if h.beforeGiftErr != nil {
+		return h.beforeGiftErr
+	}
+	gift.Reference = "hooked " + donation.ID
+	return nil
+}
+
+// panickingHook panics before creating a gift for the donation with the given ID.
+type panickingHook struct {
+	NopHook
+
+	donationID string
+}
+
+// BeforeGiftCreate panics for the hook's donation.
+func (h *panickingHook) BeforeGiftCreate(_ context.Context, donation fundraiseup.Donation, _ *blackbaud.Gift) error {
+	if donation.ID == h.donationID {
+		panic("malformed donation")
+	}
+	return nil
+}
+
+func TestProcessDonationScrubsComment(t *testing.T) {
+	t.Parallel()
+
+	scrubber, err := normalize.NewCommentScrubber(config.CommentScrubbing{CardNumbers: true, Words: []string{"darn"}})
+	require.NoError(t, err)
+
+	bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+	svc := &Service{
+		blackbaud:       bbClient,
+		commentScrubber: scrubber,
+		giftCache:       lru.New[string, []blackbaud.Gift](0),
+		giftDefaults:    config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		logger:          slog.Default(),
+	}
+	donation := testDonation("don_123")
+	donation.Comment = "Darn, charge 4242 4242 4242 4242 again"
+
+	result := svc.processDonation(context.Background(), donation)
+
+	require.NoError(t, result.Error)
+	require.Len(t, bbClient.createdGifts, 1)
+	require.Equal(t, "[removed], charge [removed] again", bbClient.createdGifts[0].Reference)
+}
+
+func TestProcessDonationSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	withoutComment := donationSize(testDonation("don_123"))
+	tests := map[string]struct {
+		comment       string
+		errMsg        string
+		limit         int
+		wantReference string
+		wantWarnings  []string
+	}{
+		"donation within the limit unchanged": {
+			comment:       "In memory of Gran",
+			limit:         withoutComment + 100,
+			wantReference: "In memory of Gran",
+		},
+		"long comment shortened to fit": {
+			comment:       strings.Repeat("a", 500),
+			limit:         withoutComment + 10,
+			wantReference: strings.Repeat("a", 10),
+			wantWarnings:  []string{"comment shortened from 500 to 10 bytes to keep the donation within"},
+		},
+		"comment not cut mid-character": {
+			comment:       strings.Repeat("é", 100),
+			limit:         withoutComment + 9,
+			wantReference: strings.Repeat("é", 4),
+			wantWarnings:  []string{"comment shortened from 200 to 8 bytes"},
+		},
+		"donation too large without its comment": {
+			comment: "In memory of Gran",
+			errMsg:  "over the 100 byte limit even without its comment",
+			limit:   100,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+			svc := &Service{
+				blackbaud:       bbClient,
+				giftCache:       lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:    config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:          slog.New(slog.DiscardHandler),
+				maxDonationSize: tc.limit,
+			}
+			donation := testDonation("don_123")
+			donation.Comment = tc.comment
+
+			result := svc.processDonation(context.Background(), donation)
+
+			if tc.errMsg != "" {
+				require.ErrorContains(t, result.Error, tc.errMsg)
+				require.Empty(t, bbClient.createdGifts)
+				return
+			}
+			require.NoError(t, result.Error)
+			require.Len(t, bbClient.createdGifts, 1)
+			require.Equal(t, tc.wantReference, bbClient.createdGifts[0].Reference)
+			require.Len(t, result.Warnings, len(tc.wantWarnings))
+			for i, want := range tc.wantWarnings {
+				require.Contains(t, result.Warnings[i], want)
+			}
+		})
+	}
+}
+
+func TestTruncateReference(t *testing.T) {
+	t.Parallel()
+
+	sentence := "We walked the coast path every summer with Gran and she loved this place. "
+	tests := map[string]struct {
+		reference string
+		want      string
+		wantFull  bool
+	}{
+		"short reference unchanged": {
+			reference: "In memory of Gran",
+			want:      "In memory of Gran",
+		},
+		"reference at the limit unchanged": {
+			reference: strings.Repeat("a", 255),
+			want:      strings.Repeat("a", 255),
+		},
+		"long reference cut at a word boundary": {
+			reference: strings.Repeat(sentence, 4),
+			want:      strings.TrimSpace(strings.Repeat(sentence, 3)) + " We walked the coast path every…",
+			wantFull:  true,
+		},
+		"long reference without spaces cut at the limit": {
+			reference: strings.Repeat("a", 300),
+			want:      strings.Repeat("a", 254) + "…",
+			wantFull:  true,
+		},
+		"characters counted rather than bytes": {
+			reference: strings.Repeat("é", 300),
+			want:      strings.Repeat("é", 254) + "…",
+			wantFull:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gift := &blackbaud.Gift{Reference: tc.reference}
+
+			full := truncateReference(gift)
+
+			require.Equal(t, tc.want, gift.Reference)
+			require.LessOrEqual(t, utf8.RuneCountInString(gift.Reference), blackbaud.MaxGiftReferenceLength)
+			if !tc.wantFull {
+				require.Empty(t, full)
+				return
+			}
+			require.Equal(t, tc.reference, full)
+		})
+	}
+}
+
+func TestProcessDonationReferenceNotes(t *testing.T) {
+	t.Parallel()
+
+	longComment := strings.Repeat("Thank you for caring for Dad. ", 10)
+	tests := map[string]struct {
+		comment       string
+		giftNoteErr   error
+		noteType      string
+		wantNotes     []*blackbaud.GiftNote
+		wantReference string
+		wantWarnings  []string
+	}{
+		"short comment adds no note": {
+			comment:       "In memory of Dad",
+			noteType:      "Comment",
+			wantReference: "In memory of Dad",
+		},
+		"long comment continued in a gift note": {
+			comment:  longComment,
+			noteType: "Comment",
+			wantNotes: []*blackbaud.GiftNote{{
+				Date:    &blackbaud.FuzzyDate{Day: 2, Month: 3, Year: 2025},
+				GiftID:  "gift-123",
+				Summary: "Full reference",
+				Text:    longComment,
+				Type:    "Comment",
+			}},
+			wantReference: strings.TrimSpace(strings.Repeat("Thank you for caring for Dad. ", 8)) + " Thank you for…",
+		},
+		"long comment truncated without a note type": {
+			comment:       longComment,
+			wantReference: strings.TrimSpace(strings.Repeat("Thank you for caring for Dad. ", 8)) + " Thank you for…",
+		},
+		"note failure reported as warning": {
+			comment:       longComment,
+			giftNoteErr:   errors.New("invalid note type"),
+			noteType:      "Comment",
+			wantReference: strings.TrimSpace(strings.Repeat("Thank you for caring for Dad. ", 8)) + " Thank you for…",
+			wantWarnings: []string{
+				"reference truncated, adding gift note with the full reference: invalid note type",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &giftNoteBlackbaudClient{
+				giftNoteErr: tc.giftNoteErr,
+				mockBlackbaudClient: mockBlackbaudClient{
+					constituents: []blackbaud.Constituent{{ID: "const-123"}},
+				},
+			}
+			svc := &Service{
+				blackbaud:    bbClient,
+				giftCache:    lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: config.GiftDefaults{FundID: "fund-1", ReferenceNoteType: tc.noteType, Type: "Donation"},
+				logger:       slog.Default(),
+			}
+			donation := testDonation("don_123")
+			donation.Comment = tc.comment
+
+			result := svc.processDonation(context.Background(), donation)
+
+			require.NoError(t, result.Error)
+			require.Len(t, bbClient.createdGifts, 1)
+			require.Equal(t, tc.wantReference, bbClient.createdGifts[0].Reference)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+			require.Equal(t, tc.wantNotes, bbClient.giftNotes)
+		})
+	}
+}
+
+func TestResultMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value any
+		want  string
+	}{
+		"result with errors as messages": {
+			value: Result{
+				DonationsProcessed:     3,
+				Errors:                 []error{errors.New("don_3: creating gift: unexpected status 400")},
+				GiftsCreated:           1,
+				GiftsSkippedExisting:   1,
+				GiftsSkippedExistingBy: map[string]int{DuplicateTracker: 1},
+				RunID:                  "2025-03-01T12:00:00Z",
+			},
+			want: `{"donationsProcessed":3,"errors":["don_3: creating gift: unexpected status 400"],` +
+				`"giftsCreated":1,"giftsSkippedExisting":1,"giftsSkippedExistingBy":{"tracker":1},` +
+				`"runId":"2025-03-01T12:00:00Z"}`,
+		},
+		"result pointer": {
+			value: &Result{Metrics: Metrics{Blackbaud: CallMetrics{Calls: 2}}},
+			want: `{"donationsProcessed":0,"metrics":{"blackbaud":{"calls":2,"duration":0},"fetchDuration":0,` +
+				`"fundraiseup":{"calls":0,"duration":0},"giftCache":{"evictions":0,"hits":0,"misses":0},` +
+				`"giftChecks":{"calls":0,"duration":0},"processDuration":0,"stateStore":{"calls":0,"duration":0},` +
+				`"totalDuration":0,"tracker":{"calls":0,"duration":0}}}`,
+		},
+		"skipped existing donation": {
+			value: DonationResult{
+				DonationID:          "don_1",
+				DuplicateFoundBy:    DuplicateLookupID,
+				GiftID:              "gift-1",
+				GiftSkippedExisting: true,
+			},
+			want: `{"donationId":"don_1","duplicateFoundBy":"lookup_id","giftId":"gift-1",` +
+				`"giftSkippedExisting":true}`,
+		},
+		"failed donation": {
+			value: DonationResult{DonationID: "don_2", Error: errors.New("creating gift: unexpected status 400")},
+			want:  `{"donationId":"don_2","error":"creating gift: unexpected status 400"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := json.Marshal(tc.value)
+
+			require.NoError(t, err)
+			require.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestProcessDonationCreatorCustomField(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		category         string
+		customFieldErr   error
+		wantCustomFields []*blackbaud.GiftCustomField
+		wantWarnings     []string
+	}{
+		"no category adds no custom field": {},
+		"gift records its creator and run": {
+			category: "Created by",
+			wantCustomFields: []*blackbaud.GiftCustomField{{
+				Category: "Created by",
+				ParentID: "gift-123",
+				Value:    "GiftBridge run 2025-03-01T12:00:00Z",
+			}},
+		},
+		"custom field failure reported as warning": {
+			category:       "Created by",
+			customFieldErr: errors.New("category not found"),
+			wantWarnings:   []string{"adding creator custom field: category not found"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &giftCustomFieldBlackbaudClient{
+				customFieldErr: tc.customFieldErr,
+				mockBlackbaudClient: mockBlackbaudClient{
+					constituents: []blackbaud.Constituent{{ID: "const-123"}},
+				},
+			}
+			svc := &Service{
+				blackbaud:    bbClient,
+				giftCache:    lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: config.GiftDefaults{CreatorCustomField: tc.category, FundID: "fund-1", Type: "Donation"},
+				logger:       slog.Default(),
+				runID:        runID(time.Date(2025, time.March, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))),
+			}
+
+			result := svc.processDonation(context.Background(), testDonation("don_123"))
+
+			require.NoError(t, result.Error)
+			require.True(t, result.GiftCreated)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+			require.Equal(t, tc.wantCustomFields, bbClient.customFields)
+		})
+	}
+}
+
+func TestApplyEditedComment(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		comment     string
+		records     map[string]storage.DonationRecord
+		wantChanged bool
+		wantUpdate  *blackbaud.Gift
+	}{
+		"updates tracked gift with edited comment": {
+			comment:     "In memory of Gran, 4242 4242 4242 4242",
+			records:     map[string]storage.DonationRecord{"don_123": {DonationID: "don_123", GiftID: "gift-1"}},
+			wantChanged: true,
+			wantUpdate:  &blackbaud.Gift{Reference: "In memory of Gran, [removed]"},
+		},
+		"skips untracked donation": {
+			comment: "In memory of Gran",
+		},
+		"skips unchanged comment": {
+			comment: "Original comment",
+			records: map[string]storage.DonationRecord{"don_123": {DonationID: "don_123", GiftID: "gift-1"}},
+		},
+		"leaves removed comment in place": {
+			records: map[string]storage.DonationRecord{"don_123": {DonationID: "don_123", GiftID: "gift-1"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			scrubber, err := normalize.NewCommentScrubber(config.CommentScrubbing{CardNumbers: true})
+			require.NoError(t, err)
+
+			bbClient := &mockBlackbaudClient{
+				storedGifts: map[string]*blackbaud.Gift{"gift-1": {ID: "gift-1", Reference: "Original comment"}},
+			}
+			svc := &Service{
+				blackbaud:       bbClient,
+				commentScrubber: scrubber,
+				giftDefaults:    config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:          slog.Default(),
+				tracker:         &mockTracker{records: tc.records},
+			}
+			donation := testDonation("don_123")
+			donation.Comment = tc.comment
+
+			changed, warnings, err := svc.applyEditedComment(context.Background(), donation)
+
+			require.NoError(t, err)
+			require.Empty(t, warnings)
+			require.Equal(t, tc.wantChanged, changed)
+			if tc.wantUpdate == nil {
+				require.Empty(t, bbClient.updatedGifts)
+				return
+			}
+			require.Equal(t, tc.wantUpdate, bbClient.updatedGifts["gift-1"])
+		})
+	}
+}
+
+func TestApplyChargeback(t *testing.T) {
+	t.Parallel()
+
+	tracked := map[string]storage.DonationRecord{"don_123": {DonationID: "don_123", GiftID: "gift-1"}}
+	at := time.Date(2025, time.April, 8, 14, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		giftStatus   string
+		noteErr      error
+		noteType     string
+		records      map[string]storage.DonationRecord
+		status       string
+		wantChanged  bool
+		wantNote     *blackbaud.ConstituentNote
+		wantWarnings []string
+	}{
+		"marks tracked gift": {
+			giftStatus:  "Active",
+			records:     tracked,
+			status:      fundraiseup.DonationStatusChargedBack,
+			wantChanged: true,
+		},
+		"adds chargeback note": {
+			noteType:    "Finance",
+			records:     tracked,
+			status:      fundraiseup.DonationStatusChargedBack,
+			wantChanged: true,
+			wantNote: &blackbaud.ConstituentNote{
+				ConstituentID: "const-123",
+				Date:          &blackbaud.FuzzyDate{Day: 8, Month: 4, Year: 2025},
+				Summary:       "FundraiseUp chargeback don_123",
+				Text: "Online donation don_123 of 10.00 GBP was charged back on 2025-04-08, " +
+					"so gift gift-1 was marked Held.",
+				Type: "Finance",
+			},
+		},
+		"adds dispute note": {
+			noteType:    "Finance",
+			records:     tracked,
+			status:      fundraiseup.DonationStatusDisputed,
+			wantChanged: true,
+			wantNote: &blackbaud.ConstituentNote{
+				ConstituentID: "const-123",
+				Date:          &blackbaud.FuzzyDate{Day: 8, Month: 4, Year: 2025},
+				Summary:       "FundraiseUp dispute don_123",
+				Text: "Online donation don_123 of 10.00 GBP was disputed on 2025-04-08, " +
+					"so gift gift-1 was marked Held.",
+				Type: "Finance",
+			},
+		},
+		"warns when the note cannot be added": {
+			noteErr:      errors.New("note type not found"),
+			noteType:     "Finance",
+			records:      tracked,
+			status:       fundraiseup.DonationStatusChargedBack,
+			wantChanged:  true,
+			wantWarnings: []string{"adding chargeback note: note type not found"},
+		},
+		"skips untracked donation": {
+			noteType: "Finance",
+			status:   fundraiseup.DonationStatusChargedBack,
+		},
+		"skips gift already marked": {
+			giftStatus: "held",
+			noteType:   "Finance",
+			records:    tracked,
+			status:     fundraiseup.DonationStatusDisputed,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &noteBlackbaudClient{
+				mockBlackbaudClient: mockBlackbaudClient{
+					storedGifts: map[string]*blackbaud.Gift{
+						"gift-1": {ConstituentID: "const-123", GiftStatus: tc.giftStatus, ID: "gift-1"},
+					},
+				},
+				noteErr: tc.noteErr,
+			}
+			svc := &Service{
+				blackbaud:          bbClient,
+				chargebackNoteType: tc.noteType,
+				chargebackStatus:   "Held",
+				logger:             slog.Default(),
+				tracker:            &mockTracker{records: tc.records},
+			}
+			donation := testDonation("don_123")
+			donation.Currency = "gbp"
+			donation.Status = tc.status
+
+			changed, warnings, err := svc.applyChargeback(context.Background(), donation, at)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantChanged, changed)
+			require.Equal(t, tc.wantWarnings, warnings)
+			if !tc.wantChanged {
+				require.Empty(t, bbClient.updatedGifts)
+				require.Empty(t, bbClient.notes)
+				return
+			}
+			require.Equal(t, &blackbaud.Gift{GiftStatus: "Held"}, bbClient.updatedGifts["gift-1"])
+			if tc.wantNote == nil {
+				require.Empty(t, bbClient.notes)
+				return
+			}
+			require.Equal(t, []*blackbaud.ConstituentNote{tc.wantNote}, bbClient.notes)
+		})
+	}
+}
+
+func TestProcessDonationHooks(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		afterErr         error
+		beforeGiftErr    error
+		dryRun           bool
+		wantCreatedGifts int
+		wantErr          string
+		wantGiftIDs      []string
+		wantWarnings     []string
+	}{
+		"changes reach blackbaud": {
+			wantCreatedGifts: 1,
+			wantGiftIDs:      []string{"gift-123"},
+		},
+		"before gift error fails donation": {
+			beforeGiftErr: errors.New("no campaign"),
+			wantErr:       "before gift create hook: no campaign",
+		},
+		"after gift error is a warning": {
+			afterErr:         errors.New("webhook down"),
+			wantCreatedGifts: 1,
+			wantGiftIDs:      []string{"gift-123"},
+			wantWarnings:     []string{"after gift create hook: webhook down"},
+		},
+		"after gift skipped in dry run": {
+			dryRun:           true,
+			wantCreatedGifts: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bbClient := &mockBlackbaudClient{}
+			hook := &recordingHook{
+				afterErr:        tc.afterErr,
+				beforeGiftErr:   tc.beforeGiftErr,
+				constituentType: "Organization",
+			}
+			svc := &Service{
+				blackbaud:    bbClient,
+				dryRun:       tc.dryRun,
+				giftCache:    lru.New[string, []blackbaud.Gift](0),
+				giftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				hooks:        []Hook{hook},
+				logger:       slog.Default(),
+			}
+			donation := fundraiseup.Donation{
+				ID:        "don_123",
+				Amount:    "50.00",
+				Supporter: &fundraiseup.Supporter{Email: "new@example.com"},
+			}
+
+			result := svc.processDonation(context.Background(), donation)
+
+			require.Len(t, bbClient.created, 1)
+			require.Equal(t, "Organization", bbClient.created[0].Type)
+			require.Equal(t, tc.wantGiftIDs, hook.createdGiftIDs)
+			require.Len(t, bbClient.createdGifts, tc.wantCreatedGifts)
+			if tc.wantErr != "" {
+				require.EqualError(t, result.Error, tc.wantErr)
+				return
+			}
+			require.NoError(t, result.Error)
+			require.Equal(t, "hooked don_123", bbClient.createdGifts[0].Reference)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+		})
+	}
+}
+
+func TestCompareGifts(t *testing.T) {
+	t.Parallel()
+
+	sent := blackbaud.Gift{
+		Amount:        &blackbaud.GiftAmount{Value: 10.005},
+		ConstituentID: "const-1",
+		Date:          "2024-01-15",
+		GiftSplits: []blackbaud.GiftSplit{{
+			Amount:   &blackbaud.GiftAmount{Value: 10.005},
+			AppealID: "appeal-1",
+			FundID:   "fund-1",
+		}},
+		LookupID: "don_1",
+		Type:     blackbaud.GiftTypeDonation,
+	}
+
+	tests := map[string]struct {
+		modify func(stored *blackbaud.Gift)
+		want   []GiftDiscrepancy
+	}{
+		"stored as sent": {
+			modify: func(*blackbaud.Gift) {},
+		},
+		"ignores time of day and fields left to the server": {
+			modify: func(stored *blackbaud.Gift) {
+				stored.Date = "2024-01-15T00:00:00"
+				stored.PostStatus = blackbaud.GiftPostStatusNotPosted
+				stored.GiftSplits[0].CampaignID = "campaign-default"
+			},
+		},
+		"rounded amount and defaulted fund": {
+			modify: func(stored *blackbaud.Gift) {
+				stored.Amount = &blackbaud.GiftAmount{Value: 10}
+				stored.GiftSplits[0].FundID = "fund-default"
+			},
+			want: []GiftDiscrepancy{
+				{Field: "amount", Got: "10.00", Want: "10.01"},
+				{Field: "gift_splits[0].fund_id", Got: "fund-default", Want: "fund-1"},
+			},
+		},
+		"split count": {
+			modify: func(stored *blackbaud.Gift) {
+				stored.GiftSplits = nil
+			},
+			want: []GiftDiscrepancy{{Field: "gift_splits", Got: "0", Want: "1"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stored := sent
+			stored.GiftSplits = append([]blackbaud.GiftSplit(nil), sent.GiftSplits...)
+			tc.modify(&stored)
+
+			require.Equal(t, tc.want, compareGifts(&sent, &stored))
+		})
+	}
+}
+
+func TestVerifyGifts(t *testing.T) {
+	t.Parallel()
+
+	donation := fundraiseup.Donation{
+		Amount:    "50.00",
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		ID:        "don_123",
+		Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+	}
+
+	tests := map[string]struct {
+		stored            map[string]*blackbaud.Gift
+		verify            bool
+		wantDiscrepancies []GiftDiscrepancy
+		wantVerified      int
+		wantWarnings      []string
+	}{
+		"matching gift": {
+			stored: map[string]*blackbaud.Gift{"gift-123": {
+				Amount:        &blackbaud.GiftAmount{Value: 50},
+				ConstituentID: "const-123",
+				Date:          "2024-01-15T00:00:00",
+				GiftSplits:    []blackbaud.GiftSplit{{Amount: &blackbaud.GiftAmount{Value: 50}, FundID: "fund-1"}},
+				LookupID:      "don_123",
+				Type:          blackbaud.GiftTypeDonation,
+			}},
+			verify:       true,
+			wantVerified: 1,
+		},
+		"defaulted type": {
+			stored: map[string]*blackbaud.Gift{"gift-123": {
+				Amount:        &blackbaud.GiftAmount{Value: 50},
+				ConstituentID: "const-123",
+				Date:          "2024-01-15T00:00:00",
+				GiftSplits:    []blackbaud.GiftSplit{{Amount: &blackbaud.GiftAmount{Value: 50}, FundID: "fund-1"}},
+				LookupID:      "don_123",
+				Type:          "Pledge",
+			}},
+			verify: true,
+			wantDiscrepancies: []GiftDiscrepancy{
+				{DonationID: "don_123", Field: "type", GiftID: "gift-123", Got: "Pledge", Want: "Donation"},
+			},
+			wantVerified: 1,
+		},
+		"unreadable gift": {
+			verify:       true,
+			wantWarnings: []string{"donation don_123: verifying gift gift-123: unexpected status 404: gift not found"},
+		},
+		"disabled": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{
+				blackbaud: &mockBlackbaudClient{
+					constituents: []blackbaud.Constituent{{ID: "const-123"}},
+					storedGifts:  tc.stored,
+				},
+				constituentCache: make(map[string]string),
+				giftCache:        lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:     config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:           slog.Default(),
+				verify:           tc.verify,
+			}
+
+			donationResult := svc.processDonation(context.Background(), donation)
+			require.NoError(t, donationResult.Error)
+
+			result := &Result{}
+			svc.verifyGifts(context.Background(), result)
+
+			require.Equal(t, tc.wantVerified, result.GiftsVerified)
+			require.Equal(t, tc.wantDiscrepancies, result.Discrepancies)
+			require.Equal(t, tc.wantWarnings, result.Warnings)
+		})
+	}
+}
+
+func TestQuotaLow(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client    BlackbaudClient
+		reserve   int
+		want      bool
+		wantQuota *blackbaud.Quota
+	}{
+		"client without quota reporting": {
+			client:  &mockBlackbaudClient{},
+			reserve: 100,
+			want:    false,
+		},
+		"no quota reported yet": {
+			client:  &quotaBlackbaudClient{},
+			reserve: 100,
+			want:    false,
+		},
+		"below reserve": {
+			client:    &quotaBlackbaudClient{quota: &blackbaud.Quota{Remaining: 99}},
+			reserve:   100,
+			want:      true,
+			wantQuota: &blackbaud.Quota{Remaining: 99},
+		},
+		"hedged calls count against reserve": {
+			client:    &quotaBlackbaudClient{quota: &blackbaud.Quota{HedgedCalls: 2, Remaining: 101}},
+			reserve:   100,
+			want:      true,
+			wantQuota: &blackbaud.Quota{HedgedCalls: 2, Remaining: 101},
+		},
+		"at reserve": {
+			client:    &quotaBlackbaudClient{quota: &blackbaud.Quota{Remaining: 100}},
+			reserve:   100,
+			want:      false,
+			wantQuota: &blackbaud.Quota{Remaining: 100},
+		},
+		"no reserve still records quota": {
+			client:    &quotaBlackbaudClient{quota: &blackbaud.Quota{Remaining: 0}},
+			want:      false,
+			wantQuota: &blackbaud.Quota{Remaining: 0},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{blackbaud: tc.client, quotaReserve: tc.reserve}
+			result := &Result{}
+
+			require.Equal(t, tc.want, svc.quotaLow(result))
+			require.Equal(t, tc.wantQuota, result.BlackbaudQuota)
+		})
+	}
+}
+
+func TestRunResumePausesForQuota(t *testing.T) {
+	t.Parallel()
+
+	lastSync := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	stateStore := &mockStateStore{lastSync: lastSync, pendingIDs: []string{"don_1", "don_2"}}
+	svc := &Service{
+		blackbaud:    &quotaBlackbaudClient{quota: &blackbaud.Quota{Remaining: 10}},
+		logger:       slog.Default(),
+		quotaReserve: 50,
+		stateStore:   stateStore,
+	}
+
+	result, err := svc.runResume(context.Background(), &Result{}, stateStore.pendingIDs)
+
+	require.NoError(t, err)
+	require.True(t, result.PausedForQuota)
+	require.Zero(t, result.DonationsProcessed)
+	require.Equal(t, []string{"don_1", "don_2"}, stateStore.pendingIDs)
+	require.Equal(t, lastSync, stateStore.lastSync)
+}
+
+func TestRunCheckpointsFetch(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		fetchState      *storage.FetchState
+		maxDonations    int
+		wantCursors     []string
+		wantFetchState  *storage.FetchState
+		wantLastSyncSet bool
+		wantProcessed   int
+	}{
+		"completes window and clears checkpoint": {
+			maxDonations:    10,
+			wantCursors:     []string{"", "don_2"},
+			wantFetchState:  nil,
+			wantLastSyncSet: true,
+			wantProcessed:   3,
+		},
+		"keeps checkpoint when limited": {
+			maxDonations:    2,
+			wantCursors:     []string{"", "don_2"},
+			wantFetchState:  &storage.FetchState{Cursor: "don_2", Since: since},
+			wantLastSyncSet: false,
+			wantProcessed:   2,
+		},
+		"truncates page at limit": {
+			maxDonations:    1,
+			wantCursors:     []string{""},
+			wantFetchState:  &storage.FetchState{Cursor: "don_1", Since: since},
+			wantLastSyncSet: false,
+			wantProcessed:   1,
+		},
+		"continues from checkpoint": {
+			fetchState:      &storage.FetchState{Cursor: "don_2", Since: since},
+			maxDonations:    10,
+			wantCursors:     []string{"don_2"},
+			wantFetchState:  nil,
+			wantLastSyncSet: true,
+			wantProcessed:   1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var cursors []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				cursor := r.URL.Query().Get("starting_after")
+				cursors = append(cursors, cursor)
+
+				page := map[string]any{
+					"data":     []fundraiseup.Donation{testDonation("don_1"), testDonation("don_2")},
+					"has_more": true,
+				}
+				if cursor == "don_2" {
+					page = map[string]any{"data": []fundraiseup.Donation{testDonation("don_3")}, "has_more": false}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(page)
+			}))
+			defer server.Close()
+
+			fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			stateStore := &mockStateStore{fetchState: tc.fetchState, lastSync: since}
+			svc := &Service{
+				blackbaud:          &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				constituentCache:   make(map[string]string),
+				fundraiseup:        fuClient,
+				giftCache:          lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:       config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:             slog.Default(),
+				maxDonationsPerRun: tc.maxDonations,
+				stateStore:         stateStore,
+			}
+
+			result, err := svc.runFresh(context.Background(), &Result{})
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantProcessed, result.DonationsProcessed)
+			require.Empty(t, result.Errors)
+			require.Equal(t, tc.wantCursors, cursors)
+			require.Equal(t, tc.wantFetchState, stateStore.fetchState)
+			require.Empty(t, stateStore.pendingIDs)
+			require.Equal(t, tc.wantLastSyncSet, stateStore.lastSync.After(since))
+		})
+	}
+}
+
+func TestRunResumeContinuesFetch(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/donations/don_2" {
+			_ = json.NewEncoder(w).Encode(testDonation("don_2"))
+			return
+		}
+		require.Equal(t, "don_2", r.URL.Query().Get("starting_after"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":     []fundraiseup.Donation{testDonation("don_3")},
+			"has_more": false,
+		})
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	stateStore := &mockStateStore{
+		fetchState: &storage.FetchState{Cursor: "don_2", Since: since},
+		lastSync:   since,
+		pendingIDs: []string{"don_2"},
+	}
+	svc := &Service{
+		blackbaud:          &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		constituentCache:   make(map[string]string),
+		fundraiseup:        fuClient,
+		giftCache:          lru.New[string, []blackbaud.Gift](0),
+		giftDefaults:       config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		logger:             slog.Default(),
+		maxDonationsPerRun: 10,
+		stateStore:         stateStore,
+	}
+
+	result, err := svc.runResume(context.Background(), &Result{}, stateStore.pendingIDs)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, result.DonationsProcessed)
+	require.Empty(t, result.Errors)
+	require.Nil(t, stateStore.fetchState)
+	require.Empty(t, stateStore.pendingIDs)
+	require.True(t, stateStore.lastSync.After(since))
+}
+
+func TestRunFailFast(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		failFast        bool
+		wantFetchState  *storage.FetchState
+		wantLastSyncSet bool
+		wantPending     []string
+		wantProcessed   int
+		wantStopped     bool
+	}{
+		"carries on after a failed donation": {
+			wantLastSyncSet: true,
+			wantPending:     []string{},
+			wantProcessed:   3,
+		},
+		"stops at the first failed donation": {
+			failFast:       true,
+			wantFetchState: &storage.FetchState{Cursor: "don_3", Since: since},
+			wantPending:    []string{"don_2", "don_3"},
+			wantProcessed:  2,
+			wantStopped:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				invalid := testDonation("don_2")
+				invalid.Amount = "ten pounds"
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"data":     []fundraiseup.Donation{testDonation("don_1"), invalid, testDonation("don_3")},
+					"has_more": false,
+				})
+			}))
+			defer server.Close()
+
+			fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			stateStore := &mockStateStore{lastSync: since}
+			svc := &Service{
+				blackbaud:          &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				constituentCache:   make(map[string]string),
+				failFast:           tc.failFast,
+				fundraiseup:        fuClient,
+				giftCache:          lru.New[string, []blackbaud.Gift](0),
+				giftDefaults:       config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				logger:             slog.Default(),
+				maxDonationsPerRun: 10,
+				stateStore:         stateStore,
+			}
+
+			result, err := svc.runFresh(context.Background(), &Result{})
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantProcessed, result.DonationsProcessed)
+			require.Len(t, result.Errors, 1)
+			require.Equal(t, tc.wantStopped, result.StoppedOnError)
+			require.Equal(t, tc.wantFetchState, stateStore.fetchState)
+			require.Equal(t, tc.wantPending, stateStore.pendingIDs)
+			require.Equal(t, tc.wantLastSyncSet, stateStore.lastSync.After(since))
+		})
+	}
+}
+
+func TestRunDonationsFailFast(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		donation := testDonation(strings.TrimPrefix(r.URL.Path, "/donations/"))
+		if donation.ID == "don_2" {
+			donation.Amount = "ten pounds"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(donation)
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	svc := &Service{
+		blackbaud:        &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		constituentCache: make(map[string]string),
+		donationIDs:      []string{"don_1", "don_2", "don_3"},
+		failFast:         true,
+		fundraiseup:      fuClient,
+		giftCache:        lru.New[string, []blackbaud.Gift](0),
+		giftDefaults:     config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		logger:           slog.Default(),
+	}
+
+	result, err := svc.runDonations(context.Background(), &Result{})
+
+	require.NoError(t, err)
+	require.True(t, result.StoppedOnError)
+	require.Equal(t, 2, result.DonationsProcessed)
+	require.Len(t, result.Errors, 1)
+	require.Equal(t, []string{"don_2", "don_3"}, result.RemainingDonationIDs)
+}
+
+func TestRunAdvancesSyncTime(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	hour := func(n int) time.Time { return since.Add(time.Duration(n) * time.Hour) }
+
+	// Thirty donations, newest first.
+	var backfill []time.Time
+	for i := 30; i > 0; i-- {
+		backfill = append(backfill, hour(i))
+	}
+
+	tests := map[string]struct {
+		created       []time.Time
+		dryRun        bool
+		lastSync      time.Time
+		sinceOverride *time.Time
+		wantLastSync  time.Time
+		wantSyncTimes []time.Time
+	}{
+		"newest donation processed": {
+			created:       []time.Time{hour(2), hour(1), hour(3)},
+			lastSync:      since,
+			wantLastSync:  hour(3),
+			wantSyncTimes: []time.Time{hour(3)},
+		},
+		"advances as the window is processed": {
+			created:       backfill,
+			lastSync:      since,
+			wantLastSync:  hour(30),
+			wantSyncTimes: []time.Time{hour(25), hour(30)},
+		},
+		"override does not move sync time back": {
+			created:       []time.Time{hour(1), hour(2)},
+			lastSync:      hour(48),
+			sinceOverride: &since,
+			wantLastSync:  hour(48),
+		},
+		"dry run": {
+			created:      []time.Time{hour(1), hour(2)},
+			dryRun:       true,
+			lastSync:     since,
+			wantLastSync: since,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var donations []fundraiseup.Donation
+			for i, created := range tc.created {
+				donation := testDonation(fmt.Sprintf("don_%02d", i+1))
+				donation.CreatedAt = created
+				donations = append(donations, donation)
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": donations, "has_more": false})
+			}))
+			t.Cleanup(server.Close)
+
+			fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			stateStore := &mockStateStore{lastSync: tc.lastSync}
+			svc, err := New(Config{
+				Blackbaud:     &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				DryRun:        tc.dryRun,
+				FundraiseUp:   fuClient,
+				GiftDefaults:  config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				SinceOverride: tc.sinceOverride,
+				StateStore:    stateStore,
+			})
+			require.NoError(t, err)
+
+			result, err := svc.Run(context.Background())
+
+			require.NoError(t, err)
+			require.Equal(t, len(tc.created), result.DonationsProcessed)
+			require.Empty(t, result.Errors)
+			require.Equal(t, tc.wantLastSync, stateStore.lastSync)
+			require.Equal(t, tc.wantSyncTimes, stateStore.syncTimes)
+		})
+	}
+}
+
+func TestRunRecordsMetrics(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/donations/don_2" {
+			_ = json.NewEncoder(w).Encode(testDonation("don_2"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":     []fundraiseup.Donation{testDonation("don_3")},
+			"has_more": false,
+		})
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	svc, err := New(Config{
+		Blackbaud:    &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		FundraiseUp:  fuClient,
+		GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		StateStore: &mockStateStore{
+			fetchState: &storage.FetchState{Cursor: "don_2", Since: since},
+			lastSync:   since,
+			pendingIDs: []string{"don_2"},
+		},
+		Tracker: &mockTracker{records: make(map[string]storage.DonationRecord)},
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 2, result.DonationsProcessed)
+
+	// One donation fetched by ID on resume, then one page continuing the fetch.
+	require.Equal(t, 2, result.Metrics.FundraiseUp.Calls)
+	// A search and gift list for the shared constituent, then a gift for each donation.
+	require.Equal(t, 4, result.Metrics.Blackbaud.Calls)
+	require.Equal(t, 1, result.Metrics.GiftCache.Misses)
+	require.Equal(t, 9, result.Metrics.StateStore.Calls)
+	require.Equal(t, 4, result.Metrics.Tracker.Calls)
+
+	require.Positive(t, result.Metrics.FetchDuration)
+	require.GreaterOrEqual(t, result.Metrics.FundraiseUp.Duration, result.Metrics.FetchDuration)
+	require.Positive(t, result.Metrics.ProcessDuration)
+	require.Equal(t, result.Metrics.ProcessDuration/2, result.AverageDonationDuration())
+	require.GreaterOrEqual(t, result.Metrics.TotalDuration, result.Metrics.FundraiseUp.Duration)
+
+	t.Run("restarts for each run", func(t *testing.T) {
+		// The next run fetches don_3 again and skips it as already tracked.
+		result, err := svc.Run(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, 1, result.DonationsProcessed)
+		require.Equal(t, 1, result.Metrics.FundraiseUp.Calls)
+		require.Zero(t, result.Metrics.Blackbaud.Calls)
+		require.Zero(t, result.Metrics.GiftCache.Misses)
+		require.Equal(t, 1, result.Metrics.Tracker.Calls)
+	})
+}
+
+func TestRunBatchesTrackedDonations(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	// Serve 30 donations, repeating one whose gift is still buffered.
+	donations := make([]fundraiseup.Donation, 0, 31)
+	for i := range 30 {
+		donations = append(donations, testDonation(fmt.Sprintf("don_%d", i)))
+	}
+	donations = append(donations, testDonation("don_28"))
+	donations[3].RecurringPlan = &fundraiseup.RecurringPlan{ID: "rec_1"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": donations, "has_more": false})
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	tracker := &mockBatchTracker{mockTracker: mockTracker{records: make(map[string]storage.DonationRecord)}}
+	svc, err := New(Config{
+		Blackbaud:    &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		FundraiseUp:  fuClient,
+		GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		StateStore:   &mockSyncTimeStore{lastSync: since},
+		Tracker:      tracker,
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 31, result.DonationsProcessed)
+	require.Equal(t, 30, result.GiftsCreated)
+	require.Equal(t, 1, result.GiftsSkippedExisting)
+	require.Equal(t, map[string]int{DuplicateTracker: 1}, result.GiftsSkippedExistingBy)
+	require.Equal(t, []int{25, 5}, tracker.batches)
+	require.Len(t, tracker.records, 30)
+	require.Equal(t, "rec_1", tracker.records["don_3"].RecurringID)
+	require.Empty(t, tracker.recurring)
+	// A lookup for each donation not already buffered, then the two batches.
+	require.Equal(t, 32, result.Metrics.Tracker.Calls)
+}
+
+func TestFlushTrackedSkipsAlreadyTracked(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	tracker := &mockBatchTracker{mockTracker: mockTracker{records: map[string]storage.DonationRecord{
+		"don_2": {DonationID: "don_2", GiftID: "other-gift"},
+	}}}
+	svc := &Service{
+		logger:  slog.New(slog.NewTextHandler(&logs, nil)),
+		tracker: tracker,
+		trackBuffer: []storage.DonationRecord{
+			{DonationID: "don_1", GiftID: "gift-1"},
+			{DonationID: "don_2", GiftID: "gift-2"},
+			{DonationID: "don_3", GiftID: "gift-3"},
+		},
+	}
+
+	svc.flushTracked(context.Background())
+
+	require.Empty(t, svc.trackBuffer)
+	require.Equal(t, []int{3}, tracker.batches)
+	require.Equal(t, "gift-1", tracker.records["don_1"].GiftID)
+	require.Equal(t, "other-gift", tracker.records["don_2"].GiftID)
+	require.Equal(t, "gift-3", tracker.records["don_3"].GiftID)
+	require.Contains(t, logs.String(), "donation_id=don_2 gift_id=gift-2 tracked_gift_id=other-gift")
+	require.NotContains(t, logs.String(), "failed to track donations")
+	require.Equal(t, []string{
+		"donation don_2: already tracked with gift other-gift, so gift gift-2 was not tracked; check it is not a duplicate",
+	}, svc.trackWarnings)
+}
+
+func TestRunWithoutPendingStore(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []fundraiseup.Donation{
+				testDonation("don_1"),
+				testDonation("don_2"),
+				testDonation("don_3"),
+			},
+			"has_more": false,
+		})
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	stateStore := &mockSyncTimeStore{lastSync: since}
+	svc, err := New(Config{
+		Blackbaud:          &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		FundraiseUp:        fuClient,
+		GiftDefaults:       config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		MaxDonationsPerRun: 2,
+		StateStore:         stateStore,
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+
+	// The window cannot be continued next run, so the per-run limit does not apply.
+	require.NoError(t, err)
+	require.Equal(t, 3, result.DonationsProcessed)
+	require.Empty(t, result.Errors)
+	require.True(t, stateStore.lastSync.After(since))
+	// Only the sync time is read and written.
+	require.Equal(t, 2, result.Metrics.StateStore.Calls)
+}
+
+func TestRunRecordsHistory(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		dryRun   bool
+		wantRuns int
+	}{
+		"records run": {
+			wantRuns: 1,
+		},
+		"does not record dry run": {
+			dryRun:   true,
+			wantRuns: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			invalid := testDonation("don_2")
+			invalid.Amount = "not-a-number"
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"data":     []fundraiseup.Donation{testDonation("don_1"), invalid},
+					"has_more": false,
+				})
+			}))
+			defer server.Close()
+
+			fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			stateStore := &mockStateStore{}
+			svc, err := New(Config{
+				Blackbaud:    &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				DryRun:       tc.dryRun,
+				FundraiseUp:  fuClient,
+				GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				StateStore:   stateStore,
+			})
+			require.NoError(t, err)
+
+			_, err = svc.Run(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, stateStore.runs, tc.wantRuns)
+			if tc.wantRuns == 0 {
+				return
+			}
+			run := stateStore.runs[0]
+			require.Equal(t, 2, run.DonationsProcessed)
+			require.Equal(t, 1, run.GiftsCreated)
+			require.Equal(t, 1, run.Errors)
+			require.Equal(t, map[string]int{"other": 1}, run.ErrorCategories)
+			require.False(t, run.StartedAt.IsZero())
+		})
+	}
+}
+
+func TestRunRecordsPoisonPills(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		dryRun    bool
+		wantPills int
+	}{
+		"records poison pill": {
+			wantPills: 1,
+		},
+		"does not record dry run": {
+			dryRun:    true,
+			wantPills: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"data":     []fundraiseup.Donation{testDonation("don_1"), testDonation("don_2")},
+					"has_more": false,
+				})
+			}))
+			defer server.Close()
+
+			fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			stateStore := &mockPoisonPillStateStore{}
+			svc, err := New(Config{
+				Blackbaud:    &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				DryRun:       tc.dryRun,
+				FundraiseUp:  fuClient,
+				GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				Hooks:        []Hook{&panickingHook{donationID: "don_1"}},
+				StateStore:   stateStore,
+			})
+			require.NoError(t, err)
+
+			result, err := svc.Run(context.Background())
+			require.NoError(t, err)
+
+			// The panic fails only its own donation, and the run carries on with the next.
+			require.Equal(t, 2, result.DonationsProcessed)
+			require.Equal(t, 1, result.GiftsCreated)
+			require.Len(t, result.Errors, 1)
+			var panicErr *PanicError
+			require.ErrorAs(t, result.Errors[0], &panicErr)
+			require.Equal(t, "don_1", panicErr.DonationID)
+			require.NotEmpty(t, panicErr.Stack)
+
+			require.Len(t, stateStore.pills, tc.wantPills)
+			if tc.wantPills == 0 {
+				return
+			}
+			require.Equal(t, "don_1", stateStore.pills[0].DonationID)
+			require.Equal(t, "malformed donation", stateStore.pills[0].Panic)
+			require.NotEmpty(t, stateStore.pills[0].Stack)
+		})
+	}
+}
+
+func TestRunRetriesFailedDonations(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	unavailable := &blackbaud.StatusError{StatusCode: http.StatusServiceUnavailable}
+
+	tests := map[string]struct {
+		createGiftErr error
+		schedule      map[string]storage.RetryEntry
+		wantAttempts  int
+		wantCreated   int
+		wantProcessed int
+	}{
+		"schedules transient failure": {
+			createGiftErr: unavailable,
+			wantAttempts:  1,
+			wantProcessed: 1,
+		},
+		"does not schedule permanent failure": {
+			createGiftErr: &blackbaud.StatusError{StatusCode: http.StatusBadRequest},
+			wantProcessed: 1,
+		},
+		"clears due retry that succeeds": {
+			schedule:      map[string]storage.RetryEntry{"don_1": {Attempts: 1, NextAttemptAt: now.Add(-time.Minute)}},
+			wantCreated:   1,
+			wantProcessed: 1,
+		},
+		"backs off due retry that fails again": {
+			createGiftErr: unavailable,
+			schedule:      map[string]storage.RetryEntry{"don_1": {Attempts: 2, NextAttemptAt: now.Add(-time.Minute)}},
+			wantAttempts:  3,
+			wantProcessed: 1,
+		},
+		"gives up after the last attempt": {
+			createGiftErr: unavailable,
+			schedule:      map[string]storage.RetryEntry{"don_1": {Attempts: 4, NextAttemptAt: now.Add(-time.Minute)}},
+			wantProcessed: 1,
+		},
+		"leaves retry that is not due": {
+			schedule:     map[string]storage.RetryEntry{"don_1": {Attempts: 1, NextAttemptAt: now.Add(time.Hour)}},
+			wantAttempts: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Fresh runs find don_1 in the window; retries fetch it by ID from an already synced window.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == "/donations/don_1" {
+					_ = json.NewEncoder(w).Encode(testDonation("don_1"))
+					return
+				}
+				var data []fundraiseup.Donation
+				if tc.schedule == nil {
+					data = append(data, testDonation("don_1"))
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": data, "has_more": false})
+			}))
+			defer server.Close()
+
+			fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			stateStore := &mockRetryStateStore{schedule: tc.schedule}
+			svc, err := New(Config{
+				Blackbaud: &mockBlackbaudClient{
+					constituents:  []blackbaud.Constituent{{ID: "const-123"}},
+					createGiftErr: tc.createGiftErr,
+				},
+				FundraiseUp:  fuClient,
+				GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				StateStore:   stateStore,
+			})
+			require.NoError(t, err)
+
+			result, err := svc.Run(context.Background())
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantProcessed, result.DonationsProcessed)
+			require.Equal(t, tc.wantCreated, result.GiftsCreated)
+			entry, scheduled := stateStore.schedule["don_1"]
+			require.Equal(t, tc.wantAttempts > 0, scheduled)
+			require.Equal(t, tc.wantAttempts, entry.Attempts)
+			if scheduled && tc.wantProcessed > 0 {
+				require.True(t, entry.NextAttemptAt.After(now))
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{retryBaseDelay: 15 * time.Minute}
+
+	tests := map[string]struct {
+		attempts int
+		want     time.Duration
+	}{
+		"first failure":   {attempts: 1, want: 15 * time.Minute},
+		"second failure":  {attempts: 2, want: 30 * time.Minute},
+		"fourth failure":  {attempts: 4, want: 2 * time.Hour},
+		"capped at a day": {attempts: 20, want: 24 * time.Hour},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, svc.retryDelay(tc.attempts))
+		})
+	}
+}
+
+func TestTransient(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"rate limited": {
+			err:  fmt.Errorf("creating gift: %w", &blackbaud.StatusError{StatusCode: http.StatusTooManyRequests}),
+			want: true,
+		},
+		"server error": {
+			err:  &blackbaud.StatusError{StatusCode: http.StatusBadGateway},
+			want: true,
+		},
+		"bad request": {
+			err:  &blackbaud.StatusError{StatusCode: http.StatusBadRequest},
+			want: false,
+		},
+		"timeout": {
+			err:  fmt.Errorf("finding constituent: %w", context.DeadlineExceeded),
+			want: true,
+		},
+		"network": {
+			err:  &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			want: true,
+		},
+		"mapping": {
+			err:  errors.New("parsing donation amount"),
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, transient(tc.err))
+		})
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err  error
+		want string
+	}{
+		"blackbaud status": {
+			err:  fmt.Errorf("creating gift: %w", &blackbaud.StatusError{StatusCode: 429}),
+			want: "blackbaud_429",
+		},
+		"timeout": {
+			err:  fmt.Errorf("fetching: %w", context.DeadlineExceeded),
+			want: "timeout",
+		},
+		"network": {
+			err:  &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			want: "network",
+		},
+		"panic": {
+			err:  &PanicError{DonationID: "don_1", Value: "boom"},
+			want: "panic",
+		},
+		"gift check": {
+			err:  &GiftCheckError{Violations: []string{"gift over 10,000 needs review"}},
+			want: "gift_check",
+		},
+		"gift date": {
+			err:  &GiftDateError{Date: "2035-01-01", Reason: "is in the future"},
+			want: "gift_date",
+		},
+		"other": {
+			err:  errors.New("mapping failed"),
+			want: "other",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, errorCategory(tc.err))
+		})
+	}
+}
+
+func TestRunWithoutPendingStoreProcessesPagesAsFetched(t *testing.T) {
+	t.Parallel()
+
+	bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+
+	// Each page is processed before the next is fetched, so the window is never held in memory at once.
+	var giftsBeforePage2 int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("starting_after") == "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data":     []fundraiseup.Donation{testDonation("don_1"), testDonation("don_2")},
+				"has_more": true,
+			})
+			return
+		}
+		giftsBeforePage2 = len(bbClient.createdGifts)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":     []fundraiseup.Donation{testDonation("don_3")},
+			"has_more": false,
+		})
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	stateStore := &mockSyncTimeStore{lastSync: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	svc, err := New(Config{
+		Blackbaud:    bbClient,
+		FundraiseUp:  fuClient,
+		GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		StateStore:   stateStore,
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 3, result.DonationsProcessed)
+	require.Equal(t, 2, giftsBeforePage2)
+	require.Equal(t, 2, result.Metrics.FundraiseUp.Calls)
+}
+
+func TestRunFetchOverlap(t *testing.T) {
+	t.Parallel()
+
+	lastSync := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	override := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		overlap       time.Duration
+		sinceOverride *time.Time
+		wantSince     time.Time
+	}{
+		"no overlap": {
+			wantSince: lastSync,
+		},
+		"fetches from before the last sync": {
+			overlap:   15 * time.Minute,
+			wantSince: lastSync.Add(-15 * time.Minute),
+		},
+		"override is not overlapped": {
+			overlap:       15 * time.Minute,
+			sinceOverride: &override,
+			wantSince:     override,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Synced by the previous run, and fetched again by the overlap.
+			synced := testDonation("don_1")
+			synced.CreatedAt = lastSync.Add(-5 * time.Minute)
+
+			var since time.Time
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var err error
+				since, err = time.Parse(time.RFC3339, r.URL.Query().Get("created[gte]"))
+				require.NoError(t, err)
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"data":     []fundraiseup.Donation{synced},
+					"has_more": false,
+				})
+			}))
+			t.Cleanup(server.Close)
+
+			fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+			require.NoError(t, err)
+
+			stateStore := &mockStateStore{lastSync: lastSync}
+			svc, err := New(Config{
+				Blackbaud:     &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+				FetchOverlap:  tc.overlap,
+				FundraiseUp:   fuClient,
+				GiftDefaults:  config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+				SinceOverride: tc.sinceOverride,
+				StateStore:    stateStore,
+				Tracker: &mockTracker{records: map[string]storage.DonationRecord{
+					"don_1": {DonationID: "don_1", GiftID: "gift-1"},
+				}},
+			})
+			require.NoError(t, err)
+
+			result, err := svc.Run(context.Background())
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantSince, since)
+			require.Equal(t, 1, result.GiftsSkippedExisting)
+			require.Empty(t, result.Errors)
+			// The donation fetched again does not move the sync time back.
+			require.Equal(t, lastSync, stateStore.lastSync)
+		})
+	}
+}
+
+func TestRunReconcileOnly(t *testing.T) {
+	t.Parallel()
+
+	lastSync := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	tracked := testDonation("don_1")
+	tracked.CreatedAt = now.Add(-24 * time.Hour)
+	missed := testDonation("don_2")
+	missed.CreatedAt = now.Add(-24 * time.Hour)
+	// Too recent to reconcile: the webhook may still be creating its gift.
+	recent := testDonation("don_3")
+	recent.CreatedAt = now.Add(-time.Minute)
+
+	var since time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		since, err = time.Parse(time.RFC3339, r.URL.Query().Get("created[gte]"))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":     []fundraiseup.Donation{tracked, missed, recent},
+			"has_more": false,
+		})
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+	fetchState := &storage.FetchState{Cursor: "don_0", Since: lastSync}
+	stateStore := &mockStateStore{
+		fetchState: fetchState,
+		lastSync:   lastSync,
+		pendingIDs: []string{"don_0"},
+	}
+	svc, err := New(Config{
+		Blackbaud:       bbClient,
+		FundraiseUp:     fuClient,
+		GiftDefaults:    config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		ReconcileOnly:   true,
+		ReconcileWindow: 48 * time.Hour,
+		StateStore:      stateStore,
+		Tracker: &mockTracker{records: map[string]storage.DonationRecord{
+			"don_1": {DonationID: "don_1", GiftID: "gift-1"},
+		}},
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.WithinDuration(t, now.Add(-48*time.Hour), since, time.Minute)
+	require.Equal(t, 2, result.DonationsProcessed)
+	require.Equal(t, 1, result.GiftsSkippedExisting)
+	require.Equal(t, map[string]int{DuplicateTracker: 1}, result.GiftsSkippedExistingBy)
+	require.Len(t, bbClient.createdGifts, 1)
+	require.Equal(t, "don_2", bbClient.createdGifts[0].LookupID)
+
+	// The webhook covers the window, so the pending list, checkpoint and sync time are left alone.
+	require.Equal(t, []string{"don_0"}, stateStore.pendingIDs)
+	require.Same(t, fetchState, stateStore.fetchState)
+	require.Equal(t, lastSync, stateStore.lastSync)
+	require.Zero(t, result.Metrics.StateStore.Calls)
+}
+
+func TestRunDonationIDs(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/donations/")
+		if id == "don_gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(testDonation(id))
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	lastSync := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+	stateStore := &mockStateStore{lastSync: lastSync, pendingIDs: []string{"don_0"}}
+	svc, err := New(Config{
+		Blackbaud:    bbClient,
+		DonationIDs:  []string{"don_1", "don_gone", "don_2"},
+		FundraiseUp:  fuClient,
+		GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		StateStore:   stateStore,
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 2, result.DonationsProcessed)
+	require.Equal(t, 2, result.GiftsCreated)
+	require.Len(t, result.Errors, 1)
+	require.Contains(t, result.Errors[0].Error(), "fetching donation don_gone")
+	require.Empty(t, result.RemainingDonationIDs)
+
+	// Batches run in parallel, so the pending list and sync time are left alone.
+	require.Equal(t, []string{"don_0"}, stateStore.pendingIDs)
+	require.Equal(t, lastSync, stateStore.lastSync)
+	require.Zero(t, result.Metrics.StateStore.Calls)
+}
+
+func TestRunDonationsPausesForQuota(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{
+		blackbaud:    &quotaBlackbaudClient{quota: &blackbaud.Quota{Remaining: 10}},
+		donationIDs:  []string{"don_1", "don_2"},
+		logger:       slog.Default(),
+		quotaReserve: 50,
+	}
+
+	result, err := svc.runDonations(context.Background(), &Result{})
+
+	require.NoError(t, err)
+	require.True(t, result.PausedForQuota)
+	require.Zero(t, result.DonationsProcessed)
+	require.Equal(t, []string{"don_1", "don_2"}, result.RemainingDonationIDs)
+}
+
+func TestRunLogsUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"data": [
+				{"id": "don_1", "amount": "10.00", "currency": "GBP", "fee": "0.30",
+					"supporter": {"id": "sup_1", "email": "ada@example.com"}},
+				{"id": "don_2", "amount": "20.00", "currency": "GBP", "fee": "0.60",
+					"supporter": {"id": "sup_1", "email": "ada@example.com"}}
+			],
+			"has_more": false
+		}`))
+	}))
+	defer server.Close()
+
+	fuClient, err := fundraiseup.NewClient("test-key",
+		fundraiseup.WithBaseURL(server.URL),
+		fundraiseup.WithStrictDecoding())
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	svc, err := New(Config{
+		Blackbaud:    &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+		FundraiseUp:  fuClient,
+		GiftDefaults: config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		Logger:       slog.New(slog.NewTextHandler(&logs, nil)),
+		StateStore:   &mockStateStore{lastSync: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 2, result.DonationsProcessed)
+	require.Empty(t, result.Errors)
+	require.Equal(t, 1, strings.Count(logs.String(), "FundraiseUp sent fields that are not mapped"))
+	require.Contains(t, logs.String(), "donations.data[].fee")
+}
+
+func TestAverageDonationDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		result Result
+		want   time.Duration
+	}{
+		"no donations processed": {
+			result: Result{Metrics: Metrics{ProcessDuration: time.Second}},
+			want:   0,
+		},
+		"divides processing time by donations": {
+			result: Result{DonationsProcessed: 4, Metrics: Metrics{ProcessDuration: 2 * time.Second}},
+			want:   500 * time.Millisecond,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, tc.result.AverageDonationDuration())
+		})
+	}
+}
+
+// testDonation returns a one-off donation from a supporter that matches an existing constituent,
+// created after the start of the tests' sync window.
+func testDonation(id string) fundraiseup.Donation {
+	return fundraiseup.Donation{
+		Amount:    "10.00",
+		CreatedAt: time.Date(2025, time.March, 2, 9, 0, 0, 0, time.UTC),
+		ID:        id,
+		Supporter: &fundraiseup.Supporter{Email: "test@example.com"},
+	}
+}
+
+func TestRunSamplesDonations(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	// Serve 30 donations in pages of 10.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := 0
+		if cursor := r.URL.Query().Get("starting_after"); cursor != "" {
+			_, _ = fmt.Sscanf(cursor, "don_%02d", &start)
+		}
+		var data []fundraiseup.Donation
+		for i := start + 1; i <= start+10; i++ {
+			data = append(data, testDonation(fmt.Sprintf("don_%02d", i)))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data, "has_more": start+10 < 30})
+	}))
+	t.Cleanup(server.Close)
+
+	fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	run := func(t *testing.T, sample int, seed int64) ([]string, *Result, *mockStateStore) {
+		t.Helper()
+
+		bbClient := &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}}
+		stateStore := &mockStateStore{lastSync: since}
+		svc := &Service{
+			blackbaud:          bbClient,
+			constituentCache:   make(map[string]string),
+			dryRun:             true,
+			fundraiseup:        fuClient,
+			giftCache:          lru.New[string, []blackbaud.Gift](0),
+			giftDefaults:       config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:             slog.Default(),
+			maxDonationsPerRun: 10,
+			sample:             sample,
+			sampleSeed:         seed,
+			stateStore:         stateStore,
+		}
+
+		result, err := svc.runFresh(context.Background(), &Result{DryRun: true})
+		require.NoError(t, err)
+
+		var ids []string
+		for _, gift := range bbClient.createdGifts {
+			ids = append(ids, gift.LookupID)
+		}
+		return ids, result, stateStore
+	}
+
+	t.Run("same seed picks same donations", func(t *testing.T) {
+		t.Parallel()
+
+		first, result, stateStore := run(t, 5, 7)
+		second, _, _ := run(t, 5, 7)
+
+		require.Len(t, first, 5)
+		require.Equal(t, first, second)
+		require.IsIncreasing(t, first)
+		require.Equal(t, 5, result.DonationsProcessed)
+		require.Equal(t, 30, result.DonationsSampledFrom)
+		require.Empty(t, stateStore.pendingIDs)
+		require.Nil(t, stateStore.fetchState)
+		require.Equal(t, since, stateStore.lastSync)
+	})
+
+	t.Run("sample larger than window processes every donation", func(t *testing.T) {
+		t.Parallel()
+
+		ids, result, _ := run(t, 50, 1)
+
+		require.Len(t, ids, 30)
+		require.Equal(t, "don_01", ids[0])
+		require.Equal(t, "don_30", ids[29])
+		require.Equal(t, 30, result.DonationsSampledFrom)
+	})
+}
+
+func TestRunInterruptedByCancellation(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("keeps fetched pages pending when cancelled mid-fetch", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data":     []fundraiseup.Donation{testDonation("don_1"), testDonation("don_2")},
+				"has_more": true,
+			})
+		}))
+		defer server.Close()
+
+		fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		// Cancel once the first page is checkpointed, as a signal arriving mid-fetch would.
+		stateStore := &mockStateStore{lastSync: since}
+		svc := &Service{
+			blackbaud:          &mockBlackbaudClient{},
+			fundraiseup:        fuClient,
+			logger:             slog.Default(),
+			maxDonationsPerRun: 10,
+			stateStore:         &cancellingStateStore{mockStateStore: stateStore, cancel: cancel},
+		}
+
+		result, err := svc.runFresh(ctx, &Result{})
+
+		require.ErrorIs(t, err, context.Canceled)
+		require.True(t, result.Interrupted)
+		require.Zero(t, result.DonationsProcessed)
+		require.Equal(t, []string{"don_1", "don_2"}, stateStore.pendingIDs)
+		require.Equal(t, &storage.FetchState{Cursor: "don_2", Since: since}, stateStore.fetchState)
+		require.Equal(t, since, stateStore.lastSync)
+	})
+
+	t.Run("finishes the in-flight donation before stopping", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data":     []fundraiseup.Donation{testDonation("don_1"), testDonation("don_2")},
+				"has_more": false,
+			})
+		}))
+		defer server.Close()
+
+		fuClient, err := fundraiseup.NewClient("test-key", fundraiseup.WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		// Cancel while the first donation's gift is being created, as a signal arriving mid-donation would.
+		bbClient := &cancellingBlackbaudClient{
+			mockBlackbaudClient: &mockBlackbaudClient{constituents: []blackbaud.Constituent{{ID: "const-123"}}},
+			cancel:              cancel,
+		}
+		stateStore := &mockStateStore{lastSync: since}
+		svc := &Service{
+			blackbaud:          bbClient,
+			constituentCache:   make(map[string]string),
+			fundraiseup:        fuClient,
+			giftCache:          lru.New[string, []blackbaud.Gift](0),
+			giftDefaults:       config.GiftDefaults{FundID: "fund-1", Type: "Donation"},
+			logger:             slog.Default(),
+			maxDonationsPerRun: 10,
+			stateStore:         stateStore,
+		}
+
+		result, err := svc.runFresh(ctx, &Result{})
+
+		require.ErrorIs(t, err, context.Canceled)
+		require.True(t, result.Interrupted)
+		require.Equal(t, 1, result.DonationsProcessed)
+		require.Equal(t, 1, result.GiftsCreated)
+		require.Empty(t, result.Errors)
+		require.NoError(t, bbClient.giftCtxErr)
+		require.Equal(t, []string{"don_2"}, stateStore.pendingIDs)
+		require.Equal(t, since, stateStore.lastSync)
+	})
+
+	t.Run("leaves pending donations when cancelled before resuming", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		stateStore := &mockStateStore{lastSync: since, pendingIDs: []string{"don_1", "don_2"}}
+		svc := &Service{
+			blackbaud:  &mockBlackbaudClient{},
+			logger:     slog.Default(),
+			stateStore: stateStore,
+		}
+
+		result, err := svc.runResume(ctx, &Result{}, stateStore.pendingIDs)
+
+		require.ErrorIs(t, err, context.Canceled)
+		require.True(t, result.Interrupted)
+		require.Zero(t, result.DonationsProcessed)
+		require.Equal(t, []string{"don_1", "don_2"}, stateStore.pendingIDs)
+		require.Equal(t, since, stateStore.lastSync)
+	})
+}
+
+// cancellingStateStore cancels the run's context after storing a fetch checkpoint.
+type cancellingStateStore struct {
+	*mockStateStore
+	cancel context.CancelFunc
+}
+
+// SetFetchState stores the checkpoint, then cancels.
+func (c *cancellingStateStore) SetFetchState(ctx context.Context, state *storage.FetchState) error {
+	err := c.mockStateStore.SetFetchState(ctx, state)
+	c.cancel()
+	return err
+}
+
+// cancellingBlackbaudClient cancels the run's context while creating a gift.
+type cancellingBlackbaudClient struct {
+	*mockBlackbaudClient
+	cancel     context.CancelFunc
+	giftCtxErr error
+}
+
+// CreateGift cancels, then records whether the gift's own context was cancelled with it.
+func (c *cancellingBlackbaudClient) CreateGift(ctx context.Context, gift *blackbaud.Gift) (string, error) {
+	c.cancel()
+	c.giftCtxErr = ctx.Err()
+	return c.mockBlackbaudClient.CreateGift(ctx, gift)
+}
+
+func TestDryRunClientIDs(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		create func(ctx context.Context, d *dryRunClient) (string, error)
+		want   string
+	}{
+		"gift named by its lookup ID": {
+			create: func(ctx context.Context, d *dryRunClient) (string, error) {
+				return d.CreateGift(ctx, &blackbaud.Gift{ConstituentID: "const-1", LookupID: "don_123"})
+			},
+			want: "dry-run-gift-don_123",
+		},
+		"gift without a lookup ID": {
+			create: func(ctx context.Context, d *dryRunClient) (string, error) {
+				return d.CreateGift(ctx, &blackbaud.Gift{ConstituentID: "const-1", Origin: `{"donation_id":"don_123"}`})
+			},
+			want: dryRunID("gift", `{"donation_id":"don_123"}`, "const-1", ""),
+		},
+		"constituent by email, ignoring case": {
+			create: func(ctx context.Context, d *dryRunClient) (string, error) {
+				return d.CreateConstituent(ctx, &blackbaud.Constituent{
+					Email:     &blackbaud.Email{Address: "Jane@Example.org"},
+					FirstName: "Jane",
+					LastName:  "Doe",
+				})
+			},
+			want: dryRunID("constituent", "jane@example.org", "Jane", "Doe"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// A second client stands in for a second dry run, which must return the same ID.
+			for range 2 {
+				id, err := tc.create(context.Background(), newDryRunClient(&mockBlackbaudClient{}, slog.Default()))
+				require.NoError(t, err)
+				require.Equal(t, tc.want, id)
+			}
+		})
+	}
+
+	require.Regexp(t, `^dry-run-constituent-[0-9a-f]{12}$`, dryRunID("constituent", "jane@example.org", "Jane", "Doe"))
+	require.NotEqual(t, dryRunID("gift-note", "gift-1", "Comment"), dryRunID("gift-note", "gift-1Comment"))
 }
@@ -0,0 +1,131 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/config"
+)
+
+// defaultCurrencyDecimals is the number of decimal places in the minor unit of most currencies.
+const defaultCurrencyDecimals = 2
+
+// currencyDecimals lists the currencies whose minor unit is not a hundredth, by ISO 4217 code.
+var currencyDecimals = map[string]int{
+	"BHD": 3,
+	"BIF": 0,
+	"CLP": 0,
+	"DJF": 0,
+	"GNF": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KMF": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"PYG": 0,
+	"RWF": 0,
+	"TND": 3,
+	"UGX": 0,
+	"VND": 0,
+	"VUV": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"XPF": 0,
+}
+
+// checkGiftSplits reports splits that name no fund, that do not set exactly one of an amount or a percentage,
+// or whose percentages leave nothing for the remainder fund.
+func checkGiftSplits(splits []config.GiftSplit) error {
+	var errs []error
+	total := 0.0
+	for i, split := range splits {
+		if split.FundID == "" {
+			errs = append(errs, fmt.Errorf("gift split %d: fund ID is required", i+1))
+		}
+		switch {
+		case split.Amount == 0 && split.Percent == 0:
+			errs = append(errs, fmt.Errorf("gift split %d: amount or percent is required", i+1))
+		case split.Amount != 0 && split.Percent != 0:
+			errs = append(errs, fmt.Errorf("gift split %d: amount and percent cannot both be set", i+1))
+		case split.Amount < 0:
+			errs = append(errs, fmt.Errorf("gift split %d: amount must be positive", i+1))
+		case split.Percent < 0 || split.Percent >= 100:
+			errs = append(errs, fmt.Errorf("gift split %d: percent must be between 0 and 100", i+1))
+		}
+		total += split.Percent
+	}
+	if total >= 100 {
+		errs = append(errs, errors.New("gift split percentages must total less than 100"))
+	}
+	return errors.Join(errs...)
+}
+
+// minorUnitScale returns how many minor units make up one unit of the currency, such as 100 pence to the pound.
+func minorUnitScale(currency string) float64 {
+	decimals, ok := currencyDecimals[strings.ToUpper(currency)]
+	if !ok {
+		decimals = defaultCurrencyDecimals
+	}
+	return math.Pow10(decimals)
+}
+
+// splitGift divides a gift between the configured splits and the remainder fund, the fund of the gift's single
+// split before splitting. Splits are taken in order: a fixed amount is taken from what is left of the gift, and
+// a percentage of the whole gift, rounded down, up to what is left. Amounts are worked out in the currency's
+// minor unit, and the remainder fund receives what is left in the first split, so the splits always add up to
+// the gift amount. Splits that come to nothing are left out, as is the remainder fund's when nothing is left.
+// Each split has its own amount, so changing one does not change the gift or another split.
+func (s *Service) splitGift(gift *blackbaud.Gift, currency string) error {
+	if len(s.giftDefaults.Splits) == 0 {
+		return nil
+	}
+
+	scale := minorUnitScale(currency)
+	total := int64(math.Round(gift.Amount.Value * scale))
+	remainder := gift.GiftSplits[0]
+
+	var splits []blackbaud.GiftSplit
+	left := total
+	for _, split := range s.giftDefaults.Splits {
+		share := int64(math.Round(split.Amount * scale))
+		if split.Percent > 0 {
+			share = int64(math.Floor(float64(total) * split.Percent / 100))
+		}
+		share = min(share, left)
+		if share <= 0 {
+			continue
+		}
+		left -= share
+		splits = append(splits, blackbaud.GiftSplit{
+			Amount:     &blackbaud.GiftAmount{Value: float64(share) / scale},
+			AppealID:   remainder.AppealID,
+			CampaignID: remainder.CampaignID,
+			FundID:     split.FundID,
+		})
+	}
+	if left > 0 || len(splits) == 0 {
+		remainder.Amount = &blackbaud.GiftAmount{Value: float64(left) / scale}
+		splits = append([]blackbaud.GiftSplit{remainder}, splits...)
+	}
+
+	// Guard against a split amount not surviving the conversion back from minor units.
+	var sum int64
+	for _, split := range splits {
+		sum += int64(math.Round(split.Amount.Value * scale))
+	}
+	if sum != total {
+		return fmt.Errorf("gift splits total %v, not the gift amount %v", float64(sum)/scale, gift.Amount.Value)
+	}
+
+	// Keep the gift amount equal to its splits when it was given more precisely than the minor unit.
+	gift.Amount.Value = float64(total) / scale
+	gift.GiftSplits = splits
+	return nil
+}
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+)
+
+// cachedSupporter returns the constituent the tracker remembers a normalized email matching in an earlier run,
+// or an empty ID if none is remembered or the supporter cache is disabled. A failed lookup is logged and treated
+// as a miss, so the constituent is searched for instead. A constituent found missing from Blackbaud this run is
+// also a miss, in case it could not be forgotten.
+func (s *Service) cachedSupporter(ctx context.Context, email string) string {
+	if s.supporterCacheTTL <= 0 {
+		return ""
+	}
+	cache, ok := s.tracker.(SupporterCache)
+	if !ok {
+		return ""
+	}
+
+	constituentID, err := cache.CachedConstituent(ctx, email)
+	if err != nil {
+		s.logger.Warn("failed to look up cached constituent", "error", err)
+		return ""
+	}
+	if constituentID == "" || s.staleConstituents[constituentID] {
+		return ""
+	}
+
+	if s.rememberedConstituents == nil {
+		s.rememberedConstituents = make(map[string]string)
+	}
+	s.rememberedConstituents[constituentID] = email
+	return constituentID
+}
+
+// cacheSupporter has the tracker remember the constituent a supporter's normalized email matched for the supporter
+// cache TTL. Nothing is remembered in dry-run mode, where new constituents have fake IDs. A failed write is logged,
+// since the constituent is only searched for again on the next run.
+func (s *Service) cacheSupporter(ctx context.Context, email string, supporterID string, constituentID string) {
+	if s.supporterCacheTTL <= 0 || s.dryRun {
+		return
+	}
+	cache, ok := s.tracker.(SupporterCache)
+	if !ok {
+		return
+	}
+
+	expiresAt := time.Now().Add(s.supporterCacheTTL)
+	if err := cache.CacheConstituent(ctx, email, supporterID, constituentID, expiresAt); err != nil {
+		s.logger.Warn("failed to cache constituent", "constituent_id", constituentID, "error", err)
+	}
+}
+
+// dropStaleSupporter forgets a constituent remembered from an earlier run once Blackbaud reports it does not exist,
+// as after it was merged or deleted in Raiser's Edge NXT, and reports whether it did, so the supporter can be
+// searched for again. Errors other than not found, and constituents not remembered from an earlier run, are left
+// alone. Nothing is deleted in dry-run mode, and a failed delete is logged, as the constituent is no longer used
+// this run either way.
+func (s *Service) dropStaleSupporter(ctx context.Context, constituentID string, err error) bool {
+	if !blackbaud.IsNotFound(err) {
+		return false
+	}
+	email, ok := s.rememberedConstituents[constituentID]
+	if !ok {
+		return false
+	}
+
+	s.logger.Warn("cached constituent no longer exists, searching again", "constituent_id", constituentID)
+	delete(s.rememberedConstituents, constituentID)
+	delete(s.constituentCache, email)
+	if s.staleConstituents == nil {
+		s.staleConstituents = make(map[string]bool)
+	}
+	s.staleConstituents[constituentID] = true
+
+	if cache, ok := s.tracker.(SupporterCache); ok && !s.dryRun {
+		if err := cache.ForgetConstituent(ctx, email); err != nil {
+			s.logger.Warn("failed to forget cached constituent", "constituent_id", constituentID, "error", err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package sync
+
+import (
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// skipTestDonation reports whether a donation is left out of Raiser's Edge NXT because it was made in
+// FundraiseUp's test mode, which it is unless test donations are synced.
+func (s *Service) skipTestDonation(donation fundraiseup.Donation) bool {
+	return donation.IsTest() && s.giftDefaults.TestDonations != config.TestDonationsSync
+}
+
+// routeTestDonation records the gift for a synced test-mode donation in the test fund, when one is set,
+// in place of the funds its splits were given, so test gifts never reach a real fund.
+// Gifts for live donations are left unchanged.
+func (s *Service) routeTestDonation(donation fundraiseup.Donation, gift *blackbaud.Gift) {
+	if !donation.IsTest() || s.giftDefaults.TestFundID == "" || len(gift.GiftSplits) == 0 {
+		return
+	}
+
+	split := gift.GiftSplits[0]
+	split.Amount = &blackbaud.GiftAmount{Value: gift.Amount.Value}
+	split.FundID = s.giftDefaults.TestFundID
+	gift.GiftSplits = []blackbaud.GiftSplit{split}
+}
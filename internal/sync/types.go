@@ -4,67 +4,233 @@ package sync
 import (
 	"context"
 	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+const (
+	// DuplicateLookupID is a gift found in Raiser's Edge NXT by a lookup ID matching the donation.
+	DuplicateLookupID = "lookup_id"
+
+	// DuplicateOrigin is a gift found in Raiser's Edge NXT by an origin naming the donation.
+	DuplicateOrigin = "origin"
+
+	// DuplicateTracker is a donation the donation tracker already holds a gift for.
+	DuplicateTracker = "tracker"
 )
 
+// DonationTracker records the Blackbaud gift created for each donation.
+type DonationTracker interface {
+	// Lookup returns the record for a donation, or nil if the donation has not been tracked.
+	Lookup(ctx context.Context, donationID string) (*storage.DonationRecord, error)
+
+	// Track records the gift created for a one-off donation.
+	// Implementations should return a *storage.AlreadyTrackedError rather than replace a different gift.
+	Track(ctx context.Context, record storage.DonationRecord) error
+
+	// TrackRecurring records the gift created for a recurring donation payment.
+	// Implementations should return a *storage.AlreadyTrackedError rather than replace a different gift.
+	TrackRecurring(ctx context.Context, record storage.DonationRecord) error
+}
+
+// BatchTracker is implemented by donation trackers that can record several gifts in one call.
+// Tracked gifts are then buffered and written in batches, so a crash can lose the last few records;
+// their gifts exist in Blackbaud, where later runs find them.
+type BatchTracker interface {
+	// TrackBatch records the gifts created for several donations, one-off and recurring.
+	// Implementations should report donations already tracked with a different gift in a *storage.BatchTrackError.
+	TrackBatch(ctx context.Context, records []storage.DonationRecord) error
+}
+
+// GiftReplacer is implemented by donation trackers that can replace a donation's gift,
+// which the recreate deleted gift policy requires.
+type GiftReplacer interface {
+	// ReplaceGift records a new gift for a tracked donation, provided it is still tracked with previousGiftID.
+	// Otherwise it returns a *storage.AlreadyTrackedError.
+	ReplaceGift(ctx context.Context, record storage.DonationRecord, previousGiftID string) error
+}
+
+// RecurringHistory is implemented by donation trackers that can list the donations tracked for a recurring plan,
+// which plan change notes require.
+type RecurringHistory interface {
+	// RecurringDonations returns all tracked donations for a recurring plan.
+	RecurringDonations(ctx context.Context, recurringID string) ([]storage.DonationRecord, error)
+}
+
+// SupporterCache is implemented by donation trackers that can remember across runs which constituent a supporter's
+// email matched, which Config.SupporterCacheTTL requires.
+type SupporterCache interface {
+	// CachedConstituent returns the constituent ID remembered for a normalized email, or an empty ID if none is.
+	CachedConstituent(ctx context.Context, email string) (string, error)
+
+	// CacheConstituent remembers the constituent ID matched for a supporter's normalized email until expiresAt.
+	CacheConstituent(
+		ctx context.Context,
+		email string,
+		supporterID string,
+		constituentID string,
+		expiresAt time.Time,
+	) error
+
+	// ForgetConstituent removes the constituent ID remembered for a normalized email.
+	ForgetConstituent(ctx context.Context, email string) error
+}
+
+// CampaignCounts counts the donations of one FundraiseUp campaign processed in a run.
+type CampaignCounts struct {
+	// Donations is the number of the campaign's donations processed, including those skipped.
+	Donations int `json:"donations"`
+
+	// GiftsCreated is the number of gifts created for the campaign's donations.
+	GiftsCreated int `json:"giftsCreated"`
+
+	// SkippedOutsideWindow is the number of the campaign's donations skipped because the campaign is excluded
+	// or they were made outside its window.
+	SkippedOutsideWindow int `json:"skippedOutsideWindow"`
+}
+
 // DonationResult contains the outcome of processing a single donation.
 type DonationResult struct {
 	// ConstituentCreated indicates if a new constituent was created.
-	ConstituentCreated bool
+	ConstituentCreated bool `json:"constituentCreated,omitempty"`
 
 	// DonationID is the FundraiseUp donation identifier.
-	DonationID string
+	DonationID string `json:"donationId"`
+
+	// DuplicateFoundBy is how the existing gift was found when GiftSkippedExisting is set,
+	// one of DuplicateLookupID, DuplicateOrigin or DuplicateTracker.
+	DuplicateFoundBy string `json:"duplicateFoundBy,omitempty"`
 
 	// Error contains any error that occurred during processing.
-	Error error
+	Error error `json:"error,omitempty"`
+
+	// Excluded indicates the donation is no longer synced because its gift was deleted in Blackbaud.
+	Excluded bool `json:"excluded,omitempty"`
 
 	// GiftCreated indicates if a new gift was created.
-	GiftCreated bool
+	GiftCreated bool `json:"giftCreated,omitempty"`
+
+	// GiftDeleted indicates the tracked gift for the donation was found deleted in Blackbaud.
+	GiftDeleted bool `json:"giftDeleted,omitempty"`
 
 	// GiftID is the Blackbaud gift identifier.
-	GiftID string
+	GiftID string `json:"giftId,omitempty"`
 
 	// GiftSkippedExisting indicates the gift already existed in Blackbaud.
-	GiftSkippedExisting bool
+	GiftSkippedExisting bool `json:"giftSkippedExisting,omitempty"`
 
 	// GiftUpdated indicates if an existing gift was updated.
-	GiftUpdated bool
+	GiftUpdated bool `json:"giftUpdated,omitempty"`
+
+	// SkippedCampaign indicates the donation's campaign is excluded, or the donation was made outside its
+	// campaign's window, so no gift was created.
+	SkippedCampaign bool `json:"skippedCampaign,omitempty"`
+
+	// SkippedTest indicates the donation was made in FundraiseUp's test mode, so no gift was created.
+	SkippedTest bool `json:"skippedTest,omitempty"`
+
+	// Warnings contains problems that did not stop the donation being processed,
+	// such as address values that could not be mapped to Raiser's Edge NXT.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // Result contains the outcome of a sync operation.
 type Result struct {
+	// BlackbaudQuota is the SKY API call quota remaining at the end of the sync, if the API reported one.
+	BlackbaudQuota *blackbaud.Quota `json:"blackbaudQuota,omitempty"`
+
+	// Campaigns counts the donations processed by FundraiseUp campaign ID, with donations made outside any campaign
+	// under "". Only counted when Config.CampaignWindows is set.
+	Campaigns map[string]CampaignCounts `json:"campaigns,omitempty"`
+
 	// ConstituentsCreated is the number of new constituents created.
-	ConstituentsCreated int
+	ConstituentsCreated int `json:"constituentsCreated,omitempty"`
 
 	// ConstituentsExisting is the number of constituents that already existed.
-	ConstituentsExisting int
+	ConstituentsExisting int `json:"constituentsExisting,omitempty"`
+
+	// DonationsExcluded is the number of donations skipped because their gift was deleted in Blackbaud
+	// under the exclude policy, including those excluded on earlier runs.
+	DonationsExcluded int `json:"donationsExcluded,omitempty"`
 
 	// DonationsProcessed is the total number of donations processed.
-	DonationsProcessed int
+	DonationsProcessed int `json:"donationsProcessed"`
+
+	// DonationsSampledFrom is the number of donations in the window a sample was drawn from,
+	// or zero when every donation was processed.
+	DonationsSampledFrom int `json:"donationsSampledFrom,omitempty"`
+
+	// DonationsSkippedCampaign is the number of donations skipped because their campaign is excluded, or they were
+	// made outside its window.
+	DonationsSkippedCampaign int `json:"donationsSkippedCampaign,omitempty"`
+
+	// DonationsSkippedTest is the number of donations made in FundraiseUp's test mode that were skipped.
+	DonationsSkippedTest int `json:"donationsSkippedTest,omitempty"`
+
+	// Discrepancies lists fields of verified gifts stored differently from what was sent.
+	Discrepancies []GiftDiscrepancy `json:"discrepancies,omitempty"`
 
 	// DryRun indicates this was a dry-run (no writes to Blackbaud).
-	DryRun bool
+	DryRun bool `json:"dryRun,omitempty"`
 
 	// Errors contains any errors that occurred during the sync.
-	Errors []error
+	Errors []error `json:"errors,omitempty"`
+
+	// GiftsChargedBack is the number of tracked gifts given the chargeback status because their donation was
+	// disputed or charged back.
+	GiftsChargedBack int `json:"giftsChargedBack,omitempty"`
 
 	// GiftsCreated is the number of new gifts created.
-	GiftsCreated int
+	GiftsCreated int `json:"giftsCreated,omitempty"`
+
+	// GiftsDeleted is the number of tracked gifts found deleted in Blackbaud.
+	GiftsDeleted int `json:"giftsDeleted,omitempty"`
 
 	// GiftsSkippedExisting is the number of gifts skipped because they already existed.
-	GiftsSkippedExisting int
+	GiftsSkippedExisting int `json:"giftsSkippedExisting,omitempty"`
+
+	// GiftsSkippedExistingBy counts the gifts skipped because they already existed by how they were found,
+	// keyed by DuplicateLookupID, DuplicateOrigin or DuplicateTracker, to show which duplicate checks are working.
+	GiftsSkippedExistingBy map[string]int `json:"giftsSkippedExistingBy,omitempty"`
 
 	// GiftsUpdated is the number of existing gifts updated.
-	GiftsUpdated int
-}
+	GiftsUpdated int `json:"giftsUpdated,omitempty"`
 
-// StateStore manages persistent state for the sync process.
-type StateStore interface {
-	// LastSyncTime returns the timestamp of the last successful sync.
-	LastSyncTime(ctx context.Context) (time.Time, error)
+	// GiftsVerified is the number of created gifts read back from Blackbaud for verification.
+	GiftsVerified int `json:"giftsVerified,omitempty"`
 
-	// SetLastSyncTime updates the last sync timestamp.
-	SetLastSyncTime(ctx context.Context, t time.Time) error
+	// Interrupted indicates processing stopped early because the run was cancelled or timed out.
+	// Unprocessed donations are resumed on the next run.
+	Interrupted bool `json:"interrupted,omitempty"`
 
+	// Metrics breaks down where the run spent its time and how many API calls it made.
+	Metrics Metrics `json:"metrics,omitzero"`
+
+	// PausedForQuota indicates processing stopped early because the remaining Blackbaud call quota
+	// fell below the reserve. Unprocessed donations are resumed on the next run.
+	PausedForQuota bool `json:"pausedForQuota,omitempty"`
+
+	// RemainingDonationIDs lists the donations of Config.DonationIDs not processed because the run was interrupted,
+	// paused for quota or stopped on error, in order. Empty when every listed donation was processed.
+	RemainingDonationIDs []string `json:"remainingDonationIds,omitempty"`
+
+	// RunID identifies the run as the UTC time it started, in RFC 3339 format. Gifts created in the run record it in
+	// GiftDefaults.CreatorCustomField, when set.
+	RunID string `json:"runId,omitempty"`
+
+	// StoppedOnError indicates processing stopped at the first donation that failed, because Config.FailFast is set.
+	// The failed and unprocessed donations are resumed on the next run.
+	StoppedOnError bool `json:"stoppedOnError,omitempty"`
+
+	// Warnings contains problems that did not stop donations being processed, prefixed with the donation ID.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PendingStore persists progress through a donations window, so an interrupted or limited run is resumed.
+// State stores that also implement PendingStore have their runs resumed; those that do not fetch the whole window
+// since the last sync each run, and rely on duplicate detection after an interruption.
+type PendingStore interface {
 	// PendingDonationIDs returns the list of donation IDs still to be processed.
 	// Returns empty slice if no pending work exists.
 	PendingDonationIDs(ctx context.Context) ([]string, error)
@@ -74,4 +240,55 @@ type StateStore interface {
 
 	// RemovePendingDonationID removes a single ID from the pending list after processing.
 	RemovePendingDonationID(ctx context.Context, id string) error
+
+	// FetchState returns the checkpoint of an unfinished fetch, or nil if no fetch is in progress.
+	FetchState(ctx context.Context) (*storage.FetchState, error)
+
+	// SetFetchState stores the checkpoint of an unfinished fetch. A nil state clears it.
+	SetFetchState(ctx context.Context, state *storage.FetchState) error
+}
+
+// GiftReviewQueue keeps the donations held back because their gifts failed the gift checks. State stores that
+// also implement GiftReviewQueue have each such donation held for review, so it can be listed and released to be
+// tried again once the checks or the donation are fixed; with other stores, it is only reported.
+type GiftReviewQueue interface {
+	// HoldForReview holds a donation whose gift failed the gift checks.
+	HoldForReview(ctx context.Context, review storage.GiftReview) error
+}
+
+// PoisonPillRecorder keeps the donations whose processing panicked. State stores that also implement
+// PoisonPillRecorder have each such donation recorded, so it can be found and fixed without the logs.
+type PoisonPillRecorder interface {
+	// RecordPoisonPill records a donation whose processing panicked.
+	RecordPoisonPill(ctx context.Context, pill storage.PoisonPill) error
+}
+
+// RetryStore keeps the schedule of donations to try again after failing with an error expected to clear.
+// State stores that also implement RetryStore have such donations retried on later runs, with backoff;
+// with other stores, a failed donation is only reported.
+type RetryStore interface {
+	// RetrySchedule returns the donations scheduled to be tried again, by donation ID.
+	RetrySchedule(ctx context.Context) (map[string]storage.RetryEntry, error)
+
+	// SetRetrySchedule stores the donations scheduled to be tried again, replacing the previous schedule.
+	SetRetrySchedule(ctx context.Context, schedule map[string]storage.RetryEntry) error
+}
+
+// RunRecorder keeps a history of run summaries. State stores that also implement RunRecorder
+// have each run recorded, so its outcome can be checked without access to the logs.
+type RunRecorder interface {
+	// RecordRun adds a run's summary to the history.
+	RecordRun(ctx context.Context, summary storage.RunSummary) error
+}
+
+// StateStore manages persistent state for the sync process.
+// Implement PendingStore as well to resume interrupted runs, RetryStore to retry failed donations,
+// RunRecorder to keep a run history, PoisonPillRecorder to keep donations whose processing panicked, and
+// GiftReviewQueue to hold donations that failed the gift checks.
+type StateStore interface {
+	// LastSyncTime returns the timestamp of the last successful sync.
+	LastSyncTime(ctx context.Context) (time.Time, error)
+
+	// SetLastSyncTime updates the last sync timestamp.
+	SetLastSyncTime(ctx context.Context, t time.Time) error
 }
@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// applyDonationEvents applies donation changes made in FundraiseUp since the given time to the gifts tracked for
+// their donations: edited comments when comment updates are enabled, and disputes and chargebacks when a chargeback
+// status is set. Only donations with a tracked gift are updated, and only when the gift differs from the change.
+// Failures are recorded in the result without failing the sync, since the gifts themselves exist.
+// Events left when the Blackbaud quota runs low are not revisited, since the next run starts from its own sync time.
+func (s *Service) applyDonationEvents(ctx context.Context, result *Result, since time.Time) {
+	if (!s.updateComments && s.chargebackStatus == "") || since.IsZero() {
+		return
+	}
+
+	s.logger.Info("applying donation events", "since", since)
+
+	updated := 0
+	chargedBack := 0
+	var processDuration time.Duration
+	fetchStart := time.Now()
+	err := s.fundraiseup.EventPages(ctx, since, "", func(page []fundraiseup.Event) error {
+		s.metrics.FundraiseUp.Calls++
+		defer func(start time.Time) { processDuration += time.Since(start) }(time.Now())
+
+		for _, event := range page {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if event.Type != fundraiseup.EventDonationUpdated || event.Data.Donation == nil {
+				continue
+			}
+			if s.quotaLow(result) {
+				return fundraiseup.ErrStop
+			}
+
+			donation := *event.Data.Donation
+			if s.chargebackStatus != "" && donation.IsChargedBack() {
+				changed, warnings, err := s.applyChargeback(ctx, donation, event.CreatedAt)
+				for _, warning := range warnings {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("donation %s: %s", donation.ID, warning))
+				}
+				if err != nil {
+					result.Errors = append(result.Errors, err)
+					s.logger.Error("failed to apply chargeback", "donation_id", donation.ID, "error", err)
+					continue
+				}
+				if changed {
+					chargedBack++
+					result.GiftsChargedBack++
+				}
+				continue
+			}
+			if !s.updateComments {
+				continue
+			}
+
+			changed, warnings, err := s.applyEditedComment(ctx, donation)
+			for _, warning := range warnings {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("donation %s: %s", donation.ID, warning))
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, err)
+				s.logger.Error("failed to apply edited comment",
+					"donation_id", event.Data.Donation.ID,
+					"error", err)
+				continue
+			}
+			if changed {
+				updated++
+				result.GiftsUpdated++
+			}
+		}
+		return nil
+	})
+	fetchDuration := time.Since(fetchStart) - processDuration
+	s.metrics.FetchDuration += fetchDuration
+	s.metrics.FundraiseUp.Duration += fetchDuration
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("fetching donation events: %w", err))
+		s.logger.Error("failed to fetch donation events", "error", err)
+	}
+
+	s.logger.Info("applied donation events", "comments_updated", updated, "gifts_charged_back", chargedBack)
+}
+
+// eventsSince returns when to read donation events from: the override sync time when set,
+// otherwise the sync time stored before this run, which is zero before the first sync.
+func (s *Service) eventsSince(ctx context.Context) (time.Time, error) {
+	if s.sinceOverride != nil {
+		return *s.sinceOverride, nil
+	}
+	return s.previousSyncTime(ctx)
+}
+
+// applyEditedComment sets the reference of the gift tracked for a donation to its current comment, adding a note with
+// the full comment when it is too long for the reference. It returns true when the gift was updated, and false when
+// the donation has no tracked gift, the comment was removed, or the gift already holds it. A note that cannot be
+// added is returned as a warning.
+func (s *Service) applyEditedComment(ctx context.Context, donation fundraiseup.Donation) (bool, []string, error) {
+	record, err := s.lookupTracked(ctx, donation.ID)
+	if err != nil {
+		return false, nil, fmt.Errorf("looking up tracked donation %s: %w", donation.ID, err)
+	}
+	if record == nil || record.GiftID == "" {
+		return false, nil, nil
+	}
+
+	donation.Comment = s.commentScrubber.Scrub(donation.Comment)
+	mapped, err := s.mapDonationToGift(donation, recurringContext{})
+	if err != nil {
+		return false, nil, fmt.Errorf("mapping donation %s to gift: %w", donation.ID, err)
+	}
+	// Updates are partial, so an empty reference would leave the old comment in place rather than clear it.
+	if mapped.Reference == "" {
+		return false, nil, nil
+	}
+	fullReference := truncateReference(mapped)
+
+	reader, ok := s.blackbaud.(GiftReader)
+	if !ok {
+		return false, nil, nil
+	}
+	current, err := reader.Gift(ctx, record.GiftID)
+	if err != nil {
+		return false, nil, fmt.Errorf("reading gift %s: %w", record.GiftID, err)
+	}
+	if current.Reference == mapped.Reference {
+		return false, nil, nil
+	}
+
+	if err := s.blackbaud.UpdateGift(ctx, record.GiftID, &blackbaud.Gift{Reference: mapped.Reference}); err != nil {
+		return false, nil, fmt.Errorf("updating gift %s: %w", record.GiftID, err)
+	}
+
+	s.logger.Info("updated gift with edited comment",
+		"donation_id", donation.ID,
+		"gift_id", record.GiftID)
+	return true, s.recordReferenceNote(ctx, record.GiftID, donation, fullReference), nil
+}
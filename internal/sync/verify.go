@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+)
+
+// GiftDiscrepancy is a gift field whose value in Raiser's Edge NXT differs from the value GiftBridge sent,
+// for example because the server rounded or defaulted it.
+type GiftDiscrepancy struct {
+	// DonationID is the FundraiseUp donation the gift was created for.
+	DonationID string `json:"donationId"`
+
+	// Field names the differing field, such as "amount" or "gift_splits[0].fund_id".
+	Field string `json:"field"`
+
+	// GiftID is the Blackbaud gift identifier.
+	GiftID string `json:"giftId"`
+
+	// Got is the value stored in Raiser's Edge NXT.
+	Got string `json:"got"`
+
+	// Want is the value GiftBridge sent.
+	Want string `json:"want"`
+}
+
+// createdGift is a gift created during a run, kept so it can be verified afterwards.
+type createdGift struct {
+	// donationID is the FundraiseUp donation the gift was created for.
+	donationID string
+
+	// gift is the payload sent to Blackbaud.
+	gift blackbaud.Gift
+
+	// giftID is the ID Blackbaud returned.
+	giftID string
+}
+
+// recordCreatedGift keeps a copy of a created gift for verification, when verification is enabled.
+func (s *Service) recordCreatedGift(donationID string, giftID string, gift *blackbaud.Gift) {
+	if !s.verify {
+		return
+	}
+	s.createdGifts = append(s.createdGifts, createdGift{donationID: donationID, gift: *gift, giftID: giftID})
+}
+
+// verifyGifts re-reads each gift created during the run and records fields that differ from what was sent.
+// Gifts that cannot be read are reported as warnings.
+func (s *Service) verifyGifts(ctx context.Context, result *Result) {
+	reader, ok := s.blackbaud.(GiftReader)
+	if !ok || len(s.createdGifts) == 0 {
+		return
+	}
+
+	s.logger.Info("verifying created gifts", "count", len(s.createdGifts))
+
+	for _, created := range s.createdGifts {
+		if ctx.Err() != nil {
+			return
+		}
+
+		stored, err := reader.Gift(ctx, created.giftID)
+		if err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("donation %s: verifying gift %s: %v", created.donationID, created.giftID, err))
+			continue
+		}
+		result.GiftsVerified++
+
+		for _, diff := range compareGifts(&created.gift, stored) {
+			diff.DonationID = created.donationID
+			diff.GiftID = created.giftID
+			result.Discrepancies = append(result.Discrepancies, diff)
+			s.logger.Warn("gift stored differently from what was sent",
+				"donation_id", diff.DonationID,
+				"gift_id", diff.GiftID,
+				"field", diff.Field,
+				"want", diff.Want,
+				"got", diff.Got)
+		}
+	}
+}
+
+// compareGifts returns the fields set in sent whose values differ in stored.
+// Fields left empty in sent are left to Raiser's Edge NXT, so are not compared.
+func compareGifts(sent *blackbaud.Gift, stored *blackbaud.Gift) []GiftDiscrepancy {
+	var diffs []GiftDiscrepancy
+	check := func(field string, want string, got string) {
+		if want != "" && want != got {
+			diffs = append(diffs, GiftDiscrepancy{Field: field, Got: got, Want: want})
+		}
+	}
+
+	check("amount", formatAmount(sent.Amount), formatAmount(stored.Amount))
+	check("constituent_id", sent.ConstituentID, stored.ConstituentID)
+	check("date", datePart(sent.Date), datePart(stored.Date))
+	check("lookup_id", sent.LookupID, stored.LookupID)
+	check("post_date", datePart(sent.PostDate), datePart(stored.PostDate))
+	check("post_status", string(sent.PostStatus), string(stored.PostStatus))
+	check("reference", sent.Reference, stored.Reference)
+	check("subtype", string(sent.Subtype), string(stored.Subtype))
+	check("type", string(sent.Type), string(stored.Type))
+	if sent.GiftAidEligible {
+		check("is_gift_aid_eligible", "true", strconv.FormatBool(stored.GiftAidEligible))
+	}
+
+	if len(sent.GiftSplits) != len(stored.GiftSplits) {
+		check("gift_splits", strconv.Itoa(len(sent.GiftSplits)), strconv.Itoa(len(stored.GiftSplits)))
+		return diffs
+	}
+	for i, split := range sent.GiftSplits {
+		got := stored.GiftSplits[i]
+		prefix := fmt.Sprintf("gift_splits[%d].", i)
+		check(prefix+"amount", formatAmount(split.Amount), formatAmount(got.Amount))
+		check(prefix+"appeal_id", split.AppealID, got.AppealID)
+		check(prefix+"campaign_id", split.CampaignID, got.CampaignID)
+		check(prefix+"fund_id", split.FundID, got.FundID)
+	}
+
+	return diffs
+}
+
+// datePart returns the YYYY-MM-DD part of a date, since the API returns dates with a time of day.
+func datePart(date string) string {
+	if len(date) > len("2006-01-02") {
+		return date[:len("2006-01-02")]
+	}
+	return date
+}
+
+// formatAmount formats an amount to the cent, or returns empty text for no amount.
+func formatAmount(amount *blackbaud.GiftAmount) string {
+	if amount == nil {
+		return ""
+	}
+	return strconv.FormatFloat(amount.Value, 'f', 2, 64)
+}
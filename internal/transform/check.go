@@ -0,0 +1,59 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Check is an assertion a mapped gift must satisfy before it is created, such as a maximum amount.
+type Check struct {
+	// Assert is the condition the gift must meet.
+	Assert *Expr
+
+	// Message describes a gift that fails the check, or is empty to describe it by the condition.
+	Message string
+}
+
+// NewCheck compiles a check that assert holds, described by message when it does not.
+// The condition must be true or false.
+func NewCheck(assert string, message string) (Check, error) {
+	expr, err := Compile(assert)
+	if err != nil {
+		return Check{}, fmt.Errorf("assert: %w", err)
+	}
+	if typ := expr.outputType; !typ.IsExactType(cel.BoolType) {
+		return Check{}, fmt.Errorf("assert must be true or false, got %s", describe(typ))
+	}
+	return Check{Assert: expr, Message: message}, nil
+}
+
+// Holds evaluates the check, reporting whether its condition is true.
+func (c Check) Holds(vars Vars) (bool, error) {
+	result, err := c.Assert.Eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("assert: %w", err)
+	}
+	holds, _ := result.(bool)
+	return holds, nil
+}
+
+// UsesGift reports whether the check refers to a gift variable, so it can only be evaluated once the gift is mapped.
+// Other checks only refer to the donation and supporter, and can be evaluated before anything is created for them.
+func (c Check) UsesGift() bool {
+	for _, name := range c.Assert.names {
+		if strings.HasPrefix(name, "gift.") {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation describes a gift that fails the check.
+func (c Check) Violation() string {
+	if c.Message != "" {
+		return c.Message
+	}
+	return "failed check " + c.Assert.String()
+}
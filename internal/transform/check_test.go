@@ -0,0 +1,121 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		assert  string
+		wantErr string
+	}{
+		"amount limit":  {assert: "donation.amount <= 100000.0"},
+		"fund requires": {assert: "gift.fund_id != 'BUILD' || gift.campaign_id == 'CAPITAL'"},
+		"missing assert": {
+			wantErr: "assert: expression is empty",
+		},
+		"unknown variable": {
+			assert:  "gift.amount < 10",
+			wantErr: "assert: undeclared reference to 'gift' (in container '') at position 1",
+		},
+		"non-boolean assert": {
+			assert:  "gift.fund_id",
+			wantErr: "assert must be true or false, got text",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewCheck(tc.assert, "")
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckHolds(t *testing.T) {
+	t.Parallel()
+
+	vars := Vars{VarDonationAmount: 250000.0, VarGiftCampaignID: "ANNUAL", VarGiftFundID: "BUILD"}
+
+	tests := map[string]struct {
+		assert        string
+		message       string
+		want          bool
+		wantErr       string
+		wantViolation string
+	}{
+		"holds": {
+			assert:        "gift.fund_id != ''",
+			want:          true,
+			wantViolation: "failed check gift.fund_id != ''",
+		},
+		"fails with message": {
+			assert:        "donation.amount <= 100000.0",
+			message:       "amount over 100000",
+			wantViolation: "amount over 100000",
+		},
+		"fails without message": {
+			assert:        "gift.fund_id != 'BUILD' || gift.campaign_id == 'CAPITAL'",
+			wantViolation: "failed check gift.fund_id != 'BUILD' || gift.campaign_id == 'CAPITAL'",
+		},
+		"evaluation error": {
+			assert:  "1 / donation.installment > 0",
+			wantErr: "assert: division by zero",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			check, err := NewCheck(tc.assert, tc.message)
+			require.NoError(t, err)
+
+			holds, err := check.Holds(vars)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, holds)
+			require.Equal(t, tc.wantViolation, check.Violation())
+		})
+	}
+}
+
+func TestCheckUsesGift(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		assert string
+		want   bool
+	}{
+		"donation only":  {assert: "donation.amount <= 100000.0", want: false},
+		"supporter only": {assert: "!ends_with(supporter.email, '@example.org')", want: false},
+		"gift only":      {assert: "gift.fund_id != ''", want: true},
+		"donation and gift": {
+			assert: "donation.amount < 1000.0 || gift.fund_id == 'MAJOR'",
+			want:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			check, err := NewCheck(tc.assert, "")
+			require.NoError(t, err)
+			require.Equal(t, tc.want, check.UsesGift())
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Package transform evaluates gift rules: small expressions over a donation that compute gift fields.
+// Expressions are written in the Common Expression Language (CEL, https://cel.dev), with an environment
+// limited to the donation variables below and no macros, so rules from config are safe to run.
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// maxFixedPlaces is the most decimal places fixed accepts.
+const maxFixedPlaces = 10
+
+// Variables available to expressions, as described in docs/field-mapping.md.
+const (
+	// VarDonationAmount is the donation amount as a decimal number, such as 25.0.
+	VarDonationAmount = "donation.amount"
+
+	// VarDonationCampaignID is the FundraiseUp campaign ID.
+	VarDonationCampaignID = "donation.campaign_id"
+
+	// VarDonationCampaignName is the FundraiseUp campaign name.
+	VarDonationCampaignName = "donation.campaign_name"
+
+	// VarDonationComment is the donor's comment.
+	VarDonationComment = "donation.comment"
+
+	// VarDonationCurrency is the three-letter currency code, such as "GBP".
+	VarDonationCurrency = "donation.currency"
+
+	// VarDonationDate is the donation date in YYYY-MM-DD format.
+	VarDonationDate = "donation.date"
+
+	// VarDonationDesignationID is the FundraiseUp designation ID.
+	VarDonationDesignationID = "donation.designation_id"
+
+	// VarDonationDesignationName is the FundraiseUp designation name.
+	VarDonationDesignationName = "donation.designation_name"
+
+	// VarDonationID is the FundraiseUp donation ID.
+	VarDonationID = "donation.id"
+
+	// VarDonationInstallment is the installment number of a recurring donation as a whole number, or 0 for a one-off.
+	VarDonationInstallment = "donation.installment"
+
+	// VarDonationRecurring is true for installments of a recurring plan.
+	VarDonationRecurring = "donation.recurring"
+
+	// VarGiftAppealID is the gift's appeal ID.
+	VarGiftAppealID = "gift.appeal_id"
+
+	// VarGiftCampaignID is the gift's campaign ID.
+	VarGiftCampaignID = "gift.campaign_id"
+
+	// VarGiftFundID is the gift's fund ID.
+	VarGiftFundID = "gift.fund_id"
+
+	// VarGiftReference is the gift's reference.
+	VarGiftReference = "gift.reference"
+
+	// VarGiftType is the gift type, such as "Donation" or "RecurringGiftPayment".
+	VarGiftType = "gift.type"
+
+	// VarSupporterEmail is the supporter's email address.
+	VarSupporterEmail = "supporter.email"
+
+	// VarSupporterFirstName is the supporter's first name.
+	VarSupporterFirstName = "supporter.first_name"
+
+	// VarSupporterLastName is the supporter's last name.
+	VarSupporterLastName = "supporter.last_name"
+)
+
+// variables maps each name an expression may refer to to its type.
+var variables = map[string]*cel.Type{
+	VarDonationAmount:          cel.DoubleType,
+	VarDonationCampaignID:      cel.StringType,
+	VarDonationCampaignName:    cel.StringType,
+	VarDonationComment:         cel.StringType,
+	VarDonationCurrency:        cel.StringType,
+	VarDonationDate:            cel.StringType,
+	VarDonationDesignationID:   cel.StringType,
+	VarDonationDesignationName: cel.StringType,
+	VarDonationID:              cel.StringType,
+	VarDonationInstallment:     cel.IntType,
+	VarDonationRecurring:       cel.BoolType,
+	VarGiftAppealID:            cel.StringType,
+	VarGiftCampaignID:          cel.StringType,
+	VarGiftFundID:              cel.StringType,
+	VarGiftReference:           cel.StringType,
+	VarGiftType:                cel.StringType,
+	VarSupporterEmail:          cel.StringType,
+	VarSupporterFirstName:      cel.StringType,
+	VarSupporterLastName:       cel.StringType,
+}
+
+// environment returns the CEL environment shared by every expression.
+var environment = sync.OnceValues(newEnvironment)
+
+// Vars holds the values of variables for evaluating an expression.
+// Values are strings, float64 or int64 numbers, or bools, matching each variable's type.
+// Variables that are not set evaluate to their type's zero value.
+type Vars map[string]any
+
+// Expr is a compiled expression. Expressions have no side effects and always terminate.
+type Expr struct {
+	// names are the variables the expression refers to.
+	names []string
+
+	// outputType is the type the expression evaluates to.
+	outputType *cel.Type
+
+	// program evaluates the expression.
+	program cel.Program
+
+	// src is the expression's source text.
+	src string
+}
+
+// Compile parses and type checks an expression, checking that it only refers to known variables and functions.
+func Compile(src string) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, errors.New("expression is empty")
+	}
+
+	env, err := environment()
+	if err != nil {
+		return nil, fmt.Errorf("creating expression environment: %w", err)
+	}
+
+	ast, issues := env.Compile(src)
+	if issues.Err() != nil {
+		messages := make([]string, 0, len(issues.Errors()))
+		for _, issue := range issues.Errors() {
+			messages = append(messages, fmt.Sprintf("%s at position %d", issue.Message, issue.Location.Column()+1))
+		}
+		return nil, errors.New(strings.Join(messages, "; "))
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("preparing expression: %w", err)
+	}
+
+	var names []string
+	for _, reference := range ast.NativeRep().ReferenceMap() {
+		if reference.Name != "" {
+			names = append(names, reference.Name)
+		}
+	}
+
+	return &Expr{names: names, outputType: ast.OutputType(), program: program, src: src}, nil
+}
+
+// Eval evaluates the expression with the given variables.
+// Numbers that are not finite, such as the result of dividing a decimal number by zero, are an error.
+func (e *Expr) Eval(vars Vars) (any, error) {
+	activation := make(map[string]any, len(variables))
+	for name, typ := range variables {
+		if value, ok := vars[name]; ok {
+			activation[name] = value
+			continue
+		}
+		activation[name] = zero(typ)
+	}
+
+	out, _, err := e.program.Eval(activation)
+	if err != nil {
+		return nil, err
+	}
+
+	value := out.Value()
+	if f, ok := value.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return nil, errors.New("result is not a finite number, check for division by zero")
+	}
+	return value, nil
+}
+
+// String returns the expression's source text.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// newEnvironment declares the variables and functions expressions may use.
+// Macros such as all and map are removed so expressions cannot loop.
+func newEnvironment() (*cel.Env, error) {
+	opts := []cel.EnvOption{
+		cel.ClearMacros(),
+		cel.CrossTypeNumericComparisons(true),
+		stringFunction("lower", strings.ToLower),
+		stringFunction("trim", strings.TrimSpace),
+		stringFunction("upper", strings.ToUpper),
+		stringPredicate("contains", strings.Contains),
+		stringPredicate("ends_with", strings.HasSuffix),
+		stringPredicate("starts_with", strings.HasPrefix),
+		cel.Function("fixed",
+			cel.Overload("fixed_double_int", []*cel.Type{cel.DoubleType, cel.IntType}, cel.StringType,
+				cel.BinaryBinding(fixed)),
+			cel.Overload("fixed_int_int", []*cel.Type{cel.IntType, cel.IntType}, cel.StringType,
+				cel.BinaryBinding(fixed)),
+		),
+	}
+	for name, typ := range variables {
+		opts = append(opts, cel.Variable(name, typ))
+	}
+	return cel.NewEnv(opts...)
+}
+
+// describe names a type for error messages.
+func describe(typ *cel.Type) string {
+	switch {
+	case typ.IsExactType(cel.BoolType):
+		return "true or false"
+	case typ.IsExactType(cel.DoubleType), typ.IsExactType(cel.IntType):
+		return "a number"
+	case typ.IsExactType(cel.StringType):
+		return "text"
+	default:
+		return typ.String()
+	}
+}
+
+// fixed formats a number with a fixed number of decimal places, rounding halves away from zero.
+// The number is rounded from its shortest decimal form, so 2.675 rounds to 2.68 despite being stored as 2.67499...
+func fixed(number ref.Val, places ref.Val) ref.Val {
+	p, ok := places.(types.Int)
+	if !ok || p < 0 || p > maxFixedPlaces {
+		return types.NewErr("fixed: decimal places must be a whole number from 0 to %d", maxFixedPlaces)
+	}
+
+	var s string
+	switch n := number.(type) {
+	case types.Double:
+		if math.IsInf(float64(n), 0) || math.IsNaN(float64(n)) {
+			return types.NewErr("fixed: %v is not a finite number", float64(n))
+		}
+		s = strconv.FormatFloat(float64(n), 'f', -1, 64)
+	case types.Int:
+		s = strconv.FormatInt(int64(n), 10)
+	default:
+		return types.MaybeNoSuchOverloadErr(number)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return types.NewErr("fixed: cannot parse %s", s)
+	}
+	return types.String(r.FloatString(int(p)))
+}
+
+// stringFunction declares a function of one text argument.
+func stringFunction(name string, fn func(string) string) cel.EnvOption {
+	return cel.Function(name,
+		cel.Overload(name+"_string", []*cel.Type{cel.StringType}, cel.StringType,
+			cel.UnaryBinding(func(value ref.Val) ref.Val {
+				s, ok := value.(types.String)
+				if !ok {
+					return types.MaybeNoSuchOverloadErr(value)
+				}
+				return types.String(fn(string(s)))
+			}),
+		),
+	)
+}
+
+// stringPredicate declares a test of two text arguments.
+func stringPredicate(name string, fn func(string, string) bool) cel.EnvOption {
+	return cel.Function(name,
+		cel.Overload(name+"_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(value ref.Val, part ref.Val) ref.Val {
+				s, sok := value.(types.String)
+				p, pok := part.(types.String)
+				if !sok || !pok {
+					return types.MaybeNoSuchOverloadErr(value)
+				}
+				return types.Bool(fn(string(s), string(p)))
+			}),
+		),
+	)
+}
+
+// text converts a value to text, formatting numbers without trailing zeros.
+func text(value any) string {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// zero returns the zero value of a variable's type.
+func zero(typ *cel.Type) any {
+	switch {
+	case typ.IsExactType(cel.BoolType):
+		return false
+	case typ.IsExactType(cel.DoubleType):
+		return 0.0
+	case typ.IsExactType(cel.IntType):
+		return int64(0)
+	default:
+		return ""
+	}
+}
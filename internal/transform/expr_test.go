@@ -0,0 +1,175 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		src     string
+		wantErr string
+	}{
+		"variable":         {src: "donation.id"},
+		"ternary":          {src: "donation.amount >= 1000 ? 'MAJOR' : gift.fund_id"},
+		"function":         {src: "upper(trim(supporter.last_name))"},
+		"member function":  {src: "donation.id.startsWith('don')"},
+		"empty":            {src: "  ", wantErr: "expression is empty"},
+		"unknown variable": {src: "donation.fee", wantErr: "undeclared reference to 'donation' (in container '') at position 1"},
+		"unknown function": {
+			src:     "reverse('a')",
+			wantErr: "undeclared reference to 'reverse' (in container '') at position 8",
+		},
+		"wrong arity": {
+			src:     "lower('a', 'b')",
+			wantErr: "found no matching overload for 'lower' applied to '(string, string)' at position 6",
+		},
+		"macros disabled": {
+			src: "[1, 2].exists(n, n > 1)",
+			wantErr: "undeclared reference to 'exists' (in container '') at position 14; " +
+				"undeclared reference to 'n' (in container '') at position 15; " +
+				"undeclared reference to 'n' (in container '') at position 18",
+		},
+		"text times number": {
+			src:     "donation.id * 2",
+			wantErr: "found no matching overload for '_*_' applied to '(string, int)' at position 13",
+		},
+		"decimal times whole number": {
+			src:     "donation.amount * 2",
+			wantErr: "found no matching overload for '_*_' applied to '(double, int)' at position 17",
+		},
+		"text equals number": {
+			src:     "donation.amount == '1250.5'",
+			wantErr: "found no matching overload for '_==_' applied to '(double, string)' at position 17",
+		},
+		"mixed ternary branches": {
+			src:     "donation.recurring ? 1 : 'one-off'",
+			wantErr: "found no matching overload for '_?_:_' applied to '(bool, int, string)' at position 20",
+		},
+		"ternary condition": {
+			src:     "donation.id ? 1 : 2",
+			wantErr: "found no matching overload for '_?_:_' applied to '(string, int, int)' at position 13",
+		},
+		"syntax error": {
+			src:     "true ? 'a'",
+			wantErr: "Syntax error: mismatched input '<EOF>' expecting ':' at position 11",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Compile(tc.src)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.src, expr.String())
+		})
+	}
+}
+
+func TestExprEval(t *testing.T) {
+	t.Parallel()
+
+	vars := Vars{
+		VarDonationAmount:      1250.5,
+		VarDonationCurrency:    "GBP",
+		VarDonationID:          "don_123",
+		VarDonationInstallment: int64(0),
+		VarDonationRecurring:   false,
+		VarSupporterLastName:   " Smith ",
+	}
+
+	tests := map[string]struct {
+		src     string
+		want    any
+		wantErr string
+	}{
+		"concatenation":         {src: "'FRU-' + donation.id", want: "FRU-don_123"},
+		"number in text":        {src: "donation.currency + ' ' + string(donation.amount)", want: "GBP 1250.5"},
+		"arithmetic":            {src: "donation.amount * 2.0 - 1.0 / 2.0", want: 2500.5},
+		"whole numbers":         {src: "7 / 2 + 7 % 2", want: int64(4)},
+		"multiplication first":  {src: "1 + 2 * 3", want: int64(7)},
+		"parentheses":           {src: "(1 + 2) * 3", want: int64(9)},
+		"left to right":         {src: "10 - 4 - 3", want: int64(3)},
+		"unary minus":           {src: "-2 * -3", want: int64(6)},
+		"comparison before and": {src: "1 + 2 * 3 == 7 && !false", want: true},
+		"and before or":         {src: "true || false && false", want: true},
+		"not binds tightly":     {src: "!true || true", want: true},
+		"ternary binds loosely": {src: "1 < 2 ? 'yes' : 'no'", want: "yes"},
+		"compare with whole":    {src: "donation.amount >= 1000", want: true},
+		"string comparison":     {src: "donation.currency < 'USD'", want: true},
+		"ternary":               {src: "donation.amount >= 1000.0 ? 'MAJOR' : 'GENERAL'", want: "MAJOR"},
+		"nested ternary": {
+			src:  "donation.amount < 100 ? 'S' : donation.amount < 1000 ? 'M' : 'L'",
+			want: "L",
+		},
+		"functions":        {src: "upper(trim(supporter.last_name))", want: "SMITH"},
+		"predicates":       {src: "starts_with(donation.id, 'don') && !ends_with(donation.id, 'x')", want: true},
+		"contains":         {src: "contains(donation.id, '_1')", want: true},
+		"member functions": {src: "donation.id.endsWith('123') && donation.id.contains('_')", want: true},
+		"missing variable": {src: "donation.campaign_id == '' && donation.designation_id == ''", want: true},
+		"escaped quote":    {src: `'it\'s'`, want: "it's"},
+		"double quotes":    {src: `"a" + 'b'`, want: "ab"},
+		"negation":         {src: "-donation.amount", want: -1250.5},
+		"short circuit":    {src: "donation.recurring && 1 / donation.installment > 0", want: false},
+		"whole division by zero": {
+			src:     "10 / donation.installment",
+			wantErr: "division by zero",
+		},
+		"whole modulus by zero": {
+			src:     "10 % donation.installment",
+			wantErr: "modulus by zero",
+		},
+		"decimal division by zero": {
+			src:     "donation.amount / 0.0",
+			wantErr: "result is not a finite number, check for division by zero",
+		},
+		"overflow": {
+			src:     "9223372036854775807 + 1",
+			wantErr: "integer overflow",
+		},
+		"fixed":              {src: "fixed(donation.amount, 2)", want: "1250.50"},
+		"fixed whole number": {src: "fixed(25, 2)", want: "25.00"},
+		"fixed rounds half away from zero": {
+			src:  "fixed(2.5, 0) + ' ' + fixed(-2.5, 0) + ' ' + fixed(0.125, 2)",
+			want: "3 -3 0.13",
+		},
+		"fixed rounds the decimal value": {
+			src:  "fixed(2.675, 2) + ' ' + fixed(1.005, 2)",
+			want: "2.68 1.01",
+		},
+		"fixed hides float error": {src: "fixed(0.1 + 0.2, 2)", want: "0.30"},
+		"fixed places": {
+			src:     "fixed(donation.amount, -1)",
+			wantErr: "fixed: decimal places must be a whole number from 0 to 10",
+		},
+		"fixed infinity": {
+			src:     "fixed(donation.amount / 0.0, 2)",
+			wantErr: "fixed: +Inf is not a finite number",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Compile(tc.src)
+			require.NoError(t, err)
+
+			got, err := expr.Eval(vars)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
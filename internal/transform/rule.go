@@ -0,0 +1,100 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Gift fields a rule can set.
+const (
+	// FieldAppealID sets the Raiser's Edge appeal of the gift.
+	FieldAppealID = "appeal_id"
+
+	// FieldCampaignID sets the Raiser's Edge campaign of the gift.
+	FieldCampaignID = "campaign_id"
+
+	// FieldFundID sets the Raiser's Edge fund of the gift.
+	FieldFundID = "fund_id"
+
+	// FieldReference sets the gift's reference.
+	FieldReference = "reference"
+)
+
+// Rule sets a gift field to the result of an expression, optionally only when a condition holds.
+type Rule struct {
+	// Field is the gift field to set: FieldAppealID, FieldCampaignID, FieldFundID or FieldReference.
+	Field string
+
+	// Value computes the field's new value.
+	Value *Expr
+
+	// When decides whether the rule applies, or is nil to always apply.
+	When *Expr
+}
+
+// NewRule compiles a rule setting field to value, when the optional when condition is true.
+// The value must be text, a number or true or false, and the condition must be true or false.
+func NewRule(field string, when string, value string) (Rule, error) {
+	switch field {
+	case FieldAppealID, FieldCampaignID, FieldFundID, FieldReference:
+	case "":
+		return Rule{}, errors.New("field is required")
+	default:
+		return Rule{}, fmt.Errorf(
+			"field %q must be %s, %s, %s or %s",
+			field, FieldAppealID, FieldCampaignID, FieldFundID, FieldReference,
+		)
+	}
+
+	rule := Rule{Field: field}
+
+	var err error
+	if rule.Value, err = Compile(value); err != nil {
+		return Rule{}, fmt.Errorf("value: %w", err)
+	}
+	if typ := rule.Value.outputType; !isScalar(typ) {
+		return Rule{}, fmt.Errorf("value must be text, a number or true or false, got %s", describe(typ))
+	}
+	if when != "" {
+		if rule.When, err = Compile(when); err != nil {
+			return Rule{}, fmt.Errorf("when: %w", err)
+		}
+		if typ := rule.When.outputType; !typ.IsExactType(cel.BoolType) {
+			return Rule{}, fmt.Errorf("when must be true or false, got %s", describe(typ))
+		}
+	}
+
+	return rule, nil
+}
+
+// Apply evaluates the rule, returning the field's new value and whether the rule applies.
+// Numbers and true or false results are converted to text.
+func (r Rule) Apply(vars Vars) (string, bool, error) {
+	if r.When != nil {
+		result, err := r.When.Eval(vars)
+		if err != nil {
+			return "", false, fmt.Errorf("when: %w", err)
+		}
+		if applies, _ := result.(bool); !applies {
+			return "", false, nil
+		}
+	}
+
+	result, err := r.Value.Eval(vars)
+	if err != nil {
+		return "", false, fmt.Errorf("value: %w", err)
+	}
+	return text(result), true, nil
+}
+
+// isScalar reports whether a value of the type can be converted to text for a gift field.
+func isScalar(typ *cel.Type) bool {
+	for _, scalar := range []*cel.Type{cel.BoolType, cel.DoubleType, cel.IntType, cel.StringType} {
+		if typ.IsExactType(scalar) {
+			return true
+		}
+	}
+	return false
+}
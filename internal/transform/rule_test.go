@@ -0,0 +1,104 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRule(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		field   string
+		value   string
+		when    string
+		wantErr string
+	}{
+		"value only":     {field: FieldReference, value: "'FRU ' + donation.id"},
+		"with condition": {field: FieldFundID, value: "'MAJOR'", when: "donation.amount >= 1000"},
+		"missing field":  {value: "'x'", wantErr: "field is required"},
+		"unknown field": {
+			field:   "type",
+			value:   "'x'",
+			wantErr: `field "type" must be appeal_id, campaign_id, fund_id or reference`,
+		},
+		"missing value": {field: FieldFundID, wantErr: "value: expression is empty"},
+		"invalid when": {
+			field:   FieldFundID,
+			value:   "'x'",
+			when:    "donation.total > 1",
+			wantErr: "when: undeclared reference to 'donation' (in container '') at position 1",
+		},
+		"non-scalar value": {
+			field:   FieldReference,
+			value:   "[donation.id]",
+			wantErr: "value must be text, a number or true or false, got list(string)",
+		},
+		"non-boolean when": {
+			field:   FieldReference,
+			value:   "'x'",
+			when:    "donation.id",
+			wantErr: "when must be true or false, got text",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := NewRule(tc.field, tc.when, tc.value)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.field, rule.Field)
+			require.Equal(t, tc.when == "", rule.When == nil)
+		})
+	}
+}
+
+func TestRuleApply(t *testing.T) {
+	t.Parallel()
+
+	vars := Vars{VarDonationAmount: 25.0, VarDonationID: "don_123"}
+
+	tests := map[string]struct {
+		value     string
+		when      string
+		want      string
+		wantApply bool
+		wantErr   string
+	}{
+		"always applies":  {value: "'FRU ' + donation.id", want: "FRU don_123", wantApply: true},
+		"condition holds": {value: "'SMALL'", when: "donation.amount < 100", want: "SMALL", wantApply: true},
+		"condition fails": {value: "'LARGE'", when: "donation.amount >= 100"},
+		"number result":   {value: "donation.amount * 2.0", want: "50", wantApply: true},
+		"whole result":    {value: "donation.installment + 1", want: "1", wantApply: true},
+		"boolean result":  {value: "donation.recurring", want: "false", wantApply: true},
+		"when error":      {value: "'x'", when: "1 / donation.installment > 0", wantErr: "when: division by zero"},
+		"value error": {
+			value:   "donation.amount / 0.0",
+			wantErr: "value: result is not a finite number, check for division by zero",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := NewRule(FieldReference, tc.when, tc.value)
+			require.NoError(t, err)
+
+			got, applies, err := rule.Apply(vars)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantApply, applies)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Package version reports which giftbridge build is running, for logs, API requests and support questions.
+package version
+
+import (
+	"cmp"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Dev is the version of binaries not built by the release workflow.
+const Dev = "dev"
+
+// commitLength is how many characters of the VCS revision are reported.
+const commitLength = 12
+
+// Version is the release tag the binary was built from, set at build time with
+// -ldflags "-X github.com/peteski22/giftbridge/internal/version.Version=v1.2.3".
+var Version = Dev
+
+// Commit returns the VCS revision the binary was built from, shortened and suffixed with "-dirty" when built
+// with uncommitted changes, or "" when the build has no VCS information, as with go run.
+func Commit() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return ""
+	}
+
+	if len(revision) > commitLength {
+		revision = revision[:commitLength]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// Compare orders two semantic versions, such as v1.2.3 and v1.3.0-rc.1, returning -1 when a is older than b, 0
+// when they are the same release and +1 when a is newer. A pre-release is older than its release, and build
+// metadata after a "+" is ignored.
+func Compare(a string, b string) (int, error) {
+	av, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	bv, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range av.core {
+		if c := cmp.Compare(av.core[i], bv.core[i]); c != 0 {
+			return c, nil
+		}
+	}
+	return comparePrerelease(av.prerelease, bv.prerelease), nil
+}
+
+// String describes the build, such as "v1.2.3 (commit 0123456789ab, go1.25.5)".
+func String() string {
+	if commit := Commit(); commit != "" {
+		return fmt.Sprintf("%s (commit %s, %s)", Version, commit, runtime.Version())
+	}
+	return fmt.Sprintf("%s (%s)", Version, runtime.Version())
+}
+
+// UserAgent returns the User-Agent header sent to the FundraiseUp and Blackbaud APIs, such as "giftbridge/v1.2.3",
+// identifying the organization running giftbridge when organization is set, as in
+// "giftbridge/v1.2.3 (st-marys-hospice)".
+func UserAgent(organization string) string {
+	if organization == "" {
+		return "giftbridge/" + Version
+	}
+	return "giftbridge/" + Version + " (" + organization + ")"
+}
+
+// semver is a parsed semantic version.
+type semver struct {
+	// core holds the major, minor and patch numbers.
+	core [3]int
+
+	// prerelease holds the dot-separated pre-release identifiers, such as ["rc", "1"], or none for a release.
+	prerelease []string
+}
+
+// parse parses a semantic version, with or without a leading "v".
+func parse(v string) (semver, error) {
+	s := strings.TrimPrefix(v, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, prerelease, hasPrerelease := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) != len(semver{}.core) {
+		return semver{}, fmt.Errorf("%q is not a semantic version", v)
+	}
+
+	var parsed semver
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, fmt.Errorf("%q is not a semantic version", v)
+		}
+		parsed.core[i] = n
+	}
+	if hasPrerelease {
+		parsed.prerelease = strings.Split(prerelease, ".")
+		for _, id := range parsed.prerelease {
+			if id == "" {
+				return semver{}, fmt.Errorf("%q is not a semantic version", v)
+			}
+		}
+	}
+
+	return parsed, nil
+}
+
+// comparePrerelease orders pre-release identifiers by semantic versioning's precedence: no identifiers (a release)
+// come last, numeric identifiers are compared as numbers and before alphanumeric ones, and a shorter list comes
+// first when the lists otherwise match.
+func comparePrerelease(a []string, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return -cmp.Compare(len(a), len(b))
+	}
+
+	for i := range min(len(a), len(b)) {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
@@ -0,0 +1,126 @@
+package version
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		a      string
+		b      string
+		errMsg string
+		want   int
+	}{
+		"same release": {
+			a: "v1.4.0",
+			b: "v1.4.0",
+		},
+		"older patch": {
+			a:    "v1.4.0",
+			b:    "v1.4.1",
+			want: -1,
+		},
+		"numbers compared numerically": {
+			a:    "v1.10.0",
+			b:    "v1.9.3",
+			want: 1,
+		},
+		"pre-release older than its release": {
+			a:    "v1.5.0-rc.1",
+			b:    "v1.5.0",
+			want: -1,
+		},
+		"pre-release newer than an earlier release": {
+			a:    "v1.5.0-rc.1",
+			b:    "v1.4.0",
+			want: 1,
+		},
+		"numeric pre-release identifiers compared numerically": {
+			a:    "v1.5.0-rc.10",
+			b:    "v1.5.0-rc.9",
+			want: 1,
+		},
+		"numeric pre-release identifier older than alphanumeric": {
+			a:    "v1.5.0-1",
+			b:    "v1.5.0-alpha",
+			want: -1,
+		},
+		"shorter pre-release older": {
+			a:    "v1.5.0-alpha",
+			b:    "v1.5.0-alpha.1",
+			want: -1,
+		},
+		"build metadata ignored": {
+			a: "v1.4.0+linux",
+			b: "1.4.0",
+		},
+		"not a semantic version": {
+			a:      "dev",
+			b:      "v1.4.0",
+			errMsg: `"dev" is not a semantic version`,
+		},
+		"empty pre-release identifier": {
+			a:      "v1.4.0",
+			b:      "v1.4.0-rc..1",
+			errMsg: `"v1.4.0-rc..1" is not a semantic version`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Compare(tc.a, tc.b)
+
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	got := String()
+
+	require.True(t, strings.HasPrefix(got, Version+" ("), "got %q", got)
+	require.True(t, strings.HasSuffix(got, runtime.Version()+")"), "got %q", got)
+	if commit := Commit(); commit != "" {
+		require.Contains(t, got, "commit "+commit)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		organization string
+		want         string
+	}{
+		"version only": {
+			want: "giftbridge/" + Version,
+		},
+		"with organization": {
+			organization: "st-marys-hospice",
+			want:         "giftbridge/" + Version + " (st-marys-hospice)",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, UserAgent(tc.organization))
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// maxBodyBytes is the largest event body the receiver reads. FundraiseUp events are a few kilobytes.
+const maxBodyBytes = 1 << 20
+
+// ErrMalformedEvent is returned when an event body cannot be decoded or is missing required fields.
+var ErrMalformedEvent = errors.New("malformed webhook event")
+
+// Handler processes verified webhook events.
+type Handler interface {
+	// DonationCreated handles a new donation.
+	DonationCreated(ctx context.Context, donation fundraiseup.Donation) error
+
+	// DonationRefunded handles a refunded donation.
+	DonationRefunded(ctx context.Context, refund Refund) error
+
+	// RecurringCancelled handles a cancelled recurring plan.
+	RecurringCancelled(ctx context.Context, cancellation Cancellation) error
+}
+
+// Option configures optional Receiver settings.
+type Option func(*options) error
+
+// options holds optional configuration for creating a Receiver.
+type options struct {
+	// deferrals holds events that arrive before the donation or plan they refer to, if set.
+	deferrals DeferralQueue
+
+	// events records handled events so redeliveries are skipped, if set.
+	events EventLog
+
+	// logger is the logger for ignored events.
+	logger *slog.Logger
+
+	// now returns the current time.
+	now func() time.Time
+
+	// tolerance is how far a signature timestamp may be from the current time.
+	tolerance time.Duration
+}
+
+// Receiver verifies FundraiseUp webhook events and passes them to a Handler.
+// The Lambda webhook mode calls Receive directly; serve mode mounts the Receiver as an http.Handler.
+type Receiver struct {
+	// deferrals holds events that arrive before the donation or plan they refer to, if set.
+	deferrals DeferralQueue
+
+	// events records handled events so redeliveries are skipped, if set.
+	events EventLog
+
+	// handler processes verified events.
+	handler Handler
+
+	// logger is the logger for ignored events.
+	logger *slog.Logger
+
+	// now returns the current time.
+	now func() time.Time
+
+	// secret is the webhook signing secret.
+	secret string
+
+	// tolerance is how far a signature timestamp may be from the current time.
+	tolerance time.Duration
+}
+
+// WithLogger sets the logger for ignored events.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		o.logger = logger
+		return nil
+	}
+}
+
+// WithTolerance sets how far a signature timestamp may be from the current time before the event is rejected.
+func WithTolerance(tolerance time.Duration) Option {
+	return func(o *options) error {
+		if tolerance <= 0 {
+			return fmt.Errorf("tolerance must be positive")
+		}
+		o.tolerance = tolerance
+		return nil
+	}
+}
+
+// NewReceiver creates a Receiver that verifies events signed with secret and passes them to handler.
+func NewReceiver(secret string, handler Handler, opts ...Option) (*Receiver, error) {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil, errors.New("webhook secret is required")
+	}
+	if handler == nil {
+		return nil, errors.New("handler is required")
+	}
+
+	o := &options{
+		logger:    slog.Default(),
+		now:       time.Now,
+		tolerance: defaultTolerance,
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, fmt.Errorf("applying option: %w", err)
+		}
+	}
+
+	return &Receiver{
+		deferrals: o.deferrals,
+		events:    o.events,
+		handler:   handler,
+		logger:    o.logger,
+		now:       o.now,
+		secret:    secret,
+		tolerance: o.tolerance,
+	}, nil
+}
+
+// Receive verifies an event body against its signature header and passes the event to the handler.
+// Unsupported event types are ignored, as are events already recorded in the event log.
+// Returns ErrInvalidSignature, ErrSignatureExpired or ErrMalformedEvent for events that should not be retried,
+// and the handler's error otherwise.
+func (r *Receiver) Receive(ctx context.Context, body []byte, signature string) error {
+	if err := Verify(r.secret, body, signature, r.now(), r.tolerance); err != nil {
+		return err
+	}
+
+	event, err := Parse(body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+	}
+
+	if !event.Supported() {
+		r.logger.Info("Ignoring unsupported webhook event", slog.String("event_id", event.ID),
+			slog.String("type", string(event.Type)))
+		return nil
+	}
+
+	return r.handle(ctx, event, body)
+}
+
+// ServeHTTP implements http.Handler. Events are acknowledged with 204 once handled, rejected with 401 or 400
+// when they fail verification or decoding, and answered with 500 when the handler fails so FundraiseUp retries.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	err = r.Receive(req.Context(), body, req.Header.Get(SignatureHeader))
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrSignatureExpired):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, ErrMalformedEvent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		r.logger.Error("Webhook handler failed", slog.String("error", err.Error()))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// dispatch maps a supported event to its internal type and passes it to the matching handler method.
+func (r *Receiver) dispatch(ctx context.Context, event *Event) error {
+	switch event.Type {
+	case EventDonationCreated:
+		donation, err := event.Donation()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+		}
+		return r.handler.DonationCreated(ctx, donation)
+	case EventDonationRefunded:
+		refund, err := event.Refund()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+		}
+		return r.handler.DonationRefunded(ctx, refund)
+	case EventRecurringCancelled:
+		cancellation, err := event.Cancellation()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+		}
+		return r.handler.RecurringCancelled(ctx, cancellation)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+type mockHandler struct {
+	cancellations []Cancellation
+	donations     []fundraiseup.Donation
+	err           error
+	refunds       []Refund
+}
+
+func (m *mockHandler) DonationCreated(_ context.Context, donation fundraiseup.Donation) error {
+	m.donations = append(m.donations, donation)
+	return m.err
+}
+
+func (m *mockHandler) DonationRefunded(_ context.Context, refund Refund) error {
+	m.refunds = append(m.refunds, refund)
+	return m.err
+}
+
+func (m *mockHandler) RecurringCancelled(_ context.Context, cancellation Cancellation) error {
+	m.cancellations = append(m.cancellations, cancellation)
+	return m.err
+}
+
+func TestNewReceiver(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		handler Handler
+		opts    []Option
+		secret  string
+		wantErr string
+	}{
+		"valid": {
+			handler: &mockHandler{},
+			secret:  "secret",
+		},
+		"missing secret": {
+			handler: &mockHandler{},
+			secret:  " ",
+			wantErr: "webhook secret is required",
+		},
+		"missing handler": {
+			secret:  "secret",
+			wantErr: "handler is required",
+		},
+		"invalid tolerance": {
+			handler: &mockHandler{},
+			opts:    []Option{WithTolerance(0)},
+			secret:  "secret",
+			wantErr: "applying option: tolerance must be positive",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			receiver, err := NewReceiver(tc.secret, tc.handler, tc.opts...)
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, receiver)
+		})
+	}
+}
+
+func TestReceiver_Receive(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	created := []byte(`{"id":"evt_1","type":"donation.created","data":{"donation":{"id":"don_1","amount":"25.00"}}}`)
+	refunded := []byte(`{"id":"evt_2","type":"donation.refunded","created_at":"2026-01-15T10:00:00Z",` +
+		`"data":{"donation":{"id":"don_1","amount":"25.00","currency":"GBP"}}}`)
+	cancelled := []byte(`{"id":"evt_3","type":"recurring.cancelled","created_at":"2026-01-15T10:00:00Z",` +
+		`"data":{"recurring_plan":{"id":"rec_1"}}}`)
+
+	t.Run("dispatches supported events", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &mockHandler{}
+		receiver, err := NewReceiver("secret", handler)
+		require.NoError(t, err)
+		receiver.now = func() time.Time { return now }
+
+		for _, body := range [][]byte{created, refunded, cancelled} {
+			require.NoError(t, receiver.Receive(t.Context(), body, Sign("secret", body, now)))
+		}
+
+		require.Equal(t, []fundraiseup.Donation{{ID: "don_1", Amount: "25.00"}}, handler.donations)
+		require.Equal(t, []Refund{{Amount: 25, Currency: "GBP", DonationID: "don_1", Full: true, RefundedAt: now}},
+			handler.refunds)
+		require.Equal(t, []Cancellation{{CancelledAt: now, RecurringID: "rec_1"}}, handler.cancellations)
+	})
+
+	t.Run("ignores unsupported events", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &mockHandler{}
+		receiver, err := NewReceiver("secret", handler)
+		require.NoError(t, err)
+		receiver.now = func() time.Time { return now }
+
+		body := []byte(`{"id":"evt_4","type":"supporter.updated","data":{}}`)
+		require.NoError(t, receiver.Receive(t.Context(), body, Sign("secret", body, now)))
+		require.Empty(t, handler.donations)
+	})
+
+	t.Run("rejects bad signatures before decoding", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &mockHandler{}
+		receiver, err := NewReceiver("secret", handler)
+		require.NoError(t, err)
+		receiver.now = func() time.Time { return now }
+
+		err = receiver.Receive(t.Context(), created, Sign("other", created, now))
+		require.ErrorIs(t, err, ErrInvalidSignature)
+		require.Empty(t, handler.donations)
+	})
+
+	t.Run("reports malformed events", func(t *testing.T) {
+		t.Parallel()
+
+		receiver, err := NewReceiver("secret", &mockHandler{})
+		require.NoError(t, err)
+		receiver.now = func() time.Time { return now }
+
+		body := []byte(`{"id":"evt_5","type":"donation.created","data":{}}`)
+		err = receiver.Receive(t.Context(), body, Sign("secret", body, now))
+		require.ErrorIs(t, err, ErrMalformedEvent)
+		require.EqualError(t, err, "malformed webhook event: event evt_5 has no donation")
+	})
+}
+
+func TestReceiver_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"id":"evt_1","type":"donation.created","data":{"donation":{"id":"don_1","amount":"25.00"}}}`)
+
+	tests := map[string]struct {
+		body       []byte
+		handlerErr error
+		method     string
+		signature  func(now time.Time) string
+		wantStatus int
+	}{
+		"handled": {
+			body:       body,
+			method:     http.MethodPost,
+			signature:  func(now time.Time) string { return Sign("secret", body, now) },
+			wantStatus: http.StatusNoContent,
+		},
+		"wrong method": {
+			method:     http.MethodGet,
+			signature:  func(time.Time) string { return "" },
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		"invalid signature": {
+			body:       body,
+			method:     http.MethodPost,
+			signature:  func(now time.Time) string { return Sign("other", body, now) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		"expired signature": {
+			body:       body,
+			method:     http.MethodPost,
+			signature:  func(now time.Time) string { return Sign("secret", body, now.Add(-time.Hour)) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		"malformed event": {
+			body:       []byte(`{"type":"donation.created"}`),
+			method:     http.MethodPost,
+			signature:  func(now time.Time) string { return Sign("secret", []byte(`{"type":"donation.created"}`), now) },
+			wantStatus: http.StatusBadRequest,
+		},
+		"handler error": {
+			body:       body,
+			handlerErr: errors.New("blackbaud unavailable"),
+			method:     http.MethodPost,
+			signature:  func(now time.Time) string { return Sign("secret", body, now) },
+			wantStatus: http.StatusInternalServerError,
+		},
+		"body too large": {
+			body:       bytes.Repeat([]byte("a"), maxBodyBytes+1),
+			method:     http.MethodPost,
+			signature:  func(time.Time) string { return "" },
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+			receiver, err := NewReceiver("secret", &mockHandler{err: tc.handlerErr})
+			require.NoError(t, err)
+			receiver.now = func() time.Time { return now }
+
+			req := httptest.NewRequest(tc.method, "/webhooks/fundraiseup", bytes.NewReader(tc.body))
+			req.Header.Set(SignatureHeader, tc.signature(now))
+			rec := httptest.NewRecorder()
+
+			receiver.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	t.Parallel()
+
+	require.Contains(t, string(OpenAPISpec), "openapi: 3.0.3")
+	require.Contains(t, string(OpenAPISpec), SignatureHeader)
+	for _, eventType := range []EventType{EventDonationCreated, EventDonationRefunded, EventRecurringCancelled} {
+		require.Contains(t, string(OpenAPISpec), string(eventType))
+	}
+}
@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrOutOfOrder is returned by a Handler when an event refers to a donation or recurring plan that has not been
+// created yet, such as a refund delivered before its donation. With a DeferralQueue, the Receiver holds the event
+// and hands it to the Handler again once the donation or plan is created. Without one, the error is returned
+// so FundraiseUp redelivers the event later.
+var ErrOutOfOrder = errors.New("webhook event arrived before the donation it refers to")
+
+// EventLog records the events a Receiver has handled, so events FundraiseUp delivers more than once
+// are only handled once.
+type EventLog interface {
+	// EventHandled reports whether the event with the given ID has been handled.
+	EventHandled(ctx context.Context, eventID string) (bool, error)
+
+	// RecordEvent records that the event with the given ID has been handled.
+	RecordEvent(ctx context.Context, eventID string, handledAt time.Time) error
+}
+
+// DeferralQueue holds the bodies of events that arrived before the donation or recurring plan they refer to.
+type DeferralQueue interface {
+	// DeferEvent holds an event body under key until ReleaseEvents is called for the same key.
+	DeferEvent(ctx context.Context, key string, body []byte, deferredAt time.Time) error
+
+	// ReleaseEvents removes and returns the event bodies held under key, in the order they were deferred.
+	ReleaseEvents(ctx context.Context, key string) ([][]byte, error)
+}
+
+// WithDeferralQueue holds events that arrive before the donation or recurring plan they refer to,
+// instead of failing them for FundraiseUp to redeliver.
+func WithDeferralQueue(queue DeferralQueue) Option {
+	return func(o *options) error {
+		if queue == nil {
+			return errors.New("deferral queue cannot be nil")
+		}
+		o.deferrals = queue
+		return nil
+	}
+}
+
+// WithEventLog skips events that have already been handled, as recorded in log.
+func WithEventLog(log EventLog) Option {
+	return func(o *options) error {
+		if log == nil {
+			return errors.New("event log cannot be nil")
+		}
+		o.events = log
+		return nil
+	}
+}
+
+// donationKey returns the deferral key of events waiting for a donation.
+func donationKey(donationID string) string {
+	return "donation/" + donationID
+}
+
+// recurringKey returns the deferral key of events waiting for a recurring plan.
+func recurringKey(recurringID string) string {
+	return "recurring/" + recurringID
+}
+
+// dependency returns the deferral key of the donation or recurring plan an event refers to,
+// or "" for events that do not depend on another.
+func (e *Event) dependency() string {
+	switch e.Type {
+	case EventDonationRefunded:
+		if e.Data.Donation != nil {
+			return donationKey(e.Data.Donation.ID)
+		}
+	case EventRecurringCancelled:
+		if e.Data.RecurringPlan != nil {
+			return recurringKey(e.Data.RecurringPlan.ID)
+		}
+	}
+	return ""
+}
+
+// dependents returns the deferral keys of events that may be waiting for an event,
+// or nil for events nothing waits for.
+func (e *Event) dependents() []string {
+	if e.Type != EventDonationCreated || e.Data.Donation == nil {
+		return nil
+	}
+
+	keys := []string{donationKey(e.Data.Donation.ID)}
+	if recurringID := e.Data.Donation.RecurringID(); recurringID != "" {
+		keys = append(keys, recurringKey(recurringID))
+	}
+
+	return keys
+}
+
+// handle passes a verified event to the handler once, deferring it if it arrived out of order,
+// then hands over any events that were waiting for it.
+func (r *Receiver) handle(ctx context.Context, event *Event, body []byte) error {
+	handled := false
+	if r.events != nil {
+		seen, err := r.events.EventHandled(ctx, event.ID)
+		if err != nil {
+			return fmt.Errorf("checking event %s: %w", event.ID, err)
+		}
+		handled = seen
+	}
+
+	if handled {
+		r.logger.Info("Ignoring redelivered webhook event", slog.String("event_id", event.ID),
+			slog.String("type", string(event.Type)))
+	} else {
+		err := r.dispatch(ctx, event)
+		if errors.Is(err, ErrOutOfOrder) && r.deferrals != nil {
+			return r.deferEvent(ctx, event, body)
+		}
+		if err != nil {
+			return err
+		}
+
+		if r.events != nil {
+			if err := r.events.RecordEvent(ctx, event.ID, r.now()); err != nil {
+				return fmt.Errorf("recording event %s: %w", event.ID, err)
+			}
+		}
+	}
+
+	// Waiting events are released even when this event was a redelivery, in case releasing them failed before.
+	return r.release(ctx, event)
+}
+
+// deferEvent holds an out of order event until the donation or recurring plan it refers to is created.
+func (r *Receiver) deferEvent(ctx context.Context, event *Event, body []byte) error {
+	key := event.dependency()
+	if key == "" {
+		return fmt.Errorf("event %s: %w", event.ID, ErrOutOfOrder)
+	}
+
+	if err := r.deferrals.DeferEvent(ctx, key, body, r.now()); err != nil {
+		return fmt.Errorf("deferring event %s: %w", event.ID, err)
+	}
+
+	r.logger.Info("Deferring webhook event until its donation is created", slog.String("event_id", event.ID),
+		slog.String("type", string(event.Type)), slog.String("waiting_for", key))
+
+	return nil
+}
+
+// release handles the events that were deferred until event was handled.
+// Events that fail are deferred again, so they are retried when event is redelivered.
+func (r *Receiver) release(ctx context.Context, event *Event) error {
+	if r.deferrals == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, key := range event.dependents() {
+		bodies, err := r.deferrals.ReleaseEvents(ctx, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("releasing events waiting for %s: %w", key, err))
+			continue
+		}
+
+		for _, body := range bodies {
+			waiting, err := Parse(body)
+			if err != nil {
+				r.logger.Warn("Dropping malformed deferred webhook event", slog.String("error", err.Error()))
+				continue
+			}
+
+			if err := r.handle(ctx, waiting, body); err != nil {
+				errs = append(errs, fmt.Errorf("handling deferred event %s: %w", waiting.ID, err))
+				if deferErr := r.deferrals.DeferEvent(ctx, key, body, r.now()); deferErr != nil {
+					errs = append(errs, fmt.Errorf("deferring event %s again: %w", waiting.ID, deferErr))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
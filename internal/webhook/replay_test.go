@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+var errRefundFailed = errors.New("refund failed")
+
+type mockEventLog struct {
+	handled map[string]time.Time
+}
+
+func (m *mockEventLog) EventHandled(_ context.Context, eventID string) (bool, error) {
+	_, ok := m.handled[eventID]
+	return ok, nil
+}
+
+func (m *mockEventLog) RecordEvent(_ context.Context, eventID string, handledAt time.Time) error {
+	m.handled[eventID] = handledAt
+	return nil
+}
+
+type mockDeferralQueue struct {
+	events map[string][][]byte
+}
+
+func (m *mockDeferralQueue) DeferEvent(_ context.Context, key string, body []byte, _ time.Time) error {
+	m.events[key] = append(m.events[key], body)
+	return nil
+}
+
+func (m *mockDeferralQueue) ReleaseEvents(_ context.Context, key string) ([][]byte, error) {
+	bodies := m.events[key]
+	delete(m.events, key)
+	return bodies, nil
+}
+
+// orderedHandler refuses refunds and cancellations for donations and plans it has not seen created.
+type orderedHandler struct {
+	mockHandler
+
+	// failRefunds makes refunds of created donations fail.
+	failRefunds bool
+}
+
+func (h *orderedHandler) DonationRefunded(ctx context.Context, refund Refund) error {
+	if !h.created(func(d fundraiseup.Donation) bool { return d.ID == refund.DonationID }) {
+		return ErrOutOfOrder
+	}
+	if h.failRefunds {
+		return errRefundFailed
+	}
+	return h.mockHandler.DonationRefunded(ctx, refund)
+}
+
+func (h *orderedHandler) RecurringCancelled(ctx context.Context, cancellation Cancellation) error {
+	if !h.created(func(d fundraiseup.Donation) bool { return d.RecurringID() == cancellation.RecurringID }) {
+		return ErrOutOfOrder
+	}
+	return h.mockHandler.RecurringCancelled(ctx, cancellation)
+}
+
+func (h *orderedHandler) created(match func(fundraiseup.Donation) bool) bool {
+	for _, d := range h.donations {
+		if match(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReceiver_ReceiveReplayAndOrdering(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	created := []byte(`{"id":"evt_1","type":"donation.created",` +
+		`"data":{"donation":{"id":"don_1","amount":"25.00","recurring_plan":{"id":"rec_1"}}}}`)
+	refunded := []byte(`{"id":"evt_2","type":"donation.refunded","created_at":"2026-01-15T10:00:00Z",` +
+		`"data":{"donation":{"id":"don_1","amount":"25.00","currency":"GBP"}}}`)
+	cancelled := []byte(`{"id":"evt_3","type":"recurring.cancelled","created_at":"2026-01-15T10:00:00Z",` +
+		`"data":{"recurring_plan":{"id":"rec_1"}}}`)
+
+	tests := map[string]struct {
+		bodies            [][]byte
+		deferrals         bool
+		failRefunds       bool
+		wantCancellations int
+		wantDeferred      map[string]int
+		wantDonations     int
+		wantErr           error
+		wantHandled       []string
+		wantRefunds       int
+	}{
+		"redelivered events are handled once": {
+			bodies:        [][]byte{created, created, refunded, refunded},
+			deferrals:     true,
+			wantDonations: 1,
+			wantHandled:   []string{"evt_1", "evt_2"},
+			wantRefunds:   1,
+		},
+		"refund before its donation is deferred": {
+			bodies:       [][]byte{refunded},
+			deferrals:    true,
+			wantDeferred: map[string]int{"donation/don_1": 1},
+		},
+		"deferred events are handled once their donation is created": {
+			bodies:            [][]byte{refunded, cancelled, created},
+			deferrals:         true,
+			wantCancellations: 1,
+			wantDonations:     1,
+			wantHandled:       []string{"evt_1", "evt_2", "evt_3"},
+			wantRefunds:       1,
+		},
+		"redelivered deferred event is handled once": {
+			bodies:        [][]byte{refunded, refunded, created},
+			deferrals:     true,
+			wantDonations: 1,
+			wantHandled:   []string{"evt_1", "evt_2"},
+			wantRefunds:   1,
+		},
+		"without a queue out of order events fail for redelivery": {
+			bodies:  [][]byte{refunded},
+			wantErr: ErrOutOfOrder,
+		},
+		"failed deferred events are deferred again": {
+			bodies:        [][]byte{refunded, created},
+			deferrals:     true,
+			failRefunds:   true,
+			wantDeferred:  map[string]int{"donation/don_1": 1},
+			wantDonations: 1,
+			wantErr:       errRefundFailed,
+			wantHandled:   []string{"evt_1"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &orderedHandler{failRefunds: tc.failRefunds}
+			log := &mockEventLog{handled: map[string]time.Time{}}
+			queue := &mockDeferralQueue{events: map[string][][]byte{}}
+			opts := []Option{WithEventLog(log)}
+			if tc.deferrals {
+				opts = append(opts, WithDeferralQueue(queue))
+			}
+			receiver, err := NewReceiver("secret", handler, opts...)
+			require.NoError(t, err)
+			receiver.now = func() time.Time { return now }
+
+			var errs []error
+			for _, body := range tc.bodies {
+				errs = append(errs, receiver.Receive(t.Context(), body, Sign("secret", body, now)))
+			}
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, errors.Join(errs...), tc.wantErr)
+			} else {
+				require.NoError(t, errors.Join(errs...))
+			}
+
+			var handled []string
+			for id := range log.handled {
+				handled = append(handled, id)
+			}
+			require.ElementsMatch(t, tc.wantHandled, handled)
+			require.Len(t, handler.donations, tc.wantDonations)
+			require.Len(t, handler.refunds, tc.wantRefunds)
+			require.Len(t, handler.cancellations, tc.wantCancellations)
+
+			deferred := map[string]int{}
+			for key, bodies := range queue.events {
+				deferred[key] = len(bodies)
+			}
+			if tc.wantDeferred == nil {
+				tc.wantDeferred = map[string]int{}
+			}
+			require.Equal(t, tc.wantDeferred, deferred)
+		})
+	}
+}
+
+func TestReceiverOptions(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewReceiver("secret", &mockHandler{}, WithEventLog(nil))
+	require.EqualError(t, err, "applying option: event log cannot be nil")
+
+	_, err = NewReceiver("secret", &mockHandler{}, WithDeferralQueue(nil))
+	require.EqualError(t, err, "applying option: deferral queue cannot be nil")
+}
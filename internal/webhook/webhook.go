@@ -0,0 +1,299 @@
+// Package webhook receives FundraiseUp webhook events, verifies their signatures and maps them to the
+// types used by the sync, so webhook deliveries can be handled the same way however they arrive.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+const (
+	// EventDonationCreated is sent when a donation succeeds.
+	EventDonationCreated EventType = "donation.created"
+
+	// EventDonationRefunded is sent when a donation is fully or partly refunded.
+	EventDonationRefunded EventType = "donation.refunded"
+
+	// EventRecurringCancelled is sent when a recurring plan is cancelled by the donor or the organisation.
+	EventRecurringCancelled EventType = "recurring.cancelled"
+)
+
+// SignatureHeader is the HTTP header carrying the event signature.
+const SignatureHeader = "X-FundraiseUp-Signature"
+
+// defaultTolerance is how far a signature timestamp may be from the current time before it is rejected.
+const defaultTolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature is returned when an event's signature is missing, malformed or does not match.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+
+	// ErrSignatureExpired is returned when an event's signature timestamp is outside the allowed tolerance.
+	ErrSignatureExpired = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// OpenAPISpec is the OpenAPI 3 description of the webhook receiver endpoint and event payloads.
+//
+//go:embed openapi.yaml
+var OpenAPISpec []byte
+
+// EventType identifies the kind of webhook event.
+type EventType string
+
+// Event is a FundraiseUp webhook event.
+type Event struct {
+	// CreatedAt is when FundraiseUp raised the event.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Data holds the objects the event refers to.
+	Data EventData `json:"data"`
+
+	// ID is the unique event identifier, the same across redeliveries of the event.
+	ID string `json:"id"`
+
+	// Type is the kind of event.
+	Type EventType `json:"type"`
+}
+
+// EventData holds the objects a webhook event refers to. Which fields are set depends on the event type.
+type EventData struct {
+	// Donation is the donation, set for donation events.
+	Donation *fundraiseup.Donation `json:"donation"`
+
+	// RecurringPlan is the recurring plan, set for recurring plan events.
+	RecurringPlan *fundraiseup.RecurringPlan `json:"recurring_plan"`
+
+	// Refund describes the refund, set for donation.refunded events.
+	Refund *RefundPayload `json:"refund"`
+}
+
+// RefundPayload describes a refund in a donation.refunded event.
+type RefundPayload struct {
+	// Amount is the refunded amount as a decimal string.
+	Amount string `json:"amount"`
+
+	// CreatedAt is when the refund was made.
+	CreatedAt time.Time `json:"created_at"`
+
+	// ID is the unique refund identifier.
+	ID string `json:"id"`
+
+	// Reason is why the donation was refunded, if given.
+	Reason string `json:"reason"`
+}
+
+// Refund is a refunded donation.
+type Refund struct {
+	// Amount is the refunded amount.
+	Amount float64
+
+	// Currency is the three-letter currency code of the donation.
+	Currency string
+
+	// DonationID is the FundraiseUp donation that was refunded.
+	DonationID string
+
+	// Full indicates the whole donation amount was refunded.
+	Full bool
+
+	// Reason is why the donation was refunded, if given.
+	Reason string
+
+	// RefundedAt is when the refund was made.
+	RefundedAt time.Time
+}
+
+// Cancellation is a cancelled recurring plan.
+type Cancellation struct {
+	// CancelledAt is when the plan was cancelled.
+	CancelledAt time.Time
+
+	// RecurringID is the FundraiseUp recurring plan that was cancelled.
+	RecurringID string
+}
+
+// Parse decodes and validates a webhook event body. It does not verify the signature; see Verify.
+func Parse(body []byte) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decoding event: %w", err)
+	}
+
+	if event.ID == "" {
+		return nil, errors.New("event id is required")
+	}
+	if event.Type == "" {
+		return nil, errors.New("event type is required")
+	}
+
+	return &event, nil
+}
+
+// Supported reports whether the event type is one the receiver handles.
+// Other event types are acknowledged and ignored so new FundraiseUp events never cause redelivery.
+func (e *Event) Supported() bool {
+	switch e.Type {
+	case EventDonationCreated, EventDonationRefunded, EventRecurringCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Donation returns the donation from a donation.created event.
+func (e *Event) Donation() (fundraiseup.Donation, error) {
+	if e.Type != EventDonationCreated {
+		return fundraiseup.Donation{}, fmt.Errorf("event %s is %s, not %s", e.ID, e.Type, EventDonationCreated)
+	}
+
+	donation := e.Data.Donation
+	if donation == nil || donation.ID == "" {
+		return fundraiseup.Donation{}, fmt.Errorf("event %s has no donation", e.ID)
+	}
+
+	return *donation, nil
+}
+
+// Refund returns the refund from a donation.refunded event.
+// A refund without its own amount is treated as a full refund of the donation.
+func (e *Event) Refund() (Refund, error) {
+	if e.Type != EventDonationRefunded {
+		return Refund{}, fmt.Errorf("event %s is %s, not %s", e.ID, e.Type, EventDonationRefunded)
+	}
+
+	donation := e.Data.Donation
+	if donation == nil || donation.ID == "" {
+		return Refund{}, fmt.Errorf("event %s has no donation", e.ID)
+	}
+
+	donationAmount, err := strconv.ParseFloat(donation.Amount, 64)
+	if err != nil {
+		return Refund{}, fmt.Errorf("parsing donation amount %s: %w", donation.Amount, err)
+	}
+
+	refund := Refund{
+		Amount:     donationAmount,
+		Currency:   donation.Currency,
+		DonationID: donation.ID,
+		Full:       true,
+		RefundedAt: e.CreatedAt,
+	}
+
+	payload := e.Data.Refund
+	if payload == nil {
+		return refund, nil
+	}
+
+	refund.Reason = payload.Reason
+	if !payload.CreatedAt.IsZero() {
+		refund.RefundedAt = payload.CreatedAt
+	}
+	if payload.Amount != "" {
+		amount, err := strconv.ParseFloat(payload.Amount, 64)
+		if err != nil {
+			return Refund{}, fmt.Errorf("parsing refund amount %s: %w", payload.Amount, err)
+		}
+		refund.Amount = amount
+		refund.Full = amount >= donationAmount
+	}
+
+	return refund, nil
+}
+
+// Cancellation returns the cancelled plan from a recurring.cancelled event.
+func (e *Event) Cancellation() (Cancellation, error) {
+	if e.Type != EventRecurringCancelled {
+		return Cancellation{}, fmt.Errorf("event %s is %s, not %s", e.ID, e.Type, EventRecurringCancelled)
+	}
+
+	plan := e.Data.RecurringPlan
+	if plan == nil || plan.ID == "" {
+		return Cancellation{}, fmt.Errorf("event %s has no recurring plan", e.ID)
+	}
+
+	cancellation := Cancellation{
+		CancelledAt: e.CreatedAt,
+		RecurringID: plan.ID,
+	}
+	if plan.EndedAt != nil {
+		cancellation.CancelledAt = *plan.EndedAt
+	}
+
+	return cancellation, nil
+}
+
+// Sign returns the signature header value for body signed with secret at the given time.
+// The value has the form "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">".
+func Sign(secret string, body []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(signature(secret, timestamp, body)))
+}
+
+// Verify checks that header is a valid signature of body by secret, made within tolerance of now.
+// Returns ErrInvalidSignature or ErrSignatureExpired when the check fails.
+func Verify(secret string, body []byte, header string, now time.Time, tolerance time.Duration) error {
+	var timestamp string
+	var signatures [][]byte
+	for part := range strings.SplitSeq(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			// Several v1 signatures are sent while the signing secret is being rotated.
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := signature(secret, timestamp, body)
+	matched := false
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return ErrInvalidSignature
+	}
+
+	// Check the age only once the signature matches, so a forged timestamp can't be told apart from a forged body.
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	return nil
+}
+
+// signature returns the HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+func signature(secret string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
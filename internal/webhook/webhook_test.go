@@ -0,0 +1,311 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body    string
+		want    *Event
+		wantErr string
+	}{
+		"donation created": {
+			body: `{"id":"evt_1","type":"donation.created","created_at":"2026-01-15T10:00:00Z",` +
+				`"data":{"donation":{"id":"don_1","amount":"25.00"}}}`,
+			want: &Event{
+				CreatedAt: time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC),
+				Data:      EventData{Donation: &fundraiseup.Donation{ID: "don_1", Amount: "25.00"}},
+				ID:        "evt_1",
+				Type:      EventDonationCreated,
+			},
+		},
+		"invalid json": {
+			body:    `{`,
+			wantErr: "decoding event: unexpected end of JSON input",
+		},
+		"missing id": {
+			body:    `{"type":"donation.created"}`,
+			wantErr: "event id is required",
+		},
+		"missing type": {
+			body:    `{"id":"evt_1"}`,
+			wantErr: "event type is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := Parse([]byte(tc.body))
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, event)
+		})
+	}
+}
+
+func TestEvent_Donation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		event   Event
+		want    fundraiseup.Donation
+		wantErr string
+	}{
+		"donation": {
+			event: Event{
+				Data: EventData{Donation: &fundraiseup.Donation{ID: "don_1", Amount: "25.00"}},
+				ID:   "evt_1",
+				Type: EventDonationCreated,
+			},
+			want: fundraiseup.Donation{ID: "don_1", Amount: "25.00"},
+		},
+		"wrong type": {
+			event:   Event{ID: "evt_1", Type: EventRecurringCancelled},
+			wantErr: "event evt_1 is recurring.cancelled, not donation.created",
+		},
+		"missing donation": {
+			event:   Event{ID: "evt_1", Type: EventDonationCreated},
+			wantErr: "event evt_1 has no donation",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			donation, err := tc.event.Donation()
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, donation)
+		})
+	}
+}
+
+func TestEvent_Refund(t *testing.T) {
+	t.Parallel()
+
+	eventAt := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	refundAt := time.Date(2026, 1, 14, 9, 0, 0, 0, time.UTC)
+	donation := &fundraiseup.Donation{ID: "don_1", Amount: "25.00", Currency: "GBP"}
+
+	tests := map[string]struct {
+		event   Event
+		want    Refund
+		wantErr string
+	}{
+		"full refund without payload": {
+			event: Event{CreatedAt: eventAt, Data: EventData{Donation: donation}, ID: "evt_1", Type: EventDonationRefunded},
+			want: Refund{
+				Amount:     25,
+				Currency:   "GBP",
+				DonationID: "don_1",
+				Full:       true,
+				RefundedAt: eventAt,
+			},
+		},
+		"partial refund": {
+			event: Event{
+				CreatedAt: eventAt,
+				Data: EventData{
+					Donation: donation,
+					Refund:   &RefundPayload{Amount: "10.00", CreatedAt: refundAt, ID: "ref_1", Reason: "duplicate"},
+				},
+				ID:   "evt_1",
+				Type: EventDonationRefunded,
+			},
+			want: Refund{
+				Amount:     10,
+				Currency:   "GBP",
+				DonationID: "don_1",
+				Reason:     "duplicate",
+				RefundedAt: refundAt,
+			},
+		},
+		"refund of full amount": {
+			event: Event{
+				CreatedAt: eventAt,
+				Data:      EventData{Donation: donation, Refund: &RefundPayload{Amount: "25.00"}},
+				ID:        "evt_1",
+				Type:      EventDonationRefunded,
+			},
+			want: Refund{
+				Amount:     25,
+				Currency:   "GBP",
+				DonationID: "don_1",
+				Full:       true,
+				RefundedAt: eventAt,
+			},
+		},
+		"invalid refund amount": {
+			event: Event{
+				Data: EventData{Donation: donation, Refund: &RefundPayload{Amount: "ten"}},
+				ID:   "evt_1",
+				Type: EventDonationRefunded,
+			},
+			wantErr: `parsing refund amount ten: strconv.ParseFloat: parsing "ten": invalid syntax`,
+		},
+		"wrong type": {
+			event:   Event{ID: "evt_1", Type: EventDonationCreated},
+			wantErr: "event evt_1 is donation.created, not donation.refunded",
+		},
+		"missing donation": {
+			event:   Event{ID: "evt_1", Type: EventDonationRefunded},
+			wantErr: "event evt_1 has no donation",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			refund, err := tc.event.Refund()
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, refund)
+		})
+	}
+}
+
+func TestEvent_Cancellation(t *testing.T) {
+	t.Parallel()
+
+	eventAt := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	endedAt := time.Date(2026, 1, 14, 9, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		event   Event
+		want    Cancellation
+		wantErr string
+	}{
+		"ended plan": {
+			event: Event{
+				CreatedAt: eventAt,
+				Data:      EventData{RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_1", EndedAt: &endedAt}},
+				ID:        "evt_1",
+				Type:      EventRecurringCancelled,
+			},
+			want: Cancellation{CancelledAt: endedAt, RecurringID: "rec_1"},
+		},
+		"plan without end time": {
+			event: Event{
+				CreatedAt: eventAt,
+				Data:      EventData{RecurringPlan: &fundraiseup.RecurringPlan{ID: "rec_1"}},
+				ID:        "evt_1",
+				Type:      EventRecurringCancelled,
+			},
+			want: Cancellation{CancelledAt: eventAt, RecurringID: "rec_1"},
+		},
+		"missing plan": {
+			event:   Event{ID: "evt_1", Type: EventRecurringCancelled},
+			wantErr: "event evt_1 has no recurring plan",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cancellation, err := tc.event.Cancellation()
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, cancellation)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"id":"evt_1"}`)
+	signedAt := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	valid := Sign("secret", body, signedAt)
+
+	tests := map[string]struct {
+		body    []byte
+		header  string
+		now     time.Time
+		wantErr error
+	}{
+		"valid": {
+			body:   body,
+			header: valid,
+			now:    signedAt.Add(time.Minute),
+		},
+		"valid during secret rotation": {
+			body:   body,
+			header: Sign("old-secret", body, signedAt) + "," + valid[len("t=1768471200,"):],
+			now:    signedAt,
+		},
+		"wrong secret": {
+			body:    body,
+			header:  Sign("other", body, signedAt),
+			now:     signedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		"tampered body": {
+			body:    []byte(`{"id":"evt_2"}`),
+			header:  valid,
+			now:     signedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		"missing header": {
+			body:    body,
+			now:     signedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		"missing timestamp": {
+			body:    body,
+			header:  valid[len("t=1768471200,"):],
+			now:     signedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		"too old": {
+			body:    body,
+			header:  valid,
+			now:     signedAt.Add(6 * time.Minute),
+			wantErr: ErrSignatureExpired,
+		},
+		"too far in the future": {
+			body:    body,
+			header:  valid,
+			now:     signedAt.Add(-6 * time.Minute),
+			wantErr: ErrSignatureExpired,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := Verify("secret", tc.body, tc.header, tc.now, defaultTolerance)
+
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
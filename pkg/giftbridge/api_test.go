@@ -0,0 +1,389 @@
+package giftbridge
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	apiGoldenFile = "testdata/api.golden"
+	modulePath    = "github.com/peteski22/giftbridge"
+	moduleRoot    = "../.."
+)
+
+var updateAPI = flag.Bool("update-api", false, "rewrite "+apiGoldenFile+" with the current exported API")
+
+// apiPackage holds the parsed non-test files of a package in this module.
+type apiPackage struct {
+	// files are the parsed files, sorted by name.
+	files []*ast.File
+
+	// path is the import path.
+	path string
+}
+
+// apiWriter renders the exported API of this package, following aliases into the internal packages they point to.
+type apiWriter struct {
+	// fset holds the positions of all parsed files.
+	fset *token.FileSet
+
+	// packages caches parsed packages by import path.
+	packages map[string]*apiPackage
+
+	// pending are the qualified names still to describe.
+	pending []string
+
+	// seen records qualified names already queued.
+	seen map[string]bool
+}
+
+// TestExportedAPI pins everything an embedding program can reach through this package. Because the types here
+// are aliases, that includes the exported fields and methods of the internal types they point to. Run
+// go test ./pkg/giftbridge -update-api after a deliberate change and review the diff of the golden file.
+func TestExportedAPI(t *testing.T) {
+	t.Parallel()
+
+	w := &apiWriter{
+		fset:     token.NewFileSet(),
+		packages: map[string]*apiPackage{},
+		seen:     map[string]bool{},
+	}
+
+	got, err := w.render(modulePath + "/pkg/giftbridge")
+	require.NoError(t, err)
+
+	if *updateAPI {
+		require.NoError(t, os.MkdirAll(filepath.Dir(apiGoldenFile), 0o755))
+		require.NoError(t, os.WriteFile(apiGoldenFile, []byte(got), 0o644))
+	}
+
+	want, err := os.ReadFile(apiGoldenFile)
+	require.NoError(t, err)
+	require.Equal(t, string(want), got, "exported API changed; if intended, run with -update-api")
+}
+
+// render describes every exported declaration of the package at path, then every module type they refer to.
+func (w *apiWriter) render(path string) (string, error) {
+	pkg, err := w.load(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range pkg.files {
+		for _, decl := range file.Decls {
+			for _, name := range declNames(decl) {
+				if ast.IsExported(name) {
+					w.queue(path + "." + name)
+				}
+			}
+		}
+	}
+
+	var sections []string
+	for len(w.pending) > 0 {
+		qualified := w.pending[0]
+		w.pending = w.pending[1:]
+
+		section, err := w.describe(qualified)
+		if err != nil {
+			return "", err
+		}
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	return strings.Join(sections, "\n"), nil
+}
+
+// describe renders the declaration of a qualified name and the signatures of its exported methods.
+func (w *apiWriter) describe(qualified string) (string, error) {
+	dot := strings.LastIndex(qualified, ".")
+	path, name := qualified[:dot], qualified[dot+1:]
+
+	pkg, err := w.load(path)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("// " + strings.TrimPrefix(qualified, modulePath+"/") + "\n")
+
+	var methods []string
+	found := false
+	for _, file := range pkg.files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if !d.Name.IsExported() {
+					continue
+				}
+				switch {
+				case d.Recv == nil && d.Name.Name == name:
+					found = true
+					w.refer(file, path, d.Type)
+					b.WriteString(w.print(&ast.FuncDecl{Name: d.Name, Type: d.Type}) + "\n")
+				case d.Recv != nil && receiverName(d.Recv) == name:
+					w.refer(file, path, d.Type)
+					methods = append(methods, w.print(&ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type}))
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						if s.Name.Name != name {
+							continue
+						}
+						found = true
+						w.refer(file, path, s.Type)
+						spec := &ast.TypeSpec{Name: s.Name, TypeParams: s.TypeParams, Assign: s.Assign, Type: exported(s.Type)}
+						b.WriteString(w.print(&ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{spec}}) + "\n")
+					case *ast.ValueSpec:
+						for i, n := range s.Names {
+							if n.Name != name {
+								continue
+							}
+							found = true
+							value := &ast.ValueSpec{Names: []*ast.Ident{n}, Type: s.Type}
+							if i < len(s.Values) {
+								w.refer(file, path, s.Values[i])
+								value.Values = []ast.Expr{s.Values[i]}
+							}
+							w.refer(file, path, s.Type)
+							b.WriteString(w.print(&ast.GenDecl{Tok: d.Tok, Specs: []ast.Spec{value}}) + "\n")
+						}
+					}
+				}
+			}
+		}
+	}
+	if !found {
+		return "", &missingDeclError{name: qualified}
+	}
+
+	sort.Strings(methods)
+	for _, m := range methods {
+		b.WriteString(m + "\n")
+	}
+
+	return b.String(), nil
+}
+
+// load parses the non-test files of the module package at path.
+func (w *apiWriter) load(path string) (*apiPackage, error) {
+	if pkg, ok := w.packages[path]; ok {
+		return pkg, nil
+	}
+
+	dir := filepath.Join(moduleRoot, strings.TrimPrefix(path, modulePath))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &apiPackage{path: path}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(w.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		pkg.files = append(pkg.files, file)
+	}
+	w.packages[path] = pkg
+
+	return pkg, nil
+}
+
+// print renders node without comments, laid out by gofmt regardless of how its source is laid out.
+func (w *apiWriter) print(node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), node); err != nil {
+		return "<" + err.Error() + ">"
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(src)
+}
+
+// queue adds a qualified name to describe, unless it has been queued before.
+func (w *apiWriter) queue(qualified string) {
+	if w.seen[qualified] {
+		return
+	}
+	w.seen[qualified] = true
+	w.pending = append(w.pending, qualified)
+}
+
+// refer queues the exported module declarations that expr, found in file of the package at path, refers to.
+func (w *apiWriter) refer(file *ast.File, path string, expr ast.Node) {
+	if expr == nil {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.StructType:
+			w.referFields(file, path, x.Fields)
+			return false
+		case *ast.InterfaceType:
+			w.referFields(file, path, x.Methods)
+			return false
+		case *ast.Field:
+			// Parameter names are not declarations.
+			w.refer(file, path, x.Type)
+			return false
+		case *ast.SelectorExpr:
+			ident, ok := x.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if imported := importPath(file, ident.Name); strings.HasPrefix(imported, modulePath+"/") {
+				w.queue(imported + "." + x.Sel.Name)
+			}
+			return false
+		case *ast.Ident:
+			if x.IsExported() {
+				w.queue(path + "." + x.Name)
+			}
+		}
+		return true
+	})
+}
+
+// referFields queues the declarations referred to by the exported and embedded entries of a field list.
+func (w *apiWriter) referFields(file *ast.File, path string, list *ast.FieldList) {
+	for _, f := range list.List {
+		if len(f.Names) == 0 || anyExported(f.Names) {
+			w.refer(file, path, f.Type)
+		}
+	}
+}
+
+// missingDeclError reports a referenced name with no declaration, such as a method value or a predeclared type.
+type missingDeclError struct {
+	// name is the qualified name that was not found.
+	name string
+}
+
+func (e *missingDeclError) Error() string {
+	return "no declaration for " + e.name
+}
+
+// anyExported reports whether any of names is exported.
+func anyExported(names []*ast.Ident) bool {
+	for _, n := range names {
+		if n.IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// declNames returns the names a top-level declaration introduces, excluding methods.
+func declNames(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names = append(names, n.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// exported returns a copy of a type expression with unexported struct fields and interface methods removed.
+func exported(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return &ast.StructType{Fields: exportedFields(t.Fields)}
+	case *ast.InterfaceType:
+		return &ast.InterfaceType{Methods: exportedFields(t.Methods)}
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: exported(t.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: exported(t.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: exported(t.Key), Value: exported(t.Value)}
+	default:
+		return expr
+	}
+}
+
+// exportedFields returns the exported and embedded entries of a field list, without their comments.
+func exportedFields(list *ast.FieldList) *ast.FieldList {
+	out := &ast.FieldList{}
+	if list == nil {
+		return out
+	}
+	for _, f := range list.List {
+		var names []*ast.Ident
+		for _, n := range f.Names {
+			if n.IsExported() {
+				names = append(names, n)
+			}
+		}
+		if len(f.Names) > 0 && len(names) == 0 {
+			continue
+		}
+		out.List = append(out.List, &ast.Field{Names: names, Type: exported(f.Type), Tag: f.Tag})
+	}
+	return out
+}
+
+// importPath returns the path file imports under name, or "" if it has no such import.
+func importPath(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		local := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			local = spec.Name.Name
+		}
+		if local == name {
+			return path
+		}
+	}
+	return ""
+}
+
+// receiverName returns the type name of a method receiver.
+func receiverName(recv *ast.FieldList) string {
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package giftbridge
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/blackbaud"
+	"github.com/peteski22/giftbridge/internal/fundraiseup"
+)
+
+// BlackbaudConfig holds the required configuration for creating a Blackbaud SKY API client.
+type BlackbaudConfig = blackbaud.Config
+
+// BlackbaudOption configures a Blackbaud SKY API client.
+type BlackbaudOption = blackbaud.Option
+
+// Constituent is a Raiser's Edge NXT constituent.
+type Constituent = blackbaud.Constituent
+
+// ConstituentAppeal records a constituent's response to an appeal.
+type ConstituentAppeal = blackbaud.ConstituentAppeal
+
+// ConstituentCode is a code attached to a constituent.
+type ConstituentCode = blackbaud.ConstituentCode
+
+// Donation is a FundraiseUp donation.
+type Donation = fundraiseup.Donation
+
+// EmailAddress is an email address record added to an existing constituent.
+type EmailAddress = blackbaud.EmailAddress
+
+// FormattedName is a constituent's name as it appears in mail, such as an addressee or salutation.
+type FormattedName = blackbaud.FormattedName
+
+// FundraiseUpAccount is one of several FundraiseUp accounts synced together.
+type FundraiseUpAccount = fundraiseup.Account
+
+// FundraiseUpAccounts merges the donations of several FundraiseUp accounts, tagging gifts with the account name.
+type FundraiseUpAccounts = fundraiseup.Accounts
+
+// FundraiseUpClient is a FundraiseUp API client.
+type FundraiseUpClient = fundraiseup.Client
+
+// FundraiseUpOption configures a FundraiseUp API client.
+type FundraiseUpOption = fundraiseup.Option
+
+// Gift is a Raiser's Edge NXT gift.
+type Gift = blackbaud.Gift
+
+// GiftAmount is the monetary value of a gift or gift split.
+type GiftAmount = blackbaud.GiftAmount
+
+// GiftSplit assigns part of a gift to a fund, campaign and appeal.
+type GiftSplit = blackbaud.GiftSplit
+
+// GiftType is the type of a Raiser's Edge NXT gift.
+type GiftType = blackbaud.GiftType
+
+// Participant is a constituent taking part in a Raiser's Edge NXT event.
+type Participant = blackbaud.Participant
+
+// Quota is the Blackbaud call quota reported by the most recent response.
+type Quota = blackbaud.Quota
+
+// SearchOptions narrows a constituent search.
+type SearchOptions = blackbaud.SearchOptions
+
+// SKYClient is a Blackbaud SKY API client.
+type SKYClient = blackbaud.Client
+
+// TokenStore provides access to the Blackbaud OAuth tokens.
+type TokenStore = blackbaud.TokenStore
+
+// NewBlackbaudClient creates a Blackbaud SKY API client.
+func NewBlackbaudClient(cfg BlackbaudConfig, opts ...BlackbaudOption) (*SKYClient, error) {
+	return blackbaud.NewClient(cfg, opts...)
+}
+
+// NewFundraiseUpAccounts merges the donations of several FundraiseUp accounts into one DonationSource.
+// Leave the main account's name empty so its gifts are not tagged.
+func NewFundraiseUpAccounts(accounts ...FundraiseUpAccount) (*FundraiseUpAccounts, error) {
+	return fundraiseup.NewAccounts(accounts...)
+}
+
+// NewFundraiseUpClient creates a FundraiseUp API client.
+func NewFundraiseUpClient(apiKey string, opts ...FundraiseUpOption) (*FundraiseUpClient, error) {
+	return fundraiseup.NewClient(apiKey, opts...)
+}
+
+// WithBlackbaudBaseURL sets the base URL for the Blackbaud SKY API.
+func WithBlackbaudBaseURL(baseURL string) BlackbaudOption {
+	return blackbaud.WithBaseURL(baseURL)
+}
+
+// WithBlackbaudHedgeDelay sets how long a Blackbaud read waits before an identical request is sent.
+func WithBlackbaudHedgeDelay(delay time.Duration) BlackbaudOption {
+	return blackbaud.WithHedgeDelay(delay)
+}
+
+// WithBlackbaudHTTPClient sets the HTTP client used for Blackbaud requests.
+func WithBlackbaudHTTPClient(httpClient *http.Client) BlackbaudOption {
+	return blackbaud.WithHTTPClient(httpClient)
+}
+
+// WithBlackbaudMaxResponseSize sets the largest Blackbaud response body read, in bytes.
+func WithBlackbaudMaxResponseSize(size int) BlackbaudOption {
+	return blackbaud.WithMaxResponseSize(size)
+}
+
+// WithBlackbaudSecondarySubscriptionKey sets the subscription key Blackbaud requests fail over to when the SKY API
+// rejects the primary one.
+func WithBlackbaudSecondarySubscriptionKey(key string) BlackbaudOption {
+	return blackbaud.WithSecondarySubscriptionKey(key)
+}
+
+// WithBlackbaudTimeout sets the timeout for Blackbaud requests.
+func WithBlackbaudTimeout(timeout time.Duration) BlackbaudOption {
+	return blackbaud.WithTimeout(timeout)
+}
+
+// WithBlackbaudTransport sets the HTTP transport used for Blackbaud requests.
+func WithBlackbaudTransport(transport http.RoundTripper) BlackbaudOption {
+	return blackbaud.WithTransport(transport)
+}
+
+// WithBlackbaudUserAgent sets the User-Agent header sent with Blackbaud requests, including token refreshes.
+func WithBlackbaudUserAgent(userAgent string) BlackbaudOption {
+	return blackbaud.WithUserAgent(userAgent)
+}
+
+// WithFundraiseUpBaseURL sets the base URL for the FundraiseUp API.
+func WithFundraiseUpBaseURL(baseURL string) FundraiseUpOption {
+	return fundraiseup.WithBaseURL(baseURL)
+}
+
+// WithFundraiseUpCampaign restricts fetched donations to a single FundraiseUp campaign.
+func WithFundraiseUpCampaign(campaignID string) FundraiseUpOption {
+	return fundraiseup.WithCampaign(campaignID)
+}
+
+// WithFundraiseUpHTTPClient sets the HTTP client used for FundraiseUp requests.
+func WithFundraiseUpHTTPClient(httpClient *http.Client) FundraiseUpOption {
+	return fundraiseup.WithHTTPClient(httpClient)
+}
+
+// WithFundraiseUpMaxResponseSize sets the largest FundraiseUp response body read, in bytes.
+func WithFundraiseUpMaxResponseSize(size int) FundraiseUpOption {
+	return fundraiseup.WithMaxResponseSize(size)
+}
+
+// WithFundraiseUpPageSize sets the number of donations requested per page (at most 100).
+func WithFundraiseUpPageSize(size int) FundraiseUpOption {
+	return fundraiseup.WithPageSize(size)
+}
+
+// WithFundraiseUpStatus restricts fetched donations to those with the given status (e.g., "succeeded").
+func WithFundraiseUpStatus(status string) FundraiseUpOption {
+	return fundraiseup.WithStatus(status)
+}
+
+// WithFundraiseUpStrictDecoding records the payload fields FundraiseUp sends that the mapper does not decode,
+// available from FundraiseUpClient.UnknownFields.
+func WithFundraiseUpStrictDecoding() FundraiseUpOption {
+	return fundraiseup.WithStrictDecoding()
+}
+
+// WithFundraiseUpTimeout sets the timeout for FundraiseUp requests.
+func WithFundraiseUpTimeout(timeout time.Duration) FundraiseUpOption {
+	return fundraiseup.WithTimeout(timeout)
+}
+
+// WithFundraiseUpTransport sets the HTTP transport used for FundraiseUp requests.
+func WithFundraiseUpTransport(transport http.RoundTripper) FundraiseUpOption {
+	return fundraiseup.WithTransport(transport)
+}
+
+// WithFundraiseUpUserAgent sets the User-Agent header sent with FundraiseUp requests.
+func WithFundraiseUpUserAgent(userAgent string) FundraiseUpOption {
+	return fundraiseup.WithUserAgent(userAgent)
+}
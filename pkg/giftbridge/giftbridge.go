@@ -0,0 +1,225 @@
+// Package giftbridge lets other Go programs embed the FundraiseUp to Raiser's Edge NXT sync
+// instead of running the giftbridge binary.
+//
+// Build the clients and stores with the constructors in this package, pass them to NewService,
+// and call Run on the returned Service:
+//
+//	bb, err := giftbridge.NewBlackbaudClient(giftbridge.BlackbaudConfig{...})
+//	fu, err := giftbridge.NewFundraiseUpClient(apiKey)
+//	svc, err := giftbridge.NewService(giftbridge.Config{
+//		Blackbaud:    bb,
+//		FundraiseUp:  fu,
+//		GiftDefaults: giftbridge.GiftDefaults{FundID: "1"},
+//		StateStore:   giftbridge.NewNoopStateStore(since),
+//	})
+//	result, err := svc.Run(ctx)
+//
+// The types here are aliases of the ones the binary uses, so values can be passed between this package
+// and any hooks or clients an embedding program provides. Because they are aliases, the exported fields and
+// methods of the types they point to are part of this package's API and are covered by the same
+// compatibility promise, even though those types are declared under internal/.
+package giftbridge
+
+import (
+	"github.com/peteski22/giftbridge/internal/config"
+	"github.com/peteski22/giftbridge/internal/lru"
+	"github.com/peteski22/giftbridge/internal/sync"
+)
+
+// Policies for Config.DeletedGiftPolicy, applied when a tracked gift has been deleted in Raiser's Edge NXT.
+const (
+	// DeletedGiftPolicyExclude marks the donation excluded, so it is never synced again.
+	DeletedGiftPolicyExclude = config.DeletedGiftPolicyExclude
+
+	// DeletedGiftPolicyRecreate creates the gift again.
+	DeletedGiftPolicyRecreate = config.DeletedGiftPolicyRecreate
+
+	// DeletedGiftPolicyReport leaves the gift deleted and reports it.
+	DeletedGiftPolicyReport = config.DeletedGiftPolicyReport
+)
+
+// Policies for GiftDefaults.DatePolicy, applied to gifts dated in the future or older than GiftDefaults.MaxAgeDays.
+const (
+	// GiftDatePolicyFlag creates the gift and adds a warning to the run.
+	GiftDatePolicyFlag = config.GiftDatePolicyFlag
+
+	// GiftDatePolicyRefuse fails the donation without creating the gift.
+	GiftDatePolicyRefuse = config.GiftDatePolicyRefuse
+)
+
+// Modes for GiftDefaults.RecurringMode, choosing how recurring donations are recorded.
+const (
+	// GiftRecurringModeFlat records every recurring payment as a plain gift of GiftDefaults.Type.
+	GiftRecurringModeFlat = config.GiftRecurringModeFlat
+
+	// GiftRecurringModeLinked records a RecurringGift and links later payments to it. An empty mode also links them.
+	GiftRecurringModeLinked = config.GiftRecurringModeLinked
+)
+
+// Policies for GiftDefaults.TestDonations, applied to donations made in FundraiseUp's test mode.
+const (
+	// TestDonationsSkip leaves test-mode donations out of Raiser's Edge NXT. An empty policy also skips them.
+	TestDonationsSkip = config.TestDonationsSkip
+
+	// TestDonationsSync creates gifts for test-mode donations, in GiftDefaults.TestFundID when it is set.
+	TestDonationsSync = config.TestDonationsSync
+)
+
+// AppealResponder is implemented by Blackbaud clients that can record constituents' responses to appeals,
+// which GiftDefaults.AppealResponses requires.
+type AppealResponder = sync.AppealResponder
+
+// BatchTracker is implemented by donation trackers that can record several gifts in one call.
+type BatchTracker = sync.BatchTracker
+
+// BlackbaudClient is the set of Blackbaud SKY API calls the sync makes.
+// *SKYClient implements it; programs may supply their own for testing or to route calls elsewhere.
+type BlackbaudClient = sync.BlackbaudClient
+
+// CacheStats counts how a run's cache was used, such as Metrics.GiftCache.
+type CacheStats = lru.Stats
+
+// CallMetrics records the calls made to one API and the time spent in them.
+type CallMetrics = sync.CallMetrics
+
+// CampaignCounts counts the donations of one FundraiseUp campaign processed in a run, in Result.Campaigns.
+type CampaignCounts = sync.CampaignCounts
+
+// CampaignWindow limits the donations of one FundraiseUp campaign synced to those made within a window, or excludes
+// the campaign, when listed in Config.CampaignWindows.
+type CampaignWindow = config.CampaignWindow
+
+// CommentScrubbing controls what is removed from donor comments before they are stored on gifts.
+type CommentScrubbing = config.CommentScrubbing
+
+// Config holds the configuration for a Service.
+type Config = sync.Config
+
+// ConstituentDefaults contains default values for constituents created in Raiser's Edge NXT.
+type ConstituentDefaults = config.ConstituentDefaults
+
+// CountryRoute sends gifts from supporters in given countries to their own fund, campaign or appeal,
+// when listed in GiftDefaults.CountryRoutes.
+type CountryRoute = config.CountryRoute
+
+// DonationNoteFields are the fields ConstituentDefaults.DonationNoteFormat can refer to, such as {{.Campaign}}.
+type DonationNoteFields = sync.DonationNoteFields
+
+// DonationResult contains the outcome of processing a single donation.
+type DonationResult = sync.DonationResult
+
+// DonationSource is the FundraiseUp API donations are synced from: a FundraiseUpClient for a single account,
+// or FundraiseUpAccounts to sync several together.
+type DonationSource = sync.DonationSource
+
+// DonationTracker records the gift created for each donation.
+type DonationTracker = sync.DonationTracker
+
+// EmailAdder is implemented by Blackbaud clients that can add email addresses to existing constituents,
+// which EmailNormalization.AddMissing requires.
+type EmailAdder = sync.EmailAdder
+
+// EmailNormalization controls how supporter emails are normalized when matching constituents.
+type EmailNormalization = config.EmailNormalization
+
+// EventLink links a FundraiseUp event to the Raiser's Edge NXT event its ticket buyers are registered for,
+// when listed in ConstituentDefaults.Events.
+type EventLink = config.EventLink
+
+// EventRegistrar is implemented by Blackbaud clients that can add constituents to events as participants,
+// which ConstituentDefaults.Events requires.
+type EventRegistrar = sync.EventRegistrar
+
+// FundSplit sends an amount or percentage of each gift to a fund, when listed in GiftDefaults.Splits.
+type FundSplit = config.GiftSplit
+
+// GiftCheck is a condition each gift must meet before it is created, when listed in GiftDefaults.Checks.
+type GiftCheck = config.GiftCheck
+
+// GiftCheckError reports that a gift failed its GiftDefaults.Checks, so it was not created.
+type GiftCheckError = sync.GiftCheckError
+
+// GiftCustomFieldCreator is implemented by Blackbaud clients that can add custom fields to gifts,
+// which GiftDefaults.CreatorCustomField requires.
+type GiftCustomFieldCreator = sync.GiftCustomFieldCreator
+
+// GiftDateError reports that a gift's date was implausible under GiftDefaults.DatePolicy.
+type GiftDateError = sync.GiftDateError
+
+// GiftDefaults contains default values for gifts created in Raiser's Edge NXT.
+type GiftDefaults = config.GiftDefaults
+
+// GiftDiscrepancy is a gift field stored differently from the value sent, reported when Config.Verify is set.
+type GiftDiscrepancy = sync.GiftDiscrepancy
+
+// GiftNoteCreator is implemented by Blackbaud clients that can add notes to gifts,
+// which GiftDefaults.ReferenceNoteType requires.
+type GiftNoteCreator = sync.GiftNoteCreator
+
+// GiftReader is implemented by Blackbaud clients that can read a single gift, which verification requires.
+type GiftReader = sync.GiftReader
+
+// GiftReplacer is implemented by donation trackers that can replace a donation's gift,
+// which the recreate deleted gift policy requires.
+type GiftReplacer = sync.GiftReplacer
+
+// GiftRule sets a gift field from an expression, optionally only when a condition holds.
+type GiftRule = config.GiftRule
+
+// Hook customises new constituents and gifts during a sync.
+type Hook = sync.Hook
+
+// Metrics records the API calls made during a run.
+type Metrics = sync.Metrics
+
+// NameNormalization controls how supporter names are cleaned up when creating constituents.
+type NameNormalization = config.NameNormalization
+
+// NoteCreator is implemented by Blackbaud clients that can add notes to constituents,
+// which ConstituentDefaults.DonationNoteType, Config.PlanChangeNoteType and Config.ChargebackNoteType require.
+type NoteCreator = sync.NoteCreator
+
+// NopHook implements Hook by doing nothing. Embed it to implement only the methods needed.
+type NopHook = sync.NopHook
+
+// PendingStore keeps progress through a donations window, so interrupted runs are resumed.
+// A StateStore that does not implement it fetches the whole window since the last sync each run.
+type PendingStore = sync.PendingStore
+
+// PanicError reports that processing a donation panicked, so the donation was skipped rather than aborting the run.
+type PanicError = sync.PanicError
+
+// PoisonPillRecorder keeps donations whose processing panicked. A StateStore that implements it has each recorded.
+type PoisonPillRecorder = sync.PoisonPillRecorder
+
+// QuotaReporter is implemented by Blackbaud clients that report the remaining call quota.
+type QuotaReporter = sync.QuotaReporter
+
+// RecurringHistory is implemented by donation trackers that can list the donations tracked for a recurring plan,
+// which Config.PlanChangeNoteType requires.
+type RecurringHistory = sync.RecurringHistory
+
+// Result contains the outcome of a sync run.
+type Result = sync.Result
+
+// RetryStore keeps the schedule of donations to try again after transient failures.
+// A StateStore that implements it has such donations retried on later runs, with backoff.
+type RetryStore = sync.RetryStore
+
+// RunRecorder keeps a history of run summaries. A StateStore that implements it has each run recorded.
+type RunRecorder = sync.RunRecorder
+
+// Service syncs donations from FundraiseUp to Raiser's Edge NXT.
+type Service = sync.Service
+
+// StateStore persists the last sync time between runs.
+type StateStore = sync.StateStore
+
+// SupporterCache is implemented by donation trackers that can remember across runs which constituent a supporter's
+// email matched, which Config.SupporterCacheTTL requires.
+type SupporterCache = sync.SupporterCache
+
+// NewService creates a Service from cfg, returning an error if required fields are missing.
+func NewService(cfg Config) (*Service, error) {
+	return sync.New(cfg)
+}
@@ -0,0 +1,115 @@
+package giftbridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBlackbaud is a BlackbaudClient written only against this package's types, as an embedding program would.
+type fakeBlackbaud struct {
+	gifts []Gift
+}
+
+func (f *fakeBlackbaud) CreateConstituent(_ context.Context, _ *Constituent) (string, error) {
+	return "const-1", nil
+}
+
+func (f *fakeBlackbaud) CreateConstituentCode(_ context.Context, _ *ConstituentCode) (string, error) {
+	return "code-1", nil
+}
+
+func (f *fakeBlackbaud) CreateGift(_ context.Context, gift *Gift) (string, error) {
+	f.gifts = append(f.gifts, *gift)
+	return "gift-1", nil
+}
+
+func (f *fakeBlackbaud) ListGiftsByConstituent(_ context.Context, _ string, _ []GiftType) ([]Gift, error) {
+	return nil, nil
+}
+
+func (f *fakeBlackbaud) SearchConstituents(_ context.Context, _ string, _ SearchOptions) ([]Constituent, error) {
+	return nil, nil
+}
+
+func (f *fakeBlackbaud) UpdateGift(_ context.Context, _ string, _ *Gift) error {
+	return nil
+}
+
+// referenceHook stamps a reference on every gift.
+type referenceHook struct {
+	NopHook
+}
+
+func (referenceHook) BeforeGiftCreate(_ context.Context, donation Donation, gift *Gift) error {
+	gift.Reference = "embedded " + donation.ID
+	return nil
+}
+
+func TestNewService(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg     Config
+		wantErr string
+	}{
+		"missing clients": {
+			cfg:     Config{GiftDefaults: GiftDefaults{FundID: "fund-1"}},
+			wantErr: "blackbaud client is required",
+		},
+		"missing fund": {
+			cfg:     Config{Blackbaud: &fakeBlackbaud{}, FundraiseUp: &FundraiseUpClient{}},
+			wantErr: "gift defaults fund ID is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewService(tc.cfg)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestServiceRun(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{
+				"amount":    "25.00",
+				"id":        "don_1",
+				"supporter": map[string]any{"email": "donor@example.com"},
+			}},
+			"has_more": false,
+		})
+	}))
+	defer server.Close()
+
+	fu, err := NewFundraiseUpClient("test-key", WithFundraiseUpBaseURL(server.URL))
+	require.NoError(t, err)
+
+	bb := &fakeBlackbaud{}
+	svc, err := NewService(Config{
+		Blackbaud:    bb,
+		FundraiseUp:  fu,
+		GiftDefaults: GiftDefaults{FundID: "fund-1", Type: "Donation"},
+		Hooks:        []Hook{referenceHook{}},
+		StateStore:   NewNoopStateStore(time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	require.NoError(t, err)
+
+	result, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.GiftsCreated)
+	require.Len(t, bb.gifts, 1)
+	require.Equal(t, "embedded don_1", bb.gifts[0].Reference)
+}
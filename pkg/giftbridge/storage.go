@@ -0,0 +1,266 @@
+package giftbridge
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/peteski22/giftbridge/internal/storage"
+)
+
+// AccessTokenSource provides OAuth access tokens for a cloud provider's APIs.
+type AccessTokenSource = storage.AccessTokenSource
+
+// AlreadyTrackedError is returned when a donation is already tracked with a different gift.
+type AlreadyTrackedError = storage.AlreadyTrackedError
+
+// AzureManagedIdentity is the managed identity of an Azure Functions or App Service app.
+type AzureManagedIdentity = storage.AzureManagedIdentity
+
+// AzureOption configures a KeyVaultTokenStore or AzureTableStateStore.
+type AzureOption = storage.AzureOption
+
+// AzureTableStateStore is a StateStore backed by an Azure Table Storage entity.
+type AzureTableStateStore = storage.AzureTableStateStore
+
+// BatchTrackError reports the records of a DynamoDBTracker batch write that were not written.
+type BatchTrackError = storage.BatchTrackError
+
+// DonationCounts summarises the donations a DynamoDBTracker tracked over a period.
+type DonationCounts = storage.DonationCounts
+
+// DonationRecord is the gift created for a donation, as stored by a DonationTracker.
+type DonationRecord = storage.DonationRecord
+
+// DynamoDBAPI defines the DynamoDB operations used by the DynamoDB donation tracker.
+type DynamoDBAPI = storage.DynamoDBAPI
+
+// DynamoDBTracker is a DonationTracker backed by a DynamoDB table.
+type DynamoDBTracker = storage.DonationTracker
+
+// DynamoDBTrackerOption configures a DynamoDBTracker.
+type DynamoDBTrackerOption = storage.DonationTrackerOption
+
+// FileTokenStore is a TokenStore backed by a local JSON file.
+type FileTokenStore = storage.FileTokenStore
+
+// FirestoreStateStore is a StateStore backed by a Firestore document.
+type FirestoreStateStore = storage.FirestoreStateStore
+
+// GoogleOption configures a GoogleSecretTokenStore or FirestoreStateStore.
+type GoogleOption = storage.GoogleOption
+
+// GoogleSecretTokenStore is a TokenStore backed by a Google Secret Manager secret.
+type GoogleSecretTokenStore = storage.GoogleSecretTokenStore
+
+// HealthSnapshot is a compact summary of the sync's health, as published by an SSMStateStore.
+type HealthSnapshot = storage.HealthSnapshot
+
+// KeyVaultTokenStore is a TokenStore backed by an Azure Key Vault secret.
+type KeyVaultTokenStore = storage.KeyVaultTokenStore
+
+// NoopStateStore is a StateStore that always starts from a fixed time and stores nothing.
+type NoopStateStore = storage.NoopStateStore
+
+// PoisonPill records a donation whose processing panicked, as kept by a PoisonPillRecorder.
+type PoisonPill = storage.PoisonPill
+
+// RetryEntry schedules another attempt at a donation that failed transiently, as kept by a RetryStore.
+type RetryEntry = storage.RetryEntry
+
+// RunSummary records the outcome of a sync run, as kept by a RunRecorder.
+type RunSummary = storage.RunSummary
+
+// SecretsManagerAPI defines the Secrets Manager operations used by the Secrets Manager token store.
+type SecretsManagerAPI = storage.SecretsManagerAPI
+
+// SecretsManagerTokenStore is a TokenStore backed by an AWS Secrets Manager secret.
+type SecretsManagerTokenStore = storage.TokenStore
+
+// SSMAPI defines the SSM Parameter Store operations used by the SSM state store.
+type SSMAPI = storage.SSMAPI
+
+// SSMStateStore is a StateStore backed by SSM Parameter Store.
+type SSMStateStore = storage.StateStore
+
+// SSMStateStoreOption configures an SSMStateStore.
+type SSMStateStoreOption = storage.StateStoreOption
+
+// VaultTokenStore is a TokenStore backed by a HashiCorp Vault KV version 2 secret.
+type VaultTokenStore = storage.VaultTokenStore
+
+// VaultTokenStoreOption configures a VaultTokenStore.
+type VaultTokenStoreOption = storage.VaultTokenStoreOption
+
+// IsAlreadyTracked reports whether err is a donation already being tracked with a different gift.
+func IsAlreadyTracked(err error) bool {
+	return storage.IsAlreadyTracked(err)
+}
+
+// NewAzureTableStateStore creates a state store keeping the sync state in an entity of the named table.
+func NewAzureTableStateStore(
+	tokens AccessTokenSource,
+	endpoint string,
+	tableName string,
+	opts ...AzureOption,
+) (*AzureTableStateStore, error) {
+	return storage.NewAzureTableStateStore(tokens, endpoint, tableName, opts...)
+}
+
+// NewAzureTokenSource returns access tokens for the Azure resource issued to the app's managed identity.
+func NewAzureTokenSource(client *http.Client, identity AzureManagedIdentity, resource string) AccessTokenSource {
+	return storage.NewAzureTokenSource(client, identity, resource)
+}
+
+// NewDynamoDBTracker creates a donation tracker backed by the named DynamoDB table.
+func NewDynamoDBTracker(
+	client DynamoDBAPI,
+	tableName string,
+	opts ...DynamoDBTrackerOption,
+) (*DynamoDBTracker, error) {
+	return storage.NewDonationTracker(client, tableName, opts...)
+}
+
+// NewFileTokenStore creates a token store that reads and writes the given file.
+func NewFileTokenStore(path string) (*FileTokenStore, error) {
+	return storage.NewFileTokenStore(path)
+}
+
+// NewFirestoreStateStore creates a state store keeping the sync state in the Firestore document at documentPath.
+func NewFirestoreStateStore(
+	tokens AccessTokenSource,
+	project string,
+	documentPath string,
+	opts ...GoogleOption,
+) (*FirestoreStateStore, error) {
+	return storage.NewFirestoreStateStore(tokens, project, documentPath, opts...)
+}
+
+// NewGoogleSecretTokenStore creates a token store backed by the given Google Secret Manager secret.
+func NewGoogleSecretTokenStore(
+	tokens AccessTokenSource,
+	project string,
+	secretID string,
+	opts ...GoogleOption,
+) (*GoogleSecretTokenStore, error) {
+	return storage.NewGoogleSecretTokenStore(tokens, project, secretID, opts...)
+}
+
+// NewGoogleTokenSource returns access tokens for the service account the code runs as on Google Cloud.
+func NewGoogleTokenSource(client *http.Client, metadataHost string) AccessTokenSource {
+	return storage.NewGoogleTokenSource(client, metadataHost)
+}
+
+// NewKeyVaultTokenStore creates a token store backed by the named secret of an Azure Key Vault.
+func NewKeyVaultTokenStore(
+	tokens AccessTokenSource,
+	vaultURL string,
+	secretName string,
+	opts ...AzureOption,
+) (*KeyVaultTokenStore, error) {
+	return storage.NewKeyVaultTokenStore(tokens, vaultURL, secretName, opts...)
+}
+
+// NewNoopStateStore creates a state store that starts every run from since, for dry runs and one-off syncs.
+func NewNoopStateStore(since time.Time) *NoopStateStore {
+	return storage.NewNoopStateStore(since)
+}
+
+// NewSecretsManagerTokenStore creates a token store backed by the given Secrets Manager secret.
+func NewSecretsManagerTokenStore(client SecretsManagerAPI, secretARN string) (*SecretsManagerTokenStore, error) {
+	return storage.NewTokenStore(client, secretARN)
+}
+
+// NewSSMStateStore creates a state store that keeps the last sync time in the named SSM parameter.
+func NewSSMStateStore(
+	client SSMAPI,
+	lastSyncParameterName string,
+	opts ...SSMStateStoreOption,
+) (*SSMStateStore, error) {
+	return storage.NewStateStore(client, lastSyncParameterName, opts...)
+}
+
+// NewVaultTokenStore creates a token store keeping the refresh token under the "refresh_token" key of the Vault
+// KV version 2 secret at secretPath.
+func NewVaultTokenStore(
+	address string,
+	token string,
+	secretPath string,
+	opts ...VaultTokenStoreOption,
+) (*VaultTokenStore, error) {
+	return storage.NewVaultTokenStore(address, token, secretPath, opts...)
+}
+
+// WithAzureHTTPClient sets the HTTP client used to reach an Azure store's API.
+func WithAzureHTTPClient(client *http.Client) AzureOption {
+	return storage.WithAzureHTTPClient(client)
+}
+
+// WithDynamoDBBatchRetryDelay sets how long to wait before first retrying items a batch read or write left unprocessed.
+func WithDynamoDBBatchRetryDelay(delay time.Duration) DynamoDBTrackerOption {
+	return storage.WithBatchRetryDelay(delay)
+}
+
+// WithDynamoDBRetention sets how long tracked donations are kept before DynamoDB expires them.
+func WithDynamoDBRetention(retention time.Duration) DynamoDBTrackerOption {
+	return storage.WithRetention(retention)
+}
+
+// WithDynamoDBTablePollInterval sets how often table status is checked while waiting for it to become active.
+func WithDynamoDBTablePollInterval(interval time.Duration) DynamoDBTrackerOption {
+	return storage.WithTablePollInterval(interval)
+}
+
+// WithGoogleBaseURL sets the base URL of a Google Cloud store's API, such as the Firestore emulator's.
+func WithGoogleBaseURL(baseURL string) GoogleOption {
+	return storage.WithGoogleBaseURL(baseURL)
+}
+
+// WithGoogleHTTPClient sets the HTTP client used to reach a Google Cloud store's API.
+func WithGoogleHTTPClient(client *http.Client) GoogleOption {
+	return storage.WithGoogleHTTPClient(client)
+}
+
+// WithSSMFetchStateParameter sets the SSM parameter name for the fetch checkpoint.
+func WithSSMFetchStateParameter(name string) SSMStateStoreOption {
+	return storage.WithFetchStateParameter(name)
+}
+
+// WithSSMHealthParameter sets the SSM parameter name for the health snapshot.
+func WithSSMHealthParameter(name string) SSMStateStoreOption {
+	return storage.WithHealthParameter(name)
+}
+
+// WithSSMPendingParameter sets the SSM parameter name for pending donation IDs.
+func WithSSMPendingParameter(name string) SSMStateStoreOption {
+	return storage.WithPendingParameter(name)
+}
+
+// WithSSMPoisonPillParameter sets the SSM parameter name for donations whose processing panicked.
+func WithSSMPoisonPillParameter(name string) SSMStateStoreOption {
+	return storage.WithPoisonPillParameter(name)
+}
+
+// WithSSMRetryParameter sets the SSM parameter name for the retry schedule.
+func WithSSMRetryParameter(name string) SSMStateStoreOption {
+	return storage.WithRetryParameter(name)
+}
+
+// WithSSMRunHistoryParameter sets the SSM parameter name for recent run summaries.
+func WithSSMRunHistoryParameter(name string) SSMStateStoreOption {
+	return storage.WithRunHistoryParameter(name)
+}
+
+// WithVaultHTTPClient sets the HTTP client used to reach Vault.
+func WithVaultHTTPClient(client *http.Client) VaultTokenStoreOption {
+	return storage.WithVaultHTTPClient(client)
+}
+
+// WithVaultKVMount sets the path the KV version 2 secrets engine is mounted at (default: secret).
+func WithVaultKVMount(mount string) VaultTokenStoreOption {
+	return storage.WithVaultKVMount(mount)
+}
+
+// WithVaultNamespace sets the Vault Enterprise namespace of the secret.
+func WithVaultNamespace(namespace string) VaultTokenStoreOption {
+	return storage.WithVaultNamespace(namespace)
+}